@@ -0,0 +1,85 @@
+package main
+
+// Command migrate applies, rolls back and reports the versioned database migrations.
+//
+//	go run ./cmd/migrate up          apply all pending migrations
+//	go run ./cmd/migrate down [n]    roll back the last n migrations (default 1)
+//	go run ./cmd/migrate status      list migrations and schema drift
+
+import (
+	"backend/config"
+	"backend/pkg/database"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("Could not load config: %v", err)
+	}
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Could not connect to database: %v", err)
+	}
+	migrator := database.NewMigrator(db)
+
+	switch os.Args[1] {
+	case "up":
+		ran, err := migrator.Up()
+		if err != nil {
+			log.Fatalf("Migration failed after %d applied: %v", ran, err)
+		}
+		log.Printf("Applied %d migration(s)", ran)
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				usage()
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		if err != nil {
+			log.Fatalf("Rollback failed after %d rolled back: %v", rolledBack, err)
+		}
+		log.Printf("Rolled back %d migration(s)", rolledBack)
+
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Could not read migration status: %v", err)
+		}
+		for _, m := range status.Migrations {
+			state := "pending"
+			if m.Applied {
+				state = "applied " + m.AppliedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%-28s %-24s %s\n", m.ID, state, m.Description)
+		}
+		for _, id := range status.Unknown {
+			fmt.Printf("%-28s %-24s %s\n", id, "unknown", "applied by a newer build")
+		}
+		for _, d := range status.Drift {
+			fmt.Println("drift:", d)
+		}
+		if status.Pending > 0 || len(status.Unknown) > 0 || len(status.Drift) > 0 {
+			os.Exit(1)
+		}
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up | down [n] | status")
+	os.Exit(2)
+}
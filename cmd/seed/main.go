@@ -0,0 +1,47 @@
+package main
+
+// Command seed bulk-generates universities, departments, users, teams and proposals for
+// performance testing of the list endpoints. Migrations must be applied first.
+//
+//	go run ./cmd/seed -universities 3 -departments 10 -teams 200
+//	go run ./cmd/seed -teams 500 -files        also write a PDF for every proposal version
+//
+// Reruns with the same sizes are no-ops; larger sizes add what is missing.
+
+import (
+	"backend/config"
+	"backend/pkg/database"
+	"flag"
+	"log"
+)
+
+func main() {
+	opts := database.BulkSeedOptions{UploadDir: "./uploads"}
+	flag.IntVar(&opts.Universities, "universities", 1, "universities to generate")
+	flag.IntVar(&opts.DepartmentsPerUniversity, "departments", 5, "departments per university")
+	flag.IntVar(&opts.AdvisorsPerDept, "advisors", 10, "advisors per department")
+	flag.IntVar(&opts.TeamsPerDept, "teams", 100, "teams per department, each with a proposal unless still forming")
+	flag.IntVar(&opts.MembersPerTeam, "members", 4, "students per team")
+	flag.BoolVar(&opts.Files, "files", false, "write a PDF document for every proposal version")
+	flag.Int64Var(&opts.Seed, "seed", 0, "random seed; change it to generate a different dataset")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("Could not load config: %v", err)
+	}
+	if cfg.IsProduction() {
+		log.Fatalf("Refusing to bulk seed a production database")
+	}
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Could not connect to database: %v", err)
+	}
+	if err := database.NewMigrator(db).EnsureReady(false); err != nil {
+		log.Fatalf("Database is not ready: %v", err)
+	}
+
+	if err := database.SeedBulk(db, opts); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+}
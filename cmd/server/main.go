@@ -14,9 +14,18 @@ import (
 	"backend/config"
 	"backend/docs"
 	"backend/internal/app"
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout bounds how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	// 1. Load configuration
 	cfg, err := config.LoadConfig(".")
@@ -38,12 +47,40 @@ func main() {
 		log.Fatalf("Bootstrap failed: %v", err)
 	}
 
+	// 2.1 Start background jobs
+	application.Scheduler.Start()
+	application.AIJobQueue.Start()
+
 	// 3. Setup Router with full app context
 	r := app.NewRouter(application)
 
 	// 4. Start Server
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Server failed to run: %v", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed to run: %v", err)
+		}
+	}()
+	<-ctx.Done()
+
+	// 5. Shut down: stop taking requests, stop the jobs that publish events, let the event
+	// handlers finish their notifications and audit entries, then close the database
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+	application.Scheduler.Stop()
+	application.AIJobQueue.Stop()
+	application.EventBus.Wait()
+	application.RealtimeHub.Close()
+	if sqlDB, err := application.DB.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("Closing the database: %v", err)
+		}
 	}
 }
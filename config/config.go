@@ -1,7 +1,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -14,22 +21,257 @@ type Config struct {
 	DBPassword      string `mapstructure:"DB_PASSWORD"`
 	DBName          string `mapstructure:"DB_NAME"`
 	DBSSLMode       string `mapstructure:"DB_SSLMODE"`
+	DBAutoMigrate   bool   `mapstructure:"DB_AUTO_MIGRATE"` // apply pending migrations at startup
 	JWTSecret       string `mapstructure:"JWT_SECRET"`
 	Environment     string `mapstructure:"ENVIRONMENT"`
 	AIServiceURL    string `mapstructure:"AI_SERVICE_URL"`
 	AIServiceAPIKey string `mapstructure:"AI_SERVICE_API_KEY"`
+
+	// AI client resilience
+	AIMaxRetries             int `mapstructure:"AI_MAX_RETRIES"`
+	AIAttemptTimeoutSeconds  int `mapstructure:"AI_ATTEMPT_TIMEOUT_SECONDS"`
+	AIBreakerThreshold       int `mapstructure:"AI_BREAKER_THRESHOLD"`
+	AIBreakerCooldownSeconds int `mapstructure:"AI_BREAKER_COOLDOWN_SECONDS"`
+
+	// Upload quotas in megabytes; 0 means unlimited
+	TeamStorageQuotaMB int `mapstructure:"TEAM_STORAGE_QUOTA_MB"`
+	UserStorageQuotaMB int `mapstructure:"USER_STORAGE_QUOTA_MB"`
+
+	// Seed data: none, minimal (default accounts), demo (realistic dataset) or load-test (demo at scale)
+	SeedProfile string `mapstructure:"SEED_PROFILE"`
+
+	// Outgoing email; notifications are only emailed when SMTP_HOST is set
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	EmailFrom    string `mapstructure:"EMAIL_FROM"`
+	// Web app address links in emails point to, e.g. email change confirmations
+	AppURL string `mapstructure:"APP_URL"`
+
+	// Refuse project shares without a signed token from GET /projects/public/{id}/share-token
+	ShareTokensRequired bool `mapstructure:"SHARE_TOKENS_REQUIRED"`
+
+	// Directory uploaded files are stored in and served from as /uploads; private uploads sit beside it
+	UploadDir string `mapstructure:"UPLOAD_DIR"`
+
+	// Directory old proposal version files are compressed into by the retention policy
+	ColdStorageDir string `mapstructure:"COLD_STORAGE_DIR"`
+
+	// Reject writes with 503 except from system admins, regardless of the flag set at /admin/maintenance
+	MaintenanceMode bool `mapstructure:"MAINTENANCE_MODE"`
+
+	// Sources lists the layers that were applied, lowest precedence first
+	Sources []string `mapstructure:"-"`
+}
+
+// MinJWTSecretLength is the shortest signing secret accepted at startup
+const MinJWTSecretLength = 32
+
+var defaults = map[string]string{
+	"PORT":               "8080",
+	"DB_HOST":            "localhost",
+	"DB_PORT":            "5432",
+	"DB_USER":            "",
+	"DB_PASSWORD":        "",
+	"DB_NAME":            "",
+	"DB_SSLMODE":         "disable",
+	"DB_AUTO_MIGRATE":    "true",
+	"JWT_SECRET":         "",
+	"ENVIRONMENT":        "development",
+	"AI_SERVICE_URL":     "",
+	"AI_SERVICE_API_KEY": "",
+
+	"AI_MAX_RETRIES":              "2",
+	"AI_ATTEMPT_TIMEOUT_SECONDS":  "20",
+	"AI_BREAKER_THRESHOLD":        "5",
+	"AI_BREAKER_COOLDOWN_SECONDS": "30",
+
+	"TEAM_STORAGE_QUOTA_MB": "200",
+	"USER_STORAGE_QUOTA_MB": "100",
+
+	"SEED_PROFILE": "minimal",
+
+	"SMTP_HOST":     "",
+	"SMTP_PORT":     "587",
+	"SMTP_USERNAME": "",
+	"SMTP_PASSWORD": "",
+	"EMAIL_FROM":    "",
+	"APP_URL":       "http://localhost:3000",
+
+	"SHARE_TOKENS_REQUIRED": "false",
+	"UPLOAD_DIR":            "./uploads",
+	"COLD_STORAGE_DIR":      "./cold_storage",
+	"MAINTENANCE_MODE":      "false",
 }
 
+// LoadConfig builds the configuration from layered sources, later layers overriding earlier ones:
+//  1. built-in defaults
+//  2. config.yaml in path (or the file named by CONFIG_FILE)
+//  3. .env in path
+//  4. process environment variables
+//
+// The result is validated; startup should abort on error.
 func LoadConfig(path string) (config Config, err error) {
-	viper.SetConfigFile(".env")
+	v := viper.New()
 
-	viper.AutomaticEnv()
+	// Registering every key as a default also lets AutomaticEnv pick it up on Unmarshal
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	config.Sources = append(config.Sources, "defaults")
+
+	yamlFile := os.Getenv("CONFIG_FILE")
+	if yamlFile == "" {
+		yamlFile = filepath.Join(path, "config.yaml")
+	}
+	if merged, mergeErr := mergeFile(v, yamlFile, "yaml"); mergeErr != nil {
+		return config, mergeErr
+	} else if merged {
+		config.Sources = append(config.Sources, yamlFile)
+	}
 
-	err = viper.ReadInConfig()
-	if err != nil {
+	envFile := filepath.Join(path, ".env")
+	if merged, mergeErr := mergeFile(v, envFile, "env"); mergeErr != nil {
+		return config, mergeErr
+	} else if merged {
+		config.Sources = append(config.Sources, envFile)
+	} else {
 		log.Printf("Warning: .env file not found, using environment variables")
 	}
 
-	err = viper.Unmarshal(&config)
+	v.AutomaticEnv()
+	config.Sources = append(config.Sources, "environment")
+
+	if err = v.Unmarshal(&config); err != nil {
+		return config, err
+	}
+
+	err = config.Validate()
 	return
 }
+
+// mergeFile merges an optional config file; a missing file is not an error
+func mergeFile(v *viper.Viper, file string, format string) (bool, error) {
+	if _, err := os.Stat(file); err != nil {
+		return false, nil
+	}
+
+	v.SetConfigFile(file)
+	v.SetConfigType(format)
+	if err := v.MergeInConfig(); err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return true, nil
+}
+
+// Validate checks required settings and reports every problem at once
+func (c Config) Validate() error {
+	var problems []string
+
+	if len(c.JWTSecret) < MinJWTSecretLength {
+		problems = append(problems, fmt.Sprintf("JWT_SECRET must be at least %d characters", MinJWTSecretLength))
+	}
+
+	required := [][2]string{{"DB_HOST", c.DBHost}, {"DB_PORT", c.DBPort}, {"DB_USER", c.DBUser}, {"DB_NAME", c.DBName}}
+	for _, field := range required {
+		if strings.TrimSpace(field[1]) == "" {
+			problems = append(problems, field[0]+" is required")
+		}
+	}
+	if c.DBPort != "" {
+		if _, err := strconv.Atoi(c.DBPort); err != nil {
+			problems = append(problems, "DB_PORT must be a number")
+		}
+	}
+
+	if c.AIServiceURL != "" {
+		u, err := url.Parse(c.AIServiceURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, "AI_SERVICE_URL must be an absolute http(s) URL")
+		}
+	} else if c.IsProduction() {
+		problems = append(problems, "AI_SERVICE_URL is required in production")
+	}
+	if c.AIMaxRetries < 0 {
+		problems = append(problems, "AI_MAX_RETRIES must not be negative")
+	}
+	if c.AIAttemptTimeoutSeconds <= 0 || c.AIBreakerThreshold <= 0 || c.AIBreakerCooldownSeconds <= 0 {
+		problems = append(problems, "AI_ATTEMPT_TIMEOUT_SECONDS, AI_BREAKER_THRESHOLD and AI_BREAKER_COOLDOWN_SECONDS must be positive")
+	}
+	if c.TeamStorageQuotaMB < 0 || c.UserStorageQuotaMB < 0 {
+		problems = append(problems, "TEAM_STORAGE_QUOTA_MB and USER_STORAGE_QUOTA_MB must not be negative")
+	}
+	switch c.SeedProfile {
+	case "none", "minimal", "demo", "load-test":
+	default:
+		problems = append(problems, "SEED_PROFILE must be one of none, minimal, demo, load-test")
+	}
+	if c.IsProduction() && (c.SeedProfile == "demo" || c.SeedProfile == "load-test") {
+		problems = append(problems, "SEED_PROFILE "+c.SeedProfile+" is not allowed in production")
+	}
+
+	if u, err := url.Parse(c.AppURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, "APP_URL must be an absolute http(s) URL")
+	}
+	if c.SMTPHost != "" {
+		if _, err := strconv.Atoi(c.SMTPPort); err != nil {
+			problems = append(problems, "SMTP_PORT must be a number")
+		}
+		if !strings.Contains(c.EmailFrom, "@") {
+			problems = append(problems, "EMAIL_FROM is required when SMTP_HOST is set")
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+}
+
+func (c Config) IsProduction() bool {
+	return strings.EqualFold(c.Environment, "production")
+}
+
+// Redacted returns the effective configuration with secrets masked, for diagnostics
+func (c Config) Redacted() map[string]interface{} {
+	return map[string]interface{}{
+		"PORT":                        c.Port,
+		"ENVIRONMENT":                 c.Environment,
+		"DB_HOST":                     c.DBHost,
+		"DB_PORT":                     c.DBPort,
+		"DB_USER":                     c.DBUser,
+		"DB_PASSWORD":                 redact(c.DBPassword),
+		"DB_NAME":                     c.DBName,
+		"DB_SSLMODE":                  c.DBSSLMode,
+		"DB_AUTO_MIGRATE":             c.DBAutoMigrate,
+		"JWT_SECRET":                  redact(c.JWTSecret),
+		"AI_SERVICE_URL":              c.AIServiceURL,
+		"AI_SERVICE_API_KEY":          redact(c.AIServiceAPIKey),
+		"AI_MAX_RETRIES":              c.AIMaxRetries,
+		"AI_ATTEMPT_TIMEOUT_SECONDS":  c.AIAttemptTimeoutSeconds,
+		"AI_BREAKER_THRESHOLD":        c.AIBreakerThreshold,
+		"AI_BREAKER_COOLDOWN_SECONDS": c.AIBreakerCooldownSeconds,
+		"TEAM_STORAGE_QUOTA_MB":       c.TeamStorageQuotaMB,
+		"USER_STORAGE_QUOTA_MB":       c.UserStorageQuotaMB,
+		"SEED_PROFILE":                c.SeedProfile,
+		"SMTP_HOST":                   c.SMTPHost,
+		"SMTP_PORT":                   c.SMTPPort,
+		"SMTP_USERNAME":               c.SMTPUsername,
+		"SMTP_PASSWORD":               redact(c.SMTPPassword),
+		"EMAIL_FROM":                  c.EmailFrom,
+		"APP_URL":                     c.AppURL,
+		"SHARE_TOKENS_REQUIRED":       c.ShareTokensRequired,
+		"UPLOAD_DIR":                  c.UploadDir,
+		"COLD_STORAGE_DIR":            c.ColdStorageDir,
+		"MAINTENANCE_MODE":            c.MaintenanceMode,
+		"sources":                     c.Sources,
+	}
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
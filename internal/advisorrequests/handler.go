@@ -0,0 +1,235 @@
+package advisorrequests
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// GetTeamQueue godoc
+// @Summary Get a team's advisor requests
+// @Description The team's latest ranked list of advisors with the state of each request: queued, pending (being asked), accepted, declined, skipped or withdrawn. state summarises the list as none, waiting, accepted, exhausted or withdrawn.
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=TeamQueue}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/advisor-requests [get]
+func (h *Handler) GetTeamQueue(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+
+	queue, err := h.service.GetTeamQueue(teamID, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondError(c, "Failed to fetch advisor requests", err)
+		return
+	}
+	response.Success(c, queue)
+}
+
+// SubmitPreferences godoc
+// @Summary Rank preferred advisors
+// @Description The team leader ranks up to three advisors of the department. They are asked one at a time in that order: a decline passes the request to the next preference, and the first to accept becomes the team's advisor. Advisors with an unresolved conflict of interest cannot be ranked. A new list can be submitted once the previous one is exhausted or withdrawn.
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body SubmitPreferencesRequest true "Advisor IDs, most preferred first"
+// @Success 201 {object} response.Response{data=TeamQueue}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /teams/{id}/advisor-requests [post]
+func (h *Handler) SubmitPreferences(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+
+	var req SubmitPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	queue, err := h.service.SubmitPreferences(teamID, req, claims.UserID)
+	if err != nil {
+		respondError(c, "Failed to request advisors", err)
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Advisor preferences submitted", queue)
+}
+
+// WithdrawPreferences godoc
+// @Summary Withdraw the team's advisor requests
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=TeamQueue}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /teams/{id}/advisor-requests [delete]
+func (h *Handler) WithdrawPreferences(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+
+	queue, err := h.service.WithdrawPreferences(teamID, claims.UserID)
+	if err != nil {
+		respondError(c, "Failed to withdraw advisor requests", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Advisor requests withdrawn", queue)
+}
+
+// GetMyRequests godoc
+// @Summary List teams asking me to advise them
+// @Tags Advisor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]domain.AdvisorRequest}
+// @Router /advisor/team-requests [get]
+func (h *Handler) GetMyRequests(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	requests, err := h.service.GetMyRequests(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch advisor requests", err.Error())
+		return
+	}
+	response.Success(c, requests)
+}
+
+// Respond godoc
+// @Summary Accept or decline a team's advisor request
+// @Description Accepting makes the advisor the team's advisor and requires a conflict-of-interest declaration for the team without an unresolved conflict. Declining passes the request to the team's next preference.
+// @Tags Advisor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Advisor request ID"
+// @Param request body RespondRequest true "Decision"
+// @Success 200 {object} response.Response{data=domain.AdvisorRequest}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /advisor/team-requests/{id}/respond [post]
+func (h *Handler) Respond(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	requestID := parseID(c, "id")
+	if requestID == 0 {
+		return
+	}
+
+	var req RespondRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	request, err := h.service.Respond(requestID, req, claims.UserID)
+	if err != nil {
+		respondError(c, "Failed to answer advisor request", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Response recorded", request)
+}
+
+// GetDepartmentQueues godoc
+// @Summary List the department's advisor request queues
+// @Description Each team's latest ranked list of advisors and where it stands
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param state query string false "Only lists in this state: waiting, accepted, exhausted or withdrawn"
+// @Success 200 {object} response.Response{data=[]TeamQueue}
+// @Router /admin/advisor-requests [get]
+func (h *Handler) GetDepartmentQueues(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	state := c.Query("state")
+	switch state {
+	case "", QueueWaiting, QueueAccepted, QueueExhausted, QueueWithdrawn:
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid state", "state must be one of waiting, accepted, exhausted, withdrawn")
+		return
+	}
+
+	queues, err := h.service.GetDepartmentQueues(claims.DepartmentID, state)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch advisor requests", err.Error())
+		return
+	}
+	response.Success(c, queues)
+}
+
+func respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, ErrTeamNotFound), errors.Is(err, ErrRequestNotFound):
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrNotLeader):
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrTeamArchived), errors.Is(err, ErrHasAdvisor), errors.Is(err, ErrQueueOpen), errors.Is(err, ErrNoOpenQueue):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	default:
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	}
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseID(c *gin.Context, param string) uint {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return 0
+	}
+	return uint(id)
+}
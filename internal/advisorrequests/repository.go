@@ -0,0 +1,145 @@
+package advisorrequests
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateRound(requests []domain.AdvisorRequest) error
+	Save(request *domain.AdvisorRequest) error
+	GetByID(id uint) (*domain.AdvisorRequest, error)
+	// GetLatestRound returns the team's most recent list in rank order; empty when it has none
+	GetLatestRound(teamID uint) ([]domain.AdvisorRequest, error)
+	GetPendingForAdvisor(advisorID uint) ([]domain.AdvisorRequest, error)
+	// GetDepartmentRounds returns the latest list of every team in the department
+	GetDepartmentRounds(departmentID uint) ([]domain.AdvisorRequest, error)
+	// Accept records the acceptance, withdraws the rest of the round and makes the advisor the
+	// team's advisor. It fails with ErrHasAdvisor when the team got an advisor in the meantime.
+	Accept(request *domain.AdvisorRequest) error
+	WithdrawRound(teamID uint, round int, reason string) error
+
+	GetTeam(id uint) (*domain.Team, error)
+	GetUser(id uint) (*domain.User, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateRound(requests []domain.AdvisorRequest) error {
+	return r.db.Omit("Advisor", "Team").Create(&requests).Error
+}
+
+func (r *repository) Save(request *domain.AdvisorRequest) error {
+	return r.db.Omit("Advisor", "Team").Save(request).Error
+}
+
+func (r *repository) GetByID(id uint) (*domain.AdvisorRequest, error) {
+	var request domain.AdvisorRequest
+	if err := r.db.Preload("Advisor").Preload("Team").First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *repository) GetLatestRound(teamID uint) ([]domain.AdvisorRequest, error) {
+	var requests []domain.AdvisorRequest
+	err := r.db.Preload("Advisor").
+		Where("team_id = ? AND round = (?)", teamID,
+			r.db.Model(&domain.AdvisorRequest{}).Select("MAX(round)").Where("team_id = ?", teamID)).
+		Order("rank ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *repository) GetPendingForAdvisor(advisorID uint) ([]domain.AdvisorRequest, error) {
+	var requests []domain.AdvisorRequest
+	err := r.db.Preload("Team").Preload("Team.Members").Preload("Team.Members.User").
+		Where("advisor_id = ? AND status = ?", advisorID, enums.AdvisorRequestStatusPending).
+		Order("sent_at ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *repository) GetDepartmentRounds(departmentID uint) ([]domain.AdvisorRequest, error) {
+	var requests []domain.AdvisorRequest
+	latest := r.db.Model(&domain.AdvisorRequest{}).
+		Select("team_id, MAX(round)").
+		Where("department_id = ?", departmentID).
+		Group("team_id")
+	err := r.db.Preload("Advisor").Preload("Team").
+		Where("department_id = ? AND (team_id, round) IN (?)", departmentID, latest).
+		Order("team_id ASC, rank ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *repository) Accept(request *domain.AdvisorRequest) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Team{}).
+			Where("id = ? AND advisor_id IS NULL", request.TeamID).
+			Update("advisor_id", request.AdvisorID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrHasAdvisor
+		}
+
+		res = tx.Model(&domain.AdvisorRequest{}).
+			Where("id = ? AND status = ?", request.ID, enums.AdvisorRequestStatusPending).
+			Updates(map[string]interface{}{
+				"status":       enums.AdvisorRequestStatusAccepted,
+				"comment":      request.Comment,
+				"responded_at": request.RespondedAt,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRequestNotFound
+		}
+
+		return tx.Model(&domain.AdvisorRequest{}).
+			Where("team_id = ? AND round = ? AND status = ?", request.TeamID, request.Round, enums.AdvisorRequestStatusQueued).
+			Updates(map[string]interface{}{
+				"status":  enums.AdvisorRequestStatusWithdrawn,
+				"comment": "another preference accepted",
+			}).Error
+	})
+}
+
+func (r *repository) WithdrawRound(teamID uint, round int, reason string) error {
+	return r.db.Model(&domain.AdvisorRequest{}).
+		Where("team_id = ? AND round = ? AND status IN ?", teamID, round,
+			[]enums.AdvisorRequestStatus{enums.AdvisorRequestStatusQueued, enums.AdvisorRequestStatusPending}).
+		Updates(map[string]interface{}{
+			"status":       enums.AdvisorRequestStatusWithdrawn,
+			"comment":      reason,
+			"responded_at": time.Now(),
+		}).Error
+}
+
+func (r *repository) GetTeam(id uint) (*domain.Team, error) {
+	var team domain.Team
+	if err := r.db.Preload("Members").Preload("Members.User").First(&team, id).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,475 @@
+package advisorrequests
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxPreferences is how many advisors a team may rank in one list
+const MaxPreferences = 3
+
+var (
+	ErrTeamNotFound    = errors.New("team not found")
+	ErrRequestNotFound = errors.New("advisor request not found")
+	ErrForbidden       = errors.New("you do not have permission to view this team's advisor requests")
+	ErrNotLeader       = errors.New("only the team leader can manage advisor requests")
+	ErrTeamArchived    = errors.New("the team is archived")
+	ErrHasAdvisor      = errors.New("the team already has an advisor")
+	ErrQueueOpen       = errors.New("the team is still waiting on its advisor requests; withdraw them first")
+	ErrNoOpenQueue     = errors.New("the team has no advisor requests waiting for an answer")
+)
+
+// Queue states, derived from the requests of a team's latest list
+const (
+	QueueNone      = "none"      // the team has not ranked any advisors
+	QueueWaiting   = "waiting"   // an advisor is being asked
+	QueueAccepted  = "accepted"  // a preference accepted and became the team's advisor
+	QueueExhausted = "exhausted" // every preference declined or was skipped
+	QueueWithdrawn = "withdrawn" // the leader withdrew the list
+)
+
+// AdvisorChecks looks up advisors' conflict of interest declarations and the department's
+// advisor quotas; implemented by the proposal service
+type AdvisorChecks interface {
+	GetConflictDeclaration(advisorID uint, teamID uint) (*domain.ConflictDeclaration, error)
+	CheckTeamQuota(team *domain.Team, advisorID uint) error
+}
+
+type Service struct {
+	repo     Repository
+	bus      *events.Bus
+	advisors AdvisorChecks
+}
+
+func NewService(repo Repository, bus *events.Bus, advisors AdvisorChecks) *Service {
+	return &Service{repo: repo, bus: bus, advisors: advisors}
+}
+
+// SubmitPreferencesRequest ranks advisors from most to least preferred
+type SubmitPreferencesRequest struct {
+	AdvisorIDs []uint `json:"advisor_ids" binding:"required,min=1,max=3" example:"4,9,2"`
+}
+
+type RespondRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=accept decline" example:"accept"`
+	Comment  string `json:"comment" example:"Happy to supervise an IoT project"`
+}
+
+// TeamQueue is a team's latest list of advisor preferences and where it stands
+type TeamQueue struct {
+	TeamID   uint                    `json:"team_id"`
+	TeamName string                  `json:"team_name"`
+	Round    int                     `json:"round"`
+	State    string                  `json:"state"`
+	Current  *domain.AdvisorRequest  `json:"current,omitempty"` // the preference being asked, or the one that accepted
+	Requests []domain.AdvisorRequest `json:"requests"`
+}
+
+// SubmitPreferences starts a new list for the team and asks the first preference. Every advisor
+// must belong to the team's department and must not have an unresolved conflict with the team.
+func (s *Service) SubmitPreferences(teamID uint, req SubmitPreferencesRequest, userID uint) (*TeamQueue, error) {
+	team, err := s.leaderTeam(teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if team.AdvisorID != nil {
+		return nil, ErrHasAdvisor
+	}
+	if len(req.AdvisorIDs) == 0 || len(req.AdvisorIDs) > MaxPreferences {
+		return nil, fmt.Errorf("rank between 1 and %d advisors", MaxPreferences)
+	}
+
+	latest, err := s.repo.GetLatestRound(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch queueState(latest) {
+	case QueueWaiting:
+		return nil, ErrQueueOpen
+	case QueueAccepted:
+		return nil, ErrHasAdvisor
+	}
+	round := 1
+	if len(latest) > 0 {
+		round = latest[0].Round + 1
+	}
+
+	seen := make(map[uint]bool, len(req.AdvisorIDs))
+	requests := make([]domain.AdvisorRequest, 0, len(req.AdvisorIDs))
+	for i, advisorID := range req.AdvisorIDs {
+		if seen[advisorID] {
+			return nil, errors.New("each advisor can be ranked only once")
+		}
+		seen[advisorID] = true
+		if reason := s.ineligible(team, advisorID); reason != "" {
+			return nil, fmt.Errorf("preference %d: %s", i+1, reason)
+		}
+		requests = append(requests, domain.AdvisorRequest{
+			TeamID:       team.ID,
+			Round:        round,
+			Rank:         i + 1,
+			AdvisorID:    advisorID,
+			DepartmentID: team.DepartmentID,
+			Status:       enums.AdvisorRequestStatusQueued,
+			RequestedBy:  userID,
+		})
+	}
+	if err := s.repo.CreateRound(requests); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.askNext(team, requests); err != nil {
+		return nil, err
+	}
+	return s.teamQueue(team)
+}
+
+// WithdrawPreferences cancels the team's list while it is still waiting for an answer
+func (s *Service) WithdrawPreferences(teamID uint, userID uint) (*TeamQueue, error) {
+	team, err := s.leaderTeam(teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.repo.GetLatestRound(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	if queueState(latest) != QueueWaiting {
+		return nil, ErrNoOpenQueue
+	}
+	if err := s.repo.WithdrawRound(team.ID, latest[0].Round, "withdrawn by the team"); err != nil {
+		return nil, err
+	}
+	return s.teamQueue(team)
+}
+
+// GetTeamQueue shows the team's latest list to its members, its advisor, the advisors on the
+// list and the department admin
+func (s *Service) GetTeamQueue(teamID uint, userID uint, role enums.Role, departmentID uint) (*TeamQueue, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, ErrTeamNotFound
+	}
+	queue, err := s.teamQueue(team)
+	if err != nil {
+		return nil, err
+	}
+	if canView(team, queue.Requests, userID, role, departmentID) {
+		return queue, nil
+	}
+	return nil, ErrForbidden
+}
+
+// GetMyRequests lists the teams waiting for the advisor's answer, oldest first
+func (s *Service) GetMyRequests(advisorID uint) ([]domain.AdvisorRequest, error) {
+	return s.repo.GetPendingForAdvisor(advisorID)
+}
+
+// GetDepartmentQueues lists the latest list of each team in the department, optionally only
+// those in the given state
+func (s *Service) GetDepartmentQueues(departmentID uint, state string) ([]TeamQueue, error) {
+	requests, err := s.repo.GetDepartmentRounds(departmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	queues := make([]TeamQueue, 0)
+	for start := 0; start < len(requests); {
+		end := start
+		for end < len(requests) && requests[end].TeamID == requests[start].TeamID {
+			end++
+		}
+		teamName := ""
+		if requests[start].Team != nil {
+			teamName = requests[start].Team.Name
+		}
+		queue := buildQueue(requests[start].TeamID, teamName, requests[start:end])
+		if state == "" || queue.State == state {
+			queues = append(queues, queue)
+		}
+		start = end
+	}
+	return queues, nil
+}
+
+// Respond records the advisor's answer. Accepting makes them the team's advisor and withdraws
+// the remaining preferences; declining asks the next preference.
+func (s *Service) Respond(requestID uint, req RespondRequest, advisorID uint) (*domain.AdvisorRequest, error) {
+	request, err := s.repo.GetByID(requestID)
+	if err != nil || request.AdvisorID != advisorID || request.Status != enums.AdvisorRequestStatusPending {
+		return nil, ErrRequestNotFound
+	}
+	team, err := s.repo.GetTeam(request.TeamID)
+	if err != nil {
+		return nil, ErrTeamNotFound
+	}
+
+	now := time.Now()
+	request.Comment = strings.TrimSpace(req.Comment)
+	request.RespondedAt = &now
+
+	if req.Decision == "accept" {
+		// Advisors declare conflicts of interest before accepting a team
+		declaration, err := s.advisors.GetConflictDeclaration(advisorID, team.ID)
+		if err != nil {
+			return nil, err
+		}
+		if declaration == nil {
+			return nil, errors.New("declare any conflict of interest with this team before accepting it")
+		}
+		if declaration.HasConflict && declaration.OverriddenAt == nil {
+			return nil, errors.New("cannot accept: you declared a conflict of interest that the department head has not overridden")
+		}
+		if team.AdvisorID != nil {
+			return nil, ErrHasAdvisor
+		}
+		// The quotas may have filled up since the team ranked the advisor
+		if err := s.advisors.CheckTeamQuota(team, advisorID); err != nil {
+			return nil, err
+		}
+
+		if err := s.repo.Accept(request); err != nil {
+			return nil, err
+		}
+		s.bus.Publish(events.Event{
+			Name:       events.AdvisorRequestAccepted,
+			EntityType: "team",
+			EntityID:   team.ID,
+			ActorID:    advisorID,
+			UserIDs:    memberIDs(team),
+			Data: map[string]interface{}{
+				"team_name":    team.Name,
+				"advisor_name": advisorName(request),
+			},
+		})
+		return s.repo.GetByID(request.ID)
+	}
+
+	request.Status = enums.AdvisorRequestStatusDeclined
+	if err := s.repo.Save(request); err != nil {
+		return nil, err
+	}
+	round, err := s.repo.GetLatestRound(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	next, err := s.askNext(team, round)
+	if err != nil {
+		return nil, err
+	}
+	// When no preference is left the leader hears that the list is exhausted instead
+	if next != nil {
+		s.bus.Publish(events.Event{
+			Name:       events.AdvisorRequestDeclined,
+			EntityType: "team",
+			EntityID:   team.ID,
+			ActorID:    advisorID,
+			UserIDs:    leaderIDs(team),
+			Data: map[string]interface{}{
+				"team_name":         team.Name,
+				"advisor_name":      advisorName(request),
+				"next_advisor_name": advisorName(next),
+			},
+		})
+	}
+	return s.repo.GetByID(request.ID)
+}
+
+// askNext asks the best-ranked queued preference, skipping advisors who can no longer take the
+// team. It returns nil and tells the leaders when no preference is left.
+func (s *Service) askNext(team *domain.Team, requests []domain.AdvisorRequest) (*domain.AdvisorRequest, error) {
+	for i := range requests {
+		request := &requests[i]
+		if request.Status != enums.AdvisorRequestStatusQueued {
+			continue
+		}
+
+		now := time.Now()
+		if reason := s.ineligible(team, request.AdvisorID); reason != "" {
+			request.Status = enums.AdvisorRequestStatusSkipped
+			request.Comment = reason
+			request.RespondedAt = &now
+			if err := s.repo.Save(request); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		request.Status = enums.AdvisorRequestStatusPending
+		request.SentAt = &now
+		if err := s.repo.Save(request); err != nil {
+			return nil, err
+		}
+		s.bus.Publish(events.Event{
+			Name:       events.AdvisorRequested,
+			EntityType: "team",
+			EntityID:   team.ID,
+			ActorID:    request.RequestedBy,
+			UserIDs:    []uint{request.AdvisorID},
+			Data: map[string]interface{}{
+				"team_name":  team.Name,
+				"request_id": request.ID,
+				"rank":       request.Rank,
+			},
+		})
+		return request, nil
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.AdvisorListExhausted,
+		EntityType: "team",
+		EntityID:   team.ID,
+		UserIDs:    leaderIDs(team),
+		Data: map[string]interface{}{
+			"team_name": team.Name,
+		},
+	})
+	return nil, nil
+}
+
+// ineligible explains why the advisor cannot be asked to take the team, or returns ""
+func (s *Service) ineligible(team *domain.Team, advisorID uint) string {
+	advisor, err := s.repo.GetUser(advisorID)
+	if err != nil || advisor.Role != enums.RoleAdvisor || advisor.DepartmentID != team.DepartmentID {
+		return "not an advisor of the team's department"
+	}
+	if !advisor.IsActive {
+		return advisor.Name + " is no longer active"
+	}
+	declaration, err := s.advisors.GetConflictDeclaration(advisorID, team.ID)
+	if err != nil {
+		return "conflict of interest declarations could not be checked"
+	}
+	if declaration != nil && declaration.HasConflict && declaration.OverriddenAt == nil {
+		return advisor.Name + " has declared a conflict of interest with this team"
+	}
+	return ""
+}
+
+// leaderTeam loads a team whose advisor requests the user, as its leader, may manage
+func (s *Service) leaderTeam(teamID uint, userID uint) (*domain.Team, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, ErrTeamNotFound
+	}
+	if !isLeader(team, userID) {
+		return nil, ErrNotLeader
+	}
+	if team.IsArchived {
+		return nil, ErrTeamArchived
+	}
+	return team, nil
+}
+
+func (s *Service) teamQueue(team *domain.Team) (*TeamQueue, error) {
+	requests, err := s.repo.GetLatestRound(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	queue := buildQueue(team.ID, team.Name, requests)
+	return &queue, nil
+}
+
+func buildQueue(teamID uint, teamName string, requests []domain.AdvisorRequest) TeamQueue {
+	queue := TeamQueue{
+		TeamID:   teamID,
+		TeamName: teamName,
+		State:    queueState(requests),
+		Requests: requests,
+	}
+	if queue.Requests == nil {
+		queue.Requests = []domain.AdvisorRequest{}
+	}
+	for i := range queue.Requests {
+		switch queue.Requests[i].Status {
+		case enums.AdvisorRequestStatusPending, enums.AdvisorRequestStatusAccepted:
+			queue.Current = &queue.Requests[i]
+		}
+	}
+	if len(requests) > 0 {
+		queue.Round = requests[0].Round
+	}
+	return queue
+}
+
+func queueState(requests []domain.AdvisorRequest) string {
+	if len(requests) == 0 {
+		return QueueNone
+	}
+	withdrawn := false
+	for _, r := range requests {
+		switch r.Status {
+		case enums.AdvisorRequestStatusAccepted:
+			return QueueAccepted
+		case enums.AdvisorRequestStatusPending, enums.AdvisorRequestStatusQueued:
+			return QueueWaiting
+		case enums.AdvisorRequestStatusWithdrawn:
+			withdrawn = true
+		}
+	}
+	if withdrawn {
+		return QueueWithdrawn
+	}
+	return QueueExhausted
+}
+
+func canView(team *domain.Team, requests []domain.AdvisorRequest, userID uint, role enums.Role, departmentID uint) bool {
+	if role == enums.RoleAdmin && team.DepartmentID == departmentID {
+		return true
+	}
+	if team.AdvisorID != nil && *team.AdvisorID == userID {
+		return true
+	}
+	for _, r := range requests {
+		if r.AdvisorID == userID {
+			return true
+		}
+	}
+	for _, m := range team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			return true
+		}
+	}
+	return false
+}
+
+func isLeader(team *domain.Team, userID uint) bool {
+	for _, m := range team.Members {
+		if m.UserID == userID && m.Role == "leader" {
+			return true
+		}
+	}
+	return false
+}
+
+func leaderIDs(team *domain.Team) []uint {
+	var ids []uint
+	for _, m := range team.Members {
+		if m.Role == "leader" {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+func memberIDs(team *domain.Team) []uint {
+	ids := make([]uint, 0, len(team.Members))
+	for _, m := range team.Members {
+		if m.InvitationStatus == enums.InvitationStatusAccepted {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+func advisorName(request *domain.AdvisorRequest) string {
+	if request.Advisor != nil {
+		return request.Advisor.Name
+	}
+	return "The advisor"
+}
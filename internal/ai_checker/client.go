@@ -17,23 +17,40 @@ type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	opts       ClientOptions
+	breaker    *breaker
+	cache      *resultCache
 }
 
-func NewClient(baseURL, apiKey string) *Client {
+func NewClient(baseURL, apiKey string, opts ClientOptions) *Client {
 	baseURL = strings.TrimRight(baseURL, "/")
+	opts = opts.withDefaults()
 	return &Client{
 		baseURL: baseURL,
 		apiKey:  apiKey,
+		// Per-attempt deadlines come from opts.AttemptTimeout; this is only a backstop
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: opts.AttemptTimeout + 5*time.Second,
 		},
+		opts:    opts,
+		breaker: &breaker{threshold: opts.BreakerThreshold, cooldown: opts.BreakerCooldown},
+		cache:   &resultCache{ttl: opts.CacheTTL, entries: make(map[string]cachedResult)},
 	}
 }
 
+// CircuitState reports the breaker state: closed, open or half_open
+func (c *Client) CircuitState() string {
+	return c.breaker.state()
+}
+
 func (c *Client) Health(ctx context.Context) error {
 	if c.baseURL == "" {
 		return errors.New("AI service URL is not configured")
 	}
+	// Health is a single probe and does not feed the breaker, but an open circuit is reported as-is
+	if c.breaker.state() == "open" {
+		return errCircuitOpen
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
 	if err != nil {
@@ -64,13 +81,14 @@ func (c *Client) CheckProposalText(ctx context.Context, payload ProposalCheckReq
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/predict/proposal-check", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, err
-	}
-	applyHeaders(req, "application/json", c.apiKey)
-
-	return c.doJSON(req)
+	return c.analyze(ctx, cacheKey("text", payload.Title, payload.Objectives, payload.Methodology), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/predict/proposal-check", bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, err
+		}
+		applyHeaders(req, "application/json", c.apiKey)
+		return req, nil
+	})
 }
 
 func (c *Client) CheckProposalFile(ctx context.Context, filename string, fileContent []byte) (map[string]interface{}, error) {
@@ -92,13 +110,17 @@ func (c *Client) CheckProposalFile(ctx context.Context, filename string, fileCon
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/predict/proposal-check-file", body)
-	if err != nil {
-		return nil, err
-	}
-	applyHeaders(req, writer.FormDataContentType(), c.apiKey)
+	contentType := writer.FormDataContentType()
+	payload := body.Bytes()
 
-	return c.doJSON(req)
+	return c.analyze(ctx, cacheKey("file", string(fileContent)), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/predict/proposal-check-file", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		applyHeaders(req, contentType, c.apiKey)
+		return req, nil
+	})
 }
 
 func (c *Client) SyncProjects(ctx context.Context, projects []SyncProject) error {
@@ -111,43 +133,71 @@ func (c *Client) SyncProjects(ctx context.Context, projects []SyncProject) error
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/sync-projects", bytes.NewBuffer(jsonBody))
+	_, err = c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/internal/sync-projects", bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, err
+		}
+		applyHeaders(req, "application/json", c.apiKey)
+		return req, nil
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("AI service sync failed: %w", err)
 	}
-	applyHeaders(req, "application/json", c.apiKey)
 
-	resp, err := c.httpClient.Do(req)
+	return nil
+}
+
+// SimilarProjects asks the similarity index for the projects closest to the given title and summary
+func (c *Client) SimilarProjects(ctx context.Context, title, summary string, limit int) ([]SimilarProject, error) {
+	if c.baseURL == "" {
+		return nil, errors.New("AI service URL is not configured")
+	}
+
+	jsonBody, err := json.Marshal(map[string]interface{}{"title": title, "summary": summary, "top_k": limit})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("AI service sync failed: %s", strings.TrimSpace(string(body)))
+	body, err := c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/predict/similar-projects", bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, err
+		}
+		applyHeaders(req, "application/json", c.apiKey)
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	var result struct {
+		Results []SimilarProject `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Results, nil
 }
 
-func (c *Client) doJSON(req *http.Request) (map[string]interface{}, error) {
-	resp, err := c.httpClient.Do(req)
+// analyze runs an analysis request with retries. When the service is down (retries exhausted or circuit open)
+// it returns a degraded result instead of an error so callers can still respond.
+func (c *Client) analyze(ctx context.Context, key string, newRequest func(ctx context.Context) (*http.Request, error)) (map[string]interface{}, error) {
+	body, err := c.do(ctx, newRequest)
 	if err != nil {
+		var retryable retryableError
+		if errors.Is(err, errCircuitOpen) || errors.As(err, &retryable) {
+			return c.degraded(key, err), nil
+		}
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("AI service error: %s", strings.TrimSpace(string(body)))
-	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
+	c.cache.put(key, result)
 	return result, nil
 }
 
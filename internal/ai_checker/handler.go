@@ -1,20 +1,25 @@
 package ai_checker
 
 import (
+	"backend/internal/auth"
 	"backend/pkg/response"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	client *Client
+	client   *Client
+	jobs     *JobQueue
+	settings *Settings
 }
 
 type ProposalCheckRequest struct {
-	Title      string `json:"title" binding:"required" example:"Project Title"`
-	Objectives string `json:"objectives" binding:"required" example:"Project objectives text"`
+	Title       string `json:"title" binding:"required" example:"Project Title"`
+	Objectives  string `json:"objectives" binding:"required" example:"Project objectives text"`
+	Methodology string `json:"methodology,omitempty" example:"Project methodology text"`
 }
 
 type SyncProject struct {
@@ -23,8 +28,21 @@ type SyncProject struct {
 	Summary string `json:"summary"`
 }
 
-func NewHandler(client *Client) *Handler {
-	return &Handler{client: client}
+// SimilarProject is a match returned by the AI similarity index
+type SimilarProject struct {
+	ID    uint    `json:"id"`
+	Score float64 `json:"score"`
+}
+
+type AnalyzeProposalRequest struct {
+	ProposalID  *uint  `json:"proposal_id"`
+	Title       string `json:"title"`
+	Objectives  string `json:"objectives"`
+	Methodology string `json:"methodology"`
+}
+
+func NewHandler(client *Client, jobs *JobQueue, settings *Settings) *Handler {
+	return &Handler{client: client, jobs: jobs, settings: settings}
 }
 
 // HealthCheck godoc
@@ -42,7 +60,7 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusOK, "AI service available", gin.H{"status": "ok"})
+	response.JSON(c, http.StatusOK, "AI service available", gin.H{"status": "ok", "circuit": h.client.CircuitState()})
 }
 
 // CheckProposalText godoc
@@ -55,9 +73,14 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 // @Param payload body ProposalCheckRequest true "Proposal content"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 502 {object} response.ErrorResponse
 // @Router /ai-checker/proposal-check [post]
 func (h *Handler) CheckProposalText(c *gin.Context) {
+	if !h.requireEnabled(c) {
+		return
+	}
+
 	var req ProposalCheckRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
@@ -83,9 +106,14 @@ func (h *Handler) CheckProposalText(c *gin.Context) {
 // @Param file formData file true "Proposal file"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 502 {object} response.ErrorResponse
 // @Router /ai-checker/proposal-check-file [post]
 func (h *Handler) CheckProposalFile(c *gin.Context) {
+	if !h.requireEnabled(c) {
+		return
+	}
+
 	file, err := c.FormFile("file")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "File is required", err.Error())
@@ -113,3 +141,98 @@ func (h *Handler) CheckProposalFile(c *gin.Context) {
 
 	response.Success(c, result)
 }
+
+// AnalyzeProposal godoc
+// @Summary Queue an AI proposal analysis
+// @Description Enqueues an analysis of an existing proposal (latest version) or of free text and returns immediately with a job ID. A proposal is analyzed with its methodology, taken from the version's form or, when that is empty, from the methodology section of its PDF. Poll GET /ai/jobs/{id}; the requester is notified when it finishes.
+// @Tags AI Checker
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body AnalyzeProposalRequest true "proposal_id, or title and objectives"
+// @Success 202 {object} response.Response{data=domain.AIJob}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /ai/analyze-proposal [post]
+func (h *Handler) AnalyzeProposal(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	if !h.requireEnabled(c) {
+		return
+	}
+
+	var req AnalyzeProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	job, err := h.jobs.Enqueue(AnalyzeInput{
+		ProposalID:  req.ProposalID,
+		Title:       req.Title,
+		Objectives:  req.Objectives,
+		Methodology: req.Methodology,
+	}, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		if err.Error() == "proposal not found" {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Failed to queue analysis", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusAccepted, "Analysis queued", job)
+}
+
+// GetJob godoc
+// @Summary Get AI analysis job
+// @Description Returns the status, progress and (when completed) result of an analysis job. Visible to the requester and admins of their department.
+// @Tags AI Checker
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Success 200 {object} response.Response{data=JobView}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /ai/jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	job, err := h.jobs.GetJob(id, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// Helpers
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseID(c *gin.Context) uint {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,281 @@
+package ai_checker
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/deadletter"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analysisTimeout bounds a single AI call made by a worker
+const analysisTimeout = 60 * time.Second
+
+// DeadLetterAnalysis is the dead letter kind of failed analysis jobs
+const DeadLetterAnalysis = "ai_analysis"
+
+// analysisDeadLetter is the stored payload of a failed analysis job
+type analysisDeadLetter struct {
+	JobID uint `json:"job_id"`
+}
+
+// JobQueue runs AI proposal analyses in background workers so requests never block on the AI service
+type JobQueue struct {
+	repo        Repository
+	client      *Client
+	bus         *events.Bus
+	deadLetters *deadletter.Queue
+	workers     int
+	queue       chan uint
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// AnalyzeInput is what a job analyzes: either an existing proposal or free text
+type AnalyzeInput struct {
+	ProposalID  *uint
+	Title       string
+	Objectives  string
+	Methodology string
+}
+
+// JobView is an AIJob with its decoded result
+type JobView struct {
+	*domain.AIJob
+	Result map[string]interface{} `json:"result,omitempty"`
+}
+
+func NewJobQueue(repo Repository, client *Client, bus *events.Bus, deadLetters *deadletter.Queue, workers int) *JobQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &JobQueue{
+		repo:        repo,
+		client:      client,
+		bus:         bus,
+		deadLetters: deadLetters,
+		workers:     workers,
+		queue:       make(chan uint, 100),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start launches the workers and re-enqueues jobs that were unfinished at shutdown
+func (q *JobQueue) Start() {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	ids, err := q.repo.GetUnfinishedJobIDs()
+	if err != nil {
+		log.Printf("failed to load unfinished AI jobs: %v", err)
+		return
+	}
+	for _, id := range ids {
+		q.push(id)
+	}
+	log.Printf("AI job queue started with %d worker(s), %d job(s) resumed", q.workers, len(ids))
+}
+
+// Stop lets in-flight jobs finish and stops the workers
+func (q *JobQueue) Stop() {
+	close(q.stop)
+	q.wg.Wait()
+}
+
+// Enqueue records a job and schedules it for processing
+func (q *JobQueue) Enqueue(input AnalyzeInput, userID uint, role enums.Role, departmentID uint) (*domain.AIJob, error) {
+	job := &domain.AIJob{
+		RequestedBy: userID,
+		ProposalID:  input.ProposalID,
+		Title:       input.Title,
+		Objectives:  input.Objectives,
+		Methodology: input.Methodology,
+		Status:      enums.AIJobStatusQueued,
+	}
+
+	if input.ProposalID != nil {
+		allowed, err := q.repo.CanAccessProposal(*input.ProposalID, userID, role, departmentID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("proposal not found")
+		}
+
+		version, err := q.repo.GetLatestVersion(*input.ProposalID)
+		if err != nil {
+			return nil, errors.New("proposal has no versions to analyze")
+		}
+		job.Title = version.Title
+		job.Objectives = version.Objectives
+		job.Methodology = version.Methodology
+		if strings.TrimSpace(job.Methodology) == "" {
+			if text, err := q.repo.GetVersionText(version.ID); err == nil {
+				job.Methodology = MethodologySection(text)
+			}
+		}
+	}
+
+	if job.Title == "" || job.Objectives == "" {
+		return nil, errors.New("title and objectives are required")
+	}
+
+	if err := q.repo.CreateJob(job); err != nil {
+		return nil, err
+	}
+
+	q.push(job.ID)
+	return job, nil
+}
+
+// GetJob returns a job to its requester, or an admin of the requester's department, with the decoded result
+func (q *JobQueue) GetJob(id uint, userID uint, role enums.Role, departmentID uint) (*JobView, error) {
+	job, err := q.repo.GetJob(id)
+	if err != nil {
+		return nil, errors.New("job not found")
+	}
+	if job.RequestedBy != userID {
+		if role != enums.RoleAdmin {
+			return nil, errors.New("job not found")
+		}
+		ownerDepartment, err := q.repo.GetUserDepartment(job.RequestedBy)
+		if err != nil || ownerDepartment != departmentID {
+			return nil, errors.New("job not found")
+		}
+	}
+
+	view := &JobView{AIJob: job}
+	if job.Result != "" {
+		json.Unmarshal([]byte(job.Result), &view.Result)
+	}
+	return view, nil
+}
+
+// RetryDeadLetter puts a failed analysis job back in the queue; it is the dead letter retrier for DeadLetterAnalysis
+func (q *JobQueue) RetryDeadLetter(payload []byte) error {
+	var letter analysisDeadLetter
+	if err := json.Unmarshal(payload, &letter); err != nil {
+		return err
+	}
+	job, err := q.repo.GetJob(letter.JobID)
+	if err != nil {
+		return errors.New("job not found")
+	}
+	if job.Status != enums.AIJobStatusFailed {
+		return nil
+	}
+
+	job.Status = enums.AIJobStatusQueued
+	job.Progress = 0
+	job.Error = ""
+	job.StartedAt = nil
+	job.CompletedAt = nil
+	if err := q.repo.UpdateJob(job); err != nil {
+		return err
+	}
+	q.push(job.ID)
+	return nil
+}
+
+// Backlog returns how many jobs are queued or running
+func (q *JobQueue) Backlog() (int, error) {
+	ids, err := q.repo.GetUnfinishedJobIDs()
+	return len(ids), err
+}
+
+// push hands the job to a worker without blocking the caller; a full buffer spills into a goroutine
+func (q *JobQueue) push(id uint) {
+	select {
+	case q.queue <- id:
+	default:
+		go func() {
+			select {
+			case q.queue <- id:
+			case <-q.stop:
+			}
+		}()
+	}
+}
+
+func (q *JobQueue) work() {
+	defer q.wg.Done()
+	for {
+		select {
+		case id := <-q.queue:
+			q.process(id)
+		case <-q.stop:
+			return
+		}
+	}
+}
+
+func (q *JobQueue) process(id uint) {
+	job, err := q.repo.GetJob(id)
+	if err != nil || job.Status == enums.AIJobStatusCompleted || job.Status == enums.AIJobStatusFailed {
+		return
+	}
+
+	now := time.Now()
+	job.Status = enums.AIJobStatusRunning
+	job.Progress = 10
+	job.Attempts++
+	job.StartedAt = &now
+	if err := q.repo.UpdateJob(job); err != nil {
+		log.Printf("failed to start AI job %d: %v", id, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), analysisTimeout)
+	result, err := q.client.CheckProposalText(ctx, ProposalCheckRequest{Title: job.Title, Objectives: job.Objectives, Methodology: job.Methodology})
+	cancel()
+	if err == nil && IsDegraded(result) {
+		// A job is only completed by a fresh analysis
+		err = errors.New("AI service unavailable")
+	}
+
+	finished := time.Now()
+	job.CompletedAt = &finished
+	job.Progress = 100
+
+	eventName := events.AIAnalysisCompleted
+	if err != nil {
+		eventName = events.AIAnalysisFailed
+		job.Status = enums.AIJobStatusFailed
+		job.Error = err.Error()
+	} else {
+		encoded, _ := json.Marshal(result)
+		job.Status = enums.AIJobStatusCompleted
+		job.Result = string(encoded)
+		job.Error = ""
+	}
+
+	if err := q.repo.UpdateJob(job); err != nil {
+		log.Printf("failed to save AI job %d: %v", id, err)
+		return
+	}
+	if err != nil {
+		q.deadLetters.Record(DeadLetterAnalysis, fmt.Sprintf("ai_job:%d", job.ID), analysisDeadLetter{JobID: job.ID}, job.Attempts, err)
+	}
+
+	q.bus.Publish(events.Event{
+		Name:       eventName,
+		EntityType: "ai_job",
+		EntityID:   job.ID,
+		ActorID:    job.RequestedBy,
+		UserIDs:    []uint{job.RequestedBy},
+		Data: map[string]interface{}{
+			"title":  job.Title,
+			"status": string(job.Status),
+			"url":    fmt.Sprintf("/ai/jobs/%d", job.ID),
+		},
+	})
+}
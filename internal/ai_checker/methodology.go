@@ -0,0 +1,75 @@
+package ai_checker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxMethodologyChars caps the methodology sent for analysis
+const maxMethodologyChars = 8000
+
+// headingNumbering matches outline numbering such as "3.", "3.1 " or "Chapter III"
+var headingNumbering = regexp.MustCompile(`^(chapter\s+)?([0-9]+|[ivx]+)(\.[0-9]+)*[.):]?\s+`)
+
+// sectionEnds are the headings that usually follow the methodology in a proposal
+var sectionEnds = []string{
+	"expected outcome", "expected result", "timeline", "time plan", "work plan", "schedule", "budget",
+	"references", "bibliography", "conclusion", "results", "evaluation", "limitation", "significance",
+	"scope", "appendix", "implementation plan",
+}
+
+// MethodologySection returns the methodology section of a proposal document's text: the lines
+// after a methodology heading up to the next section heading. Table of contents entries, with
+// their dot leaders, are skipped. Empty when the document has no such heading.
+func MethodologySection(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "...") || !isMethodologyHeading(normalizeHeading(line)) {
+			continue
+		}
+		var section strings.Builder
+		for _, next := range lines[i+1:] {
+			if isSectionEnd(normalizeHeading(next)) {
+				break
+			}
+			section.WriteString(strings.TrimSpace(next))
+			section.WriteByte('\n')
+			if section.Len() >= maxMethodologyChars {
+				break
+			}
+		}
+		if body := strings.TrimSpace(section.String()); body != "" {
+			if len(body) > maxMethodologyChars {
+				body = strings.ToValidUTF8(body[:maxMethodologyChars], "")
+			}
+			return body
+		}
+	}
+	return ""
+}
+
+func isMethodologyHeading(heading string) bool {
+	if heading == "" || len(strings.Fields(heading)) > 6 {
+		return false
+	}
+	return strings.Contains(heading, "methodology") || heading == "methods" || heading == "materials and methods"
+}
+
+func isSectionEnd(heading string) bool {
+	if heading == "" || len(strings.Fields(heading)) > 5 {
+		return false
+	}
+	for _, end := range sectionEnds {
+		if strings.HasPrefix(heading, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHeading lowercases a line, collapses its whitespace and drops outline numbering
+func normalizeHeading(line string) string {
+	line = strings.ToLower(strings.Join(strings.Fields(line), " "))
+	line = headingNumbering.ReplaceAllString(line, "")
+	return strings.TrimSpace(strings.TrimRight(line, ": "))
+}
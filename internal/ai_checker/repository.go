@@ -0,0 +1,148 @@
+package ai_checker
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateJob(job *domain.AIJob) error
+	GetJob(id uint) (*domain.AIJob, error)
+	UpdateJob(job *domain.AIJob) error
+	GetUnfinishedJobIDs() ([]uint, error)
+	GetUserDepartment(userID uint) (uint, error)
+
+	// Proposal lookups for analysis input
+	GetLatestVersion(proposalID uint) (*domain.ProposalVersion, error)
+	// GetVersionText returns the text extracted from the version's PDF
+	GetVersionText(versionID uint) (string, error)
+	CanAccessProposal(proposalID, userID uint, role enums.Role, departmentID uint) (bool, error)
+
+	// Settings
+	GetAIFlags(departmentID uint, universityID uint) (bool, *bool, error)
+	SetDepartmentAIOverride(departmentID uint, enabled *bool) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateJob(job *domain.AIJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *repository) GetJob(id uint) (*domain.AIJob, error) {
+	var job domain.AIJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetUserDepartment returns the department of the user, e.g. the one who requested a job
+func (r *repository) GetUserDepartment(userID uint) (uint, error) {
+	var user domain.User
+	if err := r.db.Select("id, department_id").First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	return user.DepartmentID, nil
+}
+
+func (r *repository) UpdateJob(job *domain.AIJob) error {
+	return r.db.Save(job).Error
+}
+
+// GetUnfinishedJobIDs returns jobs left queued or running, e.g. by a restart
+func (r *repository) GetUnfinishedJobIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.AIJob{}).
+		Where("status IN ?", []enums.AIJobStatus{enums.AIJobStatusQueued, enums.AIJobStatusRunning}).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) GetLatestVersion(proposalID uint) (*domain.ProposalVersion, error) {
+	var version domain.ProposalVersion
+	err := r.db.Where("proposal_id = ?", proposalID).Order("version_number DESC").First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (r *repository) GetVersionText(versionID uint) (string, error) {
+	var text domain.ProposalVersionText
+	if err := r.db.Select("content").Where("version_id = ?", versionID).First(&text).Error; err != nil {
+		return "", err
+	}
+	return text.Content, nil
+}
+
+// CanAccessProposal applies the same visibility rules as the proposals module
+func (r *repository) CanAccessProposal(proposalID, userID uint, role enums.Role, departmentID uint) (bool, error) {
+	query := r.db.Model(&domain.Proposal{}).Where("proposals.id = ?", proposalID)
+
+	switch role {
+	case enums.RoleAdmin:
+		query = query.Where("proposals.team_id IN (SELECT id FROM teams WHERE department_id = ?)", departmentID)
+	case enums.RoleAdvisor:
+		query = query.Where("proposals.advisor_id = ?", userID)
+	default:
+		query = query.Where("proposals.created_by = ? OR proposals.team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)", userID, userID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// GetAIFlags returns the university's AI checker flag and the department's override. The
+// department's own university wins over universityID; without either, AI stays enabled.
+func (r *repository) GetAIFlags(departmentID uint, universityID uint) (bool, *bool, error) {
+	var override *bool
+	if departmentID != 0 {
+		var department domain.Department
+		err := r.db.Select("id", "university_id", "ai_checker_enabled").Where("id = ?", departmentID).Limit(1).Find(&department).Error
+		if err != nil {
+			return false, nil, err
+		}
+		if department.ID != 0 {
+			override = department.AICheckerEnabled
+			if department.UniversityID != 0 {
+				universityID = department.UniversityID
+			}
+		}
+	}
+	if universityID == 0 {
+		return true, override, nil
+	}
+
+	var university domain.University
+	err := r.db.Select("id", "ai_checker_enabled").Where("id = ?", universityID).Limit(1).Find(&university).Error
+	if err != nil {
+		return false, nil, err
+	}
+	if university.ID == 0 {
+		return true, override, nil
+	}
+	return university.AICheckerEnabled, override, nil
+}
+
+func (r *repository) SetDepartmentAIOverride(departmentID uint, enabled *bool) error {
+	result := r.db.Model(&domain.Department{}).Where("id = ?", departmentID).Update("ai_checker_enabled", enabled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("department not found")
+	}
+	return nil
+}
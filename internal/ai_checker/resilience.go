@@ -0,0 +1,262 @@
+package ai_checker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientOptions tunes how the client copes with a slow or failing AI service
+type ClientOptions struct {
+	MaxRetries       int           // retries after the first attempt; 0 disables retrying
+	AttemptTimeout   time.Duration // per-attempt timeout
+	BaseBackoff      time.Duration // first retry delay, doubled on each retry
+	BreakerThreshold int           // consecutive failures that open the circuit
+	BreakerCooldown  time.Duration // how long the circuit stays open before a trial call
+	CacheTTL         time.Duration // how long successful analyses are kept for degraded fallback
+}
+
+// DefaultClientOptions fill in any zero-valued duration or threshold
+var DefaultClientOptions = ClientOptions{
+	MaxRetries:       2,
+	AttemptTimeout:   20 * time.Second,
+	BaseBackoff:      500 * time.Millisecond,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+	CacheTTL:         24 * time.Hour,
+}
+
+var errCircuitOpen = errors.New("AI service is unavailable (circuit open)")
+
+func (o ClientOptions) withDefaults() ClientOptions {
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.AttemptTimeout <= 0 {
+		o.AttemptTimeout = DefaultClientOptions.AttemptTimeout
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = DefaultClientOptions.BaseBackoff
+	}
+	if o.BreakerThreshold <= 0 {
+		o.BreakerThreshold = DefaultClientOptions.BreakerThreshold
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = DefaultClientOptions.BreakerCooldown
+	}
+	if o.CacheTTL <= 0 {
+		o.CacheTTL = DefaultClientOptions.CacheTTL
+	}
+	return o
+}
+
+// ---- Circuit breaker ----
+
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	trialing  bool
+}
+
+// allow reports whether a call may proceed. After the cooldown a single trial call is let through.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown || b.trialing {
+		return false
+	}
+	b.trialing = true
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trialing = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trialing = false
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *breaker) state() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < b.threshold:
+		return "closed"
+	case time.Since(b.openedAt) >= b.cooldown:
+		return "half_open"
+	default:
+		return "open"
+	}
+}
+
+// ---- Result cache for degraded responses ----
+
+type cachedResult struct {
+	result   map[string]interface{}
+	storedAt time.Time
+}
+
+type resultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedResult
+}
+
+const maxCacheEntries = 500
+
+func cacheKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *resultCache) get(key string) (cachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.storedAt) > c.ttl {
+		return cachedResult{}, false
+	}
+	return entry, true
+}
+
+func (c *resultCache) put(key string, result map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if time.Since(entry.storedAt) > c.ttl || len(c.entries) >= maxCacheEntries {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = cachedResult{result: result, storedAt: time.Now()}
+}
+
+// ---- Retrying transport ----
+
+// retryableError marks failures worth another attempt (network errors, 5xx, 429)
+type retryableError struct{ err error }
+
+func (e retryableError) Error() string { return e.err.Error() }
+
+// do sends the request built by newRequest with per-attempt timeouts, exponential backoff and the circuit breaker.
+// newRequest is called once per attempt so request bodies can be rebuilt.
+func (c *Client) do(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.opts.BaseBackoff << (attempt - 1)
+			delay += time.Duration(rand.Int63n(int64(delay)/2 + 1)) // jitter
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		if !c.breaker.allow() {
+			return nil, errCircuitOpen
+		}
+
+		body, err := c.attempt(ctx, newRequest)
+		if err == nil {
+			c.breaker.success()
+			return body, nil
+		}
+
+		lastErr = err
+		var retryable retryableError
+		if !errors.As(err, &retryable) {
+			// The service answered; a 4xx is the caller's problem, not an outage
+			c.breaker.success()
+			return nil, err
+		}
+		c.breaker.failure()
+	}
+
+	return nil, lastErr
+}
+
+func (c *Client) attempt(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.opts.AttemptTimeout)
+	defer cancel()
+
+	req, err := newRequest(attemptCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, retryableError{err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, retryableError{err}
+	}
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, retryableError{fmt.Errorf("AI service error: %s", strings.TrimSpace(string(body)))}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("AI service error: %s", strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// degraded builds the fallback returned when the AI service cannot answer:
+// the last cached analysis for the same input if there is one, otherwise an explicit "unavailable" result.
+func (c *Client) degraded(key string, cause error) map[string]interface{} {
+	if entry, ok := c.cache.get(key); ok {
+		result := make(map[string]interface{}, len(entry.result)+3)
+		for k, v := range entry.result {
+			result[k] = v
+		}
+		result["degraded"] = true
+		result["cached"] = true
+		result["cached_at"] = entry.storedAt
+		return result
+	}
+
+	return map[string]interface{}{
+		"degraded":  true,
+		"cached":    false,
+		"available": false,
+		"message":   "AI analysis is temporarily unavailable; please try again later",
+		"reason":    cause.Error(),
+	}
+}
+
+// IsDegraded reports whether a result is a fallback rather than a fresh analysis
+func IsDegraded(result map[string]interface{}) bool {
+	degraded, _ := result["degraded"].(bool)
+	return degraded
+}
@@ -0,0 +1,152 @@
+package ai_checker
+
+import (
+	"backend/pkg/response"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrAIDisabled = errors.New("AI features are disabled for your department")
+
+// Availability tells the frontend whether to offer AI features. A department's override wins
+// over its university's flag; Enabled also requires the AI service to be configured.
+type Availability struct {
+	Enabled            bool   `json:"enabled"`
+	Source             string `json:"source"` // department or university, whichever decided the policy
+	UniversityEnabled  bool   `json:"university_enabled"`
+	DepartmentOverride *bool  `json:"department_override"` // null inherits the university's flag
+	ServiceConfigured  bool   `json:"service_configured"`
+}
+
+// UpdateAISettingsRequest sets or, with null, clears the department's override
+type UpdateAISettingsRequest struct {
+	Enabled *bool `json:"enabled" example:"false"`
+}
+
+// Settings resolves the AI checker policy for a department
+type Settings struct {
+	repo   Repository
+	client *Client
+}
+
+func NewSettings(repo Repository, client *Client) *Settings {
+	return &Settings{repo: repo, client: client}
+}
+
+// For returns the AI availability for users of the department; universityID is used when the
+// user has no department
+func (s *Settings) For(departmentID uint, universityID uint) (*Availability, error) {
+	universityEnabled, override, err := s.repo.GetAIFlags(departmentID, universityID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &Availability{
+		UniversityEnabled:  universityEnabled,
+		DepartmentOverride: override,
+		ServiceConfigured:  s.client != nil && s.client.baseURL != "",
+		Source:             "university",
+	}
+	allowed := universityEnabled
+	if override != nil {
+		allowed = *override
+		availability.Source = "department"
+	}
+	availability.Enabled = allowed && availability.ServiceConfigured
+	return availability, nil
+}
+
+// Allowed reports whether the policy lets the department use AI features, regardless of
+// whether the service is configured. Lookup failures deny, so a department that turned AI off
+// does not get it back while its settings cannot be read.
+func (s *Settings) Allowed(departmentID uint, universityID uint) bool {
+	availability, err := s.For(departmentID, universityID)
+	if err != nil {
+		log.Printf("failed to load AI settings for department %d: %v", departmentID, err)
+		return false
+	}
+	if availability.DepartmentOverride != nil {
+		return *availability.DepartmentOverride
+	}
+	return availability.UniversityEnabled
+}
+
+// SetDepartmentOverride sets the department's override; nil inherits the university's flag again
+func (s *Settings) SetDepartmentOverride(departmentID uint, universityID uint, enabled *bool) (*Availability, error) {
+	if departmentID == 0 {
+		return nil, errors.New("your account is not attached to a department")
+	}
+	if err := s.repo.SetDepartmentAIOverride(departmentID, enabled); err != nil {
+		return nil, err
+	}
+	return s.For(departmentID, universityID)
+}
+
+// requireEnabled rejects the request with 403 when AI features are disabled for the caller's department
+func (h *Handler) requireEnabled(c *gin.Context) bool {
+	claims := getClaims(c)
+	if claims == nil {
+		return false
+	}
+	if !h.settings.Allowed(claims.DepartmentID, claims.UniversityID) {
+		response.Error(c, http.StatusForbidden, ErrAIDisabled.Error(), nil)
+		return false
+	}
+	return true
+}
+
+// GetSettings godoc
+// @Summary Get AI feature availability
+// @Description Tells the frontend whether to show AI features to the current user. A department override wins over the university's flag; enabled is false as well when the AI service is not configured.
+// @Tags AI Checker
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=Availability}
+// @Router /settings/ai [get]
+func (h *Handler) GetSettings(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	availability, err := h.settings.For(claims.DepartmentID, claims.UniversityID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch AI settings", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "AI settings retrieved", availability)
+}
+
+// UpdateDepartmentSettings godoc
+// @Summary Override AI features for the department
+// @Description Enables or disables the AI checker for the admin's department regardless of the university's flag. Send enabled=null to inherit the university's flag again.
+// @Tags Admin - System
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body UpdateAISettingsRequest true "Department override"
+// @Success 200 {object} response.Response{data=Availability}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/ai-settings [put]
+func (h *Handler) UpdateDepartmentSettings(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateAISettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	availability, err := h.settings.SetDepartmentOverride(claims.DepartmentID, claims.UniversityID, req.Enabled)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "AI settings updated", availability)
+}
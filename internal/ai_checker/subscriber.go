@@ -0,0 +1,68 @@
+package ai_checker
+
+import (
+	"backend/pkg/deadletter"
+	"backend/pkg/events"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DeadLetterSync is the dead letter kind of similarity index updates that failed
+const DeadLetterSync = "ai_sync"
+
+const syncTimeout = 30 * time.Second
+
+// RegisterSubscribers keeps the AI similarity index in sync with approved and published projects
+// of departments that allow AI features. Updates that still fail after the client's retries go to
+// the dead letter queue.
+func (c *Client) RegisterSubscribers(bus *events.Bus, deadLetters *deadletter.Queue, settings *Settings) {
+	bus.Subscribe(func(e events.Event) {
+		c.syncFromEvent(e, deadLetters, settings)
+	}, events.ProposalApproved, events.ProjectPublished)
+}
+
+func (c *Client) syncFromEvent(e events.Event, deadLetters *deadletter.Queue, settings *Settings) {
+	if c.baseURL == "" {
+		return
+	}
+
+	projectID, ok := e.Data["project_id"].(uint)
+	if !ok || projectID == 0 {
+		return
+	}
+	// Projects of a department without AI features never leave the system
+	departmentID, _ := e.Data["department_id"].(uint)
+	if departmentID == 0 || !settings.Allowed(departmentID, 0) {
+		return
+	}
+	title, _ := e.Data["title"].(string)
+	summary, _ := e.Data["summary"].(string)
+	projects := []SyncProject{{ID: projectID, Title: title, Summary: summary}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	if err := c.SyncProjects(ctx, projects); err != nil {
+		log.Printf("AI sync failed for project %d: %v", projectID, err)
+		deadLetters.Record(DeadLetterSync, fmt.Sprintf("project:%d", projectID), projects, c.opts.MaxRetries+1, err)
+	}
+}
+
+// RetryDeadLetter re-sends a failed similarity index update; it is the dead letter retrier for DeadLetterSync
+func (c *Client) RetryDeadLetter(payload []byte) error {
+	if c.baseURL == "" {
+		return errors.New("AI service is not configured")
+	}
+	var projects []SyncProject
+	if err := json.Unmarshal(payload, &projects); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	return c.SyncProjects(ctx, projects)
+}
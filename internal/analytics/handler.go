@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// GetFunnel godoc
+// @Summary Proposal funnel report
+// @Description Counts proposals reaching each stage (draft → submitted → under_review → approved/rejected) and the conversion rates between stages, per department and cohort (academic year). Department admins only see their own department.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param department_id query int false "Department ID (ignored for department admins)"
+// @Param cohort query string false "Academic year, e.g. 2025/2026"
+// @Param from query string false "Proposals created on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Proposals created on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} response.Response{data=FunnelReport}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/analytics/funnel [get]
+func (h *Handler) GetFunnel(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	filter, ok := parseFilter(c, claims)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetFunnel(filter)
+	if err != nil {
+		if err.Error() == "from must be before to" {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to build funnel report", err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// GetFeedbackQuality godoc
+// @Summary Feedback quality by advisor
+// @Description Per advisor, how many reviews they gave, how long the comments were (word count of the advisor's own text, templates excluded) and the time they reported spending. Advisors with at least min_reviews reviews, 60% or more of them one-liners, are flagged for supervision quality audits. Department admins only see their own department.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param department_id query int false "Department ID (ignored for department admins)"
+// @Param cohort query string false "Academic year of the reviewed proposals, e.g. 2025/2026"
+// @Param from query string false "Feedback given on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Feedback given on or before this date (YYYY-MM-DD)"
+// @Param one_line_words query int false "Word count up to which feedback is one line (default 15)"
+// @Param min_reviews query int false "Reviews needed before an advisor is flagged (default 5)"
+// @Param flagged_only query bool false "Only list flagged advisors"
+// @Success 200 {object} response.Response{data=FeedbackQualityReport}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/analytics/feedback-quality [get]
+func (h *Handler) GetFeedbackQuality(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	filter, ok := parseFilter(c, claims)
+	if !ok {
+		return
+	}
+	oneLineWords, err := strconv.Atoi(c.DefaultQuery("one_line_words", "0"))
+	if err != nil || oneLineWords < 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid one_line_words", nil)
+		return
+	}
+	minReviews, err := strconv.Atoi(c.DefaultQuery("min_reviews", "0"))
+	if err != nil || minReviews < 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid min_reviews", nil)
+		return
+	}
+
+	report, err := h.service.GetFeedbackQuality(filter, FeedbackQualityOptions{
+		OneLineWords: oneLineWords,
+		MinReviews:   minReviews,
+		FlaggedOnly:  c.Query("flagged_only") == "true",
+	})
+	if err != nil {
+		if err.Error() == "from must be before to" {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to build feedback quality report", err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// Helpers
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseDate(c *gin.Context, key string, offset time.Duration) (*time.Time, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid "+key+" date", "use YYYY-MM-DD")
+		return nil, false
+	}
+	date = date.Add(offset)
+	return &date, true
+}
+
+// parseFilter reads the department, cohort and date range; department admins are kept to their department
+func parseFilter(c *gin.Context, claims *auth.TokenClaims) (FunnelFilter, bool) {
+	filter := FunnelFilter{
+		DepartmentID: claims.DepartmentID,
+		Cohort:       c.Query("cohort"),
+	}
+	if filter.DepartmentID == 0 && c.Query("department_id") != "" {
+		deptID, err := strconv.ParseUint(c.Query("department_id"), 10, 32)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid department ID", err.Error())
+			return filter, false
+		}
+		filter.DepartmentID = uint(deptID)
+	}
+
+	var ok bool
+	if filter.From, ok = parseDate(c, "from", 0); !ok {
+		return filter, false
+	}
+	// "to" is inclusive for callers; the query compares against the start of the next day
+	if filter.To, ok = parseDate(c, "to", 24*time.Hour); !ok {
+		return filter, false
+	}
+	return filter, true
+}
@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"backend/pkg/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetFunnel(filter FunnelFilter) ([]FunnelRow, error)
+	GetFeedbackQuality(filter FunnelFilter, oneLineWords int) ([]FeedbackQualityRow, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FunnelFilter narrows the funnel to a department, a cohort and a creation date range
+type FunnelFilter struct {
+	DepartmentID uint
+	Cohort       string
+	From         *time.Time // inclusive
+	To           *time.Time // exclusive
+}
+
+// FunnelRow holds the stage counts for one department and cohort
+type FunnelRow struct {
+	DepartmentID   uint   `json:"department_id"`
+	DepartmentName string `json:"department_name"`
+	Cohort         string `json:"cohort"`
+	Drafted        int64  `json:"drafted"`
+	Submitted      int64  `json:"submitted"`
+	UnderReview    int64  `json:"under_review"`
+	Approved       int64  `json:"approved"`
+	Rejected       int64  `json:"rejected"`
+}
+
+// GetFunnel counts, per department and cohort, how many proposals reached each stage.
+// A proposal's current status implies the stages it has passed through: anything but a draft was
+// submitted, and only proposals an advisor picked up can be under review, revised or decided.
+func (r *repository) GetFunnel(filter FunnelFilter) ([]FunnelRow, error) {
+	reviewed := []enums.ProposalStatus{
+		enums.ProposalStatusUnderReview,
+		enums.ProposalStatusRevisionRequired,
+		enums.ProposalStatusAbandoned,
+		enums.ProposalStatusAwaitingSecondReview,
+		enums.ProposalStatusApproved,
+		enums.ProposalStatusRejected,
+	}
+
+	query := r.db.Table("proposals").
+		Select(`teams.department_id AS department_id,
+			COALESCE(departments.name, '') AS department_name,
+			COALESCE(proposals.academic_year, '') AS cohort,
+			COUNT(*) AS drafted,
+			COUNT(*) FILTER (WHERE proposals.status <> ?) AS submitted,
+			COUNT(*) FILTER (WHERE proposals.status IN ?) AS under_review,
+			COUNT(*) FILTER (WHERE proposals.status = ?) AS approved,
+			COUNT(*) FILTER (WHERE proposals.status = ?) AS rejected`,
+			enums.ProposalStatusDraft, reviewed, enums.ProposalStatusApproved, enums.ProposalStatusRejected).
+		Joins("JOIN teams ON teams.id = proposals.team_id").
+		Joins("LEFT JOIN departments ON departments.id = teams.department_id")
+
+	if filter.DepartmentID != 0 {
+		query = query.Where("teams.department_id = ?", filter.DepartmentID)
+	}
+	if filter.Cohort != "" {
+		query = query.Where("proposals.academic_year = ?", filter.Cohort)
+	}
+	if filter.From != nil {
+		query = query.Where("proposals.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("proposals.created_at < ?", *filter.To)
+	}
+
+	var rows []FunnelRow
+	err := query.
+		Group("teams.department_id, departments.name, proposals.academic_year").
+		Order("department_name ASC, cohort DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FeedbackQualityRow holds one advisor's review statistics
+type FeedbackQualityRow struct {
+	AdvisorID       uint       `json:"advisor_id"`
+	Name            string     `json:"name"`
+	Reviews         int64      `json:"reviews"`
+	OneLineReviews  int64      `json:"one_line_reviews"`
+	AverageWords    float64    `json:"average_words"`
+	MedianWords     float64    `json:"median_words"`
+	TimedReviews    int64      `json:"timed_reviews"`             // reviews with a reported time spent
+	AverageMinutes  *float64   `json:"average_minutes,omitempty"` // over the timed reviews
+	WithAttachments int64      `json:"with_attachments"`          // reviews with annotated files
+	LastReviewAt    *time.Time `json:"last_review_at,omitempty"`
+}
+
+// GetFeedbackQuality aggregates, per advisor, the length and reported effort of the feedback given
+// on the department's proposals. The filter's dates apply to when the feedback was given.
+func (r *repository) GetFeedbackQuality(filter FunnelFilter, oneLineWords int) ([]FeedbackQualityRow, error) {
+	query := r.db.Table("feedbacks").
+		Select(`feedbacks.reviewer_id AS advisor_id,
+			users.name AS name,
+			COUNT(*) AS reviews,
+			COUNT(*) FILTER (WHERE feedbacks.word_count <= ?) AS one_line_reviews,
+			AVG(feedbacks.word_count) AS average_words,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY feedbacks.word_count) AS median_words,
+			COUNT(feedbacks.time_spent_minutes) AS timed_reviews,
+			AVG(feedbacks.time_spent_minutes) AS average_minutes,
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM feedback_attachments WHERE feedback_attachments.feedback_id = feedbacks.id)) AS with_attachments,
+			MAX(feedbacks.created_at) AS last_review_at`, oneLineWords).
+		Joins("JOIN users ON users.id = feedbacks.reviewer_id").
+		Joins("JOIN proposals ON proposals.id = feedbacks.proposal_id").
+		Joins("JOIN teams ON teams.id = proposals.team_id")
+
+	if filter.DepartmentID != 0 {
+		query = query.Where("teams.department_id = ?", filter.DepartmentID)
+	}
+	if filter.Cohort != "" {
+		query = query.Where("proposals.academic_year = ?", filter.Cohort)
+	}
+	if filter.From != nil {
+		query = query.Where("feedbacks.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("feedbacks.created_at < ?", *filter.To)
+	}
+
+	var rows []FeedbackQualityRow
+	err := query.
+		Group("feedbacks.reviewer_id, users.name").
+		Order("users.name ASC").
+		Scan(&rows).Error
+	return rows, err
+}
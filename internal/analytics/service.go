@@ -0,0 +1,179 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(r Repository) *Service {
+	return &Service{repo: r}
+}
+
+// ConversionRates are the share of proposals that moved on from one stage to the next, in percent
+type ConversionRates struct {
+	DraftToSubmitted       float64 `json:"draft_to_submitted"`
+	SubmittedToUnderReview float64 `json:"submitted_to_under_review"`
+	UnderReviewToApproved  float64 `json:"under_review_to_approved"`
+	UnderReviewToRejected  float64 `json:"under_review_to_rejected"`
+	DraftToApproved        float64 `json:"draft_to_approved"`
+}
+
+// FunnelSegment is one department and cohort's stage counts with their conversion rates
+type FunnelSegment struct {
+	FunnelRow
+	Rates ConversionRates `json:"rates"`
+}
+
+// FunnelReport is the proposal funnel for the requested filters, per segment and overall
+type FunnelReport struct {
+	From     *time.Time      `json:"from,omitempty"`
+	To       *time.Time      `json:"to,omitempty"` // exclusive
+	Cohort   string          `json:"cohort,omitempty"`
+	Segments []FunnelSegment `json:"segments"`
+	Total    FunnelSegment   `json:"total"`
+}
+
+// GetFunnel builds the draft → submitted → under_review → approved/rejected funnel
+func (s *Service) GetFunnel(filter FunnelFilter) (*FunnelReport, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("from must be before to")
+	}
+
+	rows, err := s.repo.GetFunnel(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FunnelReport{
+		From:     filter.From,
+		To:       filter.To,
+		Cohort:   filter.Cohort,
+		Segments: make([]FunnelSegment, 0, len(rows)),
+	}
+
+	var total FunnelRow
+	for _, row := range rows {
+		report.Segments = append(report.Segments, FunnelSegment{FunnelRow: row, Rates: conversionRates(row)})
+		total.Drafted += row.Drafted
+		total.Submitted += row.Submitted
+		total.UnderReview += row.UnderReview
+		total.Approved += row.Approved
+		total.Rejected += row.Rejected
+	}
+	total.DepartmentID = filter.DepartmentID
+	total.Cohort = filter.Cohort
+	report.Total = FunnelSegment{FunnelRow: total, Rates: conversionRates(total)}
+
+	return report, nil
+}
+
+func conversionRates(row FunnelRow) ConversionRates {
+	return ConversionRates{
+		DraftToSubmitted:       percent(row.Submitted, row.Drafted),
+		SubmittedToUnderReview: percent(row.UnderReview, row.Submitted),
+		UnderReviewToApproved:  percent(row.Approved, row.UnderReview),
+		UnderReviewToRejected:  percent(row.Rejected, row.UnderReview),
+		DraftToApproved:        percent(row.Approved, row.Drafted),
+	}
+}
+
+// percent rounds to one decimal place; an empty stage converts at 0%
+func percent(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*1000) / 10
+}
+
+const (
+	// DefaultOneLineWords is the word count up to which feedback counts as one line
+	DefaultOneLineWords = 15
+	// DefaultMinReviews is how many reviews an advisor needs before their feedback is judged
+	DefaultMinReviews = 5
+	// OneLineSharePercent is the share of one-line reviews from which an advisor is flagged
+	OneLineSharePercent = 60
+)
+
+// FeedbackQualityOptions tune when feedback is short and when an advisor is flagged
+type FeedbackQualityOptions struct {
+	OneLineWords int
+	MinReviews   int
+	FlaggedOnly  bool
+}
+
+// AdvisorFeedbackQuality is an advisor's review statistics. Flagged advisors gave enough reviews
+// and most of them were one-liners.
+type AdvisorFeedbackQuality struct {
+	FeedbackQualityRow
+	OneLineShare float64 `json:"one_line_share"` // percent of the reviews
+	Flagged      bool    `json:"flagged"`
+}
+
+// FeedbackQualityReport lists the advisors of the filtered reviews, flagged ones first
+type FeedbackQualityReport struct {
+	From         *time.Time               `json:"from,omitempty"`
+	To           *time.Time               `json:"to,omitempty"` // exclusive
+	Cohort       string                   `json:"cohort,omitempty"`
+	OneLineWords int                      `json:"one_line_words"`
+	MinReviews   int                      `json:"min_reviews"`
+	Flagged      int                      `json:"flagged"`
+	Advisors     []AdvisorFeedbackQuality `json:"advisors"`
+}
+
+// GetFeedbackQuality surfaces the advisors whose feedback is consistently one line, for
+// supervision quality audits
+func (s *Service) GetFeedbackQuality(filter FunnelFilter, options FeedbackQualityOptions) (*FeedbackQualityReport, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("from must be before to")
+	}
+	if options.OneLineWords <= 0 {
+		options.OneLineWords = DefaultOneLineWords
+	}
+	if options.MinReviews <= 0 {
+		options.MinReviews = DefaultMinReviews
+	}
+
+	rows, err := s.repo.GetFeedbackQuality(filter, options.OneLineWords)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FeedbackQualityReport{
+		From:         filter.From,
+		To:           filter.To,
+		Cohort:       filter.Cohort,
+		OneLineWords: options.OneLineWords,
+		MinReviews:   options.MinReviews,
+		Advisors:     make([]AdvisorFeedbackQuality, 0, len(rows)),
+	}
+	for _, row := range rows {
+		row.AverageWords = math.Round(row.AverageWords*10) / 10
+		if row.AverageMinutes != nil {
+			minutes := math.Round(*row.AverageMinutes*10) / 10
+			row.AverageMinutes = &minutes
+		}
+		advisor := AdvisorFeedbackQuality{FeedbackQualityRow: row, OneLineShare: percent(row.OneLineReviews, row.Reviews)}
+		advisor.Flagged = row.Reviews >= int64(options.MinReviews) && advisor.OneLineShare >= OneLineSharePercent
+		if advisor.Flagged {
+			report.Flagged++
+		} else if options.FlaggedOnly {
+			continue
+		}
+		report.Advisors = append(report.Advisors, advisor)
+	}
+
+	sort.SliceStable(report.Advisors, func(i, j int) bool {
+		a, b := report.Advisors[i], report.Advisors[j]
+		if a.Flagged != b.Flagged {
+			return a.Flagged
+		}
+		return a.OneLineShare > b.OneLineShare
+	})
+	return report, nil
+}
@@ -0,0 +1,139 @@
+package announcements
+
+import (
+	"backend/internal/auth"
+	"backend/internal/files"
+	"backend/pkg/enums"
+	"backend/pkg/response"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// CreateAnnouncement godoc
+// @Summary Post a department announcement
+// @Description Posts an announcement to the admin's department and notifies its students and advisors. Send JSON, or multipart form data with up to 5 files in "attachments".
+// @Tags Announcements
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAnnouncementRequest true "Title, body and optional expiry"
+// @Param attachments formData file false "Attached files (repeat the field for several)"
+// @Success 201 {object} response.Response{data=domain.Announcement}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /admin/announcements [post]
+func (h *Handler) CreateAnnouncement(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req CreateAnnouncementRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	var attachments []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		attachments = form.File["attachments"]
+	}
+
+	announcement, err := h.service.Create(req, attachments, claims.UserID, claims.DepartmentID)
+	if err != nil {
+		if errors.Is(err, files.ErrFileInfected) {
+			response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Failed to post announcement", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusCreated, "Announcement posted", announcement)
+}
+
+// GetAnnouncements godoc
+// @Summary List department announcements
+// @Description Lists the announcements of the current user's department, newest first. Expired announcements are hidden; admins can include them with all=true.
+// @Tags Announcements
+// @Produce json
+// @Security BearerAuth
+// @Param all query bool false "Include expired announcements (admins only)"
+// @Success 200 {object} response.Response{data=[]domain.Announcement}
+// @Router /announcements [get]
+func (h *Handler) GetAnnouncements(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	includeExpired := c.Query("all") == "true" && claims.Role == enums.RoleAdmin
+	announcements, err := h.service.GetDepartmentAnnouncements(claims.DepartmentID, includeExpired)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch announcements", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Announcements retrieved", announcements)
+}
+
+// DeleteAnnouncement godoc
+// @Summary Delete a department announcement
+// @Description Removes an announcement of the admin's department and its attachments
+// @Tags Announcements
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Announcement ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/announcements/{id} [delete]
+func (h *Handler) DeleteAnnouncement(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	if err := h.service.Delete(id, claims.DepartmentID); err != nil {
+		if errors.Is(err, ErrAnnouncementNotFound) {
+			response.Error(c, http.StatusNotFound, "Announcement not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to delete announcement", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Announcement deleted", nil)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseID(c *gin.Context) uint {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,68 @@
+package announcements
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(announcement *domain.Announcement) error
+	GetByID(id uint) (*domain.Announcement, error)
+	Delete(id uint) error
+	GetByDepartment(departmentID uint, includeExpired bool) ([]domain.Announcement, error)
+
+	// GetAudienceIDs returns the active students and advisors of a department
+	GetAudienceIDs(departmentID uint) ([]uint, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(announcement *domain.Announcement) error {
+	return r.db.Create(announcement).Error
+}
+
+func (r *repository) GetByID(id uint) (*domain.Announcement, error) {
+	var announcement domain.Announcement
+	err := r.db.Preload("Author").Preload("Attachments").First(&announcement, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+func (r *repository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("announcement_id = ?", id).Delete(&domain.AnnouncementAttachment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Announcement{}, id).Error
+	})
+}
+
+func (r *repository) GetByDepartment(departmentID uint, includeExpired bool) ([]domain.Announcement, error) {
+	var announcements []domain.Announcement
+	query := r.db.Preload("Author").Preload("Attachments").Where("department_id = ?", departmentID)
+	if !includeExpired {
+		query = query.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	}
+	err := query.Order("created_at DESC").Find(&announcements).Error
+	return announcements, err
+}
+
+func (r *repository) GetAudienceIDs(departmentID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.User{}).
+		Where("department_id = ? AND is_active = ? AND role IN ?", departmentID, true,
+			[]enums.Role{enums.RoleStudent, enums.RoleAdvisor}).
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,142 @@
+package announcements
+
+import (
+	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
+)
+
+const (
+	MaxAttachments     = 5
+	MaxAttachmentBytes = 20 << 20 // 20 MB per file
+)
+
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+type Service struct {
+	repo     Repository
+	uploader *files.Uploader
+	bus      *events.Bus
+}
+
+func NewService(repo Repository, uploader *files.Uploader, bus *events.Bus) *Service {
+	return &Service{repo: repo, uploader: uploader, bus: bus}
+}
+
+// CreateAnnouncementRequest is sent as JSON, or as multipart form data when files are attached
+type CreateAnnouncementRequest struct {
+	Title     string     `json:"title" form:"title" binding:"required,max=200" example:"Proposal defense schedule"`
+	Body      string     `json:"body" form:"body" binding:"required" example:"Defenses start on 2 June; slots are listed in the attachment."`
+	ExpiresAt *time.Time `json:"expires_at" form:"expires_at" time_format:"2006-01-02T15:04:05Z07:00" example:"2026-06-30T00:00:00Z"`
+}
+
+// Create posts an announcement to the admin's department and notifies its students and advisors.
+// Attachments are checked like project documents; an infected file refuses the whole post.
+func (s *Service) Create(req CreateAnnouncementRequest, attachments []*multipart.FileHeader, authorID uint, departmentID uint) (*domain.Announcement, error) {
+	if departmentID == 0 {
+		return nil, errors.New("only a department admin can post announcements")
+	}
+	title := strings.TrimSpace(req.Title)
+	body := strings.TrimSpace(req.Body)
+	if title == "" || body == "" {
+		return nil, errors.New("title and body are required")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+	if len(attachments) > MaxAttachments {
+		return nil, fmt.Errorf("at most %d attachments are allowed", MaxAttachments)
+	}
+
+	announcement := &domain.Announcement{
+		DepartmentID: departmentID,
+		AuthorID:     authorID,
+		Title:        title,
+		Body:         body,
+		ExpiresAt:    req.ExpiresAt,
+		Attachments:  make([]domain.AnnouncementAttachment, 0, len(attachments)),
+	}
+	for _, file := range attachments {
+		if file.Size > MaxAttachmentBytes {
+			return nil, fmt.Errorf("%s is larger than %d MB", file.Filename, MaxAttachmentBytes>>20)
+		}
+		meta, err := files.Inspect(file)
+		if err != nil {
+			return nil, err
+		}
+		if meta.ScanStatus == enums.ScanStatusInfected {
+			return nil, fmt.Errorf("%w: %s", files.ErrFileInfected, file.Filename)
+		}
+		announcement.Attachments = append(announcement.Attachments, domain.AnnouncementAttachment{
+			FileName:      file.Filename,
+			FileSizeBytes: file.Size,
+			FileMetadata:  meta,
+		})
+	}
+
+	// Files are only written once every attachment passed, and removed again if the post fails
+	for i, file := range attachments {
+		path, err := s.uploader.SaveFile(file, "announcements")
+		if err != nil {
+			s.removeFiles(announcement.Attachments[:i])
+			return nil, err
+		}
+		announcement.Attachments[i].URL = path
+	}
+	if err := s.repo.Create(announcement); err != nil {
+		s.removeFiles(announcement.Attachments)
+		return nil, err
+	}
+
+	audience, err := s.repo.GetAudienceIDs(departmentID)
+	if err == nil && len(audience) > 0 {
+		s.bus.Publish(events.Event{
+			Name:       events.AnnouncementPosted,
+			EntityType: "announcement",
+			EntityID:   announcement.ID,
+			ActorID:    authorID,
+			UserIDs:    audience,
+			Data: map[string]interface{}{
+				"title": announcement.Title,
+			},
+		})
+	}
+
+	return s.repo.GetByID(announcement.ID)
+}
+
+// GetDepartmentAnnouncements lists a department's announcements, newest first. Expired ones
+// are left out unless includeExpired is set.
+func (s *Service) GetDepartmentAnnouncements(departmentID uint, includeExpired bool) ([]domain.Announcement, error) {
+	if departmentID == 0 {
+		return []domain.Announcement{}, nil
+	}
+	return s.repo.GetByDepartment(departmentID, includeExpired)
+}
+
+// Delete removes an announcement of the admin's department with its attachments
+func (s *Service) Delete(id uint, departmentID uint) error {
+	announcement, err := s.repo.GetByID(id)
+	if err != nil || announcement.DepartmentID != departmentID {
+		return ErrAnnouncementNotFound
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.removeFiles(announcement.Attachments)
+	return nil
+}
+
+func (s *Service) removeFiles(attachments []domain.AnnouncementAttachment) {
+	for _, attachment := range attachments {
+		if attachment.URL != "" {
+			_ = s.uploader.DeleteFile(attachment.URL)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"backend/docs"
+	"backend/internal/permissions"
+	"backend/pkg/enums"
+	"encoding/json"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag"
+)
+
+// APIDocGroups are the audiences the API reference is split into; each sees only the
+// endpoints it can call
+var APIDocGroups = []string{"public", "student", "advisor", "admin"}
+
+// routeAccess is who may call a route, as declared by the guards it was registered with
+type routeAccess struct {
+	Authenticated bool
+	Permission    permissions.Permission // empty when any signed-in user may call it
+	Delegated     bool                   // teachers holding a delegation of Permission may call it too
+}
+
+func (a routeAccess) merge(b routeAccess) routeAccess {
+	if b.Authenticated {
+		a.Authenticated = true
+	}
+	if b.Permission != "" {
+		a.Permission = b.Permission
+		a.Delegated = b.Delegated
+	}
+	return a
+}
+
+// routeTable records the access of every registered route, keyed by "METHOD /path/:param".
+// Guards are built right before the route or group they protect is registered, so the router's
+// guard constructors leave their requirement in pending for the registration to pick up.
+type routeTable struct {
+	mu      sync.RWMutex
+	access  map[string]routeAccess
+	pending routeAccess
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{access: make(map[string]routeAccess)}
+}
+
+func (t *routeTable) take() routeAccess {
+	pending := t.pending
+	t.pending = routeAccess{}
+	return pending
+}
+
+func (t *routeTable) wrap(group *gin.RouterGroup) *routes {
+	t.take()
+	return &routes{group: group, table: t}
+}
+
+// routes is a gin router group that records the access of the routes registered on it
+type routes struct {
+	group  *gin.RouterGroup
+	table  *routeTable
+	access routeAccess
+}
+
+func (g *routes) Group(relativePath string, handlers ...gin.HandlerFunc) *routes {
+	return &routes{
+		group:  g.group.Group(relativePath, handlers...),
+		table:  g.table,
+		access: g.access.merge(g.table.take()),
+	}
+}
+
+// Use adds middleware to routes registered on the group afterwards, as with gin
+func (g *routes) Use(middleware ...gin.HandlerFunc) {
+	g.access = g.access.merge(g.table.take())
+	g.group.Use(middleware...)
+}
+
+func (g *routes) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodGet, relativePath, handlers)
+}
+
+func (g *routes) HEAD(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodHead, relativePath, handlers)
+}
+
+func (g *routes) POST(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPost, relativePath, handlers)
+}
+
+func (g *routes) PUT(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPut, relativePath, handlers)
+}
+
+func (g *routes) PATCH(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPatch, relativePath, handlers)
+}
+
+func (g *routes) DELETE(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodDelete, relativePath, handlers)
+}
+
+func (g *routes) handle(method, relativePath string, handlers []gin.HandlerFunc) {
+	access := g.access.merge(g.table.take())
+	g.group.Handle(method, relativePath, handlers...)
+
+	g.table.mu.Lock()
+	defer g.table.mu.Unlock()
+	g.table.access[method+" "+routeShape(path.Join(g.group.BasePath(), relativePath))] = access
+}
+
+// pathParam matches gin (:id, *any) and Swagger ({id}) path parameters
+var pathParam = regexp.MustCompile(`(:[^/]+|\*[^/]+|\{[^/}]+\})`)
+
+// routeShape replaces path parameters with a placeholder, so gin and Swagger paths compare
+// even when their parameter names differ
+func routeShape(p string) string {
+	return pathParam.ReplaceAllString(p, "{}")
+}
+
+// roleDoc is the API reference filtered to the endpoints one audience can call
+type roleDoc struct {
+	group      string
+	table      *routeTable
+	authorizer *permissions.Authorizer
+}
+
+// ReadDoc implements swag.Swagger
+func (d *roleDoc) ReadDoc() string {
+	full := docs.SwaggerInfo.ReadDoc()
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(full), &spec); err != nil {
+		log.Printf("failed to parse the API reference: %v", err)
+		return full
+	}
+
+	basePath, _ := spec["basePath"].(string)
+	paths, _ := spec["paths"].(map[string]interface{})
+	for p, item := range paths {
+		operations, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			if !d.allows(strings.ToUpper(method) + " " + routeShape(path.Join("/", basePath, p))) {
+				delete(operations, method)
+			}
+		}
+		if len(operations) == 0 || (len(operations) == 1 && operations["parameters"] != nil) {
+			delete(paths, p)
+		}
+	}
+	if info, ok := spec["info"].(map[string]interface{}); ok {
+		title, _ := info["title"].(string)
+		info["title"] = title + " (" + d.group + ")"
+	}
+
+	out, err := json.Marshal(spec)
+	if err != nil {
+		return full
+	}
+	return string(out)
+}
+
+// allows reports whether the doc's audience can call the route. Documented operations without
+// a registered route are left out. Grants are the global ones; departments may override them.
+func (d *roleDoc) allows(route string) bool {
+	d.table.mu.RLock()
+	access, ok := d.table.access[route]
+	d.table.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if !access.Authenticated {
+		return true
+	}
+	if d.group == "public" {
+		return false
+	}
+	role := enums.Role(d.group)
+	if access.Permission == "" {
+		return true
+	}
+	if d.authorizer == nil {
+		return false
+	}
+	if d.authorizer.Can(role, 0, access.Permission) {
+		return true
+	}
+	return access.Delegated && d.authorizer.Can(role, 0, permissions.DelegationHold)
+}
+
+// registerRoleDocs registers one Swagger instance per audience, named "api-<group>"
+func registerRoleDocs(table *routeTable, authorizer *permissions.Authorizer) {
+	for _, group := range APIDocGroups {
+		name := "api-" + group
+		if swag.GetSwagger(name) != nil {
+			continue // already registered by an earlier router
+		}
+		swag.Register(name, &roleDoc{group: group, table: table, authorizer: authorizer})
+	}
+}
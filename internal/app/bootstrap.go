@@ -2,22 +2,44 @@ package app
 
 import (
 	"backend/config"
+	"backend/internal/advisorrequests"
 	"backend/internal/ai_checker"
+	"backend/internal/analytics"
+	"backend/internal/announcements"
 	"backend/internal/auth"
+	"backend/internal/conflicts"
+	"backend/internal/delegations"
 	"backend/internal/departments"
 	"backend/internal/files"
 
 	"backend/internal/documentations"
-	"backend/internal/domain"
+	"backend/internal/examiners"
 	"backend/internal/feedback"
+	"backend/internal/grading"
+	"backend/internal/graphql"
+	"backend/internal/notifications"
+	"backend/internal/permissions"
 	"backend/internal/projects"
 	"backend/internal/proposals"
+	"backend/internal/realtime"
+	"backend/internal/reviews"
+	"backend/internal/search"
+	"backend/internal/system"
+	"backend/internal/tasks"
 	"backend/internal/teams"
 	"backend/internal/universities"
 	"backend/internal/users"
 	"backend/pkg/audit"
 	"backend/pkg/database"
+	"backend/pkg/deadletter"
+	"backend/pkg/events"
+	"backend/pkg/mailer"
+	"backend/pkg/maintenance"
+	"backend/pkg/scheduler"
+	"backend/pkg/settings"
+	"backend/pkg/uow"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -26,6 +48,11 @@ type App struct {
 	Config               config.Config
 	DB                   *gorm.DB
 	AuditLogger          *audit.Logger
+	EventBus             *events.Bus
+	Scheduler            *scheduler.Scheduler
+	Maintenance          *maintenance.Mode
+	UploadDir            string
+	AIJobQueue           *ai_checker.JobQueue
 	AuthService          auth.Service
 	AuthHandler          *auth.Handler
 	UniversityHandler    *universities.Handler
@@ -36,7 +63,27 @@ type App struct {
 	FeedbackHandler      *feedback.Handler
 	ProjectHandler       *projects.Handler
 	DocumentationHandler *documentations.Handler
+	GradingHandler       *grading.Handler
+	ExaminerService      *examiners.Service
+	ExaminerHandler      *examiners.Handler
+	FileHandler          *files.Handler
 	AICheckerHandler     *ai_checker.Handler
+	GraphQLHandler       *graphql.Handler
+	SystemHandler        *system.Handler
+	DelegationService    *delegations.Service
+	DelegationHandler    *delegations.Handler
+	NotificationHandler  *notifications.Handler
+	Authorizer           *permissions.Authorizer
+	PermissionHandler    *permissions.Handler
+	AnalyticsHandler     *analytics.Handler
+	SearchHandler        *search.Handler
+	AnnouncementHandler  *announcements.Handler
+	TaskHandler          *tasks.Handler
+	ConflictHandler      *conflicts.Handler
+	AdvisorQueueHandler  *advisorrequests.Handler
+	ReviewHandler        *reviews.Handler
+	RealtimeHub          *realtime.Hub
+	RealtimeHandler      *realtime.Handler
 }
 
 func Bootstrap(cfg config.Config) (*App, error) {
@@ -46,30 +93,17 @@ func Bootstrap(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
-	// 2. Automigrate Models
-	err = db.AutoMigrate(
-		&domain.University{},
-		&domain.Department{},
-		&domain.User{},
-		&domain.Team{},
-		&domain.TeamMember{},
-		&domain.Proposal{},
-		&domain.ProposalVersion{},
-		&domain.Feedback{},
-		&domain.Project{},
-		&domain.ProjectDocumentation{},
-		&domain.ProjectReview{},
-		&domain.Notification{},
-		&domain.AuditLog{},
-	)
-	if err != nil {
+	// 2. Apply versioned migrations; refuses to start on pending migrations (without
+	// DB_AUTO_MIGRATE) or schema drift
+	migrator := database.NewMigrator(db)
+	if err := migrator.EnsureReady(cfg.DBAutoMigrate); err != nil {
 		return nil, err
 	}
 	log.Println("Database migration completed")
 
 	// 3. Seed Database with Initial Data
 	log.Println("Starting database seeding...")
-	if err := database.SeedDatabase(db); err != nil {
+	if err := database.SeedDatabase(db, cfg.SeedProfile); err != nil {
 		log.Printf("ERROR: Failed to seed database: %v", err)
 	} else {
 		log.Println("Database seeding completed successfully")
@@ -79,9 +113,49 @@ func Bootstrap(cfg config.Config) (*App, error) {
 	auditLogger := audit.NewLogger(db)
 	log.Println("Audit logger initialized")
 
+	// Event bus: services publish, side-effect modules subscribe
+	eventBus := events.NewBus()
+	auditLogger.Subscribe(eventBus)
+
+	// Settings: typed, cached values admins can change, with per-university overrides
+	settingsStore := settings.NewStore(db)
+
+	// Failed background jobs are kept for operators to retry
+	deadLetters := deadletter.NewQueue(db)
+
+	notificationRepo := notifications.NewRepository(db)
+	mail := mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom)
+	notificationService := notifications.NewService(notificationRepo, mail, deadLetters)
+	deadLetters.Handle(notifications.DeadLetterEmail, notificationService.RetryDeadLetter)
+	notificationService.RegisterSubscribers(eventBus)
+	notificationHandler := notifications.NewHandler(notificationService)
+
+	aiClient := ai_checker.NewClient(cfg.AIServiceURL, cfg.AIServiceAPIKey, ai_checker.ClientOptions{
+		MaxRetries:       cfg.AIMaxRetries,
+		AttemptTimeout:   time.Duration(cfg.AIAttemptTimeoutSeconds) * time.Second,
+		BreakerThreshold: cfg.AIBreakerThreshold,
+		BreakerCooldown:  time.Duration(cfg.AIBreakerCooldownSeconds) * time.Second,
+	})
+	deadLetters.Handle(ai_checker.DeadLetterSync, aiClient.RetryDeadLetter)
+
+	// Websocket hub: presence and live events for connected users
+	realtimeHub := realtime.NewHub()
+	realtimeHub.RegisterSubscribers(eventBus)
+	realtimeHandler := realtime.NewHandler(realtime.NewService(realtime.NewRepository(db), realtimeHub), realtimeHub)
+	log.Println("Event bus initialized")
+
+	// Permissions: role grants with department overrides, checked by route middleware
+	permissionRepo := permissions.NewRepository(db)
+	authorizer := permissions.NewAuthorizer(permissionRepo)
+	if err := authorizer.EnsureDefaults(); err != nil {
+		return nil, err
+	}
+	permissionHandler := permissions.NewHandler(authorizer, permissionRepo)
+	log.Println("Authorizer initialized")
+
 	// 4. Initialize Services (DI)
 	authRepo := auth.NewRepository(db)
-	authService := auth.NewService(authRepo, cfg, auditLogger)
+	authService := auth.NewService(authRepo, cfg, auditLogger, settingsStore)
 	authHandler := auth.NewHandler(authService)
 	log.Println("Authentication service initialized")
 
@@ -99,56 +173,146 @@ func Bootstrap(cfg config.Config) (*App, error) {
 
 	// 7. Initialize User Service
 	userRepo := users.NewRepository(db)
-	userService := users.NewService(userRepo)
+	userService := users.NewService(userRepo, eventBus, auditLogger, authService, mail, cfg.AppURL, settingsStore)
+	userService.RegisterSubscribers(eventBus)
 	userHandler := users.NewHandler(userService)
 	log.Println("User service initialized")
 
-	// 8. Initialize Team Service
-	teamRepo := teams.NewRepository(db)
-	teamService := teams.NewService(teamRepo)
-	teamHandler := teams.NewHandler(teamService)
-	log.Println("Team service initialized")
-
-	// 9. Initialize Proposal Service
+	// 8. Initialize Proposal Service
+	// Changes that span several tables or modules run in one unit of work
+	unitOfWork := uow.NewManager(db, eventBus)
 	proposalRepo := proposals.NewRepository(db)
 	// ⚠️ FIXED: Added 'db' argument for transaction support
-	proposalService := proposals.NewService(proposalRepo, db)
+	uploader := files.NewUploader(cfg.UploadDir)
+	storageQuota := files.NewQuota(db, cfg.TeamStorageQuotaMB, cfg.UserStorageQuotaMB)
+	proposalService := proposals.NewService(proposalRepo, db, unitOfWork, eventBus, auditLogger, uploader, storageQuota, files.NewColdStore(cfg.ColdStorageDir, uploader), settingsStore)
+	notificationService.UseBlindReview(proposalService)
 	log.Println("Proposal service initialized")
 
+	// 9. Initialize Team Service
+	// Advisor conflicts of interest and quotas are checked by the proposal service
+	teamRepo := teams.NewRepository(db)
+	teamService := teams.NewService(teamRepo, eventBus, auditLogger, settingsStore, proposalService)
+	// Blind review hides teams from advisors while their proposal awaits a decision
+	teamHandler := teams.NewHandler(teamService, proposalService)
+	log.Println("Team service initialized")
+
 	// 10. Initialize Feedback Service
+	// Feedback decisions update proposals and create projects in one unit of work
+	projectRepo := projects.NewRepository(db)
 	feedbackRepo := feedback.NewRepository(db)
-	feedbackService := feedback.NewService(feedbackRepo, proposalRepo)
+	feedbackService := feedback.NewService(feedbackRepo, proposalRepo, projectRepo, unitOfWork, eventBus, uploader)
+	if err := feedbackService.EnsureDefaultChecklist(); err != nil {
+		return nil, err
+	}
 	feedbackHandler := feedback.NewHandler(feedbackService)
 	log.Println("Feedback service initialized")
 
 	// 11. Initialize Project Service
-	projectRepo := projects.NewRepository(db)
 	// Ensure Project Service signature matches. Assuming it takes proposalRepo.
 	// If Project Service also needs DB now, check internal/projects/service.go
-	projectService := projects.NewService(projectRepo, proposalRepo)
-	projectHandler := projects.NewHandler(projectService)
-	uploader := files.NewUploader("./uploads")
+	taskRepo := tasks.NewRepository(db)
+	projectService := projects.NewService(projectRepo, proposalRepo, eventBus, aiClient, taskRepo, projects.ShareOptions{
+		Secret:         []byte(cfg.JWTSecret),
+		TokensRequired: cfg.ShareTokensRequired,
+	}, uploader)
+	projectHandler := projects.NewHandler(projectService, cfg.AppURL)
+	quarantine := files.NewQuarantine(db, uploader)
+	fileHandler := files.NewHandler(db, storageQuota, quarantine, uploader, auditLogger, proposalService)
 
 	log.Println("Project service initialized")
 
 	// 12. Initialize Documentation Service
 	documentationRepo := documentations.NewRepository(db)
-	documentationService := documentations.NewService(documentationRepo, uploader)
+	documentationService := documentations.NewService(documentationRepo, uploader, storageQuota, documentations.NewLinkChecker())
 	documentationHandler := documentations.NewHandler(documentationService)
 	log.Println("Documentation service initialized")
 
-	// 13. Initialize AI Checker Client/Handler
-	aiClient := ai_checker.NewClient(cfg.AIServiceURL, cfg.AIServiceAPIKey)
-	aiHandler := ai_checker.NewHandler(aiClient)
+	gradingService := grading.NewService(grading.NewRepository(db), eventBus)
+	if err := gradingService.EnsureDefaultRubric(); err != nil {
+		return nil, err
+	}
+	gradingHandler := grading.NewHandler(gradingService)
+
+	// External examiners: guest accounts limited to the projects they examine
+	examinerService := examiners.NewService(examiners.NewRepository(db), auditLogger, authService, mail, cfg.AppURL)
+	examinerHandler := examiners.NewHandler(examinerService)
+
+	// 13. Initialize AI Checker Handler and analysis queue
+	aiJobQueue := ai_checker.NewJobQueue(ai_checker.NewRepository(db), aiClient, eventBus, deadLetters, 2)
+	deadLetters.Handle(ai_checker.DeadLetterAnalysis, aiJobQueue.RetryDeadLetter)
+	aiSettings := ai_checker.NewSettings(ai_checker.NewRepository(db), aiClient)
+	aiClient.RegisterSubscribers(eventBus, deadLetters, aiSettings)
+	proposalService.RegisterArchiveMatching(eventBus, aiClient, aiSettings)
+	aiHandler := ai_checker.NewHandler(aiClient, aiJobQueue, aiSettings)
 	log.Println("AI checker initialized")
 
 	// Wire Proposal Handler after AI client is ready
-	proposalHandler := proposals.NewHandler(proposalService, aiClient)
+	proposalHandler := proposals.NewHandler(proposalService, aiClient, aiSettings)
+
+	// GraphQL reads through the same services for role-aware access
+	graphqlHandler := graphql.NewHandler(teamService, proposalService, db)
+
+	maintenanceMode := maintenance.NewMode(db, cfg.MaintenanceMode)
+	healthChecker := newHealthChecker(cfg, db, uploader, aiClient, mail, aiJobQueue, deadLetters)
+	systemHandler := system.NewHandler(cfg, migrator, deadLetters, maintenanceMode, healthChecker, settingsStore)
+
+	delegationService := delegations.NewService(delegations.NewRepository(db), auditLogger)
+	delegationHandler := delegations.NewHandler(delegationService)
+	log.Println("Delegation service initialized")
+
+	analyticsHandler := analytics.NewHandler(analytics.NewService(analytics.NewRepository(db)))
+	log.Println("Analytics service initialized")
+
+	searchHandler := search.NewHandler(search.NewService(search.NewRepository(db), authorizer))
+	log.Println("Search service initialized")
+
+	announcementHandler := announcements.NewHandler(announcements.NewService(announcements.NewRepository(db), uploader, eventBus))
+	log.Println("Announcement service initialized")
+
+	taskHandler := tasks.NewHandler(tasks.NewService(taskRepo, eventBus))
+	log.Println("Task service initialized")
+
+	conflictHandler := conflicts.NewHandler(conflicts.NewService(conflicts.NewRepository(db), auditLogger))
+	log.Println("Conflict of interest service initialized")
+
+	advisorRequestHandler := advisorrequests.NewHandler(advisorrequests.NewService(advisorrequests.NewRepository(db), eventBus, proposalService))
+	log.Println("Advisor request service initialized")
+
+	reviewHandler := reviews.NewHandler(reviews.NewService(reviews.NewRepository(db), projectRepo, auditLogger))
+	log.Println("Project review service initialized")
+
+	// 14. Register background jobs (started by the server)
+	jobScheduler := scheduler.NewScheduler()
+	jobScheduler.Every("expired-invitation-cleanup", time.Hour, teamService.CleanupExpiredInvitations)
+	jobScheduler.Every("team-leader-activity", teams.LeaderActivityCheckInterval, teamService.ProcessLeaderActivity)
+	jobScheduler.Every("notification-retention", 24*time.Hour, notificationService.CleanupOldNotifications)
+	jobScheduler.Every("dashboard-stats-refresh", users.DashboardRefreshInterval, userService.RefreshDashboards)
+	jobScheduler.Every("revision-deadlines", feedback.RevisionDeadlineCheckInterval, feedbackService.ProcessRevisionDeadlines)
+	jobScheduler.Every("submission-reminders", proposals.SubmissionReminderCheckInterval, proposalService.ProcessSubmissionReminders)
+	jobScheduler.Every("expired-session-cleanup", 24*time.Hour, authService.CleanupExpiredSessions)
+	jobScheduler.Every("token-denylist-cleanup", time.Hour, authService.CleanupRevokedTokens)
+	jobScheduler.Every("data-export-cleanup", time.Hour, userService.CleanupDataExports)
+	jobScheduler.Every("documentation-link-recheck", documentations.LinkRecheckInterval, documentationService.RecheckLinks)
+	jobScheduler.Every("version-file-retention", proposals.VersionRetentionInterval, proposalService.ApplyVersionRetention)
+	jobScheduler.Every("proposal-abandonment", proposals.AbandonmentCheckInterval, proposalService.ProcessAbandonment)
+	jobScheduler.Every("assignment-escalation", proposals.AssignmentEscalationInterval, proposalService.EscalateUnansweredAssignments)
+	jobScheduler.Every("advisor-workload-snapshots", proposals.WorkloadSnapshotInterval, proposalService.CaptureWorkloadSnapshots)
+	jobScheduler.Every("quarantine-rescan", files.QuarantineRescanInterval, quarantine.Rescan)
+	jobScheduler.Every("proposal-document-text", proposals.DocumentTextInterval, proposalService.ExtractPendingDocumentTexts)
+	jobScheduler.Every("examiner-access-expiry", examiners.ExpiryCheckInterval, examinerService.CloseLapsedAccounts)
+	jobScheduler.Every("scheduled-project-publishing", projects.ScheduledPublishInterval, projectService.PublishScheduledProjects)
+	log.Println("Scheduler initialized")
 
 	return &App{
 		Config:               cfg,
 		DB:                   db,
 		AuditLogger:          auditLogger,
+		EventBus:             eventBus,
+		Scheduler:            jobScheduler,
+		Maintenance:          maintenanceMode,
+		UploadDir:            uploader.UploadDir,
+		AIJobQueue:           aiJobQueue,
 		AuthService:          authService,
 		AuthHandler:          authHandler,
 		UniversityHandler:    universityHandler,
@@ -159,6 +323,26 @@ func Bootstrap(cfg config.Config) (*App, error) {
 		FeedbackHandler:      feedbackHandler,
 		ProjectHandler:       projectHandler,
 		DocumentationHandler: documentationHandler,
+		GradingHandler:       gradingHandler,
+		ExaminerService:      examinerService,
+		ExaminerHandler:      examinerHandler,
+		FileHandler:          fileHandler,
 		AICheckerHandler:     aiHandler,
+		GraphQLHandler:       graphqlHandler,
+		SystemHandler:        systemHandler,
+		DelegationService:    delegationService,
+		DelegationHandler:    delegationHandler,
+		NotificationHandler:  notificationHandler,
+		Authorizer:           authorizer,
+		PermissionHandler:    permissionHandler,
+		AnalyticsHandler:     analyticsHandler,
+		SearchHandler:        searchHandler,
+		AnnouncementHandler:  announcementHandler,
+		TaskHandler:          taskHandler,
+		ConflictHandler:      conflictHandler,
+		AdvisorQueueHandler:  advisorRequestHandler,
+		ReviewHandler:        reviewHandler,
+		RealtimeHub:          realtimeHub,
+		RealtimeHandler:      realtimeHandler,
 	}, nil
 }
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"backend/config"
+	"backend/internal/ai_checker"
+	"backend/internal/files"
+	"backend/pkg/deadletter"
+	"backend/pkg/health"
+	"backend/pkg/mailer"
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+// aiBacklogLimit is the analysis backlog above which the queues are reported as degraded; it
+// matches the job queue's buffer
+const aiBacklogLimit = 100
+
+// newHealthChecker registers the dependency checks behind /health. Only the database is
+// critical: without the AI service, mail or a writable upload directory the rest still works.
+func newHealthChecker(cfg config.Config, db *gorm.DB, uploader *files.Uploader, aiClient *ai_checker.Client, mail *mailer.Mailer, aiJobs *ai_checker.JobQueue, deadLetters *deadletter.Queue) *health.Checker {
+	checker := health.NewChecker()
+
+	checker.Register("database", true, health.Database(db))
+
+	checker.Register("storage", false, health.Directories(map[string]string{
+		"uploads":         uploader.UploadDir,
+		"private_uploads": filepath.Join(filepath.Dir(uploader.UploadDir), files.PrivateDir),
+		"cold_storage":    cfg.ColdStorageDir,
+	}))
+
+	checker.Register("ai_service", false, func(ctx context.Context) health.Result {
+		if cfg.AIServiceURL == "" {
+			return health.Result{Status: health.LevelDisabled, Message: "AI service is not configured"}
+		}
+		result := health.Result{Status: health.LevelOK, Metrics: map[string]interface{}{"circuit": aiClient.CircuitState()}}
+		if err := aiClient.Health(ctx); err != nil {
+			result.Status = health.LevelDown
+			result.Message = "AI service unreachable"
+			result.Error = err.Error()
+		} else if aiClient.CircuitState() == "half_open" {
+			result.Status = health.LevelDegraded
+			result.Message = "AI service is recovering from failures"
+		}
+		return result
+	})
+
+	checker.Register("mail", false, func(ctx context.Context) health.Result {
+		if !mail.Enabled() {
+			return health.Result{Status: health.LevelDisabled, Message: "email is not configured"}
+		}
+		if err := mail.Ping(ctx); err != nil {
+			return health.Result{Status: health.LevelDown, Message: "mail server unreachable", Error: err.Error()}
+		}
+		return health.Result{Status: health.LevelOK}
+	})
+
+	// Emails are sent as they happen, so the queues worth watching are the AI analysis backlog
+	// and the failed jobs waiting for an operator
+	checker.Register("queues", false, func(ctx context.Context) health.Result {
+		backlog, err := aiJobs.Backlog()
+		if err != nil {
+			return health.Result{Status: health.LevelDegraded, Message: "failed to read the AI job backlog", Error: err.Error()}
+		}
+		failed, err := deadLetters.Count()
+		if err != nil {
+			return health.Result{Status: health.LevelDegraded, Message: "failed to count failed jobs", Error: err.Error()}
+		}
+		result := health.Result{Status: health.LevelOK, Metrics: map[string]interface{}{
+			"ai_jobs_pending": backlog,
+			"failed_jobs":     failed,
+		}}
+		if backlog >= aiBacklogLimit {
+			result.Status = health.LevelDegraded
+			result.Message = fmt.Sprintf("%d AI analyses are waiting", backlog)
+		}
+		return result
+	})
+
+	return checker
+}
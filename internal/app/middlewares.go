@@ -3,11 +3,20 @@ package app
 import (
 	"backend/config"
 	"backend/internal/auth"
+	"backend/internal/delegations"
+	"backend/internal/domain"
+	"backend/internal/examiners"
+	"backend/internal/permissions"
 	"backend/pkg/audit"
 	"backend/pkg/enums"
+	"backend/pkg/maintenance"
 	"backend/pkg/response"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -45,8 +54,54 @@ func RequestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AuthMiddleware validates JWT tokens and sets user context
-func AuthMiddleware(cfg config.Config) gin.HandlerFunc {
+// QueryTokenMiddleware accepts the JWT from the access_token query parameter when there is no
+// Authorization header, for clients such as browser websockets that cannot set headers. Only the
+// stream routes use it. The parameter is removed from the request so handlers never see it, and
+// RequestLogger redacts it from the access log.
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get(accessTokenParam); token != "" {
+			if c.GetHeader("Authorization") == "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+			query.Del(accessTokenParam)
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+const accessTokenParam = "access_token"
+
+// RequestLogger is gin's access log with credentials passed in the query string redacted
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			p.TimeStamp.Format("2006/01/02 - 15:04:05"), p.StatusCode, p.Latency, p.ClientIP, p.Method,
+			redactQuery(p.Path), p.ErrorMessage)
+	})
+}
+
+// redactQuery masks the access token in a logged path such as /api/v1/ws?access_token=...
+func redactQuery(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?[unparsable query]"
+	}
+	if !query.Has(accessTokenParam) {
+		return path
+	}
+	query.Set(accessTokenParam, "REDACTED")
+	return base + "?" + query.Encode()
+}
+
+// AuthMiddleware validates JWT tokens and their login session and sets user context
+func AuthMiddleware(cfg config.Config, authService auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -72,6 +127,11 @@ func AuthMiddleware(cfg config.Config) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if err := authService.CheckSession(claims); err != nil {
+			response.Error(c, http.StatusUnauthorized, "Session is no longer active", err.Error())
+			c.Abort()
+			return
+		}
 
 		// Set user context
 		c.Set("user_id", claims.UserID)
@@ -80,11 +140,30 @@ func AuthMiddleware(cfg config.Config) gin.HandlerFunc {
 		c.Set("department_id", claims.DepartmentID)
 		c.Set("university_id", claims.UniversityID)
         c.Set("claims", claims) 
+		if claims.ImpersonatorID != 0 {
+			c.Set("impersonator_id", claims.ImpersonatorID)
+		}
+		if claims.ID != "" {
+			c.Set("session_id", claims.ID)
+		}
 
 		c.Next()
 	}
 }
 
+// OptionalAuthMiddleware authenticates requests that carry a token and lets anonymous ones
+// through, for routes such as public file downloads whose access depends on the resource
+func OptionalAuthMiddleware(cfg config.Config, authService auth.Service) gin.HandlerFunc {
+	authenticate := AuthMiddleware(cfg, authService)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
 // RoleMiddleware checks if user has required role
 func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -127,6 +206,144 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequirePermission admits users whose role holds the permission in their department
+func RequirePermission(authorizer *permissions.Authorizer, permission permissions.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := c.Get("user_role")
+		if !ok {
+			response.Error(c, http.StatusUnauthorized, "User role not found", nil)
+			c.Abort()
+			return
+		}
+
+		if r, isRole := role.(enums.Role); !isRole || !authorizer.Can(r, c.GetUint("department_id"), permission) {
+			response.Error(c, http.StatusForbidden, "Insufficient permissions", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DelegatedPermissionMiddleware admits users holding the permission, and teachers currently holding a
+// delegation from a department admin who does. For delegates the delegator is recorded in the context
+// for the audit trail.
+func DelegatedPermissionMiddleware(authorizer *permissions.Authorizer, delegationService *delegations.Service, permission permissions.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("user_role")
+		r, _ := role.(enums.Role)
+		departmentID := c.GetUint("department_id")
+
+		if authorizer.Can(r, departmentID, permission) {
+			c.Next()
+			return
+		}
+
+		if authorizer.Can(r, departmentID, permissions.DelegationHold) {
+			delegation, ok := delegationService.ResolveActive(c.GetUint("user_id"))
+			if ok && delegation.DepartmentID == departmentID && authorizer.Can(enums.RoleAdmin, departmentID, permission) {
+				c.Set("delegator_id", delegation.DelegatorID)
+				c.Set("delegation_id", delegation.ID)
+				c.Next()
+				return
+			}
+		}
+
+		response.Error(c, http.StatusForbidden, "Insufficient permissions", nil)
+		c.Abort()
+	}
+}
+
+// examinerRoutes are what external examiners' guest accounts can call, with the path parameter
+// holding the project they must examine ("" for routes about the examiner themselves)
+var examinerRoutes = map[string]string{
+	"GET /auth/profile":                          "",
+	"GET /examiner/projects":                     "",
+	"GET /users/me/security":                     "",
+	"DELETE /users/me/sessions":                  "",
+	"DELETE /users/me/sessions/:id":              "",
+	"GET /projects/:id":                          "id",
+	"GET /projects/:id/documentation":            "id",
+	"GET /projects/:id/docs/:docId/preview":      "id",
+	"GET /projects/:id/grading":                  "id",
+	"PUT /projects/:id/grading/sheet":            "id",
+	"GET /files/projects/:project_id/:filename":  "project_id",
+	"HEAD /files/projects/:project_id/:filename": "project_id",
+}
+
+// ExaminerScopeMiddleware confines external examiners to examinerRoutes and the projects they
+// examine. It runs after authentication; other users and anonymous requests go through.
+func ExaminerScopeMiddleware(examinerService *examiners.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role != enums.RoleExaminer {
+			c.Next()
+			return
+		}
+
+		route := c.Request.Method + " " + strings.TrimPrefix(c.FullPath(), "/api/"+CurrentAPIVersion)
+		param, allowed := examinerRoutes[route]
+		if allowed && param != "" {
+			projectID, err := strconv.ParseUint(c.Param(param), 10, 32)
+			allowed = err == nil && examinerService.CanOpenProject(c.GetUint("user_id"), uint(projectID))
+		}
+		if !allowed {
+			response.Error(c, http.StatusForbidden, "External examiners can only open the projects they examine", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// maintenanceOpenPaths stay writable during maintenance, so admins can still log in
+var maintenanceOpenPaths = []string{"/auth/login", "/auth/refresh"}
+
+// MaintenanceMiddleware refuses writes with 503 while maintenance mode is on. Reads, logging in and
+// requests from users holding system.config go through. It runs before authentication, so the
+// token is only checked here for the role; the route's own middleware still validates the session.
+func MaintenanceMiddleware(cfg config.Config, authorizer *permissions.Authorizer, mode *maintenance.Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		status := mode.Status()
+		if !status.Enabled {
+			c.Next()
+			return
+		}
+
+		for _, path := range maintenanceOpenPaths {
+			if strings.HasSuffix(c.Request.URL.Path, path) {
+				c.Next()
+				return
+			}
+		}
+
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			if claims, err := auth.ValidateToken(token, cfg); err == nil && authorizer.Can(claims.Role, claims.DepartmentID, permissions.SystemConfig) {
+				c.Next()
+				return
+			}
+		}
+
+		if status.EndsAt != nil {
+			if wait := time.Until(*status.EndsAt); wait > 0 {
+				c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+			}
+		}
+		response.Error(c, http.StatusServiceUnavailable, status.Message, gin.H{
+			"maintenance": true,
+			"ends_at":     status.EndsAt,
+		})
+		c.Abort()
+	}
+}
+
 // RBACMiddleware is an alias for RoleMiddleware for backward compatibility
 func RBACMiddleware(allowedRoles []string) gin.HandlerFunc {
 	return RoleMiddleware(allowedRoles...)
@@ -138,60 +355,81 @@ func AuditMiddleware(auditLogger *audit.Logger) gin.HandlerFunc {
 		// Capture request start time
 		startTime := time.Now()
 
-		// Get request context
+		// Process request
+		c.Next()
+
+		// Log after request completes; identity is only known once AuthMiddleware has run
+		duration := time.Since(startTime)
+
 		requestID, _ := c.Get("request_id")
 		userID, _ := c.Get("user_id")
 		userEmail, _ := c.Get("user_email")
 		userRole, _ := c.Get("user_role")
+		impersonatorID, impersonating := c.Get("impersonator_id")
+		delegatorID, delegated := c.Get("delegator_id")
+		sessionID := c.GetString("session_id")
 
-		// Process request
-		c.Next()
+		// Only log write operations (POST, PUT, DELETE, PATCH), plus every request made while impersonating or under delegation
+		if c.Request.Method == "OPTIONS" || (c.Request.Method == "GET" && !impersonating && !delegated) {
+			return
+		}
 
-		// Log after request completes
-		duration := time.Since(startTime)
+		var actorID *uint
+		if userID != nil {
+			id := userID.(uint)
+			actorID = &id
+		}
 
-		// Only log write operations (POST, PUT, DELETE, PATCH)
-		if c.Request.Method != "GET" && c.Request.Method != "OPTIONS" {
-			var actorID *uint
-			if userID != nil {
-				id := userID.(uint)
-				actorID = &id
-			}
+		role := ""
+		if userRole != nil {
+			role = string(userRole.(enums.Role))
+		}
 
-			role := ""
-			if userRole != nil {
-				role = string(userRole.(enums.Role))
-			}
+		email := ""
+		if userEmail != nil {
+			email = userEmail.(string)
+		}
 
-			email := ""
-			if userEmail != nil {
-				email = userEmail.(string)
-			}
+		reqID := ""
+		if requestID != nil {
+			reqID = requestID.(string)
+		}
 
-			reqID := ""
-			if requestID != nil {
-				reqID = requestID.(string)
-			}
+		var impersonator *uint
+		if impersonating {
+			id := impersonatorID.(uint)
+			impersonator = &id
+		}
 
-			// Log the action
-			auditLogger.LogAction(
-				"http_request",
-				0, // No specific entity ID for general requests
-				c.Request.Method+" "+c.Request.URL.Path,
-				actorID,
-				role,
-				email,
-				nil, // No old state for HTTP requests
-				map[string]interface{}{
-					"status_code": c.Writer.Status(),
-					"duration_ms": duration.Milliseconds(),
-				},
-				c.ClientIP(),
-				c.GetHeader("User-Agent"),
-				reqID,
-				"", // Session ID not used for HTTP requests
-			)
+		var delegator *uint
+		if delegated {
+			id := delegatorID.(uint)
+			delegator = &id
 		}
+
+		newState, _ := json.Marshal(map[string]interface{}{
+			"status_code": c.Writer.Status(),
+			"duration_ms": duration.Milliseconds(),
+		})
+
+		// Log the action
+		auditLogger.Log(&domain.AuditLog{
+			EntityType:     "http_request",
+			EntityID:       0, // No specific entity ID for general requests
+			Action:         c.Request.Method + " " + c.Request.URL.Path,
+			ActorID:        actorID,
+			ActorRole:      role,
+			ActorEmail:     email,
+			ImpersonatorID: impersonator,
+			DelegatorID:    delegator,
+			OldState:       "null", // No old state for HTTP requests
+			NewState:       string(newState),
+			IPAddress:      c.ClientIP(),
+			UserAgent:      c.GetHeader("User-Agent"),
+			RequestID:      reqID,
+			SessionID:      sessionID,
+			Timestamp:      time.Now(),
+		})
 	}
 }
 
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"backend/internal/permissions"
 	"backend/pkg/response"
 	"net/http"
 
@@ -11,29 +12,68 @@ import (
 )
 
 func NewRouter(app *App) *gin.Engine {
-	r := gin.Default()
+	r := gin.New()
+	r.Use(RequestLogger(), gin.Recovery())
 
-	r.Static("/uploads", "./uploads")
+	// Routes record the guards they are registered with, so the API reference can be split per role
+	table := newRouteTable()
+
+	// can guards a route with a permission resolved by the authorizer
+	can := func(permission permissions.Permission) gin.HandlerFunc {
+		table.pending.Permission = permission
+		return RequirePermission(app.Authorizer, permission)
+	}
+	// canOrDelegate also admits teachers holding a delegation of the permission
+	canOrDelegate := func(permission permissions.Permission) gin.HandlerFunc {
+		table.pending.Permission = permission
+		table.pending.Delegated = true
+		return DelegatedPermissionMiddleware(app.Authorizer, app.DelegationService, permission)
+	}
+	authenticated := func() gin.HandlerFunc {
+		table.pending.Authenticated = true
+		return AuthMiddleware(app.Config, app.AuthService)
+	}
+
+	// Quarantined uploads stay on disk but are not served until their scan passes
+	r.Group("/uploads", app.FileHandler.BlockQuarantined).Static("/", app.UploadDir)
 	// Global Middlewares
 	r.Use(CORSMiddleware())
 	r.Use(RequestIDMiddleware())
 	r.Use(AuditMiddleware(app.AuditLogger))
 	r.Use(RateLimitMiddleware())
+	r.Use(MaintenanceMiddleware(app.Config, app.Authorizer, app.Maintenance))
 
-	// Swagger UI
+	// Swagger UI: the full reference, and one per role listing only the endpoints it can call
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
-	// Health Check
-	r.GET("/health", func(c *gin.Context) {
-		response.JSON(c, http.StatusOK, "System is healthy", gin.H{
-			"status":   "ok",
-			"database": "connected",
-		})
+	registerRoleDocs(table, app.Authorizer)
+	roleDocs := make(map[string]gin.HandlerFunc)
+	for _, group := range APIDocGroups {
+		roleDocs[group] = ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.InstanceName("api-"+group))
+	}
+	r.GET("/api-docs/:group/*any", func(c *gin.Context) {
+		handler, ok := roleDocs[c.Param("group")]
+		if !ok {
+			response.Error(c, http.StatusNotFound, "Unknown API documentation group", gin.H{"groups": APIDocGroups})
+			return
+		}
+		handler(c)
 	})
 
+	// Health Check; kept at the root for load balancer probes
+	r.GET("/health", app.SystemHandler.Health)
+
+	// Permanent project links, e.g. /p/ASTU-2025-0042, and the sitemap are web pages, not API
+	// endpoints, so they stay outside /api
+	r.GET("/p/:slug", app.ProjectHandler.RedirectBySlug)
+	r.GET("/sitemap.xml", app.ProjectHandler.GetSitemap)
+
+	// Requests to /api without a version are redirected to the negotiated one
+	r.NoRoute(redirectUnversionedAPI)
+
 	// API v1 Routes
-	v1 := r.Group("/api/v1")
+	v1 := table.wrap(r.Group("/api/"+CurrentAPIVersion, APIVersionMiddleware(CurrentAPIVersion)))
 	{
+		v1.GET("/health", app.SystemHandler.Health)
 
 		{
 			// Universities
@@ -48,9 +88,23 @@ func NewRouter(app *App) *gin.Engine {
 			{
 				departments.GET("", app.DepartmentHandler.GetDepartments)
 				departments.GET("/:id", app.DepartmentHandler.GetDepartment)
+				departments.GET("/:id/showcase", app.DepartmentHandler.GetShowcase)
 			}
 		}
 
+		// Public project archive (no authentication)
+		publicProjects := v1.Group("/projects/public")
+		{
+			publicProjects.GET("", app.ProjectHandler.GetPublicProjects)
+			publicProjects.GET("/feed.xml", app.ProjectHandler.GetPublicFeed)
+			publicProjects.GET("/departments", app.ProjectHandler.GetDepartmentArchives)
+			publicProjects.GET("/:id", app.ProjectHandler.GetPublicProject)
+			publicProjects.GET("/:id/related", app.ProjectHandler.GetRelatedProjects)
+			publicProjects.POST("/:id/share", app.ProjectHandler.IncrementShareCount)
+			publicProjects.GET("/:id/share-token", app.ProjectHandler.GetShareToken)
+		}
+		v1.GET("/projects/:id/reviews", app.ReviewHandler.GetProjectReviews)
+
 		// Public Auth Routes
 		authRoutes := v1.Group("/auth")
 		{
@@ -58,28 +112,80 @@ func NewRouter(app *App) *gin.Engine {
 			authRoutes.POST("/login", app.AuthHandler.Login)
 			authRoutes.POST("/refresh", app.AuthHandler.RefreshToken)
 		}
+		// Opened from the link emailed to the new address, possibly on another device
+		v1.POST("/users/confirm-email", app.UserHandler.ConfirmEmailChange)
+
+		// Project file downloads; public project files need no token
+		projectFiles := v1.Group("/files/projects", OptionalAuthMiddleware(app.Config, app.AuthService), ExaminerScopeMiddleware(app.ExaminerService))
+		{
+			projectFiles.GET("/:project_id/:filename", app.FileHandler.DownloadProjectFile)
+			projectFiles.HEAD("/:project_id/:filename", app.FileHandler.DownloadProjectFile)
+		}
+
+		// The stream routes are the only ones that take the token as a query parameter.
+		// Realtime websocket; browsers pass the token as a query parameter
+		v1.GET("/ws", QueryTokenMiddleware(), authenticated(), app.RealtimeHandler.Connect)
+		// Live dashboard for department heads; EventSource cannot set headers either
+		v1.GET("/admin/stats/stream", QueryTokenMiddleware(), authenticated(), can(permissions.StatsView), app.UserHandler.StreamDashboardStats)
 
 		// Protected Routes (require authentication)
 		protected := v1.Group("")
-		protected.Use(AuthMiddleware(app.Config))
+		protected.Use(authenticated(), ExaminerScopeMiddleware(app.ExaminerService))
 		{
 			// Auth Profile
 			protected.GET("/auth/profile", app.AuthHandler.GetProfile)
+			protected.POST("/auth/impersonation/end", app.AuthHandler.EndImpersonation)
+
+			// GraphQL (read-only, nested dashboard queries)
+			protected.POST("/graphql", app.GraphQLHandler.Query)
 			//  NEW: Peer List for Invites
 			protected.GET("/users/peers", app.UserHandler.GetPeers)
+			protected.GET("/users/peers/search", app.UserHandler.SearchPeers)
+			protected.GET("/users/me/export", app.UserHandler.ExportMyData)
+			protected.POST("/users/me/change-email", app.UserHandler.ChangeEmail)
+			protected.GET("/users/me/invitations", app.TeamHandler.GetMyInvitations)
+			protected.GET("/users/me/delegations", can(permissions.DelegationHold), app.DelegationHandler.GetMyDelegations)
+			protected.GET("/users/me/presence", app.RealtimeHandler.GetPresenceSettings)
+			protected.GET("/users/me/security", app.AuthHandler.GetSecurity)
+			protected.DELETE("/users/me/sessions", app.AuthHandler.RevokeOtherSessions)
+			protected.DELETE("/users/me/sessions/:id", app.AuthHandler.RevokeSession)
+			protected.PUT("/users/me/presence", app.RealtimeHandler.UpdatePresenceSettings)
+			protected.GET("/universities/:id/metrics", can(permissions.StatsView), app.UniversityHandler.GetMetrics)
+			protected.GET("/examiner/projects", app.ExaminerHandler.GetMyExaminations)
+			// Proposal file downloads
+			protected.GET("/files/proposals/:proposal_id/:filename", app.FileHandler.DownloadProposalFile)
+			protected.HEAD("/files/proposals/:proposal_id/:filename", app.FileHandler.DownloadProposalFile)
+			protected.GET("/files/feedback/:feedback_id/:attachment_id", app.FileHandler.DownloadFeedbackAttachment)
 			// Teams (Students)
 			teams := protected.Group("/teams")
 			{
-				teams.POST("", RoleMiddleware("student"), app.TeamHandler.CreateTeam)
+				teams.POST("", can(permissions.TeamManage), app.TeamHandler.CreateTeam)
 				teams.GET("", app.TeamHandler.GetTeams)
 				teams.GET("/:id", app.TeamHandler.GetTeam)
 				teams.GET("/:id/members", app.TeamHandler.GetTeamMembers)
-				teams.POST("/:id/invite", RoleMiddleware("student"), app.TeamHandler.InviteMember)
-				teams.POST("/:id/invitation/respond", RoleMiddleware("student"), app.TeamHandler.RespondToInvitation)
-				teams.DELETE("/:id/members/:memberId", RoleMiddleware("student"), app.TeamHandler.RemoveMember)
-				teams.POST("/:id/transfer-leadership", RoleMiddleware("student"), app.TeamHandler.TransferLeadership)
-				teams.DELETE("/:id", RoleMiddleware("student"), app.TeamHandler.DeleteTeam)
-				teams.POST("/:id/finalize", RoleMiddleware("student"), app.TeamHandler.FinalizeTeam)
+				teams.GET("/:id/storage-usage", app.FileHandler.GetTeamStorageUsage)
+				teams.GET("/:id/advisor/presence", app.RealtimeHandler.GetAdvisorPresence)
+				teams.POST("/:id/invite", can(permissions.TeamManage), app.TeamHandler.InviteMember)
+				teams.POST("/:id/invitation/respond", can(permissions.TeamJoin), app.TeamHandler.RespondToInvitation)
+				teams.POST("/:id/invitations/:userId/resend", can(permissions.TeamManage), app.TeamHandler.ResendInvitation)
+				teams.DELETE("/:id/members/:memberId", can(permissions.TeamManage), app.TeamHandler.RemoveMember)
+				teams.PATCH("/:id/members/:memberId", can(permissions.TeamManage), app.TeamHandler.UpdateMemberProfile)
+				teams.POST("/:id/transfer-leadership", can(permissions.TeamManage), app.TeamHandler.TransferLeadership)
+				teams.GET("/:id/leadership", app.TeamHandler.GetLeadership)
+				teams.POST("/:id/leadership/vote", can(permissions.TeamManage), app.TeamHandler.StartLeadershipVote)
+				teams.POST("/:id/leadership/vote/ballots", can(permissions.TeamManage), app.TeamHandler.CastLeadershipBallot)
+				teams.DELETE("/:id", can(permissions.TeamManage), app.TeamHandler.DeleteTeam)
+				teams.POST("/:id/finalize", can(permissions.TeamManage), app.TeamHandler.FinalizeTeam)
+				teams.GET("/:id/tasks", app.TaskHandler.GetTeamTasks)
+				teams.POST("/:id/tasks", app.TaskHandler.CreateTask)
+				teams.PATCH("/:id/tasks/:taskId", app.TaskHandler.UpdateTask)
+				teams.DELETE("/:id/tasks/:taskId", app.TaskHandler.DeleteTask)
+				teams.GET("/:id/advisor-requests", app.AdvisorQueueHandler.GetTeamQueue)
+				teams.POST("/:id/advisor-requests", can(permissions.TeamManage), app.AdvisorQueueHandler.SubmitPreferences)
+				teams.DELETE("/:id/advisor-requests", can(permissions.TeamManage), app.AdvisorQueueHandler.WithdrawPreferences)
+				teams.GET("/:id/proposal/draft", app.ProposalHandler.GetTeamDraft)
+				teams.GET("/:id/deadline-extensions", app.ProposalHandler.GetTeamDeadlineExtensions)
+				teams.POST("/:id/deadline-extensions", can(permissions.TeamManage), app.ProposalHandler.RequestDeadlineExtension)
 			}
 
 			// Proposals (Students & Teachers)
@@ -87,15 +193,16 @@ func NewRouter(app *App) *gin.Engine {
 			{
 				// 1. Create a new Draft (Student Only)
 				// POST /api/v1/proposals
-				proposals.POST("", RoleMiddleware("student"), app.ProposalHandler.CreateProposal)
+				proposals.POST("", can(permissions.ProposalWrite), app.ProposalHandler.CreateProposal)
 
 				// 2. Update Draft OR Create Revision (Student Only)
 				// PUT /api/v1/proposals/:id
-				proposals.PUT("/:id", RoleMiddleware("student"), app.ProposalHandler.UpdateProposal)
+				proposals.PUT("/:id", can(permissions.ProposalWrite), app.ProposalHandler.UpdateProposal)
+				proposals.PATCH("/:id/draft", can(permissions.ProposalWrite), app.ProposalHandler.AutosaveDraft)
 
 				// 3. Submit Proposal (Student Only - Leader)
 				// POST /api/v1/proposals/:id/submit
-				proposals.POST("/:id/submit", RoleMiddleware("student"), app.ProposalHandler.SubmitProposal)
+				proposals.POST("/:id/submit", can(permissions.ProposalWrite), app.ProposalHandler.SubmitProposal)
 
 				// 4. View Proposals (Students see theirs, Teachers see dept proposals)
 				// GET /api/v1/proposals
@@ -108,44 +215,207 @@ func NewRouter(app *App) *gin.Engine {
 				// 6. View Version History
 				// GET /api/v1/proposals/:id/versions
 				proposals.GET("/:id/versions", app.ProposalHandler.GetVersions)
+				proposals.GET("/:id/validation", app.ProposalHandler.ValidateProposal)
+				proposals.GET("/:id/revision-response", app.ProposalHandler.GetRevisionResponse)
+				proposals.GET("/:id/archive-matches", app.ProposalHandler.GetArchiveMatches)
+				proposals.POST("/:id/assignment", can(permissions.FeedbackWrite), app.ProposalHandler.RespondToAssignment)
+				proposals.PATCH("/:id/versions/:vid/file", can(permissions.ProposalWrite), app.ProposalHandler.ReplaceVersionFile)
+				proposals.GET("/:id/versions/:vid/files", app.ProposalHandler.GetVersionFileHistory)
+				proposals.GET("/:id/file-access", app.FileHandler.GetProposalFileAccess)
+				proposals.POST("/:id/versions/:vid/restore-file", app.ProposalHandler.RestoreVersionFile)
 
 				// 7. Delete Draft (Student Only)
 				// DELETE /api/v1/proposals/:id
-				proposals.DELETE("/:id", RoleMiddleware("student"), app.ProposalHandler.DeleteProposal)
+				proposals.DELETE("/:id", can(permissions.ProposalWrite), app.ProposalHandler.DeleteProposal)
+
+				// 8. Structured timeline (phases) and Gantt chart data
+				proposals.GET("/:id/timeline", app.ProposalHandler.GetTimeline)
+				proposals.GET("/:id/timeline/gantt", app.ProposalHandler.GetGantt)
+				proposals.POST("/:id/timeline/phases", can(permissions.ProposalWrite), app.ProposalHandler.CreatePhase)
+				proposals.PUT("/:id/timeline/phases/:phaseId", can(permissions.ProposalWrite), app.ProposalHandler.UpdatePhase)
+				proposals.DELETE("/:id/timeline/phases/:phaseId", can(permissions.ProposalWrite), app.ProposalHandler.DeletePhase)
 			}
 
 			// AI Checker (Authenticated users)
 			aichecker := protected.Group("/ai-checker")
 			{
 				aichecker.GET("/health", app.AICheckerHandler.HealthCheck)
-				aichecker.POST("/proposal-check", RoleMiddleware("student", "advisor", "admin"), app.AICheckerHandler.CheckProposalText)
-				aichecker.POST("/proposal-check-file", RoleMiddleware("student", "advisor", "admin"), app.AICheckerHandler.CheckProposalFile)
+				aichecker.POST("/proposal-check", can(permissions.AICheck), app.AICheckerHandler.CheckProposalText)
+				aichecker.POST("/proposal-check-file", can(permissions.AICheck), app.AICheckerHandler.CheckProposalFile)
+			}
+
+			// Whether to show AI features to the current user
+			protected.GET("/settings/ai", app.AICheckerHandler.GetSettings)
+
+			// AI analysis jobs (async)
+			ai := protected.Group("/ai")
+			{
+				ai.POST("/analyze-proposal", app.AICheckerHandler.AnalyzeProposal)
+				ai.GET("/jobs/:id", app.AICheckerHandler.GetJob)
 			}
 			// Feedback (Teachers)
 			feedback := protected.Group("/feedback")
-			feedback.Use(RoleMiddleware("advisor"))
+			feedback.Use(can(permissions.FeedbackWrite))
 			{
 				feedback.GET("/pending", app.FeedbackHandler.GetPendingProposals)
+				feedback.GET("/checklist", app.FeedbackHandler.GetProposalChecklist)
+				feedback.GET("/second-opinions", app.FeedbackHandler.GetSecondOpinionRequests)
+				feedback.POST("/second-opinions", app.FeedbackHandler.RequestSecondOpinion)
+				feedback.POST("/second-opinions/:id/answer", app.FeedbackHandler.AnswerSecondOpinion)
 				feedback.POST("", app.FeedbackHandler.CreateFeedback)
 				feedback.GET("/:id", app.FeedbackHandler.GetFeedback)
 
 			}
 			protected.GET("/proposals/:id/feedback", app.FeedbackHandler.GetProposalFeedback)
+			protected.GET("/proposals/:id/second-opinions", can(permissions.FeedbackWrite), app.FeedbackHandler.GetProposalSecondOpinions)
+
+			// Advisor review inbox, grouped by the action each proposal needs
+			protected.GET("/advisor/inbox", can(permissions.FeedbackWrite), app.FeedbackHandler.GetInbox)
+
+			// Advisor feedback templates
+			feedbackTemplates := protected.Group("/advisor/feedback-templates")
+			feedbackTemplates.Use(can(permissions.FeedbackWrite))
+			{
+				feedbackTemplates.GET("", app.FeedbackHandler.GetTemplates)
+				feedbackTemplates.POST("", app.FeedbackHandler.CreateTemplate)
+				feedbackTemplates.PUT("/:id", app.FeedbackHandler.UpdateTemplate)
+				feedbackTemplates.DELETE("/:id", app.FeedbackHandler.DeleteTemplate)
+			}
+
+			// Advisor settings, e.g. accepting team assignments automatically
+			protected.GET("/advisor/settings", can(permissions.FeedbackWrite), app.TeamHandler.GetAdvisorSettings)
+			protected.PUT("/advisor/settings", can(permissions.FeedbackWrite), app.TeamHandler.UpdateAdvisorSettings)
+
+			// Advisor conflict-of-interest declarations
+			protected.GET("/advisor/conflicts", can(permissions.FeedbackWrite), app.ConflictHandler.GetMyDeclarations)
+			protected.POST("/advisor/conflicts", can(permissions.FeedbackWrite), app.ConflictHandler.Declare)
+
+			// Teams asking the advisor to supervise them, in their ranked order
+			protected.GET("/advisor/team-requests", can(permissions.FeedbackWrite), app.AdvisorQueueHandler.GetMyRequests)
+			protected.POST("/advisor/team-requests/:id/respond", can(permissions.FeedbackWrite), app.AdvisorQueueHandler.Respond)
+			// Notifications
+			notificationRoutes := protected.Group("/notifications")
+			{
+				notificationRoutes.GET("", app.NotificationHandler.GetNotifications)
+				notificationRoutes.GET("/unread-count", app.NotificationHandler.GetUnreadCount)
+				notificationRoutes.POST("/mark-all-read", app.NotificationHandler.MarkAllAsRead)
+				notificationRoutes.POST("/:id/mark-read", app.NotificationHandler.MarkAsRead)
+				notificationRoutes.DELETE("/read", app.NotificationHandler.DeleteReadNotifications)
+				notificationRoutes.DELETE("/:id", app.NotificationHandler.DeleteNotification)
+			}
+
+			// Department announcements
+			protected.GET("/announcements", app.AnnouncementHandler.GetAnnouncements)
+
 			// Admin User Management
 			admin := protected.Group("/admin")
-			admin.Use(RoleMiddleware("admin"))
 			{
 				// User Management
-				admin.POST("/users/teacher", app.UserHandler.CreateTeacher)
-				admin.POST("/users/student", app.UserHandler.CreateStudent)
-				admin.GET("/users", app.UserHandler.GetUsers)
-				admin.GET("/advisors", app.UserHandler.GetAdvisors)
-				admin.GET("/users/:id", app.UserHandler.GetUser)
-				admin.PATCH("/users/:id/status", app.UserHandler.UpdateUserStatus)
-				admin.POST("/users/:id/assign-department", app.UserHandler.AssignDepartment)
-				admin.DELETE("/users/:id", app.UserHandler.DeleteUser)
-				admin.GET("/stats", app.UserHandler.GetDashboardStats)
-				admin.PATCH("/proposals/:id/assign", app.ProposalHandler.AssignAdvisor)
+				admin.POST("/users/teacher", can(permissions.UserManage), app.UserHandler.CreateTeacher)
+				admin.POST("/users/student", can(permissions.UserManage), app.UserHandler.CreateStudent)
+				admin.GET("/users", can(permissions.UserManage), app.UserHandler.GetUsers)
+				admin.POST("/users/merge", can(permissions.UserManage), app.UserHandler.MergeUsers)
+				admin.GET("/users/:id", can(permissions.UserManage), app.UserHandler.GetUser)
+				admin.GET("/users/:id/dependencies", can(permissions.UserManage), app.UserHandler.GetUserDependencies)
+				admin.PATCH("/users/:id/status", can(permissions.UserManage), app.UserHandler.UpdateUserStatus)
+				admin.POST("/users/:id/assign-department", can(permissions.UserManage), app.UserHandler.AssignDepartment)
+				admin.DELETE("/users/:id", can(permissions.UserManage), app.UserHandler.DeleteUser)
+				admin.POST("/users/:id/anonymize", can(permissions.UserManage), app.UserHandler.AnonymizeUser)
+				admin.GET("/teams", can(permissions.ProposalAssign), app.TeamHandler.GetDepartmentTeams)
+				admin.POST("/teams/:id/transfer-department", can(permissions.UserManage), app.TeamHandler.TransferDepartment)
+				admin.POST("/teams/:id/leader", can(permissions.UserManage), app.TeamHandler.AppointLeader)
+				admin.POST("/users/:id/impersonate", can(permissions.UserImpersonate), app.AuthHandler.Impersonate)
+				admin.POST("/users/:id/revoke-tokens", can(permissions.UserManage), app.AuthHandler.ForceSignOut)
+				admin.GET("/stats", can(permissions.StatsView), app.UserHandler.GetDashboardStats)
+				admin.GET("/analytics/funnel", can(permissions.StatsView), app.AnalyticsHandler.GetFunnel)
+				admin.GET("/analytics/feedback-quality", can(permissions.StatsView), app.AnalyticsHandler.GetFeedbackQuality)
+				// Each result type is checked against its own permission
+				admin.GET("/search", app.SearchHandler.Search)
+				admin.POST("/proposals/archive-cohort", can(permissions.ProposalArchive), app.ProposalHandler.ArchiveCohort)
+				admin.POST("/proposals/:id/reopen", can(permissions.ProposalArchive), app.ProposalHandler.ReopenProposal)
+
+				// System
+				admin.GET("/config", can(permissions.SystemConfig), app.SystemHandler.GetConfig)
+				admin.GET("/health", can(permissions.SystemConfig), app.SystemHandler.GetHealth)
+				admin.PUT("/ai-settings", can(permissions.SystemConfig), app.AICheckerHandler.UpdateDepartmentSettings)
+				admin.GET("/migrations", can(permissions.SystemConfig), app.SystemHandler.GetMigrations)
+				admin.GET("/jobs/failed", can(permissions.SystemConfig), app.SystemHandler.GetFailedJobs)
+				admin.POST("/jobs/failed/:id/retry", can(permissions.SystemConfig), app.SystemHandler.RetryFailedJob)
+				admin.DELETE("/jobs/failed/:id", can(permissions.SystemConfig), app.SystemHandler.DeleteFailedJob)
+				admin.GET("/quarantine", can(permissions.SystemConfig), app.FileHandler.ListQuarantine)
+				admin.POST("/quarantine/:source/:id/release", can(permissions.SystemConfig), app.FileHandler.ReleaseQuarantinedFile)
+				admin.DELETE("/quarantine/:source/:id", can(permissions.SystemConfig), app.FileHandler.DeleteQuarantinedFile)
+				admin.GET("/settings", can(permissions.SystemConfig), app.SystemHandler.GetSettings)
+				admin.GET("/settings/:key", can(permissions.SystemConfig), app.SystemHandler.GetSetting)
+				admin.PUT("/settings/:key", can(permissions.SystemConfig), app.SystemHandler.UpdateSetting)
+				admin.DELETE("/settings/:key", can(permissions.SystemConfig), app.SystemHandler.ResetSetting)
+				admin.GET("/maintenance", can(permissions.SystemConfig), app.SystemHandler.GetMaintenance)
+				admin.PUT("/maintenance", can(permissions.SystemConfig), app.SystemHandler.UpdateMaintenance)
+				admin.GET("/review-checklist", can(permissions.SystemConfig), app.FeedbackHandler.GetDepartmentChecklist)
+				admin.PUT("/review-checklist", can(permissions.SystemConfig), app.FeedbackHandler.UpdateDepartmentChecklist)
+				admin.GET("/grading-rubric", can(permissions.SystemConfig), app.GradingHandler.GetRubric)
+				admin.PUT("/grading-rubric", can(permissions.SystemConfig), app.GradingHandler.UpdateRubric)
+				admin.GET("/quotas", can(permissions.SystemConfig), app.ProposalHandler.GetQuota)
+				admin.PUT("/quotas", can(permissions.SystemConfig), app.ProposalHandler.UpdateQuota)
+				admin.GET("/proposal-rules", can(permissions.SystemConfig), app.ProposalHandler.GetProposalRules)
+				admin.PUT("/proposal-rules", can(permissions.SystemConfig), app.ProposalHandler.UpdateProposalRules)
+				admin.GET("/submission-window", can(permissions.SystemConfig), app.ProposalHandler.GetSubmissionWindow)
+				admin.PUT("/submission-window", can(permissions.SystemConfig), app.ProposalHandler.UpdateSubmissionWindow)
+				admin.GET("/retention-policy", can(permissions.SystemConfig), app.ProposalHandler.GetRetentionPolicy)
+				admin.PUT("/retention-policy", can(permissions.SystemConfig), app.ProposalHandler.UpdateRetentionPolicy)
+				admin.GET("/abandonment-policy", can(permissions.SystemConfig), app.ProposalHandler.GetAbandonmentPolicy)
+				admin.PUT("/abandonment-policy", can(permissions.SystemConfig), app.ProposalHandler.UpdateAbandonmentPolicy)
+				admin.GET("/deadline-extensions", can(permissions.SystemConfig), app.ProposalHandler.GetDeadlineExtensions)
+				admin.POST("/deadline-extensions/:id/decide", can(permissions.SystemConfig), app.ProposalHandler.DecideDeadlineExtension)
+				admin.GET("/advisors/rebalance-suggestions", can(permissions.SystemConfig), app.ProposalHandler.GetRebalanceSuggestions)
+				admin.POST("/advisors/rebalance", can(permissions.SystemConfig), app.ProposalHandler.ApplyRebalance)
+				admin.GET("/advisors/:id/workload-history", can(permissions.StatsView), app.ProposalHandler.GetWorkloadHistory)
+				admin.POST("/universities/onboard", can(permissions.UniversityOnboard), app.UniversityHandler.OnboardUniversity)
+
+				// External examiners' guest accounts
+				admin.POST("/examiners", can(permissions.UserManage), app.ExaminerHandler.InviteExaminer)
+				admin.GET("/examiners", can(permissions.UserManage), app.ExaminerHandler.GetExaminers)
+				admin.PATCH("/examiners/:id", can(permissions.UserManage), app.ExaminerHandler.UpdateExaminerAccess)
+				admin.DELETE("/examiners/:id", can(permissions.UserManage), app.ExaminerHandler.RevokeExaminer)
+				admin.PUT("/showcase", can(permissions.SystemConfig), app.DepartmentHandler.UpdateShowcase)
+
+				// Delegation of approval rights
+				admin.POST("/delegations", can(permissions.DelegationManage), app.DelegationHandler.CreateDelegation)
+				admin.GET("/delegations", can(permissions.DelegationManage), app.DelegationHandler.GetDelegations)
+				admin.DELETE("/delegations/:id", can(permissions.DelegationManage), app.DelegationHandler.RevokeDelegation)
+
+				// Department announcements
+				admin.POST("/announcements", can(permissions.AnnouncementPost), app.AnnouncementHandler.CreateAnnouncement)
+				admin.DELETE("/announcements/:id", can(permissions.AnnouncementPost), app.AnnouncementHandler.DeleteAnnouncement)
+				admin.POST("/messages/advisors", can(permissions.AnnouncementPost), app.ProposalHandler.MessageAdvisors)
+
+				// Conflicts of interest declared by advisors
+				admin.GET("/conflicts", can(permissions.ConflictOverride), app.ConflictHandler.GetDepartmentDeclarations)
+				admin.POST("/conflicts/:id/override", can(permissions.ConflictOverride), app.ConflictHandler.Override)
+				admin.GET("/advisor-requests", can(permissions.ProposalAssign), app.AdvisorQueueHandler.GetDepartmentQueues)
+
+				// Moderation of public project reviews
+				admin.GET("/reviews/moderation", can(permissions.ReviewModerate), app.ReviewHandler.GetModerationQueue)
+				admin.POST("/reviews/moderation/:id", can(permissions.ReviewModerate), app.ReviewHandler.ModerateReview)
+				admin.GET("/reviews/filters", can(permissions.ReviewModerate), app.ReviewHandler.GetFilters)
+				admin.POST("/reviews/filters", can(permissions.ReviewModerate), app.ReviewHandler.AddFilter)
+				admin.DELETE("/reviews/filters/:id", can(permissions.ReviewModerate), app.ReviewHandler.DeleteFilter)
+
+				// Role permissions and department overrides
+				admin.GET("/permissions", can(permissions.PermissionManage), app.PermissionHandler.GetPermissions)
+				admin.PUT("/permissions/overrides", can(permissions.PermissionManage), app.PermissionHandler.SetOverride)
+				admin.DELETE("/permissions/overrides", can(permissions.PermissionManage), app.PermissionHandler.RemoveOverride)
+			}
+
+			// Approval actions a department admin can delegate to a teacher
+			approvals := protected.Group("/admin")
+			approvals.Use(canOrDelegate(permissions.ProposalAssign))
+			{
+				approvals.GET("/advisors", app.UserHandler.GetAdvisors)
+				approvals.GET("/teams/:id/balance", app.TeamHandler.GetTeamBalance)
+				approvals.PATCH("/proposals/:id/assign", app.ProposalHandler.AssignAdvisor)
+				approvals.PUT("/proposals/:id/second-reviewer", app.ProposalHandler.AssignSecondReviewer)
+				approvals.DELETE("/proposals/:id/second-reviewer", app.ProposalHandler.RemoveSecondReviewer)
 			}
 
 			// Projects (Team creators can manage, all can view)
@@ -156,6 +426,11 @@ func NewRouter(app *App) *gin.Engine {
 				projects.GET("/:id", app.ProjectHandler.GetProject)
 				projects.PUT("/:id", app.ProjectHandler.UpdateProject)
 				projects.POST("/:id/publish", app.ProjectHandler.PublishProject)
+				projects.POST("/:id/publish-schedule", app.ProjectHandler.SchedulePublish)
+				projects.DELETE("/:id/publish-schedule", app.ProjectHandler.CancelScheduledPublish)
+				projects.POST("/:id/continue", can(permissions.ProposalWrite), app.ProposalHandler.ContinueProject)
+				projects.GET("/:id/export.zip", app.ProjectHandler.ExportProject)
+				projects.POST("/:id/reviews", app.ReviewHandler.CreateReview)
 				//projects.GET("/:project_id/documentation", app.DocumentationHandler.GetProjectDocuments)
 			}
 
@@ -165,13 +440,24 @@ func NewRouter(app *App) *gin.Engine {
 			docsGroup := protected.Group("/projects/:id/documentation")
 			{
 				docsGroup.GET("", app.DocumentationHandler.GetProjectDocs)
-				docsGroup.POST("", RoleMiddleware("student"), app.DocumentationHandler.Submit)
+				docsGroup.POST("", can(permissions.DocumentationSubmit), app.DocumentationHandler.Submit)
+			}
+			protected.GET("/projects/:id/docs/:docId/preview", app.FileHandler.PreviewProjectDoc)
+			// Grading: advisor and examiners grade, the department head locks, the team reads
+			gradingGroup := protected.Group("/projects/:id/grading")
+			{
+				gradingGroup.GET("", app.GradingHandler.GetProjectGrading)
+				gradingGroup.PUT("/sheet", can(permissions.GradeSubmit), app.GradingHandler.SubmitGrade)
+				gradingGroup.POST("/lock", can(permissions.GradeLock), app.GradingHandler.LockGrade)
+				gradingGroup.POST("/examiners", can(permissions.GradeLock), app.GradingHandler.AssignExaminer)
+				gradingGroup.DELETE("/examiners/:user_id", can(permissions.GradeLock), app.GradingHandler.RemoveExaminer)
 			}
 			// Individual Doc Actions (For deleting or reviewing)
 			docActions := protected.Group("/documentation")
 			{
-				docActions.DELETE("/:id", RoleMiddleware("student"), app.DocumentationHandler.Delete)
-				docActions.PATCH("/:id/review", RoleMiddleware("advisor"), app.DocumentationHandler.Review)
+				docActions.DELETE("/:id", can(permissions.DocumentationSubmit), app.DocumentationHandler.Delete)
+				docActions.PATCH("/:id/review", can(permissions.DocumentationReview), app.DocumentationHandler.Review)
+				docActions.POST("/:id/check-link", can(permissions.DocumentationSubmit), app.DocumentationHandler.CheckLink)
 			}
 
 			// // Documentation review (Teachers only)
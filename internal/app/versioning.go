@@ -0,0 +1,102 @@
+package app
+
+import (
+	"backend/pkg/response"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentAPIVersion is the version unversioned /api requests are sent to
+const CurrentAPIVersion = "v1"
+
+// SupportedAPIVersions are the versions this server serves, each under /api/<version>
+var SupportedAPIVersions = []string{"v1"}
+
+// vendorMediaType matches a version requested through Accept, e.g. application/vnd.projecthub.v1+json
+var vendorMediaType = regexp.MustCompile(`application/vnd\.projecthub\.(v[0-9]+)\+json`)
+
+// APIVersionMiddleware stamps responses with the version that served them. The path is
+// authoritative; a client that also names a version in the API-Version header or the Accept
+// media type gets 406 when it does not match, rather than a silently different contract.
+func APIVersionMiddleware(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("API-Version", version)
+		if requested := requestedAPIVersion(c.Request); requested != "" && requested != version {
+			c.Header("API-Supported-Versions", strings.Join(SupportedAPIVersions, ", "))
+			response.Error(c, http.StatusNotAcceptable, "API version "+requested+" is not served at this path",
+				gin.H{"supported_versions": SupportedAPIVersions})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// redirectUnversionedAPI sends /api/... requests without a version to the version the client
+// asked for, or the current one. Other unknown paths keep gin's 404.
+func redirectUnversionedAPI(c *gin.Context) {
+	path := c.Request.URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		return
+	}
+	rest := strings.TrimPrefix(path, "/api")
+	if segment := strings.SplitN(strings.TrimPrefix(rest, "/"), "/", 2)[0]; isVersionSegment(segment) {
+		return // a versioned path that does not exist, or a version that was retired
+	}
+
+	version := CurrentAPIVersion
+	if requested := requestedAPIVersion(c.Request); requested != "" {
+		if !isSupportedAPIVersion(requested) {
+			c.Header("API-Supported-Versions", strings.Join(SupportedAPIVersions, ", "))
+			response.Error(c, http.StatusNotAcceptable, "API version "+requested+" is not supported",
+				gin.H{"supported_versions": SupportedAPIVersions})
+			return
+		}
+		version = requested
+	}
+
+	target := "/api/" + version + rest
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+	// 308 keeps the method and body, so POSTs follow the redirect too
+	c.Redirect(http.StatusPermanentRedirect, target)
+}
+
+// requestedAPIVersion reads the version a client asked for, normalised to "v1"; empty when none
+func requestedAPIVersion(r *http.Request) string {
+	if v := strings.ToLower(strings.TrimSpace(r.Header.Get("API-Version"))); v != "" {
+		if !strings.HasPrefix(v, "v") {
+			v = "v" + v
+		}
+		return v
+	}
+	if m := vendorMediaType.FindStringSubmatch(r.Header.Get("Accept")); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func isSupportedAPIVersion(version string) bool {
+	for _, v := range SupportedAPIVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func isVersionSegment(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, ch := range s[1:] {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
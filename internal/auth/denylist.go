@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	RevocationPasswordChanged    = "password_changed"
+	RevocationEmailChanged       = "email_changed"
+	RevocationDeactivated        = "deactivated"
+	RevocationAdmin              = "admin"
+	RevocationImpersonationEnded = "impersonation_ended"
+	RevocationAccessExpired      = "access_expired"
+
+	// denylistRefreshInterval bounds how long an instance keeps accepting a token another
+	// instance revoked; revocations made by this instance apply at once
+	denylistRefreshInterval = 5 * time.Second
+)
+
+var (
+	ErrTokenRevoked       = errors.New("token has been revoked")
+	ErrRevokeOutsideScope = errors.New("can only revoke tokens of users in your department")
+	ErrAccessExpired      = errors.New("your guest access has expired; ask the department to extend it")
+)
+
+// denylist caches the unexpired revocations, since it is consulted on every authenticated
+// request. A failed reload keeps the last known entries.
+type denylist struct {
+	mu       sync.Mutex
+	tokens   map[string]struct{} // revoked jti
+	cutoffs  map[uint]time.Time  // user ID -> tokens issued up to this time are revoked
+	loadedAt time.Time
+}
+
+func newDenylist() *denylist {
+	return &denylist{tokens: make(map[string]struct{}), cutoffs: make(map[uint]time.Time)}
+}
+
+// add applies a revocation to the cache
+func (d *denylist) add(entry domain.RevokedToken) {
+	if entry.JTI != "" {
+		d.tokens[entry.JTI] = struct{}{}
+	}
+	if entry.IssuedBefore != nil && entry.IssuedBefore.After(d.cutoffs[entry.UserID]) {
+		d.cutoffs[entry.UserID] = *entry.IssuedBefore
+	}
+}
+
+// revoked reports whether the claims are covered by a revocation, reloading the entries when stale.
+// An impersonation token is also revoked with the tokens of the admin behind it.
+func (s *service) revoked(claims *TokenClaims) bool {
+	d := s.denylist
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.loadedAt) >= denylistRefreshInterval {
+		entries, err := s.repo.GetActiveRevocations(time.Now())
+		if err != nil {
+			log.Printf("failed to load the token denylist: %v", err)
+		} else {
+			d.tokens = make(map[string]struct{}, len(entries))
+			d.cutoffs = make(map[uint]time.Time)
+			for _, entry := range entries {
+				d.add(entry)
+			}
+		}
+		d.loadedAt = time.Now()
+	}
+
+	if claims.ID != "" {
+		if _, ok := d.tokens[claims.ID]; ok {
+			return true
+		}
+	}
+	if d.issuedBeforeCutoff(claims.UserID, claims) {
+		return true
+	}
+	return claims.ImpersonatorID != 0 && d.issuedBeforeCutoff(claims.ImpersonatorID, claims)
+}
+
+// issuedBeforeCutoff reports whether the token was issued before the user's tokens were revoked
+func (d *denylist) issuedBeforeCutoff(userID uint, claims *TokenClaims) bool {
+	cutoff, ok := d.cutoffs[userID]
+	if !ok {
+		return false
+	}
+	// Token times have second precision; tokens from the second of the cutoff stay valid so signing
+	// in right after a revocation works, and their sessions were revoked along with the entry
+	return claims.IssuedAt == nil || claims.IssuedAt.Time.Before(cutoff)
+}
+
+// RevokeUserTokens rejects every token issued to the user so far and revokes their sessions.
+// Tokens issued afterwards, e.g. by signing in again, are not affected.
+func (s *service) RevokeUserTokens(userID uint, reason string, actorID *uint) error {
+	now := time.Now()
+	cutoff := now.Truncate(time.Second)
+	entry := domain.RevokedToken{
+		UserID:       userID,
+		IssuedBefore: &cutoff,
+		Reason:       reason,
+		RevokedBy:    actorID,
+		ExpiresAt:    now.Add(TokenTTL),
+		CreatedAt:    now,
+	}
+	if err := s.repo.RevokeUserTokens(&entry); err != nil {
+		return err
+	}
+
+	s.denylist.mu.Lock()
+	s.denylist.add(entry)
+	s.denylist.mu.Unlock()
+	return nil
+}
+
+// revokeToken rejects one token until it expires
+func (s *service) revokeToken(claims *TokenClaims, reason string, actorID *uint) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	entry := domain.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		Reason:    reason,
+		RevokedBy: actorID,
+		ExpiresAt: claims.ExpiresAt.Time,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.RevokeToken(&entry); err != nil {
+		return err
+	}
+
+	s.denylist.mu.Lock()
+	s.denylist.add(entry)
+	s.denylist.mu.Unlock()
+	return nil
+}
+
+// ForceSignOut lets an admin revoke every token of a user in their department at once, e.g.
+// when an account is compromised
+func (s *service) ForceSignOut(adminID uint, targetUserID uint, ipAddress string, userAgent string, requestID string) error {
+	admin, err := s.repo.FindByID(adminID)
+	if err != nil {
+		return errors.New("admin not found")
+	}
+	target, err := s.repo.FindByID(targetUserID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if target.DepartmentID != admin.DepartmentID {
+		return ErrRevokeOutsideScope
+	}
+
+	if err := s.RevokeUserTokens(target.ID, RevocationAdmin, &admin.ID); err != nil {
+		return err
+	}
+
+	s.auditLogger.LogAction("user", target.ID, "tokens_revoked", &admin.ID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{"reason": RevocationAdmin}, ipAddress, userAgent, requestID, "")
+	return nil
+}
+
+// CleanupRevokedTokens deletes denylist entries whose tokens have expired; run periodically by the scheduler
+func (s *service) CleanupRevokedTokens() {
+	deleted, err := s.repo.DeleteRevocationsExpiredBefore(time.Now())
+	if err != nil {
+		log.Printf("failed to clean up the token denylist: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d expired token revocation(s)", deleted)
+	}
+}
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"backend/pkg/response"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -78,7 +80,7 @@ func (h *Handler) Login(c *gin.Context) {
 
 	loginResp, err := h.service.Login(req, ipAddress, userAgent, requestID)
 	if err != nil {
-		if err.Error() == "account is temporarily locked due to too many failed login attempts" {
+		if err.Error() == "account is temporarily locked due to too many failed login attempts" || errors.Is(err, ErrAccessExpired) {
 			response.Error(c, http.StatusForbidden, err.Error(), err)
 			return
 		}
@@ -241,7 +243,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 
 // ChangePassword changes user password
 // @Summary Change password
-// @Description Change password for authenticated user (requires current password)
+// @Description Change password for authenticated user (requires current password). Every token of the user is revoked, including the one making the request, so the user signs in again.
 // @Tags Auth
 // @Security BearerAuth
 // @Accept json
@@ -269,7 +271,221 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusOK, "Password changed successfully", nil)
+	response.JSON(c, http.StatusOK, "Password changed successfully; please sign in again", nil)
+}
+
+// Impersonate lets an admin act as another user
+// @Summary Impersonate a user
+// @Description Admin receives a short-lived token acting as the given user. All actions are audited with both identities.
+// @Tags Admin - Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=ImpersonationResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/users/{id}/impersonate [post]
+func (h *Handler) Impersonate(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	adminClaims := claims.(*TokenClaims)
+
+	if adminClaims.ImpersonatorID != 0 {
+		response.Error(c, http.StatusForbidden, "Cannot start impersonation from an impersonated session", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	requestID, _ := c.Get("request_id")
+	reqID, _ := requestID.(string)
+
+	result, err := h.service.Impersonate(adminClaims.UserID, uint(id), c.ClientIP(), c.GetHeader("User-Agent"), reqID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case "cannot impersonate another admin", "can only impersonate users in your department":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Impersonation started", result)
+}
+
+// ForceSignOut revokes every token of a user
+// @Summary Sign a user out everywhere
+// @Description Revokes every access token and session of a user in the admin's department immediately, e.g. when the account is compromised. The user can sign in again.
+// @Tags Admin - Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/users/{id}/revoke-tokens [post]
+func (h *Handler) ForceSignOut(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	adminClaims := claims.(*TokenClaims)
+
+	if adminClaims.ImpersonatorID != 0 {
+		response.Error(c, http.StatusForbidden, "Cannot revoke tokens from an impersonated session", nil)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	requestID, _ := c.Get("request_id")
+	reqID, _ := requestID.(string)
+
+	if err := h.service.ForceSignOut(adminClaims.UserID, uint(id), c.ClientIP(), c.GetHeader("User-Agent"), reqID); err != nil {
+		switch {
+		case err.Error() == "user not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrRevokeOutsideScope):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "User signed out everywhere", nil)
+}
+
+// EndImpersonation ends an impersonation session
+// @Summary End impersonation
+// @Description Exchanges an impersonation token for a fresh token of the original admin. Refused with 401 when the admin has been deactivated or their tokens revoked.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response{data=LoginResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /auth/impersonation/end [post]
+func (h *Handler) EndImpersonation(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	requestID, _ := c.Get("request_id")
+	reqID, _ := requestID.(string)
+
+	result, err := h.service.EndImpersonation(claims.(*TokenClaims), c.ClientIP(), c.GetHeader("User-Agent"), reqID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrTokenRevoked) {
+			status = http.StatusUnauthorized
+		}
+		response.Error(c, status, err.Error(), nil)
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Impersonation ended", result)
+}
+
+// GetSecurity returns the user's recent logins and active sessions
+// @Summary Get login history and active sessions
+// @Description Recent successful and failed logins with time, IP and user agent, and the sessions that can still use the account. current=true marks the session making the request.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response{data=SecurityOverview}
+// @Failure 401 {object} response.ErrorResponse
+// @Router /users/me/security [get]
+func (h *Handler) GetSecurity(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	userClaims := claims.(*TokenClaims)
+
+	overview, err := h.service.GetSecurityOverview(userClaims.UserID, userClaims.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch security overview", err.Error())
+		return
+	}
+	response.Success(c, overview)
+}
+
+// RevokeSession signs out another session
+// @Summary Revoke a session
+// @Description Signs out one of the user's other sessions; its tokens stop working immediately
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /users/me/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	userClaims := claims.(*TokenClaims)
+
+	if err := h.service.RevokeSession(userClaims.UserID, c.Param("id"), userClaims.ID); err != nil {
+		switch err.Error() {
+		case "session not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case "cannot revoke the current session":
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to revoke session", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Session revoked", nil)
+}
+
+// RevokeOtherSessions signs out every other session
+// @Summary Revoke all other sessions
+// @Description Signs out every session of the user except the one making the request
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /users/me/sessions [delete]
+func (h *Handler) RevokeOtherSessions(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+	userClaims := claims.(*TokenClaims)
+
+	revoked, err := h.service.RevokeOtherSessions(userClaims.UserID, userClaims.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke sessions", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Other sessions revoked", gin.H{"revoked": revoked})
 }
 
 // Request structs for new endpoints
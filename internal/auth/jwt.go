@@ -9,20 +9,33 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// TokenClaims are the claims of an access token. The registered ID (jti) is the login
+// session the token belongs to, or a random ID for impersonation tokens; it is empty for
+// password reset tokens.
 type TokenClaims struct {
 	UserID       uint       `json:"user_id"`
 	Email        string     `json:"email"`
 	Role         enums.Role `json:"role"`
 	DepartmentID uint       `json:"department_id"`
 	UniversityID uint       `json:"university_id"` 
+	// ImpersonatorID is set when an admin acts as this user
+	ImpersonatorID uint `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(user *domain.User, cfg config.Config) (string, time.Time, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+const (
+	// TokenTTL is how long an access token is valid
+	TokenTTL = 24 * time.Hour
+	// ImpersonationTTL keeps impersonation sessions short-lived
+	ImpersonationTTL = 30 * time.Minute
+)
+
+// GenerateToken creates a new JWT token for a user, tied to the given login session
+func GenerateToken(user *domain.User, sessionID string, cfg config.Config) (string, time.Time, error) {
+	expirationTime := time.Now().Add(TokenTTL)
 
 	claims := &TokenClaims{
 		UserID:       user.ID,
@@ -31,6 +44,7 @@ func GenerateToken(user *domain.User, cfg config.Config) (string, time.Time, err
 		DepartmentID: user.DepartmentID,
 		UniversityID: user.UniversityID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "university-project-hub",
@@ -48,6 +62,35 @@ func GenerateToken(user *domain.User, cfg config.Config) (string, time.Time, err
 	return tokenString, expirationTime, nil
 }
 
+// GenerateImpersonationToken issues a short-lived token for user that records the acting admin
+func GenerateImpersonationToken(user *domain.User, impersonatorID uint, cfg config.Config) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ImpersonationTTL)
+
+	claims := &TokenClaims{
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           user.Role,
+		DepartmentID:   user.DepartmentID,
+		UniversityID:   user.UniversityID,
+		ImpersonatorID: impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "university-project-hub",
+			Subject:   user.Email,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(cfg.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expirationTime, nil
+}
+
 // ValidateToken validates and parses a JWT token
 func ValidateToken(tokenString string, cfg config.Config) (*TokenClaims, error) {
 	claims := &TokenClaims{}
@@ -83,8 +126,12 @@ func RefreshToken(oldTokenString string, cfg config.Config) (string, time.Time,
 		return "", time.Time{}, err
 	}
 
+	if claims.ImpersonatorID != 0 {
+		return "", time.Time{}, errors.New("impersonation tokens cannot be refreshed")
+	}
+
 	// Create new token with same claims but extended expiration
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(TokenTTL)
 	claims.ExpiresAt = jwt.NewNumericDate(expirationTime)
 	claims.IssuedAt = jwt.NewNumericDate(time.Now())
 
@@ -19,6 +19,23 @@ type Repository interface {
 	UpdateLastLogin(userID uint) error
 	LockAccount(userID uint, until time.Time) error
 	IsAccountLocked(userID uint) (bool, error)
+
+	// Sessions and login history
+	CreateSession(session *domain.UserSession) error
+	GetSession(id string) (*domain.UserSession, error)
+	TouchSession(id string, at time.Time) error
+	ExtendSession(id string, expiresAt time.Time) error
+	GetActiveSessions(userID uint) ([]domain.UserSession, error)
+	RevokeSession(userID uint, id string) error
+	RevokeOtherSessions(userID uint, keepID string) (int64, error)
+	DeleteSessionsExpiredBefore(before time.Time) (int64, error)
+	GetLoginHistory(userID uint, limit int) ([]domain.AuditLog, error)
+
+	// Token denylist
+	RevokeToken(entry *domain.RevokedToken) error
+	RevokeUserTokens(entry *domain.RevokedToken) error
+	GetActiveRevocations(now time.Time) ([]domain.RevokedToken, error)
+	DeleteRevocationsExpiredBefore(before time.Time) (int64, error)
 }
 
 type repository struct {
@@ -117,3 +134,107 @@ func (r *repository) IsAccountLocked(userID uint) (bool, error) {
 	}
 	return false, nil
 }
+
+func (r *repository) CreateSession(session *domain.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *repository) GetSession(id string) (*domain.UserSession, error) {
+	var session domain.UserSession
+	err := r.db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("session not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *repository) TouchSession(id string, at time.Time) error {
+	return r.db.Model(&domain.UserSession{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", at).
+		Error
+}
+
+func (r *repository) ExtendSession(id string, expiresAt time.Time) error {
+	return r.db.Model(&domain.UserSession{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"expires_at":   expiresAt,
+			"last_seen_at": time.Now(),
+		}).
+		Error
+}
+
+// GetActiveSessions lists the user's unrevoked, unexpired sessions, most recently used first
+func (r *repository) GetActiveSessions(userID uint) ([]domain.UserSession, error) {
+	var sessions []domain.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *repository) RevokeSession(userID uint, id string) error {
+	result := r.db.Model(&domain.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+func (r *repository) RevokeOtherSessions(userID uint, keepID string) (int64, error) {
+	result := r.db.Model(&domain.UserSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, keepID, time.Now()).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) DeleteSessionsExpiredBefore(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&domain.UserSession{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) RevokeToken(entry *domain.RevokedToken) error {
+	return r.db.Create(entry).Error
+}
+
+// RevokeUserTokens records a user-wide denylist entry and revokes all of the user's sessions
+func (r *repository) RevokeUserTokens(entry *domain.RevokedToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", entry.UserID, entry.CreatedAt).
+			Update("revoked_at", entry.CreatedAt).Error
+	})
+}
+
+func (r *repository) GetActiveRevocations(now time.Time) ([]domain.RevokedToken, error) {
+	var entries []domain.RevokedToken
+	err := r.db.Where("expires_at > ?", now).Find(&entries).Error
+	return entries, err
+}
+
+func (r *repository) DeleteRevocationsExpiredBefore(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&domain.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
+
+// GetLoginHistory returns the user's most recent successful and failed logins from the audit log
+func (r *repository) GetLoginHistory(userID uint, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	err := r.db.Where("entity_type = ? AND entity_id = ? AND action IN ?", "user", userID, []string{"login_success", "login_failed"}).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
@@ -5,7 +5,9 @@ import (
 	"backend/internal/domain"
 	"backend/pkg/audit"
 	"backend/pkg/enums"
+	"backend/pkg/settings"
 	"errors"
+	"log"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -20,19 +22,37 @@ type Service interface {
 	ResetPassword(token string, newPassword string) error
 	UpdateProfile(userID uint, name string, profilePhoto string) (*domain.User, error)
 	ChangePassword(userID uint, oldPassword, newPassword string) error
+	Impersonate(adminID uint, targetUserID uint, ipAddress string, userAgent string, requestID string) (*ImpersonationResponse, error)
+	EndImpersonation(claims *TokenClaims, ipAddress string, userAgent string, requestID string) (*LoginResponse, error)
+
+	// Sessions
+	CheckSession(claims *TokenClaims) error
+	GetSecurityOverview(userID uint, currentSessionID string) (*SecurityOverview, error)
+	RevokeSession(userID uint, sessionID string, currentSessionID string) error
+	RevokeOtherSessions(userID uint, currentSessionID string) (int64, error)
+	CleanupExpiredSessions()
+
+	// Token denylist
+	RevokeUserTokens(userID uint, reason string, actorID *uint) error
+	ForceSignOut(adminID uint, targetUserID uint, ipAddress string, userAgent string, requestID string) error
+	CleanupRevokedTokens()
 }
 
 type service struct {
 	repo        Repository
 	cfg         config.Config
 	auditLogger *audit.Logger
+	denylist    *denylist
+	settings    *settings.Store
 }
 
-func NewService(repo Repository, cfg config.Config, auditLogger *audit.Logger) Service {
+func NewService(repo Repository, cfg config.Config, auditLogger *audit.Logger, settingsStore *settings.Store) Service {
 	return &service{
 		repo:        repo,
 		cfg:         cfg,
 		auditLogger: auditLogger,
+		denylist:    newDenylist(),
+		settings:    settingsStore,
 	}
 }
 
@@ -56,6 +76,13 @@ type LoginResponse struct {
 	User      *domain.User `json:"user"`
 }
 
+type ImpersonationResponse struct {
+	Token          string       `json:"token"`
+	ExpiresAt      time.Time    `json:"expires_at"`
+	User           *domain.User `json:"user"`
+	ImpersonatorID uint         `json:"impersonator_id"`
+}
+
 // Register creates a new user account
 func (s *service) Register(req RegisterRequest) (*domain.User, error) {
 	
@@ -116,9 +143,9 @@ func (s *service) Login(req LoginRequest, ipAddress string, userAgent string, re
 		// Increment failed login attempts
 		s.repo.IncrementFailedLogins(user.ID)
 
-		// Lock account if failed attempts exceed threshold (5 attempts)
-		if user.FailedLoginAttempts+1 >= 5 {
-			lockUntil := time.Now().Add(30 * time.Minute)
+		// Lock account once failed attempts reach the university's threshold
+		if user.FailedLoginAttempts+1 >= s.settings.Int(settings.LockoutThreshold, user.UniversityID) {
+			lockUntil := time.Now().Add(time.Duration(s.settings.Int(settings.LockoutMinutes, user.UniversityID)) * time.Minute)
 			s.repo.LockAccount(user.ID, lockUntil)
 		}
 
@@ -127,14 +154,20 @@ func (s *service) Login(req LoginRequest, ipAddress string, userAgent string, re
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Guest accounts stop working when their access ends
+	if user.AccessExpiresAt != nil && !time.Now().Before(*user.AccessExpiresAt) {
+		s.auditLogger.LogUserLogin(user.ID, user.Email, string(user.Role), false, ipAddress, userAgent, requestID)
+		return nil, ErrAccessExpired
+	}
+
 	// Reset failed login attempts on successful login
 	s.repo.ResetFailedLogins(user.ID)
 
 	// Update last login timestamp
 	s.repo.UpdateLastLogin(user.ID)
 
-	// Generate JWT token
-	token, expiresAt, err := GenerateToken(user, s.cfg)
+	// Generate JWT token for a new session
+	token, expiresAt, err := s.startSession(user, ipAddress, userAgent)
 	if err != nil {
 		return nil, errors.New("failed to generate token")
 	}
@@ -157,9 +190,26 @@ func (s *service) ValidateToken(token string) (*TokenClaims, error) {
 	return ValidateToken(token, s.cfg)
 }
 
-// RefreshToken generates a new token if the current one is expiring soon
+// RefreshToken generates a new token if the current one is expiring soon and extends its session
 func (s *service) RefreshToken(token string) (string, time.Time, error) {
-	return RefreshToken(token, s.cfg)
+	claims, err := ValidateToken(token, s.cfg)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := s.CheckSession(claims); err != nil {
+		return "", time.Time{}, err
+	}
+
+	newToken, expiresAt, err := RefreshToken(token, s.cfg)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if claims.ID != "" {
+		if err := s.repo.ExtendSession(claims.ID, expiresAt); err != nil {
+			return "", time.Time{}, err
+		}
+	}
+	return newToken, expiresAt, nil
 }
 
 // ForgotPassword generates a password reset token (mock - would normally send email)
@@ -171,7 +221,7 @@ func (s *service) ForgotPassword(email string) (string, error) {
 	}
 
 	// Generate a reset token (in production, store this and send via email)
-	resetToken, _, err := GenerateToken(user, s.cfg)
+	resetToken, _, err := GenerateToken(user, "", s.cfg)
 	if err != nil {
 		return "", errors.New("failed to generate reset token")
 	}
@@ -182,7 +232,7 @@ func (s *service) ForgotPassword(email string) (string, error) {
 	return resetToken, nil
 }
 
-// ResetPassword resets user password with a valid token
+// ResetPassword resets user password with a valid token and signs the user out everywhere
 func (s *service) ResetPassword(token string, newPassword string) error {
 	// Validate the reset token
 	claims, err := ValidateToken(token, s.cfg)
@@ -196,8 +246,11 @@ func (s *service) ResetPassword(token string, newPassword string) error {
 		return errors.New("failed to hash password")
 	}
 
-	// Update password
-	return s.repo.UpdatePassword(claims.UserID, string(hashedPassword))
+	// Update password; the reset token and every other token of the user stop working
+	if err := s.repo.UpdatePassword(claims.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.RevokeUserTokens(claims.UserID, RevocationPasswordChanged, &claims.UserID)
 }
 
 // UpdateProfile updates user profile information
@@ -222,7 +275,8 @@ func (s *service) UpdateProfile(userID uint, name string, profilePhoto string) (
 	return user, nil
 }
 
-// ChangePassword changes user password (requires old password)
+// ChangePassword changes user password (requires old password) and signs the user out everywhere,
+// including the session making the request
 func (s *service) ChangePassword(userID uint, oldPassword, newPassword string) error {
 	user, err := s.repo.FindByID(userID)
 	if err != nil {
@@ -240,5 +294,96 @@ func (s *service) ChangePassword(userID uint, oldPassword, newPassword string) e
 		return errors.New("failed to hash password")
 	}
 
-	return s.repo.UpdatePassword(userID, string(hashedPassword))
+	if err := s.repo.UpdatePassword(userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.RevokeUserTokens(userID, RevocationPasswordChanged, &userID)
+}
+
+// Impersonate issues a short-lived token that lets an admin act as a user in their department
+func (s *service) Impersonate(adminID uint, targetUserID uint, ipAddress string, userAgent string, requestID string) (*ImpersonationResponse, error) {
+	admin, err := s.repo.FindByID(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	target, err := s.repo.FindByID(targetUserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if target.ID == admin.ID {
+		return nil, errors.New("cannot impersonate yourself")
+	}
+	if target.Role == enums.RoleAdmin {
+		return nil, errors.New("cannot impersonate another admin")
+	}
+	if target.DepartmentID != admin.DepartmentID {
+		return nil, errors.New("can only impersonate users in your department")
+	}
+	if !target.IsActive {
+		return nil, errors.New("cannot impersonate an inactive user")
+	}
+
+	token, expiresAt, err := GenerateImpersonationToken(target, admin.ID, s.cfg)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	adminID = admin.ID
+	s.auditLogger.LogAction("user", target.ID, "impersonation_started", &adminID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{
+			"impersonated_user_id":    target.ID,
+			"impersonated_user_email": target.Email,
+			"expires_at":              expiresAt,
+		}, ipAddress, userAgent, requestID, "")
+
+	target.Password = ""
+
+	return &ImpersonationResponse{
+		Token:          token,
+		ExpiresAt:      expiresAt,
+		User:           target,
+		ImpersonatorID: admin.ID,
+	}, nil
+}
+
+// EndImpersonation returns the admin to their own identity
+func (s *service) EndImpersonation(claims *TokenClaims, ipAddress string, userAgent string, requestID string) (*LoginResponse, error) {
+	if claims.ImpersonatorID == 0 {
+		return nil, errors.New("not an impersonation session")
+	}
+
+	admin, err := s.repo.FindByID(claims.ImpersonatorID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+	// A deactivated admin, or one whose tokens were revoked meanwhile, does not get a session back
+	if !admin.IsActive || admin.Role != enums.RoleAdmin || s.revoked(claims) {
+		return nil, ErrTokenRevoked
+	}
+
+	token, expiresAt, err := s.startSession(admin, ipAddress, userAgent)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	adminID := admin.ID
+	if err := s.revokeToken(claims, RevocationImpersonationEnded, &adminID); err != nil {
+		log.Printf("failed to revoke impersonation token of admin %d: %v", adminID, err)
+	}
+
+	s.auditLogger.LogAction("user", claims.UserID, "impersonation_ended", &adminID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{
+			"impersonated_user_id":    claims.UserID,
+			"impersonated_user_email": claims.Email,
+		}, ipAddress, userAgent, requestID, "")
+
+	admin.Password = ""
+
+	return &LoginResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      admin,
+	}, nil
 }
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// LoginHistorySize is how many recent logins the security overview lists
+	LoginHistorySize = 20
+	// SessionRetention is how long expired sessions are kept before cleanup
+	SessionRetention = 30 * 24 * time.Hour
+	// sessions are marked as seen at most this often, so authenticated requests rarely write
+	sessionTouchInterval = time.Minute
+)
+
+// LoginRecord is one login attempt from the audit log
+type LoginRecord struct {
+	At        time.Time `json:"at"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+}
+
+// SessionInfo is an active session; Current marks the one making the request
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// SecurityOverview is what a user sees about their own account access
+type SecurityOverview struct {
+	RecentLogins   []LoginRecord `json:"recent_logins"`
+	ActiveSessions []SessionInfo `json:"active_sessions"`
+}
+
+// startSession records a login session and issues its access token
+func (s *service) startSession(user *domain.User, ipAddress string, userAgent string) (string, time.Time, error) {
+	sessionID := uuid.New().String()
+	token, expiresAt, err := GenerateToken(user, sessionID, s.cfg)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	// A guest's session ends with their access, however long the token would last
+	if user.AccessExpiresAt != nil && user.AccessExpiresAt.Before(expiresAt) {
+		expiresAt = *user.AccessExpiresAt
+	}
+
+	now := time.Now()
+	if err := s.repo.CreateSession(&domain.UserSession{
+		ID:         sessionID,
+		UserID:     user.ID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// CheckSession rejects tokens on the denylist and tokens whose session was revoked or has
+// expired. Tokens without a session (impersonation, tokens issued before sessions existed) are
+// otherwise left to their expiry; impersonation tokens also fall with the admin's revoked tokens.
+func (s *service) CheckSession(claims *TokenClaims) error {
+	if s.revoked(claims) {
+		return ErrTokenRevoked
+	}
+	if claims.ID == "" || claims.ImpersonatorID != 0 {
+		return nil
+	}
+
+	session, err := s.repo.GetSession(claims.ID)
+	if err != nil || session.UserID != claims.UserID {
+		return errors.New("session not found")
+	}
+	if session.RevokedAt != nil {
+		return errors.New("session has been revoked")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return errors.New("session expired")
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.repo.TouchSession(session.ID, time.Now()); err != nil {
+			log.Printf("failed to update session %s: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetSecurityOverview lists the user's recent logins and active sessions
+func (s *service) GetSecurityOverview(userID uint, currentSessionID string) (*SecurityOverview, error) {
+	logs, err := s.repo.GetLoginHistory(userID, LoginHistorySize)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.repo.GetActiveSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &SecurityOverview{
+		RecentLogins:   make([]LoginRecord, 0, len(logs)),
+		ActiveSessions: make([]SessionInfo, 0, len(sessions)),
+	}
+	for _, l := range logs {
+		overview.RecentLogins = append(overview.RecentLogins, LoginRecord{
+			At:        l.Timestamp,
+			IPAddress: l.IPAddress,
+			UserAgent: l.UserAgent,
+			Success:   l.Action == "login_success",
+		})
+	}
+	for _, session := range sessions {
+		overview.ActiveSessions = append(overview.ActiveSessions, SessionInfo{
+			ID:         session.ID,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return overview, nil
+}
+
+// RevokeSession signs out one of the user's other sessions
+func (s *service) RevokeSession(userID uint, sessionID string, currentSessionID string) error {
+	if sessionID == currentSessionID {
+		return errors.New("cannot revoke the current session")
+	}
+	return s.repo.RevokeSession(userID, sessionID)
+}
+
+// RevokeOtherSessions signs out every session of the user except the current one
+func (s *service) RevokeOtherSessions(userID uint, currentSessionID string) (int64, error) {
+	return s.repo.RevokeOtherSessions(userID, currentSessionID)
+}
+
+// CleanupExpiredSessions deletes sessions that expired more than SessionRetention ago; run periodically by the scheduler
+func (s *service) CleanupExpiredSessions() {
+	deleted, err := s.repo.DeleteSessionsExpiredBefore(time.Now().Add(-SessionRetention))
+	if err != nil {
+		log.Printf("failed to clean up expired sessions: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d expired session(s)", deleted)
+	}
+}
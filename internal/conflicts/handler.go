@@ -0,0 +1,150 @@
+package conflicts
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/audit"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// Declare godoc
+// @Summary Declare a conflict of interest with a team
+// @Description Advisors state whether they have a conflict with a team of their department (e.g. a relative on the team) before accepting it. A flagged conflict blocks assigning the advisor to the team's proposal until the department head overrides it. Declaring again replaces the declaration and clears any override.
+// @Tags Advisor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DeclareRequest true "Declaration"
+// @Success 200 {object} response.Response{data=domain.ConflictDeclaration}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /advisor/conflicts [post]
+func (h *Handler) Declare(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req DeclareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	declaration, err := h.service.Declare(req, claims.UserID, audit.NewRequestMeta(c))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to record declaration", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Declaration recorded", declaration)
+}
+
+// GetMyDeclarations godoc
+// @Summary List my conflict-of-interest declarations
+// @Tags Advisor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]domain.ConflictDeclaration}
+// @Router /advisor/conflicts [get]
+func (h *Handler) GetMyDeclarations(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	declarations, err := h.service.GetMyDeclarations(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch declarations", err.Error())
+		return
+	}
+	response.Success(c, declarations)
+}
+
+// GetDepartmentDeclarations godoc
+// @Summary List the department's conflict-of-interest declarations
+// @Description Unresolved conflicts come first; flagged=true leaves out declarations without a conflict
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param flagged query bool false "Only declarations that flag a conflict"
+// @Success 200 {object} response.Response{data=[]domain.ConflictDeclaration}
+// @Router /admin/conflicts [get]
+func (h *Handler) GetDepartmentDeclarations(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	declarations, err := h.service.GetDepartmentDeclarations(claims.DepartmentID, c.Query("flagged") == "true")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch declarations", err.Error())
+		return
+	}
+	response.Success(c, declarations)
+}
+
+// Override godoc
+// @Summary Override a declared conflict of interest
+// @Description The department head allows the advisor to be assigned to the team despite the conflict. The reason is audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Declaration ID"
+// @Param request body OverrideRequest true "Reason"
+// @Success 200 {object} response.Response{data=domain.ConflictDeclaration}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/conflicts/{id}/override [post]
+func (h *Handler) Override(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var req OverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	declaration, err := h.service.Override(uint(id), req, claims.UserID, claims.DepartmentID, audit.NewRequestMeta(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDeclarationNotFound):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrNoConflict), errors.Is(err, ErrAlreadyOverridden):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusBadRequest, "Failed to override conflict", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Conflict overridden", declaration)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
@@ -0,0 +1,91 @@
+package conflicts
+
+import (
+	"backend/internal/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Save(declaration *domain.ConflictDeclaration) error
+	GetByID(id uint) (*domain.ConflictDeclaration, error)
+	// Find returns the advisor's declaration for the team, or nil when there is none
+	Find(advisorID, teamID uint) (*domain.ConflictDeclaration, error)
+	GetByAdvisor(advisorID uint) ([]domain.ConflictDeclaration, error)
+	GetByDepartment(departmentID uint, flaggedOnly bool) ([]domain.ConflictDeclaration, error)
+
+	GetTeam(id uint) (*domain.Team, error)
+	GetUser(id uint) (*domain.User, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Save(declaration *domain.ConflictDeclaration) error {
+	return r.db.Omit("Advisor", "Team").Save(declaration).Error
+}
+
+func (r *repository) GetByID(id uint) (*domain.ConflictDeclaration, error) {
+	var declaration domain.ConflictDeclaration
+	err := r.db.Preload("Advisor").Preload("Team").First(&declaration, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &declaration, nil
+}
+
+func (r *repository) Find(advisorID, teamID uint) (*domain.ConflictDeclaration, error) {
+	var declaration domain.ConflictDeclaration
+	err := r.db.Where("advisor_id = ? AND team_id = ?", advisorID, teamID).First(&declaration).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &declaration, nil
+}
+
+func (r *repository) GetByAdvisor(advisorID uint) ([]domain.ConflictDeclaration, error) {
+	var declarations []domain.ConflictDeclaration
+	err := r.db.Preload("Team").
+		Where("advisor_id = ?", advisorID).
+		Order("declared_at DESC").
+		Find(&declarations).Error
+	return declarations, err
+}
+
+// GetByDepartment lists the department's declarations, flagged and not yet overridden first
+func (r *repository) GetByDepartment(departmentID uint, flaggedOnly bool) ([]domain.ConflictDeclaration, error) {
+	var declarations []domain.ConflictDeclaration
+	query := r.db.Preload("Advisor").Preload("Team").Where("department_id = ?", departmentID)
+	if flaggedOnly {
+		query = query.Where("has_conflict = ?", true)
+	}
+	err := query.
+		Order("has_conflict DESC, overridden_at IS NOT NULL, declared_at DESC").
+		Find(&declarations).Error
+	return declarations, err
+}
+
+func (r *repository) GetTeam(id uint) (*domain.Team, error) {
+	var team domain.Team
+	if err := r.db.First(&team, id).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
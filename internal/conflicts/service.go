@@ -0,0 +1,150 @@
+package conflicts
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDeclarationNotFound = errors.New("declaration not found")
+	ErrNoConflict          = errors.New("the declaration does not flag a conflict")
+	ErrAlreadyOverridden   = errors.New("the conflict has already been overridden")
+)
+
+type Service struct {
+	repo        Repository
+	auditLogger *audit.Logger
+}
+
+func NewService(repo Repository, auditLogger *audit.Logger) *Service {
+	return &Service{repo: repo, auditLogger: auditLogger}
+}
+
+// DeclareRequest states whether the advisor has a conflict with a team. Nature and details are
+// required when has_conflict is set.
+type DeclareRequest struct {
+	TeamID      uint   `json:"team_id" binding:"required" example:"7"`
+	HasConflict *bool  `json:"has_conflict" binding:"required" example:"true"`
+	Nature      string `json:"nature" example:"relative"` // relative, personal, financial, professional, other
+	Details     string `json:"details" example:"My cousin is a member of this team"`
+}
+
+type OverrideRequest struct {
+	Reason string `json:"reason" binding:"required" example:"No other advisor in the department covers embedded systems"`
+}
+
+// Declare records or updates the advisor's declaration for a team of their department.
+// Changing a declaration clears an earlier override, so the department head reviews it again.
+func (s *Service) Declare(req DeclareRequest, advisorID uint, meta audit.RequestMeta) (*domain.ConflictDeclaration, error) {
+	advisor, err := s.repo.GetUser(advisorID)
+	if err != nil || advisor.Role != enums.RoleAdvisor {
+		return nil, errors.New("only advisors can declare conflicts of interest")
+	}
+	team, err := s.repo.GetTeam(req.TeamID)
+	if err != nil || team.DepartmentID != advisor.DepartmentID {
+		return nil, errors.New("team not found")
+	}
+
+	hasConflict := *req.HasConflict
+	nature := strings.TrimSpace(req.Nature)
+	details := strings.TrimSpace(req.Details)
+	if hasConflict {
+		if !enums.IsValidConflictNature(nature) {
+			return nil, errors.New("nature must be one of relative, personal, financial, professional, other")
+		}
+		if details == "" {
+			return nil, errors.New("details are required when declaring a conflict")
+		}
+	} else {
+		nature, details = "", ""
+	}
+
+	declaration, err := s.repo.Find(advisor.ID, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	var old interface{}
+	if declaration == nil {
+		declaration = &domain.ConflictDeclaration{AdvisorID: advisor.ID, TeamID: team.ID}
+	} else {
+		previous := *declaration
+		old = previous
+	}
+
+	declaration.DepartmentID = team.DepartmentID
+	declaration.HasConflict = hasConflict
+	declaration.Nature = enums.ConflictNature(nature)
+	declaration.Details = details
+	declaration.DeclaredAt = time.Now()
+	declaration.OverriddenBy = nil
+	declaration.OverriddenAt = nil
+	declaration.OverrideReason = ""
+	if err := s.repo.Save(declaration); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.LogAction("conflict_declaration", declaration.ID, "conflict_declared", &advisor.ID, string(advisor.Role), advisor.Email, old,
+		map[string]interface{}{
+			"team_id":      team.ID,
+			"has_conflict": hasConflict,
+			"nature":       nature,
+			"details":      details,
+		}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+
+	return s.repo.GetByID(declaration.ID)
+}
+
+// GetMyDeclarations lists the advisor's declarations, newest first
+func (s *Service) GetMyDeclarations(advisorID uint) ([]domain.ConflictDeclaration, error) {
+	return s.repo.GetByAdvisor(advisorID)
+}
+
+// GetDepartmentDeclarations lists the declarations of the department's advisors
+func (s *Service) GetDepartmentDeclarations(departmentID uint, flaggedOnly bool) ([]domain.ConflictDeclaration, error) {
+	return s.repo.GetByDepartment(departmentID, flaggedOnly)
+}
+
+// Override lets the department head assign the advisor to the team despite the declared conflict
+func (s *Service) Override(id uint, req OverrideRequest, adminID uint, departmentID uint, meta audit.RequestMeta) (*domain.ConflictDeclaration, error) {
+	declaration, err := s.repo.GetByID(id)
+	if err != nil || declaration.DepartmentID != departmentID {
+		return nil, ErrDeclarationNotFound
+	}
+	if !declaration.HasConflict {
+		return nil, ErrNoConflict
+	}
+	if declaration.OverriddenAt != nil {
+		return nil, ErrAlreadyOverridden
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to override a conflict")
+	}
+
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	now := time.Now()
+	declaration.OverriddenBy = &admin.ID
+	declaration.OverriddenAt = &now
+	declaration.OverrideReason = reason
+	if err := s.repo.Save(declaration); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.LogAction("conflict_declaration", declaration.ID, "conflict_overridden", &admin.ID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{
+			"advisor_id": declaration.AdvisorID,
+			"team_id":    declaration.TeamID,
+			"nature":     declaration.Nature,
+			"reason":     reason,
+		}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+
+	return s.repo.GetByID(declaration.ID)
+}
@@ -0,0 +1,155 @@
+package delegations
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/audit"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// CreateDelegation godoc
+// @Summary Delegate approval rights
+// @Description Lends the department admin's approval rights to a teacher of the same department for a date range
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateDelegationRequest true "Delegate and period"
+// @Success 201 {object} response.Response{data=domain.Delegation}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/delegations [post]
+func (h *Handler) CreateDelegation(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req CreateDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	delegation, err := h.service.Create(req, claims.UserID, audit.NewRequestMeta(c))
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "an overlapping delegation already exists" {
+			status = http.StatusConflict
+		}
+		response.Error(c, status, "Failed to create delegation", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusCreated, "Delegation created", delegation)
+}
+
+// GetDelegations godoc
+// @Summary List department delegations
+// @Description Lists current and upcoming delegations in the admin's department; all=true includes ended and revoked ones
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param all query bool false "Include ended and revoked delegations"
+// @Success 200 {object} response.Response{data=[]domain.Delegation}
+// @Router /admin/delegations [get]
+func (h *Handler) GetDelegations(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	delegations, err := h.service.GetDepartmentDelegations(claims.DepartmentID, c.Query("all") == "true")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch delegations", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Delegations retrieved", delegations)
+}
+
+// RevokeDelegation godoc
+// @Summary Revoke a delegation
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Delegation ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/delegations/{id} [delete]
+func (h *Handler) RevokeDelegation(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	if err := h.service.Revoke(id, claims.UserID, claims.DepartmentID, audit.NewRequestMeta(c)); err != nil {
+		switch err.Error() {
+		case "delegation not found":
+			response.Error(c, http.StatusNotFound, "Delegation not found", err.Error())
+		case "delegation already revoked":
+			response.Error(c, http.StatusConflict, "Delegation already revoked", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to revoke delegation", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Delegation revoked", nil)
+}
+
+// GetMyDelegations godoc
+// @Summary Delegations held by the current teacher
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]domain.Delegation}
+// @Router /users/me/delegations [get]
+func (h *Handler) GetMyDelegations(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	delegations, err := h.service.GetMyDelegations(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch delegations", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Delegations retrieved", delegations)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseID(c *gin.Context) uint {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return 0
+	}
+	return uint(id)
+}
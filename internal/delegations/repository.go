@@ -0,0 +1,94 @@
+package delegations
+
+import (
+	"backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(delegation *domain.Delegation) error
+	GetByID(id uint) (*domain.Delegation, error)
+	Update(delegation *domain.Delegation) error
+	GetByDepartment(departmentID uint, includeInactive bool) ([]domain.Delegation, error)
+	GetByDelegate(delegateID uint) ([]domain.Delegation, error)
+
+	// FindActive returns the delegation the delegate may act under at the given time
+	FindActive(delegateID uint, at time.Time) (*domain.Delegation, error)
+	HasOverlap(delegatorID, delegateID uint, startsAt, endsAt time.Time) (bool, error)
+	GetUser(id uint) (*domain.User, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(delegation *domain.Delegation) error {
+	return r.db.Create(delegation).Error
+}
+
+func (r *repository) GetByID(id uint) (*domain.Delegation, error) {
+	var delegation domain.Delegation
+	err := r.db.Preload("Delegator").Preload("Delegate").First(&delegation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (r *repository) Update(delegation *domain.Delegation) error {
+	return r.db.Save(delegation).Error
+}
+
+func (r *repository) GetByDepartment(departmentID uint, includeInactive bool) ([]domain.Delegation, error) {
+	var delegations []domain.Delegation
+	query := r.db.Preload("Delegator").Preload("Delegate").Where("department_id = ?", departmentID)
+	if !includeInactive {
+		query = query.Where("revoked_at IS NULL AND ends_at > ?", time.Now())
+	}
+	err := query.Order("starts_at DESC").Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *repository) GetByDelegate(delegateID uint) ([]domain.Delegation, error) {
+	var delegations []domain.Delegation
+	err := r.db.Preload("Delegator").
+		Where("delegate_id = ? AND revoked_at IS NULL AND ends_at > ?", delegateID, time.Now()).
+		Order("starts_at ASC").
+		Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *repository) FindActive(delegateID uint, at time.Time) (*domain.Delegation, error) {
+	var delegation domain.Delegation
+	err := r.db.Where("delegate_id = ? AND revoked_at IS NULL AND starts_at <= ? AND ends_at > ?", delegateID, at, at).
+		Order("starts_at DESC").
+		First(&delegation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+// HasOverlap reports whether either party already has a live delegation in the range
+func (r *repository) HasOverlap(delegatorID, delegateID uint, startsAt, endsAt time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Delegation{}).
+		Where("revoked_at IS NULL AND starts_at < ? AND ends_at > ?", endsAt, startsAt).
+		Where("delegator_id = ? OR delegate_id = ?", delegatorID, delegateID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
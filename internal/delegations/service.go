@@ -0,0 +1,138 @@
+package delegations
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"errors"
+	"time"
+)
+
+// MaxDelegationLength caps how long approval rights can be lent in one go
+const MaxDelegationLength = 90 * 24 * time.Hour
+
+type Service struct {
+	repo        Repository
+	auditLogger *audit.Logger
+}
+
+func NewService(repo Repository, auditLogger *audit.Logger) *Service {
+	return &Service{repo: repo, auditLogger: auditLogger}
+}
+
+type CreateDelegationRequest struct {
+	DelegateID uint      `json:"delegate_id" binding:"required"`
+	StartsAt   time.Time `json:"starts_at" binding:"required"`
+	EndsAt     time.Time `json:"ends_at" binding:"required"`
+	Reason     string    `json:"reason"`
+}
+
+// Create lends the admin's approval rights to a teacher of the same department
+func (s *Service) Create(req CreateDelegationRequest, adminID uint, meta audit.RequestMeta) (*domain.Delegation, error) {
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil || admin.Role != enums.RoleAdmin || admin.DepartmentID == 0 {
+		return nil, errors.New("only a department admin can delegate approval rights")
+	}
+
+	delegate, err := s.repo.GetUser(req.DelegateID)
+	if err != nil {
+		return nil, errors.New("delegate not found")
+	}
+	if delegate.Role != enums.RoleAdvisor {
+		return nil, errors.New("delegate must be a teacher")
+	}
+	if delegate.DepartmentID != admin.DepartmentID {
+		return nil, errors.New("delegate must belong to your department")
+	}
+	if !delegate.IsActive {
+		return nil, errors.New("delegate account is inactive")
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, errors.New("ends_at must be after starts_at")
+	}
+	if !req.EndsAt.After(time.Now()) {
+		return nil, errors.New("delegation period has already ended")
+	}
+	if req.EndsAt.Sub(req.StartsAt) > MaxDelegationLength {
+		return nil, errors.New("delegation period is too long")
+	}
+
+	overlap, err := s.repo.HasOverlap(admin.ID, delegate.ID, req.StartsAt, req.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		return nil, errors.New("an overlapping delegation already exists")
+	}
+
+	delegation := &domain.Delegation{
+		DelegatorID:  admin.ID,
+		DelegateID:   delegate.ID,
+		DepartmentID: admin.DepartmentID,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Reason:       req.Reason,
+	}
+	if err := s.repo.Create(delegation); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.LogAction("delegation", delegation.ID, "delegation_created", &admin.ID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{
+			"delegate_id": delegate.ID,
+			"starts_at":   delegation.StartsAt,
+			"ends_at":     delegation.EndsAt,
+		}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+
+	return s.repo.GetByID(delegation.ID)
+}
+
+// Revoke ends a delegation early
+func (s *Service) Revoke(id uint, adminID uint, departmentID uint, meta audit.RequestMeta) error {
+	delegation, err := s.repo.GetByID(id)
+	if err != nil || delegation.DepartmentID != departmentID {
+		return errors.New("delegation not found")
+	}
+	if delegation.RevokedAt != nil {
+		return errors.New("delegation already revoked")
+	}
+
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return errors.New("admin not found")
+	}
+
+	now := time.Now()
+	delegation.RevokedAt = &now
+	if err := s.repo.Update(delegation); err != nil {
+		return err
+	}
+
+	s.auditLogger.LogAction("delegation", delegation.ID, "delegation_revoked", &admin.ID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{
+			"delegator_id": delegation.DelegatorID,
+			"delegate_id":  delegation.DelegateID,
+		}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+
+	return nil
+}
+
+// GetDepartmentDelegations lists current and upcoming delegations, or all of them
+func (s *Service) GetDepartmentDelegations(departmentID uint, includeInactive bool) ([]domain.Delegation, error) {
+	return s.repo.GetByDepartment(departmentID, includeInactive)
+}
+
+// GetMyDelegations lists the current and upcoming delegations held by a teacher
+func (s *Service) GetMyDelegations(delegateID uint) ([]domain.Delegation, error) {
+	return s.repo.GetByDelegate(delegateID)
+}
+
+// ResolveActive returns the delegation a teacher is acting under right now, if any
+func (s *Service) ResolveActive(delegateID uint) (*domain.Delegation, bool) {
+	delegation, err := s.repo.FindActive(delegateID, time.Now())
+	if err != nil {
+		return nil, false
+	}
+	return delegation, true
+}
@@ -1,8 +1,10 @@
 package departments
 
 import (
+	"backend/internal/auth"
 	"backend/internal/domain"
 	"backend/pkg/response"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -171,3 +173,93 @@ func (h *Handler) DeleteDepartment(c *gin.Context) {
 
 	response.JSON(c, http.StatusOK, "Department deleted successfully", nil)
 }
+
+// GetShowcase godoc
+// @Summary Department project showcase
+// @Description Top public projects of a department per academic year, newest year first, for open-house events. Projects are ranked by a composite score out of 100: 40% review rating (blended with the year's average so a few reviews do not dominate), 20% views (log-scaled against the year's most viewed project) and 40% the advisor's locked grade; ungraded projects are scored on reviews and views alone. Only departments that opted in have a showcase.
+// @Tags Departments
+// @Produce json
+// @Param id path int true "Department ID"
+// @Param year query string false "Only this academic year, e.g. 2025/2026"
+// @Param limit query int false "Projects per year (default 10, max 50)"
+// @Success 200 {object} response.Response{data=Showcase}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /departments/{id}/showcase [get]
+func (h *Handler) GetShowcase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid department ID", err.Error())
+		return
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid limit", err.Error())
+			return
+		}
+	}
+
+	showcase, err := h.service.GetShowcase(uint(id), c.Query("year"), limit)
+	if err != nil {
+		if errors.Is(err, ErrShowcaseDisabled) || err.Error() == "department not found" {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to build showcase", err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	response.Success(c, showcase)
+}
+
+// UpdateShowcase godoc
+// @Summary Opt in to the project showcase
+// @Description Turns the public project showcase of the admin's department on or off
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateShowcaseRequest true "Whether the showcase is enabled"
+// @Success 200 {object} response.Response{data=domain.Department}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/showcase [put]
+func (h *Handler) UpdateShowcase(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	departmentID := claims.DepartmentID
+	if departmentID == 0 {
+		response.Error(c, http.StatusBadRequest, "Only a department admin can change the showcase", nil)
+		return
+	}
+
+	var req UpdateShowcaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	department, err := h.service.SetShowcaseEnabled(departmentID, req.Enabled)
+	if err != nil {
+		if err.Error() == "department not found" {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to update showcase", err.Error())
+		return
+	}
+	response.Success(c, department)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
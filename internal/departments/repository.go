@@ -13,6 +13,10 @@ type Repository interface {
 	GetByUniversityID(universityID uint) ([]domain.Department, error)
 	Update(department *domain.Department) error
 	Delete(id uint) error
+
+	// Project showcase
+	SetShowcaseEnabled(id uint, enabled bool) error
+	GetShowcaseCandidates(departmentID uint, academicYear string) ([]ShowcaseCandidate, error)
 }
 
 type repository struct {
@@ -55,3 +59,34 @@ func (r *repository) Update(department *domain.Department) error {
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&domain.Department{}, id).Error
 }
+
+func (r *repository) SetShowcaseEnabled(id uint, enabled bool) error {
+	return r.db.Model(&domain.Department{}).Where("id = ?", id).Update("showcase_enabled", enabled).Error
+}
+
+// GetShowcaseCandidates returns the department's public projects with their review totals, views
+// and locked advisor score, optionally for one academic year
+func (r *repository) GetShowcaseCandidates(departmentID uint, academicYear string) ([]ShowcaseCandidate, error) {
+	query := r.db.Table("projects").
+		Select(`projects.id AS project_id, projects.slug, projects.summary, projects.view_count,
+			latest.title, COALESCE(teams.name, '') AS team_name, proposals.academic_year,
+			COALESCE(reviews.review_count, 0) AS review_count, COALESCE(reviews.review_sum, 0) AS review_sum,
+			project_grades.advisor_score`).
+		Joins("JOIN proposals ON proposals.id = projects.proposal_id").
+		Joins(`JOIN LATERAL (SELECT title FROM proposal_versions
+			WHERE proposal_versions.proposal_id = projects.proposal_id
+			ORDER BY version_number DESC LIMIT 1) latest ON true`).
+		Joins("LEFT JOIN teams ON teams.id = projects.team_id").
+		Joins(`LEFT JOIN (SELECT project_id, COUNT(*) AS review_count, SUM(rate) AS review_sum
+			FROM project_reviews WHERE moderation_status = 'approved' GROUP BY project_id) reviews ON reviews.project_id = projects.id`).
+		Joins("LEFT JOIN project_grades ON project_grades.project_id = projects.id").
+		Where("projects.department_id = ? AND projects.visibility = ?", departmentID, "public").
+		Where("proposals.academic_year <> ''")
+	if academicYear != "" {
+		query = query.Where("proposals.academic_year = ?", academicYear)
+	}
+
+	var candidates []ShowcaseCandidate
+	err := query.Scan(&candidates).Error
+	return candidates, err
+}
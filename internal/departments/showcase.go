@@ -0,0 +1,189 @@
+package departments
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"math"
+	"sort"
+)
+
+// Showcase score weights; a project without a locked grade is scored on reviews and views alone
+const (
+	ShowcaseReviewWeight  = 0.4
+	ShowcaseViewWeight    = 0.2
+	ShowcaseAdvisorWeight = 0.4
+
+	// showcaseReviewPrior is how many average reviews a project's rating is blended with, so a
+	// single 5-star review does not outrank many good ones
+	showcaseReviewPrior = 3
+
+	DefaultShowcaseLimit = 10 // per year
+	MaxShowcaseLimit     = 50
+)
+
+var ErrShowcaseDisabled = errors.New("this department has not opted in to the project showcase")
+
+// ShowcaseCandidate is a public project of the department with its raw signals
+type ShowcaseCandidate struct {
+	ProjectID    uint
+	Slug         *string
+	Title        string
+	Summary      string
+	TeamName     string
+	AcademicYear string
+	ViewCount    int
+	ReviewCount  int
+	ReviewSum    int
+	AdvisorScore *float64 // from the locked grade; nil until graded
+}
+
+// ShowcaseEntry is a ranked project with its composite score out of 100 and the parts it is made of
+type ShowcaseEntry struct {
+	Rank          int      `json:"rank"`
+	ProjectID     uint     `json:"project_id"`
+	Slug          *string  `json:"slug,omitempty"`
+	Title         string   `json:"title"`
+	Summary       string   `json:"summary"`
+	TeamName      string   `json:"team_name"`
+	Score         float64  `json:"score"`
+	AverageRating *float64 `json:"average_rating,omitempty"` // 1-5, nil without reviews
+	ReviewCount   int      `json:"review_count"`
+	ViewCount     int      `json:"view_count"`
+	AdvisorScore  *float64 `json:"advisor_score,omitempty"` // out of 100
+}
+
+// ShowcaseYear is the top projects of one academic year
+type ShowcaseYear struct {
+	AcademicYear string          `json:"academic_year"`
+	Projects     []ShowcaseEntry `json:"projects"`
+}
+
+// Showcase is a department's top public projects per academic year, newest year first
+type Showcase struct {
+	DepartmentID   uint           `json:"department_id"`
+	DepartmentName string         `json:"department_name"`
+	Years          []ShowcaseYear `json:"years"`
+}
+
+// UpdateShowcaseRequest opts the admin's department in to or out of the showcase
+type UpdateShowcaseRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetShowcase ranks the department's public projects by a composite of their review rating, views
+// and the advisor's grade, per academic year. Only departments that opted in have a showcase.
+func (s *Service) GetShowcase(departmentID uint, year string, limit int) (*Showcase, error) {
+	department, err := s.repo.GetByID(departmentID)
+	if err != nil {
+		return nil, errors.New("department not found")
+	}
+	if !department.ShowcaseEnabled {
+		return nil, ErrShowcaseDisabled
+	}
+	if limit <= 0 {
+		limit = DefaultShowcaseLimit
+	}
+	if limit > MaxShowcaseLimit {
+		limit = MaxShowcaseLimit
+	}
+
+	candidates, err := s.repo.GetShowcaseCandidates(departmentID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	byYear := make(map[string][]ShowcaseCandidate)
+	var years []string
+	for _, c := range candidates {
+		if _, seen := byYear[c.AcademicYear]; !seen {
+			years = append(years, c.AcademicYear)
+		}
+		byYear[c.AcademicYear] = append(byYear[c.AcademicYear], c)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(years)))
+
+	showcase := &Showcase{DepartmentID: department.ID, DepartmentName: department.Name, Years: make([]ShowcaseYear, 0, len(years))}
+	for _, y := range years {
+		entries := rankShowcase(byYear[y])
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+		showcase.Years = append(showcase.Years, ShowcaseYear{AcademicYear: y, Projects: entries})
+	}
+	return showcase, nil
+}
+
+// SetShowcaseEnabled opts a department in to or out of the public showcase
+func (s *Service) SetShowcaseEnabled(departmentID uint, enabled bool) (*domain.Department, error) {
+	department, err := s.repo.GetByID(departmentID)
+	if err != nil {
+		return nil, errors.New("department not found")
+	}
+	if err := s.repo.SetShowcaseEnabled(departmentID, enabled); err != nil {
+		return nil, err
+	}
+	department.ShowcaseEnabled = enabled
+	return department, nil
+}
+
+// rankShowcase scores one year's projects against each other. Views are log-scaled against the
+// year's most viewed project; ratings are blended with the year's average rating.
+func rankShowcase(candidates []ShowcaseCandidate) []ShowcaseEntry {
+	maxViews, reviewSum, reviewCount := 0, 0, 0
+	for _, c := range candidates {
+		if c.ViewCount > maxViews {
+			maxViews = c.ViewCount
+		}
+		reviewSum += c.ReviewSum
+		reviewCount += c.ReviewCount
+	}
+	prior := 3.0
+	if reviewCount > 0 {
+		prior = float64(reviewSum) / float64(reviewCount)
+	}
+
+	entries := make([]ShowcaseEntry, 0, len(candidates))
+	for _, c := range candidates {
+		blended := (prior*showcaseReviewPrior + float64(c.ReviewSum)) / float64(showcaseReviewPrior+c.ReviewCount)
+		total := ShowcaseReviewWeight * (blended - 1) / 4
+		weights := ShowcaseReviewWeight
+
+		if maxViews > 0 {
+			total += ShowcaseViewWeight * math.Log1p(float64(c.ViewCount)) / math.Log1p(float64(maxViews))
+		}
+		weights += ShowcaseViewWeight
+
+		if c.AdvisorScore != nil {
+			total += ShowcaseAdvisorWeight * *c.AdvisorScore / 100
+			weights += ShowcaseAdvisorWeight
+		}
+
+		entry := ShowcaseEntry{
+			ProjectID:    c.ProjectID,
+			Slug:         c.Slug,
+			Title:        c.Title,
+			Summary:      c.Summary,
+			TeamName:     c.TeamName,
+			Score:        math.Round(total/weights*1000) / 10,
+			ReviewCount:  c.ReviewCount,
+			ViewCount:    c.ViewCount,
+			AdvisorScore: c.AdvisorScore,
+		}
+		if c.ReviewCount > 0 {
+			average := math.Round(float64(c.ReviewSum)/float64(c.ReviewCount)*10) / 10
+			entry.AverageRating = &average
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].ViewCount > entries[j].ViewCount
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
@@ -2,7 +2,9 @@ package documentations
 
 import (
 	"backend/internal/auth"
+	"backend/internal/files"
 	"backend/pkg/response"
+	"errors"
 	"net/http"
 	"strconv"
 	"github.com/gin-gonic/gin"
@@ -54,6 +56,14 @@ func (h *Handler) Submit(c *gin.Context) {
 	// 3. Call Service
 	doc, err := h.service.SubmitDoc(uint(projectID), userClaims.UserID, docType, url, file)
 	if err != nil {
+		if errors.Is(err, files.ErrTeamQuotaExceeded) || errors.Is(err, files.ErrUserQuotaExceeded) {
+			response.Error(c, http.StatusRequestEntityTooLarge, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, files.ErrFileInfected) || errors.Is(err, ErrLinkUnreachable) {
+			response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
 		response.Error(c, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
@@ -88,4 +98,27 @@ func (h *Handler) Review(c *gin.Context) {
 		return
 	}
 	response.JSON(c, http.StatusOK, "Review recorded", nil)
-}
\ No newline at end of file
+}
+
+// CheckLink re-checks a code or deployed link now instead of waiting for the daily check
+func (h *Handler) CheckLink(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+	docID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	doc, err := h.service.CheckLink(uint(docID), userClaims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDocumentNotFound):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrNotTeamOrAdvisor):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrNotLinkType):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to check link", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Link checked", doc)
+}
@@ -0,0 +1,268 @@
+package documentations
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// LinkCheckTimeout bounds a single check, redirects included
+	LinkCheckTimeout = 10 * time.Second
+	// LinkRecheckInterval is how often the job looks for links due a new check
+	LinkRecheckInterval = time.Hour
+	// LinkRecheckAge is how old a check must be before the link is checked again
+	LinkRecheckAge = 24 * time.Hour
+	// LinkBrokenAfter is how many checks in a row must fail before a link is flagged as broken,
+	// so a short outage of a student's deployment does not flag it
+	LinkBrokenAfter = 2
+
+	linkRecheckBatch = 100
+	maxLinkRedirects = 5
+	maxTitleBytes    = 256 << 10
+)
+
+var (
+	ErrInvalidLink      = errors.New("the link must be an http or https URL")
+	ErrLinkUnreachable  = errors.New("the link could not be reached")
+	ErrDocumentNotFound = errors.New("document not found")
+	ErrNotTeamOrAdvisor = errors.New("only the project's team or advisor can check its links")
+	ErrNotLinkType      = errors.New("only code and deployed links can be checked")
+	errPrivateAddress   = errors.New("the link points to a private network address")
+)
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// isLinkType reports whether documents of this type are links rather than uploaded files
+func isLinkType(docType string) bool {
+	return docType == "code_link" || docType == "deployed_link"
+}
+
+// LinkChecker checks that external links are reachable. Links are entered by students, so it only
+// connects to public addresses.
+type LinkChecker struct {
+	client *http.Client
+}
+
+func NewLinkChecker() *LinkChecker {
+	dialer := &net.Dialer{Timeout: LinkCheckTimeout, Control: rejectPrivateAddress}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   LinkCheckTimeout,
+		ResponseHeaderTimeout: LinkCheckTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &LinkChecker{client: &http.Client{
+		Timeout:   LinkCheckTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxLinkRedirects {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrInvalidLink
+			}
+			return nil
+		},
+	}}
+}
+
+// rejectPrivateAddress runs after DNS resolution, so hostnames resolving to internal addresses are refused too
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// ParseLink checks the link is an absolute http or https URL
+func ParseLink(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidLink
+	}
+	return u, nil
+}
+
+// Check requests the link with HEAD, falling back to GET for servers that do not allow HEAD, and
+// reads the page title from HTML responses. A 4xx or 5xx status counts as unreachable.
+func (c *LinkChecker) Check(ctx context.Context, link string) domain.LinkCheck {
+	now := time.Now()
+	result := domain.LinkCheck{LinkCheckedAt: &now}
+
+	u, err := ParseLink(link)
+	if err != nil {
+		result.LinkError = err.Error()
+		return result
+	}
+
+	status, contentType, err := c.do(ctx, http.MethodHead, u.String(), nil)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+		status, contentType, err = c.do(ctx, http.MethodGet, u.String(), nil)
+	}
+	if err != nil {
+		result.LinkError = truncate(linkErrorMessage(err), 300)
+		return result
+	}
+	result.LinkHTTPStatus = status
+	if status >= 400 {
+		result.LinkError = fmt.Sprintf("the link returned HTTP %d", status)
+		return result
+	}
+
+	// HEAD has no body, so fetch the start of HTML pages for their title
+	if strings.Contains(contentType, "text/html") {
+		var title string
+		if _, _, err := c.do(ctx, http.MethodGet, u.String(), &title); err == nil {
+			result.LinkTitle = truncate(title, 300)
+		}
+	}
+	result.LinkStatus = enums.LinkStatusOK
+	return result
+}
+
+func (c *LinkChecker) do(ctx context.Context, method, link string, title *string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("User-Agent", "CapstoneLinkChecker/1.0")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	if title != nil {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxTitleBytes))
+		if m := titlePattern.FindSubmatch(body); m != nil {
+			*title = strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+		}
+	}
+	return resp.StatusCode, resp.Header.Get("Content-Type"), nil
+}
+
+func linkErrorMessage(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errPrivateAddress):
+		return errPrivateAddress.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "the link did not respond in time"
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return "the link's host could not be found"
+	}
+	return err.Error()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// applyLinkCheck records a check on the document. A reachable link is ok straight away; a failing one
+// is only flagged as broken after LinkBrokenAfter checks in a row, keeping its last status until then.
+func applyLinkCheck(doc *domain.ProjectDocumentation, check domain.LinkCheck) {
+	if check.LinkStatus == enums.LinkStatusOK {
+		doc.LinkCheck = check
+		return
+	}
+	doc.LinkFailures++
+	doc.LinkHTTPStatus = check.LinkHTTPStatus
+	doc.LinkError = check.LinkError
+	doc.LinkCheckedAt = check.LinkCheckedAt
+	if doc.LinkFailures >= LinkBrokenAfter {
+		doc.LinkStatus = enums.LinkStatusBroken
+	}
+}
+
+// CheckLink re-checks one of the project's links on request, e.g. after the team fixed a deployment.
+// Only accepted members of the project's team and its advisor may ask.
+func (s *Service) CheckLink(docID uint, userID uint) (*domain.ProjectDocumentation, error) {
+	doc, err := s.repo.GetByID(docID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.repo.IsTeamOrAdvisor(doc.ProjectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrNotTeamOrAdvisor
+	}
+	if !isLinkType(doc.DocumentType) {
+		return nil, ErrNotLinkType
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*LinkCheckTimeout)
+	defer cancel()
+	check := s.links.Check(ctx, doc.URL)
+	if check.LinkStatus == enums.LinkStatusOK {
+		doc.LinkCheck = check
+	} else {
+		// Asked for explicitly, so a failure is reported as it is
+		check.LinkStatus = enums.LinkStatusBroken
+		check.LinkFailures = doc.LinkFailures + 1
+		doc.LinkCheck = check
+	}
+	if err := s.repo.UpdateLinkCheck(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// RecheckLinks checks again the links whose last check is older than LinkRecheckAge, oldest first
+func (s *Service) RecheckLinks() {
+	docs, err := s.repo.GetLinksDueCheck(time.Now().Add(-LinkRecheckAge), linkRecheckBatch)
+	if err != nil {
+		log.Printf("link recheck: %v", err)
+		return
+	}
+
+	broken := 0
+	for i := range docs {
+		doc := &docs[i]
+		ctx, cancel := context.WithTimeout(context.Background(), 2*LinkCheckTimeout)
+		check := s.links.Check(ctx, doc.URL)
+		cancel()
+
+		applyLinkCheck(doc, check)
+		if doc.LinkStatus == enums.LinkStatusBroken {
+			broken++
+		}
+		if err := s.repo.UpdateLinkCheck(doc); err != nil {
+			log.Printf("link recheck: document %d: %v", doc.ID, err)
+		}
+	}
+	if len(docs) > 0 {
+		log.Printf("link recheck: checked %d link(s), %d broken", len(docs), broken)
+	}
+}
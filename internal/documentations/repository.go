@@ -2,6 +2,9 @@ package documentations
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -12,6 +15,12 @@ type Repository interface {
 	GetByType(projectID uint, docType string) (*domain.ProjectDocumentation, error)
 	Update(doc *domain.ProjectDocumentation) error
 	Delete(id uint) error
+	GetProjectTeamID(projectID uint) (uint, error)
+	IsTeamOrAdvisor(projectID uint, userID uint) (bool, error)
+
+	// Link checks
+	GetLinksDueCheck(checkedBefore time.Time, limit int) ([]domain.ProjectDocumentation, error)
+	UpdateLinkCheck(doc *domain.ProjectDocumentation) error
 }
 
 type repository struct {
@@ -44,9 +53,52 @@ func (r *repository) Update(doc *domain.ProjectDocumentation) error { return r.d
 
 func (r *repository) Delete(id uint) error { return r.db.Delete(&domain.ProjectDocumentation{}, id).Error }
 
+func (r *repository) GetProjectTeamID(projectID uint) (uint, error) {
+	var project domain.Project
+	err := r.db.Select("id", "team_id").First(&project, projectID).Error
+	return project.TeamID, err
+}
+
+// IsTeamOrAdvisor reports whether the user is an accepted member of the project's team or the
+// advisor of its proposal
+func (r *repository) IsTeamOrAdvisor(projectID uint, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("projects").
+		Joins("JOIN proposals ON proposals.id = projects.proposal_id").
+		Where("projects.id = ?", projectID).
+		Where("proposals.advisor_id = ? OR EXISTS (SELECT 1 FROM team_members WHERE team_members.team_id = projects.team_id AND team_members.user_id = ? AND team_members.invitation_status = ?)",
+			userID, userID, enums.InvitationStatusAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *repository) IncrementViewCount(id uint) error {
     // ⚠️ Match the field "view_count" added in Step 1
 	return r.db.Model(&domain.Project{}).
 		Where("id = ?", id).
 		Update("view_count", gorm.Expr("view_count + ?", 1)).Error
-}
\ No newline at end of file
+}
+
+// GetLinksDueCheck returns code and deployed links never checked or last checked before the cutoff, oldest first
+func (r *repository) GetLinksDueCheck(checkedBefore time.Time, limit int) ([]domain.ProjectDocumentation, error) {
+	var docs []domain.ProjectDocumentation
+	err := r.db.
+		Where("document_type IN ?", []string{"code_link", "deployed_link"}).
+		Where("link_checked_at IS NULL OR link_checked_at < ?", checkedBefore).
+		Order("link_checked_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&docs).Error
+	return docs, err
+}
+
+// UpdateLinkCheck saves only the check columns, so a concurrent review of the document is not overwritten
+func (r *repository) UpdateLinkCheck(doc *domain.ProjectDocumentation) error {
+	return r.db.Model(&domain.ProjectDocumentation{}).Where("id = ?", doc.ID).Updates(map[string]interface{}{
+		"link_status":      doc.LinkStatus,
+		"link_http_status": doc.LinkHTTPStatus,
+		"link_title":       doc.LinkTitle,
+		"link_error":       doc.LinkError,
+		"link_failures":    doc.LinkFailures,
+		"link_checked_at":  doc.LinkCheckedAt,
+	}).Error
+}
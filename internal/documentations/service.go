@@ -3,7 +3,10 @@ package documentations
 import (
 	"backend/internal/domain"
 	"backend/internal/files"
+	"backend/pkg/enums"
+	"context"
 	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -14,20 +17,40 @@ import (
 type Service struct {
 	repo     Repository
 	uploader *files.Uploader
+	quota    *files.Quota
+	links    *LinkChecker
 }
 
-func NewService(r Repository, u *files.Uploader) *Service {
-	return &Service{repo: r, uploader: u}
+func NewService(r Repository, u *files.Uploader, q *files.Quota, l *LinkChecker) *Service {
+	return &Service{repo: r, uploader: u, quota: q, links: l}
 }
 
 func (s *Service) SubmitDoc(projectID, userID uint, docType, url string, file *multipart.FileHeader) (*domain.ProjectDocumentation, error) {
 	// 1. Check if THIS SPECIFIC document type already exists for this project
 	existing, _ := s.repo.GetByType(projectID, docType)
-	if existing != nil && existing.ID != 0 {
+	if existing != nil && existing.ID != 0 && !isCarriedOver(existing) {
 		return nil, errors.New("this specific document/link already exists. Delete it first to re-upload")
 	}
 
 	finalURL := url
+	var size int64
+	var hash string
+	var meta domain.FileMetadata
+	var check domain.LinkCheck
+
+	// 🔗 Links must be reachable when submitted; the recheck job keeps an eye on them afterwards
+	if file == nil && isLinkType(docType) {
+		u, err := ParseLink(url)
+		if err != nil { return nil, err }
+		finalURL = u.String()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*LinkCheckTimeout)
+		defer cancel()
+		check = s.links.Check(ctx, finalURL)
+		if check.LinkStatus != enums.LinkStatusOK {
+			return nil, fmt.Errorf("%w: %s", ErrLinkUnreachable, check.LinkError)
+		}
+	}
 
 	// 2. Handle physical file validation and upload
 	if file != nil {
@@ -41,28 +64,60 @@ func (s *Service) SubmitDoc(projectID, userID uint, docType, url string, file *m
 			return nil, errors.New("invalid file type: Presentation must be PPT or PPTX")
 		}
 
+		// 📦 Team and per-user upload quotas
+		teamID, err := s.repo.GetProjectTeamID(projectID)
+		if err != nil { return nil, errors.New("project not found") }
+		if err := s.quota.CheckUpload(teamID, userID, file.Size); err != nil { return nil, err }
+
+		// 🔍 Content type, page count and virus scan
+		meta, err = files.Inspect(file)
+		if err != nil { return nil, err }
+		if meta.ScanStatus == enums.ScanStatusInfected { return nil, files.ErrFileInfected }
+		hash, err = files.HashUpload(file)
+		if err != nil { return nil, err }
+
 		path, err := s.uploader.SaveFile(file, "project_docs")
 		if err != nil { return nil, err }
 		finalURL = path
+		size = file.Size
 	}
 
 	doc := &domain.ProjectDocumentation{
-		ProjectID:    projectID,
-		DocumentType: docType, // 'final_report', 'presentation', 'code_link', 'deployed_link'
-		URL:          finalURL,
-		Status:       "pending",
-		SubmittedBy:  userID,
-		SubmittedAt:  time.Now(),
+		ProjectID:     projectID,
+		DocumentType:  docType, // 'final_report', 'presentation', 'code_link', 'deployed_link'
+		URL:           finalURL,
+		FileSizeBytes: size,
+		FileHash:      hash,
+		Status:        "pending",
+		SubmittedBy:   userID,
+		SubmittedAt:   time.Now(),
+		FileMetadata:  meta,
+		LinkCheck:     check,
+	}
+
+	// 📚 A new submission replaces the document carried over from the previous cohort; the file stays with it
+	if existing != nil && existing.ID != 0 {
+		if err := s.repo.Delete(existing.ID); err != nil { return nil, err }
 	}
 
 	if err := s.repo.Create(doc); err != nil { return nil, err }
 	return doc, nil
 }
 
+// isCarriedOver tells whether the document is a copy of a previous cohort's; its file belongs to that cohort
+func isCarriedOver(doc *domain.ProjectDocumentation) bool {
+	return doc.Status == string(enums.DocumentStatusCarriedOver)
+}
+
 func (s *Service) DeleteDoc(docID, userID uint) error {
 	doc, err := s.repo.GetByID(docID)
 	if err != nil { return errors.New("document not found") }
 
+	// 📚 Carried-over documents can be dropped; the file stays with the previous cohort's project
+	if isCarriedOver(doc) {
+		return s.repo.Delete(docID)
+	}
+
 	// 🔒 RULE: Only Pending can be unlinked/deleted
 	if doc.Status != "pending" {
 		return errors.New("cannot unlink an approved document. Contact your advisor")
@@ -84,6 +139,11 @@ func (s *Service) ReviewDoc(docID, reviewerID uint, status string, comment strin
 	doc, err := s.repo.GetByID(docID)
 	if err != nil { return err }
 
+	// 📚 Carried-over documents were reviewed with the previous cohort
+	if isCarriedOver(doc) {
+		return errors.New("carried-over documents were already approved for the previous project and cannot be reviewed")
+	}
+
 	doc.Status = status
 	doc.ReviewComment = comment
 	doc.ReviewedBy = reviewerID
@@ -11,8 +11,13 @@ type University struct {
 	Name             string     `gorm:"unique;not null" json:"name"`
 	AcademicYear     string     `gorm:"type:varchar(50)" json:"academic_year"`
 	ProjectPeriod    string     `gorm:"type:varchar(100)" json:"project_period"`
+	ProjectStartsOn  *time.Time `json:"project_starts_on,omitempty"` // structured project period; phases must fall inside it
+	ProjectEndsOn    *time.Time `json:"project_ends_on,omitempty"`
 	VisibilityRule   string     `gorm:"type:varchar(50);default:'private'" json:"visibility_rule"` // private, public, restricted
 	AICheckerEnabled bool       `gorm:"default:true" json:"ai_checker_enabled"`
+	BlindReview      bool       `gorm:"default:false" json:"blind_review"`   // advisors see proposals without their students until they decide
+	VersionFilesKept int        `gorm:"default:0" json:"version_files_kept"` // files of this many latest proposal versions stay on disk; 0 keeps all
+	AbandonAfterDays int        `gorm:"default:0" json:"abandon_after_days"` // proposals awaiting revision without team activity this long are abandoned; 0 never
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
 	DeletedAt        *time.Time `gorm:"index" json:"-"`
@@ -27,6 +32,9 @@ type Department struct {
 	UpdatedAt    time.Time  `json:"updated_at"`
 	DeletedAt    *time.Time `gorm:"index" json:"-"`
 	University   University `gorm:"foreignKey:UniversityID"`
+
+	ShowcaseEnabled  bool  `gorm:"default:false" json:"showcase_enabled"` // the department opted in to the public project showcase
+	AICheckerEnabled *bool `json:"ai_checker_enabled"`                    // overrides the university's flag; null inherits it
 }
 
 type User struct {
@@ -44,6 +52,12 @@ type User struct {
 	FailedLoginAttempts int        `gorm:"default:0" json:"-"`
 	AccountLockedUntil  *time.Time `json:"-"`
 	LastLoginAt         *time.Time `json:"last_login_at"`
+	PresenceHidden      bool       `gorm:"default:false" json:"presence_hidden"`     // hides online status from teams
+	AdvisorAutoAccept   bool       `gorm:"default:false" json:"advisor_auto_accept"` // team assignments are accepted without a response
+	AdvisorNoConflictAt *time.Time `json:"advisor_no_conflict_at,omitempty"`         // confirmed having no conflicts of interest when turning auto-accept on
+	AnonymizedAt        *time.Time `json:"anonymized_at,omitempty"`                  // personal data was erased; the account cannot be reactivated
+	AccessExpiresAt     *time.Time `json:"access_expires_at,omitempty"`              // guest accounts (external examiners) cannot sign in after this
+	Affiliation         string     `gorm:"type:varchar(200)" json:"affiliation,omitempty"` // external examiner's home institution
 	CreatedAt           time.Time  `json:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at"`
 	DeletedAt           *time.Time `gorm:"index" json:"-"`
@@ -52,87 +66,179 @@ type User struct {
 }
 
 type Team struct {
-	ID           uint       `gorm:"primaryKey" json:"id"`
-	Name         string     `gorm:"not null" json:"name"`
-	DepartmentID uint       `json:"department_id"`
-	CreatedBy    uint       `json:"created_by"`
-	AdvisorID    *uint      `json:"advisor_id"` 
-	IsFinalized  bool       `gorm:"default:false" json:"is_finalized"`
-	CreatedAt    time.Time  `json:"created_at"`
-	
-	Department   *Department   `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
-	Creator      *User         `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
-	Advisor      *User         `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
+	ID                     uint       `gorm:"primaryKey" json:"id"`
+	Name                   string     `gorm:"not null" json:"name"`
+	DepartmentID           uint       `json:"department_id"`
+	CreatedBy              uint       `json:"created_by"`
+	AdvisorID              *uint      `json:"advisor_id"`
+	IsFinalized            bool       `gorm:"default:false" json:"is_finalized"`
+	AcademicYear           string     `gorm:"type:varchar(50);index" json:"academic_year"` // Cohort the team was formed in; a student has one active team per cohort
+	IsArchived             bool       `gorm:"default:false;index" json:"is_archived"`      // Set when the cohort is archived; memberships are kept as history
+	ArchivedAt             *time.Time `json:"archived_at,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	LeaderAbsentNotifiedAt *time.Time `json:"-"` // members were told the leader is absent; cleared when that ends
 
-	Members      []TeamMember `gorm:"foreignKey:TeamID" json:"members"`
-	Proposals    []Proposal   `gorm:"foreignKey:TeamID" json:"proposals"`
+	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Creator    *User       `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	Advisor    *User       `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
+
+	Members   []TeamMember `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE" json:"members"`
+	Proposals []Proposal   `gorm:"foreignKey:TeamID;constraint:OnDelete:SET NULL" json:"proposals"`
 }
 
 type TeamMember struct {
 	TeamID           uint                   `gorm:"primaryKey" json:"team_id"`
 	UserID           uint                   `gorm:"primaryKey" json:"user_id"`
 	Role             string                 `gorm:"type:varchar(20);default:'member'" json:"role"` // 'leader', 'member'
-	InvitationStatus enums.InvitationStatus `gorm:"type:varchar(20);default:'pending'" json:"invitation_status"`
+	InvitationStatus enums.InvitationStatus `gorm:"type:varchar(20);default:'accepted'" json:"invitation_status"` // always accepted; pending invites live in team_invitations
+	FunctionalRole   enums.FunctionalRole   `gorm:"type:varchar(20)" json:"functional_role,omitempty"` // frontend, backend, ml, documentation
+	Skills           []string               `gorm:"type:text;serializer:json" json:"skills"`
 	
 	// Preload User details for UI
 	User User `gorm:"foreignKey:UserID" json:"user"`
 }
 
+// TeamInvitation is an invite to join a team. Accepting it creates the TeamMember row.
+type TeamInvitation struct {
+	ID          uint                   `gorm:"primaryKey" json:"id"`
+	TeamID      uint                   `gorm:"index;not null" json:"team_id"`
+	InviteeID   uint                   `gorm:"index;not null" json:"invitee_id"`
+	InviterID   uint                   `gorm:"not null" json:"inviter_id"`
+	Status      enums.InvitationStatus `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	Message     string                 `gorm:"type:text" json:"message"`
+	ExpiresAt   *time.Time             `gorm:"index" json:"expires_at"`
+	RespondedAt *time.Time             `json:"responded_at"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+
+	Team    *Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Invitee *User `gorm:"foreignKey:InviteeID" json:"invitee,omitempty"`
+	Inviter *User `gorm:"foreignKey:InviterID" json:"inviter,omitempty"`
+}
+
 type Proposal struct {
 	ID               uint                 `gorm:"primaryKey" json:"id"`
-	TeamID           *uint                `json:"team_id"` // ⚠️ Changed to pointer to allow NULL
+	TeamID           *uint                `gorm:"index:idx_proposal_active_team,unique,where:team_id IS NOT NULL AND is_archived = false AND status <> 'rejected'" json:"team_id"` // ⚠️ Changed to pointer to allow NULL; a team has one active proposal
 	AdvisorID        *uint                `json:"advisor_id"`
 	Status           enums.ProposalStatus `gorm:"type:varchar(30);default:'draft'" json:"status"`
 	CreatedBy         uint   			  `json:"created_by"` // 👈 Add this
+	AcademicYear     string               `gorm:"type:varchar(50);index" json:"academic_year"` // University academic year at creation, e.g. 2025/2026
+	IsArchived       bool                 `gorm:"default:false;index" json:"is_archived"`
+	ArchivedAt       *time.Time           `json:"archived_at,omitempty"`
+	AbandonWarnings  int                  `gorm:"default:0" json:"-"` // abandonment warnings sent since AbandonWarnedAt's activity
+	AbandonWarnedAt  *time.Time           `json:"-"`
+	AbandonedAt      *time.Time           `json:"abandoned_at,omitempty"`
+	ReopenedAt       *time.Time           `json:"reopened_at,omitempty"` // last admin reopening after abandonment
+	ArchiveCheckedAt *time.Time           `json:"archive_checked_at,omitempty"` // last comparison with the department's archived projects
+	ContinuesProjectID *uint              `gorm:"index" json:"continues_project_id,omitempty"` // project of an earlier cohort this proposal carries on
+	AdvisorAssignedAt  *time.Time         `json:"advisor_assigned_at,omitempty"`
+	AdvisorAcceptBy    *time.Time         `json:"advisor_accept_by,omitempty"` // the assigned advisor answers by then, or the department head is asked
+	AdvisorAcceptedAt  *time.Time         `json:"advisor_accepted_at,omitempty"`
+	AdvisorEscalatedAt *time.Time         `json:"advisor_escalated_at,omitempty"` // the department head was told the advisor did not answer
+	SecondReviewerID   *uint              `gorm:"index" json:"second_reviewer_id,omitempty"` // co-reviewer whose approval is also required
+	SecondReviewerAssignedAt *time.Time   `json:"second_reviewer_assigned_at,omitempty"`
 	
 	// Relationships
 	Team             *Team                `gorm:"foreignKey:TeamID" json:"team,omitempty"`
-	Versions         []ProposalVersion    `gorm:"foreignKey:ProposalID" json:"versions"`
+	Versions         []ProposalVersion    `gorm:"foreignKey:ProposalID;constraint:OnDelete:CASCADE" json:"versions"`
 	CreatedAt        time.Time            `json:"created_at"`
 	UpdatedAt        time.Time            `json:"updated_at"`
 	Advisor          *User                `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
+	SecondReviewer   *User                `gorm:"foreignKey:SecondReviewerID" json:"second_reviewer,omitempty"`
 
 }
 
 // Ensure ProposalVersion matches your DBML
 type ProposalVersion struct {
 	ID               uint      `gorm:"primaryKey" json:"id"`
-	ProposalID       uint      `json:"proposal_id"`
+	ProposalID       uint      `gorm:"uniqueIndex:idx_proposal_version_number" json:"proposal_id"`
 	Title            string    `json:"title"`
 	Abstract         string    `json:"abstract"`
 	ProblemStatement string    `json:"problem_statement"`
 	Objectives       string    `json:"objectives"`
 	Methodology      string    `json:"methodology"`
 	ExpectedTimeline string    `json:"expected_timeline"`
-	VersionNumber    int       `json:"version_number"`
+	VersionNumber    int       `gorm:"uniqueIndex:idx_proposal_version_number" json:"version_number"`
 	ExpectedOutcomes string    `json:"expected_outcomes"`
 	FileURL 		*string    `json:"file_url"` //nullable
 	IsApproved       bool      `gorm:"default:false" json:"is_approved"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
+	LastSavedAt      *time.Time `json:"last_saved_at,omitempty"` // last edit of a draft, by autosave or PUT
 	FileHash      string       `gorm:"type:varchar(64)" json:"file_hash"` // Removed "not null"
     FileSizeBytes int64        `json:"file_size_bytes"`   
 	CreatedBy        uint      `json:"created_by"`
+	FileMetadata     `gorm:"embedded"`
+	ColdStorage      `gorm:"embedded"`
     
     // Optional: Relationship
     Creator          User      `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
 }
 
+// TimelinePhase is one phase of a proposal's structured timeline, rendered as a Gantt bar
+type TimelinePhase struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ProposalID   uint      `gorm:"index;not null" json:"proposal_id"`
+	Name         string    `gorm:"type:varchar(150);not null" json:"name"`
+	Description  string    `gorm:"type:text" json:"description"`
+	StartDate    time.Time `gorm:"type:date;not null" json:"start_date"`
+	EndDate      time.Time `gorm:"type:date;not null" json:"end_date"`
+	Deliverables []string  `gorm:"type:text;serializer:json" json:"deliverables"`
+	DependsOnID  *uint     `json:"depends_on_id,omitempty"` // phase that must finish before this one starts
+	Position     int       `gorm:"default:0" json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ProposalArchiveMatch is an archived project of the department found similar to a proposal when it
+// was last submitted; the advisor sees them during review. Title and academic year are copied from
+// the project when matched.
+type ProposalArchiveMatch struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	ProposalID        uint      `gorm:"index;not null" json:"proposal_id"`
+	ProposalVersionID uint      `json:"proposal_version_id"` // the submitted version that was compared
+	ProjectID         uint      `gorm:"not null" json:"project_id"`
+	Title             string    `gorm:"type:varchar(255)" json:"title"`
+	AcademicYear      string    `gorm:"type:varchar(50)" json:"academic_year"`
+	Score             float64   `json:"score"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
 type Feedback struct {
-	ID                uint             `gorm:"primaryKey" json:"id"`
-	ProposalID        uint             `gorm:"index" json:"proposal_id"`
-	ProposalVersionID uint             `gorm:"index" json:"proposal_version_id"`
-	ReviewerID        uint             `gorm:"index" json:"reviewer_id"`
-	Decision          FeedbackDecision `gorm:"type:varchar(20);not null" json:"decision"`
-	Comment           string           `gorm:"type:text;not null" json:"comment"`
-	IsStructured      bool             `gorm:"default:false" json:"is_structured"`
-	IPAddress         *string          `gorm:"type:inet" json:"-"`
-	UserAgent         *string          `gorm:"type:text" json:"-"`
-	SessionID         *string          `gorm:"type:varchar(255)" json:"-"`
-	CreatedAt         time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	Proposal          Proposal         `gorm:"foreignKey:ProposalID"`
-	Version           ProposalVersion  `gorm:"foreignKey:ProposalVersionID"`
-	Reviewer          User             `gorm:"foreignKey:ReviewerID"`
+	ID                uint                 `gorm:"primaryKey" json:"id"`
+	ProposalID        uint                 `gorm:"index" json:"proposal_id"`
+	ProposalVersionID uint                 `gorm:"index" json:"proposal_version_id"`
+	ReviewerID        uint                 `gorm:"index" json:"reviewer_id"`
+	Decision          FeedbackDecision     `gorm:"type:varchar(20);not null" json:"decision"`
+	Comment           string               `gorm:"type:text;not null" json:"comment"`
+	IsStructured      bool                 `gorm:"default:false" json:"is_structured"`
+	Checklist         []ChecklistEntry     `gorm:"type:text;serializer:json" json:"checklist"` // review checklist as completed by the advisor
+	ResubmitBy        *time.Time           `gorm:"index" json:"resubmit_by,omitempty"`         // revision deadline set by the advisor
+	DeadlineReminders int                  `gorm:"default:0" json:"-"`                         // deadline reminders already sent to the team
+	DeadlineMissedAt  *time.Time           `json:"deadline_missed_at,omitempty"`               // set when the deadline passed without a resubmission
+	TimeSpentMinutes  *int                 `json:"time_spent_minutes,omitempty"`               // time the advisor reports spending on the review
+	WordCount         int                  `gorm:"default:0" json:"word_count"`                // words the advisor wrote, computed on save; saved templates are not counted
+	IPAddress         *string              `gorm:"type:inet" json:"-"`
+	UserAgent         *string              `gorm:"type:text" json:"-"`
+	SessionID         *string              `gorm:"type:varchar(255)" json:"-"`
+	CreatedAt         time.Time            `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Attachments       []FeedbackAttachment `gorm:"foreignKey:FeedbackID;constraint:OnDelete:CASCADE" json:"attachments"` // annotated PDFs or images
+	Proposal          Proposal             `gorm:"foreignKey:ProposalID"`
+	Version           ProposalVersion      `gorm:"foreignKey:ProposalVersionID"`
+	Reviewer          User                 `gorm:"foreignKey:ReviewerID"`
+}
+
+// FeedbackAttachment is an annotated PDF or image an advisor attached to feedback. It is stored
+// outside the public uploads directory and only downloaded through DownloadURL.
+type FeedbackAttachment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	FeedbackID    uint      `gorm:"index;not null" json:"feedback_id"`
+	FileName      string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	Path          string    `gorm:"type:varchar(500);not null" json:"-"`
+	FileSizeBytes int64     `json:"file_size_bytes"`
+	CreatedAt     time.Time `json:"created_at"`
+	FileMetadata  `gorm:"embedded"`
+	DownloadURL   string `gorm:"-" json:"download_url"` // access-controlled download endpoint
 }
 
 type FeedbackDecision string
@@ -143,6 +249,66 @@ const (
 	FeedbackDecisionReject  FeedbackDecision = "reject"
 )
 
+// ReviewChecklistItem is a check an advisor confirms before deciding on a proposal.
+// Rows without a DepartmentID are the global checklist; a department's own rows replace it.
+type ReviewChecklistItem struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	DepartmentID *uint     `gorm:"uniqueIndex:idx_checklist_item_scope" json:"department_id,omitempty"`
+	Key          string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_checklist_item_scope" json:"key"`
+	Label        string    `gorm:"type:varchar(150);not null" json:"label"`
+	Mandatory    bool      `gorm:"default:true" json:"mandatory"` // must be checked to approve
+	Position     int       `gorm:"default:0" json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ChecklistEntry is one checklist item as answered on a feedback
+type ChecklistEntry struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Mandatory bool   `json:"mandatory"`
+	Checked   bool   `json:"checked"`
+}
+
+// SecondOpinion is an advisor asking another teacher of the department to look at one version
+// of a proposal. The invited reviewer can read the proposal until they answer; their comment
+// is shown in the proposal's feedback thread.
+type SecondOpinion struct {
+	ID                uint                `gorm:"primaryKey" json:"id"`
+	ProposalID        uint                `gorm:"index;not null" json:"proposal_id"`
+	ProposalVersionID uint                `gorm:"index;not null" json:"proposal_version_id"`
+	RequestedBy       uint                `gorm:"not null" json:"requested_by"`
+	ReviewerID        uint                `gorm:"index;not null" json:"reviewer_id"`
+	Message           string              `gorm:"type:text" json:"message,omitempty"`
+	Status            SecondOpinionStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Comment           string              `gorm:"type:text" json:"comment,omitempty"`
+	RespondedAt       *time.Time          `json:"responded_at,omitempty"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+	Requester         *User               `gorm:"foreignKey:RequestedBy" json:"requester,omitempty"`
+	Reviewer          *User               `gorm:"foreignKey:ReviewerID" json:"reviewer,omitempty"`
+}
+
+type SecondOpinionStatus string
+
+const (
+	SecondOpinionPending  SecondOpinionStatus = "pending"
+	SecondOpinionAnswered SecondOpinionStatus = "answered"
+	SecondOpinionDeclined SecondOpinionStatus = "declined"
+)
+
+// FeedbackTemplate is an advisor's reusable comment snippet, e.g. "scope too broad"
+type FeedbackTemplate struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	AdvisorID  uint      `gorm:"index;not null" json:"advisor_id"`
+	Title      string    `gorm:"type:varchar(100);not null" json:"title"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	Category   string    `gorm:"type:varchar(50)" json:"category,omitempty"`
+	UsageCount int       `gorm:"default:0" json:"usage_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 type Project struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
 	ProposalID   uint      `gorm:"uniqueIndex" json:"proposal_id"`
@@ -152,38 +318,120 @@ type Project struct {
 	DepartmentID uint      `json:"department_id"`
 	Visibility   string    `gorm:"type:varchar(20);default:'private'" json:"visibility"`
 	ShareCount   int       `gorm:"default:0" json:"share_count"`
+	Slug         *string   `gorm:"type:varchar(40);uniqueIndex" json:"slug,omitempty"` // permanent public identifier, e.g. ASTU-2025-0042
+	PublishedAt  *time.Time `gorm:"index" json:"published_at,omitempty"` // first time the project was made public
 	CreatedAt    time.Time `json:"created_at"`
 	ViewCount    int       `gorm:"default:0" json:"view_count"` // 👈 ADD THIS
+	IsArchived   bool       `gorm:"default:false;index" json:"is_archived"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+
+	Description     string `gorm:"type:text" json:"description"`      // README-style Markdown written by the team
+	DescriptionHTML string `gorm:"type:text" json:"description_html"` // sanitized rendering of Description, refreshed on save
+
+	PreviousProjectID *uint `gorm:"index" json:"previous_project_id,omitempty"` // project of an earlier cohort this one continues
+
+	// PublishAt schedules publication; the project goes public at that time once its grade is locked
+	PublishAt          *time.Time `gorm:"index" json:"publish_at,omitempty"`
+	PublishScheduledBy *uint      `json:"publish_scheduled_by,omitempty"`
 
 	// 👇 ADD THESE RELATIONSHIPS
 	Proposal   Proposal   `gorm:"foreignKey:ProposalID" json:"proposal"`
 	Team       Team       `gorm:"foreignKey:TeamID" json:"team"`
 	Department Department `gorm:"foreignKey:DepartmentID" json:"department"`
 	Approver   User       `gorm:"foreignKey:ApprovedBy" json:"approver"`
+
+	TaskProgress *TaskProgress          `gorm:"-" json:"task_progress,omitempty"` // the team's checklist, on the project dashboard
+	Links        []ProjectDocumentation `gorm:"-" json:"links,omitempty"`         // code and deployed links with their check status
+	Lineage      []ProjectLineageEntry  `gorm:"-" json:"lineage,omitempty"`       // earlier and later cohorts of a continued project
 	
 }
 
+// ProjectShareLink is a traceable share of a public project; visits through /p/{slug}?s={code} are counted
+type ProjectShareLink struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProjectID uint      `gorm:"index;not null" json:"project_id"`
+	Code      string    `gorm:"type:varchar(16);uniqueIndex;not null" json:"code"`
+	Channel   string    `gorm:"type:varchar(30)" json:"channel,omitempty"` // e.g. email, linkedin, copy
+	SharedBy  *uint     `json:"shared_by,omitempty"`
+	Visits    int       `gorm:"default:0" json:"visits"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProjectShare records each share request of a public project. Repeated shares from the same
+// address within the dedup window and shares from crawlers are kept but not counted.
+type ProjectShare struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ProjectID   uint      `gorm:"index:idx_project_share_dedup,priority:1;not null" json:"project_id"`
+	ShareLinkID *uint     `json:"share_link_id,omitempty"`
+	Channel     string    `gorm:"type:varchar(20);not null" json:"channel"`                           // link, email, twitter, linkedin, ...
+	IPHash      string    `gorm:"type:varchar(64);index:idx_project_share_dedup,priority:2" json:"-"` // keyed hash, the address itself is not stored
+	UserID      *uint     `json:"user_id,omitempty"`
+	Counted     bool      `gorm:"default:false" json:"counted"`
+	CreatedAt   time.Time `gorm:"index:idx_project_share_dedup,priority:3" json:"created_at"`
+}
+
 type ProjectDocumentation struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
 	ProjectID     uint      `json:"project_id"`
 	DocumentType  string    `gorm:"type:varchar(30)" json:"document_type"`
 	URL           string    `gorm:"column:url" json:"url"` 
+	FileSizeBytes int64     `gorm:"default:0" json:"file_size_bytes"` // 0 for links
+	FileHash      string    `gorm:"type:varchar(64)" json:"file_hash,omitempty"` // content hash of uploads, the download ETag
 	Status        string    `gorm:"type:varchar(20);default:'pending'" json:"status"`
 	ReviewComment string    `json:"review_comment"`
 	ReviewedBy    uint      `json:"reviewed_by"`
 	ReviewedAt    time.Time `json:"reviewed_at"`
 	SubmittedBy   uint      `json:"submitted_by"`
 	SubmittedAt   time.Time `json:"submitted_at"`
+	CarriedOverFromID *uint `json:"carried_over_from_id,omitempty"` // document of the previous project this copy was made from
+	FileMetadata  `gorm:"embedded"` // empty for links
+	LinkCheck     `gorm:"embedded"` // empty for files
+}
+
+// LinkCheck is the outcome of the latest reachability check of a code or deployed link
+type LinkCheck struct {
+	LinkStatus     enums.LinkStatus `gorm:"type:varchar(20);index" json:"link_status,omitempty"`
+	LinkHTTPStatus int              `json:"link_http_status,omitempty"`
+	LinkTitle      string           `gorm:"type:varchar(300)" json:"link_title,omitempty"` // the page's <title>, when it has one
+	LinkError      string           `gorm:"type:varchar(300)" json:"link_error,omitempty"` // why the last check failed
+	LinkFailures   int              `gorm:"default:0" json:"-"`                            // consecutive failed checks
+	LinkCheckedAt  *time.Time       `json:"link_checked_at,omitempty"`
+}
+
+// FileMetadata describes an uploaded file so clients can show it before downloading
+type FileMetadata struct {
+	DeclaredContentType string           `gorm:"type:varchar(100)" json:"declared_content_type,omitempty"`              // as sent by the uploader
+	DetectedMIME        string           `gorm:"column:detected_mime;type:varchar(100)" json:"detected_mime,omitempty"` // sniffed from the content
+	PageCount           *int             `json:"page_count,omitempty"`                                                  // PDFs only
+	ScanStatus          enums.ScanStatus `gorm:"type:varchar(20)" json:"scan_status,omitempty"`                         // quarantined until clean, see ScanStatus.Quarantined
 }
 
 type ProjectReview struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	ProjectID uint      `json:"project_id"`
-	UserID    uint      `json:"user_id"`
+	ProjectID uint      `gorm:"uniqueIndex:idx_project_review_user" json:"project_id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_project_review_user" json:"user_id"` // one review per user and project
 	Rate      int       `json:"rate"`
 	Comment   string    `json:"comment"`
 	CreatedAt time.Time `json:"created_at"`
 	User      User      `gorm:"foreignKey:UserID" json:"user"`
+
+	// Reviews whose comment matches a banned word of the project's department stay hidden until an admin approves them
+	ModerationStatus enums.ReviewModerationStatus `gorm:"type:varchar(20);not null;default:'approved';index" json:"moderation_status"`
+	FlaggedTerms     string                       `gorm:"type:text" json:"flagged_terms,omitempty"` // the matched words, comma separated
+	ModeratedBy      *uint                        `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time                   `json:"moderated_at,omitempty"`
+	ModerationNote   string                       `gorm:"type:text" json:"moderation_note,omitempty"`
+
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
+
+// ReviewFilter is a banned word or phrase for the public reviews of a department's projects
+type ReviewFilter struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	DepartmentID uint      `gorm:"not null;uniqueIndex:idx_review_filter_term" json:"department_id"`
+	Term         string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_review_filter_term" json:"term"` // stored lowercase
+	CreatedBy    uint      `gorm:"not null" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Notification struct {
@@ -197,8 +445,55 @@ type Notification struct {
 	IsRead        bool       `gorm:"default:false;index" json:"is_read"`
 	ReadAt        *time.Time `json:"read_at"`
 	Priority      string     `gorm:"type:varchar(20);default:'normal'" json:"priority"`
+	GroupKey      string     `gorm:"type:varchar(320);index" json:"group_key"` // repeats of the same kind of notice about the same thing share a thread
 	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	User          User       `gorm:"foreignKey:UserID"`
+	User          User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// AIJob is a queued AI proposal analysis processed by a background worker
+type AIJob struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	RequestedBy uint              `gorm:"index;not null" json:"requested_by"`
+	ProposalID  *uint             `gorm:"index" json:"proposal_id,omitempty"`
+	Title       string            `gorm:"type:text" json:"title"`
+	Objectives  string            `gorm:"type:text" json:"objectives"`
+	Methodology string            `gorm:"type:text" json:"methodology,omitempty"`
+	Status      enums.AIJobStatus `gorm:"type:varchar(20);default:'queued';index" json:"status"`
+	Progress    int               `gorm:"default:0" json:"progress"` // 0-100
+	Result      string            `gorm:"type:text" json:"-"`        // raw JSON from the AI service
+	Error       string            `gorm:"type:text" json:"error,omitempty"`
+	Attempts    int               `gorm:"default:0" json:"attempts"`
+	CreatedAt   time.Time         `json:"created_at"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// Delegation lends a department admin's approval rights to a teacher for a date range
+type Delegation struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	DelegatorID  uint       `gorm:"index;not null" json:"delegator_id"`
+	DelegateID   uint       `gorm:"index;not null" json:"delegate_id"`
+	DepartmentID uint       `gorm:"index;not null" json:"department_id"`
+	StartsAt     time.Time  `gorm:"not null" json:"starts_at"`
+	EndsAt       time.Time  `gorm:"not null" json:"ends_at"`
+	Reason       string     `gorm:"type:text" json:"reason"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Delegator *User `gorm:"foreignKey:DelegatorID" json:"delegator,omitempty"`
+	Delegate  *User `gorm:"foreignKey:DelegateID" json:"delegate,omitempty"`
+}
+
+// RolePermission grants (or, as a department override, denies) a permission to a role.
+// Rows without a DepartmentID are the global grants.
+type RolePermission struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Role         enums.Role `gorm:"type:varchar(20);not null;uniqueIndex:idx_role_permission_scope" json:"role"`
+	Permission   string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_role_permission_scope" json:"permission"`
+	DepartmentID *uint      `gorm:"uniqueIndex:idx_role_permission_scope" json:"department_id,omitempty"`
+	Allowed      bool       `gorm:"not null;default:true" json:"allowed"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
 // AuditLog represents system-wide audit trail (immutable)
@@ -210,6 +505,10 @@ type AuditLog struct {
 	ActorID    *uint     `gorm:"index" json:"actor_id"`
 	ActorRole  string    `gorm:"type:varchar(20)" json:"actor_role"`
 	ActorEmail string    `gorm:"type:varchar(255)" json:"actor_email"`
+	// ImpersonatorID is the admin who performed the action on behalf of ActorID
+	ImpersonatorID *uint     `gorm:"index" json:"impersonator_id,omitempty"`
+	// DelegatorID is the department admin whose delegated rights ActorID used
+	DelegatorID    *uint     `gorm:"index" json:"delegator_id,omitempty"`
 	OldState   string    `gorm:"type:jsonb" json:"old_state"`
 	NewState   string    `gorm:"type:jsonb" json:"new_state"`
 	Changes    string   `gorm:"type:text" json:"changes"` 
@@ -221,3 +520,432 @@ type AuditLog struct {
 	Metadata   string    `gorm:"type:text" json:"metadata"`
 	Actor      *User     `gorm:"foreignKey:ActorID"`
 }
+
+// DashboardStat is the materialized admin dashboard for a department. Domain events mark it
+// dirty and a periodic job recomputes dirty or stale rows, so reads are a single lookup.
+type DashboardStat struct {
+	DepartmentID      uint      `gorm:"primaryKey;autoIncrement:false" json:"department_id"`
+	PendingCount      int64     `json:"pending_assignment"`
+	UnderReviewCount  int64     `json:"under_review"`
+	ApprovedCount     int64     `json:"approved"`
+	TotalTeams        int64     `json:"total_teams"`
+	AvailableAdvisors int64     `json:"available_advisors"`
+	MissedDeadlines   int64     `json:"missed_revision_deadlines"`
+	Payload           string    `gorm:"type:jsonb" json:"-"` // recent proposals, advisor workload and missed deadlines
+	Dirty             bool      `gorm:"default:false;index" json:"dirty"`
+	RefreshedAt       time.Time `gorm:"index" json:"refreshed_at"`
+}
+
+// DepartmentQuota caps a department's supervision load per cohort (academic year). A limit of 0 is unlimited.
+type DepartmentQuota struct {
+	DepartmentID         uint      `gorm:"primaryKey;autoIncrement:false" json:"department_id"`
+	AdvisorProposalLimit int       `gorm:"default:0" json:"advisor_proposal_limit"` // proposals one advisor may take per cohort
+	TeamLimit            int       `gorm:"default:0" json:"team_limit"`             // teams the department may supervise per cohort
+	UpdatedBy            *uint     `json:"updated_by,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// AdvisorWorkloadSnapshot is an advisor's workload as captured once a week, kept so department heads
+// can follow trends across cohorts. WeekStart is the Monday (UTC) of the captured week.
+type AdvisorWorkloadSnapshot struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	AdvisorID       uint      `gorm:"uniqueIndex:idx_advisor_workload_week;not null" json:"advisor_id"`
+	WeekStart       time.Time `gorm:"type:date;uniqueIndex:idx_advisor_workload_week;not null" json:"week_start"`
+	DepartmentID    uint      `gorm:"index" json:"department_id"`
+	AcademicYear    string    `gorm:"type:varchar(50)" json:"academic_year"`
+	Proposals       int64     `json:"proposals"`        // cohort proposals assigned, as counted against capacity
+	ActiveProposals int64     `json:"active_proposals"` // unarchived proposals still in review or revision
+	PendingReviews  int64     `json:"pending_reviews"`  // unarchived proposals waiting for the advisor's decision
+	Teams           int64     `json:"teams"`            // unarchived teams supervised
+	ReviewsLastWeek int64     `json:"reviews_last_week"`
+	Capacity        int       `json:"capacity"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// UserSession is a login. Access tokens carry the session ID, so revoking the session
+// rejects its tokens before they expire.
+type UserSession struct {
+	ID         string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	IPAddress  string     `gorm:"type:varchar(64)" json:"ip_address"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+}
+
+// RevokedToken is a denylist entry rejecting access tokens before they expire. With a JTI it
+// rejects that one token; without, every token of the user issued up to IssuedBefore. Entries are
+// kept until ExpiresAt, after which the tokens they cover have expired anyway.
+type RevokedToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	JTI          string     `gorm:"type:varchar(36);index" json:"jti,omitempty"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	IssuedBefore *time.Time `json:"issued_before,omitempty"`
+	Reason       string     `gorm:"type:varchar(30);not null" json:"reason"` // password_changed, email_changed, deactivated, admin, impersonation_ended
+	RevokedBy    *uint      `json:"revoked_by,omitempty"`
+	ExpiresAt    time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// EmailChange is a user's request to move their account to another address. The old address stays
+// in use until the link sent to the new one is followed; only the SHA-256 of the link's token is kept.
+type EmailChange struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	OldEmail    string     `gorm:"type:varchar(255);not null" json:"old_email"`
+	NewEmail    string     `gorm:"type:varchar(255);not null" json:"new_email"`
+	TokenHash   string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// FailedJob is a background job that gave up, kept so operators can retry it once the cause is fixed.
+// Kind says which subsystem runs it; Reference identifies what it was about, e.g. "ai_job:12".
+type FailedJob struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Kind          string     `gorm:"type:varchar(30);not null;uniqueIndex:idx_failed_job_ref" json:"kind"`
+	Reference     string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_failed_job_ref" json:"reference"`
+	Payload       string     `gorm:"type:jsonb" json:"payload"`
+	Error         string     `gorm:"type:text" json:"error"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	Retries       int        `gorm:"default:0" json:"retries"` // manual retries by operators
+	FailedAt      time.Time  `gorm:"index" json:"failed_at"`
+	LastRetriedAt *time.Time `json:"last_retried_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// MaintenanceState is the maintenance flag switched by admins, a single row shared by every instance.
+// While enabled, writes are refused with Message except from system admins.
+type MaintenanceState struct {
+	ID        uint       `gorm:"primaryKey" json:"-"`
+	Enabled   bool       `gorm:"default:false" json:"enabled"`
+	Message   string     `gorm:"type:text" json:"message,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"` // expected end, sent to clients as Retry-After
+	UpdatedBy *uint      `json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Setting is an admin's value for one of the typed settings defined in pkg/settings. The row with
+// UniversityID 0 holds the system-wide value; other rows override it for one university.
+type Setting struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	Key          string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_setting_key_university" json:"key"`
+	UniversityID uint      `gorm:"not null;default:0;uniqueIndex:idx_setting_key_university" json:"university_id"`
+	Value        string    `gorm:"type:text;not null" json:"value"`
+	UpdatedBy    *uint     `json:"updated_by,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// GradingCriterion is one line of the rubric projects are graded against; Weight is its share of the grade in percent.
+// Rows without a DepartmentID are the global rubric; a department's own rows replace it.
+type GradingCriterion struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	DepartmentID *uint     `gorm:"uniqueIndex:idx_grading_criterion_scope" json:"department_id,omitempty"`
+	Key          string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_grading_criterion_scope" json:"key"`
+	Label        string    `gorm:"type:varchar(150);not null" json:"label"`
+	Weight       int       `gorm:"not null" json:"weight"`
+	Position     int       `gorm:"default:0" json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ProjectExaminer is a teacher on a project's defense committee; examiners grade the project alongside the advisor
+type ProjectExaminer struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProjectID  uint      `gorm:"not null;uniqueIndex:idx_project_examiner" json:"project_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_project_examiner;index" json:"user_id"`
+	AssignedBy uint      `json:"assigned_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	User       *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// GradeSheet is one grader's scores for a project. Graders can revise it until the grade is locked.
+type GradeSheet struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	ProjectID   uint             `gorm:"not null;uniqueIndex:idx_grade_sheet" json:"project_id"`
+	GraderID    uint             `gorm:"not null;uniqueIndex:idx_grade_sheet" json:"grader_id"`
+	GraderRole  GraderRole       `gorm:"type:varchar(20);not null" json:"grader_role"`
+	Scores      []CriterionScore `gorm:"type:text;serializer:json" json:"scores"`
+	Total       float64          `json:"total"` // weighted, out of 100
+	Comment     string           `gorm:"type:text" json:"comment,omitempty"`
+	SubmittedAt time.Time        `json:"submitted_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Grader      *User            `gorm:"foreignKey:GraderID" json:"-"`
+}
+
+type GraderRole string
+
+const (
+	GraderAdvisor  GraderRole = "advisor"
+	GraderExaminer GraderRole = "examiner"
+)
+
+// CriterionScore is the score out of 100 given for one rubric criterion
+type CriterionScore struct {
+	Key    string  `json:"key"`
+	Label  string  `json:"label"`
+	Weight int     `json:"weight"`
+	Score  float64 `json:"score"`
+}
+
+// ProjectGrade is the final grade of a project, written when the department head locks it
+type ProjectGrade struct {
+	ProjectID     uint      `gorm:"primaryKey;autoIncrement:false" json:"project_id"`
+	AdvisorScore  float64   `json:"advisor_score"`
+	ExaminerScore *float64  `json:"examiner_score,omitempty"` // average of the examiners; nil when there were none
+	FinalScore    float64   `json:"final_score"`
+	LetterGrade   string    `gorm:"type:varchar(3)" json:"letter_grade"`
+	LockedBy      uint      `json:"locked_by"`
+	LockedAt      time.Time `json:"locked_at"`
+}
+
+// ProposalRules are a department's constraints on proposal versions, checked before a proposal
+// can be submitted. Departments without a row use the default rules.
+type ProposalRules struct {
+	DepartmentID     uint          `gorm:"primaryKey;autoIncrement:false" json:"department_id"`
+	Sections         []SectionRule `gorm:"type:text;serializer:json" json:"sections"`
+	RequiredHeadings []string      `gorm:"type:text;serializer:json" json:"required_headings"` // must appear in the uploaded PDF
+	UpdatedBy        *uint         `json:"updated_by,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+// SectionRule bounds the word count of one proposal section; 0 means no bound
+type SectionRule struct {
+	Section  string `json:"section"` // e.g. "abstract", "methodology"
+	MinWords int    `json:"min_words"`
+	MaxWords int    `json:"max_words"`
+}
+
+// SubmissionWindow is the period in which a department's teams submit their proposal for a cohort.
+// Teams that have not submitted are reminded ReminderDays before it closes.
+type SubmissionWindow struct {
+	DepartmentID     uint       `gorm:"primaryKey;autoIncrement:false" json:"department_id"`
+	AcademicYear     string     `gorm:"type:varchar(50)" json:"academic_year"`
+	OpensAt          time.Time  `gorm:"not null" json:"opens_at"`
+	ClosesAt         time.Time  `gorm:"not null;index" json:"closes_at"`
+	ReminderDays     []int      `gorm:"type:text;serializer:json" json:"reminder_days"` // days before closing, e.g. 7, 3, 1
+	RemindersSent    int        `gorm:"default:0" json:"-"`                             // reminders already sent for this window
+	ClosedReportedAt *time.Time `json:"-"`                                              // set once admins got the final report
+	UpdatedBy        *uint      `json:"updated_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Announcement is a notice a department admin posts to the department's students and advisors.
+// It stops being listed after ExpiresAt, when one is set.
+type Announcement struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	DepartmentID uint       `gorm:"index;not null" json:"department_id"`
+	AuthorID     uint       `gorm:"not null" json:"author_id"`
+	Title        string     `gorm:"type:varchar(200);not null" json:"title"`
+	Body         string     `gorm:"type:text;not null" json:"body"`
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Author      *User                    `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Attachments []AnnouncementAttachment `gorm:"foreignKey:AnnouncementID;constraint:OnDelete:CASCADE" json:"attachments"`
+}
+
+// AnnouncementAttachment is a file uploaded with an announcement
+type AnnouncementAttachment struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	AnnouncementID uint      `gorm:"index;not null" json:"announcement_id"`
+	FileName       string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	URL            string    `gorm:"type:varchar(500);not null" json:"url"`
+	FileSizeBytes  int64     `json:"file_size_bytes"`
+	CreatedAt      time.Time `json:"created_at"`
+	FileMetadata   `gorm:"embedded"`
+}
+
+// ColdStorage records that a proposal file was moved out of the upload directory by the university's
+// retention policy. The file keeps its URL; ArchivePath is its compressed copy until it is restored.
+type ColdStorage struct {
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	ArchivePath string     `gorm:"type:varchar(500)" json:"-"`
+	RestoredAt  *time.Time `json:"restored_at,omitempty"` // restored files are kept for a grace period before archiving again
+}
+
+// ProposalVersionFile is an attachment that was replaced on a draft version. The file stays on
+// disk and its reference and hash are kept so the version's history can be audited.
+type ProposalVersionFile struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ProposalID    uint      `gorm:"index;not null" json:"proposal_id"`
+	VersionID     uint      `gorm:"index;not null" json:"version_id"`
+	FileURL       string    `gorm:"type:varchar(500);not null" json:"file_url"`
+	FileHash      string    `gorm:"type:varchar(64)" json:"file_hash"`
+	FileSizeBytes int64     `json:"file_size_bytes"`
+	ReplacedBy    uint      `json:"replaced_by"`
+	ReplacedAt    time.Time `json:"replaced_at"`
+	FileMetadata  `gorm:"embedded"`
+	ColdStorage   `gorm:"embedded"`
+}
+
+// ProposalVersionText is the text extracted from a version's PDF, behind full-text search and
+// AI analysis. FileHash is the file it was read from; a replaced file is extracted again.
+type ProposalVersionText struct {
+	VersionID   uint      `gorm:"primaryKey;autoIncrement:false" json:"version_id"`
+	ProposalID  uint      `gorm:"index;not null" json:"proposal_id"`
+	FileHash    string    `gorm:"type:varchar(64)" json:"file_hash"`
+	Content     string    `gorm:"type:text" json:"content"`
+	WordCount   int       `json:"word_count"`
+	ExtractedAt time.Time `json:"extracted_at"`
+}
+
+// TeamTask is an item on a team's checklist. Members create, assign and complete tasks; the
+// advisor follows them read-only.
+type TeamTask struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TeamID      uint       `gorm:"index;not null" json:"team_id"`
+	Title       string     `gorm:"type:varchar(200);not null" json:"title"`
+	Description string     `gorm:"type:text" json:"description"`
+	AssigneeID  *uint      `gorm:"index" json:"assignee_id"`
+	DueDate     *time.Time `json:"due_date"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CompletedBy *uint      `json:"completed_by,omitempty"`
+	CreatedBy   uint       `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Assignee *User `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
+}
+
+// ProjectLineageEntry is a project of the same line of work in another cohort. Position counts the
+// steps from the project being viewed: negative for the projects it continues, positive for later ones.
+type ProjectLineageEntry struct {
+	ProjectID    uint   `json:"project_id"`
+	Title        string `json:"title"`
+	TeamName     string `json:"team_name"`
+	AcademicYear string `json:"academic_year"`
+	Position     int    `json:"position"`
+}
+
+// TaskProgress summarises a team's checklist; Percent is completed over total, 0 without tasks
+type TaskProgress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Overdue   int `json:"overdue"`
+	Percent   int `json:"percent"`
+}
+
+// ConflictDeclaration is an advisor's statement on whether they have a conflict of interest with
+// a team. A flagged conflict blocks assigning the advisor to the team's proposal until the
+// department head overrides it.
+type ConflictDeclaration struct {
+	ID             uint                 `gorm:"primaryKey" json:"id"`
+	AdvisorID      uint                 `gorm:"uniqueIndex:idx_conflict_advisor_team;not null" json:"advisor_id"`
+	TeamID         uint                 `gorm:"uniqueIndex:idx_conflict_advisor_team;not null" json:"team_id"`
+	DepartmentID   uint                 `gorm:"index;not null" json:"department_id"`
+	HasConflict    bool                 `gorm:"not null" json:"has_conflict"`
+	Nature         enums.ConflictNature `gorm:"type:varchar(20)" json:"nature,omitempty"`
+	Details        string               `gorm:"type:text" json:"details,omitempty"`
+	DeclaredAt     time.Time            `json:"declared_at"`
+	OverriddenBy   *uint                `json:"overridden_by,omitempty"`
+	OverriddenAt   *time.Time           `json:"overridden_at,omitempty"`
+	OverrideReason string               `gorm:"type:text" json:"override_reason,omitempty"`
+
+	Advisor *User `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
+	Team    *Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+}
+
+// DataExport is a copy of everything stored about a user, generated in the background on request.
+// The archive is kept on disk, outside the public uploads, until ExpiresAt.
+type DataExport struct {
+	ID            uint                   `gorm:"primaryKey" json:"id"`
+	UserID        uint                   `gorm:"index;not null" json:"user_id"`
+	Status        enums.DataExportStatus `gorm:"type:varchar(20);not null" json:"status"`
+	FilePath      string                 `gorm:"type:varchar(500)" json:"-"`
+	FileSizeBytes int64                  `json:"file_size_bytes,omitempty"`
+	Error         string                 `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	StartedAt     *time.Time             `json:"started_at,omitempty"`
+	CompletedAt   *time.Time             `json:"completed_at,omitempty"`
+	ExpiresAt     *time.Time             `gorm:"index" json:"expires_at,omitempty"`
+}
+
+// AdvisorRequest is one entry of a team's ranked advisor preferences. The leader ranks up to three
+// advisors, who are asked one at a time in rank order; a decline passes the request on to the next
+// preference. Each list the leader submits is a new round.
+type AdvisorRequest struct {
+	ID           uint                       `gorm:"primaryKey" json:"id"`
+	TeamID       uint                       `gorm:"not null;index:idx_advisor_request_team_round" json:"team_id"`
+	Round        int                        `gorm:"not null;index:idx_advisor_request_team_round" json:"round"`
+	Rank         int                        `gorm:"not null" json:"rank"`
+	AdvisorID    uint                       `gorm:"not null;index" json:"advisor_id"`
+	DepartmentID uint                       `gorm:"not null;index" json:"department_id"`
+	Status       enums.AdvisorRequestStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Comment      string                     `gorm:"type:text" json:"comment,omitempty"` // the advisor's reply, or why the preference was skipped
+	RequestedBy  uint                       `json:"requested_by"`
+	SentAt       *time.Time                 `json:"sent_at,omitempty"` // when the advisor was asked
+	RespondedAt  *time.Time                 `json:"responded_at,omitempty"`
+	CreatedAt    time.Time                  `json:"created_at"`
+
+	Advisor *User `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
+	Team    *Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+}
+
+// DeadlineExtension is a team's request to submit its proposal after the department's submission
+// window closes. Once a department admin approves it, the team can make its first submission until
+// ExtendedUntil; it only applies to the window of the cohort it was requested in.
+type DeadlineExtension struct {
+	ID             uint                          `gorm:"primaryKey" json:"id"`
+	TeamID         uint                          `gorm:"not null;index" json:"team_id"`
+	DepartmentID   uint                          `gorm:"not null;index" json:"department_id"`
+	AcademicYear   string                        `gorm:"type:varchar(50)" json:"academic_year"`
+	RequestedBy    uint                          `gorm:"not null" json:"requested_by"`
+	Justification  string                        `gorm:"type:text;not null" json:"justification"`
+	RequestedUntil time.Time                     `gorm:"not null" json:"requested_until"`
+	ExtendedUntil  *time.Time                    `json:"extended_until,omitempty"` // set on approval; the admin may grant less than requested
+	Status         enums.DeadlineExtensionStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ReviewedBy     *uint                         `json:"reviewed_by,omitempty"`
+	ReviewComment  string                        `gorm:"type:text" json:"review_comment,omitempty"`
+	ReviewedAt     *time.Time                    `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	UpdatedAt      time.Time                     `json:"updated_at"`
+
+	Team      *Team `gorm:"foreignKey:TeamID" json:"team,omitempty"`
+	Requester *User `gorm:"foreignKey:RequestedBy" json:"requester,omitempty"`
+}
+
+// LeadershipVote is a team's election of a new leader, opened by a member while the leader's
+// account is deactivated or the leader has been inactive longer than the university allows
+type LeadershipVote struct {
+	ID        uint                       `gorm:"primaryKey" json:"id"`
+	TeamID    uint                       `gorm:"not null;index;index:idx_leadership_vote_open_team,unique,where:status = 'open'" json:"team_id"`
+	LeaderID  uint                       `gorm:"not null" json:"leader_id"` // the leader being replaced
+	StartedBy uint                       `gorm:"not null" json:"started_by"`
+	Status    enums.LeadershipVoteStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ElectedID *uint                      `json:"elected_id,omitempty"`
+	ExpiresAt time.Time                  `gorm:"not null" json:"expires_at"`
+	ClosedAt  *time.Time                 `json:"closed_at,omitempty"`
+	CreatedAt time.Time                  `json:"created_at"`
+
+	Ballots []LeadershipBallot `gorm:"foreignKey:VoteID;constraint:OnDelete:CASCADE" json:"ballots"`
+}
+
+// LeadershipBallot is a member's vote; members can change it while the vote is open
+type LeadershipBallot struct {
+	VoteID      uint      `gorm:"primaryKey" json:"vote_id"`
+	VoterID     uint      `gorm:"primaryKey" json:"voter_id"`
+	CandidateID uint      `gorm:"not null" json:"candidate_id"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AdvisorMessage is a message the department head sent to the advisors matching its filters
+type AdvisorMessage struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	DepartmentID      uint      `gorm:"not null;index" json:"department_id"`
+	SenderID          uint      `gorm:"not null" json:"sender_id"`
+	Subject           string    `gorm:"type:varchar(200);not null" json:"subject"`
+	Body              string    `gorm:"type:text;not null" json:"body"`
+	Overloaded        bool      `json:"overloaded"`                    // only advisors above capacity in the current cohort
+	PendingReviewDays int       `json:"pending_review_days,omitempty"` // only advisors with a decision pending longer than this
+	Email             bool      `json:"email"`                         // also sent by email
+	RecipientIDs      []uint    `gorm:"type:text;serializer:json" json:"recipient_ids"`
+	CreatedAt         time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,189 @@
+package examiners
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/audit"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// InviteExaminer godoc
+// @Summary Invite an external examiner
+// @Description Creates a guest account for a defense committee member from outside the university, in the admin's department. The account can sign in until expires_at (at most 180 days ahead) and only opens the projects it examines: their details, documents and the grading form. Hand the password over to the examiner; when email is configured they are also told where to sign in.
+// @Tags Admin - Examiners
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body InviteExaminerRequest true "Examiner account and projects"
+// @Success 201 {object} response.Response{data=ExaminerAccount}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/examiners [post]
+func (h *Handler) InviteExaminer(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req InviteExaminerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	account, err := h.service.Invite(req, claims.UserID, audit.NewRequestMeta(c))
+	if err != nil {
+		respondError(c, err, "Failed to invite examiner")
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Examiner invited", account)
+}
+
+// GetExaminers godoc
+// @Summary List external examiners
+// @Description The department's examiner accounts, including lapsed ones, with the projects they examine and whether they have graded them
+// @Tags Admin - Examiners
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]ExaminerAccount}
+// @Router /admin/examiners [get]
+func (h *Handler) GetExaminers(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	accounts, err := h.service.List(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch examiners", err.Error())
+		return
+	}
+	response.Success(c, accounts)
+}
+
+// UpdateExaminerAccess godoc
+// @Summary Change when an examiner's access ends
+// @Description Extends or shortens the guest account's access; a future date reopens a lapsed account. Shortening signs the examiner out.
+// @Tags Admin - Examiners
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Examiner user ID"
+// @Param request body UpdateAccessRequest true "New end of access"
+// @Success 200 {object} response.Response{data=ExaminerAccount}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/examiners/{id} [patch]
+func (h *Handler) UpdateExaminerAccess(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid examiner ID", err.Error())
+		return
+	}
+
+	var req UpdateAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	account, err := h.service.UpdateAccess(uint(id), req, claims.UserID, audit.NewRequestMeta(c))
+	if err != nil {
+		respondError(c, err, "Failed to update examiner access")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Examiner access updated", account)
+}
+
+// RevokeExaminer godoc
+// @Summary End an examiner's access
+// @Description Closes the guest account now and signs the examiner out. Their committee places and submitted grade sheets are kept.
+// @Tags Admin - Examiners
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Examiner user ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/examiners/{id} [delete]
+func (h *Handler) RevokeExaminer(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid examiner ID", err.Error())
+		return
+	}
+
+	if err := h.service.Revoke(uint(id), claims.UserID, audit.NewRequestMeta(c)); err != nil {
+		respondError(c, err, "Failed to revoke examiner access")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Examiner access revoked", nil)
+}
+
+// GetMyExaminations godoc
+// @Summary Projects I examine
+// @Description The projects the signed-in user sits on the defense committee of, with whether they have submitted their grade sheet
+// @Tags Grading
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]AssignedProject}
+// @Router /examiner/projects [get]
+func (h *Handler) GetMyExaminations(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	projects, err := h.service.MyProjects(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch projects", err.Error())
+		return
+	}
+	response.Success(c, projects)
+}
+
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrExaminerNotFound), errors.Is(err, ErrProjectNotFound):
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrProjectOutOfScope):
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrEmailTaken), errors.Is(err, ErrGradeLocked):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrAccessWindow):
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
@@ -0,0 +1,140 @@
+package examiners
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetUser(id uint) (*domain.User, error)
+	GetExaminer(id uint) (*domain.User, error)
+	EmailTaken(email string) bool
+	GetProjectScopes(projectIDs []uint) ([]ProjectScope, error)
+	CreateExaminer(user *domain.User, projectIDs []uint, assignedBy uint) error
+	ListExaminers(departmentID uint) ([]domain.User, error)
+	GetAssignedProjects(userIDs []uint) ([]AssignedProject, error)
+	IsAssigned(userID, projectID uint) bool
+	UpdateAccess(userID uint, expiresAt time.Time, active bool) error
+	GetLapsedExaminers(now time.Time) ([]domain.User, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// ProjectScope is what decides whether a project can be given to an examiner
+type ProjectScope struct {
+	ID           uint
+	DepartmentID uint
+	Locked       bool // the final grade is locked
+}
+
+// AssignedProject is a project on an examiner's list, with whether they have graded it
+type AssignedProject struct {
+	UserID    uint   `json:"-"`
+	ProjectID uint   `json:"project_id"`
+	Title     string `json:"title"`
+	TeamName  string `json:"team_name"`
+	Graded    bool   `json:"graded"`
+	Locked    bool   `json:"locked"` // the final grade is locked; the sheet can no longer change
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	err := r.db.First(&user, id).Error
+	return &user, err
+}
+
+func (r *repository) GetExaminer(id uint) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Where("id = ? AND role = ?", id, enums.RoleExaminer).First(&user).Error
+	return &user, err
+}
+
+func (r *repository) EmailTaken(email string) bool {
+	var count int64
+	r.db.Model(&domain.User{}).Where("LOWER(email) = ?", email).Count(&count)
+	return count > 0
+}
+
+func (r *repository) GetProjectScopes(projectIDs []uint) ([]ProjectScope, error) {
+	var scopes []ProjectScope
+	err := r.db.Table("projects").
+		Select(`projects.id AS id,
+			COALESCE(NULLIF(projects.department_id, 0), teams.department_id) AS department_id,
+			EXISTS (SELECT 1 FROM project_grades g WHERE g.project_id = projects.id) AS locked`).
+		Joins("LEFT JOIN teams ON teams.id = projects.team_id").
+		Where("projects.id IN ?", projectIDs).
+		Scan(&scopes).Error
+	return scopes, err
+}
+
+// CreateExaminer creates the guest account and puts it on the projects' committees in one transaction
+func (r *repository) CreateExaminer(user *domain.User, projectIDs []uint, assignedBy uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("University", "Department").Create(user).Error; err != nil {
+			return err
+		}
+		for _, projectID := range projectIDs {
+			examiner := domain.ProjectExaminer{ProjectID: projectID, UserID: user.ID, AssignedBy: assignedBy}
+			if err := tx.Create(&examiner).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repository) ListExaminers(departmentID uint) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Where("department_id = ? AND role = ? AND deleted_at IS NULL", departmentID, enums.RoleExaminer).
+		Order("access_expires_at DESC, id DESC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *repository) GetAssignedProjects(userIDs []uint) ([]AssignedProject, error) {
+	var projects []AssignedProject
+	if len(userIDs) == 0 {
+		return projects, nil
+	}
+	err := r.db.Table("project_examiners pe").
+		Select(`pe.user_id AS user_id, pe.project_id AS project_id,
+			COALESCE((SELECT pv.title FROM proposal_versions pv WHERE pv.proposal_id = p.proposal_id
+				ORDER BY pv.version_number DESC LIMIT 1), '') AS title,
+			COALESCE(t.name, '') AS team_name,
+			EXISTS (SELECT 1 FROM grade_sheets gs WHERE gs.project_id = pe.project_id AND gs.grader_id = pe.user_id) AS graded,
+			EXISTS (SELECT 1 FROM project_grades g WHERE g.project_id = pe.project_id) AS locked`).
+		Joins("JOIN projects p ON p.id = pe.project_id").
+		Joins("LEFT JOIN teams t ON t.id = p.team_id").
+		Where("pe.user_id IN ?", userIDs).
+		Order("pe.id").
+		Scan(&projects).Error
+	return projects, err
+}
+
+func (r *repository) IsAssigned(userID, projectID uint) bool {
+	var count int64
+	r.db.Model(&domain.ProjectExaminer{}).Where("project_id = ? AND user_id = ?", projectID, userID).Count(&count)
+	return count > 0
+}
+
+func (r *repository) UpdateAccess(userID uint, expiresAt time.Time, active bool) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"access_expires_at": expiresAt, "is_active": active}).Error
+}
+
+// GetLapsedExaminers returns the examiner accounts still active although their access has ended
+func (r *repository) GetLapsedExaminers(now time.Time) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Where("role = ? AND is_active = true AND access_expires_at <= ?", enums.RoleExaminer, now).
+		Find(&users).Error
+	return users, err
+}
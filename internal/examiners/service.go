@@ -0,0 +1,336 @@
+package examiners
+
+import (
+	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"backend/pkg/mailer"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MaxAccessDays bounds how long a guest account can stay open, a semester
+	MaxAccessDays = 180
+	// ExpiryCheckInterval is how often the scheduler closes accounts whose access has ended
+	ExpiryCheckInterval = time.Hour
+)
+
+var (
+	ErrExaminerNotFound  = errors.New("examiner not found")
+	ErrEmailTaken        = errors.New("email already exists")
+	ErrAccessWindow      = fmt.Errorf("access must end in the future and within %d days", MaxAccessDays)
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrProjectOutOfScope = errors.New("examiners can only be given projects of your department")
+	ErrGradeLocked       = errors.New("the project's grade is already locked")
+)
+
+// TokenRevoker signs a user out everywhere
+type TokenRevoker interface {
+	RevokeUserTokens(userID uint, reason string, actorID *uint) error
+}
+
+type Service struct {
+	repo        Repository
+	auditLogger *audit.Logger
+	tokens      TokenRevoker
+	mailer      *mailer.Mailer // nil when email is not configured
+	appURL      string
+}
+
+func NewService(r Repository, auditLogger *audit.Logger, tokens TokenRevoker, mail *mailer.Mailer, appURL string) *Service {
+	return &Service{repo: r, auditLogger: auditLogger, tokens: tokens, mailer: mail, appURL: appURL}
+}
+
+// InviteExaminerRequest creates a guest account for an external examiner. The admin hands the
+// password over; projects can also be added later from the project's grading page.
+type InviteExaminerRequest struct {
+	Name        string    `json:"name" binding:"required" example:"Dr. Hana Girma"`
+	Email       string    `json:"email" binding:"required,email" example:"hana.girma@aau.edu.et"`
+	Password    string    `json:"password" binding:"required,min=8"`
+	Affiliation string    `json:"affiliation" binding:"max=200" example:"Addis Ababa University"`
+	ExpiresAt   time.Time `json:"expires_at" binding:"required" example:"2026-07-31T23:59:59Z"`
+	ProjectIDs  []uint    `json:"project_ids"`
+}
+
+// UpdateAccessRequest moves the end of an examiner's access, reopening a lapsed account
+type UpdateAccessRequest struct {
+	ExpiresAt time.Time `json:"expires_at" binding:"required" example:"2026-08-15T23:59:59Z"`
+}
+
+// ExaminerAccount is a guest account with the projects it examines
+type ExaminerAccount struct {
+	ID              uint              `json:"id"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	Affiliation     string            `json:"affiliation,omitempty"`
+	AccessExpiresAt *time.Time        `json:"access_expires_at"`
+	Active          bool              `json:"active"` // can sign in now
+	LastLoginAt     *time.Time        `json:"last_login_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	Projects        []AssignedProject `json:"projects"`
+}
+
+func hasAccess(user *domain.User, now time.Time) bool {
+	return user.IsActive && (user.AccessExpiresAt == nil || now.Before(*user.AccessExpiresAt))
+}
+
+func checkAccessWindow(expiresAt, now time.Time) error {
+	if !expiresAt.After(now) || expiresAt.After(now.AddDate(0, 0, MaxAccessDays)) {
+		return ErrAccessWindow
+	}
+	return nil
+}
+
+// Invite creates an external examiner's guest account in the admin's department, on the
+// committees of the given projects, and emails them where to sign in when email is configured
+func (s *Service) Invite(req InviteExaminerRequest, adminID uint, meta audit.RequestMeta) (*ExaminerAccount, error) {
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+	now := time.Now()
+	if err := checkAccessWindow(req.ExpiresAt, now); err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if s.repo.EmailTaken(email) {
+		return nil, ErrEmailTaken
+	}
+	projectIDs, err := s.checkProjects(req.ProjectIDs, admin.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+	expiresAt := req.ExpiresAt
+	user := &domain.User{
+		Name:            strings.TrimSpace(req.Name),
+		Email:           email,
+		Password:        string(hashed),
+		Role:            enums.RoleExaminer,
+		UniversityID:    admin.UniversityID,
+		DepartmentID:    admin.DepartmentID,
+		IsActive:        true,
+		EmailVerified:   true,
+		AccessExpiresAt: &expiresAt,
+		Affiliation:     strings.TrimSpace(req.Affiliation),
+	}
+	if err := s.repo.CreateExaminer(user, projectIDs, admin.ID); err != nil {
+		return nil, err
+	}
+
+	s.audit(admin, user.ID, "examiner_invited", nil, map[string]interface{}{
+		"email":       user.Email,
+		"expires_at":  expiresAt,
+		"project_ids": projectIDs,
+	}, meta)
+
+	if s.mailer.Enabled() {
+		body := fmt.Sprintf("Hello %s,\n\nYou have been invited to examine capstone projects as an external examiner. Sign in at %s with this email address and the password the department gave you. Your access ends on %s.\n",
+			user.Name, s.appURL, expiresAt.Format("2 January 2006 15:04 MST"))
+		if err := s.mailer.Send(user.Email, "Your external examiner account", body); err != nil {
+			log.Printf("failed to email examiner %d: %v", user.ID, err)
+		}
+	}
+
+	return s.account(user)
+}
+
+// checkProjects drops duplicates and requires every project to be in the department and not yet graded for good
+func (s *Service) checkProjects(ids []uint, departmentID uint) ([]uint, error) {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	scopes, err := s.repo.GetProjectScopes(unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) != len(unique) {
+		return nil, ErrProjectNotFound
+	}
+	for _, scope := range scopes {
+		if scope.DepartmentID != departmentID {
+			return nil, ErrProjectOutOfScope
+		}
+		if scope.Locked {
+			return nil, fmt.Errorf("project %d: %w", scope.ID, ErrGradeLocked)
+		}
+	}
+	return unique, nil
+}
+
+// List returns the department's examiner accounts, current and lapsed, with their projects
+func (s *Service) List(departmentID uint) ([]ExaminerAccount, error) {
+	users, err := s.repo.ListExaminers(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	assigned, err := s.repo.GetAssignedProjects(ids)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint][]AssignedProject, len(users))
+	for _, p := range assigned {
+		byUser[p.UserID] = append(byUser[p.UserID], p)
+	}
+
+	now := time.Now()
+	accounts := make([]ExaminerAccount, 0, len(users))
+	for i := range users {
+		accounts = append(accounts, toAccount(&users[i], byUser[users[i].ID], now))
+	}
+	return accounts, nil
+}
+
+// UpdateAccess moves the end of the examiner's access. A date in the future reopens a lapsed
+// account; the examiner's sessions are signed out when the access is shortened.
+func (s *Service) UpdateAccess(id uint, req UpdateAccessRequest, adminID uint, meta audit.RequestMeta) (*ExaminerAccount, error) {
+	admin, user, err := s.load(id, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAccessWindow(req.ExpiresAt, time.Now()); err != nil {
+		return nil, err
+	}
+	shortened := user.AccessExpiresAt == nil || req.ExpiresAt.Before(*user.AccessExpiresAt)
+	if err := s.repo.UpdateAccess(user.ID, req.ExpiresAt, true); err != nil {
+		return nil, err
+	}
+	if shortened {
+		s.revokeTokens(user.ID, &admin.ID)
+	}
+
+	s.audit(admin, user.ID, "examiner_access_updated",
+		map[string]interface{}{"expires_at": user.AccessExpiresAt, "is_active": user.IsActive},
+		map[string]interface{}{"expires_at": req.ExpiresAt, "is_active": true}, meta)
+
+	user.AccessExpiresAt = &req.ExpiresAt
+	user.IsActive = true
+	return s.account(user)
+}
+
+// Revoke ends the examiner's access now and signs them out. Their committee places and any grade
+// sheets they submitted are kept; the department head removes them from a committee if needed.
+func (s *Service) Revoke(id uint, adminID uint, meta audit.RequestMeta) error {
+	admin, user, err := s.load(id, adminID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.repo.UpdateAccess(user.ID, now, false); err != nil {
+		return err
+	}
+	s.revokeTokens(user.ID, &admin.ID)
+
+	s.audit(admin, user.ID, "examiner_access_revoked",
+		map[string]interface{}{"expires_at": user.AccessExpiresAt, "is_active": user.IsActive},
+		map[string]interface{}{"expires_at": now, "is_active": false}, meta)
+	return nil
+}
+
+// MyProjects lists the projects the user examines
+func (s *Service) MyProjects(userID uint) ([]AssignedProject, error) {
+	return s.repo.GetAssignedProjects([]uint{userID})
+}
+
+// CanOpenProject reports whether the examiner sits on the project's committee
+func (s *Service) CanOpenProject(userID, projectID uint) bool {
+	return s.repo.IsAssigned(userID, projectID)
+}
+
+// CloseLapsedAccounts deactivates examiner accounts whose access has ended and signs them out
+// (run by the scheduler). Sessions already end with the access; this also stops tokens issued
+// without a session.
+func (s *Service) CloseLapsedAccounts() {
+	lapsed, err := s.repo.GetLapsedExaminers(time.Now())
+	if err != nil {
+		log.Printf("failed to load lapsed examiner accounts: %v", err)
+		return
+	}
+	for _, user := range lapsed {
+		if err := s.repo.UpdateAccess(user.ID, *user.AccessExpiresAt, false); err != nil {
+			log.Printf("failed to close examiner account %d: %v", user.ID, err)
+			continue
+		}
+		s.revokeTokens(user.ID, nil)
+	}
+	if len(lapsed) > 0 {
+		log.Printf("Closed %d lapsed examiner account(s)", len(lapsed))
+	}
+}
+
+// load returns the admin and an examiner of their department
+func (s *Service) load(id, adminID uint) (*domain.User, *domain.User, error) {
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, nil, errors.New("admin not found")
+	}
+	user, err := s.repo.GetExaminer(id)
+	if err != nil || user.DepartmentID != admin.DepartmentID {
+		return nil, nil, ErrExaminerNotFound
+	}
+	return admin, user, nil
+}
+
+func (s *Service) account(user *domain.User) (*ExaminerAccount, error) {
+	assigned, err := s.repo.GetAssignedProjects([]uint{user.ID})
+	if err != nil {
+		return nil, err
+	}
+	account := toAccount(user, assigned, time.Now())
+	return &account, nil
+}
+
+func toAccount(user *domain.User, projects []AssignedProject, now time.Time) ExaminerAccount {
+	if projects == nil {
+		projects = []AssignedProject{}
+	}
+	return ExaminerAccount{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Affiliation:     user.Affiliation,
+		AccessExpiresAt: user.AccessExpiresAt,
+		Active:          hasAccess(user, now),
+		LastLoginAt:     user.LastLoginAt,
+		CreatedAt:       user.CreatedAt,
+		Projects:        projects,
+	}
+}
+
+func (s *Service) revokeTokens(userID uint, actorID *uint) {
+	if err := s.tokens.RevokeUserTokens(userID, auth.RevocationAccessExpired, actorID); err != nil {
+		log.Printf("failed to revoke tokens of examiner %d: %v", userID, err)
+	}
+}
+
+func (s *Service) audit(admin *domain.User, examinerID uint, action string, oldState, newState map[string]interface{}, meta audit.RequestMeta) {
+	err := s.auditLogger.LogAction("user", examinerID, action, &admin.ID, string(admin.Role), admin.Email,
+		oldState, newState, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit %s of examiner %d: %v", action, examinerID, err)
+	}
+}
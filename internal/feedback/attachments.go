@@ -0,0 +1,84 @@
+package feedback
+
+import (
+	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"mime/multipart"
+)
+
+const (
+	MaxAttachments     = 3
+	MaxAttachmentBytes = 20 << 20 // 20 MB per file
+)
+
+var ErrAttachmentType = errors.New("attachments must be PDFs or images")
+
+// attachmentTypes are the sniffed content types an annotated copy can have
+var attachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
+// prepareAttachments checks the files like project documents and stores them privately. Nothing is
+// written unless every file passes; the caller removes the stored files if the feedback is not saved.
+func (s *Service) prepareAttachments(proposalID uint, uploads []*multipart.FileHeader) ([]domain.FeedbackAttachment, error) {
+	if len(uploads) > MaxAttachments {
+		return nil, fmt.Errorf("at most %d attachments are allowed", MaxAttachments)
+	}
+
+	attachments := make([]domain.FeedbackAttachment, 0, len(uploads))
+	for _, file := range uploads {
+		if file.Size > MaxAttachmentBytes {
+			return nil, fmt.Errorf("%s is larger than %d MB", file.Filename, MaxAttachmentBytes>>20)
+		}
+		meta, err := files.Inspect(file)
+		if err != nil {
+			return nil, err
+		}
+		if meta.ScanStatus == enums.ScanStatusInfected {
+			return nil, fmt.Errorf("%w: %s", files.ErrFileInfected, file.Filename)
+		}
+		if !attachmentTypes[meta.DetectedMIME] {
+			return nil, fmt.Errorf("%w: %s", ErrAttachmentType, file.Filename)
+		}
+		attachments = append(attachments, domain.FeedbackAttachment{
+			FileName:      file.Filename,
+			FileSizeBytes: file.Size,
+			FileMetadata:  meta,
+		})
+	}
+
+	for i, file := range uploads {
+		path, err := s.uploader.SavePrivateFile(file, fmt.Sprintf("feedback/%d", proposalID))
+		if err != nil {
+			s.removeAttachments(attachments[:i])
+			return nil, err
+		}
+		attachments[i].Path = path
+	}
+	return attachments, nil
+}
+
+func (s *Service) removeAttachments(attachments []domain.FeedbackAttachment) {
+	for _, attachment := range attachments {
+		if attachment.Path != "" {
+			_ = s.uploader.DeleteFile(attachment.Path)
+		}
+	}
+}
+
+// withDownloadURLs points each attachment at its access-checked download endpoint
+func withDownloadURLs(feedbacks ...*domain.Feedback) {
+	for _, f := range feedbacks {
+		for i := range f.Attachments {
+			a := &f.Attachments[i]
+			a.DownloadURL = fmt.Sprintf("/api/v1/files/feedback/%d/%d", a.FeedbackID, a.ID)
+		}
+	}
+}
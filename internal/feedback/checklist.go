@@ -0,0 +1,153 @@
+package feedback
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// DefaultChecklist is seeded as the global review checklist
+var DefaultChecklist = []domain.ReviewChecklistItem{
+	{Key: "similarity_check", Label: "Similarity check run", Mandatory: true, Position: 1},
+	{Key: "methodology_reviewed", Label: "Methodology reviewed", Mandatory: true, Position: 2},
+	{Key: "scope_approved", Label: "Scope approved", Mandatory: true, Position: 3},
+}
+
+var checklistKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+type ChecklistItemRequest struct {
+	Key       string `json:"key" binding:"required" example:"scope_approved"`
+	Label     string `json:"label" binding:"required" example:"Scope approved"`
+	Mandatory bool   `json:"mandatory"`
+}
+
+type UpdateChecklistRequest struct {
+	Items []ChecklistItemRequest `json:"items"` // empty reverts to the global checklist
+}
+
+// Checklist is the checklist that applies to a department
+type Checklist struct {
+	DepartmentID *uint                        `json:"department_id,omitempty"`
+	Inherited    bool                         `json:"inherited"` // true when the global checklist applies
+	Items        []domain.ReviewChecklistItem `json:"items"`
+}
+
+// EnsureDefaultChecklist seeds any missing global checklist items
+func (s *Service) EnsureDefaultChecklist() error {
+	for _, item := range DefaultChecklist {
+		if err := s.repo.EnsureGlobalChecklistItem(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetChecklist returns the department's checklist, falling back to the global one
+func (s *Service) GetChecklist(departmentID uint) (*Checklist, error) {
+	items, err := s.repo.GetChecklistItems(&departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		return &Checklist{DepartmentID: &departmentID, Items: items}, nil
+	}
+
+	items, err = s.repo.GetChecklistItems(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Checklist{DepartmentID: &departmentID, Inherited: true, Items: items}, nil
+}
+
+// GetProposalChecklist returns the checklist the assigned advisor must complete for a proposal
+func (s *Service) GetProposalChecklist(proposalID uint, advisorID uint) (*Checklist, error) {
+	proposal, err := s.proposalRepo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if err := checkDecider(proposal, advisorID); err != nil {
+		return nil, err
+	}
+	return s.GetChecklist(proposalDepartmentID(proposal))
+}
+
+// UpdateChecklist replaces the department's checklist
+func (s *Service) UpdateChecklist(departmentID uint, req UpdateChecklistRequest) (*Checklist, error) {
+	items := make([]domain.ReviewChecklistItem, 0, len(req.Items))
+	seen := make(map[string]bool)
+	for i, in := range req.Items {
+		key := strings.ToLower(strings.TrimSpace(in.Key))
+		label := strings.TrimSpace(in.Label)
+		if !checklistKeyPattern.MatchString(key) {
+			return nil, errors.New("checklist keys may only contain lowercase letters, digits and underscores")
+		}
+		if label == "" {
+			return nil, errors.New("checklist labels are required")
+		}
+		if seen[key] {
+			return nil, errors.New("duplicate checklist key: " + key)
+		}
+		seen[key] = true
+
+		deptID := departmentID
+		items = append(items, domain.ReviewChecklistItem{
+			DepartmentID: &deptID,
+			Key:          key,
+			Label:        label,
+			Mandatory:    in.Mandatory,
+			Position:     i + 1,
+		})
+	}
+
+	if err := s.repo.ReplaceChecklist(departmentID, items); err != nil {
+		return nil, err
+	}
+	return s.GetChecklist(departmentID)
+}
+
+// completeChecklist records the advisor's answers against the applicable checklist.
+// Approving requires every mandatory item to be checked; unknown keys are rejected.
+func (s *Service) completeChecklist(proposal *domain.Proposal, answers map[string]bool, decision string) ([]domain.ChecklistEntry, error) {
+	checklist, err := s.GetChecklist(proposalDepartmentID(proposal))
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(checklist.Items))
+	entries := make([]domain.ChecklistEntry, 0, len(checklist.Items))
+	var missing []string
+	for _, item := range checklist.Items {
+		known[item.Key] = true
+		checked := answers[item.Key]
+		entries = append(entries, domain.ChecklistEntry{
+			Key:       item.Key,
+			Label:     item.Label,
+			Mandatory: item.Mandatory,
+			Checked:   checked,
+		})
+		if item.Mandatory && !checked {
+			missing = append(missing, item.Label)
+		}
+	}
+	for key := range answers {
+		if !known[key] {
+			return nil, errors.New("unknown checklist item: " + key)
+		}
+	}
+
+	if decision == string(domain.FeedbackDecisionApprove) && len(missing) > 0 {
+		return nil, errors.New(ErrChecklistIncomplete + ": " + strings.Join(missing, ", "))
+	}
+	return entries, nil
+}
+
+// ErrChecklistIncomplete prefixes the error returned when approval is attempted with unchecked mandatory items
+const ErrChecklistIncomplete = "mandatory checklist items are unchecked"
+
+func proposalDepartmentID(proposal *domain.Proposal) uint {
+	if proposal.Team == nil {
+		return 0
+	}
+	return proposal.Team.DepartmentID
+}
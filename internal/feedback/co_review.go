@@ -0,0 +1,86 @@
+package feedback
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/uow"
+	"errors"
+)
+
+var (
+	ErrAwaitingSecondReview = errors.New("the advisor approved this proposal; it awaits the co-reviewer's decision")
+	ErrApprovedAsAdvisor    = errors.New("you approved this proposal as its advisor; the co-reviewer's approval must come from someone else")
+)
+
+// checkDecider lets the assigned advisor decide on the proposal, and the co-reviewer instead once
+// the advisor has approved it
+func checkDecider(proposal *domain.Proposal, reviewerID uint) error {
+	if proposal.Status == enums.ProposalStatusAwaitingSecondReview {
+		if proposal.SecondReviewerID != nil && *proposal.SecondReviewerID == reviewerID {
+			return nil
+		}
+		return ErrAwaitingSecondReview
+	}
+	if proposal.AdvisorID == nil || *proposal.AdvisorID != reviewerID {
+		return errors.New("only the assigned advisor can review this proposal")
+	}
+	return nil
+}
+
+// checkCoReviewApproval refuses the co-reviewer's approval when they gave the advisor's approval
+// themselves, e.g. when the proposal was reassigned after it
+func (s *Service) checkCoReviewApproval(proposal *domain.Proposal, reviewerID uint) error {
+	if proposal.Status != enums.ProposalStatusAwaitingSecondReview {
+		return nil
+	}
+	approval, err := s.repo.GetLatestApproval(proposal.ID)
+	if err != nil {
+		return err
+	}
+	if approval != nil && approval.ReviewerID == reviewerID {
+		return ErrApprovedAsAdvisor
+	}
+	return nil
+}
+
+// needsSecondReview reports whether an approval has to wait for the co-reviewer: the proposal has
+// one and the approval is the assigned advisor's
+func needsSecondReview(proposal *domain.Proposal) bool {
+	return proposal.SecondReviewerID != nil && proposal.Status != enums.ProposalStatusAwaitingSecondReview
+}
+
+// requestSecondReview records the advisor's approval and hands the decision to the co-reviewer.
+// The version is approved and the project created only when the co-reviewer approves too.
+func (s *Service) requestSecondReview(proposal *domain.Proposal, feedback *domain.Feedback) error {
+	var title string
+	for _, v := range proposal.Versions {
+		if v.ID == feedback.ProposalVersionID {
+			title = v.Title
+		}
+	}
+
+	return s.work.Do(func(tx *uow.Tx) error {
+		if err := s.repo.WithTx(tx.DB()).Create(feedback); err != nil {
+			return err
+		}
+		if err := s.proposalRepo.WithTx(tx.DB()).SetStatus(proposal.ID, enums.ProposalStatusAwaitingSecondReview); err != nil {
+			return err
+		}
+
+		tx.Publish(events.Event{
+			Name:       events.SecondReviewRequested,
+			EntityType: "proposal",
+			EntityID:   proposal.ID,
+			ActorID:    feedback.ReviewerID,
+			UserIDs:    append([]uint{*proposal.SecondReviewerID}, teamMemberIDs(proposal)...),
+			Data: map[string]interface{}{
+				"feedback_id":        feedback.ID,
+				"version_id":         feedback.ProposalVersionID,
+				"second_reviewer_id": *proposal.SecondReviewerID,
+				"title":              title,
+			},
+		})
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package feedback
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/events"
+	"errors"
+	"log"
+	"math"
+	"time"
+)
+
+// RevisionDeadlineCheckInterval is how often the scheduler sends reminders and flags missed deadlines
+const RevisionDeadlineCheckInterval = time.Hour
+
+// RevisionReminderLeadTimes are how long before a resubmission deadline the team is reminded, earliest first
+var RevisionReminderLeadTimes = []time.Duration{3 * 24 * time.Hour, 24 * time.Hour}
+
+// validateResubmitBy checks a deadline given with feedback; only revision requests carry one
+func validateResubmitBy(resubmitBy *time.Time, decision string) error {
+	if resubmitBy == nil {
+		return nil
+	}
+	if decision != string(domain.FeedbackDecisionRevise) {
+		return errors.New("a resubmission deadline can only be set when requesting revision")
+	}
+	if !resubmitBy.After(time.Now()) {
+		return errors.New("resubmission deadline must be in the future")
+	}
+	return nil
+}
+
+// ProcessRevisionDeadlines reminds teams as their resubmission deadline approaches and flags
+// deadlines that passed without a resubmission to the department admins (run by the scheduler)
+func (s *Service) ProcessRevisionDeadlines() {
+	open, err := s.repo.GetOpenRevisionDeadlines()
+	if err != nil {
+		log.Printf("failed to load revision deadlines: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range open {
+		feedback := &open[i]
+		remaining := feedback.ResubmitBy.Sub(now)
+
+		if remaining <= 0 {
+			s.flagMissedDeadline(feedback, now)
+			continue
+		}
+
+		// Only the latest due reminder is sent, so a deadline set two days out skips the T-3 one
+		due := 0
+		for n, lead := range RevisionReminderLeadTimes {
+			if remaining <= lead {
+				due = n + 1
+			}
+		}
+		if due <= feedback.DeadlineReminders {
+			continue
+		}
+		if err := s.repo.SetDeadlineReminders(feedback.ID, due); err != nil {
+			log.Printf("failed to record deadline reminder for feedback %d: %v", feedback.ID, err)
+			continue
+		}
+
+		s.bus.Publish(events.Event{
+			Name:       events.RevisionDeadlineNear,
+			EntityType: "proposal",
+			EntityID:   feedback.ProposalID,
+			UserIDs:    teamMemberIDs(&feedback.Proposal),
+			Data: map[string]interface{}{
+				"feedback_id": feedback.ID,
+				"title":       latestTitle(&feedback.Proposal),
+				"resubmit_by": feedback.ResubmitBy.Format(time.RFC3339),
+				"days_left":   int(math.Ceil(remaining.Hours() / 24)),
+			},
+		})
+	}
+}
+
+func (s *Service) flagMissedDeadline(feedback *domain.Feedback, now time.Time) {
+	if err := s.repo.MarkDeadlineMissed(feedback.ID, now); err != nil {
+		log.Printf("failed to flag missed deadline for feedback %d: %v", feedback.ID, err)
+		return
+	}
+
+	// Admins see it on their dashboard and in notifications; the team and advisor are told too
+	userIDs := append(teamMemberIDs(&feedback.Proposal), feedback.ReviewerID)
+	if feedback.Proposal.Team != nil {
+		adminIDs, err := s.repo.GetDepartmentAdminIDs(feedback.Proposal.Team.DepartmentID)
+		if err != nil {
+			log.Printf("failed to load department admins for feedback %d: %v", feedback.ID, err)
+		}
+		userIDs = append(userIDs, adminIDs...)
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.RevisionDeadlineMissed,
+		EntityType: "proposal",
+		EntityID:   feedback.ProposalID,
+		UserIDs:    userIDs,
+		Data: map[string]interface{}{
+			"feedback_id": feedback.ID,
+			"title":       latestTitle(&feedback.Proposal),
+			"resubmit_by": feedback.ResubmitBy.Format(time.RFC3339),
+		},
+	})
+}
+
+// latestTitle relies on the versions being loaded latest first
+func latestTitle(proposal *domain.Proposal) string {
+	if len(proposal.Versions) == 0 {
+		return ""
+	}
+	return proposal.Versions[0].Title
+}
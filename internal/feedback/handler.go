@@ -2,9 +2,14 @@ package feedback
 
 import (
 	"backend/internal/auth"
+	"backend/internal/files"
 	"backend/pkg/response"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -39,19 +44,42 @@ func (h *Handler) GetPendingProposals(c *gin.Context) {
 	response.Success(c, proposals)
 }
 
+// GetInbox godoc
+// @Summary Get the advisor's review inbox
+// @Description The advisor's assigned proposals grouped by the action they need, in this order: new_submissions (first review, reached the advisor in the last 3 days), awaiting_feedback (first review, waiting longer), resubmitted (revised after earlier feedback), co_review (approved by their advisor, awaiting the caller's decision as co-reviewer), awaiting_revision (with the team), awaiting_second_review (approved by the caller, waiting for the co-reviewer), approved and rejected. Each group has its count and latest activity; needs_action counts the first four. Archived cohorts are left out and team names are hidden under blind review until the proposal is decided.
+// @Tags Advisor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=Inbox}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /advisor/inbox [get]
+func (h *Handler) GetInbox(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	inbox, err := h.service.GetInbox(userClaims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to load inbox", err.Error())
+		return
+	}
+	response.Success(c, inbox)
+}
 
 // CreateFeedback godoc
 // @Summary Submit feedback for a proposal
-// @Description Teacher reviews proposal and submits feedback (approve, revise, reject)
+// @Description Teacher reviews proposal and submits feedback (approve, revise, reject). template_ids inserts saved feedback templates ahead of the comment. checklist records the review checklist; approval is refused (422) while mandatory items are unchecked. time_spent_minutes optionally records how long the review took; the word count of the comment is computed by the server. To attach annotated copies, send multipart form data with up to 3 PDFs or images in "attachments" and the checklist as a JSON string; each attachment gets an access-controlled download_url.
 // @Tags Feedback
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Security BearerAuth
 // @Param feedback body CreateFeedbackRequest true "Feedback details"
+// @Param attachments formData file false "Annotated PDFs or images (repeat the field for several)"
 // @Success 201 {object} response.Response{data=domain.Feedback}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /feedback [post]
 func (h *Handler) CreateFeedback(c *gin.Context) {
@@ -59,13 +87,31 @@ func (h *Handler) CreateFeedback(c *gin.Context) {
 	userClaims := claims.(*auth.TokenClaims)
 
 	var req CreateFeedbackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBind(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
 		return
 	}
+	var attachments []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		attachments = form.File["attachments"]
+		if checklist := c.PostForm("checklist"); checklist != "" {
+			if err := json.Unmarshal([]byte(checklist), &req.Checklist); err != nil {
+				response.Error(c, http.StatusBadRequest, "Invalid request", "checklist must be a JSON object of item keys to booleans")
+				return
+			}
+		}
+	}
 
-	feedback, err := h.service.CreateFeedback(req, userClaims.UserID)
+	feedback, err := h.service.CreateFeedback(req, attachments, userClaims.UserID)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), ErrChecklistIncomplete) || errors.Is(err, files.ErrFileInfected) || errors.Is(err, ErrAttachmentType) {
+			response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, ErrAwaitingSecondReview) || errors.Is(err, ErrApprovedAsAdvisor) {
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
 		response.Error(c, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
@@ -75,12 +121,12 @@ func (h *Handler) CreateFeedback(c *gin.Context) {
 
 // GetProposalFeedback godoc
 // @Summary Get all feedback for a proposal
-// @Description Retrieve all feedback history for a specific proposal
+// @Description Retrieve all feedback history for a specific proposal, with the answered second opinions keyed by version ID; a version can have second opinions without feedback of its own
 // @Tags Feedback
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Proposal ID"
-// @Success 200 {object} response.Response{data=[]domain.Feedback}
+// @Success 200 {object} response.Response{data=ProposalFeedback}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
@@ -142,3 +188,337 @@ func (h *Handler) GetFeedback(c *gin.Context) {
 	response.Success(c, feedback)
 }
 
+// GetTemplates godoc
+// @Summary List feedback templates
+// @Description Advisor's saved feedback snippets, most used first
+// @Tags Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param category query string false "Only templates in this category"
+// @Success 200 {object} response.Response{data=[]domain.FeedbackTemplate}
+// @Router /advisor/feedback-templates [get]
+func (h *Handler) GetTemplates(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	templates, err := h.service.GetTemplates(userClaims.UserID, c.Query("category"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch templates", err.Error())
+		return
+	}
+	response.Success(c, templates)
+}
+
+// CreateTemplate godoc
+// @Summary Create a feedback template
+// @Description Saves a reusable feedback snippet for the advisor
+// @Tags Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param template body FeedbackTemplateRequest true "Template"
+// @Success 201 {object} response.Response{data=domain.FeedbackTemplate}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /advisor/feedback-templates [post]
+func (h *Handler) CreateTemplate(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	var req FeedbackTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	template, err := h.service.CreateTemplate(req, userClaims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Template created", template)
+}
+
+// UpdateTemplate godoc
+// @Summary Update a feedback template
+// @Tags Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param template body FeedbackTemplateRequest true "Template"
+// @Success 200 {object} response.Response{data=domain.FeedbackTemplate}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /advisor/feedback-templates/{id} [put]
+func (h *Handler) UpdateTemplate(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid template ID", err.Error())
+		return
+	}
+
+	var req FeedbackTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(uint(id), req, userClaims.UserID)
+	if err != nil {
+		if err.Error() == "template not found" {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Template updated", template)
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a feedback template
+// @Tags Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.ErrorResponse
+// @Router /advisor/feedback-templates/{id} [delete]
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid template ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeleteTemplate(uint(id), userClaims.UserID); err != nil {
+		if err.Error() == "template not found" {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to delete template", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Template deleted", nil)
+}
+
+// GetProposalChecklist godoc
+// @Summary Get the review checklist for a proposal
+// @Description Checklist items the assigned advisor answers when submitting feedback; mandatory ones must be checked to approve
+// @Tags Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param proposal_id query int true "Proposal ID"
+// @Success 200 {object} response.Response{data=Checklist}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /feedback/checklist [get]
+func (h *Handler) GetProposalChecklist(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	proposalID, err := strconv.ParseUint(c.Query("proposal_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid proposal ID", err.Error())
+		return
+	}
+
+	checklist, err := h.service.GetProposalChecklist(uint(proposalID), userClaims.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case "only the assigned advisor can review this proposal", ErrAwaitingSecondReview.Error():
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch checklist", err.Error())
+		}
+		return
+	}
+	response.Success(c, checklist)
+}
+
+// GetDepartmentChecklist godoc
+// @Summary Get the department review checklist
+// @Description The checklist advisors in the admin's department complete before deciding; inherited=true when the global checklist applies
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=Checklist}
+// @Router /admin/review-checklist [get]
+func (h *Handler) GetDepartmentChecklist(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	checklist, err := h.service.GetChecklist(userClaims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch checklist", err.Error())
+		return
+	}
+	response.Success(c, checklist)
+}
+
+// UpdateDepartmentChecklist godoc
+// @Summary Configure the department review checklist
+// @Description Replaces the department's checklist; an empty list reverts to the global checklist
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param checklist body UpdateChecklistRequest true "Checklist items in display order"
+// @Success 200 {object} response.Response{data=Checklist}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/review-checklist [put]
+func (h *Handler) UpdateDepartmentChecklist(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	var req UpdateChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	checklist, err := h.service.UpdateChecklist(userClaims.DepartmentID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Checklist updated", checklist)
+}
+
+// RequestSecondOpinion godoc
+// @Summary Request a second opinion on a proposal version
+// @Description The assigned advisor invites another active teacher of the proposal's department to review one version. The reviewer can read the proposal until they answer.
+// @Tags Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RequestSecondOpinionRequest true "Version and reviewer"
+// @Success 201 {object} response.Response{data=domain.SecondOpinion}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Router /feedback/second-opinions [post]
+func (h *Handler) RequestSecondOpinion(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	var req RequestSecondOpinionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	opinion, err := h.service.RequestSecondOpinion(req, userClaims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Second opinion requested", opinion)
+}
+
+// GetSecondOpinionRequests godoc
+// @Summary List second opinion requests addressed to me
+// @Tags Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, answered or declined"
+// @Success 200 {object} response.Response{data=[]domain.SecondOpinion}
+// @Failure 500 {object} response.ErrorResponse
+// @Router /feedback/second-opinions [get]
+func (h *Handler) GetSecondOpinionRequests(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	opinions, err := h.service.GetSecondOpinionRequests(userClaims.UserID, c.Query("status"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Fetch failed", err.Error())
+		return
+	}
+	response.Success(c, opinions)
+}
+
+// AnswerSecondOpinion godoc
+// @Summary Answer a second opinion request
+// @Description The invited reviewer leaves a comment, shown in the proposal's feedback thread, or declines with "decline": true
+// @Tags Feedback
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Second opinion ID"
+// @Param answer body AnswerSecondOpinionRequest true "Comment"
+// @Success 200 {object} response.Response{data=domain.SecondOpinion}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /feedback/second-opinions/{id}/answer [post]
+func (h *Handler) AnswerSecondOpinion(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid second opinion ID", err.Error())
+		return
+	}
+
+	var req AnswerSecondOpinionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	opinion, err := h.service.AnswerSecondOpinion(uint(id), userClaims.UserID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSecondOpinionNotFound):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrSecondOpinionClosed):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Second opinion recorded", opinion)
+}
+
+// GetProposalSecondOpinions godoc
+// @Summary List second opinions on a proposal
+// @Description The assigned advisor sees every request they made on the proposal, answered or not
+// @Tags Feedback
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=[]domain.SecondOpinion}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /proposals/{id}/second-opinions [get]
+func (h *Handler) GetProposalSecondOpinions(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid proposal ID", err.Error())
+		return
+	}
+
+	opinions, err := h.service.GetProposalSecondOpinions(uint(id), userClaims.UserID)
+	if err != nil {
+		if err.Error() == "proposal not found" {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+		return
+	}
+	response.Success(c, opinions)
+}
@@ -0,0 +1,175 @@
+package feedback
+
+import (
+	"backend/pkg/enums"
+	"sort"
+	"time"
+)
+
+// InboxNewFor is how long a proposal that reached the advisor counts as new before it is listed as awaiting feedback
+const InboxNewFor = 72 * time.Hour
+
+// Inbox groups, in the order they are listed
+const (
+	InboxNewSubmissions   = "new_submissions"        // first review, reached the advisor within InboxNewFor
+	InboxAwaiting         = "awaiting_feedback"      // first review, waiting longer than that
+	InboxResubmitted      = "resubmitted"            // revised after earlier feedback, awaiting a new decision
+	InboxCoReview         = "co_review"              // approved by its advisor, awaiting the caller's decision as co-reviewer
+	InboxAwaitingRevision = "awaiting_revision"      // sent back to the team
+	InboxSecondReview     = "awaiting_second_review" // approved by the caller, waiting for the co-reviewer
+	InboxApproved         = "approved"
+	InboxRejected         = "rejected"
+)
+
+var inboxGroups = []string{InboxNewSubmissions, InboxAwaiting, InboxResubmitted, InboxCoReview, InboxAwaitingRevision, InboxSecondReview, InboxApproved, InboxRejected}
+
+// InboxItem is one proposal in the advisor's inbox
+type InboxItem struct {
+	ProposalID     uint                 `json:"proposal_id"`
+	Title          string               `json:"title"`
+	Status         enums.ProposalStatus `json:"status"`
+	TeamID         *uint                `json:"team_id,omitempty"`   // empty under blind review until the proposal is decided
+	TeamName       string               `json:"team_name,omitempty"` // empty under blind review until the proposal is decided
+	VersionID      uint                 `json:"version_id"`
+	VersionNumber  int                  `json:"version_number"`
+	FeedbackCount  int64                `json:"feedback_count"`
+	ResubmitBy     *time.Time           `json:"resubmit_by,omitempty"` // revision deadline, for proposals awaiting revision
+	WaitingSince   time.Time            `json:"waiting_since"`         // when the proposal entered its current state
+	LastActivityAt time.Time            `json:"last_activity_at"`      // latest version, save, feedback or status change
+}
+
+// InboxGroup is the proposals needing the same kind of action, most recently active first
+type InboxGroup struct {
+	Key            string      `json:"key"`
+	Count          int         `json:"count"`
+	LastActivityAt *time.Time  `json:"last_activity_at,omitempty"`
+	Items          []InboxItem `json:"items"`
+}
+
+// Inbox is an advisor's assigned proposals grouped by the action they need
+type Inbox struct {
+	Groups      []InboxGroup `json:"groups"`
+	NeedsAction int          `json:"needs_action"` // new, awaiting, resubmitted and co-review proposals
+	GeneratedAt time.Time    `json:"generated_at"`
+}
+
+// FeedbackActivity summarises the feedback given on a proposal
+type FeedbackActivity struct {
+	ProposalID uint
+	Count      int64
+	LastAt     time.Time
+	ResubmitBy *time.Time // deadline of the latest feedback
+}
+
+// GetInbox groups the advisor's assigned proposals of running cohorts by the action they need, with
+// counts and last-activity timestamps, in place of the separate pending, feedback and proposal lists.
+// Proposals the advisor co-reviews are listed once the assigned advisor approved them.
+func (s *Service) GetInbox(advisorID uint) (*Inbox, error) {
+	proposals, err := s.repo.GetInboxProposals(advisorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(proposals))
+	for _, p := range proposals {
+		ids = append(ids, p.ID)
+	}
+	activity, err := s.repo.GetFeedbackActivity(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blind review fails closed, like the proposal views
+	blind := true
+	if university, err := s.proposalRepo.GetUserUniversity(advisorID); err == nil {
+		blind = university.BlindReview
+	}
+
+	now := time.Now()
+	byGroup := make(map[string][]InboxItem, len(inboxGroups))
+	for i := range proposals {
+		p := &proposals[i]
+		fb, reviewed := activity[p.ID]
+		item := InboxItem{
+			ProposalID:     p.ID,
+			Status:         p.Status,
+			TeamID:         p.TeamID,
+			FeedbackCount:  fb.Count,
+			WaitingSince:   p.UpdatedAt,
+			LastActivityAt: p.UpdatedAt,
+		}
+		if len(p.Versions) > 0 {
+			latest := p.Versions[0]
+			item.Title = latest.Title
+			item.VersionID = latest.ID
+			item.VersionNumber = latest.VersionNumber
+			item.LastActivityAt = laterOf(item.LastActivityAt, latest.CreatedAt)
+			if latest.LastSavedAt != nil {
+				item.LastActivityAt = laterOf(item.LastActivityAt, *latest.LastSavedAt)
+			}
+		}
+		if reviewed {
+			item.LastActivityAt = laterOf(item.LastActivityAt, fb.LastAt)
+		}
+		secondReview := p.Status == enums.ProposalStatusAwaitingSecondReview
+		awaitingDecision := p.Status == enums.ProposalStatusSubmitted || p.Status == enums.ProposalStatusUnderReview || secondReview
+		if blind && awaitingDecision {
+			item.TeamID = nil
+		} else if p.Team != nil {
+			item.TeamName = p.Team.Name
+		}
+
+		var key string
+		switch {
+		case secondReview && p.SecondReviewerID != nil && *p.SecondReviewerID == advisorID:
+			key = InboxCoReview
+		case secondReview:
+			key = InboxSecondReview
+		case awaitingDecision && reviewed:
+			key = InboxResubmitted
+		case awaitingDecision && now.Sub(p.UpdatedAt) <= InboxNewFor:
+			key = InboxNewSubmissions
+		case awaitingDecision:
+			key = InboxAwaiting
+		case p.Status == enums.ProposalStatusRevisionRequired:
+			key = InboxAwaitingRevision
+			item.ResubmitBy = fb.ResubmitBy
+		case p.Status == enums.ProposalStatusApproved:
+			key = InboxApproved
+		case p.Status == enums.ProposalStatusRejected:
+			key = InboxRejected
+		default:
+			continue
+		}
+		byGroup[key] = append(byGroup[key], item)
+	}
+
+	inbox := &Inbox{Groups: make([]InboxGroup, 0, len(inboxGroups)), GeneratedAt: now}
+	for _, key := range inboxGroups {
+		items := byGroup[key]
+		if items == nil {
+			items = []InboxItem{}
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].LastActivityAt.After(items[j].LastActivityAt)
+		})
+		group := InboxGroup{Key: key, Count: len(items), Items: items}
+		if len(items) > 0 {
+			last := items[0].LastActivityAt
+			group.LastActivityAt = &last
+		}
+		switch key {
+		case InboxNewSubmissions, InboxAwaiting, InboxResubmitted, InboxCoReview:
+			inbox.NeedsAction += len(items)
+		}
+		inbox.Groups = append(inbox.Groups, group)
+	}
+	return inbox, nil
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -2,16 +2,53 @@ package feedback
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type Repository interface {
+	// WithTx returns the repository bound to a unit of work's transaction
+	WithTx(tx *gorm.DB) Repository
+
 	Create(feedback *domain.Feedback) error
 	GetByProposalID(proposalID uint) ([]domain.Feedback, error)
 	GetByID(id uint) (*domain.Feedback, error)
+	GetLatestApproval(proposalID uint) (*domain.Feedback, error)
 	GetPendingProposalsForReviewer(reviewerID uint) ([]domain.Proposal, error)
+	GetInboxProposals(advisorID uint) ([]domain.Proposal, error)
+	GetFeedbackActivity(proposalIDs []uint) (map[uint]FeedbackActivity, error)
 	GetDB() *gorm.DB
+
+	// Feedback templates
+	CreateTemplate(template *domain.FeedbackTemplate) error
+	GetTemplates(advisorID uint, category string) ([]domain.FeedbackTemplate, error)
+	GetTemplate(id uint) (*domain.FeedbackTemplate, error)
+	GetTemplatesByIDs(advisorID uint, ids []uint) ([]domain.FeedbackTemplate, error)
+	UpdateTemplate(template *domain.FeedbackTemplate) error
+	DeleteTemplate(id uint) error
+	IncrementTemplateUsage(ids []uint) error
+
+	// Review checklist
+	GetChecklistItems(departmentID *uint) ([]domain.ReviewChecklistItem, error)
+	ReplaceChecklist(departmentID uint, items []domain.ReviewChecklistItem) error
+	EnsureGlobalChecklistItem(item domain.ReviewChecklistItem) error
+
+	// Revision deadlines
+	GetOpenRevisionDeadlines() ([]domain.Feedback, error)
+	SetDeadlineReminders(id uint, sent int) error
+	MarkDeadlineMissed(id uint, at time.Time) error
+	GetDepartmentAdminIDs(departmentID uint) ([]uint, error)
+
+	// Second opinions
+	CreateSecondOpinion(opinion *domain.SecondOpinion) error
+	GetSecondOpinion(id uint) (*domain.SecondOpinion, error)
+	UpdateSecondOpinion(opinion *domain.SecondOpinion) error
+	GetSecondOpinionsByProposal(proposalID uint) ([]domain.SecondOpinion, error)
+	GetSecondOpinionsForReviewer(reviewerID uint, status string) ([]domain.SecondOpinion, error)
+	HasOpenSecondOpinion(versionID, reviewerID uint) (bool, error)
+	GetUser(id uint) (*domain.User, error)
 }
 
 type repository struct {
@@ -21,6 +58,10 @@ type repository struct {
 func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
 func (r *repository) GetDB() *gorm.DB {
 	return r.db
 }
@@ -32,6 +73,7 @@ func (r *repository) Create(feedback *domain.Feedback) error {
 func (r *repository) GetByProposalID(proposalID uint) ([]domain.Feedback, error) {
 	var feedbacks []domain.Feedback
 	err := r.db.Preload("Reviewer").
+		Preload("Attachments").
 		Where("proposal_id = ?", proposalID).
 		Order("created_at DESC").
 		Find(&feedbacks).Error
@@ -41,6 +83,7 @@ func (r *repository) GetByProposalID(proposalID uint) ([]domain.Feedback, error)
 func (r *repository) GetByID(id uint) (*domain.Feedback, error) {
 	var feedback domain.Feedback
 	err := r.db.Preload("Reviewer").
+		Preload("Attachments").
 		Preload("Proposal").
 		Preload("ProposalVersion").
 		First(&feedback, id).Error
@@ -50,6 +93,19 @@ func (r *repository) GetByID(id uint) (*domain.Feedback, error) {
 	return &feedback, nil
 }
 
+// GetLatestApproval returns the proposal's most recent approval, or nil when there is none
+func (r *repository) GetLatestApproval(proposalID uint) (*domain.Feedback, error) {
+	var feedbacks []domain.Feedback
+	err := r.db.Where("proposal_id = ? AND decision = ?", proposalID, domain.FeedbackDecisionApprove).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&feedbacks).Error
+	if err != nil || len(feedbacks) == 0 {
+		return nil, err
+	}
+	return &feedbacks[0], nil
+}
+
 func (r *repository) GetPendingProposalsForReviewer(advisorID uint) ([]domain.Proposal, error) {
 	var proposals []domain.Proposal
 	// 👈 FIX: Look at proposals.advisor_id and deep preload for the UI
@@ -59,9 +115,217 @@ func (r *repository) GetPendingProposalsForReviewer(advisorID uint) ([]domain.Pr
 		Preload("Versions", func(db *gorm.DB) *gorm.DB {
 			return db.Order("version_number DESC")
 		}).
-		Where("advisor_id = ?", advisorID). // 👈 Proposal's assigned advisor
-		Where("status IN ?", []string{"submitted", "under_review", "revision_required", "approved", "rejected"}).
+		Where("advisor_id = ? OR (second_reviewer_id = ? AND status = ?)", // 👈 Proposal's assigned advisor, or its co-reviewer once the advisor approved
+			advisorID, advisorID, enums.ProposalStatusAwaitingSecondReview).
+		Where("status IN ?", []string{"submitted", "under_review", "revision_required", "awaiting_second_review", "approved", "rejected"}).
 		Find(&proposals).Error
 
 	return proposals, err
-}
\ No newline at end of file
+}
+
+// GetInboxProposals lists the advisor's assigned proposals outside archived cohorts, and those awaiting
+// their decision as co-reviewer, latest version first
+func (r *repository) GetInboxProposals(advisorID uint) ([]domain.Proposal, error) {
+	var proposals []domain.Proposal
+	err := r.db.
+		Preload("Team").
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("advisor_id = ? OR (second_reviewer_id = ? AND status = ?)",
+			advisorID, advisorID, enums.ProposalStatusAwaitingSecondReview).
+		Where("is_archived = ? AND status <> ?", false, enums.ProposalStatusDraft).
+		Find(&proposals).Error
+	return proposals, err
+}
+
+// GetFeedbackActivity counts the feedback on each proposal with its latest time and revision deadline
+func (r *repository) GetFeedbackActivity(proposalIDs []uint) (map[uint]FeedbackActivity, error) {
+	activity := make(map[uint]FeedbackActivity, len(proposalIDs))
+	if len(proposalIDs) == 0 {
+		return activity, nil
+	}
+
+	var rows []FeedbackActivity
+	err := r.db.Table("feedbacks").
+		Select(`feedbacks.proposal_id, COUNT(*) AS count, MAX(feedbacks.created_at) AS last_at,
+			(SELECT latest.resubmit_by FROM feedbacks AS latest WHERE latest.proposal_id = feedbacks.proposal_id
+				ORDER BY latest.created_at DESC LIMIT 1) AS resubmit_by`).
+		Where("feedbacks.proposal_id IN ?", proposalIDs).
+		Group("feedbacks.proposal_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		activity[row.ProposalID] = row
+	}
+	return activity, nil
+}
+
+func (r *repository) CreateTemplate(template *domain.FeedbackTemplate) error {
+	return r.db.Create(template).Error
+}
+
+// GetTemplates lists an advisor's templates, most used first
+func (r *repository) GetTemplates(advisorID uint, category string) ([]domain.FeedbackTemplate, error) {
+	var templates []domain.FeedbackTemplate
+	query := r.db.Where("advisor_id = ?", advisorID)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	err := query.Order("usage_count DESC, title ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (r *repository) GetTemplate(id uint) (*domain.FeedbackTemplate, error) {
+	var template domain.FeedbackTemplate
+	if err := r.db.First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *repository) GetTemplatesByIDs(advisorID uint, ids []uint) ([]domain.FeedbackTemplate, error) {
+	var templates []domain.FeedbackTemplate
+	err := r.db.Where("advisor_id = ? AND id IN ?", advisorID, ids).Find(&templates).Error
+	return templates, err
+}
+
+func (r *repository) UpdateTemplate(template *domain.FeedbackTemplate) error {
+	return r.db.Save(template).Error
+}
+
+func (r *repository) DeleteTemplate(id uint) error {
+	return r.db.Delete(&domain.FeedbackTemplate{}, id).Error
+}
+
+func (r *repository) IncrementTemplateUsage(ids []uint) error {
+	return r.db.Model(&domain.FeedbackTemplate{}).
+		Where("id IN ?", ids).
+		UpdateColumn("usage_count", gorm.Expr("usage_count + 1")).Error
+}
+
+// GetChecklistItems returns the department's own checklist, or the global one when departmentID is nil
+func (r *repository) GetChecklistItems(departmentID *uint) ([]domain.ReviewChecklistItem, error) {
+	var items []domain.ReviewChecklistItem
+	query := r.db.Order("position ASC, id ASC")
+	if departmentID == nil {
+		query = query.Where("department_id IS NULL")
+	} else {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	err := query.Find(&items).Error
+	return items, err
+}
+
+// ReplaceChecklist swaps the department's checklist for items; no items reverts it to the global one
+func (r *repository) ReplaceChecklist(departmentID uint, items []domain.ReviewChecklistItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("department_id = ?", departmentID).Delete(&domain.ReviewChecklistItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *repository) EnsureGlobalChecklistItem(item domain.ReviewChecklistItem) error {
+	var count int64
+	if err := r.db.Model(&domain.ReviewChecklistItem{}).
+		Where("department_id IS NULL AND key = ?", item.Key).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	item.DepartmentID = nil
+	return r.db.Create(&item).Error
+}
+
+// GetOpenRevisionDeadlines returns revision requests with a deadline that is neither missed nor answered:
+// the proposal still awaits revision and no later feedback replaced the request
+func (r *repository) GetOpenRevisionDeadlines() ([]domain.Feedback, error) {
+	var feedbacks []domain.Feedback
+	err := r.db.
+		Preload("Proposal.Team.Members").
+		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Joins("JOIN proposals ON proposals.id = feedbacks.proposal_id").
+		Where("feedbacks.decision = ? AND feedbacks.resubmit_by IS NOT NULL AND feedbacks.deadline_missed_at IS NULL", domain.FeedbackDecisionRevise).
+		Where("proposals.status = ?", enums.ProposalStatusRevisionRequired).
+		Where("feedbacks.id = (SELECT MAX(latest.id) FROM feedbacks latest WHERE latest.proposal_id = feedbacks.proposal_id)").
+		Find(&feedbacks).Error
+	return feedbacks, err
+}
+
+func (r *repository) SetDeadlineReminders(id uint, sent int) error {
+	return r.db.Model(&domain.Feedback{}).Where("id = ?", id).Update("deadline_reminders", sent).Error
+}
+
+func (r *repository) MarkDeadlineMissed(id uint, at time.Time) error {
+	return r.db.Model(&domain.Feedback{}).Where("id = ?", id).Update("deadline_missed_at", at).Error
+}
+
+func (r *repository) GetDepartmentAdminIDs(departmentID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.User{}).
+		Where("role = ? AND department_id = ? AND is_active = ?", enums.RoleAdmin, departmentID, true).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) CreateSecondOpinion(opinion *domain.SecondOpinion) error {
+	return r.db.Create(opinion).Error
+}
+
+func (r *repository) GetSecondOpinion(id uint) (*domain.SecondOpinion, error) {
+	var opinion domain.SecondOpinion
+	if err := r.db.Preload("Requester").Preload("Reviewer").First(&opinion, id).Error; err != nil {
+		return nil, err
+	}
+	return &opinion, nil
+}
+
+func (r *repository) UpdateSecondOpinion(opinion *domain.SecondOpinion) error {
+	return r.db.Omit("Requester", "Reviewer").Save(opinion).Error
+}
+
+func (r *repository) GetSecondOpinionsByProposal(proposalID uint) ([]domain.SecondOpinion, error) {
+	var opinions []domain.SecondOpinion
+	err := r.db.Preload("Requester").Preload("Reviewer").
+		Where("proposal_id = ?", proposalID).
+		Order("created_at DESC").
+		Find(&opinions).Error
+	return opinions, err
+}
+
+func (r *repository) GetSecondOpinionsForReviewer(reviewerID uint, status string) ([]domain.SecondOpinion, error) {
+	var opinions []domain.SecondOpinion
+	query := r.db.Preload("Requester").Where("reviewer_id = ?", reviewerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&opinions).Error
+	return opinions, err
+}
+
+func (r *repository) HasOpenSecondOpinion(versionID, reviewerID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.SecondOpinion{}).
+		Where("proposal_version_id = ? AND reviewer_id = ? AND status = ?", versionID, reviewerID, domain.SecondOpinionPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
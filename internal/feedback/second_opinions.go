@@ -0,0 +1,181 @@
+package feedback
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSecondOpinionNotFound = errors.New("second opinion request not found")
+	ErrSecondOpinionClosed   = errors.New("second opinion request has already been answered")
+)
+
+// RequestSecondOpinionRequest invites another teacher of the department to review a version
+type RequestSecondOpinionRequest struct {
+	ProposalID        uint   `json:"proposal_id" binding:"required"`
+	ProposalVersionID uint   `json:"proposal_version_id" binding:"required"`
+	ReviewerID        uint   `json:"reviewer_id" binding:"required"`
+	Message           string `json:"message"` // what the advisor would like the reviewer to look at
+}
+
+// AnswerSecondOpinionRequest is the invited reviewer's answer; declining needs no comment
+type AnswerSecondOpinionRequest struct {
+	Comment string `json:"comment"`
+	Decline bool   `json:"decline"`
+}
+
+// RequestSecondOpinion lets the assigned advisor ask a department colleague to review one version.
+// The reviewer can read the proposal while the request is open.
+func (s *Service) RequestSecondOpinion(req RequestSecondOpinionRequest, advisorID uint) (*domain.SecondOpinion, error) {
+	proposal, err := s.proposalRepo.GetByID(req.ProposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if proposal.AdvisorID == nil || *proposal.AdvisorID != advisorID {
+		return nil, errors.New("only the assigned advisor can request a second opinion")
+	}
+
+	var version *domain.ProposalVersion
+	for i := range proposal.Versions {
+		if proposal.Versions[i].ID == req.ProposalVersionID {
+			version = &proposal.Versions[i]
+			break
+		}
+	}
+	if version == nil {
+		return nil, errors.New("version does not belong to this proposal")
+	}
+
+	if req.ReviewerID == advisorID {
+		return nil, errors.New("cannot request a second opinion from yourself")
+	}
+	reviewer, err := s.repo.GetUser(req.ReviewerID)
+	if err != nil {
+		return nil, errors.New("reviewer not found")
+	}
+	if reviewer.Role != enums.RoleAdvisor || !reviewer.IsActive {
+		return nil, errors.New("reviewer must be an active teacher")
+	}
+	if proposal.Team == nil || reviewer.DepartmentID != proposal.Team.DepartmentID {
+		return nil, errors.New("reviewer must belong to the proposal's department")
+	}
+
+	open, err := s.repo.HasOpenSecondOpinion(version.ID, reviewer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, errors.New("a second opinion on this version is already pending with this reviewer")
+	}
+
+	opinion := &domain.SecondOpinion{
+		ProposalID:        proposal.ID,
+		ProposalVersionID: version.ID,
+		RequestedBy:       advisorID,
+		ReviewerID:        reviewer.ID,
+		Message:           strings.TrimSpace(req.Message),
+		Status:            domain.SecondOpinionPending,
+	}
+	if err := s.repo.CreateSecondOpinion(opinion); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.SecondOpinionRequested,
+		EntityType: "proposal",
+		EntityID:   proposal.ID,
+		ActorID:    advisorID,
+		UserIDs:    []uint{reviewer.ID},
+		Data: map[string]interface{}{
+			"second_opinion_id": opinion.ID,
+			"version":           version.VersionNumber,
+			"title":             version.Title,
+		},
+	})
+	return opinion, nil
+}
+
+// AnswerSecondOpinion records the invited reviewer's comment, or their refusal.
+// Either way the request closes and the reviewer's read access ends.
+func (s *Service) AnswerSecondOpinion(id uint, reviewerID uint, req AnswerSecondOpinionRequest) (*domain.SecondOpinion, error) {
+	opinion, err := s.repo.GetSecondOpinion(id)
+	if err != nil || opinion.ReviewerID != reviewerID {
+		return nil, ErrSecondOpinionNotFound
+	}
+	if opinion.Status != domain.SecondOpinionPending {
+		return nil, ErrSecondOpinionClosed
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if !req.Decline && comment == "" {
+		return nil, errors.New("comment is required")
+	}
+
+	now := time.Now()
+	opinion.Comment = comment
+	opinion.RespondedAt = &now
+	opinion.Status = domain.SecondOpinionAnswered
+	if req.Decline {
+		opinion.Status = domain.SecondOpinionDeclined
+	}
+	if err := s.repo.UpdateSecondOpinion(opinion); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.SecondOpinionAnswered,
+		EntityType: "proposal",
+		EntityID:   opinion.ProposalID,
+		ActorID:    reviewerID,
+		UserIDs:    []uint{opinion.RequestedBy},
+		Data: map[string]interface{}{
+			"second_opinion_id": opinion.ID,
+			"status":            string(opinion.Status),
+			"reviewer_name":     opinion.Reviewer.Name,
+		},
+	})
+	return opinion, nil
+}
+
+// GetSecondOpinionRequests lists the requests addressed to a reviewer, optionally by status
+func (s *Service) GetSecondOpinionRequests(reviewerID uint, status string) ([]domain.SecondOpinion, error) {
+	return s.repo.GetSecondOpinionsForReviewer(reviewerID, status)
+}
+
+// GetProposalSecondOpinions lists every second opinion on a proposal for its assigned advisor
+func (s *Service) GetProposalSecondOpinions(proposalID uint, advisorID uint) ([]domain.SecondOpinion, error) {
+	proposal, err := s.proposalRepo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if proposal.AdvisorID == nil || *proposal.AdvisorID != advisorID {
+		return nil, errors.New("only the assigned advisor can view second opinion requests")
+	}
+	return s.repo.GetSecondOpinionsByProposal(proposalID)
+}
+
+// ProposalFeedback is a proposal's feedback history with the answered second opinions by version.
+// A version can have second opinions before, or without, any feedback of its own.
+type ProposalFeedback struct {
+	Feedback       []domain.Feedback               `json:"feedback"`
+	SecondOpinions map[uint][]domain.SecondOpinion `json:"second_opinions"` // keyed by proposal version ID
+}
+
+// answeredSecondOpinions groups the proposal's answered second opinions by version
+func (s *Service) answeredSecondOpinions(proposalID uint) (map[uint][]domain.SecondOpinion, error) {
+	opinions, err := s.repo.GetSecondOpinionsByProposal(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint][]domain.SecondOpinion)
+	for _, opinion := range opinions {
+		if opinion.Status == domain.SecondOpinionAnswered {
+			byVersion[opinion.ProposalVersionID] = append(byVersion[opinion.ProposalVersionID], opinion)
+		}
+	}
+	return byVersion, nil
+}
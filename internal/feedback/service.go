@@ -2,41 +2,87 @@ package feedback
 
 import (
 	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/internal/projects"
+	"backend/internal/proposals"
 	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/uow"
 	"errors"
-
-	"gorm.io/gorm" 
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"time"
 )
 
 type Service struct {
 	repo         Repository
-	proposalRepo ProposalRepository
+	proposalRepo proposals.Repository
+	projectRepo  projects.Repository
+	work         uow.UnitOfWork
+	bus          *events.Bus
+	uploader     *files.Uploader
 }
 
-// Ensure this matches your proposals.Repository interface
-type ProposalRepository interface {
-	GetByID(id uint) (*domain.Proposal, error)
-	Update(proposal *domain.Proposal) error
+// NewService wires the feedback service. A decision writes to the feedback, proposal and project
+// repositories in one unit of work, so approving never leaves a proposal without its project.
+func NewService(repo Repository, proposalRepo proposals.Repository, projectRepo projects.Repository, work uow.UnitOfWork, bus *events.Bus, uploader *files.Uploader) *Service {
+	return &Service{repo: repo, proposalRepo: proposalRepo, projectRepo: projectRepo, work: work, bus: bus, uploader: uploader}
 }
 
-func NewService(repo Repository, proposalRepo ProposalRepository) *Service {
-	return &Service{repo: repo, proposalRepo: proposalRepo}
-}
+// MaxTimeSpentMinutes bounds the time an advisor reports spending on one review
+const MaxTimeSpentMinutes = 24 * 60
 
+// CreateFeedbackRequest is sent as JSON, or as multipart form data when files are attached; the
+// checklist is then a JSON-encoded form field
 type CreateFeedbackRequest struct {
-	ProposalID        uint   `json:"proposal_id" binding:"required"`
-	ProposalVersionID uint   `json:"proposal_version_id" binding:"required"`
-	Decision          string `json:"decision" binding:"required"` // approve, revise, reject
-	Comment           string `json:"comment" binding:"required"`
+	ProposalID        uint            `json:"proposal_id" form:"proposal_id" binding:"required"`
+	ProposalVersionID uint            `json:"proposal_version_id" form:"proposal_version_id" binding:"required"`
+	Decision          string          `json:"decision" form:"decision" binding:"required"`                            // approve, revise, reject
+	Comment           string          `json:"comment" form:"comment"`                                                 // required unless templates are used
+	TemplateIDs       []uint          `json:"template_ids" form:"template_ids"`                                       // saved snippets inserted ahead of the comment
+	Checklist         map[string]bool `json:"checklist" form:"-"`                                                     // review checklist answers by item key; mandatory items gate approval
+	ResubmitBy        *time.Time      `json:"resubmit_by" form:"resubmit_by" time_format:"2006-01-02T15:04:05Z07:00"` // optional revision deadline; the team is reminded 3 days and 1 day before
+	TimeSpentMinutes  *int            `json:"time_spent_minutes" form:"time_spent_minutes" binding:"omitempty,gt=0"`  // optional, at most MaxTimeSpentMinutes
 }
-func (s *Service) CreateFeedback(req CreateFeedbackRequest, reviewerID uint) (*domain.Feedback, error) {
+
+// CreateFeedback records the advisor's decision. Attached files are stored with the feedback and
+// removed again if it cannot be saved.
+func (s *Service) CreateFeedback(req CreateFeedbackRequest, uploads []*multipart.FileHeader, reviewerID uint) (*domain.Feedback, error) {
 	// 1. Get proposal
 	proposal, err := s.proposalRepo.GetByID(req.ProposalID)
 	if err != nil { return nil, errors.New("proposal not found") }
 
-	// 2. Security Check
-	if proposal.AdvisorID == nil || *proposal.AdvisorID != reviewerID {
-		return nil, errors.New("only the assigned advisor can review this proposal")
+	// 2. Security Check: the assigned advisor decides, then the co-reviewer when there is one
+	if err := checkDecider(proposal, reviewerID); err != nil {
+		return nil, err
+	}
+	if req.Decision == "approve" {
+		if err := s.checkCoReviewApproval(proposal, reviewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateResubmitBy(req.ResubmitBy, req.Decision); err != nil {
+		return nil, err
+	}
+	if req.TimeSpentMinutes != nil && *req.TimeSpentMinutes > MaxTimeSpentMinutes {
+		return nil, fmt.Errorf("time spent is limited to %d minutes", MaxTimeSpentMinutes)
+	}
+
+	comment, err := s.composeComment(req.Comment, req.TemplateIDs, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	checklist, err := s.completeChecklist(proposal, req.Checklist, req.Decision)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.prepareAttachments(req.ProposalID, uploads)
+	if err != nil {
+		return nil, err
 	}
 
 	feedback := &domain.Feedback{
@@ -44,11 +90,22 @@ func (s *Service) CreateFeedback(req CreateFeedbackRequest, reviewerID uint) (*d
 		ProposalVersionID: req.ProposalVersionID,
 		ReviewerID:        reviewerID,
 		Decision:          domain.FeedbackDecision(req.Decision),
-		Comment:           req.Comment,
+		Comment:           comment,
+		Checklist:         checklist,
+		ResubmitBy:        req.ResubmitBy,
+		Attachments:       attachments,
+		TimeSpentMinutes:  req.TimeSpentMinutes,
+		WordCount:         len(strings.Fields(req.Comment)), // the advisor's own words, without the templates
 	}
 
 	// 3. Handle Decision
-	if req.Decision == "approve" {
+	if req.Decision == "approve" && needsSecondReview(proposal) {
+		if err := s.requestSecondReview(proposal, feedback); err != nil {
+			s.removeAttachments(attachments)
+			return nil, err
+		}
+
+	} else if req.Decision == "approve" {
 		// 🚨 SAFETY CHECKS (Prevents Panic)
 		if proposal.TeamID == nil {
 			return nil, errors.New("cannot approve: proposal is not linked to a team")
@@ -57,59 +114,132 @@ func (s *Service) CreateFeedback(req CreateFeedbackRequest, reviewerID uint) (*d
 			return nil, errors.New("cannot approve: team data failed to load")
 		}
 
-		var versionAbstract string
+		var versionAbstract, versionTitle string
 		for _, v := range proposal.Versions {
 			if v.ID == req.ProposalVersionID {
 				versionAbstract = v.Abstract
+				versionTitle = v.Title
 			}
 		}
 
-		// Run Transaction
-		err = s.repo.GetDB().Transaction(func(tx *gorm.DB) error {
-			if err := tx.Create(feedback).Error; err != nil { return err }
-
-			// Update Status
-			if err := tx.Model(&domain.Proposal{}).Where("id = ?", proposal.ID).Update("status", enums.ProposalStatusApproved).Error; err != nil { return err }
-
-			// Mark version approved
-			if err := tx.Model(&domain.ProposalVersion{}).Where("id = ?", req.ProposalVersionID).Update("is_approved", true).Error; err != nil { return err }
+		err = s.work.Do(func(tx *uow.Tx) error {
+			if err := s.repo.WithTx(tx.DB()).Create(feedback); err != nil {
+				return err
+			}
+			proposalRepo := s.proposalRepo.WithTx(tx.DB())
+			if err := proposalRepo.SetStatus(proposal.ID, enums.ProposalStatusApproved); err != nil {
+				return err
+			}
+			if err := proposalRepo.ApproveVersion(req.ProposalVersionID); err != nil {
+				return err
+			}
 
-			// Create Project
 			project := &domain.Project{
 				ProposalID:   proposal.ID,
-				TeamID:       *proposal.TeamID, // Now safe to dereference
-				DepartmentID: proposal.Team.DepartmentID, // Now safe
+				TeamID:       *proposal.TeamID,
+				DepartmentID: proposal.Team.DepartmentID,
 				Summary:      versionAbstract,
 				ApprovedBy:   reviewerID,
 				Visibility:   "private",
 			}
-			return tx.Create(project).Error
+			if err := s.projectRepo.WithTx(tx.DB()).Create(project); err != nil {
+				return err
+			}
+
+			tx.Publish(events.Event{
+				Name:       events.ProposalApproved,
+				EntityType: "proposal",
+				EntityID:   proposal.ID,
+				ActorID:    reviewerID,
+				UserIDs:    teamMemberIDs(proposal),
+				Data: map[string]interface{}{
+					"feedback_id":   feedback.ID,
+					"version_id":    req.ProposalVersionID,
+					"project_id":    project.ID,
+					"department_id": project.DepartmentID,
+					"title":         versionTitle,
+					"summary":       versionAbstract,
+				},
+			})
+			return nil
 		})
-		if err != nil { return nil, err }
+		if err != nil {
+			s.removeAttachments(attachments)
+			return nil, err
+		}
 
 	} else {
 		// Logic for Revise/Reject
-		if err := s.repo.Create(feedback); err != nil { return nil, err }
-		
 		newStatus := enums.ProposalStatusRejected
+		eventName := events.ProposalRejected
 		if req.Decision == "revise" {
 			newStatus = enums.ProposalStatusRevisionRequired
+			eventName = events.ProposalRevisionRequest
+		}
+
+		err = s.work.Do(func(tx *uow.Tx) error {
+			if err := s.repo.WithTx(tx.DB()).Create(feedback); err != nil {
+				return err
+			}
+			if err := s.proposalRepo.WithTx(tx.DB()).SetStatus(req.ProposalID, newStatus); err != nil {
+				return err
+			}
+
+			tx.Publish(events.Event{
+				Name:       eventName,
+				EntityType: "proposal",
+				EntityID:   proposal.ID,
+				ActorID:    reviewerID,
+				UserIDs:    teamMemberIDs(proposal),
+				Data: map[string]interface{}{
+					"feedback_id": feedback.ID,
+					"version_id":  req.ProposalVersionID,
+					"resubmit_by": feedback.ResubmitBy,
+				},
+			})
+			return nil
+		})
+		if err != nil {
+			s.removeAttachments(attachments)
+			return nil, err
 		}
-		
-		if err := s.repo.GetDB().Model(&domain.Proposal{}).Where("id = ?", req.ProposalID).Update("status", newStatus).Error; err != nil { return nil, err }
 	}
 
+	if len(req.TemplateIDs) > 0 {
+		s.repo.IncrementTemplateUsage(req.TemplateIDs)
+	}
+
+	withDownloadURLs(feedback)
 	return feedback, nil
 }
 
-// Helper to update status
-func txUpdateStatus(db *gorm.DB, id uint, status enums.ProposalStatus) error {
-	return db.Model(&domain.Proposal{}).Where("id = ?", id).Update("status", status).Error
+func teamMemberIDs(proposal *domain.Proposal) []uint {
+	if proposal.Team == nil {
+		return []uint{proposal.CreatedBy}
+	}
+	var ids []uint
+	for _, m := range proposal.Team.Members {
+		if m.InvitationStatus == enums.InvitationStatusAccepted {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
 }
 
-func (s *Service) GetProposalFeedback(proposalID uint, userID uint) ([]domain.Feedback, error) {
+func (s *Service) GetProposalFeedback(proposalID uint, userID uint) (*ProposalFeedback, error) {
 	// Logic: Fetch all feedback for this proposal
-	return s.repo.GetByProposalID(proposalID)
+	feedbacks, err := s.repo.GetByProposalID(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	opinions, err := s.answeredSecondOpinions(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range feedbacks {
+		withDownloadURLs(&feedbacks[i])
+	}
+	return &ProposalFeedback{Feedback: feedbacks, SecondOpinions: opinions}, nil
 }
 
 func (s *Service) GetPendingProposals(reviewerID uint) ([]domain.Proposal, error) {
@@ -117,5 +247,10 @@ func (s *Service) GetPendingProposals(reviewerID uint) ([]domain.Proposal, error
 }
 
 func (s *Service) GetFeedbackByID(id uint) (*domain.Feedback, error) {
-	return s.repo.GetByID(id)
+	feedback, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	withDownloadURLs(feedback)
+	return feedback, nil
 }
\ No newline at end of file
@@ -0,0 +1,104 @@
+package feedback
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"strings"
+)
+
+type FeedbackTemplateRequest struct {
+	Title    string `json:"title" binding:"required,max=100"`
+	Body     string `json:"body" binding:"required"`
+	Category string `json:"category" binding:"max=50"`
+}
+
+// GetTemplates lists the advisor's saved comment snippets, optionally for one category
+func (s *Service) GetTemplates(advisorID uint, category string) ([]domain.FeedbackTemplate, error) {
+	return s.repo.GetTemplates(advisorID, strings.TrimSpace(category))
+}
+
+func (s *Service) CreateTemplate(req FeedbackTemplateRequest, advisorID uint) (*domain.FeedbackTemplate, error) {
+	template := &domain.FeedbackTemplate{AdvisorID: advisorID}
+	if err := applyTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *Service) UpdateTemplate(id uint, req FeedbackTemplateRequest, advisorID uint) (*domain.FeedbackTemplate, error) {
+	template, err := s.ownTemplate(id, advisorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateTemplate(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *Service) DeleteTemplate(id uint, advisorID uint) error {
+	if _, err := s.ownTemplate(id, advisorID); err != nil {
+		return err
+	}
+	return s.repo.DeleteTemplate(id)
+}
+
+// ownTemplate loads a template; other advisors' templates are reported as missing
+func (s *Service) ownTemplate(id uint, advisorID uint) (*domain.FeedbackTemplate, error) {
+	template, err := s.repo.GetTemplate(id)
+	if err != nil || template.AdvisorID != advisorID {
+		return nil, errors.New("template not found")
+	}
+	return template, nil
+}
+
+func applyTemplateRequest(template *domain.FeedbackTemplate, req FeedbackTemplateRequest) error {
+	title := strings.TrimSpace(req.Title)
+	body := strings.TrimSpace(req.Body)
+	if title == "" || body == "" {
+		return errors.New("title and body are required")
+	}
+	template.Title = title
+	template.Body = body
+	template.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	return nil
+}
+
+// composeComment puts the chosen template bodies, in the order given, ahead of the advisor's own text
+func (s *Service) composeComment(comment string, templateIDs []uint, advisorID uint) (string, error) {
+	comment = strings.TrimSpace(comment)
+	if len(templateIDs) == 0 {
+		if comment == "" {
+			return "", errors.New("comment is required")
+		}
+		return comment, nil
+	}
+
+	templates, err := s.repo.GetTemplatesByIDs(advisorID, templateIDs)
+	if err != nil {
+		return "", err
+	}
+	byID := make(map[uint]domain.FeedbackTemplate, len(templates))
+	for _, t := range templates {
+		byID[t.ID] = t
+	}
+
+	var parts []string
+	for _, id := range templateIDs {
+		t, ok := byID[id]
+		if !ok {
+			return "", errors.New("template not found")
+		}
+		parts = append(parts, t.Body)
+	}
+	if comment != "" {
+		parts = append(parts, comment)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
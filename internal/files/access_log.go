@@ -0,0 +1,163 @@
+package files
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"backend/pkg/response"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileAccess is one download of a proposal's file, as shown to the team leader and advisor
+type FileAccess struct {
+	ID         uint      `json:"id"`
+	File       string    `json:"file"`
+	Kind       string    `json:"kind"`    // proposal_version or feedback_attachment
+	UserID     *uint     `json:"user_id"` // nil for students hidden by blind review
+	UserName   string    `json:"user_name,omitempty"`
+	UserRole   string    `json:"user_role,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"` // the team leader only sees their own
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// logDownload audits a file served by the handler. HEAD requests, revalidations answered with
+// 304 and the follow-up ranges of a resumed or streamed download are not separate accesses.
+func (h *Handler) logDownload(c *gin.Context, entityType string, entityID uint, file map[string]interface{}) {
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+	if status := c.Writer.Status(); status != http.StatusOK && status != http.StatusPartialContent {
+		return
+	}
+	if r := c.GetHeader("Range"); r != "" && !strings.HasPrefix(r, "bytes=0-") {
+		return
+	}
+
+	var actorID, impersonatorID *uint
+	var role, email string
+	if claims, ok := c.Get("claims"); ok {
+		userClaims := claims.(*auth.TokenClaims)
+		actorID = &userClaims.UserID
+		role, email = string(userClaims.Role), userClaims.Email
+		if userClaims.ImpersonatorID != 0 {
+			impersonatorID = &userClaims.ImpersonatorID
+		}
+	}
+	if err := h.audit.LogFileDownload(entityType, entityID, file, actorID, role, email, impersonatorID,
+		c.ClientIP(), c.GetHeader("User-Agent"), c.GetString("request_id")); err != nil {
+		log.Printf("failed to audit the download of %v from %s %d: %v", file["file"], entityType, entityID, err)
+	}
+}
+
+// GetProposalFileAccess godoc
+// @Summary Who downloaded a proposal's files
+// @Description Every download of the proposal's documents and feedback attachments, newest first: who, which file, when and from which IP. Open to the team leader and the proposal's advisor. The leader only sees the IP addresses of their own downloads; under blind review the advisor sees students' downloads without who made them until the proposal is decided.
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response "accesses ([]FileAccess) and pagination"
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/file-access [get]
+func (h *Handler) GetProposalFileAccess(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	proposalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid proposal ID", nil)
+		return
+	}
+
+	var proposal struct {
+		TeamID    *uint
+		AdvisorID *uint
+	}
+	if err := h.db.Table("proposals").Select("team_id, advisor_id").Where("id = ?", proposalID).First(&proposal).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Proposal not found", nil)
+		return
+	}
+	isAdvisor := proposal.AdvisorID != nil && *proposal.AdvisorID == userClaims.UserID
+	allowed := isAdvisor
+	if !allowed && proposal.TeamID != nil {
+		var count int64
+		h.db.Table("team_members").Where("team_id = ? AND user_id = ? AND role = ?", *proposal.TeamID, userClaims.UserID, "leader").Count(&count)
+		allowed = count > 0
+	}
+	if !allowed {
+		response.Error(c, http.StatusForbidden, "Only the team leader and the advisor can see who accessed the files", nil)
+		return
+	}
+
+	page, limit := 1, 20
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	logs, total, err := h.audit.GetAuditLogs(map[string]interface{}{
+		"entity_type": "proposal",
+		"entity_id":   uint(proposalID),
+		"action":      audit.ActionFileDownload,
+	}, limit, (page-1)*limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch the access log", err.Error())
+		return
+	}
+
+	hideStudents := isAdvisor && h.blind.BlindReviewApplies(userClaims.UserID, userClaims.Role) && h.blind.AwaitingDecision(uint(proposalID))
+
+	accesses := make([]FileAccess, 0, len(logs))
+	for _, entry := range logs {
+		var file struct {
+			File string `json:"file"`
+			Kind string `json:"kind"`
+		}
+		_ = json.Unmarshal([]byte(entry.NewState), &file)
+		access := FileAccess{
+			ID:         entry.ID,
+			File:       file.File,
+			Kind:       file.Kind,
+			UserID:     entry.ActorID,
+			UserRole:   entry.ActorRole,
+			IPAddress:  entry.IPAddress,
+			AccessedAt: entry.Timestamp,
+		}
+		if entry.Actor != nil {
+			access.UserName = entry.Actor.Name
+		}
+		own := entry.ActorID != nil && *entry.ActorID == userClaims.UserID
+		switch {
+		case hideStudents && entry.ActorRole == string(enums.RoleStudent):
+			access.UserID, access.UserName, access.IPAddress = nil, "", ""
+		case !isAdvisor && !own:
+			access.IPAddress = ""
+		}
+		accesses = append(accesses, access)
+	}
+
+	response.Success(c, gin.H{
+		"accesses": accesses,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
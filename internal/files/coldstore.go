@@ -0,0 +1,128 @@
+package files
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ColdStore keeps gzip-compressed copies of rarely read uploads outside the served upload
+// directory. Archiving moves a file there; restoring puts it back at its original path.
+type ColdStore struct {
+	Dir      string
+	uploader *Uploader
+}
+
+func NewColdStore(dir string, uploader *Uploader) *ColdStore {
+	_ = os.MkdirAll(dir, 0o750)
+	return &ColdStore{Dir: dir, uploader: uploader}
+}
+
+// Archive compresses the upload at relativeURL into the cold store and removes the original and
+// its preview. It returns the archive's path. Archiving a file that is already archived returns
+// its path again.
+func (s *ColdStore) Archive(relativeURL string) (string, error) {
+	source, archivePath, err := s.paths(relativeURL)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := os.Open(source)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if _, statErr := os.Stat(archivePath); statErr == nil {
+				return archivePath, nil
+			}
+		}
+		return "", err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0o750); err != nil {
+		return "", err
+	}
+	if err := writeAtomically(archivePath, func(w io.Writer) error {
+		gz := gzip.NewWriter(w)
+		if _, err := io.Copy(gz, src); err != nil {
+			return err
+		}
+		return gz.Close()
+	}); err != nil {
+		return "", err
+	}
+
+	_ = os.Remove(source + previewSuffix)
+	if err := os.Remove(source); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return archivePath, nil
+}
+
+// Restore decompresses an archive back to the upload at relativeURL and removes the archive
+func (s *ColdStore) Restore(archivePath, relativeURL string) error {
+	target, expected, err := s.paths(relativeURL)
+	if err != nil {
+		return err
+	}
+	if filepath.Clean(archivePath) != expected {
+		return errors.New("archive does not belong to this file")
+	}
+
+	src, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	if err := writeAtomically(target, func(w io.Writer) error {
+		_, err := io.Copy(w, gz)
+		return err
+	}); err != nil {
+		return err
+	}
+	return os.Remove(archivePath)
+}
+
+// paths resolves an upload URL such as "uploads/proposals/3/x.pdf" to the file on disk and its
+// place in the cold store, refusing URLs that leave the upload directory
+func (s *ColdStore) paths(relativeURL string) (string, string, error) {
+	clean := filepath.Clean(relativeURL)
+	if filepath.IsAbs(clean) || clean == "." || strings.HasPrefix(clean, "..") {
+		return "", "", errors.New("invalid file path")
+	}
+	return s.uploader.LocalPath(clean), filepath.Join(s.Dir, clean+".gz"), nil
+}
+
+// writeAtomically writes to a temporary file next to path and renames it into place once the
+// content is synced, so a crash never leaves a partial file under the final name
+func writeAtomically(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -2,10 +2,13 @@ package files
 
 import (
 	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/pkg/audit"
 	"backend/pkg/enums"
 	"backend/pkg/response"
+	"fmt"
+	"mime"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 
@@ -14,24 +17,46 @@ import (
 )
 
 type Handler struct {
-	db *gorm.DB
+	db         *gorm.DB
+	quota      *Quota
+	quarantine *Quarantine
+	uploader   *Uploader
+	audit      *audit.Logger
+	blind      BlindReview
 }
 
-func NewHandler(db *gorm.DB) *Handler {
-	return &Handler{db: db}
+// BlindReview decides whether an advisor reviews a proposal without seeing its students;
+// implemented by the proposal service
+type BlindReview interface {
+	BlindReviewApplies(userID uint, role enums.Role) bool
+	AwaitingDecision(proposalID uint) bool
+}
+
+func NewHandler(db *gorm.DB, quota *Quota, quarantine *Quarantine, uploader *Uploader, auditLogger *audit.Logger, blind BlindReview) *Handler {
+	return &Handler{db: db, quota: quota, quarantine: quarantine, uploader: uploader, audit: auditLogger, blind: blind}
 }
 
 // DownloadProposalFile godoc
 // @Summary Download proposal document
-// @Description Download a file from a proposal (access controlled)
+// @Description Download a file from a proposal (access controlled). The ETag is the file's content hash; conditional requests get 304 and Range requests get 206. Downloads are audited.
 // @Tags Files
 // @Produce application/octet-stream
 // @Security BearerAuth
 // @Param proposal_id path int true "Proposal ID"
 // @Param filename path string true "Filename"
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304
+// @Header 200 {string} ETag "Content hash of the file"
+// @Header 200 {string} Last-Modified "When the file was stored"
+// @Header 200 {string} X-Detected-Content-Type "Content type sniffed at upload"
+// @Header 200 {string} X-Page-Count "Page count for PDFs"
+// @Header 200 {string} X-Scan-Status "pending, clean, infected, failed or released"
 // @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 423 {object} response.ErrorResponse "The file is quarantined until it passes the virus scan"
 // @Router /files/proposals/{proposal_id}/{filename} [get]
 func (h *Handler) DownloadProposalFile(c *gin.Context) {
 	claims, exists := c.Get("claims")
@@ -48,6 +73,10 @@ func (h *Handler) DownloadProposalFile(c *gin.Context) {
 	}
 
 	filename := c.Param("filename")
+	if !validFilename(filename) {
+		response.Error(c, http.StatusBadRequest, "Invalid filename", nil)
+		return
+	}
 
 	// Check access permission
 	hasAccess, err := h.checkProposalAccess(uint(proposalID), userClaims)
@@ -56,29 +85,162 @@ func (h *Handler) DownloadProposalFile(c *gin.Context) {
 		return
 	}
 
+	// Files moved to cold storage by the retention policy have to be restored first
+	if restoreURL, archived := h.archivedVersionFile(uint(proposalID), filename); archived {
+		response.Error(c, http.StatusGone, "This file was moved to cold storage; restore it to download it", gin.H{
+			"restore_url": restoreURL,
+		})
+		return
+	}
+
 	// Construct file path
 	filePath := filepath.Join("uploads", "proposals", strconv.FormatUint(proposalID, 10), filename)
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		response.Error(c, http.StatusNotFound, "File not found", nil)
+	// Serve file; the version's content hash is the ETag
+	var fileHash string
+	h.db.Table("proposal_versions").
+		Select("file_hash").
+		Where("proposal_id = ? AND file_url LIKE ?", proposalID, storedAs(filename)).
+		Order("id DESC").
+		Limit(1).
+		Scan(&fileHash)
+	h.setMetadataHeaders(c, "proposal_versions", "proposal_id = ? AND file_url LIKE ?", proposalID, storedAs(filename))
+	// Replaced drafts are checked too, so the stored path is looked up rather than the latest version
+	if h.quarantine.Quarantined(filePath) {
+		response.Error(c, http.StatusLocked, ErrQuarantinedFile.Error(), nil)
+		return
+	}
+	serveFile(c, h.uploader.LocalPath(filePath), fileHash, privateCacheControl)
+	h.logDownload(c, "proposal", uint(proposalID), map[string]interface{}{"file": filename, "kind": "proposal_version"})
+}
+
+// archivedVersionFile looks the file up among the proposal's version files and replaced drafts and
+// reports whether it is in cold storage, with the endpoint that restores it
+func (h *Handler) archivedVersionFile(proposalID uint, filename string) (string, bool) {
+	var file struct {
+		VersionID uint
+		FileID    uint
+	}
+	h.db.Raw(`
+		SELECT id AS version_id, 0 AS file_id FROM proposal_versions
+		WHERE proposal_id = ? AND file_url LIKE ? AND archived_at IS NOT NULL
+		UNION ALL
+		SELECT version_id, id AS file_id FROM proposal_version_files
+		WHERE proposal_id = ? AND file_url LIKE ? AND archived_at IS NOT NULL
+		LIMIT 1`,
+		proposalID, storedAs(filename), proposalID, storedAs(filename)).
+		Scan(&file)
+	if file.VersionID == 0 {
+		return "", false
+	}
+	restoreURL := fmt.Sprintf("/api/v1/proposals/%d/versions/%d/restore-file", proposalID, file.VersionID)
+	if file.FileID != 0 {
+		restoreURL += fmt.Sprintf("?file_id=%d", file.FileID)
+	}
+	return restoreURL, true
+}
+
+// DownloadFeedbackAttachment godoc
+// @Summary Download a feedback attachment
+// @Description Download an annotated PDF or image attached to feedback. Only people with access to the proposal can download it; attachments are not served from the public uploads directory. Downloads are audited.
+// @Tags Files
+// @Produce application/octet-stream
+// @Security BearerAuth
+// @Param feedback_id path int true "Feedback ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304
+// @Header 200 {string} Content-Disposition "The attachment's original file name"
+// @Header 200 {string} X-Detected-Content-Type "Content type sniffed at upload"
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 423 {object} response.ErrorResponse "The file is quarantined until it passes the virus scan"
+// @Router /files/feedback/{feedback_id}/{attachment_id} [get]
+func (h *Handler) DownloadFeedbackAttachment(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	feedbackID, err := strconv.ParseUint(c.Param("feedback_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid feedback ID", nil)
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid attachment ID", nil)
+		return
+	}
+
+	var attachment struct {
+		ProposalID   uint
+		FileName     string
+		Path         string
+		DetectedMIME string
+		PageCount    *int
+		ScanStatus   enums.ScanStatus
+	}
+	if err := h.db.Table("feedback_attachments").
+		Select("feedbacks.proposal_id, feedback_attachments.file_name, feedback_attachments.path, feedback_attachments.detected_mime, feedback_attachments.page_count, feedback_attachments.scan_status").
+		Joins("JOIN feedbacks ON feedbacks.id = feedback_attachments.feedback_id").
+		Where("feedback_attachments.id = ? AND feedback_attachments.feedback_id = ?", attachmentID, feedbackID).
+		Take(&attachment).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Attachment not found", nil)
 		return
 	}
 
-	// Serve file
-	c.File(filePath)
+	hasAccess, err := h.checkProposalAccess(attachment.ProposalID, userClaims)
+	if err != nil || !hasAccess {
+		response.Error(c, http.StatusForbidden, "You don't have access to this file", nil)
+		return
+	}
+
+	if attachment.ScanStatus.Quarantined() {
+		response.Error(c, http.StatusLocked, ErrQuarantinedFile.Error(), nil)
+		return
+	}
+
+	if attachment.DetectedMIME != "" {
+		c.Header("X-Detected-Content-Type", attachment.DetectedMIME)
+	}
+	if attachment.PageCount != nil {
+		c.Header("X-Page-Count", strconv.Itoa(*attachment.PageCount))
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	serveFile(c, h.uploader.LocalPath(attachment.Path), "", privateCacheControl)
+	h.logDownload(c, "proposal", attachment.ProposalID, map[string]interface{}{
+		"file":          attachment.FileName,
+		"kind":          "feedback_attachment",
+		"feedback_id":   feedbackID,
+		"attachment_id": attachmentID,
+	})
 }
 
 // DownloadProjectFile godoc
 // @Summary Download project document
-// @Description Download a file from a project (public projects accessible to all)
+// @Description Download a file from a project (public projects accessible to all). Public project files may be cached by browsers and proxies for an hour; conditional requests get 304 and Range requests get 206. Downloads are audited, anonymous ones included.
 // @Tags Files
 // @Produce application/octet-stream
 // @Param project_id path int true "Project ID"
 // @Param filename path string true "Filename"
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304
+// @Header 200 {string} ETag "Content hash of the file"
+// @Header 200 {string} Cache-Control "public for public projects, private otherwise"
+// @Header 200 {string} X-Detected-Content-Type "Content type sniffed at upload"
+// @Header 200 {string} X-Page-Count "Page count for PDFs"
+// @Header 200 {string} X-Scan-Status "pending, clean, infected, failed or released"
 // @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 423 {object} response.ErrorResponse "The file is quarantined until it passes the virus scan"
 // @Router /files/projects/{project_id}/{filename} [get]
 func (h *Handler) DownloadProjectFile(c *gin.Context) {
 	projectID, err := strconv.ParseUint(c.Param("project_id"), 10, 32)
@@ -88,6 +250,10 @@ func (h *Handler) DownloadProjectFile(c *gin.Context) {
 	}
 
 	filename := c.Param("filename")
+	if !validFilename(filename) {
+		response.Error(c, http.StatusBadRequest, "Invalid filename", nil)
+		return
+	}
 
 	// Check if project is public or user has access
 	var project struct {
@@ -108,8 +274,7 @@ func (h *Handler) DownloadProjectFile(c *gin.Context) {
 		}
 		userClaims := claims.(*auth.TokenClaims)
 
-		hasAccess, _ := h.checkProjectAccess(uint(projectID), project.TeamID, userClaims)
-		if !hasAccess {
+		if !h.canReviewProject(uint(projectID), project.TeamID, userClaims) {
 			response.Error(c, http.StatusForbidden, "You don't have access to this file", nil)
 			return
 		}
@@ -118,25 +283,132 @@ func (h *Handler) DownloadProjectFile(c *gin.Context) {
 	// Construct file path
 	filePath := filepath.Join("uploads", "projects", strconv.FormatUint(projectID, 10), filename)
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		response.Error(c, http.StatusNotFound, "File not found", nil)
+	// Serve file; shared caches may keep public project files
+	cacheControl := privateCacheControl
+	if project.Visibility == "public" {
+		cacheControl = publicCacheControl()
+	}
+	meta := h.setMetadataHeaders(c, "project_documentations", "project_id = ? AND url LIKE ?", projectID, storedAs(filename))
+	if meta.ScanStatus.Quarantined() {
+		response.Error(c, http.StatusLocked, ErrQuarantinedFile.Error(), nil)
+		return
+	}
+	// The document's content hash is the ETag
+	var fileHash string
+	h.db.Table("project_documentations").
+		Select("file_hash").
+		Where("project_id = ? AND url LIKE ?", projectID, storedAs(filename)).
+		Order("id DESC").
+		Limit(1).
+		Scan(&fileHash)
+	serveFile(c, h.uploader.LocalPath(filePath), fileHash, cacheControl)
+	h.logDownload(c, "project", uint(projectID), map[string]interface{}{"file": filename, "kind": "project_document"})
+}
+
+// setMetadataHeaders exposes the stored file metadata (see Inspect) as response headers,
+// so a HEAD request tells the client what it is about to download, and returns it
+func (h *Handler) setMetadataHeaders(c *gin.Context, table string, query string, args ...interface{}) domain.FileMetadata {
+	var meta domain.FileMetadata
+	if err := h.db.Table(table).
+		Select("declared_content_type, detected_mime, page_count, scan_status").
+		Where(query, args...).
+		Order("id DESC").
+		Limit(1).
+		Scan(&meta).Error; err != nil {
+		return meta
+	}
+
+	if meta.DeclaredContentType != "" {
+		c.Header("X-Declared-Content-Type", meta.DeclaredContentType)
+	}
+	if meta.DetectedMIME != "" {
+		c.Header("X-Detected-Content-Type", meta.DetectedMIME)
+	}
+	if meta.PageCount != nil {
+		c.Header("X-Page-Count", strconv.Itoa(*meta.PageCount))
+	}
+	if meta.ScanStatus != "" {
+		c.Header("X-Scan-Status", string(meta.ScanStatus))
+	}
+	return meta
+}
+
+// GetTeamStorageUsage godoc
+// @Summary Get team storage usage
+// @Description Cumulative size of the team's proposal and documentation uploads against the team quota, with each member's usage against the per-user quota
+// @Tags Files
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=StorageUsage}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/storage-usage [get]
+func (h *Handler) GetTeamStorageUsage(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid team ID", nil)
+		return
+	}
+
+	hasAccess, err := h.checkTeamAccess(uint(teamID), userClaims)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Team not found", nil)
+		return
+	}
+	if !hasAccess {
+		response.Error(c, http.StatusForbidden, "You don't have access to this team", nil)
 		return
 	}
 
-	// Serve file
-	c.File(filePath)
+	usage, err := h.quota.TeamUsage(uint(teamID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to compute storage usage", err.Error())
+		return
+	}
+
+	response.Success(c, usage)
+}
+
+// checkTeamAccess lets team members, the team's advisor and admins of its department see the team
+func (h *Handler) checkTeamAccess(teamID uint, claims *auth.TokenClaims) (bool, error) {
+	var team struct {
+		DepartmentID uint
+		AdvisorID    *uint
+	}
+	if err := h.db.Table("teams").Select("department_id, advisor_id").Where("id = ?", teamID).First(&team).Error; err != nil {
+		return false, err
+	}
+
+	if claims.Role == enums.RoleAdmin && team.DepartmentID == claims.DepartmentID {
+		return true, nil
+	}
+	if team.AdvisorID != nil && *team.AdvisorID == claims.UserID {
+		return true, nil
+	}
+
+	var count int64
+	h.db.Table("team_members").Where("team_id = ? AND user_id = ?", teamID, claims.UserID).Count(&count)
+	return count > 0, nil
 }
 
 // checkProposalAccess checks if user has access to a proposal
 func (h *Handler) checkProposalAccess(proposalID uint, claims *auth.TokenClaims) (bool, error) {
 	var proposal struct {
-		TeamID    *uint
-		AdvisorID *uint
-		CreatedBy uint
+		TeamID           *uint
+		AdvisorID        *uint
+		SecondReviewerID *uint
+		CreatedBy        uint
 	}
 
-	if err := h.db.Table("proposals").Select("team_id, advisor_id, created_by").Where("id = ?", proposalID).First(&proposal).Error; err != nil {
+	if err := h.db.Table("proposals").Select("team_id, advisor_id, second_reviewer_id, created_by").Where("id = ?", proposalID).First(&proposal).Error; err != nil {
 		return false, err
 	}
 
@@ -150,6 +422,11 @@ func (h *Handler) checkProposalAccess(proposalID uint, claims *auth.TokenClaims)
 		return true, nil
 	}
 
+	// Co-reviewer can access the proposal they also decide on
+	if claims.Role == enums.RoleAdvisor && proposal.SecondReviewerID != nil && *proposal.SecondReviewerID == claims.UserID {
+		return true, nil
+	}
+
 	// Creator can access
 	if proposal.CreatedBy == claims.UserID {
 		return true, nil
@@ -0,0 +1,169 @@
+package files
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var ErrFileInfected = errors.New("file failed the virus scan")
+
+const (
+	// sniffLen is the header read to detect the content type and executable formats
+	sniffLen = 4096
+	// scanChunk bounds how much of the file is held in memory while the rest is scanned
+	scanChunk = 64 << 10
+	// scanOverlap carries the end of a chunk into the next, so patterns across the boundary are found
+	scanOverlap = 128
+)
+
+// The built-in scanner flags known test signatures anywhere in the file and native
+// executables by their header or extension; neither belongs in a report or presentation
+var malwareSignatures = [][]byte{
+	[]byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!`),
+}
+
+var executableHeaders = [][]byte{
+	[]byte("\x7fELF"),          // Linux ELF
+	[]byte("\xcf\xfa\xed\xfe"), // Mach-O, 64-bit
+	[]byte("\xce\xfa\xed\xfe"), // Mach-O, 32-bit
+}
+
+var executableExtensions = map[string]bool{
+	".exe": true, ".dll": true, ".com": true, ".scr": true, ".msi": true,
+	".bat": true, ".cmd": true, ".ps1": true, ".vbs": true, ".sh": true,
+}
+
+// pdfPage matches page objects but not the /Pages tree nodes
+var pdfPage = regexp.MustCompile(`/Type\s*/Page\b`)
+
+// Inspect reads an upload and records its declared and detected content type, its page count
+// when it is a PDF, and the result of the built-in signature scan. Only the header and one chunk
+// at a time are held in memory. An upload whose content could not be scanned is kept with a
+// failed scan, which quarantines it until the rescan job passes it.
+func Inspect(file *multipart.FileHeader) (domain.FileMetadata, error) {
+	meta := domain.FileMetadata{
+		DeclaredContentType: file.Header.Get("Content-Type"),
+		ScanStatus:          enums.ScanStatusPending,
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return meta, err
+	}
+	defer src.Close()
+
+	detected, pages, status, err := inspectContent(src, file.Filename)
+	if err != nil {
+		meta.ScanStatus = enums.ScanStatusFailed
+		return meta, nil
+	}
+	meta.DetectedMIME = detected
+	if detected == "application/pdf" {
+		meta.PageCount = &pages
+	}
+	meta.ScanStatus = status
+	return meta, nil
+}
+
+// inspectContent sniffs the content type from a bounded header, then scans the content in chunks
+// for malware signatures and, in a PDF, page objects
+func inspectContent(r io.Reader, filename string) (string, int, enums.ScanStatus, error) {
+	header, err := io.ReadAll(io.LimitReader(r, sniffLen))
+	if err != nil {
+		return "", 0, enums.ScanStatusFailed, err
+	}
+	detected := detectMIME(header, filename)
+	if isExecutable(header, filename) {
+		return detected, 0, enums.ScanStatusInfected, nil
+	}
+
+	pages, infected, err := scanStream(io.MultiReader(bytes.NewReader(header), r), detected == "application/pdf")
+	if err != nil {
+		return detected, 0, enums.ScanStatusFailed, err
+	}
+	if infected {
+		return detected, pages, enums.ScanStatusInfected, nil
+	}
+	return detected, pages, enums.ScanStatusClean, nil
+}
+
+// detectMIME sniffs the content type. Office Open XML files sniff as zip archives,
+// so those are narrowed down by their extension.
+func detectMIME(content []byte, filename string) string {
+	detected := http.DetectContentType(content)
+	if i := strings.Index(detected, ";"); i >= 0 {
+		detected = detected[:i]
+	}
+	if detected == "application/zip" {
+		ext := strings.ToLower(filepath.Ext(filename))
+		if strings.HasPrefix(ext, ".doc") || strings.HasPrefix(ext, ".ppt") || strings.HasPrefix(ext, ".xls") {
+			if byExt := mime.TypeByExtension(ext); byExt != "" {
+				return byExt
+			}
+		}
+	}
+	return detected
+}
+
+// isExecutable recognises native executables by their header, Windows ones by the PE signature
+// their DOS header points to rather than by "MZ" alone, and scripts and installers by extension
+func isExecutable(header []byte, filename string) bool {
+	if executableExtensions[strings.ToLower(filepath.Ext(filename))] {
+		return true
+	}
+	for _, magic := range executableHeaders {
+		if bytes.HasPrefix(header, magic) {
+			return true
+		}
+	}
+	if len(header) >= 0x40 && bytes.HasPrefix(header, []byte("MZ")) {
+		offset := int(binary.LittleEndian.Uint32(header[0x3c:0x40]))
+		if offset >= 0x40 && offset+4 <= len(header) && bytes.Equal(header[offset:offset+4], []byte("PE\x00\x00")) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanStream reads r one chunk at a time, looking for malware signatures and, with countPages,
+// counting PDF page objects. Each chunk is scanned together with the end of the previous one; a
+// page is counted in the chunk its match starts in, so none is counted twice.
+func scanStream(r io.Reader, countPages bool) (int, bool, error) {
+	buf := make([]byte, scanOverlap+scanChunk)
+	kept, pages := 0, 0
+	for {
+		n, err := io.ReadFull(r, buf[kept:])
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return 0, false, err
+		}
+		window := buf[:kept+n]
+
+		for _, signature := range malwareSignatures {
+			if bytes.Contains(window, signature) {
+				return pages, true, nil
+			}
+		}
+		if countPages {
+			for _, match := range pdfPage.FindAllIndex(window, -1) {
+				if last || match[0] < len(window)-scanOverlap {
+					pages++
+				}
+			}
+		}
+		if last {
+			return pages, false, nil
+		}
+		kept = copy(buf, window[len(window)-scanOverlap:])
+	}
+}
@@ -0,0 +1,222 @@
+package files
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxPDFStream caps how much one decompressed content stream may expand to
+const maxPDFStream = 16 << 20
+
+// ReadPDFText extracts the text of a stored PDF, given its URL as saved by the Uploader
+func (u *Uploader) ReadPDFText(relativeURL string) (string, error) {
+	content, err := os.ReadFile(u.LocalPath(relativeURL))
+	if err != nil {
+		return "", err
+	}
+	return ExtractPDFText(content), nil
+}
+
+// ExtractPDFText is a best-effort reader of the text drawn by a PDF's content streams, one
+// line per text positioning operator. It understands uncompressed and Flate-compressed
+// streams and strings in the font's own encoding, which covers most exported reports;
+// text in embedded CID fonts comes out empty rather than wrong.
+func ExtractPDFText(content []byte) string {
+	var out strings.Builder
+	for rest := content; ; {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			break
+		}
+		dict := rest[:start]
+		if obj := bytes.LastIndex(dict, []byte(" obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		body := rest[start+len("stream"):]
+		body = bytes.TrimPrefix(body, []byte("\r"))
+		body = bytes.TrimPrefix(body, []byte("\n"))
+		end := bytes.Index(body, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		rest = body[end+len("endstream"):]
+
+		data, ok := decodeStream(dict, body[:end])
+		if ok {
+			writeStreamText(&out, data)
+		}
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// decodeStream returns a content stream's bytes; images, fonts and other filters are skipped
+func decodeStream(dict, raw []byte) ([]byte, bool) {
+	if bytes.Contains(dict, []byte("/Subtype/Image")) || bytes.Contains(dict, []byte("/Subtype /Image")) ||
+		bytes.Contains(dict, []byte("/Length1")) || bytes.Contains(dict, []byte("/Type/XRef")) ||
+		bytes.Contains(dict, []byte("/Type /XRef")) {
+		return nil, false
+	}
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return raw, true
+	}
+	if !bytes.Contains(dict, []byte("/FlateDecode")) || bytes.Count(dict, []byte("Decode")) > 1 {
+		return nil, false
+	}
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxPDFStream))
+	if err != nil && len(data) == 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// writeStreamText scans a content stream for the strings shown by Tj, TJ, ' and " and starts
+// a new line on every text positioning operator
+func writeStreamText(out *strings.Builder, data []byte) {
+	var pending strings.Builder
+	var line strings.Builder
+	newLine := func() {
+		if text := strings.TrimSpace(line.String()); text != "" {
+			out.WriteString(text)
+			out.WriteByte('\n')
+		}
+		line.Reset()
+	}
+
+	for i := 0; i < len(data); {
+		ch := data[i]
+		switch {
+		case ch == '(':
+			s, next := readLiteral(data, i+1)
+			pending.WriteString(s)
+			i = next
+		case ch == '<' && i+1 < len(data) && data[i+1] == '<':
+			i += 2 // inline dictionary, e.g. marked content properties
+		case ch == '<':
+			s, next := readHex(data, i+1)
+			pending.WriteString(s)
+			i = next
+		case ch == '%':
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+		case ch == '-' || ch == '.' || (ch >= '0' && ch <= '9'):
+			j := i + 1
+			for j < len(data) && (data[j] == '.' || (data[j] >= '0' && data[j] <= '9')) {
+				j++
+			}
+			// A large negative kerning inside a TJ array is how most producers space words
+			if n, err := strconv.ParseFloat(string(data[i:j]), 64); err == nil && n < -200 && pending.Len() > 0 {
+				pending.WriteByte(' ')
+			}
+			i = j
+		case isPDFOperatorChar(ch):
+			j := i + 1
+			for j < len(data) && isPDFOperatorChar(data[j]) {
+				j++
+			}
+			switch string(data[i:j]) {
+			case "Tj", "TJ":
+				line.WriteString(pending.String())
+			case "'", "\"":
+				newLine()
+				line.WriteString(pending.String())
+			case "Td", "TD", "T*", "Tm", "ET":
+				newLine()
+			}
+			pending.Reset()
+			i = j
+		default:
+			i++
+		}
+	}
+	newLine()
+}
+
+func isPDFOperatorChar(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '*' || ch == '\'' || ch == '"'
+}
+
+// readLiteral reads a (string) with balanced parentheses and backslash escapes
+func readLiteral(data []byte, i int) (string, int) {
+	var s strings.Builder
+	depth := 1
+	for i < len(data) {
+		ch := data[i]
+		i++
+		switch ch {
+		case '\\':
+			if i >= len(data) {
+				break
+			}
+			esc := data[i]
+			i++
+			switch esc {
+			case 'n', 'r':
+				s.WriteByte(' ')
+			case 't':
+				s.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// line continuation
+			default:
+				if esc >= '0' && esc <= '7' {
+					n := int(esc - '0')
+					for k := 0; k < 2 && i < len(data) && data[i] >= '0' && data[i] <= '7'; k++ {
+						n = n*8 + int(data[i]-'0')
+						i++
+					}
+					s.WriteByte(byte(n))
+				} else {
+					s.WriteByte(esc)
+				}
+			}
+		case '(':
+			depth++
+			s.WriteByte(ch)
+		case ')':
+			depth--
+			if depth == 0 {
+				return s.String(), i
+			}
+			s.WriteByte(ch)
+		default:
+			s.WriteByte(ch)
+		}
+	}
+	return s.String(), i
+}
+
+// readHex reads a <hex string>. Two-byte strings that are not plain text are CID glyph
+// codes, which cannot be mapped back to characters without the font, and are dropped.
+func readHex(data []byte, i int) (string, int) {
+	var digits []byte
+	for i < len(data) && data[i] != '>' {
+		if ch := data[i]; (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F') {
+			digits = append(digits, ch)
+		}
+		i++
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	decoded := make([]byte, 0, len(digits)/2)
+	for k := 0; k+1 < len(digits); k += 2 {
+		n, _ := strconv.ParseUint(string(digits[k:k+2]), 16, 8)
+		decoded = append(decoded, byte(n))
+	}
+	for _, b := range decoded {
+		if b != '\t' && (b < 0x20 || b > 0x7e) {
+			return "", i + 1
+		}
+	}
+	return string(decoded), i + 1
+}
@@ -0,0 +1,196 @@
+package files
+
+import (
+	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/pkg/response"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrPreviewUnavailable means no PDF renderer (poppler's pdftoppm) is installed on the server
+var ErrPreviewUnavailable = errors.New("PDF previews are not available on this server")
+
+const (
+	previewSuffix  = ".preview.png"
+	previewWidth   = 800 // pixels; enough for a readable first page in a side panel
+	previewTimeout = 20 * time.Second
+)
+
+// DocumentPreview describes a document that is not rendered as an image: links, non-PDF
+// files, and PDFs when the server cannot render them
+type DocumentPreview struct {
+	DocumentID   uint   `json:"document_id"`
+	DocumentType string `json:"document_type"`
+	Kind         string `json:"kind"`           // "link" or "file"
+	URL          string `json:"url,omitempty"`  // links only
+	Host         string `json:"host,omitempty"` // links only
+	FileName     string `json:"file_name,omitempty"`
+	SizeBytes    int64  `json:"size_bytes,omitempty"`
+	domain.FileMetadata
+	PreviewError string    `json:"preview_error,omitempty"` // why a PDF was not rendered
+	Status       string    `json:"status"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+}
+
+// RenderFirstPage renders the first page of a stored PDF to a PNG and returns its path. The
+// image is kept beside the PDF and reused until the PDF changes.
+func (u *Uploader) RenderFirstPage(relativeURL string) (string, error) {
+	source := u.LocalPath(relativeURL)
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+	target := source + previewSuffix
+	if cached, err := os.Stat(target); err == nil && !cached.ModTime().Before(info.ModTime()) {
+		return target, nil
+	}
+
+	renderer, err := exec.LookPath("pdftoppm")
+	if err != nil {
+		return "", ErrPreviewUnavailable
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+	defer cancel()
+
+	// Render under a temporary name so a concurrent request never serves a half-written image
+	prefix := source + ".preview-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	cmd := exec.CommandContext(ctx, renderer, "-png", "-f", "1", "-l", "1", "-singlefile",
+		"-scale-to-x", strconv.Itoa(previewWidth), "-scale-to-y", "-1", source, prefix)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(prefix + ".png")
+		return "", errors.New("failed to render preview: " + strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(prefix+".png", target); err != nil {
+		_ = os.Remove(prefix + ".png")
+		return "", err
+	}
+	return target, nil
+}
+
+// PreviewProjectDoc godoc
+// @Summary Preview a project document
+// @Description Returns the first page of a PDF document as a PNG to show inline. Links, other files, and PDFs the server cannot render return their metadata as JSON instead. Open to the team, its advisor, the project's examiners and admins; anyone may preview documents of public projects.
+// @Tags Files
+// @Produce png,json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param docId path int true "Document ID"
+// @Success 200 {object} response.Response{data=DocumentPreview} "Metadata, when there is no image"
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/{id}/docs/{docId}/preview [get]
+func (h *Handler) PreviewProjectDoc(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid project ID", nil)
+		return
+	}
+	docID, err := strconv.ParseUint(c.Param("docId"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid document ID", nil)
+		return
+	}
+
+	var project struct {
+		Visibility string
+		TeamID     uint
+	}
+	if err := h.db.Table("projects").Select("visibility, team_id").Where("id = ?", projectID).First(&project).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Project not found", nil)
+		return
+	}
+	if project.Visibility != "public" && !h.canReviewProject(uint(projectID), project.TeamID, userClaims) {
+		response.Error(c, http.StatusForbidden, "You don't have access to this document", nil)
+		return
+	}
+
+	var doc domain.ProjectDocumentation
+	if err := h.db.Where("id = ? AND project_id = ?", docID, projectID).First(&doc).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Document not found", nil)
+		return
+	}
+
+	cacheControl := privateCacheControl
+	if project.Visibility == "public" {
+		cacheControl = publicCacheControl()
+	}
+
+	preview := DocumentPreview{
+		DocumentID:   doc.ID,
+		DocumentType: doc.DocumentType,
+		Status:       doc.Status,
+		SubmittedAt:  doc.SubmittedAt,
+	}
+	if !isStoredFile(doc.URL) {
+		preview.Kind = "link"
+		preview.URL = doc.URL
+		if parsed, err := url.Parse(doc.URL); err == nil {
+			preview.Host = parsed.Hostname()
+		}
+		response.Success(c, preview)
+		return
+	}
+
+	preview.Kind = "file"
+	preview.FileName = filepath.Base(doc.URL)
+	preview.SizeBytes = doc.FileSizeBytes
+	preview.FileMetadata = doc.FileMetadata
+
+	// Quarantined files are described but not rendered
+	if doc.ScanStatus.Quarantined() {
+		preview.PreviewError = ErrQuarantinedFile.Error()
+		response.Success(c, preview)
+		return
+	}
+
+	isPDF := doc.DetectedMIME == "application/pdf" ||
+		(doc.DetectedMIME == "" && strings.EqualFold(filepath.Ext(doc.URL), ".pdf"))
+	if isPDF {
+		image, err := h.uploader.RenderFirstPage(doc.URL)
+		if err == nil {
+			c.Header("Content-Disposition", `inline; filename="preview.png"`)
+			serveFile(c, image, "", cacheControl)
+			return
+		}
+		preview.PreviewError = err.Error()
+	}
+	response.Success(c, preview)
+}
+
+// canReviewProject extends checkProjectAccess to the people who review the project's documents:
+// the team's advisor and the project's examiners
+func (h *Handler) canReviewProject(projectID uint, teamID uint, claims *auth.TokenClaims) bool {
+	if ok, _ := h.checkProjectAccess(projectID, teamID, claims); ok {
+		return true
+	}
+	var count int64
+	h.db.Table("teams").Where("id = ? AND advisor_id = ?", teamID, claims.UserID).Count(&count)
+	if count > 0 {
+		return true
+	}
+	h.db.Table("project_examiners").Where("project_id = ? AND user_id = ?", projectID, claims.UserID).Count(&count)
+	return count > 0
+}
+
+// isStoredFile tells uploaded files, saved under uploads/, from submitted links
+func isStoredFile(u string) bool {
+	return strings.HasPrefix(filepath.ToSlash(u), "uploads/")
+}
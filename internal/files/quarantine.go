@@ -0,0 +1,376 @@
+package files
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/enums"
+	"backend/pkg/response"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// QuarantineRescanInterval is how often files whose scan is pending or failed are scanned again
+	QuarantineRescanInterval = 15 * time.Minute
+	// quarantineRescanBatch bounds the files rescanned per source and run
+	quarantineRescanBatch = 100
+)
+
+var (
+	ErrQuarantinedFile   = errors.New("this file is quarantined until it passes the virus scan")
+	ErrNotQuarantined    = errors.New("quarantined file not found")
+	ErrUnknownFileSource = errors.New("unknown file source")
+)
+
+// quarantineSource is a table holding uploaded files. list selects its quarantined files with the
+// department they belong to; the statuses are its only parameter.
+type quarantineSource struct {
+	table     string
+	urlColumn string
+	public    bool // served from the static uploads directory
+	list      string
+}
+
+var quarantineSources = map[string]quarantineSource{
+	"proposal_version": {
+		table: "proposal_versions", urlColumn: "file_url", public: true,
+		list: `SELECT 'proposal_version' AS source, v.id, v.file_url AS path, v.file_size_bytes, v.detected_mime, v.scan_status,
+				v.created_by AS uploaded_by, v.updated_at AS stored_at, COALESCE(t.department_id, u.department_id) AS department_id
+			FROM proposal_versions v
+			JOIN proposals p ON p.id = v.proposal_id
+			LEFT JOIN teams t ON t.id = p.team_id
+			LEFT JOIN users u ON u.id = p.created_by
+			WHERE v.file_url IS NOT NULL AND v.scan_status IN ?`,
+	},
+	"proposal_version_file": {
+		table: "proposal_version_files", urlColumn: "file_url", public: true,
+		list: `SELECT 'proposal_version_file' AS source, f.id, f.file_url AS path, f.file_size_bytes, f.detected_mime, f.scan_status,
+				NULL AS uploaded_by, f.replaced_at AS stored_at, COALESCE(t.department_id, u.department_id) AS department_id
+			FROM proposal_version_files f
+			JOIN proposals p ON p.id = f.proposal_id
+			LEFT JOIN teams t ON t.id = p.team_id
+			LEFT JOIN users u ON u.id = p.created_by
+			WHERE f.scan_status IN ?`,
+	},
+	"project_documentation": {
+		table: "project_documentations", urlColumn: "url", public: true,
+		list: `SELECT 'project_documentation' AS source, d.id, d.url AS path, d.file_size_bytes, d.detected_mime, d.scan_status,
+				d.submitted_by AS uploaded_by, d.submitted_at AS stored_at, pr.department_id
+			FROM project_documentations d
+			JOIN projects pr ON pr.id = d.project_id
+			WHERE d.scan_status IN ?`,
+	},
+	"feedback_attachment": {
+		table: "feedback_attachments", urlColumn: "path",
+		list: `SELECT 'feedback_attachment' AS source, a.id, a.path, a.file_size_bytes, a.detected_mime, a.scan_status,
+				f.reviewer_id AS uploaded_by, a.created_at AS stored_at, COALESCE(t.department_id, u.department_id) AS department_id
+			FROM feedback_attachments a
+			JOIN feedbacks f ON f.id = a.feedback_id
+			JOIN proposals p ON p.id = f.proposal_id
+			LEFT JOIN teams t ON t.id = p.team_id
+			LEFT JOIN users u ON u.id = p.created_by
+			WHERE a.scan_status IN ?`,
+	},
+	"announcement_attachment": {
+		table: "announcement_attachments", urlColumn: "url", public: true,
+		list: `SELECT 'announcement_attachment' AS source, aa.id, aa.url AS path, aa.file_size_bytes, aa.detected_mime, aa.scan_status,
+				an.author_id AS uploaded_by, aa.created_at AS stored_at, an.department_id
+			FROM announcement_attachments aa
+			JOIN announcements an ON an.id = aa.announcement_id
+			WHERE aa.scan_status IN ?`,
+	},
+}
+
+// quarantineOrder lists the sources in a stable order for the union queries
+var quarantineOrder = []string{"proposal_version", "proposal_version_file", "project_documentation", "feedback_attachment", "announcement_attachment"}
+
+// QuarantinedFile is a stored upload held back from downloads until its scan passes
+type QuarantinedFile struct {
+	Source        string           `json:"source" example:"project_documentation"`
+	ID            uint             `json:"id"`
+	FileName      string           `gorm:"-" json:"file_name"`
+	Path          string           `json:"path"`
+	FileSizeBytes int64            `json:"file_size_bytes"`
+	DetectedMIME  string           `gorm:"column:detected_mime" json:"detected_mime,omitempty"`
+	ScanStatus    enums.ScanStatus `json:"scan_status"`
+	UploadedBy    *uint            `json:"uploaded_by,omitempty"`
+	StoredAt      time.Time        `json:"stored_at"`
+	DepartmentID  uint             `json:"department_id"`
+}
+
+// Quarantine keeps uploads that have not passed the virus scan out of reach: downloads check
+// Quarantined, the rescan job retries pending and failed scans, and admins release or delete
+// what stays there. The scan status stored with each file is the quarantine state.
+type Quarantine struct {
+	db       *gorm.DB
+	uploader *Uploader
+}
+
+func NewQuarantine(db *gorm.DB, uploader *Uploader) *Quarantine {
+	return &Quarantine{db: db, uploader: uploader}
+}
+
+// List returns the department's quarantined files, latest first
+func (q *Quarantine) List(departmentID uint) ([]QuarantinedFile, error) {
+	return q.find("department_id = ?", departmentID)
+}
+
+func (q *Quarantine) find(filter string, args ...interface{}) ([]QuarantinedFile, error) {
+	queries := make([]string, 0, len(quarantineOrder))
+	params := make([]interface{}, 0, len(quarantineOrder)+len(args))
+	for _, name := range quarantineOrder {
+		queries = append(queries, quarantineSources[name].list)
+		params = append(params, enums.QuarantinedScanStatuses)
+	}
+	params = append(params, args...)
+
+	var quarantined []QuarantinedFile
+	err := q.db.Raw("SELECT * FROM ("+strings.Join(queries, " UNION ALL ")+") q WHERE "+filter+" ORDER BY stored_at DESC", params...).
+		Scan(&quarantined).Error
+	for i := range quarantined {
+		quarantined[i].FileName = filepath.Base(quarantined[i].Path)
+	}
+	return quarantined, err
+}
+
+// get looks up one of the department's quarantined files
+func (q *Quarantine) get(source string, id uint, departmentID uint) (*QuarantinedFile, quarantineSource, error) {
+	src, ok := quarantineSources[source]
+	if !ok {
+		return nil, src, ErrUnknownFileSource
+	}
+	found, err := q.find("source = ? AND id = ? AND department_id = ?", source, id, departmentID)
+	if err != nil {
+		return nil, src, err
+	}
+	if len(found) == 0 {
+		return nil, src, ErrNotQuarantined
+	}
+	return &found[0], src, nil
+}
+
+// Release lets an admin serve a quarantined file without a clean scan
+func (q *Quarantine) Release(source string, id uint, departmentID uint) error {
+	file, src, err := q.get(source, id, departmentID)
+	if err != nil {
+		return err
+	}
+	result := q.db.Table(src.table).
+		Where("id = ? AND scan_status IN ?", file.ID, enums.QuarantinedScanStatuses).
+		Update("scan_status", enums.ScanStatusReleased)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotQuarantined
+	}
+	log.Printf("Released quarantined file %s/%d (%s, scan %s)", source, file.ID, file.Path, file.ScanStatus)
+	return nil
+}
+
+// Delete removes a quarantined file from disk and its record. A proposal version keeps its content
+// and only loses the attachment.
+func (q *Quarantine) Delete(source string, id uint, departmentID uint) error {
+	file, src, err := q.get(source, id, departmentID)
+	if err != nil {
+		return err
+	}
+
+	if source == "proposal_version" {
+		err = q.db.Table(src.table).Where("id = ?", file.ID).Updates(map[string]interface{}{
+			"file_url":              nil,
+			"file_hash":             "",
+			"file_size_bytes":       0,
+			"declared_content_type": "",
+			"detected_mime":         "",
+			"page_count":            nil,
+			"scan_status":           "",
+		}).Error
+	} else {
+		err = q.db.Exec("DELETE FROM "+src.table+" WHERE id = ?", file.ID).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := q.uploader.DeleteFile(file.Path); err != nil && !os.IsNotExist(err) {
+		log.Printf("failed to delete quarantined file %s: %v", file.Path, err)
+	}
+	return nil
+}
+
+// Quarantined tells whether a path under the static uploads directory belongs to a quarantined file
+func (q *Quarantine) Quarantined(path string) bool {
+	// A rendered first page is held back with its file
+	path = strings.TrimSuffix(strings.TrimPrefix(filepath.Clean(path), "/"), previewSuffix)
+
+	queries := make([]string, 0, len(quarantineOrder))
+	params := make([]interface{}, 0, 2*len(quarantineOrder))
+	for _, name := range quarantineOrder {
+		src := quarantineSources[name]
+		if !src.public {
+			continue
+		}
+		queries = append(queries, "SELECT 1 FROM "+src.table+" WHERE "+src.urlColumn+" = ? AND scan_status IN ?")
+		params = append(params, path, enums.QuarantinedScanStatuses)
+	}
+
+	var quarantined bool
+	if err := q.db.Raw("SELECT EXISTS ("+strings.Join(queries, " UNION ALL ")+")", params...).Scan(&quarantined).Error; err != nil {
+		// Rather refuse a download than serve a file that may be infected
+		log.Printf("failed to check the quarantine for %s: %v", path, err)
+		return true
+	}
+	return quarantined
+}
+
+// Rescan scans the files whose scan is pending or failed again; clean files leave the quarantine
+// and infected ones stay for an admin to delete (run by the scheduler)
+func (q *Quarantine) Rescan() {
+	for _, name := range quarantineOrder {
+		src := quarantineSources[name]
+		var pending []struct {
+			ID   uint
+			Path string
+		}
+		err := q.db.Table(src.table).
+			Select("id, "+src.urlColumn+" AS path").
+			Where(src.urlColumn+" IS NOT NULL AND scan_status IN ?", []enums.ScanStatus{enums.ScanStatusPending, enums.ScanStatusFailed}).
+			Order("id").
+			Limit(quarantineRescanBatch).
+			Scan(&pending).Error
+		if err != nil {
+			log.Printf("failed to load %s awaiting a scan: %v", src.table, err)
+			continue
+		}
+
+		for _, file := range pending {
+			// Files moved to cold storage or missing on disk stay quarantined
+			status, err := q.rescanFile(file.Path)
+			if err != nil {
+				continue
+			}
+			if err := q.db.Table(src.table).Where("id = ?", file.ID).Update("scan_status", status).Error; err != nil {
+				log.Printf("failed to record the scan of %s %d: %v", src.table, file.ID, err)
+				continue
+			}
+			if status == enums.ScanStatusInfected {
+				log.Printf("Rescan found %s %d (%s) infected; it stays quarantined", src.table, file.ID, file.Path)
+			}
+		}
+	}
+}
+
+// rescanFile scans a stored upload again without reading it into memory at once
+func (q *Quarantine) rescanFile(path string) (enums.ScanStatus, error) {
+	f, err := os.Open(q.uploader.LocalPath(path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, _, status, err := inspectContent(f, path)
+	return status, err
+}
+
+// ListQuarantine godoc
+// @Summary List quarantined files
+// @Description Uploads of the admin's department that are held back from downloads because their virus scan is pending, failed or found them infected
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]QuarantinedFile}
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/quarantine [get]
+func (h *Handler) ListQuarantine(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	quarantined, err := h.quarantine.List(userClaims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list quarantined files", err.Error())
+		return
+	}
+	response.Success(c, quarantined)
+}
+
+// ReleaseQuarantinedFile godoc
+// @Summary Release a quarantined file
+// @Description Serve a quarantined file although its scan did not pass, e.g. after checking it by hand
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param source path string true "File source" Enums(proposal_version, proposal_version_file, project_documentation, feedback_attachment, announcement_attachment)
+// @Param id path int true "ID of the file's record"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/quarantine/{source}/{id}/release [post]
+func (h *Handler) ReleaseQuarantinedFile(c *gin.Context) {
+	h.resolveQuarantined(c, h.quarantine.Release, "File released")
+}
+
+// DeleteQuarantinedFile godoc
+// @Summary Delete a quarantined file
+// @Description Remove a quarantined file from disk together with its record; a proposal version only loses its attachment
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param source path string true "File source" Enums(proposal_version, proposal_version_file, project_documentation, feedback_attachment, announcement_attachment)
+// @Param id path int true "ID of the file's record"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/quarantine/{source}/{id} [delete]
+func (h *Handler) DeleteQuarantinedFile(c *gin.Context) {
+	h.resolveQuarantined(c, h.quarantine.Delete, "File deleted")
+}
+
+func (h *Handler) resolveQuarantined(c *gin.Context, resolve func(source string, id uint, departmentID uint) error, message string) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid file ID", nil)
+		return
+	}
+
+	if err := resolve(c.Param("source"), uint(id), userClaims.DepartmentID); err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownFileSource):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrNotQuarantined):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to update the quarantined file", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, message, nil)
+}
+
+// BlockQuarantined refuses static downloads of quarantined files
+func (h *Handler) BlockQuarantined(c *gin.Context) {
+	if h.quarantine.Quarantined(c.Request.URL.Path) {
+		response.Error(c, http.StatusLocked, ErrQuarantinedFile.Error(), nil)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,174 @@
+package files
+
+import (
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+const megabyte = 1024 * 1024
+
+var (
+	ErrTeamQuotaExceeded = errors.New("team storage quota exceeded")
+	ErrUserQuotaExceeded = errors.New("user storage quota exceeded")
+)
+
+// Quota tracks the bytes stored for proposal versions and project documentation
+// and enforces per-team and per-user limits on new uploads. A limit of 0 is unlimited.
+type Quota struct {
+	db             *gorm.DB
+	teamLimitBytes int64
+	userLimitBytes int64
+}
+
+func NewQuota(db *gorm.DB, teamLimitMB int, userLimitMB int) *Quota {
+	return &Quota{
+		db:             db,
+		teamLimitBytes: int64(teamLimitMB) * megabyte,
+		userLimitBytes: int64(userLimitMB) * megabyte,
+	}
+}
+
+// MemberStorage is how much one team member has uploaded across all their teams
+type MemberStorage struct {
+	UserID     uint   `json:"user_id"`
+	Name       string `json:"name"`
+	UsedBytes  int64  `json:"used_bytes"`
+	QuotaBytes int64  `json:"quota_bytes"`
+}
+
+// StorageUsage is a team's cumulative upload size against its quota
+type StorageUsage struct {
+	TeamID             uint            `json:"team_id"`
+	ProposalBytes      int64           `json:"proposal_bytes"`
+	DocumentationBytes int64           `json:"documentation_bytes"`
+	UsedBytes          int64           `json:"used_bytes"`
+	QuotaBytes         int64           `json:"quota_bytes"`               // 0 = unlimited
+	RemainingBytes     *int64          `json:"remaining_bytes,omitempty"` // nil when unlimited
+	UsedPercent        float64         `json:"used_percent"`
+	Members            []MemberStorage `json:"members"`
+}
+
+// CheckUpload refuses an upload of size bytes that would take the team or the uploader over quota
+func (q *Quota) CheckUpload(teamID uint, userID uint, size int64) error {
+	if q.teamLimitBytes > 0 {
+		proposalBytes, docBytes, err := q.teamBytes(teamID)
+		if err != nil {
+			return err
+		}
+		if proposalBytes+docBytes+size > q.teamLimitBytes {
+			return ErrTeamQuotaExceeded
+		}
+	}
+
+	if q.userLimitBytes > 0 {
+		used, err := q.userBytes(userID)
+		if err != nil {
+			return err
+		}
+		if used+size > q.userLimitBytes {
+			return ErrUserQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// TeamUsage reports a team's storage use with a per-member breakdown
+func (q *Quota) TeamUsage(teamID uint) (*StorageUsage, error) {
+	proposalBytes, docBytes, err := q.teamBytes(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &StorageUsage{
+		TeamID:             teamID,
+		ProposalBytes:      proposalBytes,
+		DocumentationBytes: docBytes,
+		UsedBytes:          proposalBytes + docBytes,
+		QuotaBytes:         q.teamLimitBytes,
+		Members:            []MemberStorage{},
+	}
+	if q.teamLimitBytes > 0 {
+		remaining := q.teamLimitBytes - usage.UsedBytes
+		if remaining < 0 {
+			remaining = 0
+		}
+		usage.RemainingBytes = &remaining
+		usage.UsedPercent = math.Round(float64(usage.UsedBytes)/float64(q.teamLimitBytes)*1000) / 10
+	}
+
+	var members []struct {
+		UserID uint
+		Name   string
+	}
+	if err := q.db.Table("team_members").
+		Select("team_members.user_id, users.name").
+		Joins("JOIN users ON users.id = team_members.user_id").
+		Where("team_members.team_id = ?", teamID).
+		Order("users.name ASC").
+		Scan(&members).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		used, err := q.userBytes(m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		usage.Members = append(usage.Members, MemberStorage{
+			UserID:     m.UserID,
+			Name:       m.Name,
+			UsedBytes:  used,
+			QuotaBytes: q.userLimitBytes,
+		})
+	}
+
+	return usage, nil
+}
+
+func (q *Quota) teamBytes(teamID uint) (proposalBytes int64, docBytes int64, err error) {
+	err = q.db.Table("proposal_versions").
+		Select("COALESCE(SUM(proposal_versions.file_size_bytes), 0)").
+		Joins("JOIN proposals ON proposals.id = proposal_versions.proposal_id").
+		Where("proposals.team_id = ?", teamID).
+		Scan(&proposalBytes).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Files replaced on a draft stay on disk for the audit trail, so they still count
+	var replacedBytes int64
+	err = q.db.Table("proposal_version_files").
+		Select("COALESCE(SUM(proposal_version_files.file_size_bytes), 0)").
+		Joins("JOIN proposals ON proposals.id = proposal_version_files.proposal_id").
+		Where("proposals.team_id = ?", teamID).
+		Scan(&replacedBytes).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	proposalBytes += replacedBytes
+
+	err = q.db.Table("project_documentations").
+		Select("COALESCE(SUM(project_documentations.file_size_bytes), 0)").
+		Joins("JOIN projects ON projects.id = project_documentations.project_id").
+		Where("projects.team_id = ?", teamID).
+		Scan(&docBytes).Error
+	return proposalBytes, docBytes, err
+}
+
+func (q *Quota) userBytes(userID uint) (int64, error) {
+	var proposalBytes, docBytes int64
+	if err := q.db.Table("proposal_versions").
+		Select("COALESCE(SUM(file_size_bytes), 0)").
+		Where("created_by = ?", userID).
+		Scan(&proposalBytes).Error; err != nil {
+		return 0, err
+	}
+	if err := q.db.Table("project_documentations").
+		Select("COALESCE(SUM(file_size_bytes), 0)").
+		Where("submitted_by = ?", userID).
+		Scan(&docBytes).Error; err != nil {
+		return 0, err
+	}
+	return proposalBytes + docBytes, nil
+}
@@ -0,0 +1,88 @@
+package files
+
+import (
+	"backend/pkg/database"
+	"backend/pkg/response"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// PublicFileMaxAge is how long browsers and proxies may reuse a public project file without revalidating
+	PublicFileMaxAge = time.Hour
+	// privateCacheControl lets only the user's browser keep a file, and only with revalidation
+	privateCacheControl = "private, no-cache"
+)
+
+// serveFile streams a stored file with validators so clients can revalidate and resume.
+// http.ServeContent answers If-None-Match / If-Modified-Since with 304, serves byte ranges
+// (Range / If-Range) for large PDFs and videos, and handles HEAD. The ETag is the stored
+// content hash when there is one, otherwise a weak tag from the file's size and mtime.
+func serveFile(c *gin.Context, path string, fileHash string, cacheControl string) {
+	f, err := os.Open(path)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "File not found", nil)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		response.Error(c, http.StatusNotFound, "File not found", nil)
+		return
+	}
+
+	etag := fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+	if fileHash != "" {
+		etag = `"` + fileHash + `"`
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+}
+
+// HashUpload returns the SHA-256 of an upload, stored as the file's content hash and served as its ETag
+func HashUpload(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, src); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// storedAs is the LIKE pattern matching stored file URLs that end in the filename
+func storedAs(filename string) string {
+	return "%/" + database.EscapeLike(filename)
+}
+
+func publicCacheControl() string {
+	return fmt.Sprintf("public, max-age=%d", int(PublicFileMaxAge.Seconds()))
+}
+
+// validFilename rejects names that would leave the entity's upload directory
+func validFilename(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	for _, r := range name {
+		if r == '/' || r == '\\' {
+			return false
+		}
+	}
+	return true
+}
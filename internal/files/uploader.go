@@ -6,9 +6,11 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
+// Uploader stores files under UploadDir, where the stored "uploads/..." URLs live on disk
 type Uploader struct {
 	UploadDir string
 }
@@ -18,6 +20,16 @@ func NewUploader(dir string) *Uploader {
 	return &Uploader{UploadDir: dir}
 }
 
+// LocalPath resolves a stored URL such as "uploads/pdf/file.pdf" or "private_uploads/..." to the
+// file on disk; private uploads sit beside the upload directory
+func (u *Uploader) LocalPath(relativeURL string) string {
+	clean := filepath.ToSlash(filepath.Clean(relativeURL))
+	if rest, ok := strings.CutPrefix(clean, "uploads/"); ok {
+		return filepath.Join(u.UploadDir, rest)
+	}
+	return filepath.Join(filepath.Dir(u.UploadDir), clean)
+}
+
 func (u *Uploader) SaveFile(file *multipart.FileHeader, subDir string) (string, error) {
 	src, err := file.Open()
 	if err != nil { return "", err }
@@ -37,8 +49,39 @@ func (u *Uploader) SaveFile(file *multipart.FileHeader, subDir string) (string,
 	return filepath.Join("uploads", subDir, filename), nil
 }
 
+// PrivateDir holds uploads that must only be downloaded through an access-checked endpoint;
+// unlike UploadDir it is not served as static files
+const PrivateDir = "private_uploads"
+
+// SavePrivateFile stores the file under PrivateDir instead of the public upload directory
+func (u *Uploader) SavePrivateFile(file *multipart.FileHeader, subDir string) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(file.Filename))
+	finalPath := filepath.Join(filepath.Dir(u.UploadDir), PrivateDir, subDir, filename)
+	if err := os.MkdirAll(filepath.Dir(finalPath), 0o750); err != nil {
+		return "", err
+	}
+
+	dst, err := os.OpenFile(finalPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = os.Remove(finalPath)
+		return "", err
+	}
+	return filepath.Join(PrivateDir, subDir, filename), nil
+}
+
 func (u *Uploader) DeleteFile(relativeURL string) error {
-	// convert "uploads/pdf/file.pdf" to "./uploads/pdf/file.pdf"
-	fullPath := filepath.Join(".", relativeURL)
+	fullPath := u.LocalPath(relativeURL)
+	_ = os.Remove(fullPath + previewSuffix)
 	return os.Remove(fullPath)
 }
\ No newline at end of file
@@ -0,0 +1,269 @@
+package grading
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// GetProjectGrading godoc
+// @Summary Get a project's grading
+// @Description Grading status, rubric, graders and grade sheets with the projected grade. Team members see it read-only: only the status until the department head locks the grade, then the final grade and the sheets.
+// @Tags Grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} response.Response{data=ProjectGrading}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/{id}/grading [get]
+func (h *Handler) GetProjectGrading(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c, "id")
+	if id == 0 {
+		return
+	}
+
+	grading, err := h.service.GetProjectGrading(id, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch grading")
+		return
+	}
+	response.Success(c, grading)
+}
+
+// SubmitGrade godoc
+// @Summary Submit a grade sheet
+// @Description The project's advisor or an examiner scores every rubric criterion out of 100. Opens once the final report is approved; the sheet can be revised until the grade is locked.
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body SubmitGradeRequest true "Scores by criterion key"
+// @Success 200 {object} response.Response{data=domain.GradeSheet}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /projects/{id}/grading/sheet [put]
+func (h *Handler) SubmitGrade(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c, "id")
+	if id == 0 {
+		return
+	}
+
+	var req SubmitGradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	sheet, err := h.service.SubmitGrade(id, claims.UserID, req)
+	if err != nil {
+		respondError(c, err, "Failed to submit grade")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Grade submitted", sheet)
+}
+
+// LockGrade godoc
+// @Summary Lock the final grade
+// @Description The department head fixes the weighted final grade once the advisor and every examiner have graded. The team is notified and the grade can no longer change.
+// @Tags Grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} response.Response{data=domain.ProjectGrade}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /projects/{id}/grading/lock [post]
+func (h *Handler) LockGrade(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c, "id")
+	if id == 0 {
+		return
+	}
+
+	grade, err := h.service.LockGrade(id, claims.UserID, claims.DepartmentID)
+	if err != nil {
+		respondError(c, err, "Failed to lock grade")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Grade locked", grade)
+}
+
+// AssignExaminer godoc
+// @Summary Add an examiner
+// @Description Adds a teacher to the project's defense committee; examiners grade the project alongside the advisor
+// @Tags Grading
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body AssignExaminerRequest true "Teacher to add"
+// @Success 201 {object} response.Response{data=domain.ProjectExaminer}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /projects/{id}/grading/examiners [post]
+func (h *Handler) AssignExaminer(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c, "id")
+	if id == 0 {
+		return
+	}
+
+	var req AssignExaminerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	examiner, err := h.service.AssignExaminer(id, req.UserID, claims.UserID, claims.DepartmentID)
+	if err != nil {
+		respondError(c, err, "Failed to add examiner")
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Examiner added", examiner)
+}
+
+// RemoveExaminer godoc
+// @Summary Remove an examiner
+// @Tags Grading
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param user_id path int true "Examiner user ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /projects/{id}/grading/examiners/{user_id} [delete]
+func (h *Handler) RemoveExaminer(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c, "id")
+	if id == 0 {
+		return
+	}
+	userID := parseID(c, "user_id")
+	if userID == 0 {
+		return
+	}
+
+	if err := h.service.RemoveExaminer(id, userID, claims.DepartmentID); err != nil {
+		respondError(c, err, "Failed to remove examiner")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Examiner removed", nil)
+}
+
+// GetRubric godoc
+// @Summary Get the department grading rubric
+// @Description The criteria projects are graded against with their weights; the global rubric applies until the department defines its own
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=Rubric}
+// @Router /admin/grading-rubric [get]
+func (h *Handler) GetRubric(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	rubric, err := h.service.GetRubric(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch rubric", err.Error())
+		return
+	}
+	response.Success(c, rubric)
+}
+
+// UpdateRubric godoc
+// @Summary Replace the department grading rubric
+// @Description Weights must add up to 100. Sending no criteria reverts to the global rubric. Submitted grade sheets keep the criteria they were scored against.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateRubricRequest true "Rubric criteria"
+// @Success 200 {object} response.Response{data=Rubric}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/grading-rubric [put]
+func (h *Handler) UpdateRubric(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateRubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	rubric, err := h.service.UpdateRubric(claims.DepartmentID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Rubric updated", rubric)
+}
+
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrProjectNotFound):
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrGradingForbidden), errors.Is(err, ErrNotGrader):
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrGradeLocked), errors.Is(err, ErrFinalReportPending), errors.Is(err, ErrGradesIncomplete):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	default:
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	}
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseID(c *gin.Context, param string) uint {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil || id == 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", nil)
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,151 @@
+package grading
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetProject(id uint) (*domain.Project, error)
+	IsFinalReportApproved(projectID uint) (bool, error)
+	GetUser(id uint) (*domain.User, error)
+
+	// Rubric
+	GetCriteria(departmentID *uint) ([]domain.GradingCriterion, error)
+	ReplaceCriteria(departmentID uint, criteria []domain.GradingCriterion) error
+	EnsureGlobalCriterion(criterion domain.GradingCriterion) error
+
+	// Examiners
+	GetExaminers(projectID uint) ([]domain.ProjectExaminer, error)
+	IsExaminer(projectID, userID uint) bool
+	AddExaminer(examiner *domain.ProjectExaminer) error
+	RemoveExaminer(projectID, userID uint) (int64, error)
+
+	// Grades
+	GetSheets(projectID uint) ([]domain.GradeSheet, error)
+	GetSheet(projectID, graderID uint) (*domain.GradeSheet, error)
+	SaveSheet(sheet *domain.GradeSheet) error
+	GetGrade(projectID uint) (*domain.ProjectGrade, error)
+	CreateGrade(grade *domain.ProjectGrade) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// GetProject loads the project with its proposal (for the advisor) and team members
+func (r *repository) GetProject(id uint) (*domain.Project, error) {
+	var project domain.Project
+	err := r.db.
+		Preload("Proposal").
+		Preload("Team.Members").
+		First(&project, id).Error
+	return &project, err
+}
+
+func (r *repository) IsFinalReportApproved(projectID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ProjectDocumentation{}).
+		Where("project_id = ? AND document_type = ? AND status = ?", projectID, "final_report", enums.DocumentStatusApproved).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	err := r.db.First(&user, id).Error
+	return &user, err
+}
+
+// GetCriteria returns the department's own rubric, or the global one when departmentID is nil
+func (r *repository) GetCriteria(departmentID *uint) ([]domain.GradingCriterion, error) {
+	var criteria []domain.GradingCriterion
+	query := r.db.Order("position ASC, id ASC")
+	if departmentID == nil {
+		query = query.Where("department_id IS NULL")
+	} else {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	err := query.Find(&criteria).Error
+	return criteria, err
+}
+
+// ReplaceCriteria swaps the department's rubric for criteria; no criteria reverts it to the global one
+func (r *repository) ReplaceCriteria(departmentID uint, criteria []domain.GradingCriterion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("department_id = ?", departmentID).Delete(&domain.GradingCriterion{}).Error; err != nil {
+			return err
+		}
+		if len(criteria) == 0 {
+			return nil
+		}
+		return tx.Create(&criteria).Error
+	})
+}
+
+func (r *repository) EnsureGlobalCriterion(criterion domain.GradingCriterion) error {
+	var count int64
+	if err := r.db.Model(&domain.GradingCriterion{}).
+		Where("department_id IS NULL AND key = ?", criterion.Key).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	criterion.DepartmentID = nil
+	return r.db.Create(&criterion).Error
+}
+
+func (r *repository) GetExaminers(projectID uint) ([]domain.ProjectExaminer, error) {
+	var examiners []domain.ProjectExaminer
+	err := r.db.Preload("User").Where("project_id = ?", projectID).Order("id").Find(&examiners).Error
+	return examiners, err
+}
+
+func (r *repository) IsExaminer(projectID, userID uint) bool {
+	var count int64
+	r.db.Model(&domain.ProjectExaminer{}).Where("project_id = ? AND user_id = ?", projectID, userID).Count(&count)
+	return count > 0
+}
+
+func (r *repository) AddExaminer(examiner *domain.ProjectExaminer) error {
+	return r.db.Create(examiner).Error
+}
+
+func (r *repository) RemoveExaminer(projectID, userID uint) (int64, error) {
+	result := r.db.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&domain.ProjectExaminer{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) GetSheets(projectID uint) ([]domain.GradeSheet, error) {
+	var sheets []domain.GradeSheet
+	err := r.db.Preload("Grader").Where("project_id = ?", projectID).Order("submitted_at").Find(&sheets).Error
+	return sheets, err
+}
+
+func (r *repository) GetSheet(projectID, graderID uint) (*domain.GradeSheet, error) {
+	var sheet domain.GradeSheet
+	err := r.db.Where("project_id = ? AND grader_id = ?", projectID, graderID).First(&sheet).Error
+	return &sheet, err
+}
+
+func (r *repository) SaveSheet(sheet *domain.GradeSheet) error {
+	return r.db.Omit("Grader").Save(sheet).Error
+}
+
+func (r *repository) GetGrade(projectID uint) (*domain.ProjectGrade, error) {
+	var grade domain.ProjectGrade
+	err := r.db.Where("project_id = ?", projectID).First(&grade).Error
+	return &grade, err
+}
+
+func (r *repository) CreateGrade(grade *domain.ProjectGrade) error {
+	return r.db.Create(grade).Error
+}
@@ -0,0 +1,107 @@
+package grading
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRubric is seeded as the global grading rubric; weights add up to 100
+var DefaultRubric = []domain.GradingCriterion{
+	{Key: "problem_analysis", Label: "Problem analysis and requirements", Weight: 20, Position: 1},
+	{Key: "design", Label: "Design and methodology", Weight: 20, Position: 2},
+	{Key: "implementation", Label: "Implementation quality", Weight: 30, Position: 3},
+	{Key: "documentation", Label: "Final report", Weight: 15, Position: 4},
+	{Key: "presentation", Label: "Presentation and defense", Weight: 15, Position: 5},
+}
+
+var criterionKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+type CriterionRequest struct {
+	Key    string `json:"key" binding:"required" example:"implementation"`
+	Label  string `json:"label" binding:"required" example:"Implementation quality"`
+	Weight int    `json:"weight" binding:"required,min=1,max=100" example:"30"`
+}
+
+type UpdateRubricRequest struct {
+	Criteria []CriterionRequest `json:"criteria"` // empty reverts to the global rubric
+}
+
+// Rubric is the grading rubric that applies to a department
+type Rubric struct {
+	DepartmentID *uint                     `json:"department_id,omitempty"`
+	Inherited    bool                      `json:"inherited"` // true when the global rubric applies
+	Criteria     []domain.GradingCriterion `json:"criteria"`
+}
+
+// EnsureDefaultRubric seeds any missing global rubric criteria
+func (s *Service) EnsureDefaultRubric() error {
+	for _, criterion := range DefaultRubric {
+		if err := s.repo.EnsureGlobalCriterion(criterion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRubric returns the department's rubric, falling back to the global one
+func (s *Service) GetRubric(departmentID uint) (*Rubric, error) {
+	criteria, err := s.repo.GetCriteria(&departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(criteria) > 0 {
+		return &Rubric{DepartmentID: &departmentID, Criteria: criteria}, nil
+	}
+
+	criteria, err = s.repo.GetCriteria(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Rubric{DepartmentID: &departmentID, Inherited: true, Criteria: criteria}, nil
+}
+
+// UpdateRubric replaces the department's rubric. Weights must add up to 100. Grade sheets keep
+// the criteria they were scored against, so changing the rubric does not alter submitted grades.
+func (s *Service) UpdateRubric(departmentID uint, req UpdateRubricRequest) (*Rubric, error) {
+	criteria := make([]domain.GradingCriterion, 0, len(req.Criteria))
+	seen := make(map[string]bool)
+	total := 0
+	for i, in := range req.Criteria {
+		key := strings.ToLower(strings.TrimSpace(in.Key))
+		label := strings.TrimSpace(in.Label)
+		if !criterionKeyPattern.MatchString(key) {
+			return nil, errors.New("criterion keys may only contain lowercase letters, digits and underscores")
+		}
+		if label == "" {
+			return nil, errors.New("criterion labels are required")
+		}
+		if in.Weight <= 0 {
+			return nil, errors.New("criterion weights must be positive")
+		}
+		if seen[key] {
+			return nil, errors.New("duplicate criterion key: " + key)
+		}
+		seen[key] = true
+		total += in.Weight
+
+		deptID := departmentID
+		criteria = append(criteria, domain.GradingCriterion{
+			DepartmentID: &deptID,
+			Key:          key,
+			Label:        label,
+			Weight:       in.Weight,
+			Position:     i + 1,
+		})
+	}
+	if len(criteria) > 0 && total != 100 {
+		return nil, fmt.Errorf("criterion weights must add up to 100, got %d", total)
+	}
+
+	if err := s.repo.ReplaceCriteria(departmentID, criteria); err != nil {
+		return nil, err
+	}
+	return s.GetRubric(departmentID)
+}
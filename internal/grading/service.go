@@ -0,0 +1,461 @@
+package grading
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// AdvisorShare is the advisor's part of the final grade; the examiners' average makes up the rest.
+// Without examiners the advisor's grade is the final grade.
+const AdvisorShare = 0.5
+
+var (
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrGradingForbidden   = errors.New("you do not have access to this project's grading")
+	ErrNotGrader          = errors.New("only the project's advisor and examiners can grade it")
+	ErrFinalReportPending = errors.New("grading opens once the final report is approved")
+	ErrGradeLocked        = errors.New("the grade is locked")
+	ErrGradesIncomplete   = errors.New("the advisor and every examiner must submit a grade first")
+)
+
+type GradingStatus string
+
+const (
+	GradingAwaitingReport GradingStatus = "awaiting_final_report"
+	GradingInProgress     GradingStatus = "in_progress"
+	GradingReady          GradingStatus = "ready_to_lock" // every grader has submitted
+	GradingLocked         GradingStatus = "locked"
+)
+
+type Service struct {
+	repo Repository
+	bus  *events.Bus
+}
+
+func NewService(repo Repository, bus *events.Bus) *Service {
+	return &Service{repo: repo, bus: bus}
+}
+
+type SubmitGradeRequest struct {
+	Scores  map[string]float64 `json:"scores" binding:"required"` // criterion key -> score out of 100
+	Comment string             `json:"comment"`
+}
+
+type AssignExaminerRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// Grader is someone who grades the project
+type Grader struct {
+	UserID    uint              `json:"user_id"`
+	Name      string            `json:"name"`
+	Role      domain.GraderRole `json:"role"`
+	Submitted bool              `json:"submitted"`
+}
+
+type SheetResponse struct {
+	GraderID    uint                    `json:"grader_id"`
+	GraderName  string                  `json:"grader_name"`
+	GraderRole  domain.GraderRole       `json:"grader_role"`
+	Scores      []domain.CriterionScore `json:"scores"`
+	Total       float64                 `json:"total"`
+	Comment     string                  `json:"comment,omitempty"`
+	SubmittedAt time.Time               `json:"submitted_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// ProjectGrading is the state of a project's grading. The team sees it read-only: only the
+// status until the grade is locked, then the final grade and every grade sheet.
+type ProjectGrading struct {
+	ProjectID  uint                      `json:"project_id"`
+	Status     GradingStatus             `json:"status"`
+	Rubric     []domain.GradingCriterion `json:"rubric,omitempty"`
+	Graders    []Grader                  `json:"graders,omitempty"`
+	Sheets     []SheetResponse           `json:"sheets,omitempty"`
+	Projected  *domain.ProjectGrade      `json:"projected,omitempty"` // the grade as it stands, before locking
+	FinalGrade *domain.ProjectGrade      `json:"final_grade,omitempty"`
+}
+
+// gradingContext is a project with everything needed to judge its grading
+type gradingContext struct {
+	project   *domain.Project
+	advisorID uint
+	examiners []domain.ProjectExaminer
+	sheets    []domain.GradeSheet // only those of the current advisor and examiners
+	grade     *domain.ProjectGrade
+	reportOK  bool
+}
+
+func (s *Service) load(projectID uint) (*gradingContext, error) {
+	project, err := s.repo.GetProject(projectID)
+	if err != nil {
+		return nil, ErrProjectNotFound
+	}
+	ctx := &gradingContext{project: project}
+	if project.Proposal.AdvisorID != nil {
+		ctx.advisorID = *project.Proposal.AdvisorID
+	}
+	if ctx.examiners, err = s.repo.GetExaminers(projectID); err != nil {
+		return nil, err
+	}
+	if ctx.reportOK, err = s.repo.IsFinalReportApproved(projectID); err != nil {
+		return nil, err
+	}
+	if grade, err := s.repo.GetGrade(projectID); err == nil {
+		ctx.grade = grade
+	}
+
+	sheets, err := s.repo.GetSheets(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sheet := range sheets {
+		if ctx.graderRole(sheet.GraderID) != "" {
+			ctx.sheets = append(ctx.sheets, sheet)
+		}
+	}
+	return ctx, nil
+}
+
+func (c *gradingContext) graderRole(userID uint) domain.GraderRole {
+	if c.advisorID != 0 && userID == c.advisorID {
+		return domain.GraderAdvisor
+	}
+	for _, e := range c.examiners {
+		if e.UserID == userID {
+			return domain.GraderExaminer
+		}
+	}
+	return ""
+}
+
+func (c *gradingContext) departmentID() uint {
+	if c.project.DepartmentID != 0 {
+		return c.project.DepartmentID
+	}
+	return c.project.Team.DepartmentID
+}
+
+func (c *gradingContext) isTeamMember(userID uint) bool {
+	for _, m := range c.project.Team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *gradingContext) submitted(userID uint) bool {
+	for _, sheet := range c.sheets {
+		if sheet.GraderID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *gradingContext) status() GradingStatus {
+	switch {
+	case c.grade != nil:
+		return GradingLocked
+	case !c.reportOK:
+		return GradingAwaitingReport
+	case c.advisorID == 0 || !c.submitted(c.advisorID):
+		return GradingInProgress
+	}
+	for _, e := range c.examiners {
+		if !c.submitted(e.UserID) {
+			return GradingInProgress
+		}
+	}
+	return GradingReady
+}
+
+// compute weighs the submitted sheets into a grade; nil until the advisor has graded
+func (c *gradingContext) compute() *domain.ProjectGrade {
+	var advisor *float64
+	var examinerSum float64
+	examinerCount := 0
+	for _, sheet := range c.sheets {
+		if sheet.GraderID == c.advisorID {
+			total := sheet.Total
+			advisor = &total
+		} else {
+			examinerSum += sheet.Total
+			examinerCount++
+		}
+	}
+	if advisor == nil {
+		return nil
+	}
+
+	grade := &domain.ProjectGrade{ProjectID: c.project.ID, AdvisorScore: *advisor, FinalScore: *advisor}
+	if examinerCount > 0 {
+		examiner := round2(examinerSum / float64(examinerCount))
+		grade.ExaminerScore = &examiner
+		grade.FinalScore = round2(AdvisorShare**advisor + (1-AdvisorShare)*examiner)
+	}
+	grade.LetterGrade = LetterGrade(grade.FinalScore)
+	return grade
+}
+
+// GetProjectGrading shows the grading to the department admin, the graders and, read-only, the team
+func (s *Service) GetProjectGrading(projectID, userID uint, role enums.Role, deptID uint) (*ProjectGrading, error) {
+	ctx, err := s.load(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	staff := (role == enums.RoleAdmin && deptID == ctx.departmentID()) || ctx.graderRole(userID) != ""
+	if !staff && !ctx.isTeamMember(userID) {
+		return nil, ErrGradingForbidden
+	}
+
+	result := &ProjectGrading{ProjectID: projectID, Status: ctx.status(), FinalGrade: ctx.grade}
+	if !staff && ctx.grade == nil {
+		return result, nil
+	}
+
+	for _, sheet := range ctx.sheets {
+		name := ""
+		if sheet.Grader != nil {
+			name = sheet.Grader.Name
+		}
+		result.Sheets = append(result.Sheets, SheetResponse{
+			GraderID:    sheet.GraderID,
+			GraderName:  name,
+			GraderRole:  sheet.GraderRole,
+			Scores:      sheet.Scores,
+			Total:       sheet.Total,
+			Comment:     sheet.Comment,
+			SubmittedAt: sheet.SubmittedAt,
+			UpdatedAt:   sheet.UpdatedAt,
+		})
+	}
+	if !staff {
+		return result, nil
+	}
+
+	rubric, err := s.GetRubric(ctx.departmentID())
+	if err != nil {
+		return nil, err
+	}
+	result.Rubric = rubric.Criteria
+	if ctx.advisorID != 0 {
+		name := ""
+		if advisor, err := s.repo.GetUser(ctx.advisorID); err == nil {
+			name = advisor.Name
+		}
+		result.Graders = append(result.Graders, Grader{UserID: ctx.advisorID, Name: name, Role: domain.GraderAdvisor, Submitted: ctx.submitted(ctx.advisorID)})
+	}
+	for _, e := range ctx.examiners {
+		name := ""
+		if e.User != nil {
+			name = e.User.Name
+		}
+		result.Graders = append(result.Graders, Grader{UserID: e.UserID, Name: name, Role: domain.GraderExaminer, Submitted: ctx.submitted(e.UserID)})
+	}
+	if ctx.grade == nil {
+		result.Projected = ctx.compute()
+	}
+	return result, nil
+}
+
+// SubmitGrade records or revises the grader's sheet. Every rubric criterion needs a score from 0 to 100.
+func (s *Service) SubmitGrade(projectID, graderID uint, req SubmitGradeRequest) (*domain.GradeSheet, error) {
+	ctx, err := s.load(projectID)
+	if err != nil {
+		return nil, err
+	}
+	role := ctx.graderRole(graderID)
+	if role == "" {
+		return nil, ErrNotGrader
+	}
+	if ctx.grade != nil {
+		return nil, ErrGradeLocked
+	}
+	if !ctx.reportOK {
+		return nil, ErrFinalReportPending
+	}
+
+	rubric, err := s.GetRubric(ctx.departmentID())
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]domain.CriterionScore, 0, len(rubric.Criteria))
+	known := make(map[string]bool, len(rubric.Criteria))
+	var missing []string
+	total := 0.0
+	for _, criterion := range rubric.Criteria {
+		known[criterion.Key] = true
+		score, ok := req.Scores[criterion.Key]
+		if !ok {
+			missing = append(missing, criterion.Key)
+			continue
+		}
+		if score < 0 || score > 100 {
+			return nil, fmt.Errorf("score for %s must be between 0 and 100", criterion.Key)
+		}
+		scores = append(scores, domain.CriterionScore{Key: criterion.Key, Label: criterion.Label, Weight: criterion.Weight, Score: score})
+		total += score * float64(criterion.Weight) / 100
+	}
+	for key := range req.Scores {
+		if !known[key] {
+			return nil, errors.New("unknown rubric criterion: " + key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.New("missing scores for: " + strings.Join(missing, ", "))
+	}
+
+	sheet, err := s.repo.GetSheet(projectID, graderID)
+	if err != nil {
+		sheet = &domain.GradeSheet{ProjectID: projectID, GraderID: graderID, SubmittedAt: time.Now()}
+	}
+	sheet.GraderRole = role
+	sheet.Scores = scores
+	sheet.Total = round2(total)
+	sheet.Comment = strings.TrimSpace(req.Comment)
+	if err := s.repo.SaveSheet(sheet); err != nil {
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// LockGrade fixes the final grade once every grader has submitted. Only the admin of the
+// project's department can lock, and a locked grade cannot be changed.
+func (s *Service) LockGrade(projectID, adminID, deptID uint) (*domain.ProjectGrade, error) {
+	ctx, err := s.load(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if deptID != ctx.departmentID() {
+		return nil, ErrGradingForbidden
+	}
+	switch ctx.status() {
+	case GradingLocked:
+		return nil, ErrGradeLocked
+	case GradingAwaitingReport:
+		return nil, ErrFinalReportPending
+	case GradingInProgress:
+		return nil, ErrGradesIncomplete
+	}
+
+	grade := ctx.compute()
+	grade.LockedBy = adminID
+	grade.LockedAt = time.Now()
+	if err := s.repo.CreateGrade(grade); err != nil {
+		return nil, err
+	}
+
+	var members []uint
+	for _, m := range ctx.project.Team.Members {
+		if m.InvitationStatus == enums.InvitationStatusAccepted {
+			members = append(members, m.UserID)
+		}
+	}
+	s.bus.Publish(events.Event{
+		Name:       events.ProjectGradeLocked,
+		EntityType: "project",
+		EntityID:   projectID,
+		ActorID:    adminID,
+		UserIDs:    members,
+		Data: map[string]interface{}{
+			"final_score":  grade.FinalScore,
+			"letter_grade": grade.LetterGrade,
+		},
+	})
+	return grade, nil
+}
+
+// AssignExaminer adds an active teacher, or an external examiner of the department whose access
+// has not expired, to the project's defense committee
+func (s *Service) AssignExaminer(projectID, userID, adminID, deptID uint) (*domain.ProjectExaminer, error) {
+	ctx, err := s.load(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if deptID != ctx.departmentID() {
+		return nil, ErrGradingForbidden
+	}
+	if ctx.grade != nil {
+		return nil, ErrGradeLocked
+	}
+	user, err := s.repo.GetUser(userID)
+	if err != nil || !canExamine(user, ctx.departmentID()) {
+		return nil, errors.New("examiners must be active teachers or external examiners of the department")
+	}
+	if ctx.graderRole(userID) != "" {
+		return nil, errors.New("user already grades this project")
+	}
+
+	examiner := &domain.ProjectExaminer{ProjectID: projectID, UserID: userID, AssignedBy: adminID}
+	if err := s.repo.AddExaminer(examiner); err != nil {
+		return nil, err
+	}
+	examiner.User = user
+	return examiner, nil
+}
+
+func canExamine(user *domain.User, departmentID uint) bool {
+	if !user.IsActive {
+		return false
+	}
+	switch user.Role {
+	case enums.RoleAdvisor:
+		return true
+	case enums.RoleExaminer:
+		return user.DepartmentID == departmentID &&
+			(user.AccessExpiresAt == nil || time.Now().Before(*user.AccessExpiresAt))
+	}
+	return false
+}
+
+// RemoveExaminer takes a teacher off the committee; a grade they already submitted no longer counts
+func (s *Service) RemoveExaminer(projectID, userID, deptID uint) error {
+	ctx, err := s.load(projectID)
+	if err != nil {
+		return err
+	}
+	if deptID != ctx.departmentID() {
+		return ErrGradingForbidden
+	}
+	if ctx.grade != nil {
+		return ErrGradeLocked
+	}
+	removed, err := s.repo.RemoveExaminer(projectID, userID)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return errors.New("examiner not found")
+	}
+	return nil
+}
+
+// LetterGrade maps a score out of 100 to the university's letter scale
+func LetterGrade(score float64) string {
+	scale := []struct {
+		min    float64
+		letter string
+	}{
+		{90, "A+"}, {85, "A"}, {80, "A-"}, {75, "B+"}, {70, "B"}, {65, "B-"},
+		{60, "C+"}, {50, "C"}, {45, "C-"}, {40, "D"},
+	}
+	for _, step := range scale {
+		if score >= step.min {
+			return step.letter
+		}
+	}
+	return "F"
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"backend/internal/auth"
+	"backend/internal/graphql/generated"
+	"backend/internal/proposals"
+	"backend/internal/teams"
+	"backend/pkg/response"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MaxQueryComplexity bounds how many fields one query may resolve
+const MaxQueryComplexity = 500
+
+type Handler struct {
+	server   *handler.Server
+	resolver *Resolver
+}
+
+func NewHandler(teamService *teams.Service, proposalService *proposals.Service, db *gorm.DB) *Handler {
+	resolver := &Resolver{teamService: teamService, proposalService: proposalService, db: db}
+	server := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+	server.AddTransport(transport.POST{})
+	server.Use(extension.FixedComplexityLimit(MaxQueryComplexity))
+	return &Handler{server: server, resolver: resolver}
+}
+
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query godoc
+// @Summary GraphQL query endpoint
+// @Description Read-only GraphQL API for nested dashboard data (team → members → proposals → versions → feedback), defined in internal/graphql/schema.graphqls. Root fields: me, myTeams, team(id), proposals(status, archived), proposal(id). The REST access rules apply, including blind review, and emails are only returned for the caller. Responses follow the GraphQL spec ({data, errors}) rather than the REST envelope.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /graphql [post]
+func (h *Handler) Query(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	ctx := withViewer(c.Request.Context(), claims.(*auth.TokenClaims))
+	ctx = withLoaders(ctx, h.resolver.newLoaders(claims.(*auth.TokenClaims)))
+	h.server.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
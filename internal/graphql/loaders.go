@@ -0,0 +1,159 @@
+package graphql
+
+import (
+	"backend/internal/auth"
+	"backend/internal/domain"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// loaderWait is how long a loader collects keys before querying them together. gqlgen resolves
+// the fields of list elements concurrently, so the lookups of one list land in the same batch.
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// loaders batch the nested lookups of one request so a list costs one query per field rather than
+// one per element. Proposals are loaded through the REST access rules of the caller.
+type loaders struct {
+	users     *loader[uint, *domain.User]
+	teams     *loader[uint, *domain.Team]
+	proposals *loader[uint, *domain.Proposal]
+	feedback  *loader[uint, []domain.Feedback] // by proposal, newest first
+}
+
+func (r *Resolver) newLoaders(claims *auth.TokenClaims) *loaders {
+	return &loaders{
+		users: newLoader(func(ids []uint) (map[uint]*domain.User, error) {
+			var list []domain.User
+			if err := r.db.Where("id IN ?", ids).Find(&list).Error; err != nil {
+				return nil, err
+			}
+			out := make(map[uint]*domain.User, len(list))
+			for i := range list {
+				out[list[i].ID] = &list[i]
+			}
+			return out, nil
+		}),
+		teams: newLoader(func(ids []uint) (map[uint]*domain.Team, error) {
+			list, err := r.teamService.GetTeams(ids)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[uint]*domain.Team, len(list))
+			for i := range list {
+				out[list[i].ID] = &list[i]
+			}
+			return out, nil
+		}),
+		proposals: newLoader(func(ids []uint) (map[uint]*domain.Proposal, error) {
+			list, err := r.proposalService.GetProposalsByIDs(ids, claims.UserID, claims.Role, claims.DepartmentID)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[uint]*domain.Proposal, len(list))
+			for i := range list {
+				out[list[i].ID] = &list[i]
+			}
+			return out, nil
+		}),
+		feedback: newLoader(func(proposalIDs []uint) (map[uint][]domain.Feedback, error) {
+			var list []domain.Feedback
+			if err := r.db.Where("proposal_id IN ?", proposalIDs).Order("created_at DESC").Find(&list).Error; err != nil {
+				return nil, err
+			}
+			out := make(map[uint][]domain.Feedback, len(proposalIDs))
+			for _, f := range list {
+				out[f.ProposalID] = append(out[f.ProposalID], f)
+			}
+			return out, nil
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) (*loaders, error) {
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		return nil, errors.New("request loaders missing")
+	}
+	return l, nil
+}
+
+// loader batches and caches the lookups of one request. Keys asked for within loaderWait of each
+// other are fetched together; a key is fetched at most once.
+type loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending *loaderBatch[K, V]
+	batches map[K]*loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, batches: make(map[K]*loaderBatch[K, V])}
+}
+
+// load returns the value for key; ok is false when there is none
+func (l *loader[K, V]) load(key K) (V, bool, error) {
+	b := l.enqueue(key)
+	<-b.done
+	v, ok := b.values[key]
+	return v, ok, b.err
+}
+
+// loadMany returns the values found for keys in the order of keys
+func (l *loader[K, V]) loadMany(keys []K) ([]V, error) {
+	batches := make([]*loaderBatch[K, V], len(keys))
+	for i, key := range keys {
+		batches[i] = l.enqueue(key)
+	}
+	out := make([]V, 0, len(keys))
+	for i, b := range batches {
+		<-b.done
+		if b.err != nil {
+			return nil, b.err
+		}
+		if v, ok := b.values[keys[i]]; ok {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (l *loader[K, V]) enqueue(key K) *loaderBatch[K, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.batches[key]; ok {
+		return b
+	}
+	if l.pending == nil {
+		b := &loaderBatch[K, V]{done: make(chan struct{})}
+		l.pending = b
+		time.AfterFunc(loaderWait, func() { l.dispatch(b) })
+	}
+	b := l.pending
+	b.keys = append(b.keys, key)
+	l.batches[key] = b
+	return b
+}
+
+func (l *loader[K, V]) dispatch(b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	l.pending = nil
+	l.mu.Unlock()
+	b.values, b.err = l.fetch(b.keys)
+	close(b.done)
+}
@@ -0,0 +1,76 @@
+// Package model holds the GraphQL object types. They carry only what the caller may see; the
+// resolvers in the graphql package fill them in after the access checks.
+package model
+
+import "time"
+
+// User is a user as seen by the caller. Email is only set for the caller themselves.
+type User struct {
+	ID           uint
+	Name         string
+	Email        *string
+	Role         string
+	DepartmentID uint
+}
+
+// Team is a team the caller may view; advisor and proposals are resolved on demand
+type Team struct {
+	ID           uint
+	Name         string
+	DepartmentID uint
+	IsFinalized  bool
+	CreatedAt    time.Time
+	Members      []*TeamMember
+	AdvisorID    *uint
+	ProposalIDs  []uint
+}
+
+type TeamMember struct {
+	Role string
+	User *User
+}
+
+// Proposal is a proposal the caller may view. TeamID is nil when blind review hides the team.
+type Proposal struct {
+	ID             uint
+	Status         string
+	AcademicYear   string
+	IsArchived     bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	StudentsHidden bool
+	Versions       []*ProposalVersion
+	TeamID         *uint
+	AdvisorID      *uint
+}
+
+// LatestVersion is the newest version; versions are kept newest first
+func (p *Proposal) LatestVersion() *ProposalVersion {
+	if len(p.Versions) == 0 {
+		return nil
+	}
+	return p.Versions[0]
+}
+
+type ProposalVersion struct {
+	ID               uint
+	VersionNumber    int
+	Title            string
+	Abstract         string
+	ProblemStatement string
+	Objectives       string
+	Methodology      string
+	ExpectedTimeline string
+	ExpectedOutcomes string
+	IsApproved       bool
+	CreatedAt        time.Time
+}
+
+type Feedback struct {
+	ID         uint
+	VersionID  uint
+	Decision   string
+	Comment    string
+	CreatedAt  time.Time
+	ReviewerID uint
+}
@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"backend/internal/graphql/model"
+	"backend/internal/proposals"
+	"backend/internal/teams"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Resolver answers the GraphQL queries. The root fields apply the REST access rules through the
+// team and proposal services; nested fields only reach objects of an already visible parent.
+type Resolver struct {
+	teamService     *teams.Service
+	proposalService *proposals.Service
+	db              *gorm.DB
+}
+
+// loadUser resolves a user reference; a user that no longer exists resolves to null
+func (r *Resolver) loadUser(ctx context.Context, id *uint) (*model.User, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		return nil, nil
+	}
+	l, err := loadersFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u, _, err := l.users.load(*id)
+	if err != nil {
+		return nil, err
+	}
+	return user(v, u), nil
+}
@@ -0,0 +1,198 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.70
+
+import (
+	"backend/internal/domain"
+	"backend/internal/graphql/generated"
+	"backend/internal/graphql/model"
+	"backend/internal/proposals"
+	"context"
+	"errors"
+)
+
+// Reviewer is the resolver for the reviewer field.
+func (r *feedbackResolver) Reviewer(ctx context.Context, obj *model.Feedback) (*model.User, error) {
+	return r.loadUser(ctx, &obj.ReviewerID)
+}
+
+// Team is the resolver for the team field.
+func (r *proposalResolver) Team(ctx context.Context, obj *model.Proposal) (*model.Team, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if obj.TeamID == nil {
+		return nil, nil
+	}
+	l, err := loadersFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, ok, err := l.teams.load(*obj.TeamID)
+	if err != nil || !ok {
+		return nil, nil
+	}
+	return team(v, t), nil
+}
+
+// Advisor is the resolver for the advisor field.
+func (r *proposalResolver) Advisor(ctx context.Context, obj *model.Proposal) (*model.User, error) {
+	return r.loadUser(ctx, obj.AdvisorID)
+}
+
+// Feedback is the resolver for the feedback field.
+func (r *proposalResolver) Feedback(ctx context.Context, obj *model.Proposal) ([]*model.Feedback, error) {
+	l, err := loadersFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, _, err := l.feedback.load(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.Feedback, 0, len(list))
+	for _, f := range list {
+		out = append(out, &model.Feedback{
+			ID:         f.ID,
+			VersionID:  f.ProposalVersionID,
+			Decision:   string(f.Decision),
+			Comment:    f.Comment,
+			CreatedAt:  f.CreatedAt,
+			ReviewerID: f.ReviewerID,
+		})
+	}
+	return out, nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var u domain.User
+	if err := r.db.First(&u, v.claims.UserID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user(v, &u), nil
+}
+
+// MyTeams is the resolver for the myTeams field.
+func (r *queryResolver) MyTeams(ctx context.Context) ([]*model.Team, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	myTeams, err := r.teamService.GetMyTeams(v.claims.UserID, false)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.Team, 0, len(myTeams))
+	for i := range myTeams {
+		out = append(out, team(v, &myTeams[i]))
+	}
+	return out, nil
+}
+
+// Team is the resolver for the team field.
+func (r *queryResolver) Team(ctx context.Context, id uint) (*model.Team, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := r.teamService.GetTeam(id)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if !v.canViewTeam(t) {
+		return nil, errors.New("you do not have permission to view this team")
+	}
+	if r.proposalService.TeamHiddenFrom(t.ID, v.claims.UserID, v.claims.Role) {
+		return nil, errors.New("the team is hidden under blind review until its proposal is decided")
+	}
+	return team(v, t), nil
+}
+
+// Proposals is the resolver for the proposals field.
+func (r *queryResolver) Proposals(ctx context.Context, status *string, archived *bool) ([]*model.Proposal, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var q proposals.ProposalListQuery
+	if status != nil {
+		q.Status = *status
+	}
+	if archived != nil {
+		q.Archived = *archived
+	}
+	list, _, err := r.proposalService.GetProposals(q, v.claims.UserID, v.claims.Role, v.claims.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.Proposal, 0, len(list))
+	for i := range list {
+		out = append(out, r.proposal(v, &list[i]))
+	}
+	return out, nil
+}
+
+// Proposal is the resolver for the proposal field.
+func (r *queryResolver) Proposal(ctx context.Context, id uint) (*model.Proposal, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.proposalService.GetProposal(id, v.claims.UserID, v.claims.Role, v.claims.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	return r.proposal(v, p), nil
+}
+
+// Advisor is the resolver for the advisor field.
+func (r *teamResolver) Advisor(ctx context.Context, obj *model.Team) (*model.User, error) {
+	return r.loadUser(ctx, obj.AdvisorID)
+}
+
+// Proposals is the resolver for the proposals field.
+func (r *teamResolver) Proposals(ctx context.Context, obj *model.Team) ([]*model.Proposal, error) {
+	v, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l, err := loadersFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The loader applies the REST access rules; the proposals the caller may not see are left out
+	list, err := l.proposals.loadMany(obj.ProposalIDs)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.Proposal, 0, len(list))
+	for _, p := range list {
+		out = append(out, r.proposal(v, p))
+	}
+	return out, nil
+}
+
+// Feedback returns generated.FeedbackResolver implementation.
+func (r *Resolver) Feedback() generated.FeedbackResolver { return &feedbackResolver{r} }
+
+// Proposal returns generated.ProposalResolver implementation.
+func (r *Resolver) Proposal() generated.ProposalResolver { return &proposalResolver{r} }
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+// Team returns generated.TeamResolver implementation.
+func (r *Resolver) Team() generated.TeamResolver { return &teamResolver{r} }
+
+type feedbackResolver struct{ *Resolver }
+type proposalResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type teamResolver struct{ *Resolver }
@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/internal/graphql/model"
+	"backend/internal/proposals"
+	"backend/pkg/enums"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+type viewerKey struct{}
+
+// viewer is the caller of one GraphQL request. Whether blind review applies is read once per
+// request; field resolvers may run concurrently.
+type viewer struct {
+	claims    *auth.TokenClaims
+	blindOnce sync.Once
+	blind     bool
+}
+
+func withViewer(ctx context.Context, claims *auth.TokenClaims) context.Context {
+	return context.WithValue(ctx, viewerKey{}, &viewer{claims: claims})
+}
+
+func viewerFrom(ctx context.Context) (*viewer, error) {
+	v, ok := ctx.Value(viewerKey{}).(*viewer)
+	if !ok || v.claims == nil {
+		return nil, errors.New("unauthorized")
+	}
+	return v, nil
+}
+
+// blindReview reports whether the caller is an advisor reviewing blind
+func (r *Resolver) blindReview(v *viewer) bool {
+	v.blindOnce.Do(func() {
+		v.blind = r.proposalService.BlindReviewApplies(v.claims.UserID, v.claims.Role)
+	})
+	return v.blind
+}
+
+// canViewTeam mirrors the REST access rules: members, the assigned advisor, or an admin of the
+// team's department
+func (v *viewer) canViewTeam(team *domain.Team) bool {
+	switch v.claims.Role {
+	case enums.RoleAdmin:
+		return team.DepartmentID == v.claims.DepartmentID
+	case enums.RoleAdvisor:
+		return team.AdvisorID != nil && *team.AdvisorID == v.claims.UserID
+	default:
+		for _, m := range team.Members {
+			if m.UserID == v.claims.UserID && m.InvitationStatus == enums.InvitationStatusAccepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// proposal maps a proposal the caller may view, hiding its team under blind review like the REST views
+func (r *Resolver) proposal(v *viewer, p *domain.Proposal) *model.Proposal {
+	resp := proposals.ReviewerProposalResponse(p, r.blindReview(v))
+	out := &model.Proposal{
+		ID:             resp.ID,
+		Status:         string(resp.Status),
+		AcademicYear:   resp.AcademicYear,
+		IsArchived:     resp.IsArchived,
+		CreatedAt:      resp.CreatedAt,
+		UpdatedAt:      resp.UpdatedAt,
+		StudentsHidden: resp.StudentsHidden,
+		AdvisorID:      resp.AdvisorID,
+		Versions:       make([]*model.ProposalVersion, 0, len(resp.Versions)),
+	}
+	if resp.Team != nil && !resp.StudentsHidden {
+		out.TeamID = &resp.Team.ID
+	}
+	for _, version := range resp.Versions {
+		out.Versions = append(out.Versions, &model.ProposalVersion{
+			ID:               version.ID,
+			VersionNumber:    version.VersionNumber,
+			Title:            version.Title,
+			Abstract:         version.Abstract,
+			ProblemStatement: version.ProblemStatement,
+			Objectives:       version.Objectives,
+			Methodology:      version.Methodology,
+			ExpectedTimeline: version.ExpectedTimeline,
+			ExpectedOutcomes: version.ExpectedOutcomes,
+			IsApproved:       version.IsApproved,
+			CreatedAt:        version.CreatedAt,
+		})
+	}
+	return out
+}
+
+// team maps a team the caller may view with its accepted members, newest proposal first
+func team(v *viewer, t *domain.Team) *model.Team {
+	out := &model.Team{
+		ID:           t.ID,
+		Name:         t.Name,
+		DepartmentID: t.DepartmentID,
+		IsFinalized:  t.IsFinalized,
+		CreatedAt:    t.CreatedAt,
+		AdvisorID:    t.AdvisorID,
+		Members:      make([]*model.TeamMember, 0, len(t.Members)),
+	}
+	for i := range t.Members {
+		m := &t.Members[i]
+		if m.InvitationStatus != enums.InvitationStatusAccepted {
+			continue
+		}
+		out.Members = append(out.Members, &model.TeamMember{Role: m.Role, User: user(v, &m.User)})
+	}
+
+	list := append([]domain.Proposal(nil), t.Proposals...)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	for _, p := range list {
+		out.ProposalIDs = append(out.ProposalIDs, p.ID)
+	}
+	return out
+}
+
+// user maps a user; the email is only shown to the user themselves
+func user(v *viewer, u *domain.User) *model.User {
+	if u == nil || u.ID == 0 {
+		return nil
+	}
+	out := &model.User{ID: u.ID, Name: u.Name, Role: string(u.Role), DepartmentID: u.DepartmentID}
+	if u.ID == v.claims.UserID {
+		email := u.Email
+		out.Email = &email
+	}
+	return out
+}
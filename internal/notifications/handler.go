@@ -21,14 +21,15 @@ func NewHandler(service *Service) *Handler {
 
 // GetNotifications returns notifications for the authenticated user
 // @Summary Get user notifications
-// @Description Get all notifications for the authenticated user
+// @Description Get all notifications for the authenticated user. By default repeated notifications about the same thing, e.g. several new versions of one proposal, are grouped into a thread: its latest notification with count, unread_count and first_at. Marking or deleting a thread's notification applies to the whole thread.
 // @Tags Notifications
 // @Produce json
 // @Security BearerAuth
 // @Param is_read query bool false "Filter by read status"
+// @Param grouped query bool false "Group repeated notifications into threads (default: true)"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 20, max: 50)"
-// @Success 200 {object} response.Response
+// @Success 200 {object} response.Response{data=[]Thread}
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /notifications [get]
@@ -48,6 +49,8 @@ func (h *Handler) GetNotifications(c *gin.Context) {
 		isRead = &val
 	}
 
+	grouped := c.DefaultQuery("grouped", "true") != "false"
+
 	page := 1
 	if pageStr := c.Query("page"); pageStr != "" {
 		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
@@ -62,7 +65,7 @@ func (h *Handler) GetNotifications(c *gin.Context) {
 		}
 	}
 
-	notifications, unreadCount, err := h.service.GetUserNotifications(userClaims.UserID, isRead, page, limit)
+	notifications, total, unreadCount, err := h.service.GetUserNotifications(userClaims.UserID, isRead, grouped, page, limit)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Failed to fetch notifications", err.Error())
 		return
@@ -72,15 +75,17 @@ func (h *Handler) GetNotifications(c *gin.Context) {
 		"notifications": notifications,
 		"unread_count":  unreadCount,
 		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + int64(limit) - 1) / int64(limit),
 		},
 	})
 }
 
 // MarkAsRead marks a notification as read
 // @Summary Mark notification as read
-// @Description Mark a specific notification as read
+// @Description Mark a specific notification, and the rest of its thread, as read
 // @Tags Notifications
 // @Produce json
 // @Security BearerAuth
@@ -175,3 +180,73 @@ func (h *Handler) GetUnreadCount(c *gin.Context) {
 		"unread_count": count,
 	})
 }
+
+// DeleteNotification deletes a notification
+// @Summary Delete notification
+// @Description Delete one of the authenticated user's notifications along with the rest of its thread
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /notifications/{id} [delete]
+func (h *Handler) DeleteNotification(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid notification ID", err.Error())
+		return
+	}
+
+	err = h.service.DeleteNotification(uint(id), userClaims.UserID)
+	if err != nil {
+		if err.Error() == "notification not found" || err.Error() == "notification does not belong to user" {
+			response.Error(c, http.StatusNotFound, "notification not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to delete notification", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Notification deleted", nil)
+}
+
+// DeleteReadNotifications deletes all read notifications
+// @Summary Delete read notifications
+// @Description Delete every read notification of the authenticated user
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /notifications/read [delete]
+func (h *Handler) DeleteReadNotifications(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	userClaims := claims.(*auth.TokenClaims)
+
+	deleted, err := h.service.DeleteReadNotifications(userClaims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to delete notifications", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Read notifications deleted", gin.H{
+		"deleted": deleted,
+	})
+}
@@ -10,12 +10,18 @@ import (
 // Repository defines the interface for notification data access
 type Repository interface {
 	Create(notification *domain.Notification) error
-	GetByUserID(userID uint, filters map[string]interface{}) ([]domain.Notification, error)
+	GetByUserID(userID uint, filters map[string]interface{}) ([]domain.Notification, int64, error)
+	GetThreadsByUserID(userID uint, filters map[string]interface{}) ([]Thread, int64, error)
 	GetByID(id uint) (*domain.Notification, error)
 	MarkAsRead(id uint, userID uint) error
+	MarkThreadAsRead(userID uint, groupKey string) error
 	MarkAllAsRead(userID uint) error
 	GetUnreadCount(userID uint) (int64, error)
 	Delete(id uint) error
+	DeleteThread(userID uint, groupKey string) error
+	DeleteRead(userID uint) (int64, error)
+	DeleteOlderThan(readBefore time.Time, createdBefore time.Time) (int64, error)
+	GetUserEmail(userID uint) (string, error)
 }
 
 type repository struct {
@@ -31,14 +37,20 @@ func (r *repository) Create(notification *domain.Notification) error {
 	return r.db.Create(notification).Error
 }
 
-func (r *repository) GetByUserID(userID uint, filters map[string]interface{}) ([]domain.Notification, error) {
+func (r *repository) GetByUserID(userID uint, filters map[string]interface{}) ([]domain.Notification, int64, error) {
 	var notifications []domain.Notification
-	query := r.db.Where("user_id = ?", userID)
+	var total int64
+	query := r.db.Model(&domain.Notification{}).Where("user_id = ?", userID)
 
 	if isRead, ok := filters["is_read"]; ok {
 		query = query.Where("is_read = ?", isRead)
 	}
 
+	// Count before pagination
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	// Apply pagination
 	if page, ok := filters["page"].(int); ok {
 		limit := 20
@@ -50,7 +62,41 @@ func (r *repository) GetByUserID(userID uint, filters map[string]interface{}) ([
 	}
 
 	err := query.Order("created_at DESC").Find(&notifications).Error
-	return notifications, err
+	return notifications, total, err
+}
+
+// GetThreadsByUserID pages through a user's notification threads, newest activity first. Each thread
+// is its latest notification matching the filter with the number of matching and unread notifications.
+func (r *repository) GetThreadsByUserID(userID uint, filters map[string]interface{}) ([]Thread, int64, error) {
+	groups := r.db.Model(&domain.Notification{}).
+		Select("group_key, MAX(id) AS latest_id, MIN(created_at) AS first_at, COUNT(*) AS count, "+
+			"SUM(CASE WHEN is_read THEN 0 ELSE 1 END) AS unread_count").
+		Where("user_id = ?", userID).
+		Group("group_key")
+	if isRead, ok := filters["is_read"]; ok {
+		groups = groups.Where("is_read = ?", isRead)
+	}
+
+	var total int64
+	if err := r.db.Table("(?) AS g", groups).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Table("notifications AS n").
+		Select("n.*, g.first_at, g.count, g.unread_count").
+		Joins("JOIN (?) AS g ON g.latest_id = n.id", groups)
+
+	if page, ok := filters["page"].(int); ok {
+		limit := 20
+		if l, ok := filters["limit"].(int); ok {
+			limit = l
+		}
+		query = query.Offset((page - 1) * limit).Limit(limit)
+	}
+
+	var threads []Thread
+	err := query.Order("n.created_at DESC, n.id DESC").Scan(&threads).Error
+	return threads, total, err
 }
 
 func (r *repository) GetByID(id uint) (*domain.Notification, error) {
@@ -72,6 +118,17 @@ func (r *repository) MarkAsRead(id uint, userID uint) error {
 		}).Error
 }
 
+// MarkThreadAsRead marks every unread notification in one of the user's threads as read
+func (r *repository) MarkThreadAsRead(userID uint, groupKey string) error {
+	now := time.Now()
+	return r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND group_key = ? AND is_read = ?", userID, groupKey, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": now,
+		}).Error
+}
+
 func (r *repository) MarkAllAsRead(userID uint) error {
 	now := time.Now()
 	return r.db.Model(&domain.Notification{}).
@@ -93,3 +150,28 @@ func (r *repository) GetUnreadCount(userID uint) (int64, error) {
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&domain.Notification{}, id).Error
 }
+
+// DeleteThread removes every notification in one of the user's threads
+func (r *repository) DeleteThread(userID uint, groupKey string) error {
+	return r.db.Where("user_id = ? AND group_key = ?", userID, groupKey).Delete(&domain.Notification{}).Error
+}
+
+// DeleteRead removes all of a user's read notifications
+func (r *repository) DeleteRead(userID uint) (int64, error) {
+	result := r.db.Where("user_id = ? AND is_read = ?", userID, true).Delete(&domain.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteOlderThan removes read notifications created before readBefore and any notification created before createdBefore
+func (r *repository) DeleteOlderThan(readBefore time.Time, createdBefore time.Time) (int64, error) {
+	result := r.db.
+		Where("(is_read = ? AND created_at < ?) OR created_at < ?", true, readBefore, createdBefore).
+		Delete(&domain.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) GetUserEmail(userID uint) (string, error) {
+	var user domain.User
+	err := r.db.Select("email").First(&user, userID).Error
+	return user.Email, err
+}
@@ -2,17 +2,75 @@ package notifications
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/deadletter"
+	"backend/pkg/enums"
+	"backend/pkg/mailer"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"time"
 )
 
+// Retention periods applied by the cleanup job
+const (
+	ReadRetention   = 90 * 24 * time.Hour  // read notifications
+	UnreadRetention = 365 * 24 * time.Hour // every notification, read or not
+)
+
+// DeadLetterEmail is the dead letter kind of notification emails that could not be sent
+const DeadLetterEmail = "email"
+
+// emailDeadLetter is the stored payload of an unsent email; the address is looked up again on retry
+type emailDeadLetter struct {
+	UserID  uint   `json:"user_id"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Thread is a run of notifications sharing a group key, e.g. five new versions of the same
+// proposal, shown as its latest notification with how many there are
+type Thread struct {
+	domain.Notification
+	FirstAt     time.Time `json:"first_at"`
+	Count       int64     `json:"count"`
+	UnreadCount int64     `json:"unread_count"`
+}
+
+// GroupKey threads notifications about the same thing with the same title together
+func GroupKey(refType string, refID uint, title string) string {
+	return fmt.Sprintf("%s:%d:%s", refType, refID, title)
+}
+
 // Service handles notification business logic
 type Service struct {
-	repo Repository
+	repo        Repository
+	mailer      *mailer.Mailer // nil when email is not configured
+	deadLetters *deadletter.Queue
+	blind       BlindReview // nil until UseBlindReview
+}
+
+// BlindReview decides whether a team is hidden from an advisor reviewing its proposal blind;
+// implemented by the proposal service
+type BlindReview interface {
+	TeamHiddenFrom(teamID uint, userID uint, role enums.Role) bool
 }
 
 // NewService creates a new notification service
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, mail *mailer.Mailer, deadLetters *deadletter.Queue) *Service {
+	return &Service{repo: repo, mailer: mail, deadLetters: deadLetters}
+}
+
+// UseBlindReview leaves the names of teams hidden by blind review out of advisors' notifications.
+// The proposal service is built after this one, so it is set afterwards.
+func (s *Service) UseBlindReview(blind BlindReview) {
+	s.blind = blind
+}
+
+// teamHiddenFrom reports whether blind review hides the team from the advisor; fails closed
+// until UseBlindReview is called
+func (s *Service) teamHiddenFrom(teamID uint, advisorID uint) bool {
+	return s.blind == nil || s.blind.TeamHiddenFrom(teamID, advisorID, enums.RoleAdvisor)
 }
 
 // CreateNotification creates a new notification for a user
@@ -26,6 +84,7 @@ func (s *Service) CreateNotification(userID uint, refType string, refID uint, ti
 		ActionURL:     actionURL,
 		IsRead:        false,
 		Priority:      "normal",
+		GroupKey:      GroupKey(refType, refID, title),
 	}
 
 	return s.repo.Create(notification)
@@ -42,13 +101,15 @@ func (s *Service) CreateNotificationWithPriority(userID uint, refType string, re
 		ActionURL:     actionURL,
 		IsRead:        false,
 		Priority:      priority,
+		GroupKey:      GroupKey(refType, refID, title),
 	}
 
 	return s.repo.Create(notification)
 }
 
-// GetUserNotifications returns notifications for a user with optional filtering
-func (s *Service) GetUserNotifications(userID uint, isRead *bool, page, limit int) ([]domain.Notification, int64, error) {
+// GetUserNotifications returns a page of a user's notifications, the total matching the filter and the unread count.
+// Grouped, repeated notifications come back as one thread each and the total counts threads.
+func (s *Service) GetUserNotifications(userID uint, isRead *bool, grouped bool, page, limit int) ([]Thread, int64, int64, error) {
 	filters := make(map[string]interface{})
 
 	if isRead != nil {
@@ -63,20 +124,38 @@ func (s *Service) GetUserNotifications(userID uint, isRead *bool, page, limit in
 		filters["limit"] = limit
 	}
 
-	notifications, err := s.repo.GetByUserID(userID, filters)
-	if err != nil {
-		return nil, 0, err
+	var threads []Thread
+	var total int64
+	if grouped {
+		var err error
+		threads, total, err = s.repo.GetThreadsByUserID(userID, filters)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	} else {
+		notifications, count, err := s.repo.GetByUserID(userID, filters)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		threads = make([]Thread, len(notifications))
+		for i, n := range notifications {
+			threads[i] = Thread{Notification: n, FirstAt: n.CreatedAt, Count: 1}
+			if !n.IsRead {
+				threads[i].UnreadCount = 1
+			}
+		}
+		total = count
 	}
 
 	unreadCount, err := s.repo.GetUnreadCount(userID)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 
-	return notifications, unreadCount, nil
+	return threads, total, unreadCount, nil
 }
 
-// MarkAsRead marks a single notification as read
+// MarkAsRead marks a notification, and the rest of its thread, as read
 func (s *Service) MarkAsRead(notificationID, userID uint) error {
 	// Verify notification belongs to user
 	notification, err := s.repo.GetByID(notificationID)
@@ -88,6 +167,10 @@ func (s *Service) MarkAsRead(notificationID, userID uint) error {
 		return errors.New("notification does not belong to user")
 	}
 
+	// Reading a thread reads everything in it
+	if notification.GroupKey != "" {
+		return s.repo.MarkThreadAsRead(userID, notification.GroupKey)
+	}
 	return s.repo.MarkAsRead(notificationID, userID)
 }
 
@@ -96,6 +179,41 @@ func (s *Service) MarkAllAsRead(userID uint) error {
 	return s.repo.MarkAllAsRead(userID)
 }
 
+// DeleteNotification removes one of the user's notifications along with the rest of its thread
+func (s *Service) DeleteNotification(notificationID, userID uint) error {
+	notification, err := s.repo.GetByID(notificationID)
+	if err != nil {
+		return errors.New("notification not found")
+	}
+
+	if notification.UserID != userID {
+		return errors.New("notification does not belong to user")
+	}
+
+	if notification.GroupKey != "" {
+		return s.repo.DeleteThread(userID, notification.GroupKey)
+	}
+	return s.repo.Delete(notificationID)
+}
+
+// DeleteReadNotifications removes all of the user's read notifications
+func (s *Service) DeleteReadNotifications(userID uint) (int64, error) {
+	return s.repo.DeleteRead(userID)
+}
+
+// CleanupOldNotifications applies the retention periods; run periodically by the scheduler
+func (s *Service) CleanupOldNotifications() {
+	now := time.Now()
+	count, err := s.repo.DeleteOlderThan(now.Add(-ReadRetention), now.Add(-UnreadRetention))
+	if err != nil {
+		log.Printf("failed to clean up old notifications: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("deleted %d old notification(s)", count)
+	}
+}
+
 // GetUnreadCount returns the count of unread notifications for a user
 func (s *Service) GetUnreadCount(userID uint) (int64, error) {
 	return s.repo.GetUnreadCount(userID)
@@ -109,7 +227,7 @@ func (s *Service) NotifyTeamInvitation(userID uint, teamID uint, teamName string
 		teamID,
 		"Team Invitation",
 		inviterName+" invited you to join team '"+teamName+"'",
-		"/teams/"+string(rune(teamID)),
+		fmt.Sprintf("/teams/%d", teamID),
 	)
 }
 
@@ -137,7 +255,7 @@ func (s *Service) NotifyProposalFeedback(userID uint, proposalID uint, decision
 		proposalID,
 		title,
 		message,
-		"/proposals/"+string(rune(proposalID)),
+		fmt.Sprintf("/proposals/%d", proposalID),
 		"high",
 	)
 }
@@ -150,6 +268,40 @@ func (s *Service) NotifyProjectPublished(userID uint, projectID uint, projectTit
 		projectID,
 		"Project Published",
 		"Your project '"+projectTitle+"' has been published to the public archive!",
-		"/projects/"+string(rune(projectID)),
+		fmt.Sprintf("/projects/%d", projectID),
 	)
 }
+
+// EmailUser sends a notification by email as well; a no-op when email is not configured. Emails
+// the mail server does not take are kept in the dead letter queue.
+func (s *Service) EmailUser(userID uint, subject, body string) error {
+	if !s.mailer.Enabled() {
+		return nil
+	}
+	email, err := s.repo.GetUserEmail(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.mailer.Send(email, subject, body); err != nil {
+		s.deadLetters.Record(DeadLetterEmail, fmt.Sprintf("user:%d:%d", userID, time.Now().UnixNano()),
+			emailDeadLetter{UserID: userID, Subject: subject, Body: body}, 1, err)
+		return err
+	}
+	return nil
+}
+
+// RetryDeadLetter sends an email that failed before; it is the dead letter retrier for DeadLetterEmail
+func (s *Service) RetryDeadLetter(payload []byte) error {
+	var letter emailDeadLetter
+	if err := json.Unmarshal(payload, &letter); err != nil {
+		return err
+	}
+	if !s.mailer.Enabled() {
+		return errors.New("email is not configured")
+	}
+	email, err := s.repo.GetUserEmail(letter.UserID)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(email, letter.Subject, letter.Body)
+}
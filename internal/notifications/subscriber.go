@@ -0,0 +1,322 @@
+package notifications
+
+import (
+	"backend/pkg/events"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// RegisterSubscribers wires in-app notifications to domain events
+func (s *Service) RegisterSubscribers(bus *events.Bus) {
+	bus.Subscribe(s.handleEvent,
+		events.TeamInvited,
+		events.TeamInvitationAccepted,
+		events.TeamInvitationRejected,
+		events.TaskAssigned,
+		events.AdvisorRequested,
+		events.AdvisorRequestAccepted,
+		events.AdvisorRequestDeclined,
+		events.AdvisorListExhausted,
+		events.LeaderAbsent,
+		events.LeadershipVoteStarted,
+		events.LeadershipVoteExpired,
+		events.LeaderReplaced,
+		events.ProposalSubmitted,
+		events.ProposalResubmitted,
+		events.ProposalVersionUploaded,
+		events.ProposalAdvisorAssigned,
+		events.AssignmentEscalated,
+		events.SecondReviewerAssigned,
+		events.SecondReviewRequested,
+		events.ProposalApproved,
+		events.ProposalRevisionRequest,
+		events.ProposalRejected,
+		events.RevisionDeadlineNear,
+		events.RevisionDeadlineMissed,
+		events.ProposalAbandonWarning,
+		events.ProposalAbandoned,
+		events.ProposalReopened,
+		events.SubmissionDeadlineNear,
+		events.SubmissionsOutstanding,
+		events.ExtensionRequested,
+		events.ExtensionApproved,
+		events.ExtensionDenied,
+		events.SecondOpinionRequested,
+		events.SecondOpinionAnswered,
+		events.ProjectPublished,
+		events.ProjectGradeLocked,
+		events.AnnouncementPosted,
+		events.AdvisorMessageSent,
+		events.DataExportReady,
+		events.AIAnalysisCompleted,
+		events.AIAnalysisFailed,
+	)
+}
+
+func (s *Service) handleEvent(e events.Event) {
+	for _, userID := range e.UserIDs {
+		if err := s.notifyForEvent(userID, e); err != nil {
+			log.Printf("failed to notify user %d for %s: %v", userID, e.Name, err)
+		}
+	}
+}
+
+func (s *Service) notifyForEvent(userID uint, e events.Event) error {
+	switch e.Name {
+	case events.TeamInvited:
+		return s.NotifyTeamInvitation(userID, e.EntityID, dataString(e, "team_name"), dataString(e, "inviter_name"))
+	case events.TeamInvitationAccepted:
+		return s.CreateNotification(userID, "team", e.EntityID, "Invitation Accepted",
+			dataString(e, "member_name")+" joined team '"+dataString(e, "team_name")+"'",
+			fmt.Sprintf("/teams/%d", e.EntityID))
+	case events.TeamInvitationRejected:
+		return s.CreateNotification(userID, "team", e.EntityID, "Invitation Declined",
+			dataString(e, "member_name")+" declined the invitation to team '"+dataString(e, "team_name")+"'",
+			fmt.Sprintf("/teams/%d", e.EntityID))
+	case events.TaskAssigned:
+		return s.CreateNotification(userID, "team", e.EntityID, "Task Assigned",
+			"You were assigned '"+dataString(e, "title")+"' in team '"+dataString(e, "team_name")+"'.",
+			fmt.Sprintf("/teams/%d/tasks", e.EntityID))
+	case events.AdvisorRequested:
+		// Blind review hides the team until its proposal is decided
+		message := "Team '" + dataString(e, "team_name") + "' asked you to be their advisor."
+		if s.teamHiddenFrom(e.EntityID, userID) {
+			message = "A team asked you to be their advisor."
+		}
+		return s.CreateNotification(userID, "team", e.EntityID, "Advisor Request", message, "/advisor/team-requests")
+	case events.AdvisorRequestAccepted:
+		return s.CreateNotification(userID, "team", e.EntityID, "Advisor Accepted",
+			dataString(e, "advisor_name")+" accepted to advise team '"+dataString(e, "team_name")+"'.",
+			fmt.Sprintf("/teams/%d", e.EntityID))
+	case events.AdvisorRequestDeclined:
+		message := dataString(e, "advisor_name") + " declined to advise team '" + dataString(e, "team_name") + "'."
+		if next := dataString(e, "next_advisor_name"); next != "" {
+			message += " Your next preference, " + next + ", has been asked."
+		}
+		return s.CreateNotification(userID, "team", e.EntityID, "Advisor Declined", message,
+			fmt.Sprintf("/teams/%d/advisor-requests", e.EntityID))
+	case events.AdvisorListExhausted:
+		return s.CreateNotification(userID, "team", e.EntityID, "No Advisor Found",
+			"None of the advisors team '"+dataString(e, "team_name")+"' asked could take the team. Submit a new list of preferences.",
+			fmt.Sprintf("/teams/%d/advisor-requests", e.EntityID))
+	case events.LeaderAbsent:
+		message := fmt.Sprintf("%s, leader of team '%s', has not been active for %v days.", dataString(e, "leader_name"), dataString(e, "team_name"), e.Data["inactive_days"])
+		if deactivated, _ := e.Data["deactivated"].(bool); deactivated {
+			message = "The account of " + dataString(e, "leader_name") + ", leader of team '" + dataString(e, "team_name") + "', was deactivated."
+		}
+		return s.CreateNotificationWithPriority(userID, "team", e.EntityID, "Team Leader Absent",
+			message+" Any member can start a vote to choose a new leader.",
+			fmt.Sprintf("/teams/%d/leadership", e.EntityID), "high")
+	case events.LeadershipVoteStarted:
+		message := dataString(e, "started_by_name") + " started a vote to replace " + dataString(e, "leader_name") +
+			" as leader of team '" + dataString(e, "team_name") + "'. Vote by " + formatDate(dataString(e, "expires_at")) + "."
+		if leaderID, _ := e.Data["leader_id"].(uint); leaderID == userID {
+			message = dataString(e, "started_by_name") + " started a vote to choose a new leader of team '" + dataString(e, "team_name") +
+				"' while you are away. Signing in again cancels the vote."
+		}
+		return s.CreateNotificationWithPriority(userID, "team", e.EntityID, "Leadership Vote Started", message,
+			fmt.Sprintf("/teams/%d/leadership", e.EntityID), "high")
+	case events.LeadershipVoteExpired:
+		if adminIDs, ok := e.Data["admin_ids"].([]uint); ok && containsID(adminIDs, userID) {
+			return s.CreateNotificationWithPriority(userID, "team", e.EntityID, "Team Needs a Leader",
+				"The members of team '"+dataString(e, "team_name")+"' did not elect a replacement for "+dataString(e, "leader_name")+" in time. Appoint a new leader from the team's members.",
+				fmt.Sprintf("/admin/teams/%d", e.EntityID), "high")
+		}
+		return s.CreateNotification(userID, "team", e.EntityID, "Leadership Vote Ended",
+			"The leadership vote of team '"+dataString(e, "team_name")+"' ended without a majority. The department admin has been asked to appoint a leader.",
+			fmt.Sprintf("/teams/%d/leadership", e.EntityID))
+	case events.LeaderReplaced:
+		how := "elected by the members"
+		if dataString(e, "by") == "admin" {
+			how = "appointed by the department admin"
+		}
+		message := dataString(e, "leader_name") + " replaces " + dataString(e, "previous_leader_name") + " as leader of team '" + dataString(e, "team_name") + "', " + how + "."
+		if advisorID, _ := e.Data["advisor_id"].(uint); advisorID == userID && s.teamHiddenFrom(e.EntityID, userID) {
+			message = "A team you advise has a new leader, " + how + "."
+		}
+		return s.CreateNotification(userID, "team", e.EntityID, "New Team Leader", message,
+			fmt.Sprintf("/teams/%d", e.EntityID))
+	case events.ProposalSubmitted:
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Proposal Submitted",
+			"Proposal '"+dataString(e, "title")+"' has been submitted for review.",
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.ProposalResubmitted:
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Revised Proposal Submitted",
+			fmt.Sprintf("Version %v of '%s' was resubmitted. %s", e.Data["version"], dataString(e, "title"), dataString(e, "summary")),
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.ProposalVersionUploaded:
+		title := dataString(e, "title")
+		message := fmt.Sprintf("Version %v of '%s' was uploaded in response to your revision request.", e.Data["version"], title)
+		url := fmt.Sprintf("/proposals/%d/versions", e.EntityID)
+		if err := s.CreateNotification(userID, "proposal", e.EntityID, "New Proposal Version", message, url); err != nil {
+			return err
+		}
+		return s.EmailUser(userID, "New version of '"+title+"'", message+"\n\nView it at "+url)
+	case events.ProposalAdvisorAssigned:
+		// The advisor is asked to answer by the acceptance deadline
+		if advisorID, _ := e.Data["advisor_id"].(uint); advisorID == userID {
+			return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Proposal Assigned to You",
+				"You were assigned proposal '"+dataString(e, "title")+"'. Accept or decline it by "+formatDate(dataString(e, "accept_by"))+".",
+				fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+		}
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Advisor Assigned",
+			"An advisor has been assigned to proposal '"+dataString(e, "title")+"'.",
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.AssignmentEscalated:
+		advisor := dataString(e, "advisor_name")
+		if advisor == "" {
+			advisor = "The advisor"
+		}
+		message := advisor + " did not answer the assignment of '" + dataString(e, "title") + "' in time."
+		if dataString(e, "reason") == "declined" {
+			message = advisor + " declined '" + dataString(e, "title") + "': " + dataString(e, "comment")
+		}
+		if suggested := dataString(e, "suggested_advisor_name"); suggested != "" {
+			message += " Suggested replacement: " + suggested + "."
+		} else {
+			message += " No advisor currently has room for it."
+		}
+		return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Proposal Needs an Advisor", message,
+			fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+	case events.SecondReviewerAssigned:
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Co-Review Assigned",
+			"You were assigned as co-reviewer of '"+dataString(e, "title")+"'. Its approval also needs your decision.",
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.SecondReviewRequested:
+		// The co-reviewer decides next; the team only learns that the approval is pending
+		if reviewerID, _ := e.Data["second_reviewer_id"].(uint); reviewerID == userID {
+			return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Co-Review Needed",
+				"The advisor approved '"+dataString(e, "title")+"'. It is approved once you approve it too.",
+				fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+		}
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Awaiting Second Review",
+			"Your advisor approved '"+dataString(e, "title")+"'. It now awaits the co-reviewer's decision.",
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.ProposalApproved:
+		return s.NotifyProposalFeedback(userID, e.EntityID, "approve")
+	case events.ProposalRevisionRequest:
+		return s.NotifyProposalFeedback(userID, e.EntityID, "revise")
+	case events.ProposalRejected:
+		return s.NotifyProposalFeedback(userID, e.EntityID, "reject")
+	case events.RevisionDeadlineNear:
+		return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Revision Due Soon",
+			fmt.Sprintf("The revised version of '%s' is due in %v day(s).", dataString(e, "title"), e.Data["days_left"]),
+			fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+	case events.RevisionDeadlineMissed:
+		return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Revision Deadline Missed",
+			"The resubmission deadline for '"+dataString(e, "title")+"' has passed without a revised version.",
+			fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+	case events.ProposalAbandonWarning:
+		return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Proposal Will Be Abandoned",
+			fmt.Sprintf("'%s' will be marked abandoned in %v day(s) unless your team works on the requested revision.", dataString(e, "title"), e.Data["days_left"]),
+			fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+	case events.ProposalAbandoned:
+		return s.CreateNotificationWithPriority(userID, "proposal", e.EntityID, "Proposal Abandoned",
+			fmt.Sprintf("'%s' was marked abandoned after %v day(s) without a response to the revision request.", dataString(e, "title"), e.Data["inactive_days"]),
+			fmt.Sprintf("/proposals/%d", e.EntityID), "high")
+	case events.ProposalReopened:
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Proposal Reopened",
+			"'"+dataString(e, "title")+"' was reopened and is awaiting revision again.",
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.SubmissionDeadlineNear:
+		return s.CreateNotificationWithPriority(userID, "team", e.EntityID, "Proposal Submission Closing",
+			fmt.Sprintf("Team '%s' has not submitted a proposal yet; submissions close in %v day(s).", dataString(e, "team_name"), e.Data["days_left"]),
+			fmt.Sprintf("/teams/%d", e.EntityID), "high")
+	case events.SubmissionsOutstanding:
+		message := fmt.Sprintf("%v team(s) have not submitted a proposal; submissions close in %v day(s).", e.Data["team_count"], e.Data["days_left"])
+		if closed, _ := e.Data["closed"].(bool); closed {
+			message = fmt.Sprintf("Submissions have closed and %v team(s) did not submit a proposal.", e.Data["team_count"])
+		}
+		if teams, ok := e.Data["teams"].([]string); ok && len(teams) > 0 {
+			if len(teams) > 10 {
+				teams = append(teams[:10:10], fmt.Sprintf("and %d more", len(teams)-10))
+			}
+			message += " Pending: " + strings.Join(teams, ", ") + "."
+		}
+		return s.CreateNotification(userID, "department", e.EntityID, "Teams Without a Proposal", message,
+			"/admin/submission-window")
+	case events.ExtensionRequested:
+		return s.CreateNotification(userID, "team", e.EntityID, "Deadline Extension Requested",
+			"Team '"+dataString(e, "team_name")+"' asked to submit its proposal until "+formatDate(dataString(e, "requested_until"))+": "+dataString(e, "justification"),
+			"/admin/deadline-extensions")
+	case events.ExtensionApproved:
+		return s.CreateNotificationWithPriority(userID, "team", e.EntityID, "Deadline Extension Approved",
+			"Team '"+dataString(e, "team_name")+"' can submit its proposal until "+formatDate(dataString(e, "extended_until"))+".",
+			fmt.Sprintf("/teams/%d", e.EntityID), "high")
+	case events.ExtensionDenied:
+		return s.CreateNotificationWithPriority(userID, "team", e.EntityID, "Deadline Extension Denied",
+			"The extension request of team '"+dataString(e, "team_name")+"' was denied: "+dataString(e, "comment"),
+			fmt.Sprintf("/teams/%d", e.EntityID), "high")
+	case events.SecondOpinionRequested:
+		return s.CreateNotification(userID, "proposal", e.EntityID, "Second Opinion Requested",
+			fmt.Sprintf("You have been asked for a second opinion on version %v of '%s'.", e.Data["version"], dataString(e, "title")),
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.SecondOpinionAnswered:
+		title, verb := "Second Opinion Received", "answered"
+		if dataString(e, "status") == "declined" {
+			title, verb = "Second Opinion Declined", "declined"
+		}
+		return s.CreateNotification(userID, "proposal", e.EntityID, title,
+			dataString(e, "reviewer_name")+" "+verb+" your second opinion request.",
+			fmt.Sprintf("/proposals/%d", e.EntityID))
+	case events.ProjectPublished:
+		return s.NotifyProjectPublished(userID, e.EntityID, dataString(e, "title"))
+	case events.ProjectGradeLocked:
+		return s.CreateNotificationWithPriority(userID, "project", e.EntityID, "Final Grade Published",
+			fmt.Sprintf("Your project's final grade is %s (%v/100).", dataString(e, "letter_grade"), e.Data["final_score"]),
+			fmt.Sprintf("/projects/%d/grading", e.EntityID), "high")
+	case events.AnnouncementPosted:
+		return s.CreateNotification(userID, "announcement", e.EntityID, "New Announcement",
+			"Your department posted '"+dataString(e, "title")+"'.",
+			"/announcements")
+	case events.AdvisorMessageSent:
+		subject, body := dataString(e, "subject"), dataString(e, "body")
+		if err := s.CreateNotification(userID, "advisor_message", e.EntityID, subject, body, "/notifications"); err != nil {
+			return err
+		}
+		if email, _ := e.Data["email"].(bool); email {
+			return s.EmailUser(userID, subject, body)
+		}
+		return nil
+	case events.DataExportReady:
+		return s.CreateNotification(userID, "data_export", e.EntityID, "Your Data Export Is Ready",
+			"The copy of your data you requested can be downloaded until "+dataString(e, "expires_on")+".",
+			"/users/me/export")
+	case events.AIAnalysisCompleted:
+		return s.CreateNotification(userID, "ai_job", e.EntityID, "AI Analysis Ready",
+			"The AI analysis of '"+dataString(e, "title")+"' is complete.",
+			dataString(e, "url"))
+	case events.AIAnalysisFailed:
+		return s.CreateNotification(userID, "ai_job", e.EntityID, "AI Analysis Failed",
+			"The AI analysis of '"+dataString(e, "title")+"' could not be completed.",
+			dataString(e, "url"))
+	}
+	return nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func dataString(e events.Event, key string) string {
+	if v, ok := e.Data[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// formatDate renders an RFC 3339 timestamp from event data for a message
+func formatDate(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Format("2 Jan 2006 15:04 MST")
+}
@@ -0,0 +1,134 @@
+package permissions
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/enums"
+	"backend/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	authorizer *Authorizer
+	repo       Repository
+}
+
+func NewHandler(authorizer *Authorizer, repo Repository) *Handler {
+	return &Handler{authorizer: authorizer, repo: repo}
+}
+
+type OverrideRequest struct {
+	Role       string `json:"role" binding:"required"`
+	Permission string `json:"permission" binding:"required"`
+	Allowed    *bool  `json:"allowed" binding:"required"`
+}
+
+type RemoveOverrideRequest struct {
+	Role       string `json:"role" binding:"required"`
+	Permission string `json:"permission" binding:"required"`
+}
+
+// GetPermissions godoc
+// @Summary Effective permissions of the admin's department
+// @Description Returns the role → permission matrix after department overrides, plus the overrides themselves
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Router /admin/permissions [get]
+func (h *Handler) GetPermissions(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	overrides, err := h.repo.GetByDepartment(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch permissions", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"permissions": All,
+		"matrix":      h.authorizer.GetMatrix(claims.DepartmentID),
+		"overrides":   overrides,
+	})
+}
+
+// SetOverride godoc
+// @Summary Override a permission for the department
+// @Description Grants or denies a permission to a role within the admin's department. Only department-level permissions can be overridden: students can be given their own team's work, advisors reviewing, grading, statistics and announcements; account, delegation and system administration stay with admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body OverrideRequest true "Role, permission and whether it is allowed"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/permissions/overrides [put]
+func (h *Handler) SetOverride(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req OverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	err := h.authorizer.SetOverride(enums.Role(req.Role), Permission(req.Permission), claims.DepartmentID, *req.Allowed)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to set override", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Permission override saved", nil)
+}
+
+// RemoveOverride godoc
+// @Summary Remove a department permission override
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RemoveOverrideRequest true "Role and permission"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/permissions/overrides [delete]
+func (h *Handler) RemoveOverride(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req RemoveOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	err := h.authorizer.RemoveOverride(enums.Role(req.Role), Permission(req.Permission), claims.DepartmentID)
+	if err != nil {
+		if err.Error() == "override not found" {
+			response.Error(c, http.StatusNotFound, "Override not found", nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Failed to remove override", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Permission override removed", nil)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
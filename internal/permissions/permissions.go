@@ -0,0 +1,109 @@
+package permissions
+
+import "backend/pkg/enums"
+
+// Permission is a single capability checked by the authorizer
+type Permission string
+
+const (
+	TeamManage Permission = "team.manage" // create, invite, edit and delete own teams
+	TeamJoin   Permission = "team.join"   // respond to team invitations
+
+	ProposalWrite   Permission = "proposal.write"   // create, edit, submit and delete drafts
+	ProposalAssign  Permission = "proposal.assign"  // assign advisors to proposals
+	ProposalArchive Permission = "proposal.archive" // archive past academic years
+
+	FeedbackWrite Permission = "feedback.write" // review assigned proposals
+
+	DocumentationSubmit Permission = "documentation.submit"
+	DocumentationReview Permission = "documentation.review"
+
+	GradeSubmit Permission = "grade.submit" // grade projects as advisor or examiner
+	GradeLock   Permission = "grade.lock"   // assign examiners and lock final grades
+
+	AICheck Permission = "ai.check"
+
+	UserManage      Permission = "user.manage"
+	UserImpersonate Permission = "user.impersonate"
+
+	DelegationManage Permission = "delegation.manage"
+	DelegationHold   Permission = "delegation.hold"
+
+	StatsView        Permission = "stats.view"
+	SystemConfig     Permission = "system.config"
+	PermissionManage Permission = "permission.manage"
+
+	UniversityOnboard Permission = "university.onboard" // create universities with their departments and first head
+
+	AnnouncementPost Permission = "announcement.post"
+
+	ConflictOverride Permission = "conflict.override" // clear an advisor's declared conflict of interest
+
+	ReviewModerate Permission = "review.moderate" // banned words and the moderation queue for public project reviews
+)
+
+// All lists every permission, in display order
+var All = []Permission{
+	TeamManage, TeamJoin,
+	ProposalWrite, ProposalAssign, ProposalArchive,
+	FeedbackWrite,
+	DocumentationSubmit, DocumentationReview,
+	GradeSubmit, GradeLock,
+	AICheck,
+	UserManage, UserImpersonate,
+	DelegationManage, DelegationHold,
+	StatsView, SystemConfig, PermissionManage,
+	UniversityOnboard,
+	AnnouncementPost,
+	ConflictOverride,
+	ReviewModerate,
+}
+
+// DefaultGrants are the global role grants seeded at startup
+var DefaultGrants = map[enums.Role][]Permission{
+	enums.RoleStudent: {TeamManage, TeamJoin, ProposalWrite, DocumentationSubmit, AICheck},
+	enums.RoleAdvisor: {FeedbackWrite, DocumentationReview, GradeSubmit, AICheck, DelegationHold},
+	// External examiners only grade; which projects they can open is limited by route
+	enums.RoleExaminer: {GradeSubmit},
+	enums.RoleAdmin: {
+		ProposalAssign, ProposalArchive, GradeLock, AICheck,
+		UserManage, UserImpersonate,
+		DelegationManage, StatsView, SystemConfig, PermissionManage, UniversityOnboard,
+		AnnouncementPost, ConflictOverride, ReviewModerate,
+	},
+}
+
+// lockedPermissions cannot be overridden per department, so admins cannot lock themselves out and
+// no department can onboard universities on its own
+var lockedPermissions = map[Permission]bool{
+	PermissionManage:  true,
+	UniversityOnboard: true,
+}
+
+// overridable lists the permissions a department may grant or deny each role. Account,
+// delegation and system administration stay with admins; advisors can be given department
+// duties such as announcements, students only their own team's work.
+var overridable = map[enums.Role]map[Permission]bool{
+	enums.RoleStudent: {
+		TeamManage: true, TeamJoin: true, ProposalWrite: true, DocumentationSubmit: true, AICheck: true,
+	},
+	enums.RoleAdvisor: {
+		FeedbackWrite: true, DocumentationReview: true, GradeSubmit: true, AICheck: true, DelegationHold: true,
+		StatsView: true, AnnouncementPost: true,
+	},
+	enums.RoleAdmin: {
+		ProposalAssign: true, ProposalArchive: true, GradeLock: true, AICheck: true,
+		UserManage: true, UserImpersonate: true,
+		DelegationManage: true, StatsView: true, SystemConfig: true,
+		AnnouncementPost: true, ConflictOverride: true, ReviewModerate: true,
+	},
+}
+
+func IsValid(p string) bool {
+	for _, perm := range All {
+		if Permission(p) == perm {
+			return true
+		}
+	}
+	return false
+}
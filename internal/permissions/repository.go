@@ -0,0 +1,68 @@
+package permissions
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetAll() ([]domain.RolePermission, error)
+	GetByDepartment(departmentID uint) ([]domain.RolePermission, error)
+	EnsureGrant(role enums.Role, permission Permission) error
+	UpsertOverride(role enums.Role, permission Permission, departmentID uint, allowed bool) (*domain.RolePermission, error)
+	DeleteOverride(role enums.Role, permission Permission, departmentID uint) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetAll() ([]domain.RolePermission, error) {
+	var grants []domain.RolePermission
+	err := r.db.Find(&grants).Error
+	return grants, err
+}
+
+func (r *repository) GetByDepartment(departmentID uint) ([]domain.RolePermission, error) {
+	var grants []domain.RolePermission
+	err := r.db.Where("department_id = ?", departmentID).Order("role, permission").Find(&grants).Error
+	return grants, err
+}
+
+// EnsureGrant creates a global grant if it does not exist yet; existing rows are left untouched
+func (r *repository) EnsureGrant(role enums.Role, permission Permission) error {
+	grant := domain.RolePermission{Role: role, Permission: string(permission), Allowed: true}
+	return r.db.
+		Where("role = ? AND permission = ? AND department_id IS NULL", role, permission).
+		FirstOrCreate(&grant).Error
+}
+
+func (r *repository) UpsertOverride(role enums.Role, permission Permission, departmentID uint, allowed bool) (*domain.RolePermission, error) {
+	var grant domain.RolePermission
+	err := r.db.
+		Where("role = ? AND permission = ? AND department_id = ?", role, permission, departmentID).
+		Attrs(domain.RolePermission{Role: role, Permission: string(permission), DepartmentID: &departmentID}).
+		FirstOrInit(&grant).Error
+	if err != nil {
+		return nil, err
+	}
+
+	grant.Allowed = allowed
+	if err := r.db.Save(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *repository) DeleteOverride(role enums.Role, permission Permission, departmentID uint) (int64, error) {
+	result := r.db.
+		Where("role = ? AND permission = ? AND department_id = ?", role, permission, departmentID).
+		Delete(&domain.RolePermission{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,161 @@
+package permissions
+
+import (
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long grants are served from memory before being reloaded, so changes made
+// by another instance are picked up
+const cacheTTL = time.Minute
+
+type grantKey struct {
+	role         enums.Role
+	permission   Permission
+	departmentID uint // 0 for global grants
+}
+
+// Authorizer resolves whether a role may use a permission in a department.
+// A department override wins over the global grant; with neither the permission is denied.
+type Authorizer struct {
+	repo Repository
+
+	mu       sync.RWMutex
+	grants   map[grantKey]bool
+	loadedAt time.Time
+}
+
+func NewAuthorizer(repo Repository) *Authorizer {
+	return &Authorizer{repo: repo}
+}
+
+// EnsureDefaults seeds any missing global grants from DefaultGrants
+func (a *Authorizer) EnsureDefaults() error {
+	for role, perms := range DefaultGrants {
+		for _, perm := range perms {
+			if err := a.repo.EnsureGrant(role, perm); err != nil {
+				return err
+			}
+		}
+	}
+	return a.reload()
+}
+
+// Can reports whether the role may use the permission within the department
+func (a *Authorizer) Can(role enums.Role, departmentID uint, permission Permission) bool {
+	a.refreshIfStale()
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if departmentID != 0 {
+		if allowed, ok := a.grants[grantKey{role, permission, departmentID}]; ok {
+			return allowed
+		}
+	}
+	return a.grants[grantKey{role, permission, 0}]
+}
+
+// RoleMatrix is the effective permissions of each role in a department
+type RoleMatrix map[enums.Role]map[Permission]bool
+
+// GetMatrix returns the effective grants for every role in the department
+func (a *Authorizer) GetMatrix(departmentID uint) RoleMatrix {
+	matrix := make(RoleMatrix)
+	for _, role := range []enums.Role{enums.RoleStudent, enums.RoleAdvisor, enums.RoleAdmin} {
+		matrix[role] = make(map[Permission]bool)
+		for _, perm := range All {
+			matrix[role][perm] = a.Can(role, departmentID, perm)
+		}
+	}
+	return matrix
+}
+
+// SetOverride grants or denies a permission to a role within one department
+func (a *Authorizer) SetOverride(role enums.Role, permission Permission, departmentID uint, allowed bool) error {
+	if err := validateOverride(role, permission, departmentID); err != nil {
+		return err
+	}
+	if !overridable[role][permission] {
+		return fmt.Errorf("permission %s cannot be overridden for the %s role", permission, role)
+	}
+	if _, err := a.repo.UpsertOverride(role, permission, departmentID, allowed); err != nil {
+		return err
+	}
+	return a.reload()
+}
+
+// RemoveOverride restores the global grant for a role and permission in a department. Overrides
+// outside the allowed set can still be removed.
+func (a *Authorizer) RemoveOverride(role enums.Role, permission Permission, departmentID uint) error {
+	if err := validateOverride(role, permission, departmentID); err != nil {
+		return err
+	}
+	deleted, err := a.repo.DeleteOverride(role, permission, departmentID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("override not found")
+	}
+	return a.reload()
+}
+
+func validateOverride(role enums.Role, permission Permission, departmentID uint) error {
+	if departmentID == 0 {
+		return errors.New("overrides require a department")
+	}
+	if role != enums.RoleStudent && role != enums.RoleAdvisor && role != enums.RoleAdmin {
+		return errors.New("invalid role")
+	}
+	if !IsValid(string(permission)) {
+		return errors.New("invalid permission")
+	}
+	if lockedPermissions[permission] {
+		return errors.New("permission cannot be overridden")
+	}
+	return nil
+}
+
+func (a *Authorizer) refreshIfStale() {
+	a.mu.RLock()
+	stale := time.Since(a.loadedAt) > cacheTTL
+	a.mu.RUnlock()
+
+	if stale {
+		if err := a.reload(); err != nil {
+			// Keep serving the previous grants rather than denying everything
+			log.Printf("failed to reload permissions: %v", err)
+		}
+	}
+}
+
+func (a *Authorizer) reload() error {
+	rows, err := a.repo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	grants := make(map[grantKey]bool, len(rows))
+	for _, row := range rows {
+		key := grantKey{role: row.Role, permission: Permission(row.Permission)}
+		if row.DepartmentID != nil {
+			// overrides saved before the allow-list existed are not applied
+			if !overridable[row.Role][key.permission] {
+				continue
+			}
+			key.departmentID = *row.DepartmentID
+		}
+		grants[key] = row.Allowed
+	}
+
+	a.mu.Lock()
+	a.grants = grants
+	a.loadedAt = time.Now()
+	a.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,62 @@
+package projects
+
+import (
+	"backend/internal/domain"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// PublicCursor marks a position in the public listing, which in cursor mode is ordered by
+// creation time and then id, newest first. Clients treat it as an opaque string.
+type PublicCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// Encode returns the cursor's opaque form
+func (c PublicCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodePublicCursor parses a cursor returned as next_cursor
+func DecodePublicCursor(s string) (*PublicCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &PublicCursor{CreatedAt: t, ID: uint(n)}, nil
+}
+
+// GetPublicProjectsPage returns up to limit public projects after the cursor (from the newest
+// when it is nil) and the cursor of the next page, empty on the last page. Cursor pages stay
+// stable while projects are published, unlike offset pages, but only follow date order.
+func (s *Service) GetPublicProjectsPage(filters map[string]interface{}, after *PublicCursor, limit int) ([]domain.Project, string, error) {
+	projects, err := s.repo.GetPublicProjectsAfter(filters, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(projects) <= limit {
+		return projects, "", nil
+	}
+	projects = projects[:limit]
+	last := projects[limit-1]
+	return projects, PublicCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode(), nil
+}
@@ -0,0 +1,216 @@
+package projects
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
+)
+
+// ProjectResponse is the API view of a project. Projects can be public, so people are
+// shown by name only.
+type ProjectResponse struct {
+	ID              uint       `json:"id"`
+	ProposalID      uint       `json:"proposal_id"`
+	TeamID          uint       `json:"team_id"`
+	DepartmentID    uint       `json:"department_id"`
+	Title           string     `json:"title"`
+	Summary         string     `json:"summary"`
+	ApprovedBy      uint       `json:"approved_by"`
+	Visibility      string     `json:"visibility"`
+	Slug            *string    `json:"slug,omitempty"`
+	ShareCount      int        `json:"share_count"`
+	ViewCount       int        `json:"view_count"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	PublishAt       *time.Time `json:"publish_at,omitempty"` // scheduled publication, once the grade is locked
+	IsArchived      bool       `json:"is_archived"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Description     string     `json:"description,omitempty"`      // README-style Markdown as written
+	DescriptionHTML string     `json:"description_html,omitempty"` // sanitized HTML, safe to embed as-is
+
+	Proposal     *ProjectProposal     `json:"proposal,omitempty"`
+	Team         *ProjectTeam         `json:"team,omitempty"`
+	Department   *ProjectDepartment   `json:"department,omitempty"`
+	Approver     *ProjectPerson       `json:"approver,omitempty"`
+	TaskProgress *domain.TaskProgress `json:"task_progress,omitempty"` // the team's task checklist; team, advisor and admin views only
+	Links        []ProjectLink        `json:"links,omitempty"`         // code and deployed links; single project only
+	BrokenLinks  int                  `json:"broken_links,omitempty"`
+
+	// PreviousProjectID is the earlier cohort's project this one continues; Lineage lists every
+	// earlier and later cohort, earliest first (single project only; the public view lists only
+	// public projects)
+	PreviousProjectID *uint                        `json:"previous_project_id,omitempty"`
+	Lineage           []domain.ProjectLineageEntry `json:"lineage,omitempty"`
+}
+
+// ProjectLink is a code or deployed link and whether it was reachable at its last check
+type ProjectLink struct {
+	Type       string           `json:"type"` // code_link or deployed_link
+	URL        string           `json:"url"`
+	Status     enums.LinkStatus `json:"status,omitempty"` // ok or broken; empty until checked
+	HTTPStatus int              `json:"http_status,omitempty"`
+	Title      string           `json:"title,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	CheckedAt  *time.Time       `json:"checked_at,omitempty"`
+}
+
+// ProjectPerson names someone involved in a project
+type ProjectPerson struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type ProjectDepartment struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// ProjectTeam is the team that built the project
+type ProjectTeam struct {
+	ID           uint            `json:"id"`
+	Name         string          `json:"name"`
+	AcademicYear string          `json:"academic_year"`
+	Members      []ProjectMember `json:"members"`
+}
+
+type ProjectMember struct {
+	UserID         uint                 `json:"user_id"`
+	Name           string               `json:"name"`
+	Role           string               `json:"role"` // leader or member
+	FunctionalRole enums.FunctionalRole `json:"functional_role,omitempty"`
+}
+
+// ProjectProposal is the approved proposal behind the project with its loaded versions
+type ProjectProposal struct {
+	ID           uint                 `json:"id"`
+	Status       enums.ProposalStatus `json:"status"`
+	AcademicYear string               `json:"academic_year"`
+	Advisor      *ProjectPerson       `json:"advisor,omitempty"`
+	Versions     []ProjectVersion     `json:"versions"`
+}
+
+type ProjectVersion struct {
+	ID               uint      `json:"id"`
+	VersionNumber    int       `json:"version_number"`
+	Title            string    `json:"title"`
+	Abstract         string    `json:"abstract"`
+	ProblemStatement string    `json:"problem_statement"`
+	Objectives       string    `json:"objectives"`
+	Methodology      string    `json:"methodology"`
+	ExpectedOutcomes string    `json:"expected_outcomes"`
+	FileURL          *string   `json:"file_url"`
+	IsApproved       bool      `json:"is_approved"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func toProjectResponse(project *domain.Project) ProjectResponse {
+	resp := ProjectResponse{
+		ID:              project.ID,
+		ProposalID:      project.ProposalID,
+		TeamID:          project.TeamID,
+		DepartmentID:    project.DepartmentID,
+		Title:           projectTitle(project),
+		Summary:         project.Summary,
+		ApprovedBy:      project.ApprovedBy,
+		Visibility:      project.Visibility,
+		Slug:            project.Slug,
+		ShareCount:      project.ShareCount,
+		ViewCount:       project.ViewCount,
+		PublishedAt:     project.PublishedAt,
+		PublishAt:       project.PublishAt,
+		IsArchived:      project.IsArchived,
+		ArchivedAt:      project.ArchivedAt,
+		CreatedAt:       project.CreatedAt,
+		Approver:        toProjectPerson(&project.Approver),
+		TaskProgress:    project.TaskProgress,
+		Description:     project.Description,
+		DescriptionHTML: project.DescriptionHTML,
+
+		PreviousProjectID: project.PreviousProjectID,
+		Lineage:           project.Lineage,
+	}
+	for _, l := range project.Links {
+		resp.Links = append(resp.Links, ProjectLink{
+			Type:       l.DocumentType,
+			URL:        l.URL,
+			Status:     l.LinkStatus,
+			HTTPStatus: l.LinkHTTPStatus,
+			Title:      l.LinkTitle,
+			Error:      l.LinkError,
+			CheckedAt:  l.LinkCheckedAt,
+		})
+		if l.LinkStatus == enums.LinkStatusBroken {
+			resp.BrokenLinks++
+		}
+	}
+
+	if project.Proposal.ID != 0 {
+		proposal := &ProjectProposal{
+			ID:           project.Proposal.ID,
+			Status:       project.Proposal.Status,
+			AcademicYear: project.Proposal.AcademicYear,
+			Advisor:      toProjectPerson(project.Proposal.Advisor),
+			Versions:     make([]ProjectVersion, 0, len(project.Proposal.Versions)),
+		}
+		for _, v := range project.Proposal.Versions {
+			proposal.Versions = append(proposal.Versions, ProjectVersion{
+				ID:               v.ID,
+				VersionNumber:    v.VersionNumber,
+				Title:            v.Title,
+				Abstract:         v.Abstract,
+				ProblemStatement: v.ProblemStatement,
+				Objectives:       v.Objectives,
+				Methodology:      v.Methodology,
+				ExpectedOutcomes: v.ExpectedOutcomes,
+				FileURL:          v.FileURL,
+				IsApproved:       v.IsApproved,
+				CreatedAt:        v.CreatedAt,
+			})
+		}
+		resp.Proposal = proposal
+	}
+
+	if project.Team.ID != 0 {
+		team := &ProjectTeam{
+			ID:           project.Team.ID,
+			Name:         project.Team.Name,
+			AcademicYear: project.Team.AcademicYear,
+			Members:      make([]ProjectMember, 0, len(project.Team.Members)),
+		}
+		for _, m := range project.Team.Members {
+			team.Members = append(team.Members, ProjectMember{
+				UserID:         m.UserID,
+				Name:           m.User.Name,
+				Role:           m.Role,
+				FunctionalRole: m.FunctionalRole,
+			})
+		}
+		resp.Team = team
+	}
+
+	department := project.Department
+	if department.ID == 0 && project.Team.Department != nil {
+		department = *project.Team.Department
+	}
+	if department.ID != 0 {
+		resp.Department = &ProjectDepartment{ID: department.ID, Name: department.Name, Code: department.Code}
+	}
+	return resp
+}
+
+func toProjectResponses(projects []domain.Project) []ProjectResponse {
+	result := make([]ProjectResponse, 0, len(projects))
+	for i := range projects {
+		result = append(result, toProjectResponse(&projects[i]))
+	}
+	return result
+}
+
+// toProjectPerson returns nil when the user was not loaded
+func toProjectPerson(u *domain.User) *ProjectPerson {
+	if u == nil || u.ID == 0 {
+		return nil
+	}
+	return &ProjectPerson{ID: u.ID, Name: u.Name}
+}
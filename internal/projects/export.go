@@ -0,0 +1,198 @@
+package projects
+
+import (
+	"archive/zip"
+	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/pkg/enums"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExportBundle is everything that goes into a project's handover archive, loaded up front
+// so access and lookup errors are reported before the download starts
+type ExportBundle struct {
+	Project   *domain.Project
+	Feedback  []domain.Feedback
+	Documents []domain.ProjectDocumentation
+	uploader  *files.Uploader
+}
+
+// exportMetadata is metadata.json at the root of the archive
+type exportMetadata struct {
+	ExportedAt   time.Time         `json:"exported_at"`
+	ProjectID    uint              `json:"project_id"`
+	Slug         *string           `json:"slug,omitempty"`
+	Title        string            `json:"title"`
+	Summary      string            `json:"summary"`
+	Visibility   string            `json:"visibility"`
+	Department   string            `json:"department"`
+	AcademicYear string            `json:"academic_year"`
+	Status       string            `json:"proposal_status"`
+	Team         exportTeam        `json:"team"`
+	AdvisorID    *uint             `json:"advisor_id,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	PublishedAt  *time.Time        `json:"published_at,omitempty"`
+	Files        map[string]string `json:"files"` // archive path -> what it is
+}
+
+type exportTeam struct {
+	ID      uint           `json:"id"`
+	Name    string         `json:"name"`
+	Members []exportMember `json:"members"`
+}
+
+type exportMember struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// PrepareExport loads a project's handover bundle. Team members, the advisor and admins of the
+// project's department may export.
+func (s *Service) PrepareExport(id uint, userID uint, role enums.Role, departmentID uint) (*ExportBundle, error) {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("project not found")
+	}
+
+	if !isInsider(project, userID, role, departmentID) {
+		return nil, errors.New("unauthorized: you cannot export this project")
+	}
+
+	feedback, err := s.repo.GetFeedbackHistory(project.ProposalID)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := s.repo.GetApprovedDocuments(project.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportBundle{Project: project, Feedback: feedback, Documents: documents, uploader: s.uploader}, nil
+}
+
+// FileName is the suggested download name, e.g. ASTU-2025-0042.zip
+func (b *ExportBundle) FileName() string {
+	if b.Project.Slug != nil {
+		return *b.Project.Slug + ".zip"
+	}
+	return fmt.Sprintf("project-%d.zip", b.Project.ID)
+}
+
+// WriteZip streams the archive to w one entry at a time; uploaded files are copied from disk
+// rather than read into memory
+func (b *ExportBundle) WriteZip(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	project := b.Project
+	files := map[string]string{}
+
+	for _, version := range project.Proposal.Versions {
+		dir := fmt.Sprintf("proposal/v%d", version.VersionNumber)
+		if err := writeJSON(archive, dir+"/version.json", version); err != nil {
+			return err
+		}
+		files[dir+"/version.json"] = fmt.Sprintf("proposal version %d", version.VersionNumber)
+
+		if version.FileURL != nil && *version.FileURL != "" {
+			name := dir + "/" + filepath.Base(*version.FileURL)
+			if err := b.copyFile(archive, name, *version.FileURL); err != nil {
+				return err
+			}
+			files[name] = fmt.Sprintf("proposal version %d document", version.VersionNumber)
+		}
+	}
+
+	if err := writeJSON(archive, "feedback.json", b.Feedback); err != nil {
+		return err
+	}
+	files["feedback.json"] = "feedback history, oldest first"
+
+	if err := writeJSON(archive, "documents/documents.json", b.Documents); err != nil {
+		return err
+	}
+	files["documents/documents.json"] = "approved documents and links"
+	for _, doc := range b.Documents {
+		if !strings.HasPrefix(doc.URL, "uploads") {
+			continue // links are only listed in documents.json
+		}
+		name := "documents/" + doc.DocumentType + "_" + filepath.Base(doc.URL)
+		if err := b.copyFile(archive, name, doc.URL); err != nil {
+			return err
+		}
+		files[name] = doc.DocumentType
+	}
+
+	if err := writeJSON(archive, "metadata.json", b.metadata(files)); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func (b *ExportBundle) metadata(files map[string]string) exportMetadata {
+	project := b.Project
+	meta := exportMetadata{
+		ExportedAt:   time.Now(),
+		ProjectID:    project.ID,
+		Slug:         project.Slug,
+		Title:        projectTitle(project),
+		Summary:      project.Summary,
+		Visibility:   project.Visibility,
+		AcademicYear: project.Proposal.AcademicYear,
+		Status:       string(project.Proposal.Status),
+		AdvisorID:    project.Proposal.AdvisorID,
+		CreatedAt:    project.CreatedAt,
+		PublishedAt:  project.PublishedAt,
+		Files:        files,
+		Team:         exportTeam{ID: project.Team.ID, Name: project.Team.Name, Members: []exportMember{}},
+	}
+	if project.Team.Department != nil {
+		meta.Department = project.Team.Department.Name
+	}
+	for _, m := range project.Team.Members {
+		meta.Team.Members = append(meta.Team.Members, exportMember{
+			UserID: m.UserID,
+			Name:   m.User.Name,
+			Email:  m.User.Email,
+			Role:   m.Role,
+		})
+	}
+	return meta
+}
+
+func writeJSON(archive *zip.Writer, name string, v interface{}) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// copyFile adds an uploaded file to the archive; a file missing from disk is noted instead of failing the export
+func (b *ExportBundle) copyFile(archive *zip.Writer, name string, relativePath string) error {
+	src, err := os.Open(b.uploader.LocalPath(relativePath))
+	if err != nil {
+		entry, createErr := archive.Create(name + ".missing.txt")
+		if createErr != nil {
+			return createErr
+		}
+		_, err = fmt.Fprintf(entry, "%s could not be read when the archive was built\n", relativePath)
+		return err
+	}
+	defer src.Close()
+
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, src)
+	return err
+}
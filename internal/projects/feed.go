@@ -0,0 +1,116 @@
+package projects
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// FeedSize is how many of the newest published projects the archive feed carries
+	FeedSize      = 50
+	atomNamespace = "http://www.w3.org/2005/Atom"
+)
+
+// AtomFeed is the public archive as an Atom 1.0 document
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Links     []AtomLink    `xml:"link"`
+	Authors   []AtomAuthor  `xml:"author"`
+	Category  *AtomCategory `xml:"category,omitempty"`
+	Summary   string        `xml:"summary,omitempty"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+// GetPublicFeed builds the Atom feed of newly published projects, optionally for one department (0 = all).
+// baseURL is the configured public address (APP_URL); entry links point at the permanent /p/ links.
+func (s *Service) GetPublicFeed(departmentID uint, baseURL string, selfURL string) (*AtomFeed, error) {
+	projects, err := s.repo.GetRecentlyPublished(departmentID, FeedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &AtomFeed{
+		Xmlns:    atomNamespace,
+		ID:       selfURL,
+		Title:    "Capstone Project Archive",
+		Subtitle: "Newly published capstone projects",
+		Links: []AtomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: baseURL + "/api/v1/projects/public", Rel: "alternate", Type: "application/json"},
+		},
+		Entries: []AtomEntry{},
+	}
+
+	// An empty feed still needs an updated time; it is the newest entry otherwise
+	updated := time.Unix(0, 0)
+	for i := range projects {
+		project := &projects[i]
+		if departmentID != 0 && i == 0 && project.Department.Name != "" {
+			feed.Title = "Capstone Project Archive - " + project.Department.Name
+		}
+
+		// Older projects published before identifiers existed get one now so their link is permanent
+		link := baseURL + "/api/v1/projects/public/" + strconv.FormatUint(uint64(project.ID), 10)
+		if slug, err := s.ensureSlug(project); err == nil {
+			link = baseURL + PermalinkPath + slug
+		}
+
+		published := project.CreatedAt
+		if project.PublishedAt != nil {
+			published = *project.PublishedAt
+		}
+		if published.After(updated) {
+			updated = published
+		}
+
+		entry := AtomEntry{
+			ID:        link,
+			Title:     projectTitle(project),
+			Updated:   published.UTC().Format(time.RFC3339),
+			Published: published.UTC().Format(time.RFC3339),
+			Links:     []AtomLink{{Href: link, Rel: "alternate"}},
+			Authors:   []AtomAuthor{{Name: project.Team.Name}},
+			Summary:   project.Summary,
+		}
+		if entry.Title == "" {
+			entry.Title = fmt.Sprintf("Project #%d", project.ID)
+		}
+		if project.Department.Name != "" {
+			entry.Category = &AtomCategory{Term: strconv.FormatUint(uint64(project.DepartmentID), 10), Label: project.Department.Name}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	return feed, nil
+}
@@ -3,18 +3,51 @@ package projects
 import (
 	"backend/internal/auth"
 	"backend/pkg/response"
+	"encoding/xml"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	service *Service
+	baseURL string // configured public address links in the feed and sitemap start with
 }
 
-func NewHandler(s *Service) *Handler {
-	return &Handler{service: s}
+func NewHandler(s *Service, baseURL string) *Handler {
+	return &Handler{service: s, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// GetDepartmentArchives godoc
+// @Summary Department summaries of the public archive
+// @Description One entry per department with public projects, for department landing pages: project counts overall and per academic year, total views, the most frequent keywords in titles and summaries, and the latest published projects. Archived cohorts are included. Cached for a few minutes.
+// @Tags Projects
+// @Produce json
+// @Param university_id query int false "Only departments of this university"
+// @Success 200 {object} response.Response{data=[]DepartmentArchive}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /projects/public/departments [get]
+func (h *Handler) GetDepartmentArchives(c *gin.Context) {
+	var universityID uint64
+	if raw := c.Query("university_id"); raw != "" {
+		var err error
+		if universityID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid university ID", err.Error())
+			return
+		}
+	}
+
+	summaries, err := h.service.GetDepartmentArchives(uint(universityID))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to summarise the archive", err.Error())
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	response.Success(c, summaries)
 }
 
 // GetPublicProjects godoc
@@ -28,7 +61,10 @@ func NewHandler(s *Service) *Handler {
 // @Param sort query string false "Sort by: rating, date, views (default: rating)"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 20)"
-// @Success 200 {object} response.Response{data=[]domain.Project}
+// @Param cursor query string false "Cursor pagination: send it empty for the first page, then the previous page's next_cursor. Orders by date and ignores page and sort."
+// @Param archived query bool false "List archived projects instead of active ones"
+// @Success 200 {object} response.Response{data=[]ProjectResponse}
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /projects/public [get]
 func (h *Handler) GetPublicProjects(c *gin.Context) {
@@ -47,6 +83,9 @@ func (h *Handler) GetPublicProjects(c *gin.Context) {
 	if sort := c.Query("sort"); sort != "" {
 		filters["sort"] = sort
 	}
+	if c.Query("archived") == "true" {
+		filters["archived"] = true
+	}
 
 	// Pagination
 	page := 1
@@ -61,6 +100,34 @@ func (h *Handler) GetPublicProjects(c *gin.Context) {
 			limit = parsed
 		}
 	}
+
+	// Cursor mode, chosen by the presence of the parameter; offset mode stays the default
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		var after *PublicCursor
+		if cursor != "" {
+			decoded, err := DecodePublicCursor(cursor)
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, err.Error(), nil)
+				return
+			}
+			after = decoded
+		}
+		projects, next, err := h.service.GetPublicProjectsPage(filters, after, limit)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch projects", err.Error())
+			return
+		}
+		response.Success(c, gin.H{
+			"projects": toProjectResponses(projects),
+			"pagination": gin.H{
+				"limit":       limit,
+				"next_cursor": next,
+				"has_more":    next != "",
+			},
+		})
+		return
+	}
+
 	filters["page"] = page
 	filters["limit"] = limit
 
@@ -71,7 +138,7 @@ func (h *Handler) GetPublicProjects(c *gin.Context) {
 	}
 
 	response.Success(c, gin.H{
-		"projects": projects,
+		"projects": toProjectResponses(projects),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -87,7 +154,7 @@ func (h *Handler) GetPublicProjects(c *gin.Context) {
 // @Tags Projects
 // @Produce json
 // @Param id path int true "Project ID"
-// @Success 200 {object} response.Response{data=domain.Project}
+// @Success 200 {object} response.Response{data=ProjectResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
@@ -113,18 +180,57 @@ func (h *Handler) GetPublicProject(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, project)
+	response.Success(c, toProjectResponse(project))
+}
+
+// GetRelatedProjects godoc
+// @Summary Get projects related to a public project
+// @Description Similar public projects from the AI similarity index, falling back to keyword overlap. Cached per project.
+// @Tags Projects
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} response.Response{data=RelatedProjects}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/public/{id}/related [get]
+func (h *Handler) GetRelatedProjects(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	related, err := h.service.GetRelatedProjects(c.Request.Context(), uint(id))
+	if err != nil {
+		if err.Error() == "project not found" {
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+			return
+		}
+		if err.Error() == "project is not public" {
+			response.Error(c, http.StatusForbidden, "This project is not publicly accessible", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch related projects", err.Error())
+		return
+	}
+
+	response.Success(c, related)
 }
 
 // IncrementShareCount godoc
-// @Summary Increment project share count
-// @Description Track when a project is shared
+// @Summary Share a public project
+// @Description Issues a traceable share link (/p/{slug}?s={code}) for a public project and increments its share count. Visits through the link are counted per link. Repeated shares from the same address within an hour return the earlier link and count once; crawlers are not counted. When share tokens are enforced, the token from GET /projects/public/{id}/share-token is required.
 // @Tags Projects
+// @Accept json
 // @Produce json
 // @Param id path int true "Project ID"
-// @Success 200 {object} response.Response
+// @Param share body ShareProjectRequest false "Where the link is being shared, and the share token"
+// @Success 200 {object} response.Response{data=ShareLink}
 // @Failure 400 {object} response.ErrorResponse
-// @Router /projects/{id}/share [post]
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/public/{id}/share [post]
 func (h *Handler) IncrementShareCount(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -132,13 +238,195 @@ func (h *Handler) IncrementShareCount(c *gin.Context) {
 		return
 	}
 
-	newCount, err := h.service.IncrementShareCount(uint(id))
+	var req ShareProjectRequest
+	_ = c.ShouldBindJSON(&req) // the channel and token are optional
+
+	link, err := h.service.ShareProject(uint(id), req, shareContext(c))
+	if err != nil {
+		switch {
+		case err.Error() == "project not found":
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+		case err.Error() == "project is not public":
+			response.Error(c, http.StatusForbidden, "This project is not publicly accessible", nil)
+		case errors.Is(err, ErrShareToken):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrShareChannel):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to share project", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, link)
+}
+
+// GetShareToken godoc
+// @Summary Get a share token for a public project
+// @Description Issues a signed token, valid for 30 minutes from the requesting address, to pass when sharing the project. Required for shares when the server enforces share tokens.
+// @Tags Projects
+// @Produce json
+// @Param id path int true "Project ID"
+// @Success 200 {object} response.Response{data=ShareToken}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/public/{id}/share-token [get]
+func (h *Handler) GetShareToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	token, err := h.service.IssueShareToken(uint(id), shareContext(c))
+	if err != nil {
+		switch err.Error() {
+		case "project not found":
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+		case "project is not public":
+			response.Error(c, http.StatusForbidden, "This project is not publicly accessible", nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to issue share token", err.Error())
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, token)
+}
+
+func shareContext(c *gin.Context) ShareContext {
+	ctx := ShareContext{IPAddress: c.ClientIP(), UserAgent: c.GetHeader("User-Agent")}
+	if claims, exists := c.Get("claims"); exists {
+		ctx.UserID = &claims.(*auth.TokenClaims).UserID
+	}
+	return ctx
+}
+
+// GetPublicFeed godoc
+// @Summary Atom feed of newly published projects
+// @Description Atom 1.0 feed of the most recently published public projects, newest first, for feed readers and external sites
+// @Tags Projects
+// @Produce xml
+// @Param department_id query int false "Only projects from this department"
+// @Success 200 {string} string "Atom feed"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /projects/public/feed.xml [get]
+func (h *Handler) GetPublicFeed(c *gin.Context) {
+	var departmentID uint64
+	if dept := c.Query("department_id"); dept != "" {
+		parsed, err := strconv.ParseUint(dept, 10, 32)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid department ID", err.Error())
+			return
+		}
+		departmentID = parsed
+	}
+
+	feed, err := h.service.GetPublicFeed(uint(departmentID), h.baseURL, h.baseURL+c.Request.URL.RequestURI())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build feed", err.Error())
+		return
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build feed", err.Error())
+		return
+	}
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// GetSitemap godoc
+// @Summary Sitemap of public projects
+// @Description sitemaps.org sitemap listing every public project page under its permanent /p/ link with the date it was published. Regenerated when a project is published or unpublished.
+// @Tags Projects
+// @Produce xml
+// @Success 200 {string} string "Sitemap"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /sitemap.xml [get]
+func (h *Handler) GetSitemap(c *gin.Context) {
+	body, generatedAt, err := h.service.GetSitemap(h.baseURL)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build sitemap", err.Error())
+		return
+	}
+	c.Header("Last-Modified", generatedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// ExportProject godoc
+// @Summary Download a project's handover bundle
+// @Description Zip archive of the proposal versions, approved documents, feedback history and project metadata, for handover and accreditation audits. Available to team members, the advisor and admins of the project's department.
+// @Tags Projects
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/{id}/export.zip [get]
+func (h *Handler) ExportProject(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "No authentication claims found")
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	bundle, err := h.service.PrepareExport(uint(id), userClaims.UserID, userClaims.Role, userClaims.DepartmentID)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to update share count", err.Error())
+		switch err.Error() {
+		case "project not found":
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+		case "unauthorized: you cannot export this project":
+			response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to export project", err.Error())
+		}
 		return
 	}
 
-	response.Success(c, gin.H{"share_count": newCount})
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+bundle.FileName()+`"`)
+	c.Status(http.StatusOK)
+	if err := bundle.WriteZip(c.Writer); err != nil {
+		// Headers are already sent; the client gets a truncated archive
+		log.Printf("failed to stream export of project %d: %v", id, err)
+	}
+}
+
+// RedirectBySlug godoc
+// @Summary Resolve a project's permanent link
+// @Description Redirects a permanent identifier such as ASTU-2025-0042 to the public project. The optional share code attributes the visit to a share link.
+// @Tags Projects
+// @Param slug path string true "Permanent identifier"
+// @Param s query string false "Share code"
+// @Success 302
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /p/{slug} [get]
+func (h *Handler) RedirectBySlug(c *gin.Context) {
+	project, err := h.service.ResolveSlug(c.Param("slug"), c.Query("s"))
+	if err != nil {
+		if err.Error() == "project is not public" {
+			response.Error(c, http.StatusForbidden, "This project is not publicly accessible", nil)
+			return
+		}
+		response.Error(c, http.StatusNotFound, "Project not found", nil)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/api/v1/projects/public/"+strconv.FormatUint(uint64(project.ID), 10))
 }
 
 // CreateProject godoc
@@ -149,7 +437,7 @@ func (h *Handler) IncrementShareCount(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param project body CreateProjectRequest true "Project details"
-// @Success 201 {object} response.Response{data=domain.Project}
+// @Success 201 {object} response.Response{data=ProjectResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -175,7 +463,7 @@ func (h *Handler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusCreated, "Project created successfully", project)
+	response.JSON(c, http.StatusCreated, "Project created successfully", toProjectResponse(project))
 }
 
 // GetProjects godoc
@@ -187,7 +475,8 @@ func (h *Handler) CreateProject(c *gin.Context) {
 // @Param visibility query string false "Filter by visibility (private, public)"
 // @Param department_id query int false "Filter by department ID"
 // @Param team_id query int false "Filter by team ID"
-// @Success 200 {object} response.Response{data=[]domain.Project}
+// @Param archived query bool false "List archived projects instead of active ones"
+// @Success 200 {object} response.Response{data=[]ProjectResponse}
 // @Failure 500 {object} response.ErrorResponse
 // @Router /projects [get]
 func (h *Handler) GetProjects(c *gin.Context) {
@@ -202,6 +491,9 @@ func (h *Handler) GetProjects(c *gin.Context) {
 	if teamID := c.Query("team_id"); teamID != "" {
 		filters["team_id"] = teamID
 	}
+	if c.Query("archived") == "true" {
+		filters["archived"] = true
+	}
 
 	projects, err := h.service.GetProjects(filters)
 	if err != nil {
@@ -209,49 +501,58 @@ func (h *Handler) GetProjects(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, projects)
+	response.Success(c, toProjectResponses(projects))
 }
 
 // GetProject godoc
 // @Summary Get project by ID
-// @Description Retrieve specific project details
+// @Description Retrieve specific project details. task_progress is only included for the team, the assigned advisor and admins of the project's department.
 // @Tags Projects
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Project ID"
-// @Success 200 {object} response.Response{data=domain.Project}
+// @Success 200 {object} response.Response{data=ProjectResponse}
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /projects/{id} [get]
 func (h *Handler) GetProject(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "No authentication claims found")
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid project ID", err.Error())
 		return
 	}
 
-	project, err := h.service.GetProject(uint(id))
+	project, err := h.service.GetProject(uint(id), userClaims.UserID, userClaims.Role, userClaims.DepartmentID)
 	if err != nil {
 		response.Error(c, http.StatusNotFound, "Project not found", err.Error())
 		return
 	}
 
-	response.Success(c, project)
+	response.Success(c, toProjectResponse(project))
 }
 
 // UpdateProject godoc
 // @Summary Update project details
-// @Description Update project summary and keywords
+// @Description Update project summary, visibility and README-style Markdown description. The description is stored as written and rendered to sanitized HTML on save; raw HTML in it is escaped and only http, https, mailto and relative links are kept.
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Project ID"
 // @Param project body UpdateProjectRequest true "Updated project details"
-// @Success 200 {object} response.Response{data=domain.Project}
+// @Success 200 {object} response.Response{data=ProjectResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /projects/{id} [put]
 func (h *Handler) UpdateProject(c *gin.Context) {
@@ -282,9 +583,19 @@ func (h *Handler) UpdateProject(c *gin.Context) {
 			response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
 			return
 		}
-	response.JSON(c, http.StatusOK, "Project updated successfully", project)
+		if err.Error() == "project not found" {
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+			return
+		}
+		if errors.Is(err, ErrDescriptionTooLong) {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to update project", err.Error())
+		return
+	}
 
-}
+	response.JSON(c, http.StatusOK, "Project updated successfully", toProjectResponse(project))
 }
 
 // PublishProject godoc
@@ -327,4 +638,101 @@ func (h *Handler) PublishProject(c *gin.Context) {
 
 	response.JSON(c, http.StatusOK, "Project published successfully", nil)
 
+}
+
+// SchedulePublish godoc
+// @Summary Schedule the project's publication
+// @Description Set when the project goes public. It is published at that time once its grade is locked, or as soon as the grade is locked after it. Setting it again reschedules.
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body SchedulePublishRequest true "Publication time"
+// @Success 200 {object} response.Response{data=ProjectResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /projects/{id}/publish-schedule [post]
+func (h *Handler) SchedulePublish(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "No authentication claims found")
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	var req SchedulePublishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	project, err := h.service.SchedulePublish(uint(id), req, userClaims.UserID, userClaims.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrProjectNotFound):
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+		case errors.Is(err, ErrPublishForbidden):
+			response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+		case errors.Is(err, ErrAlreadyPublic):
+			response.Error(c, http.StatusConflict, "Project is already public", err.Error())
+		case errors.Is(err, ErrPublishAtInPast):
+			response.Error(c, http.StatusBadRequest, "Invalid publication time", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to schedule publication", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Publication scheduled", toProjectResponse(project))
+}
+
+// CancelScheduledPublish godoc
+// @Summary Cancel the project's scheduled publication
+// @Description Remove the scheduled publication time; the project stays private until published again
+// @Tags Projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /projects/{id}/publish-schedule [delete]
+func (h *Handler) CancelScheduledPublish(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "No authentication claims found")
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid project ID", err.Error())
+		return
+	}
+
+	if err := h.service.CancelScheduledPublish(uint(id), userClaims.UserID, userClaims.Role); err != nil {
+		switch {
+		case errors.Is(err, ErrProjectNotFound):
+			response.Error(c, http.StatusNotFound, "Project not found", nil)
+		case errors.Is(err, ErrPublishForbidden):
+			response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+		case errors.Is(err, ErrNoPublishScheduled):
+			response.Error(c, http.StatusNotFound, "No scheduled publication", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to cancel the scheduled publication", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Scheduled publication cancelled", nil)
 }
\ No newline at end of file
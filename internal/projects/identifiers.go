@@ -0,0 +1,185 @@
+package projects
+
+import (
+	"backend/internal/domain"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PermalinkPath is where the permanent identifier resolves, e.g. /p/ASTU-2025-0042
+const PermalinkPath = "/p/"
+
+// ShareLink is a traceable link handed out when a project is shared
+type ShareLink struct {
+	Slug       string `json:"slug"`
+	Code       string `json:"code"`
+	URL        string `json:"url"`
+	Channel    string `json:"channel,omitempty"`
+	ShareCount int    `json:"share_count"`
+}
+
+// words left out of a university's initials ("Adama Science and Technology University" -> ASTU)
+var slugStopWords = map[string]bool{"and": true, "of": true, "the": true, "for": true, "in": true, "at": true}
+
+// slugPrefix abbreviates the university name to its initials, e.g. ASTU
+func slugPrefix(universityName string) string {
+	var initials strings.Builder
+	for _, word := range strings.Fields(universityName) {
+		if slugStopWords[strings.ToLower(word)] {
+			continue
+		}
+		for _, r := range word {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				initials.WriteRune(unicode.ToUpper(r))
+				break
+			}
+		}
+		if initials.Len() == 8 {
+			break
+		}
+	}
+	if initials.Len() == 0 {
+		return "PRJ"
+	}
+	return initials.String()
+}
+
+// ensureSlug gives a published project its permanent identifier if it does not have one yet.
+// The year is the year the project was first given an identifier, so it never changes afterwards.
+func (s *Service) ensureSlug(project *domain.Project) (string, error) {
+	if project.Slug != nil {
+		return *project.Slug, nil
+	}
+
+	departmentID := project.DepartmentID
+	if departmentID == 0 {
+		departmentID = project.Team.DepartmentID
+	}
+	universityName, err := s.repo.GetUniversityName(departmentID)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := fmt.Sprintf("%s-%d", slugPrefix(universityName), time.Now().Year())
+	slug, err := s.repo.AssignSlug(project.ID, prefix)
+	if err != nil {
+		return "", err
+	}
+	project.Slug = &slug
+	return slug, nil
+}
+
+// ResolveSlug finds the public, unarchived project behind a permanent identifier and counts a visit
+// when the link carries a share code issued for that project
+func (s *Service) ResolveSlug(slug string, shareCode string) (*domain.Project, error) {
+	project, err := s.repo.GetBySlug(strings.ToUpper(strings.TrimSpace(slug)))
+	if err != nil {
+		return nil, errors.New("project not found")
+	}
+	if project.Visibility != "public" {
+		return nil, errors.New("project is not public")
+	}
+
+	if shareCode != "" {
+		if link, err := s.repo.GetShareLink(shareCode); err == nil && link.ProjectID == project.ID {
+			_ = s.repo.IncrementShareLinkVisits(link.ID)
+		}
+	}
+	return project, nil
+}
+
+// ShareProject issues a traceable share link for a public project and bumps its share count.
+// Repeated shares from the same address (or signed-in user) within ShareDedupWindow get the
+// earlier link back and count once; shares from crawlers are recorded but not counted.
+func (s *Service) ShareProject(id uint, req ShareProjectRequest, ctx ShareContext) (*ShareLink, error) {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("project not found")
+	}
+	if project.Visibility != "public" {
+		return nil, errors.New("project is not public")
+	}
+
+	channel, err := normalizeShareChannel(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+	ipHash := s.hashIP(ctx.IPAddress)
+	if req.Token != "" || s.sharing.TokensRequired {
+		if !s.verifyShareToken(req.Token, project.ID, ipHash) {
+			return nil, ErrShareToken
+		}
+	}
+
+	slug, err := s.ensureSlug(project)
+	if err != nil {
+		return nil, err
+	}
+
+	if recent, err := s.repo.GetRecentShare(project.ID, ipHash, ctx.UserID, time.Now().Add(-ShareDedupWindow)); err == nil && recent.ShareLinkID != nil {
+		if link, err := s.repo.GetShareLinkByID(*recent.ShareLinkID); err == nil {
+			return &ShareLink{
+				Slug:       slug,
+				Code:       link.Code,
+				URL:        PermalinkPath + slug + "?s=" + link.Code,
+				Channel:    link.Channel,
+				ShareCount: project.ShareCount,
+			}, nil
+		}
+	}
+
+	code, err := newShareCode()
+	if err != nil {
+		return nil, err
+	}
+	link := &domain.ProjectShareLink{
+		ProjectID: project.ID,
+		Code:      code,
+		Channel:   channel,
+		SharedBy:  ctx.UserID,
+	}
+	if err := s.repo.CreateShareLink(link); err != nil {
+		return nil, err
+	}
+
+	share := &domain.ProjectShare{
+		ProjectID:   project.ID,
+		ShareLinkID: &link.ID,
+		Channel:     channel,
+		IPHash:      ipHash,
+		UserID:      ctx.UserID,
+		Counted:     !isBot(ctx.UserAgent),
+	}
+	if err := s.repo.CreateShare(share); err != nil {
+		return nil, err
+	}
+
+	count := project.ShareCount
+	if share.Counted {
+		if count, err = s.repo.IncrementShareCount(project.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ShareLink{
+		Slug:       slug,
+		Code:       code,
+		URL:        PermalinkPath + slug + "?s=" + code,
+		Channel:    channel,
+		ShareCount: count,
+	}, nil
+}
+
+// newShareCode returns a short random code such as K3QZ7M2A
+func newShareCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(buf), nil
+}
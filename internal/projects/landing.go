@@ -0,0 +1,208 @@
+package projects
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// landingTTL bounds how stale the department summaries get through changes that do not
+	// invalidate them, such as new views or archiving a cohort
+	landingTTL = 10 * time.Minute
+	// landingKeywords and landingHighlights are how many keywords and projects each summary lists
+	landingKeywords   = 8
+	landingHighlights = 3
+	// landingExcerptLength bounds the summary shown on a highlight card, in characters
+	landingExcerptLength = 240
+)
+
+// ArchiveRow is a public project with the fields the department summaries are built from
+type ArchiveRow struct {
+	ID             uint
+	DepartmentID   uint
+	DepartmentName string
+	DepartmentCode string
+	UniversityID   uint
+	Slug           *string
+	Title          string
+	Summary        string
+	AcademicYear   string
+	ViewCount      int
+	PublishedAt    time.Time
+}
+
+// DepartmentArchive summarises a department's public projects for its landing page
+type DepartmentArchive struct {
+	DepartmentID    uint               `json:"department_id"`
+	Name            string             `json:"name"`
+	Code            string             `json:"code"`
+	UniversityID    uint               `json:"university_id"`
+	ProjectCount    int                `json:"project_count"`
+	TotalViews      int                `json:"total_views"`
+	ProjectsByYear  []YearCount        `json:"projects_by_year"` // newest academic year first
+	TopKeywords     []KeywordCount     `json:"top_keywords"`
+	Highlights      []ArchiveHighlight `json:"highlights"` // latest published first
+	LastPublishedAt *time.Time         `json:"last_published_at,omitempty"`
+}
+
+// YearCount is how many public projects an academic year produced
+type YearCount struct {
+	AcademicYear string `json:"academic_year"`
+	Projects     int    `json:"projects"`
+}
+
+// KeywordCount is a word from titles and summaries with the number of projects using it
+type KeywordCount struct {
+	Keyword  string `json:"keyword"`
+	Projects int    `json:"projects"`
+}
+
+// ArchiveHighlight is a recently published project shown on the landing page
+type ArchiveHighlight struct {
+	ID           uint      `json:"id"`
+	Slug         *string   `json:"slug,omitempty"`
+	Title        string    `json:"title"`
+	Excerpt      string    `json:"excerpt"`
+	AcademicYear string    `json:"academic_year"`
+	ViewCount    int       `json:"view_count"`
+	PublishedAt  time.Time `json:"published_at"`
+}
+
+// landingCache keeps the summaries per university (0 = all) until a project is published or unpublished
+type landingCache struct {
+	mu      sync.Mutex
+	entries map[uint]landingEntry
+}
+
+type landingEntry struct {
+	summaries   []DepartmentArchive
+	generatedAt time.Time
+}
+
+func (c *landingCache) get(universityID uint) ([]DepartmentArchive, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[universityID]
+	if !ok || time.Since(entry.generatedAt) > landingTTL {
+		return nil, false
+	}
+	return entry.summaries, true
+}
+
+func (c *landingCache) put(universityID uint, summaries []DepartmentArchive) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[universityID] = landingEntry{summaries: summaries, generatedAt: time.Now()}
+}
+
+func (c *landingCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[uint]landingEntry)
+}
+
+// GetDepartmentArchives summarises the public projects of every department, optionally of one
+// university (0 = all), in one response for the department landing pages. Departments without
+// public projects are left out; archived cohorts count, since the archive is their history.
+func (s *Service) GetDepartmentArchives(universityID uint) ([]DepartmentArchive, error) {
+	if summaries, ok := s.landing.get(universityID); ok {
+		return summaries, nil
+	}
+
+	rows, err := s.repo.GetArchiveRows(universityID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]DepartmentArchive, 0)
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].DepartmentID == rows[start].DepartmentID {
+			end++
+		}
+		summaries = append(summaries, summarizeDepartment(rows[start:end]))
+		start = end
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+
+	s.landing.put(universityID, summaries)
+	return summaries, nil
+}
+
+// summarizeDepartment builds one department's summary from its rows, newest published first
+func summarizeDepartment(rows []ArchiveRow) DepartmentArchive {
+	summary := DepartmentArchive{
+		DepartmentID: rows[0].DepartmentID,
+		Name:         rows[0].DepartmentName,
+		Code:         rows[0].DepartmentCode,
+		UniversityID: rows[0].UniversityID,
+		ProjectCount: len(rows),
+		Highlights:   make([]ArchiveHighlight, 0, landingHighlights),
+	}
+	latest := rows[0].PublishedAt
+	summary.LastPublishedAt = &latest
+
+	years := make(map[string]int)
+	keywordCounts := make(map[string]int)
+	for _, row := range rows {
+		summary.TotalViews += row.ViewCount
+		if row.AcademicYear != "" {
+			years[row.AcademicYear]++
+		}
+		for word := range keywords(row.Title + " " + row.Summary) {
+			keywordCounts[word]++
+		}
+		if len(summary.Highlights) < landingHighlights {
+			summary.Highlights = append(summary.Highlights, ArchiveHighlight{
+				ID:           row.ID,
+				Slug:         row.Slug,
+				Title:        row.Title,
+				Excerpt:      excerpt(row.Summary, landingExcerptLength),
+				AcademicYear: row.AcademicYear,
+				ViewCount:    row.ViewCount,
+				PublishedAt:  row.PublishedAt,
+			})
+		}
+	}
+
+	summary.ProjectsByYear = make([]YearCount, 0, len(years))
+	for year, count := range years {
+		summary.ProjectsByYear = append(summary.ProjectsByYear, YearCount{AcademicYear: year, Projects: count})
+	}
+	sort.Slice(summary.ProjectsByYear, func(i, j int) bool {
+		return summary.ProjectsByYear[i].AcademicYear > summary.ProjectsByYear[j].AcademicYear
+	})
+
+	summary.TopKeywords = make([]KeywordCount, 0, len(keywordCounts))
+	for word, count := range keywordCounts {
+		summary.TopKeywords = append(summary.TopKeywords, KeywordCount{Keyword: word, Projects: count})
+	}
+	sort.Slice(summary.TopKeywords, func(i, j int) bool {
+		a, b := summary.TopKeywords[i], summary.TopKeywords[j]
+		if a.Projects != b.Projects {
+			return a.Projects > b.Projects
+		}
+		return a.Keyword < b.Keyword
+	})
+	if len(summary.TopKeywords) > landingKeywords {
+		summary.TopKeywords = summary.TopKeywords[:landingKeywords]
+	}
+	return summary
+}
+
+// excerpt shortens text to at most n characters, cutting at a word boundary
+func excerpt(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	cut := n
+	for cut > n/2 && runes[cut] != ' ' {
+		cut--
+	}
+	if runes[cut] != ' ' {
+		cut = n
+	}
+	return string(runes[:cut]) + "…"
+}
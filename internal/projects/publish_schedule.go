@@ -0,0 +1,97 @@
+package projects
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// ScheduledPublishInterval is how often projects due for publication are published
+	ScheduledPublishInterval = 15 * time.Minute
+
+	// scheduledPublishBatch bounds how many projects one run publishes
+	scheduledPublishBatch = 100
+)
+
+var (
+	ErrAlreadyPublic      = errors.New("the project is already public")
+	ErrPublishAtInPast    = errors.New("publish_at must be in the future")
+	ErrNoPublishScheduled = errors.New("the project has no scheduled publication")
+	ErrPublishForbidden   = errors.New("only the team leader, the assigned advisor or an admin can schedule publication")
+	ErrProjectNotFound    = errors.New("project not found")
+)
+
+// SchedulePublishRequest sets when the project goes public. It is published at that time if its
+// grade is locked by then, otherwise as soon as the grade is locked.
+type SchedulePublishRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required" example:"2026-07-01T09:00:00Z"`
+}
+
+// SchedulePublish schedules, or reschedules, the project's publication
+func (s *Service) SchedulePublish(id uint, req SchedulePublishRequest, userID uint, role enums.Role) (*domain.Project, error) {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrProjectNotFound
+	}
+	if !canPublish(project, userID, role) {
+		return nil, ErrPublishForbidden
+	}
+	if project.Visibility == "public" {
+		return nil, ErrAlreadyPublic
+	}
+	if !req.PublishAt.After(time.Now()) {
+		return nil, ErrPublishAtInPast
+	}
+
+	if err := s.repo.SetPublishSchedule(project.ID, &req.PublishAt, &userID); err != nil {
+		return nil, err
+	}
+	project.PublishAt = &req.PublishAt
+	project.PublishScheduledBy = &userID
+	return project, nil
+}
+
+// CancelScheduledPublish removes the project's scheduled publication; the project stays private
+func (s *Service) CancelScheduledPublish(id uint, userID uint, role enums.Role) error {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
+		return ErrProjectNotFound
+	}
+	if !canPublish(project, userID, role) {
+		return ErrPublishForbidden
+	}
+	if project.PublishAt == nil {
+		return ErrNoPublishScheduled
+	}
+	return s.repo.SetPublishSchedule(project.ID, nil, nil)
+}
+
+// PublishScheduledProjects publishes the projects whose publication time has passed and whose
+// grade is locked (run by the scheduler)
+func (s *Service) PublishScheduledProjects() {
+	projects, err := s.repo.GetDueScheduledProjects(time.Now(), scheduledPublishBatch)
+	if err != nil {
+		log.Printf("failed to load projects scheduled for publication: %v", err)
+		return
+	}
+
+	published := 0
+	for i := range projects {
+		project := &projects[i]
+		var actorID uint
+		if project.PublishScheduledBy != nil {
+			actorID = *project.PublishScheduledBy
+		}
+		if err := s.publish(project, actorID); err != nil {
+			log.Printf("failed to publish scheduled project %d: %v", project.ID, err)
+			continue
+		}
+		published++
+	}
+	if published > 0 {
+		log.Printf("published %d scheduled projects", published)
+	}
+}
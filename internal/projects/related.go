@@ -0,0 +1,233 @@
+package projects
+
+import (
+	"backend/internal/domain"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// relatedLimit is how many related projects are returned
+	relatedLimit = 6
+	// relatedTTL is how long a project's related list is cached
+	relatedTTL = time.Hour
+	// keywordCandidates bounds the projects scanned by the keyword fallback
+	keywordCandidates = 500
+	// similarityTimeout bounds the call to the AI similarity index
+	similarityTimeout = 5 * time.Second
+)
+
+// RelatedProject is a public project similar to another one
+type RelatedProject struct {
+	ID           uint    `json:"id"`
+	Title        string  `json:"title"`
+	Summary      string  `json:"summary"`
+	DepartmentID uint    `json:"department_id"`
+	Score        float64 `json:"score"`
+}
+
+// RelatedProjects is the related list for a project and where it came from
+type RelatedProjects struct {
+	ProjectID uint             `json:"project_id"`
+	Source    string           `json:"source"` // "ai" or "keywords"
+	Projects  []RelatedProject `json:"projects"`
+	CachedAt  time.Time        `json:"cached_at"`
+}
+
+type relatedEntry struct {
+	result    *RelatedProjects
+	expiresAt time.Time
+}
+
+type relatedCache struct {
+	mu      sync.Mutex
+	entries map[uint]relatedEntry
+}
+
+func (c *relatedCache) get(id uint) (*RelatedProjects, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (c *relatedCache) put(id uint, result *RelatedProjects) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[id] = relatedEntry{result: result, expiresAt: now.Add(relatedTTL)}
+}
+
+// GetRelatedProjects returns public projects similar to a public project, using the AI similarity
+// index when it is available and keyword overlap otherwise. Results are cached per project.
+func (s *Service) GetRelatedProjects(ctx context.Context, id uint) (*RelatedProjects, error) {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("project not found")
+	}
+	if project.Visibility != "public" || project.IsArchived {
+		return nil, errors.New("project is not public")
+	}
+
+	if cached, ok := s.related.get(id); ok {
+		return cached, nil
+	}
+
+	title := projectTitle(project)
+	result := &RelatedProjects{ProjectID: id, CachedAt: time.Now()}
+
+	if related, err := s.relatedFromIndex(ctx, project, title); err == nil && len(related) > 0 {
+		result.Source = "ai"
+		result.Projects = related
+	} else {
+		if err != nil {
+			log.Printf("similarity index unavailable for project %d, using keyword overlap: %v", id, err)
+		}
+		related, err := s.relatedByKeywords(project, title)
+		if err != nil {
+			return nil, err
+		}
+		result.Source = "keywords"
+		result.Projects = related
+	}
+
+	s.related.put(id, result)
+	return result, nil
+}
+
+func (s *Service) relatedFromIndex(ctx context.Context, project *domain.Project, title string) ([]RelatedProject, error) {
+	if s.similarity == nil {
+		return nil, errors.New("similarity index not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, similarityTimeout)
+	defer cancel()
+
+	// Ask for one extra since the project itself is usually its own best match
+	matches, err := s.similarity.SimilarProjects(ctx, title, project.Summary, relatedLimit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[uint]float64)
+	var ids []uint
+	for _, m := range matches {
+		if m.ID != project.ID {
+			scores[m.ID] = m.Score
+			ids = append(ids, m.ID)
+		}
+	}
+
+	// The index may hold projects that were since hidden or archived
+	projects, err := s.repo.GetPublicByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]RelatedProject, 0, len(projects))
+	for i := range projects {
+		related = append(related, toRelated(&projects[i], scores[projects[i].ID]))
+	}
+	return topRelated(related), nil
+}
+
+func (s *Service) relatedByKeywords(project *domain.Project, title string) ([]RelatedProject, error) {
+	target := keywords(title + " " + project.Summary)
+	if len(target) == 0 {
+		return []RelatedProject{}, nil
+	}
+
+	candidates, err := s.repo.GetPublicCandidates(project.ID, keywordCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	related := []RelatedProject{}
+	for i := range candidates {
+		candidate := &candidates[i]
+		score := jaccard(target, keywords(projectTitle(candidate)+" "+candidate.Summary))
+		if score > 0 {
+			related = append(related, toRelated(candidate, score))
+		}
+	}
+	return topRelated(related), nil
+}
+
+func toRelated(project *domain.Project, score float64) RelatedProject {
+	return RelatedProject{
+		ID:           project.ID,
+		Title:        projectTitle(project),
+		Summary:      project.Summary,
+		DepartmentID: project.DepartmentID,
+		Score:        score,
+	}
+}
+
+func topRelated(related []RelatedProject) []RelatedProject {
+	sort.SliceStable(related, func(i, j int) bool { return related[i].Score > related[j].Score })
+	if len(related) > relatedLimit {
+		related = related[:relatedLimit]
+	}
+	return related
+}
+
+// projectTitle is the title of the highest proposal version loaded with the project
+func projectTitle(project *domain.Project) string {
+	title := ""
+	latest := 0
+	for _, v := range project.Proposal.Versions {
+		if v.VersionNumber > latest {
+			latest = v.VersionNumber
+			title = v.Title
+		}
+	}
+	return title
+}
+
+// stopWords are common words that carry no topical signal
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"into": true, "using": true, "based": true, "system": true, "project": true, "are": true, "its": true,
+	"will": true, "can": true, "our": true, "their": true, "which": true, "also": true, "has": true,
+}
+
+func keywords(text string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]bool)
+	for _, w := range words {
+		if len(w) > 2 && !stopWords[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+// jaccard is the overlap of two keyword sets, from 0 to 1
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
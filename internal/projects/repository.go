@@ -2,20 +2,58 @@ package projects
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type Repository interface {
+	// WithTx returns the repository bound to a unit of work's transaction
+	WithTx(tx *gorm.DB) Repository
+
 	Create(project *domain.Project) error
 	GetByID(id uint) (*domain.Project, error)
 	GetByProposalID(proposalID uint) (*domain.Project, error)
 	GetAll(filters map[string]interface{}) ([]domain.Project, error)
 	GetPublicProjects(filters map[string]interface{}) ([]domain.Project, int, error)
+	GetPublicProjectsAfter(filters map[string]interface{}, after *PublicCursor, limit int) ([]domain.Project, error)
 	Update(project *domain.Project) error
 	UpdateVisibility(id uint, visibility string) error
+	SetPublishSchedule(id uint, publishAt *time.Time, scheduledBy *uint) error
+	// GetDueScheduledProjects returns unpublished projects whose publication time has passed and whose grade is locked
+	GetDueScheduledProjects(now time.Time, limit int) ([]domain.Project, error)
+	UpdateDescription(id uint, description string, descriptionHTML string) error
 	IncrementViewCount(id uint) error
 	IncrementShareCount(id uint) (int, error)
+	GetLinks(projectID uint, approvedOnly bool) ([]domain.ProjectDocumentation, error)
+
+	// Related-project lookups over the public archive
+	GetPublicByIDs(ids []uint) ([]domain.Project, error)
+	GetArchiveRows(universityID uint) ([]ArchiveRow, error)
+	GetPublicCandidates(excludeID uint, limit int) ([]domain.Project, error)
+	GetRecentlyPublished(departmentID uint, limit int) ([]domain.Project, error)
+
+	// Permanent identifiers and share links
+	GetBySlug(slug string) (*domain.Project, error)
+	GetUniversityName(departmentID uint) (string, error)
+	AssignSlug(id uint, prefix string) (string, error)
+	CreateShareLink(link *domain.ProjectShareLink) error
+	GetShareLink(code string) (*domain.ProjectShareLink, error)
+	IncrementShareLinkVisits(id uint) error
+	GetShareLinkByID(id uint) (*domain.ProjectShareLink, error)
+	CreateShare(share *domain.ProjectShare) error
+	GetRecentShare(projectID uint, ipHash string, userID *uint, since time.Time) (*domain.ProjectShare, error)
+
+	// Export bundle
+	GetFeedbackHistory(proposalID uint) ([]domain.Feedback, error)
+	GetApprovedDocuments(projectID uint) ([]domain.ProjectDocumentation, error)
+
+	// Continuation across cohorts
+	CreateContinuation(project *domain.Project) error
+	GetLineage(projectID uint) ([]domain.Project, map[uint]int, error)
 }
 
 type repository struct {
@@ -27,6 +65,10 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
 func (r *repository) Create(project *domain.Project) error {
 	return r.db.Create(project).Error
 }
@@ -41,6 +83,18 @@ func (r *repository) GetByID(id uint) (*domain.Project, error) {
 	return &project, err
 }
 
+// GetLinks returns the project's code and deployed links with their latest check. With approvedOnly
+// only the links the advisor approved are returned, carried-over ones included.
+func (r *repository) GetLinks(projectID uint, approvedOnly bool) ([]domain.ProjectDocumentation, error) {
+	var links []domain.ProjectDocumentation
+	query := r.db.Where("project_id = ? AND document_type IN ?", projectID, []string{"code_link", "deployed_link"})
+	if approvedOnly {
+		query = query.Where("status IN ?", []enums.DocumentStatus{enums.DocumentStatusApproved, enums.DocumentStatusCarriedOver})
+	}
+	err := query.Order("document_type").Find(&links).Error
+	return links, err
+}
+
 func (r *repository) GetByProposalID(proposalID uint) (*domain.Project, error) {
 	var project domain.Project
 	err := r.db.Where("proposal_id = ?", proposalID).First(&project).Error
@@ -69,6 +123,7 @@ func (r *repository) GetAll(filters map[string]interface{}) ([]domain.Project, e
 	if teamID, ok := filters["team_id"]; ok {
 		query = query.Where("team_id = ?", teamID)
 	}
+	query = archivedFilter(query, filters)
 
 	err := query.Order("created_at DESC").Find(&projects).Error
 	return projects, err
@@ -79,10 +134,46 @@ func (r *repository) Update(project *domain.Project) error {
 	return r.db.Model(project).Omit("Team", "Proposal", "Department", "Approver").Updates(project).Error
 }
 
+// UpdateDescription stores the Markdown description and its rendering; an empty description clears both
+func (r *repository) UpdateDescription(id uint, description string, descriptionHTML string) error {
+	return r.db.Model(&domain.Project{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"description":      description,
+		"description_html": descriptionHTML,
+	}).Error
+}
+
 func (r *repository) UpdateVisibility(id uint, visibility string) error {
+	updates := map[string]interface{}{"visibility": visibility}
+	if visibility == "public" {
+		// Keep the first publication time so re-publishing does not resurface the project in the feed
+		updates["published_at"] = gorm.Expr("COALESCE(published_at, ?)", time.Now())
+		// A published project has no publication left to schedule
+		updates["publish_at"] = nil
+		updates["publish_scheduled_by"] = nil
+	}
+	return r.db.Model(&domain.Project{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *repository) SetPublishSchedule(id uint, publishAt *time.Time, scheduledBy *uint) error {
 	return r.db.Model(&domain.Project{}).
 		Where("id = ?", id).
-		Update("visibility", visibility).Error
+		Updates(map[string]interface{}{"publish_at": publishAt, "publish_scheduled_by": scheduledBy}).Error
+}
+
+func (r *repository) GetDueScheduledProjects(now time.Time, limit int) ([]domain.Project, error) {
+	var projects []domain.Project
+	err := r.db.
+		Preload("Proposal.Versions").
+		Preload("Team.Members.User").
+		Preload("Team.Department").
+		Where("visibility <> ? AND publish_at <= ?", "public", now).
+		Where("EXISTS (SELECT 1 FROM project_grades WHERE project_grades.project_id = projects.id)").
+		Order("publish_at").
+		Limit(limit).
+		Find(&projects).Error
+	return projects, err
 }
 
 func (r *repository) IncrementViewCount(id uint) error {
@@ -108,24 +199,12 @@ func (r *repository) GetPublicProjects(filters map[string]interface{}) ([]domain
 	var projects []domain.Project
 	var total int64
 
-	query := r.db.Model(&domain.Project{}).Where("visibility = ?", "public")
-
-	// Apply filters
-	if deptID, ok := filters["department_id"]; ok {
-		query = query.Where("department_id = ?", deptID)
-	}
-	if year, ok := filters["year"]; ok {
-		query = query.Where("EXTRACT(YEAR FROM created_at) = ?", year)
-	}
-	if search, ok := filters["search"].(string); ok && search != "" {
-		searchPattern := "%" + search + "%"
-		query = query.Where("summary ILIKE ?", searchPattern)
-	}
+	query := publicProjectsQuery(r.db, filters)
 
 	// Get total count
 	query.Count(&total)
 
-	// Apply sorting
+	// Apply sorting; id breaks ties so pages do not overlap
 	sortBy := "created_at DESC"
 	if sort, ok := filters["sort"].(string); ok {
 		switch sort {
@@ -137,7 +216,7 @@ func (r *repository) GetPublicProjects(filters map[string]interface{}) ([]domain
 			sortBy = "created_at DESC"
 		}
 	}
-	query = query.Order(sortBy)
+	query = query.Order(sortBy).Order("id DESC")
 
 	// Apply pagination
 	if page, ok := filters["page"].(int); ok {
@@ -148,16 +227,49 @@ func (r *repository) GetPublicProjects(filters map[string]interface{}) ([]domain
 	}
 
 	// Preload relationships
-	err := query.
+	err := preloadPublicProject(query).Find(&projects).Error
+
+	return projects, int(total), err
+}
+
+func (r *repository) GetPublicProjectsAfter(filters map[string]interface{}, after *PublicCursor, limit int) ([]domain.Project, error) {
+	var projects []domain.Project
+
+	query := publicProjectsQuery(r.db, filters)
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	err := preloadPublicProject(query.Order("created_at DESC").Order("id DESC").Limit(limit)).
+		Find(&projects).Error
+	return projects, err
+}
+
+// publicProjectsQuery applies the public listing's filters
+func publicProjectsQuery(db *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	query := db.Model(&domain.Project{}).Where("visibility = ?", "public")
+
+	query = archivedFilter(query, filters)
+	if deptID, ok := filters["department_id"]; ok {
+		query = query.Where("department_id = ?", deptID)
+	}
+	if year, ok := filters["year"]; ok {
+		query = query.Where("EXTRACT(YEAR FROM created_at) = ?", year)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		searchPattern := "%" + search + "%"
+		query = query.Where("summary ILIKE ?", searchPattern)
+	}
+	return query
+}
+
+func preloadPublicProject(query *gorm.DB) *gorm.DB {
+	return query.
 		Preload("Team.Members.User").
 		Preload("Proposal.Advisor").
 		Preload("Department").
 		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
 			return db.Order("version_number DESC")
-		}).
-		Find(&projects).Error
-
-	return projects, int(total), err
+		})
 }
 
 func (r *repository) GetByAdvisor(advisorID uint) ([]domain.Project, error) {
@@ -173,3 +285,277 @@ func (r *repository) GetByAdvisor(advisorID uint) ([]domain.Project, error) {
 	return projects, err
 }
 
+// GetPublicByIDs loads the given projects that are public and not archived, with their latest version
+func (r *repository) GetPublicByIDs(ids []uint) ([]domain.Project, error) {
+	var projects []domain.Project
+	if len(ids) == 0 {
+		return projects, nil
+	}
+	err := r.db.
+		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("id IN ? AND visibility = ? AND is_archived = ?", ids, "public", false).
+		Find(&projects).Error
+	return projects, err
+}
+
+// GetPublicCandidates returns the most recent public projects other than excludeID, for keyword matching
+func (r *repository) GetPublicCandidates(excludeID uint, limit int) ([]domain.Project, error) {
+	var projects []domain.Project
+	err := r.db.
+		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("id <> ? AND visibility = ? AND is_archived = ?", excludeID, "public", false).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&projects).Error
+	return projects, err
+}
+
+// GetArchiveRows returns every public project with its department and title, grouped by department
+// and newest published first, optionally of one university (0 = all)
+func (r *repository) GetArchiveRows(universityID uint) ([]ArchiveRow, error) {
+	var rows []ArchiveRow
+	query := r.db.Table("projects p").
+		Select(`p.id, p.department_id, d.name AS department_name, d.code AS department_code, d.university_id,
+			p.slug, COALESCE(lv.title, '') AS title, p.summary, pr.academic_year, p.view_count,
+			COALESCE(p.published_at, p.created_at) AS published_at`).
+		Joins("JOIN departments d ON d.id = p.department_id AND d.deleted_at IS NULL").
+		Joins("JOIN proposals pr ON pr.id = p.proposal_id").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT title FROM proposal_versions
+			WHERE proposal_id = p.proposal_id
+			ORDER BY is_approved DESC, version_number DESC
+			LIMIT 1
+		) lv ON true`).
+		Where("p.visibility = ?", "public")
+	if universityID != 0 {
+		query = query.Where("d.university_id = ?", universityID)
+	}
+	err := query.Order("p.department_id, published_at DESC, p.id DESC").Scan(&rows).Error
+	return rows, err
+}
+
+// GetRecentlyPublished returns the newest public, unarchived projects, optionally for one department (0 = all)
+func (r *repository) GetRecentlyPublished(departmentID uint, limit int) ([]domain.Project, error) {
+	var projects []domain.Project
+	query := r.db.
+		Preload("Proposal.Versions").
+		Preload("Team").
+		Preload("Department").
+		Where("visibility = ? AND is_archived = ?", "public", false)
+	if departmentID != 0 {
+		query = query.Where("department_id = ?", departmentID)
+	}
+	err := query.Order("COALESCE(published_at, created_at) DESC").Limit(limit).Find(&projects).Error
+	return projects, err
+}
+
+// archivedFilter hides archived projects unless the caller asked for them
+func archivedFilter(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	if archived, ok := filters["archived"]; ok {
+		return query.Where("is_archived = ?", archived)
+	}
+	return query.Where("is_archived = ?", false)
+}
+
+func (r *repository) GetBySlug(slug string) (*domain.Project, error) {
+	var project domain.Project
+	if err := r.db.Where("slug = ? AND is_archived = ?", slug, false).First(&project).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *repository) GetUniversityName(departmentID uint) (string, error) {
+	var name string
+	err := r.db.Table("departments").
+		Select("universities.name").
+		Joins("JOIN universities ON universities.id = departments.university_id").
+		Where("departments.id = ?", departmentID).
+		Scan(&name).Error
+	return name, err
+}
+
+// AssignSlug gives the project the next identifier in its prefix's sequence, e.g. ASTU-2025-0042.
+// The unique index arbitrates concurrent publishes; a lost race retries with the next number.
+// A project that already has a slug keeps it.
+func (r *repository) AssignSlug(id uint, prefix string) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt < 5; attempt++ {
+		var existing domain.Project
+		if err := r.db.Select("id", "slug").First(&existing, id).Error; err != nil {
+			return "", err
+		}
+		if existing.Slug != nil {
+			return *existing.Slug, nil
+		}
+
+		// Compare the numbers, not the strings: "-10000" sorts below "-9999"
+		var last int
+		suffix := len(prefix) + 2
+		if err := r.db.Model(&domain.Project{}).
+			Select("COALESCE(MAX(CAST(SUBSTRING(slug FROM ?) AS INTEGER)), 0)", suffix).
+			Where("LEFT(slug, ?) = ? AND SUBSTRING(slug FROM ?) ~ '^[0-9]+$'", suffix-1, prefix+"-", suffix).
+			Scan(&last).Error; err != nil {
+			return "", err
+		}
+		next := last + 1
+		slug := fmt.Sprintf("%s-%04d", prefix, next)
+
+		result := r.db.Model(&domain.Project{}).
+			Where("id = ? AND slug IS NULL", id).
+			Update("slug", slug)
+		if result.Error == nil && result.RowsAffected == 1 {
+			return slug, nil
+		}
+		// Either the number was taken or the project got its slug meanwhile; re-read and retry
+		lastErr = result.Error
+	}
+	if lastErr == nil {
+		lastErr = errors.New("could not assign project identifier")
+	}
+	return "", lastErr
+}
+
+func (r *repository) CreateShareLink(link *domain.ProjectShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *repository) GetShareLink(code string) (*domain.ProjectShareLink, error) {
+	var link domain.ProjectShareLink
+	if err := r.db.Where("code = ?", code).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *repository) IncrementShareLinkVisits(id uint) error {
+	return r.db.Model(&domain.ProjectShareLink{}).
+		Where("id = ?", id).
+		Update("visits", gorm.Expr("visits + ?", 1)).Error
+}
+
+func (r *repository) GetShareLinkByID(id uint) (*domain.ProjectShareLink, error) {
+	var link domain.ProjectShareLink
+	if err := r.db.First(&link, id).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *repository) CreateShare(share *domain.ProjectShare) error {
+	return r.db.Create(share).Error
+}
+
+// GetRecentShare returns the latest share of the project since the given time from the same
+// address, or from the same user when signed in
+func (r *repository) GetRecentShare(projectID uint, ipHash string, userID *uint, since time.Time) (*domain.ProjectShare, error) {
+	query := r.db.Where("project_id = ? AND created_at >= ?", projectID, since)
+	if userID != nil {
+		query = query.Where("ip_hash = ? OR user_id = ?", ipHash, *userID)
+	} else {
+		query = query.Where("ip_hash = ?", ipHash)
+	}
+	var share domain.ProjectShare
+	if err := query.Order("created_at DESC").First(&share).Error; err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (r *repository) GetFeedbackHistory(proposalID uint) ([]domain.Feedback, error) {
+	var feedbacks []domain.Feedback
+	err := r.db.Preload("Reviewer").
+		Where("proposal_id = ?", proposalID).
+		Order("created_at ASC").
+		Find(&feedbacks).Error
+	return feedbacks, err
+}
+
+func (r *repository) GetApprovedDocuments(projectID uint) ([]domain.ProjectDocumentation, error) {
+	var docs []domain.ProjectDocumentation
+	err := r.db.Where("project_id = ? AND status = ?", projectID, enums.DocumentStatusApproved).
+		Order("document_type ASC").
+		Find(&docs).Error
+	return docs, err
+}
+
+// lineageDepth bounds how many cohorts the lineage walks in each direction
+const lineageDepth = 20
+
+// CreateContinuation creates a project continuing PreviousProjectID and copies the earlier project's
+// approved documents onto it, marked as carried over, so the new team starts from them
+func (r *repository) CreateContinuation(project *domain.Project) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+
+		var docs []domain.ProjectDocumentation
+		err := tx.Where("project_id = ? AND status IN ?", *project.PreviousProjectID,
+			[]enums.DocumentStatus{enums.DocumentStatusApproved, enums.DocumentStatusCarriedOver}).
+			Find(&docs).Error
+		if err != nil || len(docs) == 0 {
+			return err
+		}
+		for i := range docs {
+			sourceID := docs[i].ID
+			docs[i].ID = 0
+			docs[i].ProjectID = project.ID
+			docs[i].Status = string(enums.DocumentStatusCarriedOver)
+			docs[i].CarriedOverFromID = &sourceID
+		}
+		return tx.Create(&docs).Error
+	})
+}
+
+// GetLineage returns the projects the project continues and those continuing it, with the number
+// of steps from the project: negative for earlier cohorts, positive for later ones
+func (r *repository) GetLineage(projectID uint) ([]domain.Project, map[uint]int, error) {
+	var rows []struct {
+		ID       uint
+		Position int
+	}
+	err := r.db.Raw(`
+		WITH RECURSIVE earlier AS (
+			SELECT id, previous_project_id, 0 AS position FROM projects WHERE id = ?
+			UNION ALL
+			SELECT p.id, p.previous_project_id, e.position - 1
+			FROM projects p JOIN earlier e ON p.id = e.previous_project_id
+			WHERE e.position > ?
+		), later AS (
+			SELECT id, 0 AS position FROM projects WHERE id = ?
+			UNION ALL
+			SELECT p.id, l.position + 1
+			FROM projects p JOIN later l ON p.previous_project_id = l.id
+			WHERE l.position < ?
+		)
+		SELECT id, position FROM earlier WHERE position < 0
+		UNION ALL
+		SELECT id, position FROM later WHERE position > 0`,
+		projectID, -lineageDepth, projectID, lineageDepth).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, nil, err
+	}
+
+	positions := make(map[uint]int, len(rows))
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		positions[row.ID] = row.Position
+		ids = append(ids, row.ID)
+	}
+
+	var projects []domain.Project
+	err = r.db.
+		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Preload("Team").
+		Where("id IN ?", ids).
+		Find(&projects).Error
+	return projects, positions, err
+}
@@ -1,24 +1,58 @@
 package projects
 
 import (
+	"backend/internal/ai_checker"
 	"backend/internal/domain"
+	"backend/internal/files"
 	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/markdown"
+	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
 )
 
 type Service struct {
 	repo         Repository
 	proposalRepo ProposalRepository
+	bus          *events.Bus
+	similarity   SimilarityIndex
+	tasks        TaskProgressSource
+	related      *relatedCache
+	sitemap      *sitemapCache
+	landing      *landingCache
+	sharing      ShareOptions
+	uploader     *files.Uploader
+}
+
+// SimilarityIndex finds similar projects; implemented by the AI checker client
+type SimilarityIndex interface {
+	SimilarProjects(ctx context.Context, title, summary string, limit int) ([]ai_checker.SimilarProject, error)
 }
 
 type ProposalRepository interface {
 	GetByID(id uint) (*domain.Proposal, error)
 }
 
-func NewService(repo Repository, proposalRepo ProposalRepository) *Service {
+// TaskProgressSource summarises a team's task checklist; implemented by the tasks repository
+type TaskProgressSource interface {
+	GetProgress(teamID uint) (*domain.TaskProgress, error)
+}
+
+func NewService(repo Repository, proposalRepo ProposalRepository, bus *events.Bus, similarity SimilarityIndex, tasks TaskProgressSource, sharing ShareOptions, uploader *files.Uploader) *Service {
 	return &Service{
 		repo:         repo,
 		proposalRepo: proposalRepo,
+		bus:          bus,
+		similarity:   similarity,
+		tasks:        tasks,
+		related:      &relatedCache{entries: make(map[uint]relatedEntry)},
+		sitemap:      &sitemapCache{},
+		landing:      &landingCache{entries: make(map[uint]landingEntry)},
+		sharing:      sharing,
+		uploader:     uploader,
 	}
 }
 
@@ -28,11 +62,22 @@ type CreateProjectRequest struct {
 	Keywords   string `json:"keywords"`
 }
 
+type ShareProjectRequest struct {
+	Channel string `json:"channel" example:"linkedin"`                 // link (default), email, twitter, linkedin, facebook, whatsapp or telegram
+	Token   string `json:"token,omitempty" example:"MTIuMTc2MDAw.x9Q"` // from GET /projects/public/{id}/share-token; required when share tokens are enforced
+}
+
 type UpdateProjectRequest struct {
-	Summary  string `json:"summary"`
-	Visibility string `json:"visibility"`
+	Summary     string  `json:"summary"`
+	Visibility  string  `json:"visibility"`
+	Description *string `json:"description"` // Markdown; an empty string clears it
 }
 
+// MaxDescriptionLength bounds a project's Markdown description, in characters
+const MaxDescriptionLength = 20000
+
+var ErrDescriptionTooLong = fmt.Errorf("the description can be at most %d characters", MaxDescriptionLength)
+
 func (s *Service) CreateProject(req CreateProjectRequest, userID uint) (*domain.Project, error) {
 	// 1. Verify proposal exists and is approved
 	proposal, err := s.proposalRepo.GetByID(req.ProposalID)
@@ -70,6 +115,15 @@ func (s *Service) CreateProject(req CreateProjectRequest, userID uint) (*domain.
 		Visibility:   "private",
 	}
 
+	// A proposal continuing an earlier cohort's project takes over its documentation
+	if proposal.ContinuesProjectID != nil {
+		project.PreviousProjectID = proposal.ContinuesProjectID
+		if err := s.repo.CreateContinuation(project); err != nil {
+			return nil, err
+		}
+		return s.repo.GetByID(project.ID)
+	}
+
 	if err := s.repo.Create(project); err != nil {
 		return nil, err
 	}
@@ -77,7 +131,9 @@ func (s *Service) CreateProject(req CreateProjectRequest, userID uint) (*domain.
 	return s.repo.GetByID(project.ID)
 }
 
-func (s *Service) GetProject(id uint) (*domain.Project, error) {
+// GetProject returns a project. The team's task progress and its links awaiting approval are only
+// included for the team, the assigned advisor and admins of the project's department.
+func (s *Service) GetProject(id uint, userID uint, role enums.Role, departmentID uint) (*domain.Project, error) {
 	project, err := s.repo.GetByID(id)
 	if err != nil {
 		return nil, err
@@ -86,9 +142,47 @@ func (s *Service) GetProject(id uint) (*domain.Project, error) {
 	// Increment view count
 	_ = s.repo.IncrementViewCount(id)
 
+	insider := isInsider(project, userID, role, departmentID)
+	if insider {
+		if progress, err := s.tasks.GetProgress(project.TeamID); err == nil {
+			project.TaskProgress = progress
+		}
+	}
+	if links, err := s.repo.GetLinks(project.ID, !insider); err == nil {
+		project.Links = links
+	}
+	if lineage, err := s.getLineage(project.ID, false); err == nil {
+		project.Lineage = lineage
+	}
+
 	return project, nil
 }
 
+// getLineage lists the earlier and later cohorts of the project, earliest first. With publicOnly
+// the projects that are not public are left out.
+func (s *Service) getLineage(projectID uint, publicOnly bool) ([]domain.ProjectLineageEntry, error) {
+	projects, positions, err := s.repo.GetLineage(projectID)
+	if err != nil {
+		return nil, err
+	}
+	lineage := make([]domain.ProjectLineageEntry, 0, len(projects))
+	for i := range projects {
+		p := &projects[i]
+		if publicOnly && p.Visibility != "public" {
+			continue
+		}
+		lineage = append(lineage, domain.ProjectLineageEntry{
+			ProjectID:    p.ID,
+			Title:        projectTitle(p),
+			TeamName:     p.Team.Name,
+			AcademicYear: p.Proposal.AcademicYear,
+			Position:     positions[p.ID],
+		})
+	}
+	sort.Slice(lineage, func(i, j int) bool { return lineage[i].Position < lineage[j].Position })
+	return lineage, nil
+}
+
 func (s *Service) GetProjects(filters map[string]interface{}) ([]domain.Project, error) {
 	return s.repo.GetAll(filters)
 }
@@ -112,13 +206,34 @@ func (s *Service) UpdateProject(id uint, req UpdateProjectRequest, userID uint,
 	if req.Summary != "" {
 		project.Summary = req.Summary
 	}
+	visibilityChanged := req.Visibility != "" && req.Visibility != project.Visibility
 	if req.Visibility != "" {
 		project.Visibility = req.Visibility
 	}
+	if req.Description != nil {
+		description := markdown.Normalize(*req.Description)
+		if utf8.RuneCountInString(description) > MaxDescriptionLength {
+			return nil, ErrDescriptionTooLong
+		}
+		project.Description = description
+		project.DescriptionHTML = ""
+		if description != "" {
+			project.DescriptionHTML = markdown.Render(description)
+		}
+	}
 
 	if err := s.repo.Update(project); err != nil {
 		return nil, err
 	}
+	if req.Description != nil {
+		if err := s.repo.UpdateDescription(project.ID, project.Description, project.DescriptionHTML); err != nil {
+			return nil, err
+		}
+	}
+	if visibilityChanged {
+		s.sitemap.invalidate()
+		s.landing.invalidate()
+	}
 
 	return project, nil
 }
@@ -127,16 +242,87 @@ func (s *Service) PublishProject(id uint, userID uint, role enums.Role) error {
 		project, err := s.repo.GetByID(id)
 	if err != nil { return err }
 
+	if !canPublish(project, userID, role) {
+		return errors.New("unauthorized: only team leader or assigned advisor can publish")
+	}
+	return s.publish(project, userID)
+}
+
+// isInsider reports whether the user works on the project: an accepted team member, the assigned
+// advisor or an admin of the project's department
+func isInsider(project *domain.Project, userID uint, role enums.Role, departmentID uint) bool {
+	if role == enums.RoleAdmin && project.DepartmentID == departmentID {
+		return true
+	}
+	if project.Proposal.AdvisorID != nil && *project.Proposal.AdvisorID == userID {
+		return true
+	}
+	for _, m := range project.Team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			return true
+		}
+	}
+	return false
+}
+
+// canPublish allows the team's creator, the assigned advisor and admins to publish a project
+func canPublish(project *domain.Project, userID uint, role enums.Role) bool {
 	// 🔒 FIX: Allow Creator OR Advisor OR Admin
 	isCreator := project.Team.CreatedBy == userID
 	isAdvisor := project.Proposal.AdvisorID != nil && *project.Proposal.AdvisorID == userID
 	isAdmin := role == enums.RoleAdmin
 
-	if !isCreator && !isAdvisor && !isAdmin {
-		return errors.New("unauthorized: only team leader or assigned advisor can publish")
+	return isCreator || isAdvisor || isAdmin
+}
+
+// publish makes the project public, gives it its permanent identifier and tells the team
+func (s *Service) publish(project *domain.Project, actorID uint) error {
+	id := project.ID
+	if err := s.repo.UpdateVisibility(id, "public"); err != nil {
+		return err
 	}
 
-	return s.repo.UpdateVisibility(id, "public")
+	// Published projects get their permanent identifier
+	slug, err := s.ensureSlug(project)
+	if err != nil {
+		return err
+	}
+	s.sitemap.invalidate()
+	s.landing.invalidate()
+
+	title := ""
+	if len(project.Proposal.Versions) > 0 {
+		title = project.Proposal.Versions[len(project.Proposal.Versions)-1].Title
+		for _, v := range project.Proposal.Versions {
+			if v.IsApproved {
+				title = v.Title
+			}
+		}
+	}
+	var memberIDs []uint
+	for _, m := range project.Team.Members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+	departmentID := project.DepartmentID
+	if departmentID == 0 {
+		departmentID = project.Team.DepartmentID
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.ProjectPublished,
+		EntityType: "project",
+		EntityID:   id,
+		ActorID:    actorID,
+		UserIDs:    memberIDs,
+		Data: map[string]interface{}{
+			"project_id":    id,
+			"department_id": departmentID,
+			"slug":          slug,
+			"title":         title,
+			"summary":       project.Summary,
+		},
+	})
+	return nil
 }
 
 // GetPublicProjects returns public projects with search and pagination
@@ -155,13 +341,19 @@ func (s *Service) GetPublicProject(id uint) (*domain.Project, error) {
 		return nil, errors.New("project is not public")
 	}
 
+	// Projects published before identifiers existed get one on first view
+	_, _ = s.ensureSlug(project)
+
 	// Increment view count
 	_ = s.repo.IncrementViewCount(id)
 
+	if links, err := s.repo.GetLinks(project.ID, true); err == nil {
+		project.Links = links
+	}
+	if lineage, err := s.getLineage(project.ID, true); err == nil {
+		project.Lineage = lineage
+	}
+
 	return project, nil
 }
 
-// IncrementShareCount increments and returns the new share count
-func (s *Service) IncrementShareCount(id uint) (int, error) {
-	return s.repo.IncrementShareCount(id)
-}
@@ -0,0 +1,133 @@
+package projects
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ShareDedupWindow is how long repeated shares of a project from the same address count once
+	ShareDedupWindow = time.Hour
+	// ShareTokenTTL is how long a signed share token can be used
+	ShareTokenTTL = 30 * time.Minute
+
+	defaultShareChannel = "link"
+)
+
+// shareChannels are the channels a share can be tracked under
+var shareChannels = map[string]bool{
+	"link": true, "email": true, "twitter": true, "linkedin": true,
+	"facebook": true, "whatsapp": true, "telegram": true,
+}
+
+// Crawlers and scripted clients do not count as shares
+var botUserAgents = []string{"bot", "crawl", "spider", "slurp", "curl", "wget", "python-requests", "headless", "preview"}
+
+var (
+	ErrShareChannel = errors.New("unknown share channel; use link, email, twitter, linkedin, facebook, whatsapp or telegram")
+	ErrShareToken   = errors.New("the share token is missing, invalid or expired")
+)
+
+// ShareOptions configures share tokens and the keyed hashing of sharer addresses
+type ShareOptions struct {
+	Secret         []byte
+	TokensRequired bool // refuse shares without a valid token
+}
+
+// ShareContext describes who is sharing
+type ShareContext struct {
+	IPAddress string
+	UserAgent string
+	UserID    *uint
+}
+
+// ShareToken is a signed, short-lived permission to share a project from one address
+type ShareToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IssueShareToken signs a token for sharing a public project from the requesting address.
+// Pages fetch one when shown, so shares have to come from a client that loaded the project.
+func (s *Service) IssueShareToken(id uint, ctx ShareContext) (*ShareToken, error) {
+	project, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("project not found")
+	}
+	if project.Visibility != "public" {
+		return nil, errors.New("project is not public")
+	}
+
+	expires := time.Now().Add(ShareTokenTTL).Truncate(time.Second)
+	payload := fmt.Sprintf("%d.%d", id, expires.Unix())
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + s.signShare(payload, s.hashIP(ctx.IPAddress))
+	return &ShareToken{Token: token, ExpiresAt: expires}, nil
+}
+
+// verifyShareToken checks the token was issued for this project and address and has not expired
+func (s *Service) verifyShareToken(token string, id uint, ipHash string) bool {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	payload := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(s.signShare(payload, ipHash))) {
+		return false
+	}
+
+	projectPart, expiresPart, ok := strings.Cut(payload, ".")
+	if !ok || projectPart != strconv.FormatUint(uint64(id), 10) {
+		return false
+	}
+	expires, err := strconv.ParseInt(expiresPart, 10, 64)
+	return err == nil && time.Now().Unix() <= expires
+}
+
+func (s *Service) signShare(payload, ipHash string) string {
+	mac := hmac.New(sha256.New, s.sharing.Secret)
+	mac.Write([]byte("share:" + payload + ":" + ipHash))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// hashIP keys the address with the secret, so the stored hash cannot be reversed by brute force
+func (s *Service) hashIP(ip string) string {
+	mac := hmac.New(sha256.New, s.sharing.Secret)
+	mac.Write([]byte("ip:" + ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeShareChannel defaults to a plain link and rejects channels that are not tracked
+func normalizeShareChannel(channel string) (string, error) {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel == "" {
+		return defaultShareChannel, nil
+	}
+	if !shareChannels[channel] {
+		return "", ErrShareChannel
+	}
+	return channel, nil
+}
+
+func isBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, marker := range botUserAgents {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
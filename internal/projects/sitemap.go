@@ -0,0 +1,95 @@
+package projects
+
+import (
+	"encoding/xml"
+	"sync"
+	"time"
+)
+
+const (
+	// sitemapTTL bounds how stale the cached sitemap can get through changes that do not
+	// invalidate it, such as archiving a cohort
+	sitemapTTL    = 6 * time.Hour
+	sitemapMaxURL = 50000 // protocol limit per sitemap file
+	sitemapXmlns  = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+// URLSet is a sitemaps.org sitemap
+type URLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapCache keeps the rendered sitemap until a project is published or unpublished
+type sitemapCache struct {
+	mu          sync.Mutex
+	baseURL     string
+	body        []byte
+	generatedAt time.Time
+}
+
+func (c *sitemapCache) get(baseURL string) ([]byte, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.body == nil || c.baseURL != baseURL || time.Since(c.generatedAt) > sitemapTTL {
+		return nil, time.Time{}, false
+	}
+	return c.body, c.generatedAt, true
+}
+
+func (c *sitemapCache) put(baseURL string, body []byte, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseURL, c.body, c.generatedAt = baseURL, body, at
+}
+
+func (c *sitemapCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.body = nil
+}
+
+// GetSitemap returns the sitemap of public project pages as XML with the time it was generated.
+// Each project is listed under its permanent /p/ link with the date it was published.
+func (s *Service) GetSitemap(baseURL string) ([]byte, time.Time, error) {
+	if body, at, ok := s.sitemap.get(baseURL); ok {
+		return body, at, nil
+	}
+
+	projects, err := s.repo.GetRecentlyPublished(0, sitemapMaxURL)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	set := URLSet{Xmlns: sitemapXmlns, URLs: make([]SitemapURL, 0, len(projects))}
+	for i := range projects {
+		project := &projects[i]
+		slug, err := s.ensureSlug(project)
+		if err != nil {
+			continue
+		}
+		lastMod := project.CreatedAt
+		if project.PublishedAt != nil {
+			lastMod = *project.PublishedAt
+		}
+		set.URLs = append(set.URLs, SitemapURL{
+			Loc:     baseURL + PermalinkPath + slug,
+			LastMod: lastMod.UTC().Format("2006-01-02"),
+		})
+	}
+
+	body, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	body = append([]byte(xml.Header), body...)
+	now := time.Now()
+	s.sitemap.put(baseURL, body, now)
+	return body, now, nil
+}
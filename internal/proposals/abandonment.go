@@ -0,0 +1,277 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// AbandonmentCheckInterval is how often the scheduler warns inactive teams and abandons proposals
+	AbandonmentCheckInterval = time.Hour
+	// MaxAbandonAfterDays bounds the abandonment setting
+	MaxAbandonAfterDays = 365
+)
+
+// AbandonWarningLeadTimes are how long before abandonment the team is warned, earliest first
+var AbandonWarningLeadTimes = []time.Duration{7 * 24 * time.Hour, 24 * time.Hour}
+
+var (
+	ErrNotAbandoned        = errors.New("only abandoned proposals can be reopened")
+	ErrNotAwaitingRevision = errors.New("the proposal is not awaiting revision")
+)
+
+// AbandonmentPolicy is a university's auto-close rule: a proposal awaiting revision is abandoned
+// after AbandonAfterDays without activity from the team. 0 never abandons proposals.
+type AbandonmentPolicy struct {
+	UniversityID     uint `json:"university_id"`
+	AbandonAfterDays int  `json:"abandon_after_days"`
+}
+
+// UpdateAbandonmentPolicyRequest sets after how many inactive days proposals are abandoned
+type UpdateAbandonmentPolicyRequest struct {
+	AbandonAfterDays *int `json:"abandon_after_days" binding:"required,min=0" example:"30"`
+}
+
+// RevisionActivity is what the team did since a proposal's last revision request
+type RevisionActivity struct {
+	ProposalID          uint
+	AbandonAfterDays    int
+	AbandonWarnings     int
+	AbandonWarnedAt     *time.Time
+	RevisionRequestedAt time.Time
+	LastEditAt          *time.Time // latest version created or saved by the team
+	LastActivityAt      time.Time
+}
+
+// RevisionResponse tells whether the team has responded to the revision request and, when the
+// university abandons inactive proposals, when this one will be
+type RevisionResponse struct {
+	ProposalID          uint                 `json:"proposal_id"`
+	Status              enums.ProposalStatus `json:"status"`
+	RevisionRequestedAt time.Time            `json:"revision_requested_at"`
+	Responded           bool                 `json:"responded"` // the team edited the proposal since the request
+	LastActivityAt      time.Time            `json:"last_activity_at"`
+	AbandonAfterDays    int                  `json:"abandon_after_days"`
+	AbandonsAt          *time.Time           `json:"abandons_at,omitempty"` // null when the rule is off or the proposal is abandoned
+	AbandonedAt         *time.Time           `json:"abandoned_at,omitempty"`
+	ReopenedAt          *time.Time           `json:"reopened_at,omitempty"`
+}
+
+// deadline is when the proposal is abandoned without further activity
+func (a *RevisionActivity) deadline() time.Time {
+	return a.LastActivityAt.Add(time.Duration(a.AbandonAfterDays) * 24 * time.Hour)
+}
+
+// warningsSent counts the warnings sent since the last activity; activity starts the count over
+func (a *RevisionActivity) warningsSent() int {
+	if a.AbandonWarnedAt == nil || a.AbandonWarnedAt.Before(a.LastActivityAt) {
+		return 0
+	}
+	return a.AbandonWarnings
+}
+
+// GetAbandonmentPolicy returns the university's abandonment policy
+func (s *Service) GetAbandonmentPolicy(universityID uint) (*AbandonmentPolicy, error) {
+	if universityID == 0 {
+		return nil, ErrNoUniversity
+	}
+	university, err := s.repo.GetUniversity(universityID)
+	if err != nil {
+		return nil, errors.New("university not found")
+	}
+	return &AbandonmentPolicy{UniversityID: university.ID, AbandonAfterDays: university.AbandonAfterDays}, nil
+}
+
+// UpdateAbandonmentPolicy sets the university's abandonment policy; the inactivity already accumulated counts
+func (s *Service) UpdateAbandonmentPolicy(universityID uint, req UpdateAbandonmentPolicyRequest) (*AbandonmentPolicy, error) {
+	if universityID == 0 {
+		return nil, ErrNoUniversity
+	}
+	if *req.AbandonAfterDays > MaxAbandonAfterDays {
+		return nil, fmt.Errorf("proposals can be kept open for at most %d days; use 0 to never abandon them", MaxAbandonAfterDays)
+	}
+	if _, err := s.repo.GetUniversity(universityID); err != nil {
+		return nil, errors.New("university not found")
+	}
+	if err := s.repo.SetAbandonAfterDays(universityID, *req.AbandonAfterDays); err != nil {
+		return nil, err
+	}
+	return &AbandonmentPolicy{UniversityID: universityID, AbandonAfterDays: *req.AbandonAfterDays}, nil
+}
+
+// GetRevisionResponse reports the team's response to the latest revision request
+func (s *Service) GetRevisionResponse(proposalID uint, userID uint, role enums.Role, departmentID uint) (*RevisionResponse, error) {
+	proposal, err := s.GetProposal(proposalID, userID, role, departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != enums.ProposalStatusRevisionRequired && proposal.Status != enums.ProposalStatusAbandoned {
+		return nil, ErrNotAwaitingRevision
+	}
+
+	activity, err := s.repo.GetRevisionActivity(proposal.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("proposal not found")
+		}
+		return nil, err
+	}
+
+	result := &RevisionResponse{
+		ProposalID:          proposal.ID,
+		Status:              proposal.Status,
+		RevisionRequestedAt: activity.RevisionRequestedAt,
+		Responded:           activity.LastEditAt != nil && activity.LastEditAt.After(activity.RevisionRequestedAt),
+		LastActivityAt:      activity.LastActivityAt,
+		AbandonAfterDays:    activity.AbandonAfterDays,
+		AbandonedAt:         proposal.AbandonedAt,
+		ReopenedAt:          proposal.ReopenedAt,
+	}
+	if proposal.Status == enums.ProposalStatusRevisionRequired && activity.AbandonAfterDays > 0 {
+		deadline := activity.deadline()
+		result.AbandonsAt = &deadline
+	}
+	return result, nil
+}
+
+// ProcessAbandonment warns teams whose proposal awaiting revision is about to be abandoned and
+// abandons the ones inactive for longer than their university allows (run by the scheduler)
+func (s *Service) ProcessAbandonment() {
+	candidates, err := s.repo.GetAbandonmentCandidates()
+	if err != nil {
+		log.Printf("failed to load proposals awaiting revision: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range candidates {
+		activity := &candidates[i]
+		deadline := activity.deadline()
+		remaining := deadline.Sub(now)
+
+		if remaining <= 0 {
+			s.abandon(activity, now)
+			continue
+		}
+
+		// Only the latest due warning is sent, so a short policy skips the earlier ones
+		due := 0
+		for n, lead := range AbandonWarningLeadTimes {
+			if remaining <= lead {
+				due = n + 1
+			}
+		}
+		if due <= activity.warningsSent() {
+			continue
+		}
+		if err := s.repo.SetAbandonWarnings(activity.ProposalID, due, now); err != nil {
+			log.Printf("failed to record abandonment warning for proposal %d: %v", activity.ProposalID, err)
+			continue
+		}
+
+		proposal, err := s.repo.GetByID(activity.ProposalID)
+		if err != nil || proposal.Team == nil {
+			log.Printf("failed to load proposal %d for its abandonment warning: %v", activity.ProposalID, err)
+			continue
+		}
+		s.bus.Publish(events.Event{
+			Name:       events.ProposalAbandonWarning,
+			EntityType: "proposal",
+			EntityID:   proposal.ID,
+			UserIDs:    acceptedMemberIDs(proposal.Team),
+			Data: map[string]interface{}{
+				"title":       latestTitle(proposal),
+				"abandons_at": deadline.Format(time.RFC3339),
+				"days_left":   int(math.Ceil(remaining.Hours() / 24)),
+			},
+		})
+	}
+}
+
+func (s *Service) abandon(activity *RevisionActivity, now time.Time) {
+	abandoned, err := s.repo.MarkAbandoned(activity.ProposalID, now)
+	if err != nil {
+		log.Printf("failed to abandon proposal %d: %v", activity.ProposalID, err)
+		return
+	}
+	if !abandoned {
+		return
+	}
+
+	// Candidates always belong to a team, see GetAbandonmentCandidates
+	proposal, err := s.repo.GetByID(activity.ProposalID)
+	if err != nil || proposal.Team == nil {
+		log.Printf("failed to load abandoned proposal %d: %v", activity.ProposalID, err)
+		return
+	}
+
+	// Admins are told so they can reopen it if the team asks
+	userIDs := acceptedMemberIDs(proposal.Team)
+	if proposal.AdvisorID != nil {
+		userIDs = append(userIDs, *proposal.AdvisorID)
+	}
+	adminIDs, err := s.repo.GetDepartmentAdminIDs(proposal.Team.DepartmentID)
+	if err != nil {
+		log.Printf("failed to load department admins for proposal %d: %v", proposal.ID, err)
+	}
+	userIDs = append(userIDs, adminIDs...)
+
+	s.bus.Publish(events.Event{
+		Name:       events.ProposalAbandoned,
+		EntityType: "proposal",
+		EntityID:   proposal.ID,
+		UserIDs:    userIDs,
+		Data: map[string]interface{}{
+			"title":            latestTitle(proposal),
+			"last_activity_at": activity.LastActivityAt.Format(time.RFC3339),
+			"inactive_days":    activity.AbandonAfterDays,
+		},
+	})
+}
+
+// ReopenProposal lets an admin put an abandoned proposal of their department back to awaiting
+// revision; the inactivity period starts over
+func (s *Service) ReopenProposal(proposalID uint, adminID uint, departmentID uint) (*domain.Proposal, error) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if proposal.Team == nil || proposal.Team.DepartmentID != departmentID {
+		return nil, errors.New("you do not have permission to view this proposal")
+	}
+
+	reopened, err := s.repo.Reopen(proposal.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !reopened {
+		return nil, ErrNotAbandoned
+	}
+
+	proposal, err = s.repo.GetByID(proposal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := acceptedMemberIDs(proposal.Team)
+	if proposal.AdvisorID != nil {
+		userIDs = append(userIDs, *proposal.AdvisorID)
+	}
+	s.bus.Publish(events.Event{
+		Name:       events.ProposalReopened,
+		EntityType: "proposal",
+		EntityID:   proposal.ID,
+		ActorID:    adminID,
+		UserIDs:    userIDs,
+		Data:       map[string]interface{}{"title": latestTitle(proposal)},
+	})
+	return proposal, nil
+}
@@ -0,0 +1,141 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/events"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrNoAdvisorsMatched = errors.New("no advisor matches the filters")
+
+// AdvisorMessageRequest is a message to the department's active advisors. Filters combine: an
+// advisor must match every filter that is set, and without filters every advisor receives it.
+type AdvisorMessageRequest struct {
+	Subject string `json:"subject" binding:"required,max=200" example:"Pending proposal reviews"`
+	Body    string `json:"body" binding:"required" example:"Please review the proposals waiting for your decision before Friday."`
+	// Overloaded keeps the advisors above capacity in the current cohort
+	Overloaded bool `json:"overloaded" example:"false"`
+	// PendingReviewDays keeps the advisors with a submitted proposal waiting longer than this many days for their decision
+	PendingReviewDays int  `json:"pending_review_days" binding:"min=0" example:"7"`
+	Email             bool `json:"email" example:"true"` // also send by email
+	// DryRun lists the recipients without sending
+	DryRun bool `json:"dry_run" example:"false"`
+}
+
+// AdvisorMessageRecipient is an advisor a message was, or would be, sent to
+type AdvisorMessageRecipient struct {
+	AdvisorID uint   `json:"advisor_id"`
+	Name      string `json:"name"`
+}
+
+// AdvisorMessageResult lists the recipients; MessageID is zero for a dry run
+type AdvisorMessageResult struct {
+	MessageID  uint                      `json:"message_id,omitempty"`
+	Sent       bool                      `json:"sent"`
+	Recipients []AdvisorMessageRecipient `json:"recipients"`
+}
+
+// MessageAdvisors sends the department head's message to the advisors matching its filters as a
+// notification, and by email when asked. The message and its recipients are kept.
+func (s *Service) MessageAdvisors(req AdvisorMessageRequest, senderID uint, departmentID uint) (*AdvisorMessageResult, error) {
+	if departmentID == 0 {
+		return nil, errors.New("only a department admin can message advisors")
+	}
+	subject := strings.TrimSpace(req.Subject)
+	body := strings.TrimSpace(req.Body)
+	if subject == "" || body == "" {
+		return nil, errors.New("subject and body are required")
+	}
+
+	recipients, err := s.matchAdvisors(req, departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, ErrNoAdvisorsMatched
+	}
+	result := &AdvisorMessageResult{Recipients: recipients}
+	if req.DryRun {
+		return result, nil
+	}
+
+	message := &domain.AdvisorMessage{
+		DepartmentID:      departmentID,
+		SenderID:          senderID,
+		Subject:           subject,
+		Body:              body,
+		Overloaded:        req.Overloaded,
+		PendingReviewDays: req.PendingReviewDays,
+		Email:             req.Email,
+		RecipientIDs:      make([]uint, 0, len(recipients)),
+	}
+	for _, r := range recipients {
+		message.RecipientIDs = append(message.RecipientIDs, r.AdvisorID)
+	}
+	if err := s.repo.CreateAdvisorMessage(message); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.AdvisorMessageSent,
+		EntityType: "advisor_message",
+		EntityID:   message.ID,
+		ActorID:    senderID,
+		UserIDs:    message.RecipientIDs,
+		Data: map[string]interface{}{
+			"subject": subject,
+			"body":    body,
+			"email":   req.Email,
+		},
+	})
+
+	result.MessageID = message.ID
+	result.Sent = true
+	return result, nil
+}
+
+// matchAdvisors returns the department's active advisors matching every filter of the request
+func (s *Service) matchAdvisors(req AdvisorMessageRequest, departmentID uint) ([]AdvisorMessageRecipient, error) {
+	advisors, err := s.repo.GetDepartmentAdvisors(departmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var overloaded map[uint]bool
+	if req.Overloaded {
+		_, _, loads, err := s.departmentLoads(departmentID)
+		if err != nil {
+			return nil, err
+		}
+		overloaded = make(map[uint]bool, len(loads))
+		for _, load := range loads {
+			overloaded[load.AdvisorID] = load.Overloaded
+		}
+	}
+
+	var pending map[uint]bool
+	if req.PendingReviewDays > 0 {
+		ids, err := s.repo.GetAdvisorsWithPendingReviews(departmentID, time.Now().AddDate(0, 0, -req.PendingReviewDays))
+		if err != nil {
+			return nil, err
+		}
+		pending = make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			pending[id] = true
+		}
+	}
+
+	recipients := []AdvisorMessageRecipient{}
+	for _, advisor := range advisors {
+		if overloaded != nil && !overloaded[advisor.ID] {
+			continue
+		}
+		if pending != nil && !pending[advisor.ID] {
+			continue
+		}
+		recipients = append(recipients, AdvisorMessageRecipient{AdvisorID: advisor.ID, Name: advisor.Name})
+	}
+	return recipients, nil
+}
@@ -0,0 +1,128 @@
+package proposals
+
+import (
+	"backend/internal/ai_checker"
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	// archiveMatchLimit is how many archived projects are attached to a proposal
+	archiveMatchLimit = 5
+	// archiveCandidates is how many matches are asked from the similarity index; most belong to
+	// other departments or to projects that are not archived and are filtered out
+	archiveCandidates = 50
+	// archiveMatchTimeout bounds the call to the similarity index
+	archiveMatchTimeout = 30 * time.Second
+)
+
+var ErrArchiveMatchesHidden = errors.New("archive matches are only shown to reviewers")
+
+// SimilarityIndex finds similar projects; implemented by the AI checker client
+type SimilarityIndex interface {
+	SimilarProjects(ctx context.Context, title, summary string, limit int) ([]ai_checker.SimilarProject, error)
+}
+
+// ArchiveComparison is the outcome of comparing a proposal with its department's archived projects.
+// CheckedAt is null until the first comparison succeeded.
+type ArchiveComparison struct {
+	ProposalID uint                          `json:"proposal_id"`
+	CheckedAt  *time.Time                    `json:"checked_at"`
+	Matches    []domain.ProposalArchiveMatch `json:"matches"`
+}
+
+// RegisterArchiveMatching compares every submitted proposal with the department's archived
+// projects in the background. Departments with AI features turned off, or without an AI service
+// configured, are skipped.
+func (s *Service) RegisterArchiveMatching(bus *events.Bus, index SimilarityIndex, settings *ai_checker.Settings) {
+	bus.Subscribe(func(e events.Event) {
+		s.matchArchive(e.EntityID, index, settings)
+	}, events.ProposalSubmitted)
+}
+
+func (s *Service) matchArchive(proposalID uint, index SimilarityIndex, settings *ai_checker.Settings) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil || proposal.Team == nil || len(proposal.Versions) == 0 {
+		return
+	}
+	departmentID := proposal.Team.DepartmentID
+	// Enabled also requires the AI service to be configured
+	if availability, err := settings.For(departmentID, 0); err != nil || !availability.Enabled {
+		return
+	}
+	// GetByID loads the versions latest first
+	version := proposal.Versions[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveMatchTimeout)
+	defer cancel()
+
+	results, err := index.SimilarProjects(ctx, version.Title, version.Abstract, archiveCandidates)
+	if err != nil {
+		// The previous matches stay until a submission is compared successfully
+		log.Printf("failed to compare proposal %d with the archive: %v", proposalID, err)
+		return
+	}
+
+	scores := make(map[uint]float64, len(results))
+	ids := make([]uint, 0, len(results))
+	for _, r := range results {
+		scores[r.ID] = r.Score
+		ids = append(ids, r.ID)
+	}
+	projects, err := s.repo.GetArchivedProjects(departmentID, ids)
+	if err != nil {
+		log.Printf("failed to load archived projects for proposal %d: %v", proposalID, err)
+		return
+	}
+
+	now := time.Now()
+	matches := make([]domain.ProposalArchiveMatch, 0, len(projects))
+	for i := range projects {
+		project := &projects[i]
+		// An archived project of the same proposal is not a match
+		if project.ProposalID == proposal.ID {
+			continue
+		}
+		matches = append(matches, domain.ProposalArchiveMatch{
+			ProposalID:        proposal.ID,
+			ProposalVersionID: version.ID,
+			ProjectID:         project.ID,
+			Title:             latestTitle(&project.Proposal),
+			AcademicYear:      project.Proposal.AcademicYear,
+			Score:             scores[project.ID],
+			CreatedAt:         now,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > archiveMatchLimit {
+		matches = matches[:archiveMatchLimit]
+	}
+
+	if err := s.repo.ReplaceArchiveMatches(proposal.ID, matches, now); err != nil {
+		log.Printf("failed to save archive matches for proposal %d: %v", proposalID, err)
+	}
+}
+
+// GetArchiveMatches returns the archived projects found similar to the proposal on its last
+// submission; students do not see them
+func (s *Service) GetArchiveMatches(proposalID uint, userID uint, role enums.Role, departmentID uint) (*ArchiveComparison, error) {
+	if role == enums.RoleStudent {
+		return nil, ErrArchiveMatchesHidden
+	}
+	proposal, err := s.GetProposal(proposalID, userID, role, departmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.repo.GetArchiveMatches(proposal.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveComparison{ProposalID: proposal.ID, CheckedAt: proposal.ArchiveCheckedAt, Matches: matches}, nil
+}
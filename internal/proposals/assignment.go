@@ -0,0 +1,174 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/events"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAcceptWindow is how long an advisor has to accept or decline an assignment unless
+	// the admin gives another deadline
+	DefaultAcceptWindow = 72 * time.Hour
+	// MaxAcceptWindowHours bounds the deadline an admin can give, two weeks
+	MaxAcceptWindowHours = 14 * 24
+	// AssignmentEscalationInterval is how often the scheduler looks for assignments left unanswered
+	AssignmentEscalationInterval = time.Hour
+)
+
+// Reasons an assignment is escalated to the department head
+const (
+	EscalationNoResponse = "no_response"
+	EscalationDeclined   = "declined"
+)
+
+var (
+	ErrNotAssignedAdvisor   = errors.New("only the assigned advisor can answer this assignment")
+	ErrAssignmentAnswered   = errors.New("the assignment has already been accepted")
+	ErrAssignmentReviewed   = errors.New("the proposal has already been reviewed; it can no longer be declined")
+	ErrAcceptWindowTooLong  = fmt.Errorf("advisors can be given at most %d hours to accept", MaxAcceptWindowHours)
+	ErrDeclineReasonMissing = errors.New("a reason is required to decline an assignment")
+	ErrAdvisorInvalid       = errors.New("the advisor must be an active advisor of the proposal's department")
+)
+
+// RespondAssignmentRequest is the advisor's answer to being assigned a proposal
+type RespondAssignmentRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=accept decline" example:"accept"`
+	Comment  string `json:"comment" binding:"max=1000" example:"Outside my area; Dr. Alemu works on computer vision"`
+}
+
+// acceptDeadline is when an advisor assigned now has to answer by
+func acceptDeadline(now time.Time, acceptWithinHours int) (time.Time, error) {
+	if acceptWithinHours < 0 {
+		return time.Time{}, errors.New("the acceptance window must be positive")
+	}
+	if acceptWithinHours > MaxAcceptWindowHours {
+		return time.Time{}, ErrAcceptWindowTooLong
+	}
+	if acceptWithinHours == 0 {
+		return now.Add(DefaultAcceptWindow), nil
+	}
+	return now.Add(time.Duration(acceptWithinHours) * time.Hour), nil
+}
+
+// RespondToAssignment records the assigned advisor's answer. Accepting stops the escalation;
+// declining hands the proposal back to the department head, with a suggested replacement, while
+// the advisor has not reviewed it yet.
+func (s *Service) RespondToAssignment(proposalID uint, req RespondAssignmentRequest, advisorID uint) (*domain.Proposal, error) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if proposal.AdvisorID == nil || *proposal.AdvisorID != advisorID {
+		return nil, ErrNotAssignedAdvisor
+	}
+	if proposal.AdvisorAcceptedAt != nil {
+		return nil, ErrAssignmentAnswered
+	}
+
+	if req.Decision == "accept" {
+		if _, err := s.repo.AcceptAssignment(proposal.ID, advisorID, time.Now()); err != nil {
+			return nil, err
+		}
+		return s.repo.GetByID(proposal.ID)
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if comment == "" {
+		return nil, ErrDeclineReasonMissing
+	}
+	declined, err := s.repo.DeclineAssignment(proposal.ID, advisorID)
+	if err != nil {
+		return nil, err
+	}
+	if !declined {
+		return nil, ErrAssignmentReviewed
+	}
+	s.escalateAssignment(proposal, EscalationDeclined, comment)
+	return s.repo.GetByID(proposal.ID)
+}
+
+// EscalateUnansweredAssignments tells the department heads about advisors who let the acceptance
+// deadline pass, suggesting another advisor (run by the scheduler). The advisor keeps the
+// proposal until an admin reassigns it, and can still accept it.
+func (s *Service) EscalateUnansweredAssignments() {
+	now := time.Now()
+	overdue, err := s.repo.GetOverdueAssignments(now)
+	if err != nil {
+		log.Printf("failed to load unanswered advisor assignments: %v", err)
+		return
+	}
+
+	for i := range overdue {
+		proposal := &overdue[i]
+		escalated, err := s.repo.MarkAssignmentEscalated(proposal.ID, *proposal.AdvisorID, now)
+		if err != nil {
+			log.Printf("failed to escalate the assignment of proposal %d: %v", proposal.ID, err)
+			continue
+		}
+		if escalated {
+			s.escalateAssignment(proposal, EscalationNoResponse, "")
+		}
+	}
+}
+
+// escalateAssignment notifies the department admins that the proposal needs another advisor,
+// naming the least-loaded advisor with room, no conflict of interest with the team and who is not
+// the proposal's co-reviewer
+func (s *Service) escalateAssignment(proposal *domain.Proposal, reason string, comment string) {
+	if proposal.Team == nil || proposal.AdvisorID == nil {
+		return
+	}
+	adminIDs, err := s.repo.GetDepartmentAdminIDs(proposal.Team.DepartmentID)
+	if err != nil {
+		log.Printf("failed to load department admins for proposal %d: %v", proposal.ID, err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"title":      latestTitle(proposal),
+		"reason":     reason,
+		"advisor_id": *proposal.AdvisorID,
+	}
+	if proposal.Advisor != nil {
+		data["advisor_name"] = proposal.Advisor.Name
+	} else if advisor, err := s.repo.GetAdvisor(*proposal.AdvisorID); err == nil {
+		data["advisor_name"] = advisor.Name
+	}
+	if comment != "" {
+		data["comment"] = comment
+	}
+	if suggested, err := s.suggestAdvisor(proposal); err != nil {
+		log.Printf("failed to suggest an advisor for proposal %d: %v", proposal.ID, err)
+	} else if suggested != nil {
+		data["suggested_advisor_id"] = suggested.AdvisorID
+		data["suggested_advisor_name"] = suggested.Name
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.AssignmentEscalated,
+		EntityType: "proposal",
+		EntityID:   proposal.ID,
+		UserIDs:    adminIDs,
+		Data:       data,
+	})
+}
+
+// suggestAdvisor picks a replacement the way rebalancing does; nil when no advisor has room
+func (s *Service) suggestAdvisor(proposal *domain.Proposal) (*AdvisorLoad, error) {
+	_, _, loads, err := s.departmentLoads(proposal.Team.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	projected := make(map[uint]int64, len(loads))
+	for _, l := range loads {
+		projected[l.AdvisorID] = l.Load
+	}
+	return leastLoadedWithRoom(loads, projected, *proposal.AdvisorID, func(advisorID uint) bool {
+		return s.checkConflict(proposal, advisorID) == nil && checkNotSecondReviewer(proposal, advisorID) == nil
+	}), nil
+}
@@ -0,0 +1,144 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/uow"
+	"errors"
+	"time"
+)
+
+// AutosaveMinInterval is the shortest time between two stored autosaves of a draft.
+// Editors debounce on their side; saves arriving faster are not written and get the last save back.
+const AutosaveMinInterval = 2 * time.Second
+
+var (
+	ErrAutosaveNotDraft      = errors.New("only drafts can be autosaved; revisions are saved as a new version")
+	ErrAutosaveTeamForbidden = errors.New("a draft can only be moved to a team you are an accepted member of")
+)
+
+// AutosaveDraftRequest holds the fields that changed since the last save; omitted fields are kept
+type AutosaveDraftRequest struct {
+	TeamID           *uint   `json:"team_id"`
+	Title            *string `json:"title"`
+	Abstract         *string `json:"abstract"`
+	ProblemStatement *string `json:"problem_statement"`
+	Objectives       *string `json:"objectives"`
+	Methodology      *string `json:"methodology"`
+	Timeline         *string `json:"expected_timeline"`
+	ExpectedOutcomes *string `json:"expected_outcomes"`
+}
+
+// AutosaveResult tells the editor whether anything was written and when the draft was last saved
+type AutosaveResult struct {
+	ProposalID  uint       `json:"proposal_id"`
+	VersionID   uint       `json:"version_id"`
+	Saved       bool       `json:"saved"`     // false when nothing changed or the save was debounced
+	Debounced   bool       `json:"debounced"` // the draft was saved moments ago; send the latest content again
+	LastSavedAt *time.Time `json:"last_saved_at"`
+}
+
+// AutosaveDraft applies a partial update to the draft's current version in place; no version
+// is created. Saves that change nothing, or arrive within AutosaveMinInterval of the last one,
+// are not written. A new team must be one the user has accepted to join.
+func (s *Service) AutosaveDraft(proposalID uint, req AutosaveDraftRequest, userID uint) (*AutosaveResult, error) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if !canEditDraft(proposal, userID) {
+		return nil, errors.New("you do not have permission to edit this proposal")
+	}
+	if proposal.Status != enums.ProposalStatusDraft {
+		return nil, ErrAutosaveNotDraft
+	}
+
+	version, err := s.repo.GetLatestVersion(proposal.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := &AutosaveResult{ProposalID: proposal.ID, VersionID: version.ID, LastSavedAt: version.LastSavedAt}
+
+	changed := false
+	for _, field := range []struct {
+		value  *string
+		target *string
+	}{
+		{req.Title, &version.Title},
+		{req.Abstract, &version.Abstract},
+		{req.ProblemStatement, &version.ProblemStatement},
+		{req.Objectives, &version.Objectives},
+		{req.Methodology, &version.Methodology},
+		{req.Timeline, &version.ExpectedTimeline},
+		{req.ExpectedOutcomes, &version.ExpectedOutcomes},
+	} {
+		if field.value != nil && *field.value != *field.target {
+			*field.target = *field.value
+			changed = true
+		}
+	}
+	teamChanged := req.TeamID != nil && (proposal.TeamID == nil || *proposal.TeamID != *req.TeamID)
+	if !changed && !teamChanged {
+		return result, nil
+	}
+
+	if version.LastSavedAt != nil && time.Since(*version.LastSavedAt) < AutosaveMinInterval {
+		result.Debounced = true
+		return result, nil
+	}
+	if teamChanged {
+		if err := s.checkAcceptedMember(*req.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	version.LastSavedAt = &now
+	err = s.work.Do(func(tx *uow.Tx) error {
+		if err := tx.DB().Omit("Creator").Save(version).Error; err != nil {
+			return err
+		}
+		if teamChanged {
+			proposal.TeamID = req.TeamID
+			return s.repo.WithTx(tx.DB()).Update(proposal)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, mapConstraintError(err, ErrTeamHasActiveProposal)
+	}
+
+	result.Saved = true
+	result.LastSavedAt = &now
+	return result, nil
+}
+
+// checkAcceptedMember refuses a team the user has not accepted to join
+func (s *Service) checkAcceptedMember(teamID uint, userID uint) error {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return ErrAutosaveTeamForbidden
+	}
+	for _, m := range team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			return nil
+		}
+	}
+	return ErrAutosaveTeamForbidden
+}
+
+// canEditDraft allows the proposal's creator and the accepted members of its team
+func canEditDraft(proposal *domain.Proposal, userID uint) bool {
+	if proposal.CreatedBy == userID {
+		return true
+	}
+	if proposal.Team == nil {
+		return false
+	}
+	for _, m := range proposal.Team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			return true
+		}
+	}
+	return false
+}
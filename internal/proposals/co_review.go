@@ -0,0 +1,104 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSecondReviewerInvalid   = errors.New("the co-reviewer must be an active advisor of the proposal's department")
+	ErrSecondReviewerIsAdvisor = errors.New("the assigned advisor cannot also be the co-reviewer")
+	ErrAdvisorIsSecondReviewer = errors.New("the co-reviewer cannot also be the assigned advisor; remove or replace the co-reviewer first")
+	ErrCoReviewClosed          = errors.New("the proposal has already been decided")
+	ErrNoSecondReviewer        = errors.New("the proposal has no co-reviewer")
+	ErrSecondReviewPending     = errors.New("the proposal is awaiting the co-reviewer's decision; assign another co-reviewer instead")
+)
+
+// AssignSecondReviewerRequest names the advisor whose approval the proposal also needs
+type AssignSecondReviewerRequest struct {
+	ReviewerID uint `json:"reviewer_id" binding:"required" example:"12"`
+}
+
+// coReviewOpenStatuses are the states in which the department head can change the co-reviewer
+var coReviewOpenStatuses = map[enums.ProposalStatus]bool{
+	enums.ProposalStatusSubmitted:            true,
+	enums.ProposalStatusUnderReview:          true,
+	enums.ProposalStatusRevisionRequired:     true,
+	enums.ProposalStatusAwaitingSecondReview: true,
+}
+
+// checkNotSecondReviewer refuses to make the proposal's co-reviewer its advisor, which would let
+// one person give both approvals
+func checkNotSecondReviewer(proposal *domain.Proposal, advisorID uint) error {
+	if proposal.SecondReviewerID != nil && *proposal.SecondReviewerID == advisorID {
+		return ErrAdvisorIsSecondReviewer
+	}
+	return nil
+}
+
+// AssignSecondReviewer makes an advisor of the department the proposal's co-reviewer. Once set,
+// the assigned advisor's approval moves the proposal to awaiting_second_review, and only the
+// co-reviewer's approval makes it approved. Assigning again replaces the co-reviewer, including
+// while their decision is pending.
+func (s *Service) AssignSecondReviewer(proposalID uint, req AssignSecondReviewerRequest, departmentID uint) (*domain.Proposal, error) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil || proposal.Team == nil || proposal.Team.DepartmentID != departmentID {
+		return nil, errors.New("proposal not found")
+	}
+	if !coReviewOpenStatuses[proposal.Status] {
+		return nil, ErrCoReviewClosed
+	}
+	if proposal.AdvisorID != nil && *proposal.AdvisorID == req.ReviewerID {
+		return nil, ErrSecondReviewerIsAdvisor
+	}
+	reviewer, err := s.repo.GetAdvisor(req.ReviewerID)
+	if err != nil || !reviewer.IsActive || reviewer.DepartmentID != departmentID {
+		return nil, ErrSecondReviewerInvalid
+	}
+	if err := s.checkConflict(proposal, reviewer.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.repo.SetSecondReviewer(proposal.ID, &reviewer.ID, &now); err != nil {
+		return nil, err
+	}
+
+	name := events.SecondReviewerAssigned
+	data := map[string]interface{}{"title": latestTitle(proposal)}
+	if proposal.Status == enums.ProposalStatusAwaitingSecondReview {
+		// The advisor already approved; the new co-reviewer decides next
+		name = events.SecondReviewRequested
+		data["second_reviewer_id"] = reviewer.ID
+	}
+	s.bus.Publish(events.Event{
+		Name:       name,
+		EntityType: "proposal",
+		EntityID:   proposal.ID,
+		UserIDs:    []uint{reviewer.ID},
+		Data:       data,
+	})
+
+	return s.repo.GetByID(proposal.ID)
+}
+
+// RemoveSecondReviewer lets the assigned advisor's approval alone approve the proposal again
+func (s *Service) RemoveSecondReviewer(proposalID uint, departmentID uint) error {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil || proposal.Team == nil || proposal.Team.DepartmentID != departmentID {
+		return errors.New("proposal not found")
+	}
+	if proposal.SecondReviewerID == nil {
+		return ErrNoSecondReviewer
+	}
+	if proposal.Status == enums.ProposalStatusAwaitingSecondReview {
+		return ErrSecondReviewPending
+	}
+	if !coReviewOpenStatuses[proposal.Status] {
+		return ErrCoReviewClosed
+	}
+	return s.repo.SetSecondReviewer(proposal.ID, nil, nil)
+}
@@ -0,0 +1,80 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"errors"
+)
+
+var (
+	ErrNotTeamLeader      = errors.New("only the team leader can continue a project")
+	ErrProjectInOtherDept = errors.New("the project belongs to another department")
+	ErrProjectContinued   = errors.New("the project is already being continued by another proposal")
+)
+
+// ContinueProjectRequest names the team of the new cohort taking over the project
+type ContinueProjectRequest struct {
+	TeamID uint `json:"team_id" binding:"required" example:"12"`
+}
+
+// ContinueProject starts a draft proposal for the team that continues an earlier cohort's project.
+// The draft starts from the content of the project's approved version and stays linked to the
+// project; once approved, its project shows the lineage and gets the earlier documentation.
+func (s *Service) ContinueProject(projectID uint, req ContinueProjectRequest, userID uint) (*domain.Proposal, error) {
+	project, err := s.repo.GetProject(projectID)
+	if err != nil {
+		return nil, errors.New("project not found")
+	}
+	team, err := s.repo.GetTeam(req.TeamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+
+	if !isTeamLeader(team, userID) {
+		return nil, ErrNotTeamLeader
+	}
+	if team.DepartmentID != project.DepartmentID {
+		return nil, ErrProjectInOtherDept
+	}
+	// A team still holding the project's own proposal is refused by CreateDraft as having an active proposal
+	continued, err := s.repo.HasActiveContinuation(project.ID)
+	if err != nil {
+		return nil, err
+	}
+	if continued {
+		return nil, ErrProjectContinued
+	}
+
+	source := continuationSource(&project.Proposal)
+	input := ProposalInput{
+		TeamID:             &team.ID,
+		ContinuesProjectID: &project.ID,
+	}
+	if source != nil {
+		input.Title = source.Title
+		input.Abstract = source.Abstract
+		input.ProblemStatement = source.ProblemStatement
+		input.Objectives = source.Objectives
+		input.Methodology = source.Methodology
+		input.Timeline = source.ExpectedTimeline
+		input.ExpectedOutcomes = source.ExpectedOutcomes
+	}
+
+	draft, err := s.CreateDraft(input, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(draft.ID)
+}
+
+// continuationSource is the approved version of the proposal, or its latest when none is marked
+func continuationSource(proposal *domain.Proposal) *domain.ProposalVersion {
+	for i := range proposal.Versions {
+		if proposal.Versions[i].IsApproved {
+			return &proposal.Versions[i]
+		}
+	}
+	if len(proposal.Versions) > 0 {
+		return &proposal.Versions[0]
+	}
+	return nil
+}
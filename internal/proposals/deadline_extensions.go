@@ -0,0 +1,226 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"log"
+	"time"
+)
+
+// MaxDeadlineExtension is the longest a team can ask to submit after the window closes
+const MaxDeadlineExtension = 30 * 24 * time.Hour
+
+var (
+	ErrExtensionNotFound    = errors.New("deadline extension request not found")
+	ErrExtensionForbidden   = errors.New("you do not have permission to view this team's extension requests")
+	ErrExtensionNotLeader   = errors.New("only the team leader can request a deadline extension")
+	ErrExtensionPending     = errors.New("the team already has a pending extension request")
+	ErrExtensionDecided     = errors.New("the extension request has already been decided")
+	ErrExtensionNoWindow    = errors.New("no submission window is set for the team's department")
+	ErrExtensionNotRequired = errors.New("the team has already submitted a proposal")
+)
+
+// DeadlineExtensionRequest asks for more time to make the team's first submission
+type DeadlineExtensionRequest struct {
+	RequestedUntil time.Time `json:"requested_until" binding:"required" example:"2026-03-22T23:59:00Z"`
+	Justification  string    `json:"justification" binding:"required,min=20,max=2000"`
+}
+
+// DecideDeadlineExtensionRequest approves or denies an extension request
+type DecideDeadlineExtensionRequest struct {
+	Approve       bool       `json:"approve"`
+	ExtendedUntil *time.Time `json:"extended_until"`             // defaults to the requested date when approving
+	Comment       string     `json:"comment" binding:"max=2000"` // required when denying
+}
+
+// RequestDeadlineExtension records the leader's request for the department admins to decide
+func (s *Service) RequestDeadlineExtension(teamID, userID uint, req DeadlineExtensionRequest) (*domain.DeadlineExtension, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if !isTeamLeader(team, userID) {
+		return nil, ErrExtensionNotLeader
+	}
+	if team.IsArchived {
+		return nil, errors.New("the team's cohort has been archived")
+	}
+
+	window, err := s.repo.GetSubmissionWindow(team.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, ErrExtensionNoWindow
+	}
+	submitted, err := s.repo.TeamHasSubmission(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if submitted {
+		return nil, ErrExtensionNotRequired
+	}
+
+	if !req.RequestedUntil.After(window.ClosesAt) || !req.RequestedUntil.After(time.Now()) {
+		return nil, errors.New("the requested date must be after the window closes and in the future")
+	}
+	if req.RequestedUntil.Sub(window.ClosesAt) > MaxDeadlineExtension {
+		return nil, errors.New("an extension can be at most 30 days past the window's closing date")
+	}
+
+	pending, err := s.repo.HasPendingDeadlineExtension(teamID, window.AcademicYear)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrExtensionPending
+	}
+
+	ext := &domain.DeadlineExtension{
+		TeamID:         teamID,
+		DepartmentID:   team.DepartmentID,
+		AcademicYear:   window.AcademicYear,
+		RequestedBy:    userID,
+		Justification:  req.Justification,
+		RequestedUntil: req.RequestedUntil,
+		Status:         enums.DeadlineExtensionPending,
+	}
+	if err := s.repo.CreateDeadlineExtension(ext); err != nil {
+		return nil, err
+	}
+
+	adminIDs, _ := s.repo.GetDepartmentAdminIDs(team.DepartmentID)
+	s.bus.Publish(events.Event{
+		Name:       events.ExtensionRequested,
+		EntityType: "team",
+		EntityID:   teamID,
+		ActorID:    userID,
+		UserIDs:    adminIDs,
+		Data: map[string]interface{}{
+			"extension_id":    ext.ID,
+			"team_name":       team.Name,
+			"window_closes":   window.ClosesAt.Format(time.RFC3339),
+			"requested_until": ext.RequestedUntil.Format(time.RFC3339),
+			"justification":   ext.Justification,
+		},
+	})
+	return ext, nil
+}
+
+// GetTeamDeadlineExtensions lists the team's requests to its accepted members, advisor and department admins
+func (s *Service) GetTeamDeadlineExtensions(teamID, userID uint, role enums.Role, departmentID uint) ([]domain.DeadlineExtension, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+
+	allowed := role == enums.RoleAdmin && team.DepartmentID == departmentID
+	if team.AdvisorID != nil && *team.AdvisorID == userID {
+		allowed = true
+	}
+	for _, m := range team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, ErrExtensionForbidden
+	}
+	return s.repo.GetTeamDeadlineExtensions(teamID)
+}
+
+// GetDepartmentDeadlineExtensions lists the department's extension requests, optionally by status
+func (s *Service) GetDepartmentDeadlineExtensions(departmentID uint, status string) ([]domain.DeadlineExtension, error) {
+	return s.repo.GetDepartmentDeadlineExtensions(departmentID, status)
+}
+
+// DecideDeadlineExtension approves or denies a pending request of the admin's department. An
+// approval lets the team make its first submission until the extended date. The decision is audited.
+func (s *Service) DecideDeadlineExtension(id, adminID, departmentID uint, req DecideDeadlineExtensionRequest, meta audit.RequestMeta) (*domain.DeadlineExtension, error) {
+	ext, err := s.repo.GetDeadlineExtension(id)
+	if err != nil || ext.DepartmentID != departmentID {
+		return nil, ErrExtensionNotFound
+	}
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+	if ext.Status != enums.DeadlineExtensionPending {
+		return nil, ErrExtensionDecided
+	}
+
+	now := time.Now()
+	name := events.ExtensionDenied
+	ext.Status = enums.DeadlineExtensionDenied
+	if req.Approve {
+		until := ext.RequestedUntil
+		if req.ExtendedUntil != nil {
+			until = *req.ExtendedUntil
+		}
+		if !until.After(now) {
+			return nil, errors.New("the extended deadline must be in the future")
+		}
+		if window, err := s.repo.GetSubmissionWindow(departmentID); err == nil && window != nil && until.Sub(window.ClosesAt) > MaxDeadlineExtension {
+			return nil, errors.New("an extension can be at most 30 days past the window's closing date")
+		}
+		name = events.ExtensionApproved
+		ext.Status = enums.DeadlineExtensionApproved
+		ext.ExtendedUntil = &until
+	} else if req.Comment == "" {
+		return nil, errors.New("a comment is required when denying an extension")
+	}
+	ext.ReviewedBy = &adminID
+	ext.ReviewComment = req.Comment
+	ext.ReviewedAt = &now
+
+	decided, err := s.repo.DecideDeadlineExtension(ext)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, ErrExtensionDecided
+	}
+
+	err = s.auditLogger.LogAction("deadline_extension", ext.ID, "deadline_extension_"+string(ext.Status), &admin.ID, string(admin.Role), admin.Email,
+		map[string]interface{}{"status": enums.DeadlineExtensionPending, "requested_until": ext.RequestedUntil},
+		map[string]interface{}{"status": ext.Status, "extended_until": ext.ExtendedUntil, "comment": ext.ReviewComment},
+		meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit the decision on deadline extension %d: %v", ext.ID, err)
+	}
+
+	data := map[string]interface{}{
+		"extension_id":    ext.ID,
+		"requested_until": ext.RequestedUntil.Format(time.RFC3339),
+		"comment":         ext.ReviewComment,
+	}
+	var memberIDs []uint
+	if ext.Team != nil {
+		data["team_name"] = ext.Team.Name
+		memberIDs = acceptedMemberIDs(ext.Team)
+	}
+	if ext.ExtendedUntil != nil {
+		data["extended_until"] = ext.ExtendedUntil.Format(time.RFC3339)
+	}
+	s.bus.Publish(events.Event{
+		Name:       name,
+		EntityType: "team",
+		EntityID:   ext.TeamID,
+		ActorID:    adminID,
+		UserIDs:    memberIDs,
+		Data:       data,
+	})
+	return ext, nil
+}
+
+func isTeamLeader(team *domain.Team, userID uint) bool {
+	for _, m := range team.Members {
+		if m.UserID == userID && m.Role == "leader" {
+			return true
+		}
+	}
+	return false
+}
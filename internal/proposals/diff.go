@@ -0,0 +1,138 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"fmt"
+	"strings"
+)
+
+// SectionChange is how one section of a proposal changed between two versions
+type SectionChange struct {
+	Section     string `json:"section"`
+	Change      string `json:"change"` // "added", "removed" or "changed"
+	WordsBefore int    `json:"words_before"`
+	WordsAfter  int    `json:"words_after"`
+	WordDelta   int    `json:"word_delta"`
+}
+
+// VersionDiff lists the sections that differ between two versions of a proposal
+type VersionDiff struct {
+	FromVersion int             `json:"from_version"`
+	ToVersion   int             `json:"to_version"`
+	Sections    []SectionChange `json:"sections"`
+	WordDelta   int             `json:"word_delta"`
+	FileChanged bool            `json:"file_changed"`
+}
+
+// versionSections are the compared text sections, in proposal order
+var versionSections = []struct {
+	Key  string
+	Name string
+	Text func(v *domain.ProposalVersion) string
+}{
+	{"title", "Title", func(v *domain.ProposalVersion) string { return v.Title }},
+	{"abstract", "Abstract", func(v *domain.ProposalVersion) string { return v.Abstract }},
+	{"problem_statement", "Problem statement", func(v *domain.ProposalVersion) string { return v.ProblemStatement }},
+	{"objectives", "Objectives", func(v *domain.ProposalVersion) string { return v.Objectives }},
+	{"methodology", "Methodology", func(v *domain.ProposalVersion) string { return v.Methodology }},
+	{"expected_timeline", "Expected timeline", func(v *domain.ProposalVersion) string { return v.ExpectedTimeline }},
+	{"expected_outcomes", "Expected outcomes", func(v *domain.ProposalVersion) string { return v.ExpectedOutcomes }},
+}
+
+// DiffVersions compares two versions section by section. Whitespace-only edits are not changes.
+func DiffVersions(from, to *domain.ProposalVersion) *VersionDiff {
+	diff := &VersionDiff{
+		FromVersion: from.VersionNumber,
+		ToVersion:   to.VersionNumber,
+		Sections:    []SectionChange{},
+		FileChanged: from.FileHash != to.FileHash,
+	}
+
+	for _, section := range versionSections {
+		before := strings.Fields(section.Text(from))
+		after := strings.Fields(section.Text(to))
+		if strings.Join(before, " ") == strings.Join(after, " ") {
+			continue
+		}
+
+		change := "changed"
+		switch {
+		case len(before) == 0:
+			change = "added"
+		case len(after) == 0:
+			change = "removed"
+		}
+		diff.Sections = append(diff.Sections, SectionChange{
+			Section:     section.Name,
+			Change:      change,
+			WordsBefore: len(before),
+			WordsAfter:  len(after),
+			WordDelta:   len(after) - len(before),
+		})
+		diff.WordDelta += len(after) - len(before)
+	}
+	return diff
+}
+
+// Summary describes the diff in one sentence, e.g.
+// "2 sections changed: Methodology (+120 words), Objectives (-15 words); +105 words overall."
+func (d *VersionDiff) Summary() string {
+	if len(d.Sections) == 0 {
+		if d.FileChanged {
+			return "Only the attached file changed."
+		}
+		return "No changes since the reviewed version."
+	}
+
+	parts := make([]string, 0, len(d.Sections))
+	for _, s := range d.Sections {
+		switch {
+		case s.Change != "changed":
+			parts = append(parts, fmt.Sprintf("%s (%s, %s)", s.Section, s.Change, signedWords(s.WordDelta)))
+		case s.WordDelta == 0:
+			parts = append(parts, s.Section+" (reworded)")
+		default:
+			parts = append(parts, fmt.Sprintf("%s (%s)", s.Section, signedWords(s.WordDelta)))
+		}
+	}
+
+	noun := "sections"
+	if len(d.Sections) == 1 {
+		noun = "section"
+	}
+	summary := fmt.Sprintf("%d %s changed: %s; %s overall.", len(d.Sections), noun, strings.Join(parts, ", "), signedWords(d.WordDelta))
+	if d.FileChanged {
+		summary += " The attached file was replaced."
+	}
+	return summary
+}
+
+func signedWords(n int) string {
+	word := "words"
+	if n == 1 || n == -1 {
+		word = "word"
+	}
+	return fmt.Sprintf("%+d %s", n, word)
+}
+
+// resubmissionDiff compares the submitted version with the one the advisor last reviewed.
+// Versions are ordered newest first, as loaded by GetByID.
+func (s *Service) resubmissionDiff(proposal *domain.Proposal) *VersionDiff {
+	if len(proposal.Versions) == 0 {
+		return nil
+	}
+	latest := &proposal.Versions[0]
+
+	reviewedID, err := s.repo.GetLastReviewedVersionID(proposal.ID)
+	if err == nil {
+		for i := range proposal.Versions {
+			if proposal.Versions[i].ID == reviewedID {
+				return DiffVersions(&proposal.Versions[i], latest)
+			}
+		}
+	}
+	if len(proposal.Versions) < 2 {
+		return nil
+	}
+	return DiffVersions(&proposal.Versions[1], latest)
+}
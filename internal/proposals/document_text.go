@@ -0,0 +1,81 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DocumentTextInterval is how often PDFs without extracted text are read: files uploaded before
+	// extraction existed, released from quarantine, or whose extraction on upload failed
+	DocumentTextInterval = 30 * time.Minute
+	// MaxDocumentTextChars caps the stored text of one document
+	MaxDocumentTextChars = 500000
+
+	// documentTextBatch bounds how many documents one run reads
+	documentTextBatch = 50
+)
+
+// ExtractVersionText stores the text of the version's PDF for full-text search and AI analysis.
+// Other formats, and quarantined files until they pass the scan, have their stale text removed. A PDF
+// whose text cannot be read is stored without text, so it is not read again until replaced.
+func (s *Service) ExtractVersionText(version *domain.ProposalVersion) error {
+	if version.FileURL == nil || *version.FileURL == "" || !isPDFVersion(version) || version.ScanStatus.Quarantined() {
+		return s.repo.DeleteVersionText(version.ID)
+	}
+
+	text, err := s.uploader.ReadPDFText(*version.FileURL)
+	if err != nil {
+		log.Printf("failed to read the text of proposal version %d (%s): %v", version.ID, *version.FileURL, err)
+		text = ""
+	}
+	text = truncateText(strings.ToValidUTF8(strings.ReplaceAll(text, "\x00", ""), ""), MaxDocumentTextChars)
+
+	return s.repo.SaveVersionText(&domain.ProposalVersionText{
+		VersionID:   version.ID,
+		ProposalID:  version.ProposalID,
+		FileHash:    version.FileHash,
+		Content:     text,
+		WordCount:   len(strings.Fields(text)),
+		ExtractedAt: time.Now(),
+	})
+}
+
+// ExtractPendingDocumentTexts reads the PDFs that have no text for their current file yet
+func (s *Service) ExtractPendingDocumentTexts() {
+	versions, err := s.repo.GetVersionsWithoutText(documentTextBatch)
+	if err != nil {
+		log.Printf("failed to load proposal documents to extract: %v", err)
+		return
+	}
+
+	extracted := 0
+	for i := range versions {
+		if err := s.ExtractVersionText(&versions[i]); err != nil {
+			log.Printf("failed to store the text of proposal version %d: %v", versions[i].ID, err)
+			continue
+		}
+		extracted++
+	}
+	if extracted > 0 {
+		log.Printf("extracted the text of %d proposal documents", extracted)
+	}
+}
+
+func isPDFVersion(version *domain.ProposalVersion) bool {
+	if version.DetectedMIME != "" {
+		return version.DetectedMIME == "application/pdf"
+	}
+	return strings.HasSuffix(strings.ToLower(*version.FileURL), ".pdf")
+}
+
+// truncateText cuts s to at most max characters
+func truncateText(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,67 @@
+package proposals
+
+import (
+	"backend/pkg/enums"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNoDraft        = errors.New("the team has no draft proposal")
+	ErrDraftForbidden = errors.New("only members of the team can open its draft")
+)
+
+// TeamDraft is a team's draft with what is still missing before it can be submitted, so an
+// editor can resume it instead of creating a second proposal
+type TeamDraft struct {
+	Proposal ProposalResponse `json:"proposal"`
+	// Missing lists the empty sections by key, and "file" when no document is attached
+	Missing    []string `json:"missing"`
+	Incomplete bool     `json:"incomplete"`
+}
+
+// GetTeamDraft returns the team's draft for its accepted members
+func (s *Service) GetTeamDraft(teamID, userID uint) (*TeamDraft, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	member := false
+	for _, m := range team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			member = true
+		}
+	}
+	if !member {
+		return nil, ErrDraftForbidden
+	}
+
+	proposal, err := s.repo.GetTeamDraft(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, ErrNoDraft
+	}
+
+	draft := &TeamDraft{Proposal: toProposalResponse(proposal), Missing: []string{}}
+	if len(proposal.Versions) == 0 {
+		// A draft without a version predates atomic creation; everything is still to be written
+		for _, section := range versionSections {
+			draft.Missing = append(draft.Missing, section.Key)
+		}
+		draft.Missing = append(draft.Missing, "file")
+	} else {
+		latest := &proposal.Versions[0]
+		for _, section := range versionSections {
+			if strings.TrimSpace(section.Text(latest)) == "" {
+				draft.Missing = append(draft.Missing, section.Key)
+			}
+		}
+		if latest.FileURL == nil || *latest.FileURL == "" {
+			draft.Missing = append(draft.Missing, "file")
+		}
+	}
+	draft.Incomplete = len(draft.Missing) > 0
+	return draft, nil
+}
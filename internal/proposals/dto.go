@@ -0,0 +1,199 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/internal/users"
+	"backend/pkg/enums"
+	"time"
+)
+
+// ProposalResponse is the API view of a proposal. Versions are newest first, as loaded.
+type ProposalResponse struct {
+	ID           uint                 `json:"id"`
+	TeamID       *uint                `json:"team_id"`
+	AdvisorID    *uint                `json:"advisor_id"`
+	Status       enums.ProposalStatus `json:"status"`
+	CreatedBy    uint                 `json:"created_by"`
+	AcademicYear string               `json:"academic_year"`
+	IsArchived   bool                 `json:"is_archived"`
+	ArchivedAt   *time.Time           `json:"archived_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	Team         *ProposalTeam        `json:"team,omitempty"`
+	Advisor      *users.UserSummary   `json:"advisor,omitempty"`
+	Versions     []VersionResponse    `json:"versions"`
+	// StudentsHidden is set when blind review withholds the team's identities from the advisor
+	StudentsHidden bool `json:"students_hidden,omitempty"`
+	// ContinuesProjectID is the earlier cohort's project this proposal carries on
+	ContinuesProjectID *uint `json:"continues_project_id,omitempty"`
+	// SecondReviewerID is the co-reviewer whose approval the proposal also needs
+	SecondReviewerID *uint `json:"second_reviewer_id,omitempty"`
+}
+
+// ProposalTeam is the team behind a proposal with its members
+type ProposalTeam struct {
+	ID           uint                 `json:"id"`
+	Name         string               `json:"name"`
+	DepartmentID uint                 `json:"department_id"`
+	AcademicYear string               `json:"academic_year"`
+	Members      []ProposalTeamMember `json:"members"`
+}
+
+type ProposalTeamMember struct {
+	UserID uint               `json:"user_id"`
+	Role   string             `json:"role"` // leader or member
+	User   *users.UserSummary `json:"user,omitempty"`
+}
+
+// VersionResponse is one saved version of a proposal's content
+type VersionResponse struct {
+	ID               uint               `json:"id"`
+	ProposalID       uint               `json:"proposal_id"`
+	VersionNumber    int                `json:"version_number"`
+	Title            string             `json:"title"`
+	Abstract         string             `json:"abstract"`
+	ProblemStatement string             `json:"problem_statement"`
+	Objectives       string             `json:"objectives"`
+	Methodology      string             `json:"methodology"`
+	ExpectedTimeline string             `json:"expected_timeline"`
+	ExpectedOutcomes string             `json:"expected_outcomes"`
+	FileURL          *string            `json:"file_url"`
+	FileHash         string             `json:"file_hash,omitempty"`
+	FileSizeBytes    int64              `json:"file_size_bytes"`
+	IsApproved       bool               `json:"is_approved"`
+	CreatedBy        uint               `json:"created_by"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	LastSavedAt      *time.Time         `json:"last_saved_at,omitempty"`
+	Creator          *users.UserSummary `json:"creator,omitempty"`
+	domain.FileMetadata
+}
+
+func toProposalResponse(p *domain.Proposal) ProposalResponse {
+	resp := ProposalResponse{
+		ID:           p.ID,
+		TeamID:       p.TeamID,
+		AdvisorID:    p.AdvisorID,
+		Status:       p.Status,
+		CreatedBy:    p.CreatedBy,
+		AcademicYear: p.AcademicYear,
+		IsArchived:   p.IsArchived,
+		ArchivedAt:   p.ArchivedAt,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		Advisor:      users.NewUserSummary(p.Advisor),
+		Versions:     toVersionResponses(p.Versions),
+	}
+	resp.ContinuesProjectID = p.ContinuesProjectID
+	resp.SecondReviewerID = p.SecondReviewerID
+	if p.Team != nil {
+		team := &ProposalTeam{
+			ID:           p.Team.ID,
+			Name:         p.Team.Name,
+			DepartmentID: p.Team.DepartmentID,
+			AcademicYear: p.Team.AcademicYear,
+			Members:      make([]ProposalTeamMember, 0, len(p.Team.Members)),
+		}
+		for i := range p.Team.Members {
+			m := &p.Team.Members[i]
+			team.Members = append(team.Members, ProposalTeamMember{
+				UserID: m.UserID,
+				Role:   m.Role,
+				User:   users.NewUserSummary(&m.User),
+			})
+		}
+		resp.Team = team
+	}
+	return resp
+}
+
+// blindReviewStatuses are the states in which blind review hides a proposal's students from
+// advisors; the identities are revealed once the advisor has reached a decision
+var blindReviewStatuses = map[enums.ProposalStatus]bool{
+	enums.ProposalStatusSubmitted:            true,
+	enums.ProposalStatusUnderReview:          true,
+	enums.ProposalStatusAwaitingSecondReview: true,
+}
+
+// toReviewerProposalResponse maps a proposal for an advisor, hiding its students when blind
+// review applies and the proposal is still awaiting a decision
+func toReviewerProposalResponse(p *domain.Proposal, blind bool) ProposalResponse {
+	resp := toProposalResponse(p)
+	if blind && blindReviewStatuses[p.Status] {
+		resp.hideStudents()
+	}
+	return resp
+}
+
+// ReviewerProposalResponse is toReviewerProposalResponse for other API layers
+func ReviewerProposalResponse(p *domain.Proposal, blind bool) ProposalResponse {
+	return toReviewerProposalResponse(p, blind)
+}
+
+func toReviewerProposalResponses(proposals []domain.Proposal, blind bool) []ProposalResponse {
+	result := make([]ProposalResponse, 0, len(proposals))
+	for i := range proposals {
+		result = append(result, toReviewerProposalResponse(&proposals[i], blind))
+	}
+	return result
+}
+
+// hideStudents removes everything that identifies the students: the author, the team's name
+// and its members' accounts. Member roles stay so the reviewer still sees the team's size.
+func (r *ProposalResponse) hideStudents() {
+	r.CreatedBy = 0
+	r.StudentsHidden = true
+	if r.Team != nil {
+		r.Team.Name = ""
+		for i := range r.Team.Members {
+			r.Team.Members[i].UserID = 0
+			r.Team.Members[i].User = nil
+		}
+	}
+	for i := range r.Versions {
+		r.Versions[i].hideAuthor()
+	}
+}
+
+func (v *VersionResponse) hideAuthor() {
+	v.CreatedBy = 0
+	v.Creator = nil
+}
+
+func toProposalResponses(proposals []domain.Proposal) []ProposalResponse {
+	result := make([]ProposalResponse, 0, len(proposals))
+	for i := range proposals {
+		result = append(result, toProposalResponse(&proposals[i]))
+	}
+	return result
+}
+
+func toVersionResponses(versions []domain.ProposalVersion) []VersionResponse {
+	result := make([]VersionResponse, 0, len(versions))
+	for i := range versions {
+		v := &versions[i]
+		result = append(result, VersionResponse{
+			ID:               v.ID,
+			ProposalID:       v.ProposalID,
+			VersionNumber:    v.VersionNumber,
+			Title:            v.Title,
+			Abstract:         v.Abstract,
+			ProblemStatement: v.ProblemStatement,
+			Objectives:       v.Objectives,
+			Methodology:      v.Methodology,
+			ExpectedTimeline: v.ExpectedTimeline,
+			ExpectedOutcomes: v.ExpectedOutcomes,
+			FileURL:          v.FileURL,
+			FileHash:         v.FileHash,
+			FileSizeBytes:    v.FileSizeBytes,
+			FileMetadata:     v.FileMetadata,
+			IsApproved:       v.IsApproved,
+			CreatedBy:        v.CreatedBy,
+			Creator:          users.NewUserSummary(&v.Creator),
+			CreatedAt:        v.CreatedAt,
+			UpdatedAt:        v.UpdatedAt,
+			LastSavedAt:      v.LastSavedAt,
+		})
+	}
+	return result
+}
@@ -3,33 +3,40 @@ package proposals
 import (
 	"backend/internal/ai_checker"
 	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
 	"backend/pkg/response"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service  *Service
-	aiClient *ai_checker.Client
+	service    *Service
+	aiClient   *ai_checker.Client
+	aiSettings *ai_checker.Settings
 }
 
-func NewHandler(s *Service, aiClient *ai_checker.Client) *Handler {
-	return &Handler{service: s, aiClient: aiClient}
+func NewHandler(s *Service, aiClient *ai_checker.Client, aiSettings *ai_checker.Settings) *Handler {
+	return &Handler{service: s, aiClient: aiClient, aiSettings: aiSettings}
 }
 
 // DTOs
 type SaveProposalRequest struct {
-	TeamID           *uint  `json:"team_id"` // Optional
-	Title            string `json:"title" binding:"required"`
-	Abstract         string `json:"abstract"`
-	ProblemStatement string `json:"problem_statement"`
-	Objectives       string `json:"objectives"`
-	Methodology      string `json:"methodology"`
-	Timeline         string `json:"expected_timeline"`
-	ExpectedOutcomes string `json:"expected_outcomes"`
+	TeamID           *uint  `json:"team_id" form:"team_id"` // Optional
+	Title            string `json:"title" form:"title" binding:"required"`
+	Abstract         string `json:"abstract" form:"abstract"`
+	ProblemStatement string `json:"problem_statement" form:"problem_statement"`
+	Objectives       string `json:"objectives" form:"objectives"`
+	Methodology      string `json:"methodology" form:"methodology"`
+	Timeline         string `json:"expected_timeline" form:"expected_timeline"`
+	ExpectedOutcomes string `json:"expected_outcomes" form:"expected_outcomes"`
 }
 
 type SubmitProposalRequest struct {
@@ -39,12 +46,22 @@ type SubmitProposalRequest struct {
 // CreateProposal godoc
 // @Summary Create a new proposal draft
 // @Description Creates a new proposal ID with version 1. Team is optional at this stage.
+// @Description Send multipart/form-data with a "file" to attach the document: the draft, its version and
+// @Description the document are created together or not at all. A team that already has a draft gets 409;
+// @Description GET /teams/{id}/proposal/draft returns it to resume.
 // @Tags Proposals
 // @Accept json
+// @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
 // @Param proposal body SaveProposalRequest true "Proposal details"
-// @Success 201 {object} response.Response{data=domain.Proposal}
+// @Param file formData file false "Proposal document (PDF or DOCX)"
+// @Success 201 {object} response.Response{data=ProposalResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
 // @Router /proposals [post]
 func (h *Handler) CreateProposal(c *gin.Context) {
 	claims := getClaims(c)
@@ -53,18 +70,41 @@ func (h *Handler) CreateProposal(c *gin.Context) {
 	}
 
 	var req SaveProposalRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	multipartForm := c.ContentType() == "multipart/form-data"
+	bind := c.ShouldBindJSON
+	if multipartForm {
+		bind = c.ShouldBind
+	}
+	if err := bind(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid inputs", err.Error())
 		return
 	}
 
-	result, err := h.service.CreateDraft(h.mapRequestToInput(req), claims.UserID)
+	input := h.mapRequestToInput(req)
+	if multipartForm {
+		if file, err := c.FormFile("file"); err == nil {
+			input.File = file
+		}
+	}
+
+	result, err := h.service.CreateDraft(input, claims.UserID)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to create draft", err.Error())
+		switch {
+		case errors.Is(err, ErrTeamHasActiveProposal):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, files.ErrTeamQuotaExceeded), errors.Is(err, files.ErrUserQuotaExceeded):
+			response.Error(c, http.StatusRequestEntityTooLarge, err.Error(), nil)
+		case errors.Is(err, files.ErrFileInfected):
+			response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+		case errors.Is(err, ErrVersionFileType), errors.Is(err, ErrVersionFileTooLarge):
+			response.Error(c, http.StatusBadRequest, "Failed to create draft", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to create draft", err.Error())
+		}
 		return
 	}
 
-	response.JSON(c, http.StatusCreated, "Draft created successfully", result)
+	response.JSON(c, http.StatusCreated, "Draft created successfully", toProposalResponse(result))
 }
 
 // UpdateProposal godoc
@@ -76,7 +116,8 @@ func (h *Handler) CreateProposal(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "Proposal ID"
 // @Param proposal body SaveProposalRequest true "Proposal details"
-// @Success 200 {object} response.Response{data=domain.Proposal}
+// @Success 200 {object} response.Response{data=ProposalResponse}
+// @Failure 409 {object} response.ErrorResponse
 // @Router /proposals/{id} [put]
 func (h *Handler) UpdateProposal(c *gin.Context) {
 	claims := getClaims(c)
@@ -106,18 +147,22 @@ func (h *Handler) UpdateProposal(c *gin.Context) {
 	}
 
 	result, err := h.service.UpdateProposal(proposalID, h.mapRequestToInput(req), claims.UserID)
+	if errors.Is(err, ErrTeamHasActiveProposal) || errors.Is(err, ErrVersionConflict) {
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+		return
+	}
 	if err != nil {
 		// Differentiate error types (400 vs 500) if needed
 		response.Error(c, http.StatusBadRequest, "Failed to update proposal", err.Error())
 		return
 	}
 
-	response.JSON(c, http.StatusOK, "Proposal updated successfully", result)
+	response.JSON(c, http.StatusOK, "Proposal updated successfully", toProposalResponse(result))
 }
 
 // SubmitProposal godoc
 // @Summary Submit proposal
-// @Description Locks proposal and sends to Admin. Requires Finalized Team.
+// @Description Locks proposal and sends to Admin. Requires Finalized Team. A first submission is refused (409) outside the department's submission window, unless the team was granted a deadline extension that has not ended, and when the team already has another active proposal. The latest version must meet the department's proposal rules; otherwise the violations are returned with 422.
 // @Tags Proposals
 // @Accept json
 // @Produce json
@@ -125,6 +170,8 @@ func (h *Handler) UpdateProposal(c *gin.Context) {
 // @Param id path int true "Proposal ID"
 // @Param request body SubmitProposalRequest true "Team ID Confirmation"
 // @Success 200 {object} response.Response
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse{errors=[]Violation}
 // @Router /proposals/{id}/submit [post]
 func (h *Handler) SubmitProposal(c *gin.Context) {
 	claims := getClaims(c)
@@ -145,12 +192,22 @@ func (h *Handler) SubmitProposal(c *gin.Context) {
 
 	err := h.service.SubmitProposal(proposalID, req.TeamID, claims.UserID)
 	if err != nil {
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			response.Error(c, http.StatusUnprocessableEntity, ErrProposalInvalid.Error(), invalid.Report.Violations)
+			return
+		}
+		if errors.Is(err, ErrSubmissionWindowNotOpen) || errors.Is(err, ErrSubmissionWindowClosed) || errors.Is(err, ErrTeamHasActiveProposal) {
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
 		response.Error(c, http.StatusBadRequest, "Submission failed", err.Error())
 		return
 	}
 
+	// The AI check is skipped where the department or university turned AI features off
 	data := gin.H{}
-	if h.aiClient != nil {
+	if h.aiClient != nil && h.aiSettings.Allowed(claims.DepartmentID, claims.UniversityID) {
 		version, verErr := h.service.GetLatestVersion(proposalID)
 		if verErr != nil {
 			data["ai_error"] = verErr.Error()
@@ -175,16 +232,81 @@ func (h *Handler) SubmitProposal(c *gin.Context) {
 	response.JSON(c, http.StatusOK, "Proposal submitted successfully", data)
 }
 
+// AutosaveDraft godoc
+// @Summary Autosave a proposal draft
+// @Description Saves the changed fields of a draft onto its current version without creating a new version. Saves that change nothing are not written; saves less than 2 seconds after the last one are not written either and return the last save with debounced set, so the editor sends its latest content again. team_id must be a team the caller has accepted to join; 409 when that team already has an active proposal.
+// @Tags Proposals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param draft body AutosaveDraftRequest true "Changed fields"
+// @Success 200 {object} response.Response{data=AutosaveResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /proposals/{id}/draft [patch]
+func (h *Handler) AutosaveDraft(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+
+	var req AutosaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid inputs", err.Error())
+		return
+	}
+
+	result, err := h.service.AutosaveDraft(proposalID, req, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAutosaveNotDraft), errors.Is(err, ErrTeamHasActiveProposal):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, ErrAutosaveTeamForbidden):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to edit this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Autosave failed", err.Error())
+		}
+		return
+	}
+
+	message := "Draft saved"
+	switch {
+	case result.Debounced:
+		message = "Draft was saved moments ago; send the latest content again shortly"
+	case !result.Saved:
+		message = "No changes to save"
+	}
+	response.JSON(c, http.StatusOK, message, result)
+}
+
 // GET /proposals
 // GetProposals godoc
 // @Summary Get proposals
-// @Description Retrieve proposals with optional filters
+// @Description Retrieve a page of proposals visible to the caller, with filters, sorting and total count. At universities with blind review, advisors get submitted and under-review proposals without the author, team name or members (students_hidden is set).
 // @Tags Proposals
 // @Produce json
 // @Security BearerAuth
 // @Param status query string false "Proposal status"
-// @Param department_id query int false "Department ID"
-// @Success 200 {object} response.Response{data=[]domain.Proposal}
+// @Param archived query bool false "List archived proposals instead of active ones"
+// @Param advisor_id query int false "Filter by assigned advisor (admins only; advisors always see their own)"
+// @Param team query string false "Search by team name"
+// @Param sort query string false "Sort by: created_at, updated_at, title (default: created_at)"
+// @Param order query string false "Sort order: asc, desc (default: desc)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=[]ProposalResponse}
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /proposals [get]
 func (h *Handler) GetProposals(c *gin.Context) {
@@ -193,11 +315,51 @@ func (h *Handler) GetProposals(c *gin.Context) {
 		return
 	}
 
-	status := c.Query("status")
+	q := ProposalListQuery{
+		Status:     c.Query("status"),
+		Archived:   c.Query("archived") == "true",
+		TeamSearch: c.Query("team"),
+		Sort:       c.DefaultQuery("sort", "created_at"),
+		Order:      c.DefaultQuery("order", "desc"),
+		Page:       1,
+		Limit:      20,
+	}
+
+	switch q.Sort {
+	case "created_at", "updated_at", "title":
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid sort field", "sort must be one of created_at, updated_at, title")
+		return
+	}
+	if q.Order != "asc" && q.Order != "desc" {
+		response.Error(c, http.StatusBadRequest, "Invalid sort order", "order must be asc or desc")
+		return
+	}
+
+	if advisorID := c.Query("advisor_id"); advisorID != "" {
+		id, err := strconv.ParseUint(advisorID, 10, 32)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid advisor ID", err.Error())
+			return
+		}
+		q.AdvisorID = uint(id)
+	}
+
+	// Pagination
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			q.Page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			q.Limit = parsed
+		}
+	}
 
 	// Call service with user context from token
-	proposals, err := h.service.GetProposals(
-		status,
+	proposals, total, err := h.service.GetProposals(
+		q,
 		claims.UserID,
 		claims.Role,
 		claims.DepartmentID,
@@ -208,17 +370,25 @@ func (h *Handler) GetProposals(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, proposals)
+	response.Success(c, gin.H{
+		"proposals": toReviewerProposalResponses(proposals, h.service.BlindReviewApplies(claims.UserID, claims.Role)),
+		"pagination": gin.H{
+			"page":  q.Page,
+			"limit": q.Limit,
+			"total": total,
+			"pages": (total + int64(q.Limit) - 1) / int64(q.Limit),
+		},
+	})
 }
 
 // GetProposal godoc
 // @Summary Get proposal by ID
-// @Description Retrieve a specific proposal by its ID
+// @Description Retrieve a specific proposal by its ID. Under blind review the students are hidden from advisors until a decision is made.
 // @Tags Proposals
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Proposal ID"
-// @Success 200 {object} response.Response{data=domain.Proposal}
+// @Success 200 {object} response.Response{data=ProposalResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
@@ -251,7 +421,7 @@ func (h *Handler) GetProposal(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, proposal)
+	response.Success(c, toReviewerProposalResponse(proposal, h.service.BlindReviewApplies(claims.UserID, claims.Role)))
 }
 
 // GetProposal godoc
@@ -261,10 +431,14 @@ func (h *Handler) GetProposal(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Proposal ID"
-// @Success 200 {object} response.Response{data=[]domain.ProposalVersion}
+// @Success 200 {object} response.Response{data=[]VersionResponse}
 // @Failure 500 {object} response.ErrorResponse
 // @Router /proposals/{id}/versions [get]
 func (h *Handler) GetVersions(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
 	id := parseID(c)
 	if id == 0 {
 		return
@@ -276,7 +450,13 @@ func (h *Handler) GetVersions(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, versions)
+	result := toVersionResponses(versions)
+	if h.service.BlindReviewApplies(claims.UserID, claims.Role) && h.service.AwaitingDecision(id) {
+		for i := range result {
+			result[i].hideAuthor()
+		}
+	}
+	response.Success(c, result)
 }
 
 // DeleteProposal godoc
@@ -338,17 +518,27 @@ func parseID(c *gin.Context) uint {
 }
 
 type AssignAdvisorRequest struct {
-	AdvisorID uint `json:"advisor_id" binding:"required"`
+	AdvisorID         uint `json:"advisor_id" binding:"required"`
+	AcceptWithinHours int  `json:"accept_within_hours" binding:"omitempty,min=1" example:"72"` // defaults to 72
 }
 
 // AssignAdvisor godoc
 // @Summary Assign advisor to proposal
+// @Description The proposal and the advisor must belong to the caller's department, and the advisor must be active (400 otherwise). Refused with 409 when the advisor or the department has reached its quota for the proposal's cohort, the advisor declared a conflict of interest with the team that the department head has not overridden, or the advisor is the proposal's co-reviewer. The advisor is notified and has accept_within_hours (72 by default, at most 336) to accept or decline; unanswered assignments are escalated to the department admins with a suggested replacement.
 // @Tags Admin
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body AssignAdvisorRequest true "Advisor and acceptance window"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Router /proposals/{id}/assign [patch]
 func (h *Handler) AssignAdvisor(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
 	id := parseID(c) // Helper
 	var req AssignAdvisorRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -356,9 +546,1340 @@ func (h *Handler) AssignAdvisor(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.AssignAdvisor(id, req.AdvisorID); err != nil {
-		response.Error(c, http.StatusInternalServerError, "Assignment failed", err.Error())
+	if err := h.service.AssignAdvisor(id, req.AdvisorID, req.AcceptWithinHours, claims.DepartmentID); err != nil {
+		switch {
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrAcceptWindowTooLong), errors.Is(err, ErrAdvisorInvalid):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrAdvisorQuotaReached), errors.Is(err, ErrTeamQuotaReached), errors.Is(err, ErrConflictOfInterest),
+			errors.Is(err, ErrAdvisorIsSecondReviewer):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Assignment failed", err.Error())
+		}
 		return
 	}
 	response.JSON(c, http.StatusOK, "Advisor assigned successfully", nil)
 }
+
+// AssignSecondReviewer godoc
+// @Summary Assign a co-reviewer to a proposal
+// @Description The department head names another advisor of the department whose approval the proposal also needs. The assigned advisor's approval then moves the proposal to awaiting_second_review, and only the co-reviewer's approval makes it approved; either can still request a revision or reject. Assigning again replaces the co-reviewer.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param request body AssignSecondReviewerRequest true "Co-reviewer"
+// @Success 200 {object} response.Response{data=ProposalResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/proposals/{id}/second-reviewer [put]
+func (h *Handler) AssignSecondReviewer(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+	var req AssignSecondReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	proposal, err := h.service.AssignSecondReviewer(id, req, claims.DepartmentID)
+	if err != nil {
+		switch {
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrSecondReviewerInvalid), errors.Is(err, ErrSecondReviewerIsAdvisor):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrCoReviewClosed), errors.Is(err, ErrConflictOfInterest):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to assign the co-reviewer", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Co-reviewer assigned successfully", toProposalResponse(proposal))
+}
+
+// RemoveSecondReviewer godoc
+// @Summary Remove a proposal's co-reviewer
+// @Description The assigned advisor's approval alone approves the proposal again. Not possible while the co-reviewer's decision is pending; assign another co-reviewer instead.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/proposals/{id}/second-reviewer [delete]
+func (h *Handler) RemoveSecondReviewer(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	if err := h.service.RemoveSecondReviewer(id, claims.DepartmentID); err != nil {
+		switch {
+		case err.Error() == "proposal not found", errors.Is(err, ErrNoSecondReviewer):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrSecondReviewPending), errors.Is(err, ErrCoReviewClosed):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to remove the co-reviewer", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Co-reviewer removed", nil)
+}
+
+// RespondToAssignment godoc
+// @Summary Accept or decline a proposal assignment
+// @Description The assigned advisor answers before the acceptance deadline. Declining needs a comment and is only possible before the advisor reviews the proposal; the proposal goes back to submitted and the department admins are asked to assign someone else, with a suggested replacement.
+// @Tags Proposals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param request body RespondAssignmentRequest true "Decision"
+// @Success 200 {object} response.Response{data=domain.Proposal}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /proposals/{id}/assignment [post]
+func (h *Handler) RespondToAssignment(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+
+	var req RespondAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	proposal, err := h.service.RespondToAssignment(proposalID, req, claims.UserID)
+	if err != nil {
+		switch {
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrNotAssignedAdvisor):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrDeclineReasonMissing):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrAssignmentAnswered), errors.Is(err, ErrAssignmentReviewed):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to answer the assignment", err.Error())
+		}
+		return
+	}
+
+	message := "Assignment accepted"
+	if req.Decision == "decline" {
+		message = "Assignment declined; the department has been asked to assign another advisor"
+	}
+	response.JSON(c, http.StatusOK, message, proposal)
+}
+
+// GetQuota godoc
+// @Summary Get the department quota
+// @Description Per-cohort limits on proposals per advisor and supervised teams, with the current cohort's team usage
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=QuotaStatus}
+// @Router /admin/quotas [get]
+func (h *Handler) GetQuota(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	status, err := h.service.GetQuota(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch quota", err.Error())
+		return
+	}
+	response.Success(c, status)
+}
+
+// UpdateQuota godoc
+// @Summary Configure the department quota
+// @Description Sets how many proposals an advisor may take and how many teams the department may supervise per cohort; 0 removes a limit. Enforced when advisors are assigned.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateQuotaRequest true "Quota limits"
+// @Success 200 {object} response.Response{data=QuotaStatus}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/quotas [put]
+func (h *Handler) UpdateQuota(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	status, err := h.service.UpdateQuota(claims.DepartmentID, claims.UserID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Quota updated", status)
+}
+
+// ValidateProposal godoc
+// @Summary Check a proposal against the department's rules
+// @Description Runs the checks applied on submission against the latest version: word counts per section and, when a PDF is attached, the required headings. Checks that could not run are listed as warnings.
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=ValidationReport}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/validation [get]
+func (h *Handler) ValidateProposal(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+
+	report, err := h.service.ValidateProposal(proposalID, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		switch err.Error() {
+		case "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case "you do not have permission to view this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+		}
+		return
+	}
+	response.Success(c, report)
+}
+
+// ReplaceVersionFile godoc
+// @Summary Replace the document of the current version
+// @Description Swaps the PDF or DOCX attached to the proposal's current version without creating a new version. Only the draft, or a revision the advisor has not seen yet, can be changed. The previous file's reference and hash are kept in the version's file history.
+// @Tags Proposals
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param vid path int true "Version ID"
+// @Param file formData file true "Proposal document (PDF or DOCX)"
+// @Success 200 {object} response.Response{data=VersionResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /proposals/{id}/versions/{vid}/file [patch]
+func (h *Handler) ReplaceVersionFile(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+	versionID, err := strconv.ParseUint(c.Param("vid"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid version ID", err.Error())
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "A file is required", err.Error())
+		return
+	}
+
+	version, err := h.service.ReplaceVersionFile(proposalID, uint(versionID), file, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrVersionNotFound), err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to edit this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrVersionNotEditable):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, files.ErrTeamQuotaExceeded), errors.Is(err, files.ErrUserQuotaExceeded):
+			response.Error(c, http.StatusRequestEntityTooLarge, err.Error(), nil)
+		case errors.Is(err, files.ErrFileInfected):
+			response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusBadRequest, "Failed to replace file", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "File replaced", toVersionResponses([]domain.ProposalVersion{*version})[0])
+}
+
+// GetVersionFileHistory godoc
+// @Summary File history of a proposal version
+// @Description Lists the version's current document and the files it replaced, with their hashes, newest first
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param vid path int true "Version ID"
+// @Success 200 {object} response.Response{data=VersionFileHistory}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/versions/{vid}/files [get]
+func (h *Handler) GetVersionFileHistory(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+	versionID, err := strconv.ParseUint(c.Param("vid"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid version ID", err.Error())
+		return
+	}
+
+	history, err := h.service.GetVersionFileHistory(proposalID, uint(versionID), claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrVersionNotFound), err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to view this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch file history", err.Error())
+		}
+		return
+	}
+	response.Success(c, history)
+}
+
+// RestoreVersionFile godoc
+// @Summary Restore an archived version file
+// @Description Brings a file the university's retention policy moved to cold storage back into the upload directory, so it can be downloaded again. Without file_id the version's own document is restored; with it, one of the drafts it replaced. Restored files stay for a week before the policy can archive them again.
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param vid path int true "Version ID"
+// @Param file_id query int false "ID of a replaced file from the version's file history"
+// @Success 200 {object} response.Response{data=VersionFileHistory}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /proposals/{id}/versions/{vid}/restore-file [post]
+func (h *Handler) RestoreVersionFile(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+	versionID, err := strconv.ParseUint(c.Param("vid"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid version ID", err.Error())
+		return
+	}
+	var fileID uint64
+	if raw := c.Query("file_id"); raw != "" {
+		if fileID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid file ID", err.Error())
+			return
+		}
+	}
+
+	history, err := h.service.RestoreVersionFile(proposalID, uint(versionID), uint(fileID), claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrVersionNotFound), errors.Is(err, ErrFileNotFound), err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to view this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrFileNotArchived):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to restore file", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "File restored", history)
+}
+
+// GetRetentionPolicy godoc
+// @Summary Get the university's version file retention policy
+// @Description How many of each proposal's latest versions keep their files in the upload directory. Older files are moved to cold storage daily and can be restored on demand; 0 keeps every file.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=RetentionPolicy}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/retention-policy [get]
+func (h *Handler) GetRetentionPolicy(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	policy, err := h.service.GetRetentionPolicy(claims.UniversityID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.Success(c, policy)
+}
+
+// UpdateRetentionPolicy godoc
+// @Summary Set the university's version file retention policy
+// @Description Keeps the files of each proposal's latest version_files_kept versions on disk and moves older ones, including the drafts they replaced, to cold storage on the next daily run. The approved version's file is never moved. 0 keeps every file.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateRetentionPolicyRequest true "Retention policy"
+// @Success 200 {object} response.Response{data=RetentionPolicy}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/retention-policy [put]
+func (h *Handler) UpdateRetentionPolicy(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	policy, err := h.service.UpdateRetentionPolicy(claims.UniversityID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Retention policy updated", policy)
+}
+
+// GetAbandonmentPolicy godoc
+// @Summary Get the university's abandonment policy
+// @Description After how many days without activity from the team a proposal awaiting revision is marked abandoned. Teams are warned 7 days and 1 day before; 0 never abandons proposals.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=AbandonmentPolicy}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/abandonment-policy [get]
+func (h *Handler) GetAbandonmentPolicy(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	policy, err := h.service.GetAbandonmentPolicy(claims.UniversityID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.Success(c, policy)
+}
+
+// UpdateAbandonmentPolicy godoc
+// @Summary Set the university's abandonment policy
+// @Description Proposals awaiting revision are abandoned once the team has not edited them for abandon_after_days since the revision request, their last edit or their reopening. 0 never abandons proposals.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateAbandonmentPolicyRequest true "Abandonment policy"
+// @Success 200 {object} response.Response{data=AbandonmentPolicy}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/abandonment-policy [put]
+func (h *Handler) UpdateAbandonmentPolicy(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateAbandonmentPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	policy, err := h.service.UpdateAbandonmentPolicy(claims.UniversityID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Abandonment policy updated", policy)
+}
+
+// GetRevisionResponse godoc
+// @Summary Get the team's response to a revision request
+// @Description Whether the team has edited the proposal since the advisor requested revision, its last activity and, when the university abandons inactive proposals, when this one will be abandoned.
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=RevisionResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /proposals/{id}/revision-response [get]
+func (h *Handler) GetRevisionResponse(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+
+	result, err := h.service.GetRevisionResponse(proposalID, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		switch {
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to view this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrNotAwaitingRevision):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch revision response", err.Error())
+		}
+		return
+	}
+	response.Success(c, result)
+}
+
+// ContinueProject godoc
+// @Summary Continue an earlier cohort's project
+// @Description Starts a draft proposal for the team, pre-filled from the project's approved version and linked to the project. Once the draft is approved and becomes a project, that project lists the earlier cohorts and gets their approved documentation for reference. Only the team leader can do this, for a project of the team's department that no other active proposal continues.
+// @Tags Proposals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param request body ContinueProjectRequest true "Team taking over the project"
+// @Success 201 {object} response.Response{data=ProposalResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /projects/{id}/continue [post]
+func (h *Handler) ContinueProject(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	projectID := parseID(c)
+	if projectID == 0 {
+		return
+	}
+
+	var req ContinueProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	proposal, err := h.service.ContinueProject(projectID, req, claims.UserID)
+	if err != nil {
+		switch {
+		case err.Error() == "project not found", err.Error() == "team not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrNotTeamLeader), errors.Is(err, ErrProjectInOtherDept):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrProjectContinued), errors.Is(err, ErrTeamHasActiveProposal):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to continue project", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Draft created from the project", toProposalResponse(proposal))
+}
+
+// GetArchiveMatches godoc
+// @Summary Get archived projects similar to a proposal
+// @Description Up to 5 archived projects of the department that the AI similarity index found closest to the proposal when it was last submitted, best match first. checked_at is null until a submission was compared; departments with AI features turned off are not compared. Not shown to students.
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=ArchiveComparison}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/archive-matches [get]
+func (h *Handler) GetArchiveMatches(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+
+	comparison, err := h.service.GetArchiveMatches(proposalID, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		switch {
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to view this proposal", errors.Is(err, ErrArchiveMatchesHidden):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch archive matches", err.Error())
+		}
+		return
+	}
+	response.Success(c, comparison)
+}
+
+// ReopenProposal godoc
+// @Summary Reopen an abandoned proposal
+// @Description Puts a proposal abandoned for inactivity back to revision_required so the team can resubmit it. The inactivity period starts over and the team and advisor are notified.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=domain.Proposal}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/proposals/{id}/reopen [post]
+func (h *Handler) ReopenProposal(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	proposalID := parseID(c)
+	if proposalID == 0 {
+		return
+	}
+
+	proposal, err := h.service.ReopenProposal(proposalID, claims.UserID, claims.DepartmentID)
+	if err != nil {
+		switch {
+		case err.Error() == "proposal not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case err.Error() == "you do not have permission to view this proposal":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrNotAbandoned):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to reopen proposal", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Proposal reopened", proposal)
+}
+
+// GetProposalRules godoc
+// @Summary Get the department's proposal rules
+// @Description Word limits per proposal section and headings the uploaded PDF must contain, checked before a proposal can be submitted. No rules apply until the department sets its own.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=ProposalRules}
+// @Router /admin/proposal-rules [get]
+func (h *Handler) GetProposalRules(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	rules, err := h.service.GetProposalRules(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch proposal rules", err.Error())
+		return
+	}
+	response.Success(c, rules)
+}
+
+// UpdateProposalRules godoc
+// @Summary Replace the department's proposal rules
+// @Description Sections are title, abstract, problem_statement, objectives, methodology, expected_timeline and expected_outcomes; a word limit of 0 is no limit. Applies to the next submission.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateProposalRulesRequest true "Proposal rules"
+// @Success 200 {object} response.Response{data=ProposalRules}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/proposal-rules [put]
+func (h *Handler) UpdateProposalRules(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateProposalRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	rules, err := h.service.UpdateProposalRules(claims.DepartmentID, claims.UserID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Proposal rules updated", rules)
+}
+
+// GetSubmissionWindow godoc
+// @Summary Get the proposal submission window
+// @Description The department's submission window for the current cohort with the teams that have not submitted a proposal
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=SubmissionWindowStatus}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/submission-window [get]
+func (h *Handler) GetSubmissionWindow(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	status, err := h.service.GetSubmissionWindow(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	response.Success(c, status)
+}
+
+// UpdateSubmissionWindow godoc
+// @Summary Set the proposal submission window
+// @Description Teams can make their first submission only while the window is open. Teams that have not submitted are reminded reminder_days before it closes (7, 3 and 1 by default), and department admins get the list of outstanding teams with each reminder and when the window closes.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateSubmissionWindowRequest true "Submission window"
+// @Success 200 {object} response.Response{data=SubmissionWindowStatus}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/submission-window [put]
+func (h *Handler) UpdateSubmissionWindow(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateSubmissionWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	status, err := h.service.UpdateSubmissionWindow(claims.DepartmentID, claims.UserID, req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Submission window updated", status)
+}
+
+// RequestDeadlineExtension godoc
+// @Summary Request a submission deadline extension
+// @Description The team leader asks to make the team's first submission after the department's submission window closes, at most 30 days past its closing date. The department admins are notified and approve or deny the request; a team has one pending request at a time.
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body DeadlineExtensionRequest true "Requested deadline and justification"
+// @Success 201 {object} response.Response{data=domain.DeadlineExtension}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /teams/{id}/deadline-extensions [post]
+func (h *Handler) RequestDeadlineExtension(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c)
+	if teamID == 0 {
+		return
+	}
+
+	var req DeadlineExtensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	ext, err := h.service.RequestDeadlineExtension(teamID, claims.UserID, req)
+	if err != nil {
+		respondExtensionError(c, "Failed to request an extension", err)
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Extension requested", ext)
+}
+
+// GetTeamDeadlineExtensions godoc
+// @Summary List a team's deadline extension requests
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=[]domain.DeadlineExtension}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/deadline-extensions [get]
+func (h *Handler) GetTeamDeadlineExtensions(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c)
+	if teamID == 0 {
+		return
+	}
+
+	exts, err := h.service.GetTeamDeadlineExtensions(teamID, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondExtensionError(c, "Failed to fetch extension requests", err)
+		return
+	}
+	response.Success(c, exts)
+}
+
+// GetTeamDraft godoc
+// @Summary Resume a team's draft proposal
+// @Description Returns the team's draft with the sections, and the document, still missing. 404 when the team has no draft.
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=TeamDraft}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/proposal/draft [get]
+func (h *Handler) GetTeamDraft(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c)
+	if teamID == 0 {
+		return
+	}
+
+	draft, err := h.service.GetTeamDraft(teamID, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNoDraft), err.Error() == "team not found":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrDraftForbidden):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch draft", err.Error())
+		}
+		return
+	}
+	response.Success(c, draft)
+}
+
+// GetDeadlineExtensions godoc
+// @Summary List the department's deadline extension requests
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved or denied"
+// @Success 200 {object} response.Response{data=[]domain.DeadlineExtension}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/deadline-extensions [get]
+func (h *Handler) GetDeadlineExtensions(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	status := c.Query("status")
+	switch enums.DeadlineExtensionStatus(status) {
+	case "", enums.DeadlineExtensionPending, enums.DeadlineExtensionApproved, enums.DeadlineExtensionDenied:
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid status", "status must be one of pending, approved, denied")
+		return
+	}
+
+	exts, err := h.service.GetDepartmentDeadlineExtensions(claims.DepartmentID, status)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch extension requests", err.Error())
+		return
+	}
+	response.Success(c, exts)
+}
+
+// DecideDeadlineExtension godoc
+// @Summary Approve or deny a deadline extension
+// @Description Approving lets the team make its first submission until extended_until (the requested date by default). Denying requires a comment. The team is notified and the decision is recorded in the audit log.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Extension request ID"
+// @Param request body DecideDeadlineExtensionRequest true "Decision"
+// @Success 200 {object} response.Response{data=domain.DeadlineExtension}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/deadline-extensions/{id}/decide [post]
+func (h *Handler) DecideDeadlineExtension(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	var req DecideDeadlineExtensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	meta := audit.NewRequestMeta(c)
+	ext, err := h.service.DecideDeadlineExtension(id, claims.UserID, claims.DepartmentID, req, meta)
+	if err != nil {
+		respondExtensionError(c, "Failed to decide the extension", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Extension "+string(ext.Status), ext)
+}
+
+func respondExtensionError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, ErrExtensionNotFound), err.Error() == "team not found":
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrExtensionForbidden), errors.Is(err, ErrExtensionNotLeader):
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrExtensionPending), errors.Is(err, ErrExtensionDecided), errors.Is(err, ErrExtensionNotRequired):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	default:
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	}
+}
+
+// GetRebalanceSuggestions godoc
+// @Summary Suggest advisor rebalancing
+// @Description Compares each advisor's load in the current cohort with their capacity (the department's advisor quota, or 5 when none is set) and suggests moving unreviewed proposals from overloaded advisors to underloaded ones in the same department
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=RebalanceSuggestions}
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/advisors/rebalance-suggestions [get]
+func (h *Handler) GetRebalanceSuggestions(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	suggestions, err := h.service.GetRebalanceSuggestions(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to compute suggestions", err.Error())
+		return
+	}
+	response.Success(c, suggestions)
+}
+
+// GetWorkloadHistory godoc
+// @Summary Get an advisor's workload history
+// @Description Weekly snapshots of the advisor's load (cohort proposals against capacity, proposals in review, pending decisions, supervised teams and feedback given) next to the department average, oldest first, with a summary per academic year. relative_load above 1 means more than the department average.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Advisor ID"
+// @Param weeks query int false "Weeks of history, up to 260" default(52)
+// @Success 200 {object} response.Response{data=WorkloadHistory}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/advisors/{id}/workload-history [get]
+func (h *Handler) GetWorkloadHistory(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	advisorID := parseID(c)
+	if advisorID == 0 {
+		return
+	}
+
+	weeks := 0
+	if raw := c.Query("weeks"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			response.Error(c, http.StatusBadRequest, "Invalid weeks", "weeks must be a positive number")
+			return
+		}
+		weeks = n
+	}
+
+	history, err := h.service.GetWorkloadHistory(advisorID, claims.DepartmentID, weeks)
+	if err != nil {
+		if errors.Is(err, ErrAdvisorNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	response.Success(c, history)
+}
+
+// ApplyRebalance godoc
+// @Summary Apply advisor rebalancing
+// @Description Reassigns a batch of proposals in one transaction, typically the moves returned by the suggestions endpoint. Each move is revalidated; if any is no longer valid nothing is changed. Returns the updated suggestions.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ApplyRebalanceRequest true "Moves to apply"
+// @Success 200 {object} response.Response{data=RebalanceSuggestions}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/advisors/rebalance [post]
+func (h *Handler) ApplyRebalance(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req ApplyRebalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.service.ApplyRebalance(claims.DepartmentID, req)
+	if err != nil {
+		if errors.Is(err, ErrRebalanceMoveInvalid) {
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to apply rebalancing", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Proposals reassigned", result)
+}
+
+// MessageAdvisors godoc
+// @Summary Message a filtered group of advisors
+// @Description Sends the department head's message as a notification, and by email when asked, to the department's active advisors matching every filter set: overloaded (above capacity in the current cohort) and pending_review_days (a submitted proposal waiting longer than that for their decision). Without filters every advisor receives it. dry_run lists the recipients without sending.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AdvisorMessageRequest true "Message and filters"
+// @Success 200 {object} response.Response{data=AdvisorMessageResult} "Dry run"
+// @Success 201 {object} response.Response{data=AdvisorMessageResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/messages/advisors [post]
+func (h *Handler) MessageAdvisors(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req AdvisorMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.service.MessageAdvisors(req, claims.UserID, claims.DepartmentID)
+	if err != nil {
+		if errors.Is(err, ErrNoAdvisorsMatched) {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Failed to message advisors", err.Error())
+		return
+	}
+	if !result.Sent {
+		response.JSON(c, http.StatusOK, "Recipients matched", result)
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Message sent", result)
+}
+
+type ArchiveCohortRequest struct {
+	AcademicYear string `json:"academic_year" binding:"required"`
+}
+
+// ArchiveCohort godoc
+// @Summary Archive a past academic year
+// @Description Bulk-archives the department's proposals and projects from a completed academic year. Archived records are hidden from default listings but can be listed with archived=true.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ArchiveCohortRequest true "Academic year to archive"
+// @Success 200 {object} response.Response{data=ArchiveResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/proposals/archive-cohort [post]
+func (h *Handler) ArchiveCohort(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req ArchiveCohortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.service.ArchiveCohort(req.AcademicYear, claims.UserID, claims.DepartmentID)
+	if err != nil {
+		if err.Error() == "cannot archive the current academic year" {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to archive cohort", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Cohort archived successfully", result)
+}
+
+// GetTimeline godoc
+// @Summary Get proposal timeline
+// @Description Structured timeline phases of a proposal and the project period they must fit in
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=Timeline}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/timeline [get]
+func (h *Handler) GetTimeline(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	timeline, err := h.service.GetTimeline(id, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondTimelineError(c, "Failed to fetch timeline", err)
+		return
+	}
+
+	response.Success(c, timeline)
+}
+
+// GetGantt godoc
+// @Summary Get proposal Gantt chart data
+// @Description Timeline phases positioned for rendering as a Gantt chart
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} response.Response{data=GanttChart}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/timeline/gantt [get]
+func (h *Handler) GetGantt(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	chart, err := h.service.GetGantt(id, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondTimelineError(c, "Failed to build Gantt chart", err)
+		return
+	}
+
+	response.Success(c, chart)
+}
+
+// CreatePhase godoc
+// @Summary Add a timeline phase
+// @Tags Proposals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param phase body PhaseInput true "Phase details"
+// @Success 201 {object} response.Response{data=domain.TimelinePhase}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /proposals/{id}/timeline/phases [post]
+func (h *Handler) CreatePhase(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	var input PhaseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	phase, err := h.service.CreatePhase(id, input, claims.UserID)
+	if err != nil {
+		respondTimelineError(c, "Failed to add phase", err)
+		return
+	}
+
+	response.JSON(c, http.StatusCreated, "Phase added", phase)
+}
+
+// UpdatePhase godoc
+// @Summary Update a timeline phase
+// @Tags Proposals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param phaseId path int true "Phase ID"
+// @Param phase body PhaseInput true "Phase details"
+// @Success 200 {object} response.Response{data=domain.TimelinePhase}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/timeline/phases/{phaseId} [put]
+func (h *Handler) UpdatePhase(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+	phaseID, err := strconv.ParseUint(c.Param("phaseId"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid phase ID", err.Error())
+		return
+	}
+
+	var input PhaseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	phase, err := h.service.UpdatePhase(id, uint(phaseID), input, claims.UserID)
+	if err != nil {
+		respondTimelineError(c, "Failed to update phase", err)
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Phase updated", phase)
+}
+
+// DeletePhase godoc
+// @Summary Delete a timeline phase
+// @Tags Proposals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Proposal ID"
+// @Param phaseId path int true "Phase ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /proposals/{id}/timeline/phases/{phaseId} [delete]
+func (h *Handler) DeletePhase(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+	phaseID, err := strconv.ParseUint(c.Param("phaseId"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid phase ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeletePhase(id, uint(phaseID), claims.UserID); err != nil {
+		respondTimelineError(c, "Failed to delete phase", err)
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Phase deleted", nil)
+}
+
+func respondTimelineError(c *gin.Context, message string, err error) {
+	switch {
+	case err.Error() == "proposal not found" || err.Error() == "phase not found":
+		response.Error(c, http.StatusNotFound, message, err.Error())
+	case err.Error() == "you do not have permission to view this proposal" || err.Error() == "you do not have permission to edit this timeline":
+		response.Error(c, http.StatusForbidden, message, err.Error())
+	case strings.HasPrefix(err.Error(), "timeline cannot be edited"):
+		response.Error(c, http.StatusConflict, message, err.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	}
+}
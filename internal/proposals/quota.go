@@ -0,0 +1,150 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"errors"
+)
+
+var (
+	ErrAdvisorQuotaReached = errors.New("advisor has reached their proposal quota for this cohort")
+	ErrTeamQuotaReached    = errors.New("department has reached its team quota for this cohort")
+	ErrConflictOfInterest  = errors.New("advisor has declared a conflict of interest with this team")
+)
+
+// UpdateQuotaRequest sets the department's per-cohort limits; omitted fields keep their value and 0 removes a limit
+type UpdateQuotaRequest struct {
+	AdvisorProposalLimit *int `json:"advisor_proposal_limit" binding:"omitempty,min=0"`
+	TeamLimit            *int `json:"team_limit" binding:"omitempty,min=0"`
+}
+
+// QuotaStatus is a department's quota with how much of it the current cohort uses
+type QuotaStatus struct {
+	DepartmentID         uint   `json:"department_id"`
+	AcademicYear         string `json:"academic_year"`
+	AdvisorProposalLimit int    `json:"advisor_proposal_limit"` // 0 = unlimited
+	TeamLimit            int    `json:"team_limit"`             // 0 = unlimited
+	TeamsSupervised      int64  `json:"teams_supervised"`
+	TeamsRemaining       *int64 `json:"teams_remaining,omitempty"` // nil when unlimited
+}
+
+// GetQuota returns the department's quota and the current cohort's team usage
+func (s *Service) GetQuota(departmentID uint) (*QuotaStatus, error) {
+	quota, err := s.repo.GetDepartmentQuota(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	year := s.repo.GetDepartmentAcademicYear(departmentID)
+	supervised, err := s.repo.CountSupervisedTeams(departmentID, year, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &QuotaStatus{
+		DepartmentID:         departmentID,
+		AcademicYear:         year,
+		AdvisorProposalLimit: quota.AdvisorProposalLimit,
+		TeamLimit:            quota.TeamLimit,
+		TeamsSupervised:      supervised,
+	}
+	if quota.TeamLimit > 0 {
+		remaining := int64(quota.TeamLimit) - supervised
+		if remaining < 0 {
+			remaining = 0
+		}
+		status.TeamsRemaining = &remaining
+	}
+	return status, nil
+}
+
+// UpdateQuota changes the department's limits. Lowering a limit below current usage is allowed;
+// it only stops further assignments.
+func (s *Service) UpdateQuota(departmentID uint, userID uint, req UpdateQuotaRequest) (*QuotaStatus, error) {
+	quota, err := s.repo.GetDepartmentQuota(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if req.AdvisorProposalLimit != nil {
+		if *req.AdvisorProposalLimit < 0 {
+			return nil, errors.New("advisor proposal limit cannot be negative")
+		}
+		quota.AdvisorProposalLimit = *req.AdvisorProposalLimit
+	}
+	if req.TeamLimit != nil {
+		if *req.TeamLimit < 0 {
+			return nil, errors.New("team limit cannot be negative")
+		}
+		quota.TeamLimit = *req.TeamLimit
+	}
+	quota.UpdatedBy = &userID
+
+	if err := s.repo.SaveDepartmentQuota(quota); err != nil {
+		return nil, err
+	}
+	return s.GetQuota(departmentID)
+}
+
+// checkQuota refuses an assignment that would take the advisor or the department over its
+// quota for the proposal's cohort. Reassigning within the same proposal or team is not counted twice.
+func (s *Service) checkQuota(proposal *domain.Proposal, advisorID uint) error {
+	if proposal.Team == nil {
+		return nil
+	}
+	return s.checkAdvisorQuota(proposal.Team, proposal.AcademicYear, proposal.ID, advisorID)
+}
+
+// CheckTeamQuota refuses an advisor taking on the team when the advisor or the department is at
+// its quota for the team's cohort; checked when an advisor is assigned to or accepts a team
+func (s *Service) CheckTeamQuota(team *domain.Team, advisorID uint) error {
+	return s.checkAdvisorQuota(team, team.AcademicYear, 0, advisorID)
+}
+
+// checkAdvisorQuota counts the advisor's proposals of the cohort other than excludeProposalID and
+// the department's supervised teams other than the team itself
+func (s *Service) checkAdvisorQuota(team *domain.Team, academicYear string, excludeProposalID uint, advisorID uint) error {
+	quota, err := s.repo.GetDepartmentQuota(team.DepartmentID)
+	if err != nil {
+		return err
+	}
+
+	if quota.AdvisorProposalLimit > 0 {
+		taken, err := s.repo.CountAdvisorProposals(advisorID, academicYear, excludeProposalID)
+		if err != nil {
+			return err
+		}
+		if taken >= int64(quota.AdvisorProposalLimit) {
+			return ErrAdvisorQuotaReached
+		}
+	}
+
+	if quota.TeamLimit > 0 {
+		supervised, err := s.repo.CountSupervisedTeams(team.DepartmentID, team.AcademicYear, team.ID)
+		if err != nil {
+			return err
+		}
+		if supervised >= int64(quota.TeamLimit) {
+			return ErrTeamQuotaReached
+		}
+	}
+	return nil
+}
+
+// GetConflictDeclaration returns the advisor's declaration for the team, or nil when there is none
+func (s *Service) GetConflictDeclaration(advisorID uint, teamID uint) (*domain.ConflictDeclaration, error) {
+	return s.repo.GetConflictDeclaration(advisorID, teamID)
+}
+
+// checkConflict refuses an advisor who declared a conflict of interest with the proposal's team,
+// unless the department head has overridden it
+func (s *Service) checkConflict(proposal *domain.Proposal, advisorID uint) error {
+	if proposal.TeamID == nil {
+		return nil
+	}
+	declaration, err := s.repo.GetConflictDeclaration(advisorID, *proposal.TeamID)
+	if err != nil {
+		return err
+	}
+	if declaration != nil && declaration.HasConflict && declaration.OverriddenAt == nil {
+		return ErrConflictOfInterest
+	}
+	return nil
+}
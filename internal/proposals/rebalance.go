@@ -0,0 +1,242 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/settings"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var ErrRebalanceMoveInvalid = errors.New("rebalance move is no longer valid")
+
+// AdvisorLoad is an advisor's share of the current cohort against their capacity
+type AdvisorLoad struct {
+	AdvisorID  uint   `json:"advisor_id"`
+	Name       string `json:"name"`
+	Load       int64  `json:"load"`
+	Capacity   int    `json:"capacity"`
+	Overloaded bool   `json:"overloaded"`
+}
+
+// RebalanceMove proposes handing one unreviewed proposal to another advisor
+type RebalanceMove struct {
+	ProposalID    uint   `json:"proposal_id" binding:"required"`
+	Title         string `json:"title,omitempty"`
+	FromAdvisorID uint   `json:"from_advisor_id,omitempty"`
+	ToAdvisorID   uint   `json:"to_advisor_id" binding:"required"`
+}
+
+// RebalanceSuggestions lists every advisor's load and the moves that would bring overloaded
+// advisors back within capacity. Moves can be sent back as-is to ApplyRebalance.
+type RebalanceSuggestions struct {
+	DepartmentID   uint            `json:"department_id"`
+	AcademicYear   string          `json:"academic_year"`
+	CapacitySource string          `json:"capacity_source"` // quota, or setting when the department has none
+	Advisors       []AdvisorLoad   `json:"advisors"`
+	Moves          []RebalanceMove `json:"moves"`
+	Unresolved     int64           `json:"unresolved"` // excess load no underloaded advisor could take
+}
+
+type ApplyRebalanceRequest struct {
+	Moves []RebalanceMove `json:"moves" binding:"required,min=1,dive"`
+}
+
+// departmentLoads returns the department's active advisors with their cohort load and capacity,
+// the department's advisor quota or else the university's advisors.default_capacity setting
+func (s *Service) departmentLoads(departmentID uint) (string, string, []AdvisorLoad, error) {
+	quota, err := s.repo.GetDepartmentQuota(departmentID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	capacity, source := quota.AdvisorProposalLimit, "quota"
+	if capacity == 0 {
+		universityID := s.repo.GetDepartmentUniversityID(departmentID)
+		capacity, source = s.settings.Int(settings.AdvisorCapacity, universityID), "setting"
+	}
+
+	year := s.repo.GetDepartmentAcademicYear(departmentID)
+	advisors, err := s.repo.GetDepartmentAdvisors(departmentID)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	loads := make([]AdvisorLoad, 0, len(advisors))
+	for _, advisor := range advisors {
+		load, err := s.repo.CountAdvisorProposals(advisor.ID, year, 0)
+		if err != nil {
+			return "", "", nil, err
+		}
+		loads = append(loads, AdvisorLoad{
+			AdvisorID:  advisor.ID,
+			Name:       advisor.Name,
+			Load:       load,
+			Capacity:   capacity,
+			Overloaded: load > int64(capacity),
+		})
+	}
+	return year, source, loads, nil
+}
+
+// GetRebalanceSuggestions finds advisors over capacity and proposes moving their unreviewed
+// proposals, most recently assigned first, to the least-loaded advisors that still have room
+func (s *Service) GetRebalanceSuggestions(departmentID uint) (*RebalanceSuggestions, error) {
+	year, source, loads, err := s.departmentLoads(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	result := &RebalanceSuggestions{
+		DepartmentID:   departmentID,
+		AcademicYear:   year,
+		CapacitySource: source,
+		Advisors:       loads,
+		Moves:          []RebalanceMove{},
+	}
+
+	// projected load after the suggested moves
+	projected := make(map[uint]int64, len(loads))
+	for _, l := range loads {
+		projected[l.AdvisorID] = l.Load
+	}
+
+	for _, from := range loads {
+		if !from.Overloaded {
+			continue
+		}
+		candidates, err := s.repo.GetUnreviewedAdvisorProposals(from.AdvisorID, year)
+		if err != nil {
+			return nil, err
+		}
+		for i := range candidates {
+			if projected[from.AdvisorID] <= int64(from.Capacity) {
+				break
+			}
+			// advisors with an unresolved conflict of interest with the team, or who co-review the
+			// proposal, are never suggested
+			proposal := &candidates[i]
+			to := leastLoadedWithRoom(loads, projected, from.AdvisorID, func(advisorID uint) bool {
+				return s.checkConflict(proposal, advisorID) == nil && checkNotSecondReviewer(proposal, advisorID) == nil
+			})
+			if to == nil {
+				continue // every advisor with room conflicts with this team; try the next proposal
+			}
+			result.Moves = append(result.Moves, RebalanceMove{
+				ProposalID:    candidates[i].ID,
+				Title:         latestTitle(&candidates[i]),
+				FromAdvisorID: from.AdvisorID,
+				ToAdvisorID:   to.AdvisorID,
+			})
+			projected[from.AdvisorID]--
+			projected[to.AdvisorID]++
+		}
+		if excess := projected[from.AdvisorID] - int64(from.Capacity); excess > 0 {
+			result.Unresolved += excess
+		}
+	}
+	return result, nil
+}
+
+// leastLoadedWithRoom picks the advisor with the lowest projected load below capacity, lowest ID on ties
+func leastLoadedWithRoom(loads []AdvisorLoad, projected map[uint]int64, exclude uint, eligible func(advisorID uint) bool) *AdvisorLoad {
+	var candidates []AdvisorLoad
+	for _, l := range loads {
+		if l.AdvisorID != exclude && projected[l.AdvisorID] < int64(l.Capacity) && eligible(l.AdvisorID) {
+			candidates = append(candidates, l)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if projected[candidates[i].AdvisorID] != projected[candidates[j].AdvisorID] {
+			return projected[candidates[i].AdvisorID] < projected[candidates[j].AdvisorID]
+		}
+		return candidates[i].AdvisorID < candidates[j].AdvisorID
+	})
+	return &candidates[0]
+}
+
+// ApplyRebalance reassigns all the given proposals in one transaction. Every move is checked
+// again first: the proposal must still be unreviewed and in the department, and the new advisor
+// must be an active advisor of the department with room left and no unresolved conflict of
+// interest with the team. If any move fails nothing changes.
+func (s *Service) ApplyRebalance(departmentID uint, req ApplyRebalanceRequest) (*RebalanceSuggestions, error) {
+	year, _, loads, err := s.departmentLoads(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]AdvisorLoad, len(loads))
+	projected := make(map[uint]int64, len(loads))
+	for _, l := range loads {
+		byID[l.AdvisorID] = l
+		projected[l.AdvisorID] = l.Load
+	}
+
+	moves := make(map[uint]uint, len(req.Moves))
+	var proposals []*domain.Proposal
+	for _, move := range req.Moves {
+		if _, dup := moves[move.ProposalID]; dup {
+			return nil, fmt.Errorf("%w: proposal %d is listed twice", ErrRebalanceMoveInvalid, move.ProposalID)
+		}
+		proposal, err := s.repo.GetByID(move.ProposalID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: proposal %d not found", ErrRebalanceMoveInvalid, move.ProposalID)
+		}
+		if proposal.Team == nil || proposal.Team.DepartmentID != departmentID || proposal.AdvisorID == nil {
+			return nil, fmt.Errorf("%w: proposal %d has no advisor in this department", ErrRebalanceMoveInvalid, move.ProposalID)
+		}
+		if proposal.Status != enums.ProposalStatusUnderReview || s.repo.HasFeedback(proposal.ID) {
+			return nil, fmt.Errorf("%w: proposal %d has already been reviewed", ErrRebalanceMoveInvalid, move.ProposalID)
+		}
+		target, ok := byID[move.ToAdvisorID]
+		if !ok {
+			return nil, fmt.Errorf("%w: user %d is not an active advisor in this department", ErrRebalanceMoveInvalid, move.ToAdvisorID)
+		}
+		if *proposal.AdvisorID == move.ToAdvisorID {
+			return nil, fmt.Errorf("%w: proposal %d is already assigned to advisor %d", ErrRebalanceMoveInvalid, move.ProposalID, move.ToAdvisorID)
+		}
+		if err := s.checkConflict(proposal, move.ToAdvisorID); err != nil {
+			return nil, fmt.Errorf("%w: advisor %d: %v", ErrRebalanceMoveInvalid, move.ToAdvisorID, err)
+		}
+		if err := checkNotSecondReviewer(proposal, move.ToAdvisorID); err != nil {
+			return nil, fmt.Errorf("%w: advisor %d: %v", ErrRebalanceMoveInvalid, move.ToAdvisorID, err)
+		}
+		if proposal.AcademicYear == year {
+			projected[*proposal.AdvisorID]--
+			projected[move.ToAdvisorID]++
+			if projected[move.ToAdvisorID] > int64(target.Capacity) {
+				return nil, fmt.Errorf("%w: advisor %d would exceed their capacity", ErrRebalanceMoveInvalid, move.ToAdvisorID)
+			}
+		}
+		moves[proposal.ID] = move.ToAdvisorID
+		proposals = append(proposals, proposal)
+	}
+
+	now := time.Now()
+	acceptBy := now.Add(DefaultAcceptWindow)
+	if err := s.repo.ReassignAdvisors(moves, now, acceptBy); err != nil {
+		return nil, err
+	}
+
+	for _, proposal := range proposals {
+		advisorID := moves[proposal.ID]
+		recipients := append([]uint{advisorID}, acceptedMemberIDs(proposal.Team)...)
+		s.bus.Publish(events.Event{
+			Name:       events.ProposalAdvisorAssigned,
+			EntityType: "proposal",
+			EntityID:   proposal.ID,
+			UserIDs:    recipients,
+			Data: map[string]interface{}{
+				"advisor_id":          advisorID,
+				"previous_advisor_id": *proposal.AdvisorID,
+				"title":               latestTitle(proposal),
+				"accept_by":           acceptBy.Format(time.RFC3339),
+			},
+		})
+	}
+
+	return s.GetRebalanceSuggestions(departmentID)
+}
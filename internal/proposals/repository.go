@@ -3,24 +3,144 @@ package proposals
 import (
 	"backend/internal/domain"
 	"backend/pkg/enums"
+	"backend/pkg/settings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type Repository interface {
+	// WithTx returns the repository bound to a unit of work's transaction
+	WithTx(tx *gorm.DB) Repository
+
 	Create(proposal *domain.Proposal) error
 	GetByID(id uint) (*domain.Proposal, error)
-	GetAll(filters map[string]interface{}) ([]domain.Proposal, error)
+	// GetByIDs loads the proposals with the same associations as GetByID; missing ones are left out
+	GetByIDs(ids []uint) ([]domain.Proposal, error)
+	GetAll(filters map[string]interface{}) ([]domain.Proposal, int64, error)
 	Update(proposal *domain.Proposal) error
+	SetStatus(id uint, status enums.ProposalStatus) error
 	Delete(id uint) error
 	
 	// Versioning
 	CreateVersion(version *domain.ProposalVersion) error
+	ApproveVersion(versionID uint) error
 	GetVersionsByProposalID(proposalID uint) ([]domain.ProposalVersion, error)
 	GetLatestVersion(proposalID uint) (*domain.ProposalVersion, error)
 	GetFirstVersion(proposalID uint) (*domain.ProposalVersion, error)
+	// ReplaceVersionFile saves the version's new attachment and, when set, the archived old one together
+	ReplaceVersionFile(version *domain.ProposalVersion, archived *domain.ProposalVersionFile) error
+	GetVersionFiles(proposalID uint, versionID uint) ([]domain.ProposalVersionFile, error)
+	GetLastReviewedVersionID(proposalID uint) (uint, error)
+	IsSecondOpinionReviewer(proposalID uint, userID uint) bool
+	// TeamHasProposalIn reports whether the team has an unarchived proposal in one of the statuses
+	TeamHasProposalIn(teamID uint, statuses []enums.ProposalStatus) (bool, error)
+
+	// AssignAdvisor gives the proposal to the advisor, who has until acceptBy to accept or decline it
+	AssignAdvisor(proposalID uint, advisorID uint, assignedAt time.Time, acceptBy time.Time) error
+	// SetSecondReviewer assigns the proposal's co-reviewer, or removes it when reviewerID is nil
+	SetSecondReviewer(proposalID uint, reviewerID *uint, assignedAt *time.Time) error
+
+	// Quotas
+	GetDepartmentQuota(departmentID uint) (*domain.DepartmentQuota, error)
+	SaveDepartmentQuota(quota *domain.DepartmentQuota) error
+	GetDepartmentAcademicYear(departmentID uint) string
+	GetDepartmentUniversityID(departmentID uint) uint
+	CountAdvisorProposals(advisorID uint, academicYear string, excludeProposalID uint) (int64, error)
+	CountSupervisedTeams(departmentID uint, academicYear string, excludeTeamID uint) (int64, error)
+	// GetConflictDeclaration returns the advisor's declaration for the team, or nil when there is none
+	GetConflictDeclaration(advisorID uint, teamID uint) (*domain.ConflictDeclaration, error)
+
+	// Validation rules
+	GetProposalRules(departmentID uint) (*domain.ProposalRules, error)
+	SaveProposalRules(rules *domain.ProposalRules) error
+
+	// Submission windows
+	GetSubmissionWindow(departmentID uint) (*domain.SubmissionWindow, error)
+	SaveSubmissionWindow(window *domain.SubmissionWindow) error
+	GetUnreportedSubmissionWindows() ([]domain.SubmissionWindow, error)
+	SetSubmissionReminders(departmentID uint, sent int) error
+	MarkSubmissionWindowReported(departmentID uint, at time.Time) error
+	GetTeamsWithoutSubmission(departmentID uint, academicYear string) ([]domain.Team, error)
+	GetDepartmentAdminIDs(departmentID uint) ([]uint, error)
+
+	// Deadline extensions
+	GetTeam(teamID uint) (*domain.Team, error)
+	// GetTeamDraft returns the team's unarchived draft, or nil when it has none
+	GetTeamDraft(teamID uint) (*domain.Proposal, error)
+	TeamHasSubmission(teamID uint) (bool, error)
+	CreateDeadlineExtension(ext *domain.DeadlineExtension) error
+	GetDeadlineExtension(id uint) (*domain.DeadlineExtension, error)
+	GetTeamDeadlineExtensions(teamID uint) ([]domain.DeadlineExtension, error)
+	GetDepartmentDeadlineExtensions(departmentID uint, status string) ([]domain.DeadlineExtension, error)
+	HasPendingDeadlineExtension(teamID uint, academicYear string) (bool, error)
+	DecideDeadlineExtension(ext *domain.DeadlineExtension) (bool, error)
+	GetUser(userID uint) (*domain.User, error)
+	GetExtendedDeadline(teamID uint, academicYear string) (*time.Time, error)
+
+	// Rebalancing
+	GetDepartmentAdvisors(departmentID uint) ([]domain.User, error)
+	// GetAdvisorsWithPendingReviews returns the department's advisors with a proposal waiting for their decision since before the cutoff
+	GetAdvisorsWithPendingReviews(departmentID uint, before time.Time) ([]uint, error)
+	CreateAdvisorMessage(message *domain.AdvisorMessage) error
+	GetUnreviewedAdvisorProposals(advisorID uint, academicYear string) ([]domain.Proposal, error)
+	HasFeedback(proposalID uint) bool
+	ReassignAdvisors(moves map[uint]uint, assignedAt time.Time, acceptBy time.Time) error
+
+	// Advisor acceptance
+	AcceptAssignment(proposalID uint, advisorID uint, at time.Time) (bool, error)
+	// DeclineAssignment takes the proposal and its team back from the advisor while they have not reviewed it
+	DeclineAssignment(proposalID uint, advisorID uint) (bool, error)
+	GetOverdueAssignments(now time.Time) ([]domain.Proposal, error)
+	MarkAssignmentEscalated(proposalID uint, advisorID uint, at time.Time) (bool, error)
+
+	// Archiving
+	ArchiveCohort(academicYear string, departmentID uint, archivedAt time.Time) (int64, int64, error)
 
-	AssignAdvisor(proposalID uint, advisorID uint) error 
+	// Timeline
+	GetPhases(proposalID uint) ([]domain.TimelinePhase, error)
+	GetPhase(proposalID, phaseID uint) (*domain.TimelinePhase, error)
+	CreatePhase(phase *domain.TimelinePhase) error
+	UpdatePhase(phase *domain.TimelinePhase) error
+	DeletePhase(phase *domain.TimelinePhase) error
+	GetUserUniversity(userID uint) (*domain.University, error)
+
+	// Version file retention
+	GetUniversity(universityID uint) (*domain.University, error)
+	SetVersionFilesKept(universityID uint, kept int) error
+	GetRetentionCandidates(restoredBefore time.Time, limit int) ([]RetentionCandidate, error)
+	GetVersionFile(versionID uint, fileID uint) (*domain.ProposalVersionFile, error)
+	MarkFileArchived(kind string, id uint, archivePath string, at time.Time) error
+	MarkFileRestored(kind string, id uint, at time.Time) error
+
+	// Abandonment
+	SetAbandonAfterDays(universityID uint, days int) error
+	GetAbandonmentCandidates() ([]RevisionActivity, error)
+	GetRevisionActivity(proposalID uint) (*RevisionActivity, error)
+	SetAbandonWarnings(proposalID uint, sent int, at time.Time) error
+	MarkAbandoned(proposalID uint, at time.Time) (bool, error)
+	Reopen(proposalID uint, at time.Time) (bool, error)
+
+	// Archive comparison
+	GetArchivedProjects(departmentID uint, ids []uint) ([]domain.Project, error)
+	ReplaceArchiveMatches(proposalID uint, matches []domain.ProposalArchiveMatch, checkedAt time.Time) error
+	GetArchiveMatches(proposalID uint) ([]domain.ProposalArchiveMatch, error)
+
+	// Workload history
+	CaptureWorkloadSnapshots(weekStart time.Time, capacityKey settings.IntKey, defaultCapacity int, now time.Time) (int64, error)
+	GetAdvisor(advisorID uint) (*domain.User, error)
+	GetWorkloadSnapshots(advisorID uint, since time.Time) ([]domain.AdvisorWorkloadSnapshot, error)
+	GetDepartmentWorkloadAverages(departmentID uint, since time.Time) ([]WeeklyAverage, error)
+
+	// Continuation
+	GetProject(projectID uint) (*domain.Project, error)
+	HasActiveContinuation(projectID uint) (bool, error)
+
+	// Document text
+	SaveVersionText(text *domain.ProposalVersionText) error
+	DeleteVersionText(versionID uint) error
+	// GetVersionsWithoutText returns PDF versions whose current file has not been extracted yet
+	GetVersionsWithoutText(limit int) ([]domain.ProposalVersion, error)
 }
 
 type repository struct {
@@ -31,6 +151,10 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
 func (r *repository) Create(proposal *domain.Proposal) error {
 	return r.db.Create(proposal).Error
 }
@@ -53,33 +177,100 @@ func (r *repository) GetByID(id uint) (*domain.Proposal, error) {
 	return &proposal, nil
 }
 
-func (r *repository) GetAll(filters map[string]interface{}) ([]domain.Proposal, error) {
+func (r *repository) GetByIDs(ids []uint) ([]domain.Proposal, error) {
 	var proposals []domain.Proposal
-	query := r.db.Preload("Team").
-        Preload("Team.Department").
-        Preload("Team.Creator").
-		Preload("Advisor").
-		Preload("Team.Members.User").  // To count team size
-        Preload("Versions", func(db *gorm.DB) *gorm.DB {
-            return db.Order("version_number DESC") // Get latest version first
-        })
+	err := r.db.
+		Preload("Team").
+		Preload("Team.Members.User").
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("id IN ?", ids).
+		Find(&proposals).Error
+	return proposals, err
+}
+
+// latestTitleSQL selects a proposal's current title for sorting
+const latestTitleSQL = "(SELECT pv.title FROM proposal_versions pv WHERE pv.proposal_id = proposals.id ORDER BY pv.version_number DESC LIMIT 1)"
+
+func (r *repository) GetAll(filters map[string]interface{}) ([]domain.Proposal, int64, error) {
+	var proposals []domain.Proposal
+	var total int64
+
+	query := r.db.Model(&domain.Proposal{})
 
 	if status, ok := filters["status"]; ok {
-		query = query.Where("status = ?", status)
+		query = query.Where("proposals.status = ?", status)
 	}
 	if departmentID, ok := filters["department_id"]; ok {
-		query = query.Joins("JOIN teams ON proposals.team_id = teams.id").
-			Where("teams.department_id = ?", departmentID)
+		query = query.Where("proposals.team_id IN (SELECT id FROM teams WHERE department_id = ?)", departmentID)
+	}
+	if advisorID, ok := filters["advisor_id"]; ok {
+		query = query.Where("proposals.advisor_id = ?", advisorID)
+	}
+	if userID, ok := filters["user_id"]; ok {
+		// Creators see their drafts; other team members only once submitted
+		query = query.Where(
+			"proposals.created_by = ? OR (proposals.status <> ? AND proposals.team_id IN (SELECT team_id FROM team_members WHERE user_id = ?))",
+			userID, enums.ProposalStatusDraft, userID,
+		)
+	}
+	if search, ok := filters["team_search"].(string); ok && search != "" {
+		query = query.Where("proposals.team_id IN (SELECT id FROM teams WHERE name ILIKE ?)", "%"+search+"%")
+	}
+	// Archived proposals are hidden unless explicitly requested
+	if archived, ok := filters["archived"]; ok {
+		query = query.Where("proposals.is_archived = ?", archived)
+	} else {
+		query = query.Where("proposals.is_archived = ?", false)
 	}
 
-	err := query.Find(&proposals).Error
-	return proposals, err
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Sorting (whitelisted columns only)
+	direction := "DESC"
+	if order, ok := filters["order"].(string); ok && order == "asc" {
+		direction = "ASC"
+	}
+	sortBy := "proposals.created_at"
+	if sort, ok := filters["sort"].(string); ok {
+		switch sort {
+		case "updated_at":
+			sortBy = "proposals.updated_at"
+		case "title":
+			sortBy = latestTitleSQL
+		}
+	}
+	query = query.Order(sortBy + " " + direction).Order("proposals.id " + direction)
+
+	if page, ok := filters["page"].(int); ok {
+		if limit, ok := filters["limit"].(int); ok {
+			query = query.Offset((page - 1) * limit).Limit(limit)
+		}
+	}
+
+	err := query.Preload("Team").
+		Preload("Team.Department").
+		Preload("Team.Creator").
+		Preload("Advisor").
+		Preload("Team.Members.User"). // To count team size
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC") // Get latest version first
+		}).
+		Find(&proposals).Error
+	return proposals, total, err
 }
 
 func (r *repository) Update(proposal *domain.Proposal) error {
 	return r.db.Omit("Team", "Versions", "CurrentVersion", "Feedback").Save(proposal).Error
 }
 
+func (r *repository) SetStatus(id uint, status enums.ProposalStatus) error {
+	return r.db.Model(&domain.Proposal{}).Where("id = ?", id).Update("status", status).Error
+}
+
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&domain.Proposal{}, id).Error
 }
@@ -88,6 +279,10 @@ func (r *repository) CreateVersion(version *domain.ProposalVersion) error {
 	return r.db.Create(version).Error
 }
 
+func (r *repository) ApproveVersion(versionID uint) error {
+	return r.db.Model(&domain.ProposalVersion{}).Where("id = ?", versionID).Update("is_approved", true).Error
+}
+
 func (r *repository) GetVersionsByProposalID(proposalID uint) ([]domain.ProposalVersion, error) {
 	var versions []domain.ProposalVersion
 	err := r.db.Where("proposal_id = ?", proposalID).Order("version_number DESC").Find(&versions).Error
@@ -106,15 +301,66 @@ func (r *repository) GetFirstVersion(proposalID uint) (*domain.ProposalVersion,
 	return &version, err
 }
 
-func (r *repository) AssignAdvisor(proposalID uint, advisorID uint) error {
+func (r *repository) ReplaceVersionFile(version *domain.ProposalVersion, archived *domain.ProposalVersionFile) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if archived != nil {
+			if err := tx.Create(archived).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(version).Error
+	})
+}
+
+func (r *repository) GetVersionFiles(proposalID uint, versionID uint) ([]domain.ProposalVersionFile, error) {
+	var archived []domain.ProposalVersionFile
+	err := r.db.Where("proposal_id = ? AND version_id = ?", proposalID, versionID).
+		Order("replaced_at DESC").
+		Find(&archived).Error
+	return archived, err
+}
+
+// GetLastReviewedVersionID is the version the most recent feedback on the proposal was given on
+func (r *repository) GetLastReviewedVersionID(proposalID uint) (uint, error) {
+	var feedback domain.Feedback
+	err := r.db.Select("proposal_version_id").
+		Where("proposal_id = ?", proposalID).
+		Order("created_at DESC").
+		First(&feedback).Error
+	return feedback.ProposalVersionID, err
+}
+
+// IsSecondOpinionReviewer reports whether the user has an open second opinion request on the proposal
+func (r *repository) IsSecondOpinionReviewer(proposalID uint, userID uint) bool {
+	var count int64
+	r.db.Model(&domain.SecondOpinion{}).
+		Where("proposal_id = ? AND reviewer_id = ? AND status = ?", proposalID, userID, domain.SecondOpinionPending).
+		Count(&count)
+	return count > 0
+}
+
+func (r *repository) TeamHasProposalIn(teamID uint, statuses []enums.ProposalStatus) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Proposal{}).
+		Where("team_id = ? AND status IN ? AND is_archived = ?", teamID, statuses, false).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) SetSecondReviewer(proposalID uint, reviewerID *uint, assignedAt *time.Time) error {
+	return r.db.Model(&domain.Proposal{}).
+		Where("id = ?", proposalID).
+		Updates(map[string]interface{}{"second_reviewer_id": reviewerID, "second_reviewer_assigned_at": assignedAt}).Error
+}
+
+func (r *repository) AssignAdvisor(proposalID uint, advisorID uint, assignedAt time.Time, acceptBy time.Time) error {
     return r.db.Transaction(func(tx *gorm.DB) error {
-        // 1. Update Proposal Status
+        // 1. Update Proposal Status; a new assignment starts a new acceptance window
         if err := tx.Model(&domain.Proposal{}).
             Where("id = ?", proposalID).
-            Updates(map[string]interface{}{
-                "advisor_id": advisorID,
-                "status":     enums.ProposalStatusUnderReview,
-            }).Error; err != nil {
+            Updates(assignmentUpdates(advisorID, assignedAt, acceptBy, map[string]interface{}{
+                "status": enums.ProposalStatusUnderReview,
+            })).Error; err != nil {
             return err
         }
 
@@ -130,4 +376,798 @@ func (r *repository) AssignAdvisor(proposalID uint, advisorID uint) error {
         }
         return nil
     })
-}
\ No newline at end of file
+}
+
+// assignmentUpdates sets the advisor of a proposal and starts their acceptance window. A
+// co-reviewer who becomes the advisor stops being the co-reviewer.
+func assignmentUpdates(advisorID uint, assignedAt time.Time, acceptBy time.Time, extra map[string]interface{}) map[string]interface{} {
+	updates := map[string]interface{}{
+		"advisor_id":           advisorID,
+		"advisor_assigned_at":  assignedAt,
+		"advisor_accept_by":    acceptBy,
+		"advisor_accepted_at":  nil,
+		"advisor_escalated_at": nil,
+	}
+	for key, value := range clearSecondReviewer(advisorID) {
+		updates[key] = value
+	}
+	for key, value := range extra {
+		updates[key] = value
+	}
+	return updates
+}
+
+// clearSecondReviewer unsets the co-reviewer when it is advisorID, the proposal's new advisor
+func clearSecondReviewer(advisorID uint) map[string]interface{} {
+	return map[string]interface{}{
+		"second_reviewer_id":          gorm.Expr("NULLIF(second_reviewer_id, ?)", advisorID),
+		"second_reviewer_assigned_at": gorm.Expr("CASE WHEN second_reviewer_id = ? THEN NULL ELSE second_reviewer_assigned_at END", advisorID),
+	}
+}
+
+// GetDepartmentQuota returns the department's quota, or an unlimited one when none is configured
+func (r *repository) GetDepartmentQuota(departmentID uint) (*domain.DepartmentQuota, error) {
+	quota := domain.DepartmentQuota{DepartmentID: departmentID}
+	err := r.db.Where("department_id = ?", departmentID).Limit(1).Find(&quota).Error
+	return &quota, err
+}
+
+func (r *repository) GetConflictDeclaration(advisorID uint, teamID uint) (*domain.ConflictDeclaration, error) {
+	var declarations []domain.ConflictDeclaration
+	if err := r.db.Where("advisor_id = ? AND team_id = ?", advisorID, teamID).Limit(1).Find(&declarations).Error; err != nil {
+		return nil, err
+	}
+	if len(declarations) == 0 {
+		return nil, nil
+	}
+	return &declarations[0], nil
+}
+
+func (r *repository) SaveDepartmentQuota(quota *domain.DepartmentQuota) error {
+	return r.db.Save(quota).Error
+}
+
+// GetProposalRules returns the department's proposal rules, or nil when it has not configured any
+func (r *repository) GetProposalRules(departmentID uint) (*domain.ProposalRules, error) {
+	var rules []domain.ProposalRules
+	if err := r.db.Where("department_id = ?", departmentID).Limit(1).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &rules[0], nil
+}
+
+func (r *repository) SaveProposalRules(rules *domain.ProposalRules) error {
+	return r.db.Save(rules).Error
+}
+
+// GetSubmissionWindow returns the department's submission window, or nil when none is set
+func (r *repository) GetSubmissionWindow(departmentID uint) (*domain.SubmissionWindow, error) {
+	var windows []domain.SubmissionWindow
+	if err := r.db.Where("department_id = ?", departmentID).Limit(1).Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	return &windows[0], nil
+}
+
+func (r *repository) SaveSubmissionWindow(window *domain.SubmissionWindow) error {
+	return r.db.Save(window).Error
+}
+
+// GetUnreportedSubmissionWindows returns the windows still open or closed without a final report
+func (r *repository) GetUnreportedSubmissionWindows() ([]domain.SubmissionWindow, error) {
+	var windows []domain.SubmissionWindow
+	err := r.db.Where("closed_reported_at IS NULL").Find(&windows).Error
+	return windows, err
+}
+
+func (r *repository) SetSubmissionReminders(departmentID uint, sent int) error {
+	return r.db.Model(&domain.SubmissionWindow{}).Where("department_id = ?", departmentID).Update("reminders_sent", sent).Error
+}
+
+func (r *repository) MarkSubmissionWindowReported(departmentID uint, at time.Time) error {
+	return r.db.Model(&domain.SubmissionWindow{}).Where("department_id = ?", departmentID).Update("closed_reported_at", at).Error
+}
+
+// GetTeamsWithoutSubmission lists the cohort's active teams that have not submitted any proposal
+func (r *repository) GetTeamsWithoutSubmission(departmentID uint, academicYear string) ([]domain.Team, error) {
+	var teams []domain.Team
+	err := r.db.Preload("Members").
+		Where("department_id = ? AND academic_year = ? AND is_archived = ?", departmentID, academicYear, false).
+		Where("NOT EXISTS (SELECT 1 FROM proposals WHERE proposals.team_id = teams.id AND proposals.status <> ?)", enums.ProposalStatusDraft).
+		Order("name ASC").
+		Find(&teams).Error
+	return teams, err
+}
+
+func (r *repository) GetDepartmentAdminIDs(departmentID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.User{}).
+		Where("role = ? AND department_id = ? AND is_active = ?", enums.RoleAdmin, departmentID, true).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) GetTeam(teamID uint) (*domain.Team, error) {
+	var team domain.Team
+	if err := r.db.Preload("Members").First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *repository) GetTeamDraft(teamID uint) (*domain.Proposal, error) {
+	var drafts []domain.Proposal
+	err := r.db.
+		Preload("Team").
+		Preload("Team.Members.User").
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("team_id = ? AND status = ? AND is_archived = ?", teamID, enums.ProposalStatusDraft, false).
+		Order("created_at DESC").
+		Limit(1).Find(&drafts).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(drafts) == 0 {
+		return nil, nil
+	}
+	return &drafts[0], nil
+}
+
+// TeamHasSubmission reports whether any of the team's proposals has left draft
+func (r *repository) TeamHasSubmission(teamID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Proposal{}).
+		Where("team_id = ? AND status <> ?", teamID, enums.ProposalStatusDraft).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) CreateDeadlineExtension(ext *domain.DeadlineExtension) error {
+	return r.db.Create(ext).Error
+}
+
+func (r *repository) GetDeadlineExtension(id uint) (*domain.DeadlineExtension, error) {
+	var ext domain.DeadlineExtension
+	if err := r.db.Preload("Team.Members").Preload("Requester").First(&ext, id).Error; err != nil {
+		return nil, err
+	}
+	return &ext, nil
+}
+
+func (r *repository) GetUser(userID uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *repository) GetTeamDeadlineExtensions(teamID uint) ([]domain.DeadlineExtension, error) {
+	var exts []domain.DeadlineExtension
+	err := r.db.Preload("Requester").Where("team_id = ?", teamID).Order("created_at DESC").Find(&exts).Error
+	return exts, err
+}
+
+// GetDepartmentDeadlineExtensions lists the department's requests, pending ones first
+func (r *repository) GetDepartmentDeadlineExtensions(departmentID uint, status string) ([]domain.DeadlineExtension, error) {
+	var exts []domain.DeadlineExtension
+	query := r.db.Preload("Team").Preload("Requester").Where("department_id = ?", departmentID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("CASE WHEN status = 'pending' THEN 0 ELSE 1 END, created_at DESC").Find(&exts).Error
+	return exts, err
+}
+
+func (r *repository) HasPendingDeadlineExtension(teamID uint, academicYear string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.DeadlineExtension{}).
+		Where("team_id = ? AND academic_year = ? AND status = ?", teamID, academicYear, enums.DeadlineExtensionPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DecideDeadlineExtension records the decision unless the request was decided in the meantime
+func (r *repository) DecideDeadlineExtension(ext *domain.DeadlineExtension) (bool, error) {
+	result := r.db.Model(&domain.DeadlineExtension{}).
+		Where("id = ? AND status = ?", ext.ID, enums.DeadlineExtensionPending).
+		Updates(map[string]interface{}{
+			"status":         ext.Status,
+			"extended_until": ext.ExtendedUntil,
+			"reviewed_by":    ext.ReviewedBy,
+			"review_comment": ext.ReviewComment,
+			"reviewed_at":    ext.ReviewedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetExtendedDeadline is the latest deadline approved for the team in the cohort, or nil when none was
+func (r *repository) GetExtendedDeadline(teamID uint, academicYear string) (*time.Time, error) {
+	var exts []domain.DeadlineExtension
+	err := r.db.Where("team_id = ? AND academic_year = ? AND status = ?", teamID, academicYear, enums.DeadlineExtensionApproved).
+		Order("extended_until DESC").
+		Limit(1).
+		Find(&exts).Error
+	if err != nil || len(exts) == 0 {
+		return nil, err
+	}
+	return exts[0].ExtendedUntil, nil
+}
+
+// GetDepartmentAcademicYear is the current academic year of the department's university
+func (r *repository) GetDepartmentAcademicYear(departmentID uint) string {
+	var year string
+	r.db.Table("departments").
+		Select("universities.academic_year").
+		Joins("JOIN universities ON universities.id = departments.university_id").
+		Where("departments.id = ?", departmentID).
+		Scan(&year)
+	return year
+}
+
+// GetDepartmentUniversityID is the university the department belongs to; 0 when unknown
+func (r *repository) GetDepartmentUniversityID(departmentID uint) uint {
+	var universityID uint
+	r.db.Model(&domain.Department{}).
+		Select("university_id").
+		Where("id = ?", departmentID).
+		Scan(&universityID)
+	return universityID
+}
+
+// CountAdvisorProposals counts the cohort's proposals assigned to the advisor, other than excludeProposalID
+func (r *repository) CountAdvisorProposals(advisorID uint, academicYear string, excludeProposalID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Proposal{}).
+		Where("advisor_id = ? AND academic_year = ? AND id <> ?", advisorID, academicYear, excludeProposalID).
+		Count(&count).Error
+	return count, err
+}
+
+// CountSupervisedTeams counts the department's unarchived teams of the cohort that have an advisor, other than excludeTeamID
+func (r *repository) CountSupervisedTeams(departmentID uint, academicYear string, excludeTeamID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Team{}).
+		Where("department_id = ? AND academic_year = ? AND advisor_id IS NOT NULL AND is_archived = ? AND id <> ?",
+			departmentID, academicYear, false, excludeTeamID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetDepartmentAdvisors returns the department's active advisors
+func (r *repository) GetDepartmentAdvisors(departmentID uint) ([]domain.User, error) {
+	var advisors []domain.User
+	err := r.db.Where("department_id = ? AND role = ? AND is_active = ?", departmentID, enums.RoleAdvisor, true).
+		Order("id").
+		Find(&advisors).Error
+	return advisors, err
+}
+
+func (r *repository) GetAdvisorsWithPendingReviews(departmentID uint, before time.Time) ([]uint, error) {
+	// A proposal waits from its assignment or its latest version, whichever came last; updated_at
+	// moves with every unrelated change and would keep resetting the wait
+	var ids []uint
+	err := r.db.Model(&domain.Proposal{}).
+		Joins("JOIN users ON users.id = proposals.advisor_id").
+		Where("users.department_id = ? AND proposals.is_archived = ? AND proposals.status IN ?",
+			departmentID, false, []enums.ProposalStatus{enums.ProposalStatusSubmitted, enums.ProposalStatusUnderReview}).
+		Where(`COALESCE(GREATEST(proposals.advisor_assigned_at,
+			(SELECT MAX(proposal_versions.created_at) FROM proposal_versions WHERE proposal_versions.proposal_id = proposals.id)),
+			proposals.created_at) < ?`, before).
+		Distinct().
+		Pluck("proposals.advisor_id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) CreateAdvisorMessage(message *domain.AdvisorMessage) error {
+	return r.db.Create(message).Error
+}
+
+// GetUnreviewedAdvisorProposals returns the advisor's unarchived proposals of the cohort that are
+// waiting for their first feedback, most recently assigned first
+func (r *repository) GetUnreviewedAdvisorProposals(advisorID uint, academicYear string) ([]domain.Proposal, error) {
+	var proposals []domain.Proposal
+	err := r.db.
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("advisor_id = ? AND academic_year = ? AND status = ? AND is_archived = ?",
+			advisorID, academicYear, enums.ProposalStatusUnderReview, false).
+		Where("NOT EXISTS (SELECT 1 FROM feedbacks WHERE feedbacks.proposal_id = proposals.id)").
+		Order("updated_at DESC").
+		Find(&proposals).Error
+	return proposals, err
+}
+
+func (r *repository) HasFeedback(proposalID uint) bool {
+	var count int64
+	r.db.Model(&domain.Feedback{}).Where("proposal_id = ?", proposalID).Count(&count)
+	return count > 0
+}
+
+// ReassignAdvisors moves each proposal (key) and its team to the new advisor (value) in one transaction
+func (r *repository) ReassignAdvisors(moves map[uint]uint, assignedAt time.Time, acceptBy time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for proposalID, advisorID := range moves {
+			var p domain.Proposal
+			if err := tx.First(&p, proposalID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&p).Updates(assignmentUpdates(advisorID, assignedAt, acceptBy, nil)).Error; err != nil {
+				return err
+			}
+			if p.TeamID != nil {
+				if err := tx.Model(&domain.Team{}).
+					Where("id = ?", *p.TeamID).
+					Update("advisor_id", advisorID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ArchiveCohort archives a department's proposals from an academic year, the projects created from them
+// and the teams formed in that year. Returns the number of proposals and projects archived.
+func (r *repository) ArchiveCohort(academicYear string, departmentID uint, archivedAt time.Time) (int64, int64, error) {
+	var proposalCount, projectCount int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		cohort := tx.Model(&domain.Proposal{}).
+			Select("proposals.id").
+			Joins("JOIN teams ON proposals.team_id = teams.id").
+			Where("proposals.academic_year = ? AND teams.department_id = ? AND proposals.is_archived = ?", academicYear, departmentID, false)
+
+		var ids []uint
+		if err := cohort.Pluck("proposals.id", &ids).Error; err != nil {
+			return err
+		}
+
+		archive := map[string]interface{}{"is_archived": true, "archived_at": archivedAt}
+
+		// The cohort's teams stop counting as active; their memberships stay as history
+		if err := tx.Model(&domain.Team{}).
+			Where("academic_year = ? AND department_id = ? AND is_archived = ?", academicYear, departmentID, false).
+			Updates(archive).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		result := tx.Model(&domain.Proposal{}).Where("id IN ?", ids).Updates(archive)
+		if result.Error != nil {
+			return result.Error
+		}
+		proposalCount = result.RowsAffected
+
+		result = tx.Model(&domain.Project{}).Where("proposal_id IN ? AND is_archived = ?", ids, false).Updates(archive)
+		if result.Error != nil {
+			return result.Error
+		}
+		projectCount = result.RowsAffected
+		return nil
+	})
+
+	return proposalCount, projectCount, err
+}
+
+func (r *repository) GetPhases(proposalID uint) ([]domain.TimelinePhase, error) {
+	var phases []domain.TimelinePhase
+	err := r.db.Where("proposal_id = ?", proposalID).Order("position ASC, start_date ASC, id ASC").Find(&phases).Error
+	return phases, err
+}
+
+func (r *repository) GetPhase(proposalID, phaseID uint) (*domain.TimelinePhase, error) {
+	var phase domain.TimelinePhase
+	err := r.db.Where("id = ? AND proposal_id = ?", phaseID, proposalID).First(&phase).Error
+	if err != nil {
+		return nil, err
+	}
+	return &phase, nil
+}
+
+func (r *repository) CreatePhase(phase *domain.TimelinePhase) error {
+	return r.db.Create(phase).Error
+}
+
+func (r *repository) UpdatePhase(phase *domain.TimelinePhase) error {
+	return r.db.Save(phase).Error
+}
+
+// DeletePhase removes a phase and detaches phases that depended on it
+func (r *repository) DeletePhase(phase *domain.TimelinePhase) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.TimelinePhase{}).
+			Where("depends_on_id = ?", phase.ID).
+			Update("depends_on_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(phase).Error
+	})
+}
+
+func (r *repository) GetUserUniversity(userID uint) (*domain.University, error) {
+	var university domain.University
+	err := r.db.Joins("JOIN users ON users.university_id = universities.id").
+		Where("users.id = ?", userID).
+		First(&university).Error
+	if err != nil {
+		return nil, err
+	}
+	return &university, nil
+}
+
+func (r *repository) GetUniversity(universityID uint) (*domain.University, error) {
+	var university domain.University
+	if err := r.db.First(&university, universityID).Error; err != nil {
+		return nil, err
+	}
+	return &university, nil
+}
+
+func (r *repository) SetVersionFilesKept(universityID uint, kept int) error {
+	return r.db.Model(&domain.University{}).
+		Where("id = ?", universityID).
+		Update("version_files_kept", kept).Error
+}
+
+// GetRetentionCandidates finds the files of proposal versions older than the latest ones their
+// university keeps: the version's own file and the drafts it replaced. Approved versions, files
+// still used by a kept version and files restored after restoredBefore are left alone.
+func (r *repository) GetRetentionCandidates(restoredBefore time.Time, limit int) ([]RetentionCandidate, error) {
+	var candidates []RetentionCandidate
+	err := r.db.Raw(`
+		WITH ranked AS (
+			SELECT pv.id, pv.file_url, pv.is_approved, u.version_files_kept,
+				ROW_NUMBER() OVER (PARTITION BY pv.proposal_id ORDER BY pv.version_number DESC) AS recency
+			FROM proposal_versions pv
+			JOIN proposals p ON p.id = pv.proposal_id
+			JOIN teams t ON t.id = p.team_id
+			JOIN departments d ON d.id = t.department_id
+			JOIN universities u ON u.id = d.university_id
+			WHERE u.version_files_kept > 0
+		)
+		SELECT 'version' AS kind, pv.id, pv.file_url
+		FROM proposal_versions pv
+		JOIN ranked r ON r.id = pv.id
+		WHERE r.recency > r.version_files_kept AND NOT pv.is_approved
+			AND pv.file_url IS NOT NULL AND pv.file_url <> '' AND pv.archived_at IS NULL
+			AND (pv.restored_at IS NULL OR pv.restored_at < ?)
+			AND NOT EXISTS (
+				SELECT 1 FROM ranked k
+				WHERE k.file_url = pv.file_url AND k.id <> pv.id AND (k.recency <= k.version_files_kept OR k.is_approved)
+			)
+		UNION ALL
+		SELECT 'replaced' AS kind, f.id, f.file_url
+		FROM proposal_version_files f
+		JOIN ranked r ON r.id = f.version_id
+		WHERE r.recency > r.version_files_kept AND f.archived_at IS NULL
+			AND (f.restored_at IS NULL OR f.restored_at < ?)
+		ORDER BY id
+		LIMIT ?`,
+		restoredBefore, restoredBefore, limit).
+		Scan(&candidates).Error
+	return candidates, err
+}
+
+func (r *repository) GetVersionFile(versionID uint, fileID uint) (*domain.ProposalVersionFile, error) {
+	var file domain.ProposalVersionFile
+	if err := r.db.Where("id = ? AND version_id = ?", fileID, versionID).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *repository) MarkFileArchived(kind string, id uint, archivePath string, at time.Time) error {
+	return r.db.Table(retentionTable(kind)).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"archived_at": at, "archive_path": archivePath}).Error
+}
+
+func (r *repository) MarkFileRestored(kind string, id uint, at time.Time) error {
+	return r.db.Table(retentionTable(kind)).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"archived_at": nil, "archive_path": "", "restored_at": at}).Error
+}
+
+func retentionTable(kind string) string {
+	if kind == RetentionKindReplaced {
+		return "proposal_version_files"
+	}
+	return "proposal_versions"
+}
+
+func (r *repository) SetAbandonAfterDays(universityID uint, days int) error {
+	return r.db.Model(&domain.University{}).
+		Where("id = ?", universityID).
+		Update("abandon_after_days", days).Error
+}
+
+// revisionActivityQuery measures each proposal's activity since its last revision request: the
+// request itself, versions the team created or saved, and an admin reopening the proposal.
+// GREATEST skips the NULLs of proposals without versions or reopenings.
+const revisionActivityQuery = `
+	SELECT p.id AS proposal_id, u.abandon_after_days, p.abandon_warnings, p.abandon_warned_at,
+		a.revision_requested_at, a.last_edit_at,
+		GREATEST(a.revision_requested_at, a.last_edit_at, p.reopened_at) AS last_activity_at
+	FROM proposals p
+	JOIN teams t ON t.id = p.team_id
+	JOIN departments d ON d.id = t.department_id
+	JOIN universities u ON u.id = d.university_id
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(
+				(SELECT MAX(f.created_at) FROM feedbacks f WHERE f.proposal_id = p.id AND f.decision = ?),
+				p.updated_at) AS revision_requested_at,
+			(SELECT MAX(COALESCE(v.last_saved_at, v.created_at)) FROM proposal_versions v WHERE v.proposal_id = p.id) AS last_edit_at
+	) a
+	WHERE p.is_archived = false`
+
+// GetAbandonmentCandidates returns the proposals awaiting revision in universities that abandon them
+func (r *repository) GetAbandonmentCandidates() ([]RevisionActivity, error) {
+	var rows []RevisionActivity
+	err := r.db.Raw(revisionActivityQuery+` AND p.status = ? AND u.abandon_after_days > 0 ORDER BY p.id`,
+		domain.FeedbackDecisionRevise, enums.ProposalStatusRevisionRequired).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *repository) GetRevisionActivity(proposalID uint) (*RevisionActivity, error) {
+	var rows []RevisionActivity
+	err := r.db.Raw(revisionActivityQuery+` AND p.id = ?`, domain.FeedbackDecisionRevise, proposalID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &rows[0], nil
+}
+
+func (r *repository) SetAbandonWarnings(proposalID uint, sent int, at time.Time) error {
+	return r.db.Model(&domain.Proposal{}).
+		Where("id = ?", proposalID).
+		UpdateColumns(map[string]interface{}{"abandon_warnings": sent, "abandon_warned_at": at}).Error
+}
+
+// MarkAbandoned closes the proposal unless the team resubmitted it meanwhile; false means it was not awaiting revision
+func (r *repository) MarkAbandoned(proposalID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.Proposal{}).
+		Where("id = ? AND status = ?", proposalID, enums.ProposalStatusRevisionRequired).
+		Updates(map[string]interface{}{"status": enums.ProposalStatusAbandoned, "abandoned_at": at})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Reopen puts an abandoned proposal back to awaiting revision; false means it was not abandoned
+func (r *repository) Reopen(proposalID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.Proposal{}).
+		Where("id = ? AND status = ?", proposalID, enums.ProposalStatusAbandoned).
+		Updates(map[string]interface{}{
+			"status":            enums.ProposalStatusRevisionRequired,
+			"reopened_at":       at,
+			"abandoned_at":      nil,
+			"abandon_warnings":  0,
+			"abandon_warned_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetArchivedProjects returns the department's archived projects among ids, with their versions latest first
+func (r *repository) GetArchivedProjects(departmentID uint, ids []uint) ([]domain.Project, error) {
+	var projects []domain.Project
+	if len(ids) == 0 {
+		return projects, nil
+	}
+	err := r.db.
+		Preload("Proposal").
+		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("id IN ? AND department_id = ? AND is_archived = ?", ids, departmentID, true).
+		Find(&projects).Error
+	return projects, err
+}
+
+// ReplaceArchiveMatches swaps the proposal's matches for those of its latest submission
+func (r *repository) ReplaceArchiveMatches(proposalID uint, matches []domain.ProposalArchiveMatch, checkedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("proposal_id = ?", proposalID).Delete(&domain.ProposalArchiveMatch{}).Error; err != nil {
+			return err
+		}
+		if len(matches) > 0 {
+			if err := tx.Create(&matches).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&domain.Proposal{}).
+			Where("id = ?", proposalID).
+			UpdateColumn("archive_checked_at", checkedAt).Error
+	})
+}
+
+func (r *repository) GetArchiveMatches(proposalID uint) ([]domain.ProposalArchiveMatch, error) {
+	var matches []domain.ProposalArchiveMatch
+	err := r.db.Where("proposal_id = ?", proposalID).Order("score DESC").Find(&matches).Error
+	return matches, err
+}
+
+// CaptureWorkloadSnapshots records the week's snapshot of every active advisor that has none yet,
+// so the job can run more often than weekly and after restarts without duplicating weeks. Capacity
+// is the department quota, else the university's override of capacityKey, else defaultCapacity.
+func (r *repository) CaptureWorkloadSnapshots(weekStart time.Time, capacityKey settings.IntKey, defaultCapacity int, now time.Time) (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO advisor_workload_snapshots
+			(advisor_id, week_start, department_id, academic_year, proposals, active_proposals,
+			 pending_reviews, teams, reviews_last_week, capacity, created_at)
+		SELECT u.id, ?, u.department_id, COALESCE(un.academic_year, ''),
+			(SELECT COUNT(*) FROM proposals p WHERE p.advisor_id = u.id AND p.academic_year = COALESCE(un.academic_year, '')),
+			(SELECT COUNT(*) FROM proposals p WHERE p.advisor_id = u.id AND p.is_archived = false AND p.status IN ?),
+			(SELECT COUNT(*) FROM proposals p WHERE p.advisor_id = u.id AND p.is_archived = false AND p.status IN ?),
+			(SELECT COUNT(*) FROM teams t WHERE t.advisor_id = u.id AND t.is_archived = false),
+			(SELECT COUNT(*) FROM feedbacks f WHERE f.reviewer_id = u.id AND f.created_at >= ?),
+			COALESCE(NULLIF(q.advisor_proposal_limit, 0),
+				(SELECT s.value::int FROM settings s WHERE s.key = ? AND s.university_id = d.university_id), ?),
+			?
+		FROM users u
+		LEFT JOIN departments d ON d.id = u.department_id
+		LEFT JOIN universities un ON un.id = d.university_id
+		LEFT JOIN department_quotas q ON q.department_id = u.department_id
+		WHERE u.role = ? AND u.is_active = true AND u.deleted_at IS NULL
+		ON CONFLICT (advisor_id, week_start) DO NOTHING`,
+		weekStart,
+		[]enums.ProposalStatus{enums.ProposalStatusSubmitted, enums.ProposalStatusUnderReview, enums.ProposalStatusRevisionRequired},
+		[]enums.ProposalStatus{enums.ProposalStatusSubmitted, enums.ProposalStatusUnderReview},
+		now.AddDate(0, 0, -7),
+		string(capacityKey),
+		defaultCapacity,
+		now,
+		enums.RoleAdvisor)
+	return result.RowsAffected, result.Error
+}
+
+// GetAdvisor returns the user when they are an advisor, active or not
+func (r *repository) GetAdvisor(advisorID uint) (*domain.User, error) {
+	var advisor domain.User
+	if err := r.db.Where("id = ? AND role = ?", advisorID, enums.RoleAdvisor).First(&advisor).Error; err != nil {
+		return nil, err
+	}
+	return &advisor, nil
+}
+
+func (r *repository) GetWorkloadSnapshots(advisorID uint, since time.Time) ([]domain.AdvisorWorkloadSnapshot, error) {
+	var snapshots []domain.AdvisorWorkloadSnapshot
+	err := r.db.Where("advisor_id = ? AND week_start >= ?", advisorID, since).
+		Order("week_start").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetDepartmentWorkloadAverages averages the cohort load of the department's advisors per week
+func (r *repository) GetDepartmentWorkloadAverages(departmentID uint, since time.Time) ([]WeeklyAverage, error) {
+	var averages []WeeklyAverage
+	err := r.db.Model(&domain.AdvisorWorkloadSnapshot{}).
+		Select("week_start, AVG(proposals) AS average_proposals, COUNT(*) AS advisors").
+		Where("department_id = ? AND week_start >= ?", departmentID, since).
+		Group("week_start").
+		Order("week_start").
+		Scan(&averages).Error
+	return averages, err
+}
+
+// GetProject returns the project with its proposal's versions, latest first
+func (r *repository) GetProject(projectID uint) (*domain.Project, error) {
+	var project domain.Project
+	err := r.db.
+		Preload("Proposal").
+		Preload("Proposal.Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		First(&project, projectID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// HasActiveContinuation reports whether a proposal that is neither rejected nor archived already continues the project
+func (r *repository) HasActiveContinuation(projectID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Proposal{}).
+		Where("continues_project_id = ? AND is_archived = ? AND status <> ?", projectID, false, enums.ProposalStatusRejected).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) AcceptAssignment(proposalID uint, advisorID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.Proposal{}).
+		Where("id = ? AND advisor_id = ? AND advisor_accepted_at IS NULL", proposalID, advisorID).
+		Update("advisor_accepted_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) DeclineAssignment(proposalID uint, advisorID uint) (bool, error) {
+	declined := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var p domain.Proposal
+		if err := tx.First(&p, proposalID).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&domain.Proposal{}).
+			Where("id = ? AND advisor_id = ? AND advisor_accepted_at IS NULL AND status = ?", proposalID, advisorID, enums.ProposalStatusUnderReview).
+			Where("NOT EXISTS (SELECT 1 FROM feedbacks WHERE feedbacks.proposal_id = proposals.id)").
+			Updates(map[string]interface{}{
+				"advisor_id":           nil,
+				"status":               enums.ProposalStatusSubmitted,
+				"advisor_assigned_at":  nil,
+				"advisor_accept_by":    nil,
+				"advisor_escalated_at": nil,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		declined = true
+		if p.TeamID == nil {
+			return nil
+		}
+		return tx.Model(&domain.Team{}).
+			Where("id = ? AND advisor_id = ?", *p.TeamID, advisorID).
+			Update("advisor_id", nil).Error
+	})
+	return declined, err
+}
+
+// GetOverdueAssignments returns the proposals whose advisor let the acceptance deadline pass
+// without accepting or reviewing them, and that were not escalated yet
+func (r *repository) GetOverdueAssignments(now time.Time) ([]domain.Proposal, error) {
+	var proposals []domain.Proposal
+	err := r.db.
+		Preload("Team").
+		Preload("Advisor").
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version_number DESC")
+		}).
+		Where("advisor_id IS NOT NULL AND advisor_accept_by < ? AND advisor_accepted_at IS NULL AND advisor_escalated_at IS NULL", now).
+		Where("status = ? AND is_archived = ?", enums.ProposalStatusUnderReview, false).
+		Where("NOT EXISTS (SELECT 1 FROM feedbacks WHERE feedbacks.proposal_id = proposals.id AND feedbacks.reviewer_id = proposals.advisor_id)").
+		Order("advisor_accept_by ASC").
+		Find(&proposals).Error
+	return proposals, err
+}
+
+func (r *repository) MarkAssignmentEscalated(proposalID uint, advisorID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.Proposal{}).
+		Where("id = ? AND advisor_id = ? AND advisor_accepted_at IS NULL AND advisor_escalated_at IS NULL", proposalID, advisorID).
+		Update("advisor_escalated_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *repository) SaveVersionText(text *domain.ProposalVersionText) error {
+	return r.db.Save(text).Error
+}
+
+func (r *repository) DeleteVersionText(versionID uint) error {
+	return r.db.Where("version_id = ?", versionID).Delete(&domain.ProposalVersionText{}).Error
+}
+
+func (r *repository) GetVersionsWithoutText(limit int) ([]domain.ProposalVersion, error) {
+	var versions []domain.ProposalVersion
+	err := r.db.Where("file_url IS NOT NULL AND file_url <> '' AND archived_at IS NULL").
+		Where("detected_mime = 'application/pdf' OR (COALESCE(detected_mime, '') = '' AND LOWER(file_url) LIKE '%.pdf')").
+		Where("scan_status IS NULL OR scan_status NOT IN ?", enums.QuarantinedScanStatuses).
+		Where(`NOT EXISTS (SELECT 1 FROM proposal_version_texts t
+			WHERE t.version_id = proposal_versions.id AND t.file_hash = proposal_versions.file_hash)`).
+		Order("id").
+		Limit(limit).
+		Find(&versions).Error
+	return versions, err
+}
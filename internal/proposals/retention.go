@@ -0,0 +1,153 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// VersionRetentionInterval is how often old version files are moved to cold storage
+	VersionRetentionInterval = 24 * time.Hour
+	// RestoredFileGracePeriod keeps a restored file on disk this long before it can be archived again
+	RestoredFileGracePeriod = 7 * 24 * time.Hour
+	// MaxVersionFilesKept bounds the retention setting; beyond it the policy would never apply
+	MaxVersionFilesKept = 50
+
+	// retentionBatchSize bounds how many files one run archives
+	retentionBatchSize = 500
+)
+
+const (
+	RetentionKindVersion  = "version"
+	RetentionKindReplaced = "replaced"
+)
+
+var (
+	ErrNoUniversity    = errors.New("your account is not attached to a university")
+	ErrFileNotArchived = errors.New("the file is not in cold storage")
+	ErrFileNotFound    = errors.New("file not found")
+)
+
+// RetentionPolicy is a university's rule for proposal version files. The files of the latest
+// VersionFilesKept versions of each proposal stay in the upload directory; older ones, including
+// drafts they replaced, are compressed into cold storage and can be restored on demand. The
+// approved version's file is never moved. 0 keeps every file.
+type RetentionPolicy struct {
+	UniversityID     uint `json:"university_id"`
+	VersionFilesKept int  `json:"version_files_kept"`
+}
+
+// UpdateRetentionPolicyRequest sets how many versions keep their files on disk
+type UpdateRetentionPolicyRequest struct {
+	VersionFilesKept *int `json:"version_files_kept" binding:"required,min=0" example:"3"`
+}
+
+// RetentionCandidate is a file the retention policy moves to cold storage
+type RetentionCandidate struct {
+	Kind    string // version or replaced
+	ID      uint
+	FileURL string
+}
+
+// GetRetentionPolicy returns the university's retention policy
+func (s *Service) GetRetentionPolicy(universityID uint) (*RetentionPolicy, error) {
+	if universityID == 0 {
+		return nil, ErrNoUniversity
+	}
+	university, err := s.repo.GetUniversity(universityID)
+	if err != nil {
+		return nil, errors.New("university not found")
+	}
+	return &RetentionPolicy{UniversityID: university.ID, VersionFilesKept: university.VersionFilesKept}, nil
+}
+
+// UpdateRetentionPolicy sets the university's retention policy; the next run of the retention job applies it
+func (s *Service) UpdateRetentionPolicy(universityID uint, req UpdateRetentionPolicyRequest) (*RetentionPolicy, error) {
+	if universityID == 0 {
+		return nil, ErrNoUniversity
+	}
+	if *req.VersionFilesKept > MaxVersionFilesKept {
+		return nil, fmt.Errorf("at most %d versions can be kept; use 0 to keep every file", MaxVersionFilesKept)
+	}
+	if _, err := s.repo.GetUniversity(universityID); err != nil {
+		return nil, errors.New("university not found")
+	}
+	if err := s.repo.SetVersionFilesKept(universityID, *req.VersionFilesKept); err != nil {
+		return nil, err
+	}
+	return &RetentionPolicy{UniversityID: universityID, VersionFilesKept: *req.VersionFilesKept}, nil
+}
+
+// ApplyVersionRetention moves the files the universities' policies no longer keep on disk to cold
+// storage. Files that fail to move stay where they are and are tried again on the next run.
+func (s *Service) ApplyVersionRetention() {
+	now := time.Now()
+	candidates, err := s.repo.GetRetentionCandidates(now.Add(-RestoredFileGracePeriod), retentionBatchSize)
+	if err != nil {
+		log.Printf("failed to load version files to archive: %v", err)
+		return
+	}
+
+	archived := 0
+	for _, candidate := range candidates {
+		archivePath, err := s.coldStore.Archive(candidate.FileURL)
+		if err != nil {
+			log.Printf("failed to archive %s file %d (%s): %v", candidate.Kind, candidate.ID, candidate.FileURL, err)
+			continue
+		}
+		if err := s.repo.MarkFileArchived(candidate.Kind, candidate.ID, archivePath, now); err != nil {
+			log.Printf("failed to record archived %s file %d: %v", candidate.Kind, candidate.ID, err)
+			continue
+		}
+		archived++
+	}
+	if archived > 0 {
+		log.Printf("moved %d proposal version files to cold storage", archived)
+	}
+}
+
+// RestoreVersionFile brings an archived file of a version back from cold storage, for whoever may
+// view the proposal: the version's own file, or with fileID one of the drafts it replaced
+func (s *Service) RestoreVersionFile(proposalID uint, versionID uint, fileID uint, userID uint, role enums.Role, userDeptID uint) (*VersionFileHistory, error) {
+	proposal, err := s.GetProposal(proposalID, userID, role, userDeptID)
+	if err != nil {
+		return nil, err
+	}
+	var version *domain.ProposalVersion
+	for i := range proposal.Versions {
+		if proposal.Versions[i].ID == versionID {
+			version = &proposal.Versions[i]
+			break
+		}
+	}
+	if version == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	kind, id, fileURL, stored := RetentionKindVersion, version.ID, "", version.ColdStorage
+	if version.FileURL != nil {
+		fileURL = *version.FileURL
+	}
+	if fileID != 0 {
+		file, err := s.repo.GetVersionFile(version.ID, fileID)
+		if err != nil {
+			return nil, ErrFileNotFound
+		}
+		kind, id, fileURL, stored = RetentionKindReplaced, file.ID, file.FileURL, file.ColdStorage
+	}
+	if stored.ArchivedAt == nil {
+		return nil, ErrFileNotArchived
+	}
+
+	if err := s.coldStore.Restore(stored.ArchivePath, fileURL); err != nil {
+		return nil, fmt.Errorf("failed to restore the file: %w", err)
+	}
+	if err := s.repo.MarkFileRestored(kind, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.GetVersionFileHistory(proposalID, versionID, userID, role, userDeptID)
+}
@@ -2,20 +2,48 @@ package proposals
 
 import (
 	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/pkg/audit"
 	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/settings"
+	"backend/pkg/uow"
 	"errors"
 	"fmt"
+	"log"
+	"mime/multipart"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrTeamHasActiveProposal = errors.New("the team already has an active proposal")
+	ErrVersionConflict       = errors.New("another version of the proposal was saved at the same time; reload and try again")
+)
+
+// mapConstraintError explains a unique index violation; the indexes are the last word on these rules
+func mapConstraintError(err error, conflict error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return conflict
+	}
+	return err
+}
+
 type Service struct {
-	repo Repository
-	db   *gorm.DB
+	repo      Repository
+	db        *gorm.DB
+	work        uow.UnitOfWork
+	bus         *events.Bus
+	auditLogger *audit.Logger
+	uploader    *files.Uploader
+	quota       *files.Quota
+	coldStore   *files.ColdStore
+	settings    *settings.Store
 }
 
-func NewService(r Repository, db *gorm.DB) *Service {
-	return &Service{repo: r, db: db}
+func NewService(r Repository, db *gorm.DB, work uow.UnitOfWork, bus *events.Bus, auditLogger *audit.Logger, uploader *files.Uploader, quota *files.Quota, coldStore *files.ColdStore, settingsStore *settings.Store) *Service {
+	return &Service{repo: r, db: db, work: work, bus: bus, auditLogger: auditLogger, uploader: uploader, quota: quota, coldStore: coldStore, settings: settingsStore}
 }
 
 func (s *Service) GetLatestVersion(proposalID uint) (*domain.ProposalVersion, error) {
@@ -32,11 +60,27 @@ type ProposalInput struct {
 	Methodology      string
 	Timeline         string
 	ExpectedOutcomes string
+	// ContinuesProjectID links the draft to an earlier cohort's project; set by ContinueProject
+	ContinuesProjectID *uint
+	// File is the document of version 1, stored together with the draft or not at all
+	File *multipart.FileHeader
 }
 
 // 1. Create New Draft (Creates Proposal + Version 1)
+// The proposal, its first version and the version's document are created together: when any of
+// them fails nothing is left behind, so a retry is not refused as a second active proposal.
 func (s *Service) CreateDraft(input ProposalInput, userID uint) (*domain.Proposal, error) {
+	var upload *versionUpload
+	if input.File != nil {
+		var err error
+		if upload, err = s.checkVersionFile(input.File, input.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	var proposal domain.Proposal
+	var version domain.ProposalVersion
+	var storedPath string
 
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Create Parent (Status: Draft)
@@ -45,13 +89,15 @@ func (s *Service) CreateDraft(input ProposalInput, userID uint) (*domain.Proposa
 			Status:    enums.ProposalStatusDraft,
 			AdvisorID: nil,
 				CreatedBy: userID,
+			AcademicYear: currentAcademicYear(tx, userID),
+			ContinuesProjectID: input.ContinuesProjectID,
 		}
 		if err := tx.Create(&proposal).Error; err != nil {
 			return err
 		}
 
 		// 2. Create Version 1
-		version := domain.ProposalVersion{
+		version = domain.ProposalVersion{
 			ProposalID:       proposal.ID,
 			CreatedBy:        userID,
 			VersionNumber:    1,
@@ -66,9 +112,31 @@ func (s *Service) CreateDraft(input ProposalInput, userID uint) (*domain.Proposa
 			FileHash:         "",
 			FileSizeBytes:    0,
 		}
+
+		// 3. Store the document under the new proposal's directory
+		if upload != nil {
+			path, err := s.saveVersionFile(upload, proposal.ID)
+			if err != nil {
+				return err
+			}
+			storedPath = path
+			upload.attach(&version, path)
+		}
 		return tx.Create(&version).Error
 	})
-	return &proposal, err
+	if err != nil {
+		if storedPath != "" {
+			_ = s.uploader.DeleteFile(storedPath)
+		}
+		return nil, mapConstraintError(err, ErrTeamHasActiveProposal)
+	}
+
+	if upload != nil {
+		if err := s.ExtractVersionText(&version); err != nil {
+			log.Printf("failed to store the text of proposal version %d: %v", version.ID, err)
+		}
+	}
+	return &proposal, nil
 }
 
 // 2. Update Proposal (Edit Draft OR Create Revision)
@@ -106,12 +174,14 @@ func (s *Service) overwriteDraftVersion(p *domain.Proposal, input ProposalInput)
 	version.Objectives = input.Objectives
 	version.Methodology = input.Methodology
 	version.ExpectedTimeline = input.Timeline
+	now := time.Now()
+	version.LastSavedAt = &now
 
 	// Update Team if changed
 	if input.TeamID != nil {
 		p.TeamID = input.TeamID
 		if err := s.repo.Update(p); err != nil {
-			return nil, err
+			return nil, mapConstraintError(err, ErrTeamHasActiveProposal)
 		}
 	}
 
@@ -146,7 +216,23 @@ func (s *Service) createNewVersion(p *domain.Proposal, input ProposalInput, user
 	}
 
 	if err := s.repo.CreateVersion(&newVer); err != nil {
-		return nil, err
+		return nil, mapConstraintError(err, ErrVersionConflict)
+	}
+
+	// The advisor asked for this revision; let them know it is ready before it is resubmitted
+	if p.Status == enums.ProposalStatusRevisionRequired && p.AdvisorID != nil {
+		s.bus.Publish(events.Event{
+			Name:       events.ProposalVersionUploaded,
+			EntityType: "proposal",
+			EntityID:   p.ID,
+			ActorID:    userID,
+			UserIDs:    []uint{*p.AdvisorID},
+			Data: map[string]interface{}{
+				"title":      newVer.Title,
+				"version":    newVer.VersionNumber,
+				"version_id": newVer.ID,
+			},
+		})
 	}
 	return p, nil
 }
@@ -185,11 +271,61 @@ func (s *Service) SubmitProposal(proposalID uint, teamID uint, userID uint) erro
 		return errors.New("only team leader can submit")
 	}
 
+	// Rule: First submissions only while the department's window is open
+	if err := s.checkSubmissionWindow(proposal, team.DepartmentID); err != nil {
+		return err
+	}
+
+	// Rule: Latest version meets the department's proposal rules
+	report, err := s.validateLatestVersion(proposalID, team.DepartmentID)
+	if err != nil {
+		return err
+	}
+	if !report.Valid {
+		return &ValidationError{Report: report}
+	}
+
 	// Update Status to Submitted
+	resubmission := proposal.Status == enums.ProposalStatusRevisionRequired
 	proposal.TeamID = &teamID
 	proposal.Status = enums.ProposalStatusSubmitted
 
-	return s.repo.Update(proposal)
+	if err := s.repo.Update(proposal); err != nil {
+		return mapConstraintError(err, ErrTeamHasActiveProposal)
+	}
+
+	// Tell the advisor what changed since the version they asked to be revised
+	if resubmission && proposal.AdvisorID != nil {
+		if diff := s.resubmissionDiff(proposal); diff != nil {
+			s.bus.Publish(events.Event{
+				Name:       events.ProposalResubmitted,
+				EntityType: "proposal",
+				EntityID:   proposalID,
+				ActorID:    userID,
+				UserIDs:    []uint{*proposal.AdvisorID},
+				Data: map[string]interface{}{
+					"title":      latestTitle(proposal),
+					"version":    diff.ToVersion,
+					"summary":    diff.Summary(),
+					"sections":   diff.Sections,
+					"word_delta": diff.WordDelta,
+				},
+			})
+		}
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.ProposalSubmitted,
+		EntityType: "proposal",
+		EntityID:   proposalID,
+		ActorID:    userID,
+		UserIDs:    acceptedMemberIDs(&team),
+		Data: map[string]interface{}{
+			"team_id": teamID,
+			"title":   latestTitle(proposal),
+		},
+	})
+	return nil
 }
 
 // Getters
@@ -199,6 +335,33 @@ func (s *Service) GetProposal(id uint, userID uint, role enums.Role, userDeptID
 		return nil, errors.New("proposal not found")
 	}
 
+	if !s.canView(proposal, userID, role, userDeptID) {
+		return nil, errors.New("you do not have permission to view this proposal")
+	}
+
+	return proposal, nil
+}
+
+// GetProposalsByIDs loads several proposals at once and keeps the ones GetProposal would return
+func (s *Service) GetProposalsByIDs(ids []uint, userID uint, role enums.Role, userDeptID uint) ([]domain.Proposal, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	list, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	visible := list[:0]
+	for i := range list {
+		if s.canView(&list[i], userID, role, userDeptID) {
+			visible = append(visible, list[i])
+		}
+	}
+	return visible, nil
+}
+
+// canView is the permission check of GetProposal
+func (s *Service) canView(proposal *domain.Proposal, userID uint, role enums.Role, userDeptID uint) bool {
 	// 🔒 PERMISSION CHECK 🔒
 	allowed := false
 
@@ -213,6 +376,14 @@ func (s *Service) GetProposal(id uint, userID uint, role enums.Role, userDeptID
 		if proposal.AdvisorID != nil && *proposal.AdvisorID == userID {
 			allowed = true
 		}
+		// ...or a colleague invited for a second opinion, read-only until they answer
+		if !allowed && s.repo.IsSecondOpinionReviewer(proposal.ID, userID) {
+			allowed = true
+		}
+		// ...or the co-reviewer whose approval it also needs
+		if !allowed && proposal.SecondReviewerID != nil && *proposal.SecondReviewerID == userID {
+			allowed = true
+		}
 	case enums.RoleStudent:
 		// 1. Is user the creator/leader?
 		if proposal.CreatedBy == userID {
@@ -231,19 +402,46 @@ func (s *Service) GetProposal(id uint, userID uint, role enums.Role, userDeptID
 		}
 	}
 
-	if !allowed {
-		return nil, errors.New("you do not have permission to view this proposal")
-	}
+	return allowed
+}
 
-	return proposal, nil
+// ProposalListQuery holds the list filters, sorting and pagination accepted by GET /proposals
+type ProposalListQuery struct {
+	Status     string
+	Archived   bool
+	AdvisorID  uint
+	TeamSearch string
+	Sort       string // created_at, updated_at, title
+	Order      string // asc, desc
+	Page       int
+	Limit      int
 }
 
-// GetProposals fetches a list of proposals filtered by user role (Data Isolation)
-func (s *Service) GetProposals(status string, userID uint, role enums.Role, userDeptID uint) ([]domain.Proposal, error) {
+// GetProposals fetches a page of proposals filtered by user role (Data Isolation) and the total match count
+func (s *Service) GetProposals(q ProposalListQuery, userID uint, role enums.Role, userDeptID uint) ([]domain.Proposal, int64, error) {
 	filters := make(map[string]interface{})
 
-	if status != "" {
-		filters["status"] = status
+	if q.Status != "" {
+		filters["status"] = q.Status
+	}
+	if q.Archived {
+		filters["archived"] = true
+	}
+	if q.AdvisorID != 0 {
+		filters["advisor_id"] = q.AdvisorID
+	}
+	if q.TeamSearch != "" {
+		filters["team_search"] = q.TeamSearch
+	}
+	if q.Sort != "" {
+		filters["sort"] = q.Sort
+	}
+	if q.Order != "" {
+		filters["order"] = q.Order
+	}
+	if q.Page > 0 && q.Limit > 0 {
+		filters["page"] = q.Page
+		filters["limit"] = q.Limit
 	}
 
 	// 🔒 DATA ISOLATION 🔒
@@ -257,15 +455,127 @@ func (s *Service) GetProposals(status string, userID uint, role enums.Role, user
 	case enums.RoleStudent:
 		// Students see proposals where they are members/leaders
 		filters["user_id"] = userID
-		// Note: The repository logic must handle filtering out drafts for members
 	}
 
 	return s.repo.GetAll(filters)
 }
 
-func (s *Service) AssignAdvisor(proposalID uint, advisorID uint) error {
-	// Ideally check if advisor exists and is in same department, skipping for speed
-	return s.repo.AssignAdvisor(proposalID, advisorID)
+// AssignAdvisor gives the proposal to the advisor, who has acceptWithinHours (72 when 0) to
+// accept or decline it before the department head is asked to pick someone else. Both the
+// proposal and the advisor must belong to the assigner's department.
+func (s *Service) AssignAdvisor(proposalID uint, advisorID uint, acceptWithinHours int, departmentID uint) error {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil || proposal.Team == nil || proposal.Team.DepartmentID != departmentID {
+		return errors.New("proposal not found")
+	}
+	advisor, err := s.repo.GetAdvisor(advisorID)
+	if err != nil || !advisor.IsActive || advisor.DepartmentID != departmentID {
+		return ErrAdvisorInvalid
+	}
+	now := time.Now()
+	acceptBy, err := acceptDeadline(now, acceptWithinHours)
+	if err != nil {
+		return err
+	}
+	if err := s.checkQuota(proposal, advisorID); err != nil {
+		return err
+	}
+	if err := s.checkConflict(proposal, advisorID); err != nil {
+		return err
+	}
+	if err := checkNotSecondReviewer(proposal, advisorID); err != nil {
+		return err
+	}
+
+	if err := s.repo.AssignAdvisor(proposalID, advisorID, now, acceptBy); err != nil {
+		return err
+	}
+
+	recipients := []uint{advisorID}
+	title := latestTitle(proposal)
+	if proposal.Team != nil {
+		recipients = append(recipients, acceptedMemberIDs(proposal.Team)...)
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.ProposalAdvisorAssigned,
+		EntityType: "proposal",
+		EntityID:   proposalID,
+		UserIDs:    recipients,
+		Data: map[string]interface{}{
+			"advisor_id": advisorID,
+			"title":      title,
+			"accept_by":  acceptBy.Format(time.RFC3339),
+		},
+	})
+	return nil
+}
+
+// ArchiveResult reports how many records an archive run touched
+type ArchiveResult struct {
+	AcademicYear      string `json:"academic_year"`
+	ProposalsArchived int64  `json:"proposals_archived"`
+	ProjectsArchived  int64  `json:"projects_archived"`
+}
+
+// ArchiveCohort archives all proposals, projects and teams of a completed academic year in the admin's department
+func (s *Service) ArchiveCohort(academicYear string, adminID uint, departmentID uint) (*ArchiveResult, error) {
+	if academicYear == currentAcademicYear(s.db, adminID) {
+		return nil, errors.New("cannot archive the current academic year")
+	}
+
+	proposalCount, projectCount, err := s.repo.ArchiveCohort(academicYear, departmentID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArchiveResult{
+		AcademicYear:      academicYear,
+		ProposalsArchived: proposalCount,
+		ProjectsArchived:  projectCount,
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.CohortArchived,
+		EntityType: "department",
+		EntityID:   departmentID,
+		ActorID:    adminID,
+		Data: map[string]interface{}{
+			"academic_year":      academicYear,
+			"proposals_archived": proposalCount,
+			"projects_archived":  projectCount,
+		},
+	})
+	return result, nil
+}
+
+// currentAcademicYear reads the academic year of the user's university
+func currentAcademicYear(db *gorm.DB, userID uint) string {
+	var year string
+	db.Table("users").
+		Select("universities.academic_year").
+		Joins("JOIN universities ON universities.id = users.university_id").
+		Where("users.id = ?", userID).
+		Scan(&year)
+	return year
+}
+
+// latestTitle relies on repo.GetByID ordering versions latest first
+func latestTitle(p *domain.Proposal) string {
+	if len(p.Versions) == 0 {
+		return ""
+	}
+	return p.Versions[0].Title
+}
+
+func acceptedMemberIDs(team *domain.Team) []uint {
+	var ids []uint
+	for _, m := range team.Members {
+		if m.InvitationStatus == enums.InvitationStatusAccepted {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
 }
 
 // func (s *Service) GetProposal(id uint) (*domain.Proposal, error) {
@@ -276,6 +586,42 @@ func (s *Service) GetVersions(id uint) ([]domain.ProposalVersion, error) {
 	return s.repo.GetVersionsByProposalID(id)
 }
 
+// BlindReviewApplies reports whether the viewer is an advisor of a university that reviews
+// proposals blind. When the setting cannot be read the students stay hidden.
+func (s *Service) BlindReviewApplies(userID uint, role enums.Role) bool {
+	if role != enums.RoleAdvisor {
+		return false
+	}
+	university, err := s.repo.GetUserUniversity(userID)
+	if err != nil {
+		return true
+	}
+	return university.BlindReview
+}
+
+// TeamHiddenFrom reports whether blind review hides the team from the viewer: an advisor of a
+// blind-review university while one of the team's proposals awaits a decision. Fails closed.
+func (s *Service) TeamHiddenFrom(teamID uint, userID uint, role enums.Role) bool {
+	if !s.BlindReviewApplies(userID, role) {
+		return false
+	}
+	statuses := make([]enums.ProposalStatus, 0, len(blindReviewStatuses))
+	for status := range blindReviewStatuses {
+		statuses = append(statuses, status)
+	}
+	awaiting, err := s.repo.TeamHasProposalIn(teamID, statuses)
+	return err != nil || awaiting
+}
+
+// AwaitingDecision reports whether the proposal is still in a state blind review applies to
+func (s *Service) AwaitingDecision(id uint) bool {
+	proposal, err := s.repo.GetByID(id)
+	if err != nil {
+		return true
+	}
+	return blindReviewStatuses[proposal.Status]
+}
+
 func (s *Service) DeleteProposal(id uint) error {
 	proposal, err := s.repo.GetByID(id)
 	if err != nil {
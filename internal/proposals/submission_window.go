@@ -0,0 +1,238 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// SubmissionReminderCheckInterval is how often the scheduler reminds teams of the submission deadline
+const SubmissionReminderCheckInterval = time.Hour
+
+// DefaultSubmissionReminderDays are the reminders sent before a window closes when none are configured
+var DefaultSubmissionReminderDays = []int{7, 3, 1}
+
+var (
+	ErrSubmissionWindowNotOpen = errors.New("the proposal submission window has not opened yet")
+	ErrSubmissionWindowClosed  = errors.New("the proposal submission window has closed")
+)
+
+// UpdateSubmissionWindowRequest sets the department's submission window for the current cohort
+type UpdateSubmissionWindowRequest struct {
+	OpensAt      time.Time `json:"opens_at" binding:"required" example:"2026-02-01T00:00:00Z"`
+	ClosesAt     time.Time `json:"closes_at" binding:"required" example:"2026-03-15T23:59:00Z"`
+	ReminderDays []int     `json:"reminder_days" example:"7,3,1"` // defaults to 7, 3 and 1
+}
+
+// PendingTeam is a team that has not submitted a proposal yet
+type PendingTeam struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	MemberCount int    `json:"member_count"`
+}
+
+// SubmissionWindowStatus is a department's submission window with the teams still to submit
+type SubmissionWindowStatus struct {
+	*domain.SubmissionWindow
+	IsOpen       bool          `json:"is_open"`
+	PendingTeams []PendingTeam `json:"pending_teams"`
+}
+
+// GetSubmissionWindow returns the department's window and the current cohort's teams without a submission
+func (s *Service) GetSubmissionWindow(departmentID uint) (*SubmissionWindowStatus, error) {
+	window, err := s.repo.GetSubmissionWindow(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, errors.New("no submission window is set for the department")
+	}
+
+	teams, err := s.repo.GetTeamsWithoutSubmission(departmentID, window.AcademicYear)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	status := &SubmissionWindowStatus{
+		SubmissionWindow: window,
+		IsOpen:           !now.Before(window.OpensAt) && now.Before(window.ClosesAt),
+		PendingTeams:     make([]PendingTeam, 0, len(teams)),
+	}
+	for _, team := range teams {
+		status.PendingTeams = append(status.PendingTeams, PendingTeam{ID: team.ID, Name: team.Name, MemberCount: len(acceptedMemberIDs(&team))})
+	}
+	return status, nil
+}
+
+// UpdateSubmissionWindow sets the window for the department's current cohort. Changing the
+// closing date or the reminder schedule starts the reminders over.
+func (s *Service) UpdateSubmissionWindow(departmentID uint, userID uint, req UpdateSubmissionWindowRequest) (*SubmissionWindowStatus, error) {
+	if !req.ClosesAt.After(req.OpensAt) {
+		return nil, errors.New("the window must close after it opens")
+	}
+	days := req.ReminderDays
+	if len(days) == 0 {
+		days = DefaultSubmissionReminderDays
+	}
+	seen := make(map[int]bool)
+	reminders := make([]int, 0, len(days))
+	for _, d := range days {
+		if d < 1 || d > 60 {
+			return nil, errors.New("reminder days must be between 1 and 60")
+		}
+		if !seen[d] {
+			seen[d] = true
+			reminders = append(reminders, d)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(reminders)))
+
+	window, err := s.repo.GetSubmissionWindow(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		window = &domain.SubmissionWindow{DepartmentID: departmentID}
+	}
+	if !window.ClosesAt.Equal(req.ClosesAt) || fmt.Sprint(window.ReminderDays) != fmt.Sprint(reminders) {
+		window.RemindersSent = 0
+		window.ClosedReportedAt = nil
+	}
+	window.AcademicYear = s.repo.GetDepartmentAcademicYear(departmentID)
+	window.OpensAt = req.OpensAt
+	window.ClosesAt = req.ClosesAt
+	window.ReminderDays = reminders
+	window.UpdatedBy = &userID
+
+	if err := s.repo.SaveSubmissionWindow(window); err != nil {
+		return nil, err
+	}
+	return s.GetSubmissionWindow(departmentID)
+}
+
+// checkSubmissionWindow refuses a first submission outside the department's window, unless the team
+// was granted an extension. Resubmissions after a revision request follow the advisor's deadline instead.
+func (s *Service) checkSubmissionWindow(proposal *domain.Proposal, departmentID uint) error {
+	if proposal.Status != enums.ProposalStatusDraft {
+		return nil
+	}
+	window, err := s.repo.GetSubmissionWindow(departmentID)
+	if err != nil || window == nil {
+		return err
+	}
+	now := time.Now()
+	if now.Before(window.OpensAt) {
+		return fmt.Errorf("%w: it opens on %s", ErrSubmissionWindowNotOpen, window.OpensAt.Format("2006-01-02 15:04 MST"))
+	}
+	if !now.Before(window.ClosesAt) {
+		if proposal.TeamID != nil {
+			until, err := s.repo.GetExtendedDeadline(*proposal.TeamID, window.AcademicYear)
+			if err != nil {
+				return err
+			}
+			if until != nil && now.Before(*until) {
+				return nil
+			}
+			if until != nil {
+				return fmt.Errorf("%w; the team's extension ended on %s", ErrSubmissionWindowClosed, until.Format("2006-01-02 15:04 MST"))
+			}
+		}
+		return fmt.Errorf("%w on %s", ErrSubmissionWindowClosed, window.ClosesAt.Format("2006-01-02 15:04 MST"))
+	}
+	return nil
+}
+
+// ProcessSubmissionReminders reminds teams that have not submitted as their department's window
+// nears its close, with a summary of the outstanding teams to the department admins; once the
+// window closes the admins get the final list (run by the scheduler)
+func (s *Service) ProcessSubmissionReminders() {
+	windows, err := s.repo.GetUnreportedSubmissionWindows()
+	if err != nil {
+		log.Printf("failed to load submission windows: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range windows {
+		window := &windows[i]
+		if now.Before(window.OpensAt) {
+			continue
+		}
+		remaining := window.ClosesAt.Sub(now)
+		closed := remaining <= 0
+
+		// Only the latest due reminder is sent, so a window set up two days before closing skips the T-7 one
+		due := 0
+		for n, days := range window.ReminderDays {
+			if remaining <= time.Duration(days)*24*time.Hour {
+				due = n + 1
+			}
+		}
+		if !closed && due <= window.RemindersSent {
+			continue
+		}
+
+		if closed {
+			if err := s.repo.MarkSubmissionWindowReported(window.DepartmentID, now); err != nil {
+				log.Printf("failed to record submission report for department %d: %v", window.DepartmentID, err)
+				continue
+			}
+		} else if err := s.repo.SetSubmissionReminders(window.DepartmentID, due); err != nil {
+			log.Printf("failed to record submission reminder for department %d: %v", window.DepartmentID, err)
+			continue
+		}
+
+		teams, err := s.repo.GetTeamsWithoutSubmission(window.DepartmentID, window.AcademicYear)
+		if err != nil {
+			log.Printf("failed to load teams without a submission for department %d: %v", window.DepartmentID, err)
+			continue
+		}
+		if len(teams) == 0 {
+			continue
+		}
+
+		daysLeft := int(math.Ceil(remaining.Hours() / 24))
+		names := make([]string, 0, len(teams))
+		for _, team := range teams {
+			names = append(names, team.Name)
+			if closed {
+				continue
+			}
+			s.bus.Publish(events.Event{
+				Name:       events.SubmissionDeadlineNear,
+				EntityType: "team",
+				EntityID:   team.ID,
+				UserIDs:    acceptedMemberIDs(&team),
+				Data: map[string]interface{}{
+					"team_name": team.Name,
+					"closes_at": window.ClosesAt.Format(time.RFC3339),
+					"days_left": daysLeft,
+				},
+			})
+		}
+
+		adminIDs, err := s.repo.GetDepartmentAdminIDs(window.DepartmentID)
+		if err != nil {
+			log.Printf("failed to load department admins for department %d: %v", window.DepartmentID, err)
+			continue
+		}
+		s.bus.Publish(events.Event{
+			Name:       events.SubmissionsOutstanding,
+			EntityType: "department",
+			EntityID:   window.DepartmentID,
+			UserIDs:    adminIDs,
+			Data: map[string]interface{}{
+				"closed":     closed,
+				"days_left":  daysLeft,
+				"team_count": len(teams),
+				"teams":      names,
+			},
+		})
+	}
+}
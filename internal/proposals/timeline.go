@@ -0,0 +1,361 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxPhasesPerProposal    = 30
+	maxDeliverablesPerPhase = 20
+)
+
+// PhaseInput is the editable part of a timeline phase
+type PhaseInput struct {
+	Name         string    `json:"name" binding:"required,max=150"`
+	Description  string    `json:"description"`
+	StartDate    time.Time `json:"start_date" binding:"required"`
+	EndDate      time.Time `json:"end_date" binding:"required"`
+	Deliverables []string  `json:"deliverables"`
+	DependsOnID  *uint     `json:"depends_on_id"`
+	Position     int       `json:"position"`
+}
+
+// ProjectPeriod is the window a timeline must fit in
+type ProjectPeriod struct {
+	StartsOn time.Time `json:"starts_on"`
+	EndsOn   time.Time `json:"ends_on"`
+	Source   string    `json:"source"` // "university" or "academic_year"
+}
+
+// Timeline is a proposal's phases with the period they are validated against
+type Timeline struct {
+	ProposalID uint                   `json:"proposal_id"`
+	Period     *ProjectPeriod         `json:"period,omitempty"`
+	Phases     []domain.TimelinePhase `json:"phases"`
+}
+
+// GanttBar is a phase positioned on the chart; offsets and widths are percentages of the chart span
+type GanttBar struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	DurationDays  int       `json:"duration_days"`
+	OffsetDays    int       `json:"offset_days"`
+	OffsetPercent float64   `json:"offset_percent"`
+	WidthPercent  float64   `json:"width_percent"`
+	Deliverables  []string  `json:"deliverables"`
+	DependsOnID   *uint     `json:"depends_on_id,omitempty"`
+}
+
+// GanttChart is ready-to-render chart data for a proposal timeline
+type GanttChart struct {
+	ProposalID   uint       `json:"proposal_id"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      time.Time  `json:"end_date"`
+	TotalDays    int        `json:"total_days"`
+	TodayPercent *float64   `json:"today_percent,omitempty"` // nil when today is outside the chart
+	Bars         []GanttBar `json:"bars"`
+}
+
+// GetTimeline returns the phases of a proposal the user can view
+func (s *Service) GetTimeline(proposalID uint, userID uint, role enums.Role, userDeptID uint) (*Timeline, error) {
+	proposal, err := s.GetProposal(proposalID, userID, role, userDeptID)
+	if err != nil {
+		return nil, err
+	}
+
+	phases, err := s.repo.GetPhases(proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Timeline{ProposalID: proposalID, Period: s.projectPeriod(proposal), Phases: phases}, nil
+}
+
+// CreatePhase adds a phase to the timeline of a proposal the user is working on
+func (s *Service) CreatePhase(proposalID uint, input PhaseInput, userID uint) (*domain.TimelinePhase, error) {
+	proposal, err := s.editableProposal(proposalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	phases, err := s.repo.GetPhases(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(phases) >= maxPhasesPerProposal {
+		return nil, errors.New("timeline has too many phases")
+	}
+
+	phase := &domain.TimelinePhase{ProposalID: proposalID}
+	if err := s.applyPhaseInput(proposal, phase, input, phases); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePhase(phase); err != nil {
+		return nil, err
+	}
+	return phase, nil
+}
+
+// UpdatePhase replaces a phase's details
+func (s *Service) UpdatePhase(proposalID, phaseID uint, input PhaseInput, userID uint) (*domain.TimelinePhase, error) {
+	proposal, err := s.editableProposal(proposalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	phase, err := s.repo.GetPhase(proposalID, phaseID)
+	if err != nil {
+		return nil, errors.New("phase not found")
+	}
+
+	phases, err := s.repo.GetPhases(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPhaseInput(proposal, phase, input, phases); err != nil {
+		return nil, err
+	}
+
+	// A phase that now ends later may push past phases that depend on it
+	for _, other := range phases {
+		if other.DependsOnID != nil && *other.DependsOnID == phase.ID && other.StartDate.Before(phase.EndDate) {
+			return nil, errors.New("phase '" + other.Name + "' depends on this phase and starts before it ends")
+		}
+	}
+
+	if err := s.repo.UpdatePhase(phase); err != nil {
+		return nil, err
+	}
+	return phase, nil
+}
+
+// DeletePhase removes a phase; dependent phases lose their dependency
+func (s *Service) DeletePhase(proposalID, phaseID uint, userID uint) error {
+	if _, err := s.editableProposal(proposalID, userID); err != nil {
+		return err
+	}
+
+	phase, err := s.repo.GetPhase(proposalID, phaseID)
+	if err != nil {
+		return errors.New("phase not found")
+	}
+	return s.repo.DeletePhase(phase)
+}
+
+// GetGantt lays the timeline out as Gantt chart bars
+func (s *Service) GetGantt(proposalID uint, userID uint, role enums.Role, userDeptID uint) (*GanttChart, error) {
+	timeline, err := s.GetTimeline(proposalID, userID, role, userDeptID)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &GanttChart{ProposalID: proposalID, Bars: []GanttBar{}}
+	if timeline.Period != nil {
+		chart.StartDate, chart.EndDate = timeline.Period.StartsOn, timeline.Period.EndsOn
+	}
+	for _, phase := range timeline.Phases {
+		if chart.StartDate.IsZero() || phase.StartDate.Before(chart.StartDate) {
+			chart.StartDate = phase.StartDate
+		}
+		if chart.EndDate.IsZero() || phase.EndDate.After(chart.EndDate) {
+			chart.EndDate = phase.EndDate
+		}
+	}
+	if chart.StartDate.IsZero() {
+		return chart, nil
+	}
+
+	// Both ends are inclusive days
+	chart.TotalDays = daysBetween(chart.StartDate, chart.EndDate) + 1
+	span := float64(chart.TotalDays)
+
+	phases := timeline.Phases
+	sort.SliceStable(phases, func(i, j int) bool {
+		if phases[i].Position != phases[j].Position {
+			return phases[i].Position < phases[j].Position
+		}
+		return phases[i].StartDate.Before(phases[j].StartDate)
+	})
+
+	for _, phase := range phases {
+		offset := daysBetween(chart.StartDate, phase.StartDate)
+		duration := daysBetween(phase.StartDate, phase.EndDate) + 1
+		chart.Bars = append(chart.Bars, GanttBar{
+			ID:            phase.ID,
+			Name:          phase.Name,
+			StartDate:     phase.StartDate,
+			EndDate:       phase.EndDate,
+			DurationDays:  duration,
+			OffsetDays:    offset,
+			OffsetPercent: percent(float64(offset), span),
+			WidthPercent:  percent(float64(duration), span),
+			Deliverables:  phase.Deliverables,
+			DependsOnID:   phase.DependsOnID,
+		})
+	}
+
+	today := truncateDay(time.Now())
+	if !today.Before(chart.StartDate) && !today.After(chart.EndDate) {
+		p := percent(float64(daysBetween(chart.StartDate, today)), span)
+		chart.TodayPercent = &p
+	}
+
+	return chart, nil
+}
+
+// editableProposal loads a proposal whose timeline the student may change
+func (s *Service) editableProposal(proposalID, userID uint) (*domain.Proposal, error) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+
+	member := proposal.CreatedBy == userID
+	if !member && proposal.Team != nil {
+		for _, m := range proposal.Team.Members {
+			if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+				member = true
+				break
+			}
+		}
+	}
+	if !member {
+		return nil, errors.New("you do not have permission to edit this timeline")
+	}
+
+	switch proposal.Status {
+	case enums.ProposalStatusDraft, enums.ProposalStatusRevisionRequired, enums.ProposalStatusApproved:
+		return proposal, nil
+	default:
+		return nil, errors.New("timeline cannot be edited while the proposal is " + string(proposal.Status))
+	}
+}
+
+func (s *Service) applyPhaseInput(proposal *domain.Proposal, phase *domain.TimelinePhase, input PhaseInput, phases []domain.TimelinePhase) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return errors.New("phase name is required")
+	}
+
+	start, end := truncateDay(input.StartDate), truncateDay(input.EndDate)
+	if end.Before(start) {
+		return errors.New("phase end date must not be before its start date")
+	}
+
+	if period := s.projectPeriod(proposal); period != nil {
+		if start.Before(period.StartsOn) || end.After(period.EndsOn) {
+			return errors.New("phase must fall within the project period (" +
+				period.StartsOn.Format("2006-01-02") + " to " + period.EndsOn.Format("2006-01-02") + ")")
+		}
+	}
+
+	deliverables := []string{}
+	for _, d := range input.Deliverables {
+		if d = strings.TrimSpace(d); d != "" {
+			deliverables = append(deliverables, d)
+		}
+	}
+	if len(deliverables) > maxDeliverablesPerPhase {
+		return errors.New("a phase can have at most 20 deliverables")
+	}
+
+	if input.DependsOnID != nil {
+		if phase.ID != 0 && *input.DependsOnID == phase.ID {
+			return errors.New("a phase cannot depend on itself")
+		}
+		var dependency *domain.TimelinePhase
+		for i := range phases {
+			if phases[i].ID == *input.DependsOnID {
+				dependency = &phases[i]
+				break
+			}
+		}
+		if dependency == nil {
+			return errors.New("dependency phase not found in this timeline")
+		}
+		if start.Before(dependency.EndDate) {
+			return errors.New("phase cannot start before the phase it depends on ends")
+		}
+		if phase.ID != 0 && dependsOn(phases, dependency.ID, phase.ID) {
+			return errors.New("phase dependencies cannot form a cycle")
+		}
+	}
+
+	phase.Name = name
+	phase.Description = input.Description
+	phase.StartDate = start
+	phase.EndDate = end
+	phase.Deliverables = deliverables
+	phase.DependsOnID = input.DependsOnID
+	phase.Position = input.Position
+	return nil
+}
+
+// dependsOn reports whether phase id (transitively) depends on target
+func dependsOn(phases []domain.TimelinePhase, id, target uint) bool {
+	parents := make(map[uint]*uint, len(phases))
+	for _, p := range phases {
+		parents[p.ID] = p.DependsOnID
+	}
+	for steps := 0; steps <= len(phases); steps++ {
+		if id == target {
+			return true
+		}
+		parent := parents[id]
+		if parent == nil {
+			return false
+		}
+		id = *parent
+	}
+	return false
+}
+
+// projectPeriod is the university's project dates when set, otherwise the proposal's academic year
+// ("2025/2026" spans 1 September 2025 to 31 August 2026). Nil when neither is known.
+func (s *Service) projectPeriod(proposal *domain.Proposal) *ProjectPeriod {
+	if university, err := s.repo.GetUserUniversity(proposal.CreatedBy); err == nil &&
+		university.ProjectStartsOn != nil && university.ProjectEndsOn != nil {
+		return &ProjectPeriod{
+			StartsOn: truncateDay(*university.ProjectStartsOn),
+			EndsOn:   truncateDay(*university.ProjectEndsOn),
+			Source:   "university",
+		}
+	}
+
+	years := strings.Split(proposal.AcademicYear, "/")
+	if len(years) != 2 {
+		return nil
+	}
+	first, err1 := strconv.Atoi(strings.TrimSpace(years[0]))
+	second, err2 := strconv.Atoi(strings.TrimSpace(years[1]))
+	if err1 != nil || err2 != nil || second != first+1 {
+		return nil
+	}
+	return &ProjectPeriod{
+		StartsOn: time.Date(first, time.September, 1, 0, 0, 0, 0, time.UTC),
+		EndsOn:   time.Date(second, time.August, 31, 0, 0, 0, 0, time.UTC),
+		Source:   "academic_year",
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(truncateDay(to).Sub(truncateDay(from)).Hours() / 24))
+}
+
+func percent(part, whole float64) float64 {
+	return math.Round(part/whole*10000) / 100
+}
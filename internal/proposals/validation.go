@@ -0,0 +1,288 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrProposalInvalid = errors.New("proposal does not meet the department's requirements")
+
+// Violation rules
+const (
+	RuleMinWords        = "min_words"
+	RuleMaxWords        = "max_words"
+	RuleRequiredHeading = "required_heading"
+)
+
+// headingNumbering strips outline numbering such as "2.", "2.1" or "IV." from a PDF line
+var headingNumbering = regexp.MustCompile(`^([0-9]+(\.[0-9]+)*[.)]?|[ivxlc]+[.)])\s+`)
+
+// UpdateProposalRulesRequest replaces the department's proposal rules; empty lists remove the constraints
+type UpdateProposalRulesRequest struct {
+	Sections         []domain.SectionRule `json:"sections"`
+	RequiredHeadings []string             `json:"required_headings" example:"Introduction,Methodology,References"`
+}
+
+// ProposalRules are the constraints that apply to a department's proposals
+type ProposalRules struct {
+	DepartmentID     uint                 `json:"department_id"`
+	Inherited        bool                 `json:"inherited"` // true while the department has no rules of its own; none apply then
+	Sections         []domain.SectionRule `json:"sections"`
+	RequiredHeadings []string             `json:"required_headings"`
+}
+
+// Violation is one unmet constraint
+type Violation struct {
+	Section string `json:"section,omitempty"` // section key; empty for document rules
+	Heading string `json:"heading,omitempty"` // the missing heading, for required_heading
+	Rule    string `json:"rule"`              // min_words, max_words or required_heading
+	Limit   int    `json:"limit,omitempty"`
+	Actual  int    `json:"actual,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of checking a proposal's latest version against its department's rules
+type ValidationReport struct {
+	ProposalID    uint        `json:"proposal_id"`
+	VersionNumber int         `json:"version_number"`
+	Valid         bool        `json:"valid"`
+	Violations    []Violation `json:"violations"`
+	Warnings      []string    `json:"warnings,omitempty"` // checks that could not be run
+}
+
+// ValidationError refuses a submission; it matches ErrProposalInvalid and carries the violations
+type ValidationError struct {
+	Report *ValidationReport
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %d violation(s)", ErrProposalInvalid, len(e.Report.Violations))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrProposalInvalid
+}
+
+// GetProposalRules returns the department's proposal rules. A department that has not set any
+// has no constraints, so drafts written before rules existed are not held back.
+func (s *Service) GetProposalRules(departmentID uint) (*ProposalRules, error) {
+	rules, err := s.repo.GetProposalRules(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return &ProposalRules{
+			DepartmentID:     departmentID,
+			Inherited:        true,
+			Sections:         []domain.SectionRule{},
+			RequiredHeadings: []string{},
+		}, nil
+	}
+	return &ProposalRules{
+		DepartmentID:     departmentID,
+		Sections:         rules.Sections,
+		RequiredHeadings: rules.RequiredHeadings,
+	}, nil
+}
+
+// UpdateProposalRules replaces the department's rules. They apply to the next submission;
+// proposals already submitted are not checked again.
+func (s *Service) UpdateProposalRules(departmentID uint, userID uint, req UpdateProposalRulesRequest) (*ProposalRules, error) {
+	sections := make([]domain.SectionRule, 0, len(req.Sections))
+	seen := make(map[string]bool)
+	for _, in := range req.Sections {
+		key := strings.ToLower(strings.TrimSpace(in.Section))
+		if !isVersionSection(key) {
+			return nil, errors.New("unknown proposal section: " + in.Section)
+		}
+		if seen[key] {
+			return nil, errors.New("duplicate rule for section: " + key)
+		}
+		seen[key] = true
+		if in.MinWords < 0 || in.MaxWords < 0 {
+			return nil, errors.New("word limits cannot be negative")
+		}
+		if in.MaxWords > 0 && in.MinWords > in.MaxWords {
+			return nil, fmt.Errorf("minimum words of %s exceed its maximum", key)
+		}
+		if in.MinWords == 0 && in.MaxWords == 0 {
+			continue
+		}
+		sections = append(sections, domain.SectionRule{Section: key, MinWords: in.MinWords, MaxWords: in.MaxWords})
+	}
+
+	headings := make([]string, 0, len(req.RequiredHeadings))
+	seenHeading := make(map[string]bool)
+	for _, heading := range req.RequiredHeadings {
+		heading = strings.Join(strings.Fields(heading), " ")
+		if heading == "" {
+			return nil, errors.New("required headings cannot be blank")
+		}
+		if seenHeading[normalizeHeading(heading)] {
+			continue
+		}
+		seenHeading[normalizeHeading(heading)] = true
+		headings = append(headings, heading)
+	}
+
+	rules, err := s.repo.GetProposalRules(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = &domain.ProposalRules{DepartmentID: departmentID}
+	}
+	rules.Sections = sections
+	rules.RequiredHeadings = headings
+	rules.UpdatedBy = &userID
+
+	if err := s.repo.SaveProposalRules(rules); err != nil {
+		return nil, err
+	}
+	return s.GetProposalRules(departmentID)
+}
+
+// ValidateProposal checks the proposal's latest version against the rules of its team's
+// department, or the viewer's department while the draft has no team
+func (s *Service) ValidateProposal(proposalID uint, userID uint, role enums.Role, userDeptID uint) (*ValidationReport, error) {
+	proposal, err := s.GetProposal(proposalID, userID, role, userDeptID)
+	if err != nil {
+		return nil, err
+	}
+	departmentID := userDeptID
+	if proposal.Team != nil {
+		departmentID = proposal.Team.DepartmentID
+	}
+	return s.validateLatestVersion(proposal.ID, departmentID)
+}
+
+func (s *Service) validateLatestVersion(proposalID uint, departmentID uint) (*ValidationReport, error) {
+	version, err := s.repo.GetLatestVersion(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal has no version to validate")
+	}
+	rules, err := s.GetProposalRules(departmentID)
+	if err != nil {
+		return nil, err
+	}
+	report := s.ValidateVersion(version, rules)
+	report.ProposalID = proposalID
+	return report, nil
+}
+
+// ValidateVersion checks a version's section word counts and, when a PDF is attached, that
+// the document contains every required heading
+func (s *Service) ValidateVersion(version *domain.ProposalVersion, rules *ProposalRules) *ValidationReport {
+	report := &ValidationReport{
+		VersionNumber: version.VersionNumber,
+		Violations:    []Violation{},
+	}
+
+	for _, rule := range rules.Sections {
+		name, text := versionSectionText(version, rule.Section)
+		if name == "" {
+			continue
+		}
+		words := len(strings.Fields(text))
+		if rule.MinWords > 0 && words < rule.MinWords {
+			report.Violations = append(report.Violations, Violation{
+				Section: rule.Section,
+				Rule:    RuleMinWords,
+				Limit:   rule.MinWords,
+				Actual:  words,
+				Message: fmt.Sprintf("%s must have at least %d words (has %d)", name, rule.MinWords, words),
+			})
+		}
+		if rule.MaxWords > 0 && words > rule.MaxWords {
+			report.Violations = append(report.Violations, Violation{
+				Section: rule.Section,
+				Rule:    RuleMaxWords,
+				Limit:   rule.MaxWords,
+				Actual:  words,
+				Message: fmt.Sprintf("%s must have at most %d words (has %d)", name, rule.MaxWords, words),
+			})
+		}
+	}
+
+	if len(rules.RequiredHeadings) > 0 {
+		report.Violations = append(report.Violations, s.checkHeadings(version, rules.RequiredHeadings, &report.Warnings)...)
+	}
+
+	report.Valid = len(report.Violations) == 0
+	return report
+}
+
+// checkHeadings looks for each required heading at the start of a line of the version's PDF.
+// A version without a PDF, or one whose text cannot be read, is not held back; the skipped
+// check is reported as a warning instead.
+func (s *Service) checkHeadings(version *domain.ProposalVersion, headings []string, warnings *[]string) []Violation {
+	if version.FileURL == nil || *version.FileURL == "" {
+		*warnings = append(*warnings, "no document is attached; required headings were not checked")
+		return nil
+	}
+	if version.DetectedMIME != "" && version.DetectedMIME != "application/pdf" {
+		*warnings = append(*warnings, "the attached document is not a PDF; required headings were not checked")
+		return nil
+	}
+	text, err := s.uploader.ReadPDFText(*version.FileURL)
+	if err != nil || strings.TrimSpace(text) == "" {
+		*warnings = append(*warnings, "the text of the attached PDF could not be read; required headings were not checked")
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = normalizeHeading(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	var violations []Violation
+	for _, heading := range headings {
+		want := normalizeHeading(heading)
+		found := false
+		for _, line := range lines {
+			if strings.HasPrefix(line, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, Violation{
+				Heading: heading,
+				Rule:    RuleRequiredHeading,
+				Message: fmt.Sprintf("the document has no %q heading", heading),
+			})
+		}
+	}
+	return violations
+}
+
+// normalizeHeading lowercases a heading, collapses its whitespace and drops outline numbering
+func normalizeHeading(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.TrimSpace(headingNumbering.ReplaceAllString(s, ""))
+}
+
+func isVersionSection(key string) bool {
+	for _, section := range versionSections {
+		if section.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// versionSectionText returns the display name and text of a section by key
+func versionSectionText(version *domain.ProposalVersion, key string) (string, string) {
+	for _, section := range versionSections {
+		if section.Key == key {
+			return section.Name, section.Text(version)
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,205 @@
+package proposals
+
+import (
+	"backend/internal/domain"
+	"backend/internal/files"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxVersionFileBytes caps the size of a proposal document
+const MaxVersionFileBytes = 25 << 20
+
+var (
+	ErrVersionNotFound     = errors.New("version not found")
+	ErrVersionNotEditable  = errors.New("only the file of the current, unsubmitted version can be replaced")
+	ErrVersionFileType     = errors.New("invalid file type: proposal documents must be PDF or DOCX")
+	ErrVersionFileTooLarge = fmt.Errorf("proposal documents are limited to %d MB", MaxVersionFileBytes>>20)
+)
+
+// allowedVersionFileTypes are the document formats a proposal version accepts
+var allowedVersionFileTypes = map[string]bool{".pdf": true, ".docx": true}
+
+// VersionFileHistory is a version's current attachment and the ones it replaced, newest first
+type VersionFileHistory struct {
+	VersionID     uint                         `json:"version_id"`
+	VersionNumber int                          `json:"version_number"`
+	FileURL       *string                      `json:"file_url"`
+	FileHash      string                       `json:"file_hash,omitempty"`
+	Replaced      []domain.ProposalVersionFile `json:"replaced"`
+}
+
+// ReplaceVersionFile attaches a new document to the proposal's current version without creating
+// a version. This is the draft while the proposal has not been submitted, or the revision being
+// prepared after feedback; versions an advisor has seen are never changed. The previous file
+// stays on disk and its reference and hash are archived.
+func (s *Service) ReplaceVersionFile(proposalID uint, versionID uint, file *multipart.FileHeader, userID uint) (*domain.ProposalVersion, error) {
+	proposal, err := s.repo.GetByID(proposalID)
+	if err != nil {
+		return nil, errors.New("proposal not found")
+	}
+	if !canEditDraft(proposal, userID) {
+		return nil, errors.New("you do not have permission to edit this proposal")
+	}
+
+	version, err := s.repo.GetLatestVersion(proposal.ID)
+	if err != nil {
+		return nil, ErrVersionNotFound
+	}
+	if version.ID != versionID {
+		if !hasVersion(proposal, versionID) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, ErrVersionNotEditable
+	}
+	if !s.isWorkingVersion(proposal, version) {
+		return nil, ErrVersionNotEditable
+	}
+
+	upload, err := s.checkVersionFile(file, proposal.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	path, err := s.saveVersionFile(upload, proposal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived *domain.ProposalVersionFile
+	if version.FileURL != nil && *version.FileURL != "" {
+		archived = &domain.ProposalVersionFile{
+			ProposalID:    proposal.ID,
+			VersionID:     version.ID,
+			FileURL:       *version.FileURL,
+			FileHash:      version.FileHash,
+			FileSizeBytes: version.FileSizeBytes,
+			ReplacedBy:    userID,
+			ReplacedAt:    time.Now(),
+			FileMetadata:  version.FileMetadata,
+		}
+	}
+
+	now := time.Now()
+	upload.attach(version, path)
+	version.LastSavedAt = &now
+	if err := s.repo.ReplaceVersionFile(version, archived); err != nil {
+		_ = s.uploader.DeleteFile(path)
+		return nil, err
+	}
+	// A failed extraction does not fail the upload; the document text job retries it
+	if err := s.ExtractVersionText(version); err != nil {
+		log.Printf("failed to store the text of proposal version %d: %v", version.ID, err)
+	}
+	return version, nil
+}
+
+// versionUpload is a proposal document that passed the type, size, quota and content checks
+type versionUpload struct {
+	file *multipart.FileHeader
+	hash string
+	meta domain.FileMetadata
+}
+
+// checkVersionFile vets a proposal document before anything is stored
+func (s *Service) checkVersionFile(file *multipart.FileHeader, teamID *uint, userID uint) (*versionUpload, error) {
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !allowedVersionFileTypes[ext] {
+		return nil, ErrVersionFileType
+	}
+	if file.Size > MaxVersionFileBytes {
+		return nil, ErrVersionFileTooLarge
+	}
+
+	var quotaTeamID uint
+	if teamID != nil {
+		quotaTeamID = *teamID
+	}
+	if err := s.quota.CheckUpload(quotaTeamID, userID, file.Size); err != nil {
+		return nil, err
+	}
+
+	meta, err := files.Inspect(file)
+	if err != nil {
+		return nil, err
+	}
+	if meta.ScanStatus == enums.ScanStatusInfected {
+		return nil, files.ErrFileInfected
+	}
+	hash, err := files.HashUpload(file)
+	if err != nil {
+		return nil, err
+	}
+	return &versionUpload{file: file, hash: hash, meta: meta}, nil
+}
+
+// saveVersionFile stores the document under the proposal's upload directory and returns its URL
+func (s *Service) saveVersionFile(upload *versionUpload, proposalID uint) (string, error) {
+	return s.uploader.SaveFile(upload.file, filepath.Join("proposals", fmt.Sprint(proposalID)))
+}
+
+// attach records the stored document on the version
+func (u *versionUpload) attach(version *domain.ProposalVersion, path string) {
+	version.FileURL = &path
+	version.FileHash = u.hash
+	version.FileSizeBytes = u.file.Size
+	version.FileMetadata = u.meta
+}
+
+// GetVersionFileHistory lists the files a version had, for whoever may view the proposal
+func (s *Service) GetVersionFileHistory(proposalID uint, versionID uint, userID uint, role enums.Role, userDeptID uint) (*VersionFileHistory, error) {
+	proposal, err := s.GetProposal(proposalID, userID, role, userDeptID)
+	if err != nil {
+		return nil, err
+	}
+	var version *domain.ProposalVersion
+	for i := range proposal.Versions {
+		if proposal.Versions[i].ID == versionID {
+			version = &proposal.Versions[i]
+			break
+		}
+	}
+	if version == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	replaced, err := s.repo.GetVersionFiles(proposal.ID, version.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionFileHistory{
+		VersionID:     version.ID,
+		VersionNumber: version.VersionNumber,
+		FileURL:       version.FileURL,
+		FileHash:      version.FileHash,
+		Replaced:      replaced,
+	}, nil
+}
+
+// isWorkingVersion tells whether the latest version is still being written: the draft itself,
+// or a revision created after feedback that the advisor has not reviewed
+func (s *Service) isWorkingVersion(proposal *domain.Proposal, latest *domain.ProposalVersion) bool {
+	switch proposal.Status {
+	case enums.ProposalStatusDraft:
+		return true
+	case enums.ProposalStatusRevisionRequired, enums.ProposalStatusRejected:
+		reviewedID, err := s.repo.GetLastReviewedVersionID(proposal.ID)
+		return err == nil && reviewedID != latest.ID
+	default:
+		return false
+	}
+}
+
+func hasVersion(proposal *domain.Proposal, versionID uint) bool {
+	for _, v := range proposal.Versions {
+		if v.ID == versionID {
+			return true
+		}
+	}
+	return false
+}
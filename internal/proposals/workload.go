@@ -0,0 +1,187 @@
+package proposals
+
+import (
+	"backend/pkg/settings"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	// WorkloadSnapshotInterval is how often the scheduler looks for advisors without a snapshot
+	// of the current week; each advisor gets one per week
+	WorkloadSnapshotInterval = 6 * time.Hour
+	// DefaultWorkloadWeeks is how far back the workload history goes unless asked otherwise
+	DefaultWorkloadWeeks = 52
+	// MaxWorkloadWeeks bounds the history, about five cohorts
+	MaxWorkloadWeeks = 260
+)
+
+var ErrAdvisorNotFound = errors.New("advisor not found")
+
+// WeeklyAverage is the department's average cohort load in one week
+type WeeklyAverage struct {
+	WeekStart        time.Time
+	AverageProposals float64
+	Advisors         int64
+}
+
+// WorkloadWeek is an advisor's weekly snapshot next to the department average. RelativeLoad is
+// the advisor's load over the average: 1 is a fair share, above 1 more than their colleagues.
+type WorkloadWeek struct {
+	WeekStart         time.Time `json:"week_start"`
+	AcademicYear      string    `json:"academic_year"`
+	Proposals         int64     `json:"proposals"`
+	ActiveProposals   int64     `json:"active_proposals"`
+	PendingReviews    int64     `json:"pending_reviews"`
+	Teams             int64     `json:"teams"`
+	ReviewsLastWeek   int64     `json:"reviews_last_week"`
+	Capacity          int       `json:"capacity"`
+	Utilization       float64   `json:"utilization"` // proposals over capacity
+	DepartmentAverage float64   `json:"department_average"`
+	DepartmentSize    int64     `json:"department_size"` // advisors in the department average
+	RelativeLoad      float64   `json:"relative_load"`
+}
+
+// CohortWorkload summarizes the weeks of one academic year
+type CohortWorkload struct {
+	AcademicYear       string  `json:"academic_year"`
+	Weeks              int     `json:"weeks"`
+	AverageLoad        float64 `json:"average_load"`
+	PeakLoad           int64   `json:"peak_load"`
+	AverageUtilization float64 `json:"average_utilization"`
+	DepartmentAverage  float64 `json:"department_average"`
+	RelativeLoad       float64 `json:"relative_load"`
+	Reviews            int64   `json:"reviews"` // feedback given over the captured weeks
+}
+
+// WorkloadHistory is an advisor's weekly workload, oldest first, with a summary per cohort
+type WorkloadHistory struct {
+	AdvisorID    uint             `json:"advisor_id"`
+	Name         string           `json:"name"`
+	DepartmentID uint             `json:"department_id"`
+	Since        time.Time        `json:"since"`
+	Weeks        []WorkloadWeek   `json:"weeks"`
+	Cohorts      []CohortWorkload `json:"cohorts"`
+}
+
+// weekStart is the Monday, 00:00 UTC, of the week containing t
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// CaptureWorkloadSnapshots records this week's workload of every active advisor (run by the scheduler)
+func (s *Service) CaptureWorkloadSnapshots() {
+	now := time.Now()
+	captured, err := s.repo.CaptureWorkloadSnapshots(weekStart(now), settings.AdvisorCapacity, s.settings.Int(settings.AdvisorCapacity, 0), now)
+	if err != nil {
+		log.Printf("failed to capture advisor workload snapshots: %v", err)
+		return
+	}
+	if captured > 0 {
+		log.Printf("Captured %d advisor workload snapshot(s)", captured)
+	}
+}
+
+// GetWorkloadHistory returns the weekly workload of an advisor of the admin's department over the
+// last weeks, compared with the department average
+func (s *Service) GetWorkloadHistory(advisorID uint, departmentID uint, weeks int) (*WorkloadHistory, error) {
+	if weeks <= 0 {
+		weeks = DefaultWorkloadWeeks
+	}
+	if weeks > MaxWorkloadWeeks {
+		return nil, fmt.Errorf("the history covers at most %d weeks", MaxWorkloadWeeks)
+	}
+
+	advisor, err := s.repo.GetAdvisor(advisorID)
+	if err != nil || advisor.DepartmentID != departmentID {
+		return nil, ErrAdvisorNotFound
+	}
+
+	since := weekStart(time.Now()).AddDate(0, 0, -7*(weeks-1))
+	snapshots, err := s.repo.GetWorkloadSnapshots(advisor.ID, since)
+	if err != nil {
+		return nil, err
+	}
+	averages, err := s.repo.GetDepartmentWorkloadAverages(departmentID, since)
+	if err != nil {
+		return nil, err
+	}
+	byWeek := make(map[string]WeeklyAverage, len(averages))
+	for _, avg := range averages {
+		byWeek[avg.WeekStart.Format("2006-01-02")] = avg
+	}
+
+	history := &WorkloadHistory{
+		AdvisorID:    advisor.ID,
+		Name:         advisor.Name,
+		DepartmentID: departmentID,
+		Since:        since,
+		Weeks:        make([]WorkloadWeek, 0, len(snapshots)),
+		Cohorts:      []CohortWorkload{},
+	}
+
+	// Snapshots are ordered by week, so each cohort's weeks are contiguous
+	cohortIndex := make(map[string]int)
+	for _, snap := range snapshots {
+		// Weeks the advisor spent in another department are compared with the current one
+		avg := byWeek[snap.WeekStart.Format("2006-01-02")]
+		week := WorkloadWeek{
+			WeekStart:         snap.WeekStart,
+			AcademicYear:      snap.AcademicYear,
+			Proposals:         snap.Proposals,
+			ActiveProposals:   snap.ActiveProposals,
+			PendingReviews:    snap.PendingReviews,
+			Teams:             snap.Teams,
+			ReviewsLastWeek:   snap.ReviewsLastWeek,
+			Capacity:          snap.Capacity,
+			Utilization:       ratio(float64(snap.Proposals), float64(snap.Capacity)),
+			DepartmentAverage: round2(avg.AverageProposals),
+			DepartmentSize:    avg.Advisors,
+			RelativeLoad:      ratio(float64(snap.Proposals), avg.AverageProposals),
+		}
+		history.Weeks = append(history.Weeks, week)
+
+		i, ok := cohortIndex[snap.AcademicYear]
+		if !ok {
+			i = len(history.Cohorts)
+			cohortIndex[snap.AcademicYear] = i
+			history.Cohorts = append(history.Cohorts, CohortWorkload{AcademicYear: snap.AcademicYear})
+		}
+		cohort := &history.Cohorts[i]
+		cohort.Weeks++
+		cohort.AverageLoad += float64(week.Proposals)
+		cohort.AverageUtilization += week.Utilization
+		cohort.DepartmentAverage += week.DepartmentAverage
+		cohort.Reviews += week.ReviewsLastWeek
+		if week.Proposals > cohort.PeakLoad {
+			cohort.PeakLoad = week.Proposals
+		}
+	}
+
+	for i := range history.Cohorts {
+		cohort := &history.Cohorts[i]
+		n := float64(cohort.Weeks)
+		cohort.RelativeLoad = ratio(cohort.AverageLoad, cohort.DepartmentAverage)
+		cohort.AverageLoad = round2(cohort.AverageLoad / n)
+		cohort.AverageUtilization = round2(cohort.AverageUtilization / n)
+		cohort.DepartmentAverage = round2(cohort.DepartmentAverage / n)
+	}
+	return history, nil
+}
+
+// ratio divides, rounding to two decimals; 0 when there is nothing to compare with
+func ratio(value, base float64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	return round2(value / base)
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,141 @@
+package realtime
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+type Handler struct {
+	service *Service
+	hub     *Hub
+}
+
+func NewHandler(s *Service, hub *Hub) *Handler {
+	return &Handler{service: s, hub: hub}
+}
+
+// Connect godoc
+// @Summary Open the realtime websocket
+// @Description Upgrades to a websocket that marks the user online while open and pushes domain events that affect them. Browsers can pass the JWT as access_token since they cannot set headers on websockets.
+// @Tags Realtime
+// @Security BearerAuth
+// @Param access_token query string false "JWT when the Authorization header cannot be set"
+// @Success 101
+// @Failure 401 {object} response.ErrorResponse
+// @Router /ws [get]
+func (h *Handler) Connect(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userID := claims.(*auth.TokenClaims).UserID
+
+	server := websocket.Server{
+		// Origins are not checked: the token authenticates the connection, as CORS allows any origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.hub.Serve(userID, conn)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// GetAdvisorPresence godoc
+// @Summary Get the team advisor's online status
+// @Description Whether the team's advisor currently has the app open, for office hours. Advisors who hide their status are reported as hidden.
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=Presence}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/advisor/presence [get]
+func (h *Handler) GetAdvisorPresence(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	teamID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid team ID", err.Error())
+		return
+	}
+
+	presence, err := h.service.GetAdvisorPresence(uint(teamID), userClaims.UserID, userClaims.Role, userClaims.DepartmentID)
+	if err != nil {
+		switch err.Error() {
+		case "team not found", "team has no advisor":
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case "you do not have permission to view this team":
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch presence", err.Error())
+		}
+		return
+	}
+	response.Success(c, presence)
+}
+
+// GetPresenceSettings godoc
+// @Summary Get my presence visibility
+// @Tags Realtime
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=PresenceSettings}
+// @Router /users/me/presence [get]
+func (h *Handler) GetPresenceSettings(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	settings, err := h.service.GetPresenceSettings(claims.(*auth.TokenClaims).UserID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// UpdatePresenceSettings godoc
+// @Summary Hide or show my online status
+// @Description Hidden users appear as "hidden" to teams instead of online or offline
+// @Tags Realtime
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body PresenceSettings true "Presence visibility"
+// @Success 200 {object} response.Response{data=PresenceSettings}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /users/me/presence [put]
+func (h *Handler) UpdatePresenceSettings(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	var req PresenceSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	settings, err := h.service.UpdatePresenceSettings(claims.(*auth.TokenClaims).UserID, req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to update presence settings", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Presence settings updated", settings)
+}
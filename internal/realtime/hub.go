@@ -0,0 +1,185 @@
+package realtime
+
+import (
+	"backend/pkg/events"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// PingInterval is how often idle connections get a ping message; a failed write drops the connection
+	PingInterval = 30 * time.Second
+	writeTimeout = 10 * time.Second
+	sendBuffer   = 16
+	maxIncoming  = 4 * 1024
+)
+
+// Message is what the hub writes to a connection
+type Message struct {
+	Type       string                 `json:"type"` // "event" or "ping"
+	Event      string                 `json:"event,omitempty"`
+	EntityType string                 `json:"entity_type,omitempty"`
+	EntityID   uint                   `json:"entity_id,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	SentAt     time.Time              `json:"sent_at"`
+}
+
+type client struct {
+	userID uint
+	conn   *websocket.Conn
+	send   chan Message
+}
+
+// Hub keeps the open websocket connections per user. A user is online while at least one of
+// their connections is open; the hub remembers when each user's last connection closed.
+type Hub struct {
+	mu       sync.RWMutex
+	clients  map[uint]map[*client]struct{}
+	lastSeen map[uint]time.Time
+	closed   bool
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients:  make(map[uint]map[*client]struct{}),
+		lastSeen: make(map[uint]time.Time),
+	}
+}
+
+// RegisterSubscribers pushes every domain event to the connected users it affects
+func (h *Hub) RegisterSubscribers(bus *events.Bus) {
+	bus.SubscribeAll(h.pushEvent)
+}
+
+// Presence reports whether the user has an open connection and when they were last connected
+func (h *Hub) Presence(userID uint) (bool, *time.Time) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.clients[userID]) > 0 {
+		now := time.Now()
+		return true, &now
+	}
+	if seen, ok := h.lastSeen[userID]; ok {
+		return false, &seen
+	}
+	return false, nil
+}
+
+// Serve runs one connection until the client disconnects or the hub closes
+func (h *Hub) Serve(userID uint, conn *websocket.Conn) {
+	conn.MaxPayloadBytes = maxIncoming
+	c := &client{userID: userID, conn: conn, send: make(chan Message, sendBuffer)}
+	if !h.register(c) {
+		conn.Close()
+		return
+	}
+	defer h.unregister(c)
+
+	go h.writeLoop(c)
+
+	// Incoming messages are only keep-alives; reading detects the client going away
+	for {
+		var ignored string
+		if err := websocket.Message.Receive(conn, &ignored); err != nil {
+			return
+		}
+	}
+}
+
+// Close disconnects every client; used on shutdown
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, conns := range h.clients {
+		for c := range conns {
+			c.conn.Close()
+		}
+	}
+}
+
+func (h *Hub) register(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*client]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+	return true
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c.userID][c]; !ok {
+		return
+	}
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+	h.lastSeen[c.userID] = time.Now()
+	close(c.send)
+	c.conn.Close()
+}
+
+func (h *Hub) writeLoop(c *client) {
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+
+	for {
+		var msg Message
+		select {
+		case m, ok := <-c.send:
+			if !ok {
+				return
+			}
+			msg = m
+		case <-ticker.C:
+			msg = Message{Type: "ping", SentAt: time.Now()}
+		}
+
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			log.Printf("failed to encode websocket message: %v", err)
+			continue
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := websocket.Message.Send(c.conn, string(payload)); err != nil {
+			// Unblocks the read loop, which unregisters the client
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+func (h *Hub) pushEvent(e events.Event) {
+	msg := Message{
+		Type:       "event",
+		Event:      string(e.Name),
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Data:       e.Data,
+		SentAt:     e.OccurredAt,
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, userID := range e.UserIDs {
+		for c := range h.clients[userID] {
+			select {
+			case c.send <- msg:
+			default:
+				// Slow client: drop the message rather than block the event bus
+			}
+		}
+	}
+}
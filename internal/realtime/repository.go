@@ -0,0 +1,37 @@
+package realtime
+
+import (
+	"backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetTeam(teamID uint) (*domain.Team, error)
+	GetUser(userID uint) (*domain.User, error)
+	SetPresenceHidden(userID uint, hidden bool) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetTeam(teamID uint) (*domain.Team, error) {
+	var team domain.Team
+	err := r.db.Preload("Members").First(&team, teamID).Error
+	return &team, err
+}
+
+func (r *repository) GetUser(userID uint) (*domain.User, error) {
+	var user domain.User
+	err := r.db.First(&user, userID).Error
+	return &user, err
+}
+
+func (r *repository) SetPresenceHidden(userID uint, hidden bool) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", userID).Update("presence_hidden", hidden).Error
+}
@@ -0,0 +1,88 @@
+package realtime
+
+import (
+	"backend/pkg/enums"
+	"errors"
+	"time"
+)
+
+type Service struct {
+	repo Repository
+	hub  *Hub
+}
+
+func NewService(repo Repository, hub *Hub) *Service {
+	return &Service{repo: repo, hub: hub}
+}
+
+// Presence is whether a user is connected. Hidden users are reported with status "hidden" and no times.
+type Presence struct {
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"` // "online", "offline" or "hidden"
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// PresenceSettings is a user's own presence visibility
+type PresenceSettings struct {
+	Hidden bool `json:"hidden"`
+}
+
+// GetAdvisorPresence reports whether the team's advisor is online. Team members, the advisor
+// and admins of the team's department may ask.
+func (s *Service) GetAdvisorPresence(teamID uint, userID uint, role enums.Role, departmentID uint) (*Presence, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+
+	allowed := role == enums.RoleAdmin && team.DepartmentID == departmentID
+	if team.AdvisorID != nil && *team.AdvisorID == userID {
+		allowed = true
+	}
+	for _, m := range team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, errors.New("you do not have permission to view this team")
+	}
+
+	if team.AdvisorID == nil {
+		return nil, errors.New("team has no advisor")
+	}
+	advisor, err := s.repo.GetUser(*team.AdvisorID)
+	if err != nil {
+		return nil, errors.New("team has no advisor")
+	}
+
+	presence := &Presence{UserID: advisor.ID, Name: advisor.Name, Status: "hidden"}
+	if advisor.PresenceHidden {
+		return presence, nil
+	}
+	online, lastSeen := s.hub.Presence(advisor.ID)
+	presence.Status = "offline"
+	if online {
+		presence.Status = "online"
+	}
+	presence.LastSeenAt = lastSeen
+	return presence, nil
+}
+
+// UpdatePresenceSettings lets a user hide or show their online status
+func (s *Service) UpdatePresenceSettings(userID uint, settings PresenceSettings) (*PresenceSettings, error) {
+	if err := s.repo.SetPresenceHidden(userID, settings.Hidden); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// GetPresenceSettings returns the user's presence visibility
+func (s *Service) GetPresenceSettings(userID uint) (*PresenceSettings, error) {
+	user, err := s.repo.GetUser(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &PresenceSettings{Hidden: user.PresenceHidden}, nil
+}
@@ -2,7 +2,10 @@ package reviews
 
 import (
 	"backend/internal/auth"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
 	"backend/pkg/response"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -27,7 +30,8 @@ type CreateReviewRequest struct {
 
 // CreateReview creates a new review for a project
 // @Summary Add project review
-// @Description Add a review and rating to a public project
+// @Description Add a review and rating to a public project. A comment containing one of the department's
+// @Description banned words is held for moderation and stays hidden until an admin approves it.
 // @Tags Reviews
 // @Accept json
 // @Produce json
@@ -76,7 +80,11 @@ func (h *Handler) CreateReview(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusCreated, "Review submitted", gin.H{
+	message := "Review submitted"
+	if review.ModerationStatus == enums.ReviewModerationPending {
+		message = "Review submitted for moderation"
+	}
+	response.JSON(c, http.StatusCreated, message, gin.H{
 		"review":          review,
 		"updated_average": avgRating,
 	})
@@ -115,3 +123,167 @@ func (h *Handler) GetProjectReviews(c *gin.Context) {
 		"total_reviews":  len(reviews),
 	})
 }
+
+// GetModerationQueue godoc
+// @Summary List reviews held for moderation
+// @Description Reviews of the department's projects that matched a banned word, oldest first. Filter by status to see past decisions.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending (default), approved or rejected"
+// @Success 200 {object} response.Response{data=[]domain.ProjectReview}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /admin/reviews/moderation [get]
+func (h *Handler) GetModerationQueue(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	reviews, err := h.service.GetModerationQueue(claims.DepartmentID, c.Query("status"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Failed to fetch moderation queue", err.Error())
+		return
+	}
+	response.Success(c, reviews)
+}
+
+// ModerateReview godoc
+// @Summary Approve or reject a flagged review
+// @Description Approving publishes the review and counts its rating; rejecting keeps it hidden. The decision is audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Review ID"
+// @Param request body ModerateRequest true "Decision"
+// @Success 200 {object} response.Response{data=domain.ProjectReview}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/reviews/moderation/{id} [post]
+func (h *Handler) ModerateReview(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid review ID", err.Error())
+		return
+	}
+	var req ModerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	review, err := h.service.ModerateReview(uint(id), req, claims.UserID, claims.DepartmentID, audit.NewRequestMeta(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrReviewNotFound):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrReviewNotPending):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to moderate review", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Review "+string(review.ModerationStatus), review)
+}
+
+// GetFilters godoc
+// @Summary List the banned words for project reviews
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]domain.ReviewFilter}
+// @Router /admin/reviews/filters [get]
+func (h *Handler) GetFilters(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	filters, err := h.service.GetFilters(claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch filters", err.Error())
+		return
+	}
+	response.Success(c, filters)
+}
+
+// AddFilter godoc
+// @Summary Ban a word or phrase in project reviews
+// @Description New reviews of the department's projects containing the term, as a whole word and in any case, are held for moderation
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddFilterRequest true "Term"
+// @Success 201 {object} response.Response{data=domain.ReviewFilter}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/reviews/filters [post]
+func (h *Handler) AddFilter(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	var req AddFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	filter, err := h.service.AddFilter(req, claims.UserID, claims.DepartmentID, audit.NewRequestMeta(c))
+	if err != nil {
+		if errors.Is(err, ErrFilterExists) {
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Failed to add filter", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Filter added", filter)
+}
+
+// DeleteFilter godoc
+// @Summary Remove a banned word
+// @Description Reviews the term already flagged stay in the moderation queue
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Filter ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/reviews/filters/{id} [delete]
+func (h *Handler) DeleteFilter(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid filter ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeleteFilter(uint(id), claims.UserID, claims.DepartmentID, audit.NewRequestMeta(c)); err != nil {
+		if errors.Is(err, ErrFilterNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to remove filter", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Filter removed", nil)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
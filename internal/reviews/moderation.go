@@ -0,0 +1,168 @@
+package reviews
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxFilterTermLength caps a banned word or phrase
+const MaxFilterTermLength = 100
+
+var (
+	ErrReviewNotFound   = errors.New("review not found")
+	ErrReviewNotPending = errors.New("the review is not awaiting moderation")
+	ErrFilterNotFound   = errors.New("filter not found")
+	ErrFilterExists     = errors.New("the department already filters this term")
+)
+
+// ModerateRequest approves a flagged review, making it public, or rejects it
+type ModerateRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve reject" example:"reject"`
+	Note     string `json:"note" example:"Insulting language"`
+}
+
+// AddFilterRequest adds a banned word or phrase; matching ignores case and only hits whole words
+type AddFilterRequest struct {
+	Term string `json:"term" binding:"required" example:"scam"`
+}
+
+// GetModerationQueue lists the department's reviews in the given state, pending when empty
+func (s *Service) GetModerationQueue(departmentID uint, status string) ([]domain.ProjectReview, error) {
+	if status == "" {
+		status = string(enums.ReviewModerationPending)
+	}
+	switch enums.ReviewModerationStatus(status) {
+	case enums.ReviewModerationPending, enums.ReviewModerationApproved, enums.ReviewModerationRejected:
+	default:
+		return nil, errors.New("status must be pending, approved or rejected")
+	}
+	return s.repo.GetModerationQueue(departmentID, enums.ReviewModerationStatus(status))
+}
+
+// ModerateReview records an admin's decision on a flagged review of their department's project
+func (s *Service) ModerateReview(id uint, req ModerateRequest, adminID, departmentID uint, meta audit.RequestMeta) (*domain.ProjectReview, error) {
+	review, err := s.repo.GetByID(id)
+	if err != nil || review.Project == nil || review.Project.DepartmentID != departmentID {
+		return nil, ErrReviewNotFound
+	}
+	if review.ModerationStatus != enums.ReviewModerationPending {
+		return nil, ErrReviewNotPending
+	}
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	status := enums.ReviewModerationApproved
+	if req.Decision == "reject" {
+		status = enums.ReviewModerationRejected
+	}
+	now := time.Now()
+	review.ModerationStatus = status
+	review.ModeratedBy = &admin.ID
+	review.ModeratedAt = &now
+	review.ModerationNote = strings.TrimSpace(req.Note)
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+
+	err = s.auditLogger.LogAction("project_review", review.ID, "review_"+string(status), &admin.ID, string(admin.Role), admin.Email,
+		map[string]interface{}{"moderation_status": enums.ReviewModerationPending},
+		map[string]interface{}{
+			"moderation_status": status,
+			"project_id":        review.ProjectID,
+			"flagged_terms":     review.FlaggedTerms,
+			"note":              review.ModerationNote,
+		}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit the moderation of review %d: %v", review.ID, err)
+	}
+	return review, nil
+}
+
+// GetFilters lists the department's banned words
+func (s *Service) GetFilters(departmentID uint) ([]domain.ReviewFilter, error) {
+	return s.repo.GetFilters(departmentID)
+}
+
+// AddFilter bans a word or phrase in the reviews of the department's projects. Reviews already
+// public are not re-checked.
+func (s *Service) AddFilter(req AddFilterRequest, adminID, departmentID uint, meta audit.RequestMeta) (*domain.ReviewFilter, error) {
+	term := normalizeTerm(req.Term)
+	if term == "" {
+		return nil, errors.New("term is required")
+	}
+	if len(term) > MaxFilterTermLength {
+		return nil, errors.New("terms are limited to 100 characters")
+	}
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	filter := &domain.ReviewFilter{DepartmentID: departmentID, Term: term, CreatedBy: admin.ID, CreatedAt: time.Now()}
+	if err := s.repo.CreateFilter(filter); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrFilterExists
+		}
+		return nil, err
+	}
+
+	err = s.auditLogger.LogAction("review_filter", filter.ID, "review_filter_added", &admin.ID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{"term": term}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit the added review filter %d: %v", filter.ID, err)
+	}
+	return filter, nil
+}
+
+// DeleteFilter removes one of the department's banned words. Reviews it flagged stay in the queue.
+func (s *Service) DeleteFilter(id, adminID, departmentID uint, meta audit.RequestMeta) error {
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return errors.New("admin not found")
+	}
+	deleted, err := s.repo.DeleteFilter(id, departmentID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrFilterNotFound
+	}
+
+	err = s.auditLogger.LogAction("review_filter", id, "review_filter_removed", &admin.ID, string(admin.Role), admin.Email, nil, nil,
+		meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit the removed review filter %d: %v", id, err)
+	}
+	return nil
+}
+
+// flaggedTerms returns the filtered terms the text contains as whole words, ignoring case
+func flaggedTerms(text string, filters []domain.ReviewFilter) []string {
+	text = normalizeTerm(text)
+	var matched []string
+	for _, f := range filters {
+		if f.Term == "" || !strings.Contains(text, f.Term) {
+			continue
+		}
+		pattern := `(^|[^\pL\pN])` + regexp.QuoteMeta(f.Term) + `($|[^\pL\pN])`
+		if regexp.MustCompile(pattern).MatchString(text) {
+			matched = append(matched, f.Term)
+		}
+	}
+	return matched
+}
+
+// normalizeTerm lowercases a term and collapses its whitespace
+func normalizeTerm(term string) string {
+	return strings.ToLower(strings.Join(strings.Fields(term), " "))
+}
@@ -2,8 +2,10 @@ package reviews
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/enums"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository defines the interface for project review data access
@@ -14,6 +16,14 @@ type Repository interface {
 	GetAverageRating(projectID uint) (float64, error)
 	Update(review *domain.ProjectReview) error
 	Delete(id uint) error
+
+	// Moderation
+	GetByID(id uint) (*domain.ProjectReview, error)
+	GetModerationQueue(departmentID uint, status enums.ReviewModerationStatus) ([]domain.ProjectReview, error)
+	GetFilters(departmentID uint) ([]domain.ReviewFilter, error)
+	CreateFilter(filter *domain.ReviewFilter) error
+	DeleteFilter(id, departmentID uint) (bool, error)
+	GetUser(id uint) (*domain.User, error)
 }
 
 type repository struct {
@@ -31,7 +41,7 @@ func (r *repository) Create(review *domain.ProjectReview) error {
 
 func (r *repository) GetByProjectID(projectID uint) ([]domain.ProjectReview, error) {
 	var reviews []domain.ProjectReview
-	err := r.db.Where("project_id = ?", projectID).
+	err := r.db.Where("project_id = ? AND moderation_status = ?", projectID, enums.ReviewModerationApproved).
 		Preload("User").
 		Order("created_at DESC").
 		Find(&reviews).Error
@@ -50,7 +60,7 @@ func (r *repository) GetByUserAndProject(userID, projectID uint) (*domain.Projec
 func (r *repository) GetAverageRating(projectID uint) (float64, error) {
 	var avg float64
 	err := r.db.Model(&domain.ProjectReview{}).
-		Where("project_id = ?", projectID).
+		Where("project_id = ? AND moderation_status = ?", projectID, enums.ReviewModerationApproved).
 		Preload("User").
 		Select("COALESCE(AVG(rate), 0)").
 		Scan(&avg).Error
@@ -58,9 +68,54 @@ func (r *repository) GetAverageRating(projectID uint) (float64, error) {
 }
 
 func (r *repository) Update(review *domain.ProjectReview) error {
-	return r.db.Save(review).Error
+	return r.db.Omit(clause.Associations).Save(review).Error
 }
 
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&domain.ProjectReview{}, id).Error
 }
+
+func (r *repository) GetByID(id uint) (*domain.ProjectReview, error) {
+	var review domain.ProjectReview
+	if err := r.db.Preload("User").Preload("Project").First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetModerationQueue lists the reviews of the department's projects in a moderation state, oldest first
+func (r *repository) GetModerationQueue(departmentID uint, status enums.ReviewModerationStatus) ([]domain.ProjectReview, error) {
+	var reviews []domain.ProjectReview
+	err := r.db.
+		Joins("JOIN projects ON projects.id = project_reviews.project_id").
+		Where("projects.department_id = ? AND project_reviews.moderation_status = ?", departmentID, status).
+		Preload("User").
+		Preload("Project").
+		Order("project_reviews.created_at ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *repository) GetFilters(departmentID uint) ([]domain.ReviewFilter, error) {
+	var filters []domain.ReviewFilter
+	err := r.db.Where("department_id = ?", departmentID).Order("term ASC").Find(&filters).Error
+	return filters, err
+}
+
+func (r *repository) CreateFilter(filter *domain.ReviewFilter) error {
+	return r.db.Create(filter).Error
+}
+
+// DeleteFilter removes one of the department's filters and reports whether it existed
+func (r *repository) DeleteFilter(id, departmentID uint) (bool, error) {
+	res := r.db.Where("id = ? AND department_id = ?", id, departmentID).Delete(&domain.ReviewFilter{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
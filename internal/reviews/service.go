@@ -2,14 +2,20 @@ package reviews
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
 	"errors"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Service handles project review business logic
 type Service struct {
 	repo        Repository
 	projectRepo ProjectRepository
+	auditLogger *audit.Logger
 }
 
 // ProjectRepository interface for accessing project data
@@ -18,14 +24,16 @@ type ProjectRepository interface {
 }
 
 // NewService creates a new review service
-func NewService(repo Repository, projectRepo ProjectRepository) *Service {
+func NewService(repo Repository, projectRepo ProjectRepository, auditLogger *audit.Logger) *Service {
 	return &Service{
 		repo:        repo,
 		projectRepo: projectRepo,
+		auditLogger: auditLogger,
 	}
 }
 
-// CreateReview creates a new review for a project
+// CreateReview creates a new review for a project. A comment containing one of the department's
+// banned words is held for moderation and does not count towards the average until approved.
 func (s *Service) CreateReview(userID, projectID uint, rating int, comment string) (*domain.ProjectReview, float64, error) {
 	// Verify project exists and is public
 	project, err := s.projectRepo.GetByID(projectID)
@@ -50,14 +58,22 @@ func (s *Service) CreateReview(userID, projectID uint, rating int, comment strin
 
 	// Create review
 	review := &domain.ProjectReview{
-		ProjectID: projectID,
-		UserID:    userID,
-		Rate:      rating,
-		Comment:   comment,
-		CreatedAt: time.Now(),
+		ProjectID:        projectID,
+		UserID:           userID,
+		Rate:             rating,
+		Comment:          comment,
+		CreatedAt:        time.Now(),
+		ModerationStatus: enums.ReviewModerationApproved,
+	}
+	if err := s.screen(review, project.DepartmentID); err != nil {
+		return nil, 0, err
 	}
 
 	if err := s.repo.Create(review); err != nil {
+		// A concurrent review by the same user lost the race to the unique index
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, 0, errors.New("you have already reviewed this project")
+		}
 		return nil, 0, err
 	}
 
@@ -107,8 +123,15 @@ func (s *Service) UpdateReview(reviewID, userID uint, rating int, comment string
 		review.Rate = rating
 	}
 
-	if comment != "" {
+	if comment != "" && comment != review.Comment {
 		review.Comment = comment
+		project, err := s.projectRepo.GetByID(review.ProjectID)
+		if err != nil {
+			return nil, errors.New("project not found")
+		}
+		if err := s.screen(review, project.DepartmentID); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.repo.Update(review); err != nil {
@@ -127,3 +150,19 @@ func (s *Service) DeleteReview(reviewID, userID uint, isAdmin bool) error {
 
 	return s.repo.Delete(reviewID)
 }
+
+// screen holds the review for moderation when its comment contains a banned word of the department
+func (s *Service) screen(review *domain.ProjectReview, departmentID uint) error {
+	filters, err := s.repo.GetFilters(departmentID)
+	if err != nil {
+		return err
+	}
+	if terms := flaggedTerms(review.Comment, filters); len(terms) > 0 {
+		review.ModerationStatus = enums.ReviewModerationPending
+		review.FlaggedTerms = strings.Join(terms, ", ")
+		review.ModeratedBy = nil
+		review.ModeratedAt = nil
+		review.ModerationNote = ""
+	}
+	return nil
+}
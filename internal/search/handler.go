@@ -0,0 +1,86 @@
+package search
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// Search godoc
+// @Summary Search users, teams, proposals and projects
+// @Description One search box for admins: matches user names, emails and student IDs, team names, proposal titles and project titles, slugs and summaries, and the words of proposal PDFs in the admin's department (or university, for admins without one). Results are mixed and tagged with their type, exact matches first, then prefix matches. Types the admin's role cannot open are left out: users need user.manage; teams, proposals and projects need proposal.assign.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text, at least 2 characters"
+// @Param types query string false "Comma-separated types to search: user, team, proposal, project"
+// @Param limit query int false "Results per type (default 5, max 20)"
+// @Success 200 {object} response.Response{data=Results}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/search [get]
+func (h *Handler) Search(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	query := Query{
+		Q:            c.Query("q"),
+		Role:         claims.Role,
+		DepartmentID: claims.DepartmentID,
+		UniversityID: claims.UniversityID,
+	}
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				query.Types = append(query.Types, t)
+			}
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid limit", err.Error())
+			return
+		}
+		query.Limit = limit
+	}
+
+	results, err := h.service.Search(query)
+	if err != nil {
+		if errors.Is(err, ErrNoSearchTypes) {
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, ErrQueryTooShort) || errors.Is(err, ErrUnknownType) {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Search failed", err.Error())
+		return
+	}
+	response.Success(c, results)
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"backend/pkg/database"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	SearchUsers(scope Scope, q string, limit int) ([]Result, error)
+	SearchTeams(scope Scope, q string, limit int) ([]Result, error)
+	SearchProposals(scope Scope, q string, limit int) ([]Result, error)
+	SearchProjects(scope Scope, q string, limit int) ([]Result, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Scope limits results to the admin's department, or to the university for admins without one
+type Scope struct {
+	DepartmentID uint
+	UniversityID uint
+}
+
+// departmentFilter restricts a department_id column to the scope
+func (s Scope) departmentFilter(column string) clause.Expr {
+	if s.DepartmentID != 0 {
+		return clause.Expr{SQL: column + " = ?", Vars: []interface{}{s.DepartmentID}}
+	}
+	return clause.Expr{SQL: column + " IN (SELECT id FROM departments WHERE university_id = ?)", Vars: []interface{}{s.UniversityID}}
+}
+
+// patterns returns the exact, prefix and contains patterns for q
+func patterns(q string) (exact, prefix, contains string) {
+	escaped := database.EscapeLike(q)
+	return escaped, escaped + "%", "%" + escaped + "%"
+}
+
+// documentMatch is a full-text match of the query against the text extracted from the latest
+// version's PDF. The expression is the one indexed by idx_proposal_version_texts_search.
+const documentMatch = `EXISTS (SELECT 1 FROM proposal_version_texts dt WHERE dt.version_id = latest.id
+	AND to_tsvector('english', dt.content) @@ plainto_tsquery('english', ?))`
+
+// rankBy orders exact matches of any column first, then prefix matches, then the rest
+func rankBy(q string, columns ...string) clause.Expr {
+	exact, prefix, _ := patterns(q)
+	var exactSQL, prefixSQL []string
+	var vars []interface{}
+	for _, column := range columns {
+		exactSQL = append(exactSQL, column+" ILIKE ?")
+		vars = append(vars, exact)
+	}
+	for _, column := range columns {
+		prefixSQL = append(prefixSQL, column+" ILIKE ?")
+		vars = append(vars, prefix)
+	}
+	return clause.Expr{
+		SQL:  "CASE WHEN " + strings.Join(exactSQL, " OR ") + " THEN 0 WHEN " + strings.Join(prefixSQL, " OR ") + " THEN 1 ELSE 2 END",
+		Vars: vars,
+	}
+}
+
+func (r *repository) SearchUsers(scope Scope, q string, limit int) ([]Result, error) {
+	_, _, contains := patterns(q)
+	rank := rankBy(q, "users.name", "users.email", "users.student_id")
+
+	query := r.db.Table("users").
+		Select("users.id, users.name AS title, users.email AS subtitle, users.role AS status, ? AS match_rank", rank).
+		Where("users.deleted_at IS NULL AND users.anonymized_at IS NULL").
+		Where("users.name ILIKE ? OR users.email ILIKE ? OR users.student_id ILIKE ?", contains, contains, contains)
+	if scope.DepartmentID != 0 {
+		query = query.Where("users.department_id = ?", scope.DepartmentID)
+	} else {
+		query = query.Where("users.university_id = ?", scope.UniversityID)
+	}
+
+	var results []Result
+	err := query.Order(clause.Expr{SQL: "match_rank, users.name"}).Limit(limit).Scan(&results).Error
+	return results, err
+}
+
+func (r *repository) SearchTeams(scope Scope, q string, limit int) ([]Result, error) {
+	_, _, contains := patterns(q)
+	rank := rankBy(q, "teams.name")
+
+	var results []Result
+	err := r.db.Table("teams").
+		Select(`teams.id, teams.name AS title, teams.academic_year AS subtitle,
+			CASE WHEN teams.is_archived THEN 'archived' WHEN teams.is_finalized THEN 'finalized' ELSE 'forming' END AS status,
+			? AS match_rank`, rank).
+		Where(scope.departmentFilter("teams.department_id")).
+		Where("teams.name ILIKE ?", contains).
+		Order(clause.Expr{SQL: "match_rank, teams.is_archived, teams.name"}).
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
+
+// SearchProposals matches the title of each proposal's latest version, the team's name, and the
+// words of the latest version's PDF
+func (r *repository) SearchProposals(scope Scope, q string, limit int) ([]Result, error) {
+	_, _, contains := patterns(q)
+	rank := rankBy(q, "latest.title")
+
+	var results []Result
+	err := r.db.Table("proposals").
+		Select("proposals.id, latest.title, COALESCE(teams.name, '') AS subtitle, proposals.status, ? AS match_rank", rank).
+		Joins(`JOIN LATERAL (SELECT id, title FROM proposal_versions
+			WHERE proposal_versions.proposal_id = proposals.id
+			ORDER BY version_number DESC LIMIT 1) latest ON true`).
+		Joins("LEFT JOIN teams ON teams.id = proposals.team_id").
+		Joins("JOIN users creator ON creator.id = proposals.created_by").
+		Where(scope.departmentFilter("COALESCE(teams.department_id, creator.department_id)")).
+		Where("latest.title ILIKE ? OR teams.name ILIKE ? OR "+documentMatch, contains, contains, q).
+		Order(clause.Expr{SQL: "match_rank, proposals.is_archived, proposals.updated_at DESC"}).
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
+
+// SearchProjects matches the project's title (its proposal's latest version), slug, summary and
+// the words of the proposal's latest PDF
+func (r *repository) SearchProjects(scope Scope, q string, limit int) ([]Result, error) {
+	_, _, contains := patterns(q)
+	rank := rankBy(q, "latest.title", "projects.slug")
+
+	var results []Result
+	err := r.db.Table("projects").
+		Select("projects.id, latest.title, COALESCE(projects.slug, teams.name, '') AS subtitle, projects.visibility AS status, ? AS match_rank", rank).
+		Joins(`JOIN LATERAL (SELECT id, title FROM proposal_versions
+			WHERE proposal_versions.proposal_id = projects.proposal_id
+			ORDER BY version_number DESC LIMIT 1) latest ON true`).
+		Joins("LEFT JOIN teams ON teams.id = projects.team_id").
+		Where(scope.departmentFilter("projects.department_id")).
+		Where("latest.title ILIKE ? OR projects.slug ILIKE ? OR projects.summary ILIKE ? OR "+documentMatch, contains, contains, contains, q).
+		Order(clause.Expr{SQL: "match_rank, projects.created_at DESC"}).
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
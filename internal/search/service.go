@@ -0,0 +1,157 @@
+package search
+
+import (
+	"backend/internal/permissions"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Result types, in the order mixed results of the same rank are listed
+const (
+	TypeUser     = "user"
+	TypeTeam     = "team"
+	TypeProposal = "proposal"
+	TypeProject  = "project"
+)
+
+var resultTypes = []string{TypeUser, TypeTeam, TypeProposal, TypeProject}
+
+// typePermissions is what an admin needs to see each result type; types the admin cannot open are left out
+var typePermissions = map[string]permissions.Permission{
+	TypeUser:     permissions.UserManage,
+	TypeTeam:     permissions.ProposalAssign,
+	TypeProposal: permissions.ProposalAssign,
+	TypeProject:  permissions.ProposalAssign,
+}
+
+const (
+	MinQueryLength = 2
+	DefaultLimit   = 5  // per type
+	MaxLimit       = 20 // per type
+)
+
+var (
+	ErrQueryTooShort = fmt.Errorf("the search needs at least %d characters", MinQueryLength)
+	ErrNoSearchTypes = errors.New("you do not have permission to search any of the requested types")
+	ErrUnknownType   = errors.New("unknown search type")
+)
+
+// Result is one match, tagged with its type and the admin page it opens
+type Result struct {
+	Type      string `json:"type"`
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	Subtitle  string `json:"subtitle,omitempty"` // email, cohort, team name or slug
+	Status    string `json:"status,omitempty"`   // role, team state, proposal status or project visibility
+	URL       string `json:"url"`
+	MatchRank int    `json:"-"` // 0 exact, 1 prefix, 2 anywhere
+}
+
+// Results are the mixed matches, best first, with the number found per type
+type Results struct {
+	Query   string         `json:"query"`
+	Results []Result       `json:"results"`
+	Counts  map[string]int `json:"counts"`
+}
+
+// Query is a search with the searching admin's scope and rights
+type Query struct {
+	Q            string
+	Types        []string // empty for every type the admin may see
+	Limit        int      // per type
+	Role         enums.Role
+	DepartmentID uint
+	UniversityID uint
+}
+
+type Service struct {
+	repo       Repository
+	authorizer *permissions.Authorizer
+}
+
+func NewService(r Repository, a *permissions.Authorizer) *Service {
+	return &Service{repo: r, authorizer: a}
+}
+
+// Search finds users, teams, proposals and projects of the admin's department (or university, for
+// admins without a department) in one go. Each type is only searched when the admin's role has the
+// permission to open it; exact matches come first, then prefix matches, then the rest.
+func (s *Service) Search(query Query) (*Results, error) {
+	q := strings.TrimSpace(query.Q)
+	if utf8.RuneCountInString(q) < MinQueryLength {
+		return nil, ErrQueryTooShort
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	wanted := query.Types
+	if len(wanted) == 0 {
+		wanted = resultTypes
+	}
+	var types []string
+	for _, t := range wanted {
+		permission, ok := typePermissions[t]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownType, t)
+		}
+		if s.authorizer.Can(query.Role, query.DepartmentID, permission) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return nil, ErrNoSearchTypes
+	}
+
+	scope := Scope{DepartmentID: query.DepartmentID, UniversityID: query.UniversityID}
+	results := &Results{Query: q, Results: []Result{}, Counts: make(map[string]int, len(types))}
+	for _, t := range types {
+		var found []Result
+		var err error
+		switch t {
+		case TypeUser:
+			found, err = s.repo.SearchUsers(scope, q, limit)
+		case TypeTeam:
+			found, err = s.repo.SearchTeams(scope, q, limit)
+		case TypeProposal:
+			found, err = s.repo.SearchProposals(scope, q, limit)
+		case TypeProject:
+			found, err = s.repo.SearchProjects(scope, q, limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			found[i].Type = t
+			found[i].URL = resultURL(t, found[i].ID)
+		}
+		results.Counts[t] = len(found)
+		results.Results = append(results.Results, found...)
+	}
+
+	// Types were appended in listing order, so a stable sort keeps it within a rank
+	sort.SliceStable(results.Results, func(i, j int) bool {
+		return results.Results[i].MatchRank < results.Results[j].MatchRank
+	})
+	return results, nil
+}
+
+func resultURL(t string, id uint) string {
+	switch t {
+	case TypeUser:
+		return fmt.Sprintf("/admin/users/%d", id)
+	case TypeTeam:
+		return fmt.Sprintf("/teams/%d", id)
+	case TypeProposal:
+		return fmt.Sprintf("/proposals/%d", id)
+	}
+	return fmt.Sprintf("/projects/%d", id)
+}
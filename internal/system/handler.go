@@ -0,0 +1,61 @@
+package system
+
+import (
+	"backend/config"
+	"backend/pkg/database"
+	"backend/pkg/deadletter"
+	"backend/pkg/health"
+	"backend/pkg/maintenance"
+	"backend/pkg/response"
+	"backend/pkg/settings"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	cfg         config.Config
+	migrator    *database.Migrator
+	deadLetters *deadletter.Queue
+	maintenance *maintenance.Mode
+	health      *health.Checker
+	settings    *settings.Store
+}
+
+func NewHandler(cfg config.Config, migrator *database.Migrator, deadLetters *deadletter.Queue, maintenanceMode *maintenance.Mode, checker *health.Checker, settingsStore *settings.Store) *Handler {
+	return &Handler{cfg: cfg, migrator: migrator, deadLetters: deadLetters, maintenance: maintenanceMode, health: checker, settings: settingsStore}
+}
+
+// GetConfig godoc
+// @Summary Get effective configuration
+// @Description Read-only view of the loaded configuration and the layers it came from. Secrets are redacted.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/config [get]
+func (h *Handler) GetConfig(c *gin.Context) {
+	response.Success(c, h.cfg.Redacted())
+}
+
+// GetMigrations godoc
+// @Summary Get database migration status
+// @Description Lists the versioned migrations with whether each is applied, migrations applied by a newer build, and schema drift against the domain models
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=database.SchemaStatus}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/migrations [get]
+func (h *Handler) GetMigrations(c *gin.Context) {
+	status, err := h.migrator.Status()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to read migration status", err.Error())
+		return
+	}
+	response.Success(c, status)
+}
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"backend/pkg/health"
+	"backend/pkg/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthStatus is the health report with the maintenance flag. Database repeats the database
+// check as "connected" or "disconnected" for probes written against the earlier endpoint.
+type HealthStatus struct {
+	health.Report
+	Database    string `json:"database"`
+	Maintenance bool   `json:"maintenance"`
+}
+
+// Health godoc
+// @Summary Health check
+// @Description Overall status (ok, degraded or down) with the status, latency and metrics of each dependency: database, storage, AI service, mail server and background queues. Returns 503 only when a critical dependency (the database) is down, so uptime monitors can alert on the status code and dashboards on the levels. Results are cached for a few seconds.
+// @Tags System
+// @Produce json
+// @Success 200 {object} response.Response{data=HealthStatus}
+// @Failure 503 {object} response.Response{data=HealthStatus}
+// @Router /health [get]
+func (h *Handler) Health(c *gin.Context) {
+	h.writeHealth(c, h.health.Run(c.Request.Context()).Public())
+}
+
+// GetHealth godoc
+// @Summary Detailed health check
+// @Description Same as /health, including the underlying error of each failing dependency
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=HealthStatus}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 503 {object} response.Response{data=HealthStatus}
+// @Router /admin/health [get]
+func (h *Handler) GetHealth(c *gin.Context) {
+	h.writeHealth(c, h.health.Run(c.Request.Context()))
+}
+
+func (h *Handler) writeHealth(c *gin.Context, report health.Report) {
+	status := HealthStatus{Report: report, Database: "connected", Maintenance: h.maintenance.Status().Enabled}
+	if report.Checks["database"].Status == health.LevelDown {
+		status.Database = "disconnected"
+	}
+
+	switch report.Status {
+	case health.LevelDown:
+		response.JSON(c, http.StatusServiceUnavailable, "System is down", status)
+	case health.LevelDegraded:
+		response.JSON(c, http.StatusOK, "System is degraded", status)
+	default:
+		response.JSON(c, http.StatusOK, "System is healthy", status)
+	}
+}
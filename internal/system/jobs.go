@@ -0,0 +1,138 @@
+package system
+
+import (
+	"backend/pkg/deadletter"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errFailedJobsSystemWide refuses department admins: the queue holds every department's jobs,
+// including the contents of unsent emails
+var errFailedJobsSystemWide = errors.New("only a system administrator can manage failed background jobs")
+
+// canManageFailedJobs refuses admins of a department
+func canManageFailedJobs(c *gin.Context) bool {
+	if c.GetUint("department_id") != 0 {
+		response.Error(c, http.StatusForbidden, errFailedJobsSystemWide.Error(), nil)
+		return false
+	}
+	return true
+}
+
+// GetFailedJobs godoc
+// @Summary List failed background jobs
+// @Description Dead letter queue of AI analyses, AI index updates and notification emails that failed for good, newest first. System administrators only.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Param kind query string false "Filter by kind (ai_analysis, ai_sync, email)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/jobs/failed [get]
+func (h *Handler) GetFailedJobs(c *gin.Context) {
+	if !canManageFailedJobs(c) {
+		return
+	}
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 20
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	jobs, total, err := h.deadLetters.List(c.Query("kind"), page, limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch failed jobs", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"jobs": jobs,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// RetryFailedJob godoc
+// @Summary Retry a failed background job
+// @Description Re-runs the job. On success it leaves the queue; on failure it stays with the new error. System administrators only.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Failed job ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /admin/jobs/failed/{id}/retry [post]
+func (h *Handler) RetryFailedJob(c *gin.Context) {
+	if !canManageFailedJobs(c) {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	job, err := h.deadLetters.Retry(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, deadletter.ErrNotFound):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, deadletter.ErrNoRetrier):
+			response.Error(c, http.StatusUnprocessableEntity, err.Error(), nil)
+		case job != nil:
+			response.Error(c, http.StatusBadGateway, err.Error(), job)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to retry job", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Job retried", job)
+}
+
+// DeleteFailedJob godoc
+// @Summary Discard a failed background job
+// @Description System administrators only.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Failed job ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/jobs/failed/{id} [delete]
+func (h *Handler) DeleteFailedJob(c *gin.Context) {
+	if !canManageFailedJobs(c) {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.deadLetters.Delete(uint(id)); err != nil {
+		if errors.Is(err, deadletter.ErrNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to delete job", err.Error())
+		return
+	}
+	response.JSON(c, http.StatusOK, "Failed job deleted", nil)
+}
@@ -0,0 +1,67 @@
+package system
+
+import (
+	"backend/pkg/maintenance"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateMaintenanceRequest switches maintenance mode
+type UpdateMaintenanceRequest struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message" binding:"max=500"`
+	EndsAt  *time.Time `json:"ends_at"` // expected end, optional
+}
+
+// GetMaintenance godoc
+// @Summary Get maintenance mode
+// @Description Whether writes are currently refused, why, and until when. Source is "config" when MAINTENANCE_MODE is set, which cannot be switched off here.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=maintenance.Status}
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/maintenance [get]
+func (h *Handler) GetMaintenance(c *gin.Context) {
+	response.Success(c, h.maintenance.Status())
+}
+
+// UpdateMaintenance godoc
+// @Summary Switch maintenance mode
+// @Description While enabled, POST, PUT, PATCH and DELETE requests get 503 with the message, except from system admins and to log in; reads are unaffected. The flag is shared by every instance and picked up within seconds.
+// @Tags Admin - System
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateMaintenanceRequest true "Maintenance mode"
+// @Success 200 {object} response.Response{data=maintenance.Status}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/maintenance [put]
+func (h *Handler) UpdateMaintenance(c *gin.Context) {
+	var req UpdateMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	status, err := h.maintenance.Set(req.Enabled, req.Message, req.EndsAt, c.GetUint("user_id"))
+	if err != nil {
+		if errors.Is(err, maintenance.ErrEndsInPast) {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to update maintenance mode", err.Error())
+		return
+	}
+
+	message := "Maintenance mode disabled"
+	if status.Enabled {
+		message = "Maintenance mode enabled"
+	}
+	response.JSON(c, http.StatusOK, message, status)
+}
@@ -0,0 +1,178 @@
+package system
+
+import (
+	"backend/pkg/response"
+	"backend/pkg/settings"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errOtherUniversity = errors.New("you can only change the settings of your own university")
+	errSystemWide      = errors.New("only a system administrator can change system-wide settings")
+)
+
+// UpdateSettingRequest sets a value system-wide (university_id 0 or omitted) or for a university
+type UpdateSettingRequest struct {
+	Value        string `json:"value" binding:"required" example:"5"`
+	UniversityID uint   `json:"university_id" example:"1"`
+}
+
+// GetSettings godoc
+// @Summary List settings
+// @Description Every setting with its type, default, allowed range and the value in effect for the university (the admin's own by default; 0 for the system-wide values). Source says whether the value is the default, set system-wide or overridden for the university.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Param university_id query int false "University to resolve values for; 0 for system-wide"
+// @Success 200 {object} response.Response{data=[]settings.Value}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /admin/settings [get]
+func (h *Handler) GetSettings(c *gin.Context) {
+	universityID, ok := settingsUniversity(c, c.Query("university_id"))
+	if !ok {
+		return
+	}
+	response.Success(c, h.settings.List(universityID))
+}
+
+// GetSetting godoc
+// @Summary Get a setting
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Setting key, e.g. auth.lockout_threshold"
+// @Param university_id query int false "University to resolve the value for; 0 for system-wide"
+// @Success 200 {object} response.Response{data=settings.Value}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/settings/{key} [get]
+func (h *Handler) GetSetting(c *gin.Context) {
+	def, found := settings.Lookup(c.Param("key"))
+	if !found {
+		response.Error(c, http.StatusNotFound, settings.ErrUnknownKey.Error(), nil)
+		return
+	}
+	universityID, ok := settingsUniversity(c, c.Query("university_id"))
+	if !ok {
+		return
+	}
+	response.Success(c, h.settings.Get(def, universityID))
+}
+
+// UpdateSetting godoc
+// @Summary Change a setting
+// @Description Sets the value system-wide, or overrides it for the admin's university when the setting allows it. Department admins can only override values for their university. Values are checked against the setting's type and range. Other instances pick the change up within 30 seconds; settings marked restart_required apply after a restart.
+// @Tags Admin - System
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Setting key"
+// @Param request body UpdateSettingRequest true "New value"
+// @Success 200 {object} response.Response{data=settings.Value}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/settings/{key} [put]
+func (h *Handler) UpdateSetting(c *gin.Context) {
+	var req UpdateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.UniversityID != 0 && req.UniversityID != c.GetUint("university_id") {
+		response.Error(c, http.StatusForbidden, errOtherUniversity.Error(), nil)
+		return
+	}
+	if req.UniversityID == 0 && !canWriteSystemWide(c) {
+		return
+	}
+
+	value, err := h.settings.Set(c.Param("key"), req.UniversityID, req.Value, c.GetUint("user_id"))
+	if err != nil {
+		respondSettingError(c, "Failed to update setting", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Setting updated", value)
+}
+
+// ResetSetting godoc
+// @Summary Reset a setting
+// @Description Removes the system-wide value or the university's override, so the default or the system-wide value applies again. Department admins can only remove their university's overrides.
+// @Tags Admin - System
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Setting key"
+// @Param university_id query int false "University whose override to remove; 0 or omitted for the system-wide value"
+// @Success 200 {object} response.Response{data=settings.Value}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/settings/{key} [delete]
+func (h *Handler) ResetSetting(c *gin.Context) {
+	universityID := uint(0)
+	if raw := c.Query("university_id"); raw != "" {
+		var ok bool
+		if universityID, ok = settingsUniversity(c, raw); !ok {
+			return
+		}
+	}
+	if universityID == 0 && !canWriteSystemWide(c) {
+		return
+	}
+
+	value, err := h.settings.Reset(c.Param("key"), universityID)
+	if err != nil {
+		respondSettingError(c, "Failed to reset setting", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Setting reset", value)
+}
+
+// settingsUniversity parses the university a request is about, the admin's own when not given;
+// 0 means system-wide
+func settingsUniversity(c *gin.Context, raw string) (uint, bool) {
+	own := c.GetUint("university_id")
+	if raw == "" {
+		return own, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid university ID", err.Error())
+		return 0, false
+	}
+	if id != 0 && uint(id) != own {
+		response.Error(c, http.StatusForbidden, errOtherUniversity.Error(), nil)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// canWriteSystemWide refuses system-wide changes from department admins; their settings only
+// apply to their own university
+func canWriteSystemWide(c *gin.Context) bool {
+	if c.GetUint("department_id") != 0 {
+		response.Error(c, http.StatusForbidden, errSystemWide.Error(), nil)
+		return false
+	}
+	return true
+}
+
+func respondSettingError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, settings.ErrUnknownKey), errors.Is(err, settings.ErrNotSet):
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, settings.ErrNotPerUniversity):
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		var invalid *settings.InvalidValueError
+		if errors.As(err, &invalid) {
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
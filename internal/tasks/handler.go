@@ -0,0 +1,191 @@
+package tasks
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/response"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{service: s}
+}
+
+// GetTeamTasks godoc
+// @Summary List a team's tasks
+// @Description Lists the team's checklist, open tasks first by due date, with the completed percentage. Visible to members, the advisor and the department admin.
+// @Tags Tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=TeamTasks}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/tasks [get]
+func (h *Handler) GetTeamTasks(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+
+	tasks, err := h.service.GetTeamTasks(teamID, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondError(c, "Failed to fetch tasks", err)
+		return
+	}
+	response.Success(c, tasks)
+}
+
+// CreateTask godoc
+// @Summary Add a task to a team's checklist
+// @Description Team members add tasks, optionally assigned to a member and with a due date. The assignee is notified.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body CreateTaskRequest true "Task"
+// @Success 201 {object} response.Response{data=domain.TeamTask}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /teams/{id}/tasks [post]
+func (h *Handler) CreateTask(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+
+	var req CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	task, err := h.service.CreateTask(teamID, req, claims.UserID)
+	if err != nil {
+		respondError(c, "Failed to create task", err)
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Task created", task)
+}
+
+// UpdateTask godoc
+// @Summary Update, assign or complete a task
+// @Description Changes only the fields sent. completed=true completes the task and completed=false reopens it.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param taskId path int true "Task ID"
+// @Param request body UpdateTaskRequest true "Changes"
+// @Success 200 {object} response.Response{data=domain.TeamTask}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/tasks/{taskId} [patch]
+func (h *Handler) UpdateTask(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+	taskID := parseID(c, "taskId")
+	if taskID == 0 {
+		return
+	}
+
+	var req UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	task, err := h.service.UpdateTask(teamID, taskID, req, claims.UserID)
+	if err != nil {
+		respondError(c, "Failed to update task", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Task updated", task)
+}
+
+// DeleteTask godoc
+// @Summary Delete a task
+// @Tags Tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param taskId path int true "Task ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/tasks/{taskId} [delete]
+func (h *Handler) DeleteTask(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	teamID := parseID(c, "id")
+	if teamID == 0 {
+		return
+	}
+	taskID := parseID(c, "taskId")
+	if taskID == 0 {
+		return
+	}
+
+	if err := h.service.DeleteTask(teamID, taskID, claims.UserID); err != nil {
+		respondError(c, "Failed to delete task", err)
+		return
+	}
+	response.JSON(c, http.StatusOK, "Task deleted", nil)
+}
+
+func respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, ErrTeamNotFound), errors.Is(err, ErrTaskNotFound):
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrNotMember):
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrTeamArchived):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	default:
+		response.Error(c, http.StatusBadRequest, message, err.Error())
+	}
+}
+
+func getClaims(c *gin.Context) *auth.TokenClaims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return nil
+	}
+	return claims.(*auth.TokenClaims)
+}
+
+func parseID(c *gin.Context, param string) uint {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"backend/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(task *domain.TeamTask) error
+	Update(task *domain.TeamTask) error
+	Delete(id uint) error
+	GetByID(id uint) (*domain.TeamTask, error)
+	GetByTeam(teamID uint) ([]domain.TeamTask, error)
+	GetProgress(teamID uint) (*domain.TaskProgress, error)
+
+	GetTeam(teamID uint) (*domain.Team, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(task *domain.TeamTask) error {
+	return r.db.Create(task).Error
+}
+
+func (r *repository) Update(task *domain.TeamTask) error {
+	return r.db.Omit("Assignee").Save(task).Error
+}
+
+func (r *repository) Delete(id uint) error {
+	return r.db.Delete(&domain.TeamTask{}, id).Error
+}
+
+func (r *repository) GetByID(id uint) (*domain.TeamTask, error) {
+	var task domain.TeamTask
+	if err := r.db.Preload("Assignee").First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetByTeam lists open tasks first, by due date with undated ones last, then completed ones
+func (r *repository) GetByTeam(teamID uint) ([]domain.TeamTask, error) {
+	var tasks []domain.TeamTask
+	err := r.db.Preload("Assignee").
+		Where("team_id = ?", teamID).
+		Order("completed_at IS NOT NULL, due_date ASC NULLS LAST, id ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *repository) GetProgress(teamID uint) (*domain.TaskProgress, error) {
+	var row struct {
+		Total     int
+		Completed int
+		Overdue   int
+	}
+	err := r.db.Model(&domain.TeamTask{}).
+		Select("COUNT(*) AS total, "+
+			"COUNT(completed_at) AS completed, "+
+			"COUNT(*) FILTER (WHERE completed_at IS NULL AND due_date < ?) AS overdue", time.Now()).
+		Where("team_id = ?", teamID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &domain.TaskProgress{Total: row.Total, Completed: row.Completed, Overdue: row.Overdue}
+	if row.Total > 0 {
+		progress.Percent = row.Completed * 100 / row.Total
+	}
+	return progress, nil
+}
+
+func (r *repository) GetTeam(teamID uint) (*domain.Team, error) {
+	var team domain.Team
+	if err := r.db.Preload("Members").First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
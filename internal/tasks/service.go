@@ -0,0 +1,228 @@
+package tasks
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTeamNotFound = errors.New("team not found")
+	ErrTaskNotFound = errors.New("task not found")
+	ErrForbidden    = errors.New("you do not have permission to view this team's tasks")
+	ErrNotMember    = errors.New("only team members can change the team's tasks")
+	ErrTeamArchived = errors.New("the team is archived")
+)
+
+type Service struct {
+	repo Repository
+	bus  *events.Bus
+}
+
+func NewService(repo Repository, bus *events.Bus) *Service {
+	return &Service{repo: repo, bus: bus}
+}
+
+type CreateTaskRequest struct {
+	Title       string     `json:"title" binding:"required,max=200" example:"Draft the literature review"`
+	Description string     `json:"description" example:"Cover at least ten papers on soil moisture sensing"`
+	AssigneeID  *uint      `json:"assignee_id" example:"12"`
+	DueDate     *time.Time `json:"due_date" example:"2026-03-15T00:00:00Z"`
+}
+
+// UpdateTaskRequest changes only the fields that are sent. Set completed to complete or reopen
+// the task, and clear_assignee or clear_due_date to remove them.
+type UpdateTaskRequest struct {
+	Title        *string    `json:"title" binding:"omitempty,max=200"`
+	Description  *string    `json:"description"`
+	AssigneeID   *uint      `json:"assignee_id"`
+	ClearAssign  bool       `json:"clear_assignee"`
+	DueDate      *time.Time `json:"due_date"`
+	ClearDueDate bool       `json:"clear_due_date"`
+	Completed    *bool      `json:"completed"`
+}
+
+// TeamTasks is a team's checklist with its progress
+type TeamTasks struct {
+	Tasks    []domain.TeamTask   `json:"tasks"`
+	Progress domain.TaskProgress `json:"progress"`
+}
+
+// GetTeamTasks lists a team's tasks for its members, its advisor and the department admin
+func (s *Service) GetTeamTasks(teamID uint, userID uint, role enums.Role, departmentID uint) (*TeamTasks, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, ErrTeamNotFound
+	}
+	if !canView(team, userID, role, departmentID) {
+		return nil, ErrForbidden
+	}
+
+	tasks, err := s.repo.GetByTeam(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.repo.GetProgress(team.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamTasks{Tasks: tasks, Progress: *progress}, nil
+}
+
+// CreateTask adds a task to the checklist; the assignee, if any, must be a member
+func (s *Service) CreateTask(teamID uint, req CreateTaskRequest, userID uint) (*domain.TeamTask, error) {
+	team, err := s.editableTeam(teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+	if req.AssigneeID != nil && !isMember(team, *req.AssigneeID) {
+		return nil, errors.New("tasks can only be assigned to team members")
+	}
+
+	task := &domain.TeamTask{
+		TeamID:      team.ID,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		AssigneeID:  req.AssigneeID,
+		DueDate:     req.DueDate,
+		CreatedBy:   userID,
+	}
+	if err := s.repo.Create(task); err != nil {
+		return nil, err
+	}
+	s.notifyAssignee(team, task, userID)
+	return s.repo.GetByID(task.ID)
+}
+
+// UpdateTask edits, reassigns, completes or reopens a task
+func (s *Service) UpdateTask(teamID uint, taskID uint, req UpdateTaskRequest, userID uint) (*domain.TeamTask, error) {
+	team, err := s.editableTeam(teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.repo.GetByID(taskID)
+	if err != nil || task.TeamID != team.ID {
+		return nil, ErrTaskNotFound
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, errors.New("title must not be empty")
+		}
+		task.Title = title
+	}
+	if req.Description != nil {
+		task.Description = strings.TrimSpace(*req.Description)
+	}
+
+	reassigned := false
+	switch {
+	case req.ClearAssign:
+		task.AssigneeID = nil
+	case req.AssigneeID != nil:
+		if !isMember(team, *req.AssigneeID) {
+			return nil, errors.New("tasks can only be assigned to team members")
+		}
+		reassigned = task.AssigneeID == nil || *task.AssigneeID != *req.AssigneeID
+		task.AssigneeID = req.AssigneeID
+	}
+
+	switch {
+	case req.ClearDueDate:
+		task.DueDate = nil
+	case req.DueDate != nil:
+		task.DueDate = req.DueDate
+	}
+
+	if req.Completed != nil {
+		if *req.Completed && task.CompletedAt == nil {
+			now := time.Now()
+			task.CompletedAt = &now
+			task.CompletedBy = &userID
+		} else if !*req.Completed {
+			task.CompletedAt = nil
+			task.CompletedBy = nil
+		}
+	}
+
+	if err := s.repo.Update(task); err != nil {
+		return nil, err
+	}
+	if reassigned {
+		s.notifyAssignee(team, task, userID)
+	}
+	return s.repo.GetByID(task.ID)
+}
+
+// DeleteTask removes a task from the checklist
+func (s *Service) DeleteTask(teamID uint, taskID uint, userID uint) error {
+	team, err := s.editableTeam(teamID, userID)
+	if err != nil {
+		return err
+	}
+	task, err := s.repo.GetByID(taskID)
+	if err != nil || task.TeamID != team.ID {
+		return ErrTaskNotFound
+	}
+	return s.repo.Delete(task.ID)
+}
+
+// editableTeam loads a team whose tasks the user, as a member, may change
+func (s *Service) editableTeam(teamID uint, userID uint) (*domain.Team, error) {
+	team, err := s.repo.GetTeam(teamID)
+	if err != nil {
+		return nil, ErrTeamNotFound
+	}
+	if !isMember(team, userID) {
+		return nil, ErrNotMember
+	}
+	if team.IsArchived {
+		return nil, ErrTeamArchived
+	}
+	return team, nil
+}
+
+func (s *Service) notifyAssignee(team *domain.Team, task *domain.TeamTask, actorID uint) {
+	if task.AssigneeID == nil || *task.AssigneeID == actorID {
+		return
+	}
+	s.bus.Publish(events.Event{
+		Name:       events.TaskAssigned,
+		EntityType: "team",
+		EntityID:   team.ID,
+		ActorID:    actorID,
+		UserIDs:    []uint{*task.AssigneeID},
+		Data: map[string]interface{}{
+			"task_id":   task.ID,
+			"title":     task.Title,
+			"team_name": team.Name,
+		},
+	})
+}
+
+func canView(team *domain.Team, userID uint, role enums.Role, departmentID uint) bool {
+	if role == enums.RoleAdmin && team.DepartmentID == departmentID {
+		return true
+	}
+	if team.AdvisorID != nil && *team.AdvisorID == userID {
+		return true
+	}
+	return isMember(team, userID)
+}
+
+func isMember(team *domain.Team, userID uint) bool {
+	for _, m := range team.Members {
+		if m.UserID == userID && m.InvitationStatus == enums.InvitationStatusAccepted {
+			return true
+		}
+	}
+	return false
+}
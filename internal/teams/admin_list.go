@@ -0,0 +1,80 @@
+package teams
+
+import (
+	"backend/internal/users"
+	"time"
+)
+
+// AdminTeamQuery holds the filters and pagination accepted by GET /admin/teams; nil filters are not applied
+type AdminTeamQuery struct {
+	Finalized    *bool
+	HasAdvisor   *bool
+	HasProposal  *bool
+	AcademicYear string
+	Archived     bool // list archived teams instead of active ones
+	Search       string
+	Page         int
+	Limit        int
+}
+
+// AdminTeamItem is a team as listed for the department admin
+type AdminTeamItem struct {
+	ID            uint               `json:"id"`
+	Name          string             `json:"name"`
+	AcademicYear  string             `json:"academic_year"`
+	IsFinalized   bool               `json:"is_finalized"`
+	IsArchived    bool               `json:"is_archived"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Leader        *users.UserSummary `json:"leader,omitempty"`
+	Advisor       *users.UserSummary `json:"advisor,omitempty"`
+	MemberCount   int64              `json:"member_count"`
+	ProposalCount int64              `json:"proposal_count"`
+}
+
+// teamCounts are the per-team aggregates loaded alongside a page of teams
+type teamCounts struct {
+	TeamID        uint
+	MemberCount   int64
+	ProposalCount int64
+}
+
+// GetDepartmentTeams lists a page of the department's teams with member and proposal counts
+func (s *Service) GetDepartmentTeams(departmentID uint, q AdminTeamQuery) ([]AdminTeamItem, int64, error) {
+	teams, total, err := s.repo.ListDepartmentTeams(departmentID, q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, 0, len(teams))
+	for _, team := range teams {
+		ids = append(ids, team.ID)
+	}
+	counts, err := s.repo.GetTeamCounts(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]AdminTeamItem, 0, len(teams))
+	for i := range teams {
+		team := &teams[i]
+		item := AdminTeamItem{
+			ID:            team.ID,
+			Name:          team.Name,
+			AcademicYear:  team.AcademicYear,
+			IsFinalized:   team.IsFinalized,
+			IsArchived:    team.IsArchived,
+			CreatedAt:     team.CreatedAt,
+			Leader:        users.NewUserSummary(team.Creator),
+			Advisor:       users.NewUserSummary(team.Advisor),
+			MemberCount:   counts[team.ID].MemberCount,
+			ProposalCount: counts[team.ID].ProposalCount,
+		}
+		for j := range team.Members {
+			if team.Members[j].Role == "leader" {
+				item.Leader = users.NewUserSummary(&team.Members[j].User)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
@@ -0,0 +1,61 @@
+package teams
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotAdvisor         = errors.New("only advisors have advisor settings")
+	ErrConfirmNoConflicts = errors.New("confirm that you have no conflict of interest with teams that pick you before turning on auto-accept")
+)
+
+// AdvisorSettings are an advisor's preferences for team assignments
+type AdvisorSettings struct {
+	AutoAccept    bool       `json:"auto_accept"`                         // accept team assignments without responding to each
+	NoConflictsAt *time.Time `json:"no_conflicts_confirmed_at,omitempty"` // when the advisor confirmed having no conflicts
+}
+
+// UpdateAdvisorSettingsRequest changes the advisor's settings. Accepting a team requires a conflict of
+// interest declaration, so turning auto-accept on requires confirming there are none; the confirmation
+// is stored once with the setting and stands in for a declaration on teams accepted automatically.
+// Teams the advisor has declared a conflict with still cannot pick them.
+type UpdateAdvisorSettingsRequest struct {
+	AutoAccept         bool `json:"auto_accept"`
+	ConfirmNoConflicts bool `json:"confirm_no_conflicts"`
+}
+
+// GetAdvisorSettings returns the advisor's settings
+func (s *Service) GetAdvisorSettings(advisorID uint) (*AdvisorSettings, error) {
+	advisor, err := s.repo.GetAdvisor(advisorID)
+	if err != nil {
+		return nil, ErrNotAdvisor
+	}
+	return &AdvisorSettings{AutoAccept: advisor.AdvisorAutoAccept, NoConflictsAt: advisor.AdvisorNoConflictAt}, nil
+}
+
+// UpdateAdvisorSettings saves the advisor's settings
+func (s *Service) UpdateAdvisorSettings(advisorID uint, req UpdateAdvisorSettingsRequest) (*AdvisorSettings, error) {
+	if _, err := s.repo.GetAdvisor(advisorID); err != nil {
+		return nil, ErrNotAdvisor
+	}
+	if req.AutoAccept && !req.ConfirmNoConflicts {
+		return nil, ErrConfirmNoConflicts
+	}
+	var confirmedAt *time.Time
+	if req.AutoAccept {
+		now := time.Now()
+		confirmedAt = &now
+	}
+	if err := s.repo.SetAdvisorAutoAccept(advisorID, req.AutoAccept, confirmedAt); err != nil {
+		return nil, err
+	}
+	return &AdvisorSettings{AutoAccept: req.AutoAccept, NoConflictsAt: confirmedAt}, nil
+}
+
+// autoAccepts reports whether the user is an advisor who accepts assignments automatically, having
+// confirmed they have no conflicts
+func (s *Service) autoAccepts(advisorID uint) bool {
+	advisor, err := s.repo.GetAdvisor(advisorID)
+	return err == nil && advisor.AdvisorAutoAccept && advisor.AdvisorNoConflictAt != nil
+}
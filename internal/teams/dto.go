@@ -0,0 +1,113 @@
+package teams
+
+import (
+	"backend/internal/domain"
+	"backend/internal/users"
+	"backend/pkg/enums"
+	"time"
+)
+
+// TeamResponse is the API view of a team with the relations the repository preloads
+type TeamResponse struct {
+	ID           uint                 `json:"id"`
+	Name         string               `json:"name"`
+	DepartmentID uint                 `json:"department_id"`
+	CreatedBy    uint                 `json:"created_by"`
+	AdvisorID    *uint                `json:"advisor_id"`
+	IsFinalized  bool                 `json:"is_finalized"`
+	AcademicYear string               `json:"academic_year"`
+	IsArchived   bool                 `json:"is_archived"`
+	ArchivedAt   *time.Time           `json:"archived_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	Department   *DepartmentSummary   `json:"department,omitempty"`
+	Creator      *users.UserSummary   `json:"creator,omitempty"`
+	Advisor      *users.UserSummary   `json:"advisor,omitempty"`
+	Members      []TeamMemberResponse `json:"members"`
+	Proposals    []TeamProposal       `json:"proposals"`
+}
+
+// DepartmentSummary names the team's department
+type DepartmentSummary struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// TeamMemberResponse is a membership with the member's public user details
+type TeamMemberResponse struct {
+	TeamID           uint                   `json:"team_id"`
+	UserID           uint                   `json:"user_id"`
+	Role             string                 `json:"role"` // leader or member
+	InvitationStatus enums.InvitationStatus `json:"invitation_status"`
+	FunctionalRole   enums.FunctionalRole   `json:"functional_role,omitempty"`
+	Skills           []string               `json:"skills"`
+	User             *users.UserSummary     `json:"user,omitempty"`
+}
+
+// TeamProposal is one of the team's proposals as listed with the team
+type TeamProposal struct {
+	ID           uint                 `json:"id"`
+	Status       enums.ProposalStatus `json:"status"`
+	AdvisorID    *uint                `json:"advisor_id"`
+	AcademicYear string               `json:"academic_year"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+func toTeamResponse(team *domain.Team) TeamResponse {
+	resp := TeamResponse{
+		ID:           team.ID,
+		Name:         team.Name,
+		DepartmentID: team.DepartmentID,
+		CreatedBy:    team.CreatedBy,
+		AdvisorID:    team.AdvisorID,
+		IsFinalized:  team.IsFinalized,
+		AcademicYear: team.AcademicYear,
+		IsArchived:   team.IsArchived,
+		ArchivedAt:   team.ArchivedAt,
+		CreatedAt:    team.CreatedAt,
+		Creator:      users.NewUserSummary(team.Creator),
+		Advisor:      users.NewUserSummary(team.Advisor),
+		Members:      make([]TeamMemberResponse, 0, len(team.Members)),
+		Proposals:    make([]TeamProposal, 0, len(team.Proposals)),
+	}
+	if team.Department != nil {
+		resp.Department = &DepartmentSummary{ID: team.Department.ID, Name: team.Department.Name, Code: team.Department.Code}
+	}
+	for i := range team.Members {
+		resp.Members = append(resp.Members, toTeamMemberResponse(&team.Members[i]))
+	}
+	for _, p := range team.Proposals {
+		resp.Proposals = append(resp.Proposals, TeamProposal{
+			ID:           p.ID,
+			Status:       p.Status,
+			AdvisorID:    p.AdvisorID,
+			AcademicYear: p.AcademicYear,
+			CreatedAt:    p.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func toTeamResponses(teams []domain.Team) []TeamResponse {
+	result := make([]TeamResponse, 0, len(teams))
+	for i := range teams {
+		result = append(result, toTeamResponse(&teams[i]))
+	}
+	return result
+}
+
+func toTeamMemberResponse(member *domain.TeamMember) TeamMemberResponse {
+	skills := member.Skills
+	if skills == nil {
+		skills = []string{}
+	}
+	return TeamMemberResponse{
+		TeamID:           member.TeamID,
+		UserID:           member.UserID,
+		Role:             member.Role,
+		InvitationStatus: member.InvitationStatus,
+		FunctionalRole:   member.FunctionalRole,
+		Skills:           skills,
+		User:             users.NewUserSummary(&member.User),
+	}
+}
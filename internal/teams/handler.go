@@ -2,26 +2,41 @@ package teams
 
 import (
 	"backend/internal/auth"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
 	"backend/pkg/response"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
 	service *Service
+	blind   BlindReview
 }
 
-func NewHandler(s *Service) *Handler {
-	return &Handler{service: s}
+// BlindReview decides whether a team is hidden from an advisor reviewing its proposal blind;
+// implemented by the proposal service
+type BlindReview interface {
+	TeamHiddenFrom(teamID uint, userID uint, role enums.Role) bool
 }
+
+func NewHandler(s *Service, blind BlindReview) *Handler {
+	return &Handler{service: s, blind: blind}
+}
+
+var errTeamHidden = errors.New("the team is hidden under blind review until its proposal is decided")
+
 type CreateTeamRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
 type InviteMemberRequest struct {
-	UserID uint `json:"user_id" binding:"required"`
+	UserID  uint   `json:"user_id" binding:"required"`
+	Message string `json:"message" binding:"max=500"`
 }
 
 type TransferLeadershipRequest struct {
@@ -41,6 +56,11 @@ type AssignAdvisorRequest struct {
 	AdvisorID uint `json:"advisor_id" binding:"required"`
 }
 
+type UpdateMemberProfileRequest struct {
+	FunctionalRole string   `json:"functional_role"` // frontend, backend, ml, documentation; empty clears it
+	Skills         []string `json:"skills"`
+}
+
 // CreateTeam godoc
 // @Summary Create a new team
 // @Description Student creates a new team and becomes the leader
@@ -49,9 +69,10 @@ type AssignAdvisorRequest struct {
 // @Produce json
 // @Security BearerAuth
 // @Param team body CreateTeamRequest true "Team details"
-// @Success 201 {object} response.Response{data=domain.Team}
+// @Success 201 {object} response.Response{data=TeamResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /teams [post]
 func (h *Handler) CreateTeam(c *gin.Context) {
@@ -67,11 +88,15 @@ func (h *Handler) CreateTeam(c *gin.Context) {
 	// Pass DepartmentID from Claims!
 	team, err := h.service.CreateTeam(req.Name, claims.UserID, claims.DepartmentID)
 	if err != nil {
+		if err.Error() == "you already belong to an active team in this cohort" {
+			response.Error(c, http.StatusConflict, "Failed to create team", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to create team", err.Error())
 		return
 	}
 
-	response.JSON(c, http.StatusCreated, "Team created successfully", team)
+	response.JSON(c, http.StatusCreated, "Team created successfully", toTeamResponse(team))
 }
 
 // FinalizeTeam godoc
@@ -108,7 +133,7 @@ func (h *Handler) FinalizeTeam(c *gin.Context) {
 // @Tags Teams
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} response.Response{data=[]domain.Team}
+// @Success 200 {object} response.Response{data=[]TeamResponse}
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /teams [get]
@@ -125,19 +150,20 @@ func (h *Handler) GetTeams(c *gin.Context) {
         return
     }
 
-    response.Success(c, teams)
+    response.Success(c, toTeamResponses(teams))
 }
 
 // GetTeam godoc
 // @Summary Get team by ID
-// @Description Retrieve team details with members
+// @Description Retrieve team details with members. Under blind review an advisor gets 403 while one of the team's proposals awaits a decision.
 // @Tags Teams
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Team ID"
-// @Success 200 {object} response.Response{data=domain.Team}
+// @Success 200 {object} response.Response{data=TeamResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /teams/{id} [get]
 func (h *Handler) GetTeam(c *gin.Context) {
@@ -146,6 +172,9 @@ func (h *Handler) GetTeam(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "Invalid team ID", err.Error())
 		return
 	}
+	if h.teamHidden(c, uint(id)) {
+		return
+	}
 
 	team, err := h.service.GetTeam(uint(id))
 	if err != nil {
@@ -153,19 +182,20 @@ func (h *Handler) GetTeam(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, team)
+	response.Success(c, toTeamResponse(team))
 }
 
 // GetTeamMembers godoc
 // @Summary Get team members
-// @Description Retrieve all members of a team
+// @Description Retrieve all members of a team. Under blind review an advisor gets 403 while one of the team's proposals awaits a decision.
 // @Tags Teams
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Team ID"
-// @Success 200 {object} response.Response{data=[]domain.User}
+// @Success 200 {object} response.Response{data=[]MemberProfile}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /teams/{id}/members [get]
 func (h *Handler) GetTeamMembers(c *gin.Context) {
@@ -174,6 +204,9 @@ func (h *Handler) GetTeamMembers(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "Invalid team ID", err.Error())
 		return
 	}
+	if h.teamHidden(c, uint(id)) {
+		return
+	}
 
 	members, err := h.service.GetTeamMembers(uint(id))
 	if err != nil {
@@ -197,6 +230,7 @@ func (h *Handler) GetTeamMembers(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /teams/{id}/invite [post]
 func (h *Handler) InviteMember(c *gin.Context) {
@@ -220,12 +254,17 @@ func (h *Handler) InviteMember(c *gin.Context) {
 		return
 	}
 
-	err = h.service.InviteMember(uint(id), req.UserID, userClaims.UserID)
+	err = h.service.InviteMember(uint(id), req.UserID, userClaims.UserID, req.Message)
 	if err != nil {
 		if err.Error() == "only team leader can invite members" {
 			response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
 			return
 		}
+		if err.Error() == "user is already a team member" || err.Error() == "user already has a pending invitation" ||
+			err.Error() == "user already belongs to an active team in this cohort" {
+			response.Error(c, http.StatusConflict, "Failed to invite member", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to invite member", err.Error())
 		return
 	}
@@ -246,6 +285,7 @@ func (h *Handler) InviteMember(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /teams/{id}/invitation/respond [post]
 func (h *Handler) RespondToInvitation(c *gin.Context) {
@@ -271,7 +311,16 @@ func (h *Handler) RespondToInvitation(c *gin.Context) {
 
 	err = h.service.RespondToInvitation(uint(id), userClaims.UserID, req.Accept)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to respond to invitation", err.Error())
+		switch err.Error() {
+		case "invitation not found":
+			response.Error(c, http.StatusNotFound, "Failed to respond to invitation", err.Error())
+		case "invitation has expired":
+			response.Error(c, http.StatusGone, "Failed to respond to invitation", err.Error())
+		case "you already belong to an active team in this cohort":
+			response.Error(c, http.StatusConflict, "Failed to respond to invitation", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to respond to invitation", err.Error())
+		}
 		return
 	}
 
@@ -282,6 +331,77 @@ func (h *Handler) RespondToInvitation(c *gin.Context) {
 	response.JSON(c, http.StatusOK, message, nil)
 }
 
+// ResendInvitation godoc
+// @Summary Resend a team invitation
+// @Description Team leader reopens a pending or expired invitation with a fresh expiry and notifies the invitee again
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param userId path int true "Invitee User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/invitations/{userId}/resend [post]
+func (h *Handler) ResendInvitation(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	teamID := parseID(c)
+	if teamID == 0 {
+		return
+	}
+
+	inviteeID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	err = h.service.ResendInvitation(teamID, uint(inviteeID), claims.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "only team leader can invite members":
+			response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+		case "invitation not found":
+			response.Error(c, http.StatusNotFound, "Failed to resend invitation", err.Error())
+		default:
+			response.Error(c, http.StatusBadRequest, "Failed to resend invitation", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Invitation resent successfully", nil)
+}
+
+// GetMyInvitations godoc
+// @Summary Get my pending invitations
+// @Description List team invitations awaiting the current user's response, with remaining time
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]PendingInvitation}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/invitations [get]
+func (h *Handler) GetMyInvitations(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	invitations, err := h.service.GetMyInvitations(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch invitations", err.Error())
+		return
+	}
+
+	response.Success(c, invitations)
+}
+
 // // RemoveMember godoc
 // // @Summary Remove a member from team
 // // @Description Team leader removes a member from the team
@@ -319,6 +439,90 @@ func (h *Handler) RemoveMember(c *gin.Context) {
 	response.JSON(c, http.StatusOK, "Member removed successfully", nil)
 }
 
+// UpdateMemberProfile godoc
+// @Summary Set a member's functional role and skills
+// @Description Team leader sets the functional role (frontend, backend, ml, documentation) and skill tags of a member
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param memberId path int true "Member User ID"
+// @Param request body UpdateMemberProfileRequest true "Functional role and skills"
+// @Success 200 {object} response.Response{data=TeamMemberResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/members/{memberId} [patch]
+func (h *Handler) UpdateMemberProfile(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	teamID := parseID(c)
+	if teamID == 0 {
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("memberId"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid member ID", err.Error())
+		return
+	}
+
+	var req UpdateMemberProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid inputs", err.Error())
+		return
+	}
+
+	member, err := h.service.UpdateMemberProfile(teamID, uint(memberID), claims.UserID, req.FunctionalRole, req.Skills)
+	if err != nil {
+		switch err.Error() {
+		case "team not found", "user is not a member of this team":
+			response.Error(c, http.StatusNotFound, "Failed to update member", err.Error())
+		case "only the team leader can edit member profiles":
+			response.Error(c, http.StatusForbidden, "Failed to update member", err.Error())
+		default:
+			response.Error(c, http.StatusBadRequest, "Failed to update member", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Member profile updated", toTeamMemberResponse(member))
+}
+
+// GetTeamBalance godoc
+// @Summary Team role and skill balance
+// @Description Functional-role counts, missing roles and combined skills of a team, to assess balance before advisor assignment
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=TeamBalance}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/teams/{id}/balance [get]
+func (h *Handler) GetTeamBalance(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	teamID := parseID(c)
+	if teamID == 0 {
+		return
+	}
+
+	balance, err := h.service.GetTeamBalance(teamID, claims.DepartmentID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "Team not found", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Team balance retrieved", balance)
+}
+
 // TransferLeadership godoc
 // @Summary Transfer team leadership
 // @Description Assign a new leader. Old leader becomes a member.
@@ -424,7 +628,7 @@ func (h *Handler) AdvisorResponse(c *gin.Context) {
 
 // AssignAdvisor godoc
 // @Summary Assign advisor to team
-// @Description Team leader assigns an advisor to the team
+// @Description Team leader assigns an advisor to the team. If the advisor has auto-accept on, the team is accepted and finalized at once; otherwise it waits for the advisor's response.
 // @Tags Teams
 // @Accept json
 // @Produce json
@@ -452,7 +656,7 @@ func (h *Handler) AssignAdvisor(c *gin.Context) {
 		return
 	}
 
-	err := h.service.AssignAdvisor(teamID, claims.UserID, req.AdvisorID)
+	accepted, err := h.service.AssignAdvisor(teamID, claims.UserID, req.AdvisorID)
 	if err != nil {
 		if err.Error() == "only team leader can assign advisor" {
 			response.Error(c, http.StatusForbidden, err.Error(), nil)
@@ -462,7 +666,298 @@ func (h *Handler) AssignAdvisor(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusOK, "Advisor assigned successfully", nil)
+	if accepted {
+		response.JSON(c, http.StatusOK, "Advisor assigned and accepted the team", gin.H{"accepted": true})
+		return
+	}
+	response.JSON(c, http.StatusOK, "Advisor assigned successfully", gin.H{"accepted": false})
+}
+
+// GetDepartmentTeams godoc
+// @Summary List the department's teams
+// @Description Admin lists the teams of their department, newest first, with member and proposal counts. Archived teams are only listed with archived=true.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param finalized query bool false "Only finalized (true) or forming (false) teams"
+// @Param has_advisor query bool false "Only teams with (true) or without (false) an advisor"
+// @Param has_proposal query bool false "Only teams with (true) or without (false) a proposal"
+// @Param academic_year query string false "Cohort, e.g. 2024/2025"
+// @Param archived query bool false "List archived teams"
+// @Param search query string false "Team name contains"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} response.Response{data=[]AdminTeamItem}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/teams [get]
+func (h *Handler) GetDepartmentTeams(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	q := AdminTeamQuery{
+		AcademicYear: c.Query("academic_year"),
+		Archived:     c.Query("archived") == "true",
+		Search:       strings.TrimSpace(c.Query("search")),
+		Page:         1,
+		Limit:        20,
+	}
+	for param, target := range map[string]**bool{
+		"finalized":    &q.Finalized,
+		"has_advisor":  &q.HasAdvisor,
+		"has_proposal": &q.HasProposal,
+	} {
+		if raw := c.Query(param); raw != "" {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, "Invalid "+param+" filter", "use true or false")
+				return
+			}
+			*target = &value
+		}
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		q.Page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		q.Limit = l
+	}
+
+	teams, total, err := h.service.GetDepartmentTeams(claims.DepartmentID, q)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch teams", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"teams": teams,
+		"pagination": gin.H{
+			"page":  q.Page,
+			"limit": q.Limit,
+			"total": total,
+			"pages": (total + int64(q.Limit) - 1) / int64(q.Limit),
+		},
+	})
+}
+
+// TransferDepartment godoc
+// @Summary Move a team to another department
+// @Description For a team created under the wrong department: moves the team with its proposals, projects and deadline extension requests to another department of the same university. Every member must belong to the target department (422 lists the others). An advisor of the old department is refused (409) unless remove_advisor unassigns them; open advisor requests are withdrawn. The team, each proposal and each project get an audit entry with the reason.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body TransferDepartmentRequest true "Target department and reason"
+// @Success 200 {object} response.Response{data=DepartmentTransfer}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse{errors=[]MisplacedMember}
+// @Router /admin/teams/{id}/transfer-department [post]
+func (h *Handler) TransferDepartment(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	var req TransferDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	meta := audit.NewRequestMeta(c)
+	transfer, err := h.service.TransferDepartment(id, req, claims.UserID, claims.DepartmentID, meta)
+	if err != nil {
+		var misplaced *MemberDepartmentError
+		switch {
+		case errors.As(err, &misplaced):
+			response.Error(c, http.StatusUnprocessableEntity, ErrMembersInOtherDepartment.Error(), misplaced.Members)
+		case err.Error() == "team not found", errors.Is(err, ErrDepartmentNotFound):
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, ErrAdvisorInOtherDepartment), errors.Is(err, ErrTeamArchived):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, ErrSameDepartment), errors.Is(err, ErrOtherUniversity):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to transfer the team", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Team transferred", transfer)
+}
+
+// GetLeadership godoc
+// @Summary Team leadership status
+// @Description Whether the team's leader can be replaced, because their account is deactivated or they have been inactive longer than the university's leader inactivity period (setting teams.leader_inactive_days), with the open leadership vote and its tally. For members and the department's admins.
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} response.Response{data=LeadershipStatus}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /teams/{id}/leadership [get]
+func (h *Handler) GetLeadership(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	status, err := h.service.GetLeadership(id, claims.UserID, claims.Role, claims.DepartmentID)
+	if err != nil {
+		respondLeadershipError(c, err, "Failed to fetch leadership")
+		return
+	}
+	response.Success(c, status)
+}
+
+// StartLeadershipVote godoc
+// @Summary Start a vote for a new team leader
+// @Description Opens a vote once the leader is absent; every member is notified, the leader included. Members then vote with POST /teams/{id}/leadership/vote/ballots; the first member backed by a majority becomes the leader. A vote without a majority after 7 days is handed to the department admins, and a vote is cancelled if the leader becomes active again.
+// @Tags Teams
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 201 {object} response.Response{data=LeadershipStatus}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /teams/{id}/leadership/vote [post]
+func (h *Handler) StartLeadershipVote(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	status, err := h.service.StartLeadershipVote(id, claims.UserID)
+	if err != nil {
+		respondLeadershipError(c, err, "Failed to start the leadership vote")
+		return
+	}
+	response.JSON(c, http.StatusCreated, "Leadership vote started", status)
+}
+
+// CastLeadershipBallot godoc
+// @Summary Vote for the new team leader
+// @Description Records or changes the member's vote in the open leadership vote. The candidate must be an active member other than the absent leader. When the candidate reaches a majority of the eligible voters they become the leader and the team and advisor are notified.
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body CastBallotRequest true "Candidate"
+// @Success 200 {object} response.Response{data=LeadershipStatus}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /teams/{id}/leadership/vote/ballots [post]
+func (h *Handler) CastLeadershipBallot(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	var req CastBallotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	status, err := h.service.CastLeadershipBallot(id, claims.UserID, req.CandidateID)
+	if err != nil {
+		respondLeadershipError(c, err, "Failed to record the vote")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Vote recorded", status)
+}
+
+// AppointLeader godoc
+// @Summary Appoint a new team leader
+// @Description For a team whose leader is absent (deactivated, or inactive longer than the leader inactivity period), e.g. after a vote without a majority: makes another active member the leader. An open vote is cancelled; the change is audited with the reason and the team and advisor are notified.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body AppointLeaderRequest true "New leader and reason"
+// @Success 200 {object} response.Response{data=LeadershipStatus}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/teams/{id}/leader [post]
+func (h *Handler) AppointLeader(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+	id := parseID(c)
+	if id == 0 {
+		return
+	}
+
+	var req AppointLeaderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	meta := audit.NewRequestMeta(c)
+	status, err := h.service.AppointLeader(id, req, claims.UserID, claims.DepartmentID, meta)
+	if err != nil {
+		respondLeadershipError(c, err, "Failed to appoint the leader")
+		return
+	}
+	response.JSON(c, http.StatusOK, "Leader appointed", status)
+}
+
+func respondLeadershipError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "team not found":
+		response.Error(c, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrNotTeamMember), errors.Is(err, ErrAbsentLeaderVote):
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrLeaderPresent), errors.Is(err, ErrVoteAlreadyOpen), errors.Is(err, ErrNoOpenVote),
+		errors.Is(err, ErrNoEligibleMembers), errors.Is(err, ErrTeamArchived):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrInvalidCandidate), errors.Is(err, ErrReasonRequired):
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
+
+// teamHidden answers 403 when blind review hides the team from the caller
+func (h *Handler) teamHidden(c *gin.Context, teamID uint) bool {
+	claims := getClaims(c)
+	if claims == nil {
+		return true
+	}
+	if h.blind.TeamHiddenFrom(teamID, claims.UserID, claims.Role) {
+		response.Error(c, http.StatusForbidden, errTeamHidden.Error(), nil)
+		return true
+	}
+	return false
 }
 
 // Helpers
@@ -483,3 +978,64 @@ func parseID(c *gin.Context) uint {
 	}
 	return uint(id)
 }
+
+// GetAdvisorSettings godoc
+// @Summary Get my advisor settings
+// @Tags Advisor
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=AdvisorSettings}
+// @Failure 403 {object} response.ErrorResponse
+// @Router /advisor/settings [get]
+func (h *Handler) GetAdvisorSettings(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	settings, err := h.service.GetAdvisorSettings(claims.UserID)
+	if err != nil {
+		response.Error(c, http.StatusForbidden, err.Error(), nil)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// UpdateAdvisorSettings godoc
+// @Summary Update my advisor settings
+// @Description With auto_accept on, teams that assign you are accepted and finalized at once, without a response from you. Turning it on requires confirm_no_conflicts: the confirmation is stored once with the setting and stands in for a declaration on teams accepted this way. Teams you declared a conflict with still cannot pick you.
+// @Tags Advisor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateAdvisorSettingsRequest true "Advisor settings"
+// @Success 200 {object} response.Response{data=AdvisorSettings}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /advisor/settings [put]
+func (h *Handler) UpdateAdvisorSettings(c *gin.Context) {
+	claims := getClaims(c)
+	if claims == nil {
+		return
+	}
+
+	var req UpdateAdvisorSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	settings, err := h.service.UpdateAdvisorSettings(claims.UserID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotAdvisor):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrConfirmNoConflicts):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to update advisor settings", err.Error())
+		}
+		return
+	}
+	response.JSON(c, http.StatusOK, "Advisor settings updated", settings)
+}
@@ -0,0 +1,515 @@
+package teams
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/settings"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// LeadershipVoteTTL is how long members have to elect a new leader before the department
+	// admin is asked to appoint one
+	LeadershipVoteTTL = 7 * 24 * time.Hour
+	// LeaderActivityCheckInterval is how often leaders are checked for absence and votes for expiry
+	LeaderActivityCheckInterval = 6 * time.Hour
+)
+
+var (
+	ErrLeaderPresent     = errors.New("the team leader is active; only they can hand over leadership")
+	ErrVoteAlreadyOpen   = errors.New("a leadership vote is already open for this team")
+	ErrNoOpenVote        = errors.New("the team has no open leadership vote")
+	ErrNotTeamMember     = errors.New("you are not a member of this team")
+	ErrAbsentLeaderVote  = errors.New("the leader being replaced cannot vote")
+	ErrInvalidCandidate  = errors.New("the new leader must be an active member of the team other than the current leader")
+	ErrNoEligibleMembers = errors.New("the team has no other active member to take over leadership")
+	ErrReasonRequired    = errors.New("a reason is required to appoint a leader")
+)
+
+// LeaderActivity is a team's leader with when they were last active
+type LeaderActivity struct {
+	TeamID       uint
+	TeamName     string
+	DepartmentID uint
+	UniversityID uint
+	LeaderID     uint
+	LeaderName   string
+	LeaderActive bool // the account is active and not deleted
+	LastActiveAt time.Time
+	NotifiedAt   *time.Time // members were told the leader is absent
+}
+
+// absent tells whether the leader can be replaced: their account is deactivated, or they have
+// not been active for the university's leader inactivity period
+func (a *LeaderActivity) absent(inactiveDays int, now time.Time) bool {
+	return !a.LeaderActive || now.Sub(a.LastActiveAt) >= time.Duration(inactiveDays)*24*time.Hour
+}
+
+// LeadershipStatus is whether a team's leader can be replaced, with the open vote if any
+type LeadershipStatus struct {
+	TeamID           uint             `json:"team_id"`
+	LeaderID         uint             `json:"leader_id"`
+	LeaderName       string           `json:"leader_name"`
+	LeaderActive     bool             `json:"leader_active"` // false when the leader's account is deactivated
+	LastActiveAt     time.Time        `json:"last_active_at"`
+	InactiveDays     int              `json:"inactive_days"`
+	InactivityPeriod int              `json:"inactivity_period_days"` // the university's limit
+	Replaceable      bool             `json:"replaceable"`            // members may vote, and admins appoint, a new leader
+	Vote             *LeadershipTally `json:"vote,omitempty"`
+}
+
+// LeadershipTally is an open vote with its ballots counted
+type LeadershipTally struct {
+	*domain.LeadershipVote
+	Votes          map[uint]int `json:"votes"` // ballots per candidate
+	EligibleVoters int          `json:"eligible_voters"`
+	VotesNeeded    int          `json:"votes_needed"` // a majority of the eligible voters
+}
+
+// CastBallotRequest is a member's choice of the new leader
+type CastBallotRequest struct {
+	CandidateID uint `json:"candidate_id" binding:"required" example:"12"`
+}
+
+// AppointLeaderRequest is an admin's choice of the new leader of a team whose leader is absent
+type AppointLeaderRequest struct {
+	NewLeaderID uint   `json:"new_leader_id" binding:"required" example:"12"`
+	Reason      string `json:"reason" binding:"required,max=500" example:"Leader withdrew from the university"`
+}
+
+// GetLeadership returns the leadership status of a team to its members and the department's admins
+func (s *Service) GetLeadership(teamID, userID uint, role enums.Role, departmentID uint) (*LeadershipStatus, error) {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if role == enums.RoleAdmin {
+		if departmentID != 0 && team.DepartmentID != departmentID {
+			return nil, errors.New("team not found")
+		}
+	} else if findMember(team, userID) == nil {
+		return nil, ErrNotTeamMember
+	}
+
+	activity, err := s.repo.GetLeaderActivity(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	vote, err := s.repo.GetOpenLeadershipVote(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return s.leadershipStatus(team, activity, vote, time.Now()), nil
+}
+
+// StartLeadershipVote opens a vote for a new leader. Any member other than the leader can start it
+// once the leader is absent; every member is notified, the leader included.
+func (s *Service) StartLeadershipVote(teamID, userID uint) (*LeadershipStatus, error) {
+	team, activity, err := s.absentLeaderTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if findMember(team, userID) == nil {
+		return nil, ErrNotTeamMember
+	}
+	if userID == activity.LeaderID {
+		return nil, ErrAbsentLeaderVote
+	}
+	if len(eligibleVoters(team, activity.LeaderID)) == 0 {
+		return nil, ErrNoEligibleMembers
+	}
+
+	open, err := s.repo.GetOpenLeadershipVote(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, ErrVoteAlreadyOpen
+	}
+
+	now := time.Now()
+	vote := &domain.LeadershipVote{
+		TeamID:    teamID,
+		LeaderID:  activity.LeaderID,
+		StartedBy: userID,
+		Status:    enums.LeadershipVoteOpen,
+		ExpiresAt: now.Add(LeadershipVoteTTL),
+	}
+	if err := s.repo.CreateLeadershipVote(vote); err != nil {
+		// Another member opened one at the same time
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrVoteAlreadyOpen
+		}
+		return nil, err
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.LeadershipVoteStarted,
+		EntityType: "team",
+		EntityID:   team.ID,
+		ActorID:    userID,
+		UserIDs:    memberIDs(team, 0),
+		Data: map[string]interface{}{
+			"team_name":       team.Name,
+			"leader_id":       activity.LeaderID,
+			"leader_name":     activity.LeaderName,
+			"started_by_name": memberName(team, userID),
+			"expires_at":      vote.ExpiresAt.Format(time.RFC3339),
+		},
+	})
+	return s.leadershipStatus(team, activity, vote, now), nil
+}
+
+// CastLeadershipBallot records a member's vote, which they can change while the vote is open. The
+// first candidate backed by a majority of the eligible voters becomes the leader. A vote whose
+// leader has become active again is cancelled.
+func (s *Service) CastLeadershipBallot(teamID, voterID, candidateID uint) (*LeadershipStatus, error) {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if findMember(team, voterID) == nil {
+		return nil, ErrNotTeamMember
+	}
+	vote, err := s.repo.GetOpenLeadershipVote(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if vote == nil {
+		return nil, ErrNoOpenVote
+	}
+	if voterID == vote.LeaderID {
+		return nil, ErrAbsentLeaderVote
+	}
+
+	activity, err := s.repo.GetLeaderActivity(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	now := time.Now()
+	if activity.LeaderID != vote.LeaderID || !activity.absent(s.inactivityPeriod(activity), now) {
+		s.cancelVote(vote, now)
+		return nil, ErrLeaderPresent
+	}
+	if !isEligibleLeader(team, candidateID, vote.LeaderID) {
+		return nil, ErrInvalidCandidate
+	}
+
+	ballot := &domain.LeadershipBallot{VoteID: vote.ID, VoterID: voterID, CandidateID: candidateID, UpdatedAt: now}
+	if err := s.repo.SaveLeadershipBallot(ballot); err != nil {
+		return nil, err
+	}
+	vote.Ballots = replaceBallot(vote.Ballots, *ballot)
+
+	tally := tallyVote(team, vote)
+	if tally.Votes[candidateID] < tally.VotesNeeded {
+		return s.leadershipStatus(team, activity, vote, now), nil
+	}
+
+	vote.Status = enums.LeadershipVoteElected
+	vote.ElectedID = &candidateID
+	vote.ClosedAt = &now
+	if err := s.repo.ReplaceLeader(teamID, vote.LeaderID, candidateID, vote); err != nil {
+		return nil, err
+	}
+	s.publishLeaderReplaced(team, vote.LeaderID, candidateID, voterID, "vote")
+
+	activity, err = s.repo.GetLeaderActivity(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return s.leadershipStatus(team, activity, nil, now), nil
+}
+
+// AppointLeader lets a department admin make another member the leader of a team whose leader is
+// absent, e.g. after a vote without a majority. An open vote is cancelled and the change is audited.
+func (s *Service) AppointLeader(teamID uint, req AppointLeaderRequest, adminID uint, adminDeptID uint, meta audit.RequestMeta) (*LeadershipStatus, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	team, activity, err := s.absentLeaderTeam(teamID)
+	// Admins without a department manage every department
+	if err == nil && adminDeptID != 0 && team.DepartmentID != adminDeptID {
+		err = errors.New("team not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isEligibleLeader(team, req.NewLeaderID, activity.LeaderID) {
+		return nil, ErrInvalidCandidate
+	}
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	now := time.Now()
+	vote, err := s.repo.GetOpenLeadershipVote(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if vote != nil {
+		vote.Status = enums.LeadershipVoteCancelled
+		vote.ClosedAt = &now
+	}
+	if err := s.repo.ReplaceLeader(teamID, activity.LeaderID, req.NewLeaderID, vote); err != nil {
+		return nil, err
+	}
+
+	err = s.auditLogger.LogAction("team", team.ID, "leader_appointed", &admin.ID, string(admin.Role), admin.Email,
+		map[string]interface{}{"leader_id": activity.LeaderID, "leader_active": activity.LeaderActive, "last_active_at": activity.LastActiveAt},
+		map[string]interface{}{"leader_id": req.NewLeaderID, "reason": reason},
+		meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit the leader appointment of team %d: %v", team.ID, err)
+	}
+	s.publishLeaderReplaced(team, activity.LeaderID, req.NewLeaderID, adminID, "admin")
+
+	activity, err = s.repo.GetLeaderActivity(teamID)
+	if err != nil {
+		return nil, err
+	}
+	return s.leadershipStatus(team, activity, nil, now), nil
+}
+
+// ProcessLeaderActivity tells members once when their leader becomes absent, cancels votes whose
+// leader is back, and hands expired votes to the department admins. Scheduled every
+// LeaderActivityCheckInterval.
+func (s *Service) ProcessLeaderActivity() {
+	now := time.Now()
+	activities, err := s.repo.GetLeaderActivities()
+	if err != nil {
+		log.Printf("failed to load team leader activity: %v", err)
+		return
+	}
+
+	notified := 0
+	for i := range activities {
+		activity := &activities[i]
+		absent := activity.absent(s.inactivityPeriod(activity), now)
+		switch {
+		case absent && activity.NotifiedAt == nil:
+			if s.notifyLeaderAbsent(activity, now) {
+				notified++
+			}
+		case !absent && activity.NotifiedAt != nil:
+			if err := s.repo.SetLeaderAbsentNotified(activity.TeamID, nil); err != nil {
+				log.Printf("failed to reset the absent leader notice of team %d: %v", activity.TeamID, err)
+			}
+			if vote, err := s.repo.GetOpenLeadershipVote(activity.TeamID); err == nil && vote != nil {
+				s.cancelVote(vote, now)
+			}
+		}
+	}
+	if notified > 0 {
+		log.Printf("told the members of %d teams that their leader is absent", notified)
+	}
+
+	s.expireLeadershipVotes(now)
+}
+
+func (s *Service) notifyLeaderAbsent(activity *LeaderActivity, now time.Time) bool {
+	team, err := s.repo.GetByID(activity.TeamID)
+	if err != nil {
+		return false
+	}
+	if err := s.repo.SetLeaderAbsentNotified(team.ID, &now); err != nil {
+		log.Printf("failed to record the absent leader notice of team %d: %v", team.ID, err)
+		return false
+	}
+	s.bus.Publish(events.Event{
+		Name:       events.LeaderAbsent,
+		EntityType: "team",
+		EntityID:   team.ID,
+		UserIDs:    memberIDs(team, activity.LeaderID),
+		Data: map[string]interface{}{
+			"team_name":     team.Name,
+			"leader_name":   activity.LeaderName,
+			"deactivated":   !activity.LeaderActive,
+			"inactive_days": int(now.Sub(activity.LastActiveAt).Hours() / 24),
+		},
+	})
+	return true
+}
+
+// expireLeadershipVotes closes the votes that ended without a majority and asks the department
+// admins to appoint a leader
+func (s *Service) expireLeadershipVotes(now time.Time) {
+	votes, err := s.repo.GetExpiredLeadershipVotes(now)
+	if err != nil {
+		log.Printf("failed to load expired leadership votes: %v", err)
+		return
+	}
+	for i := range votes {
+		vote := &votes[i]
+		vote.Status = enums.LeadershipVoteExpired
+		vote.ClosedAt = &now
+		if err := s.repo.CloseLeadershipVote(vote); err != nil {
+			log.Printf("failed to expire leadership vote %d: %v", vote.ID, err)
+			continue
+		}
+
+		team, err := s.repo.GetByID(vote.TeamID)
+		if err != nil {
+			continue
+		}
+		adminIDs, err := s.repo.GetDepartmentAdminIDs(team.DepartmentID)
+		if err != nil {
+			log.Printf("failed to load the admins of department %d: %v", team.DepartmentID, err)
+		}
+		s.bus.Publish(events.Event{
+			Name:       events.LeadershipVoteExpired,
+			EntityType: "team",
+			EntityID:   team.ID,
+			UserIDs:    append(memberIDs(team, 0), adminIDs...),
+			Data: map[string]interface{}{
+				"team_name":   team.Name,
+				"leader_name": memberName(team, vote.LeaderID),
+				"admin_ids":   adminIDs,
+			},
+		})
+	}
+}
+
+func (s *Service) cancelVote(vote *domain.LeadershipVote, now time.Time) {
+	vote.Status = enums.LeadershipVoteCancelled
+	vote.ClosedAt = &now
+	if err := s.repo.CloseLeadershipVote(vote); err != nil {
+		log.Printf("failed to cancel leadership vote %d: %v", vote.ID, err)
+	}
+}
+
+// absentLeaderTeam loads a team whose leader can be replaced
+func (s *Service) absentLeaderTeam(teamID uint) (*domain.Team, *LeaderActivity, error) {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil {
+		return nil, nil, errors.New("team not found")
+	}
+	if team.IsArchived {
+		return nil, nil, ErrTeamArchived
+	}
+	activity, err := s.repo.GetLeaderActivity(teamID)
+	if err != nil {
+		return nil, nil, errors.New("team not found")
+	}
+	if !activity.absent(s.inactivityPeriod(activity), time.Now()) {
+		return nil, nil, ErrLeaderPresent
+	}
+	return team, activity, nil
+}
+
+func (s *Service) inactivityPeriod(activity *LeaderActivity) int {
+	return s.settings.Int(settings.LeaderInactiveDays, activity.UniversityID)
+}
+
+func (s *Service) leadershipStatus(team *domain.Team, activity *LeaderActivity, vote *domain.LeadershipVote, now time.Time) *LeadershipStatus {
+	period := s.inactivityPeriod(activity)
+	status := &LeadershipStatus{
+		TeamID:           team.ID,
+		LeaderID:         activity.LeaderID,
+		LeaderName:       activity.LeaderName,
+		LeaderActive:     activity.LeaderActive,
+		LastActiveAt:     activity.LastActiveAt,
+		InactiveDays:     int(now.Sub(activity.LastActiveAt).Hours() / 24),
+		InactivityPeriod: period,
+		Replaceable:      !team.IsArchived && activity.absent(period, now),
+	}
+	if vote != nil {
+		status.Vote = tallyVote(team, vote)
+	}
+	return status
+}
+
+func (s *Service) publishLeaderReplaced(team *domain.Team, oldLeaderID, newLeaderID, actorID uint, by string) {
+	recipients := memberIDs(team, 0)
+	data := map[string]interface{}{
+		"team_name":            team.Name,
+		"leader_name":          memberName(team, newLeaderID),
+		"previous_leader_name": memberName(team, oldLeaderID),
+		"by":                   by, // vote or admin
+	}
+	if team.AdvisorID != nil {
+		recipients = append(recipients, *team.AdvisorID)
+		data["advisor_id"] = *team.AdvisorID
+	}
+	s.bus.Publish(events.Event{
+		Name:       events.LeaderReplaced,
+		EntityType: "team",
+		EntityID:   team.ID,
+		ActorID:    actorID,
+		UserIDs:    recipients,
+		Data:       data,
+	})
+}
+
+// tallyVote counts the ballots of the members who can still vote
+func tallyVote(team *domain.Team, vote *domain.LeadershipVote) *LeadershipTally {
+	voters := eligibleVoters(team, vote.LeaderID)
+	tally := &LeadershipTally{
+		LeadershipVote: vote,
+		Votes:          make(map[uint]int),
+		EligibleVoters: len(voters),
+		VotesNeeded:    len(voters)/2 + 1,
+	}
+	for _, ballot := range vote.Ballots {
+		if voters[ballot.VoterID] {
+			tally.Votes[ballot.CandidateID]++
+		}
+	}
+	return tally
+}
+
+// eligibleVoters are the team's members with an active account, except the leader being replaced
+func eligibleVoters(team *domain.Team, leaderID uint) map[uint]bool {
+	voters := make(map[uint]bool)
+	for _, m := range team.Members {
+		if m.UserID != leaderID && m.InvitationStatus == enums.InvitationStatusAccepted && m.User.IsActive {
+			voters[m.UserID] = true
+		}
+	}
+	return voters
+}
+
+func isEligibleLeader(team *domain.Team, candidateID, leaderID uint) bool {
+	return eligibleVoters(team, leaderID)[candidateID]
+}
+
+// findMember finds an accepted member of the team; invitees do not count
+func findMember(team *domain.Team, userID uint) *domain.TeamMember {
+	for i := range team.Members {
+		if team.Members[i].UserID == userID && team.Members[i].InvitationStatus == enums.InvitationStatusAccepted {
+			return &team.Members[i]
+		}
+	}
+	return nil
+}
+
+// memberIDs lists the team's accepted members, leaving out except when it is not 0
+func memberIDs(team *domain.Team, except uint) []uint {
+	var ids []uint
+	for _, m := range team.Members {
+		if m.UserID != except && m.InvitationStatus == enums.InvitationStatusAccepted {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+func replaceBallot(ballots []domain.LeadershipBallot, ballot domain.LeadershipBallot) []domain.LeadershipBallot {
+	for i := range ballots {
+		if ballots[i].VoterID == ballot.VoterID {
+			ballots[i] = ballot
+			return ballots
+		}
+	}
+	return append(ballots, ballot)
+}
@@ -3,27 +3,71 @@ package teams
 import (
 	"backend/internal/domain"
 	"backend/pkg/enums"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	CreateWithLeader(team *domain.Team, leaderID uint) error
 	GetByID(id uint) (*domain.Team, error)
+	// GetByIDs loads the teams with the same associations as GetByID; missing ones are left out
+	GetByIDs(ids []uint) ([]domain.Team, error)
 	GetByUserID(userID uint, availableOnly bool) ([]domain.Team, error)
 	Update(team *domain.Team) error
 	GetDB() *gorm.DB
+	GetCurrentAcademicYear(userID uint) string
+	HasActiveTeamInCohort(userID uint, academicYear string, excludeTeamID uint) (bool, error)
 
 	// Member management
 	AddMember(member *domain.TeamMember) error
 	RemoveMember(teamID, userID uint) error
 	GetMember(teamID, userID uint) (*domain.TeamMember, error)
-	UpdateMemberStatus(teamID, userID uint, status enums.InvitationStatus) error
 	Delete(id uint) error
 	UpdateMemberRole(teamID, userID uint, role string) error
+	UpdateMemberProfile(member *domain.TeamMember) error
+
+	// Invitation management
+	CreateInvitation(invitation *domain.TeamInvitation) error
+	GetLatestInvitation(teamID, inviteeID uint) (*domain.TeamInvitation, error)
+	UpdateInvitation(invitation *domain.TeamInvitation) error
+	AcceptInvitation(invitation *domain.TeamInvitation) error
+	GetPendingInvitations(userID uint, now time.Time) ([]PendingInvitation, error)
+	ExpireInvitations(now time.Time) (int64, error)
 	
 	// Advisor management
 	AssignAdvisor(teamID, advisorID uint) error
 	RemoveAdvisor(teamID uint) error
+	// AcceptAdvisor assigns the advisor and finalizes the team at once
+	AcceptAdvisor(teamID, advisorID uint) error
+	GetAdvisor(advisorID uint) (*domain.User, error)
+	// SetAdvisorAutoAccept stores the setting with the advisor's blanket no-conflict confirmation, nil when it is off
+	SetAdvisorAutoAccept(advisorID uint, enabled bool, noConflictsAt *time.Time) error
+
+	// Admin listing
+	ListDepartmentTeams(departmentID uint, q AdminTeamQuery) ([]domain.Team, int64, error)
+	GetTeamCounts(teamIDs []uint) (map[uint]teamCounts, error)
+
+	// Department transfer
+	GetDepartment(id uint) (*domain.Department, error)
+	GetUser(id uint) (*domain.User, error)
+	TransferDepartment(team *domain.Team, departmentID uint, removeAdvisorIDs []uint) (*DepartmentTransfer, error)
+
+	// Leadership
+	GetLeaderActivity(teamID uint) (*LeaderActivity, error)
+	// GetLeaderActivities returns the leader activity of every team not archived
+	GetLeaderActivities() ([]LeaderActivity, error)
+	SetLeaderAbsentNotified(teamID uint, at *time.Time) error
+	// GetOpenLeadershipVote returns the team's open vote with its ballots, or nil when there is none
+	GetOpenLeadershipVote(teamID uint) (*domain.LeadershipVote, error)
+	CreateLeadershipVote(vote *domain.LeadershipVote) error
+	SaveLeadershipBallot(ballot *domain.LeadershipBallot) error
+	CloseLeadershipVote(vote *domain.LeadershipVote) error
+	// ReplaceLeader hands the team to newLeaderID and, when given, closes the vote in one transaction
+	ReplaceLeader(teamID, oldLeaderID, newLeaderID uint, vote *domain.LeadershipVote) error
+	GetExpiredLeadershipVotes(now time.Time) ([]domain.LeadershipVote, error)
+	GetDepartmentAdminIDs(departmentID uint) ([]uint, error)
 }
 
 type repository struct {
@@ -61,6 +105,28 @@ func (r *repository) CreateWithLeader(team *domain.Team, leaderID uint) error {
 	})
 }
 
+// GetCurrentAcademicYear is the academic year of the user's university, used as the cohort of new teams
+func (r *repository) GetCurrentAcademicYear(userID uint) string {
+	var year string
+	r.db.Table("users").
+		Select("universities.academic_year").
+		Joins("JOIN universities ON universities.id = users.university_id").
+		Where("users.id = ?", userID).
+		Scan(&year)
+	return year
+}
+
+// HasActiveTeamInCohort reports whether the user belongs to an unarchived team of the cohort other than excludeTeamID
+func (r *repository) HasActiveTeamInCohort(userID uint, academicYear string, excludeTeamID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("team_members").
+		Joins("JOIN teams ON teams.id = team_members.team_id").
+		Where("team_members.user_id = ? AND teams.academic_year = ? AND teams.is_archived = ? AND teams.id <> ?",
+			userID, academicYear, false, excludeTeamID).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *repository) GetByID(id uint) (*domain.Team, error) {
 	var team domain.Team
 	// Added Preload("Proposals") to check for existing proposals before deletion
@@ -74,6 +140,16 @@ func (r *repository) GetByID(id uint) (*domain.Team, error) {
 	return &team, nil
 }
 
+func (r *repository) GetByIDs(ids []uint) ([]domain.Team, error) {
+	var teams []domain.Team
+	err := r.db.Preload("Department").
+		Preload("Members.User").
+		Preload("Proposals").
+		Where("id IN ?", ids).
+		Find(&teams).Error
+	return teams, err
+}
+
 func (r *repository) Update(team *domain.Team) error {
 	return r.db.Save(team).Error
 }
@@ -111,7 +187,12 @@ func (r *repository) Delete(id uint) error {
 	// GORM will handle cascading deletes if setup in DB, 
 	// otherwise we delete members first then team.
 	// Assuming DB constraints handles cascade or we do soft delete.
-	return r.db.Delete(&domain.Team{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", id).Delete(&domain.TeamInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Team{}, id).Error
+	})
 }
 
 func (r *repository) RemoveMember(teamID, userID uint) error {
@@ -125,6 +206,14 @@ func (r *repository) UpdateMemberRole(teamID, userID uint, role string) error {
 		Update("role", role).Error
 }
 
+// UpdateMemberProfile saves a member's functional role and skills
+func (r *repository) UpdateMemberProfile(member *domain.TeamMember) error {
+	return r.db.Model(&domain.TeamMember{}).
+		Where("team_id = ? AND user_id = ?", member.TeamID, member.UserID).
+		Select("functional_role", "skills").
+		Updates(member).Error
+}
+
 func (r *repository) GetMember(teamID, userID uint) (*domain.TeamMember, error) {
 	var member domain.TeamMember
 	err := r.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error
@@ -134,12 +223,6 @@ func (r *repository) GetMember(teamID, userID uint) (*domain.TeamMember, error)
 	return &member, nil
 }
 
-func (r *repository) UpdateMemberStatus(teamID, userID uint, status enums.InvitationStatus) error {
-	return r.db.Model(&domain.TeamMember{}).
-		Where("team_id = ? AND user_id = ?", teamID, userID).
-		Update("invitation_status", status).Error
-}
-
 func (r *repository) AssignAdvisor(teamID, advisorID uint) error {
 	return r.db.Model(&domain.Team{}).
 		Where("id = ?", teamID).
@@ -150,4 +233,349 @@ func (r *repository) RemoveAdvisor(teamID uint) error {
 	return r.db.Model(&domain.Team{}).
 		Where("id = ?", teamID).
 		Update("advisor_id", nil).Error
-}
\ No newline at end of file
+}
+
+func (r *repository) AcceptAdvisor(teamID, advisorID uint) error {
+	return r.db.Model(&domain.Team{}).
+		Where("id = ?", teamID).
+		Updates(map[string]interface{}{"advisor_id": advisorID, "is_finalized": true}).Error
+}
+
+func (r *repository) GetAdvisor(advisorID uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.Where("id = ? AND role = ?", advisorID, enums.RoleAdvisor).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *repository) SetAdvisorAutoAccept(advisorID uint, enabled bool, noConflictsAt *time.Time) error {
+	return r.db.Model(&domain.User{}).
+		Where("id = ?", advisorID).
+		Updates(map[string]interface{}{"advisor_auto_accept": enabled, "advisor_no_conflict_at": noConflictsAt}).Error
+}
+
+func (r *repository) CreateInvitation(invitation *domain.TeamInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// GetLatestInvitation returns the most recent invitation sent to a user for a team
+func (r *repository) GetLatestInvitation(teamID, inviteeID uint) (*domain.TeamInvitation, error) {
+	var invitation domain.TeamInvitation
+	err := r.db.Where("team_id = ? AND invitee_id = ?", teamID, inviteeID).
+		Order("id DESC").
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *repository) UpdateInvitation(invitation *domain.TeamInvitation) error {
+	return r.db.Save(invitation).Error
+}
+
+// AcceptInvitation marks the invitation accepted and adds the invitee as a member in one transaction
+func (r *repository) AcceptInvitation(invitation *domain.TeamInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(invitation).Error; err != nil {
+			return err
+		}
+
+		member := domain.TeamMember{
+			TeamID:           invitation.TeamID,
+			UserID:           invitation.InviteeID,
+			Role:             "member",
+			InvitationStatus: enums.InvitationStatusAccepted,
+		}
+		return tx.Create(&member).Error
+	})
+}
+
+// GetPendingInvitations lists unexpired pending invitations for a user with team and inviter names
+func (r *repository) GetPendingInvitations(userID uint, now time.Time) ([]PendingInvitation, error) {
+	var invitations []PendingInvitation
+	err := r.db.Table("team_invitations").
+		Select("team_invitations.id, team_invitations.team_id, teams.name AS team_name, users.name AS inviter_name, team_invitations.message, team_invitations.created_at AS invited_at, team_invitations.expires_at").
+		Joins("JOIN teams ON teams.id = team_invitations.team_id").
+		Joins("LEFT JOIN users ON users.id = team_invitations.inviter_id").
+		Where("team_invitations.invitee_id = ? AND team_invitations.status = ?", userID, enums.InvitationStatusPending).
+		Where("team_invitations.expires_at IS NULL OR team_invitations.expires_at > ?", now).
+		Order("team_invitations.expires_at ASC").
+		Scan(&invitations).Error
+	return invitations, err
+}
+
+// ExpireInvitations marks pending invitations past their expiry as expired
+func (r *repository) ExpireInvitations(now time.Time) (int64, error) {
+	result := r.db.Model(&domain.TeamInvitation{}).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", enums.InvitationStatusPending, now).
+		Update("status", enums.InvitationStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+// ListDepartmentTeams returns a page of the department's teams, newest first, with the leader and advisor loaded
+func (r *repository) ListDepartmentTeams(departmentID uint, q AdminTeamQuery) ([]domain.Team, int64, error) {
+	query := r.db.Model(&domain.Team{}).Where("teams.department_id = ? AND teams.is_archived = ?", departmentID, q.Archived)
+	if q.Finalized != nil {
+		query = query.Where("teams.is_finalized = ?", *q.Finalized)
+	}
+	if q.HasAdvisor != nil {
+		if *q.HasAdvisor {
+			query = query.Where("teams.advisor_id IS NOT NULL")
+		} else {
+			query = query.Where("teams.advisor_id IS NULL")
+		}
+	}
+	if q.HasProposal != nil {
+		exists := "EXISTS (SELECT 1 FROM proposals WHERE proposals.team_id = teams.id)"
+		if *q.HasProposal {
+			query = query.Where(exists)
+		} else {
+			query = query.Where("NOT " + exists)
+		}
+	}
+	if q.AcademicYear != "" {
+		query = query.Where("teams.academic_year = ?", q.AcademicYear)
+	}
+	if q.Search != "" {
+		query = query.Where("teams.name ILIKE ?", "%"+q.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var teams []domain.Team
+	err := query.
+		Preload("Creator").
+		Preload("Advisor").
+		Preload("Members", "role = ?", "leader").
+		Preload("Members.User").
+		Order("teams.created_at DESC, teams.id DESC").
+		Offset((q.Page - 1) * q.Limit).
+		Limit(q.Limit).
+		Find(&teams).Error
+	return teams, total, err
+}
+
+// GetTeamCounts counts accepted members and proposals for each of the teams
+func (r *repository) GetTeamCounts(teamIDs []uint) (map[uint]teamCounts, error) {
+	counts := make(map[uint]teamCounts, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []teamCounts
+	err := r.db.Table("teams").
+		Select(`teams.id AS team_id,
+			(SELECT COUNT(*) FROM team_members WHERE team_members.team_id = teams.id AND team_members.invitation_status = ?) AS member_count,
+			(SELECT COUNT(*) FROM proposals WHERE proposals.team_id = teams.id) AS proposal_count`, enums.InvitationStatusAccepted).
+		Where("teams.id IN ?", teamIDs).
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.TeamID] = row
+	}
+	return counts, err
+}
+
+func (r *repository) GetDepartment(id uint) (*domain.Department, error) {
+	var department domain.Department
+	if err := r.db.Where("deleted_at IS NULL").First(&department, id).Error; err != nil {
+		return nil, err
+	}
+	return &department, nil
+}
+
+func (r *repository) GetUser(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// TransferDepartment moves the team, its projects and its deadline extension requests to the
+// department in one transaction. The listed advisors are unassigned from the team and its active
+// proposals, and open requests to advisors of the old department are withdrawn.
+func (r *repository) TransferDepartment(team *domain.Team, departmentID uint, removeAdvisorIDs []uint) (*DepartmentTransfer, error) {
+	transfer := &DepartmentTransfer{
+		TeamID:            team.ID,
+		FromDepartmentID:  team.DepartmentID,
+		ToDepartmentID:    departmentID,
+		ProposalIDs:       []uint{},
+		ProjectIDs:        []uint{},
+		RemovedAdvisorIDs: removeAdvisorIDs,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		teamUpdates := map[string]interface{}{"department_id": departmentID}
+		if len(removeAdvisorIDs) > 0 {
+			if team.AdvisorID != nil && containsID(removeAdvisorIDs, *team.AdvisorID) {
+				teamUpdates["advisor_id"] = nil
+			}
+			err := tx.Model(&domain.Proposal{}).
+				Where("team_id = ? AND is_archived = ? AND advisor_id IN ?", team.ID, false, removeAdvisorIDs).
+				Update("advisor_id", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&domain.Team{}).Where("id = ?", team.ID).Updates(teamUpdates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&domain.Proposal{}).Where("team_id = ?", team.ID).Order("id").Pluck("id", &transfer.ProposalIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Project{}).Where("team_id = ?", team.ID).Order("id").Pluck("id", &transfer.ProjectIDs).Error; err != nil {
+			return err
+		}
+		if len(transfer.ProjectIDs) > 0 {
+			err := tx.Model(&domain.Project{}).Where("id IN ?", transfer.ProjectIDs).Update("department_id", departmentID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		extensions := tx.Model(&domain.DeadlineExtension{}).Where("team_id = ?", team.ID).Update("department_id", departmentID)
+		if extensions.Error != nil {
+			return extensions.Error
+		}
+		transfer.DeadlineExtensions = extensions.RowsAffected
+
+		withdrawn := tx.Model(&domain.AdvisorRequest{}).
+			Where("team_id = ? AND status IN ?", team.ID, []enums.AdvisorRequestStatus{enums.AdvisorRequestStatusQueued, enums.AdvisorRequestStatusPending}).
+			Updates(map[string]interface{}{
+				"status":  enums.AdvisorRequestStatusWithdrawn,
+				"comment": "the team moved to another department",
+			})
+		if withdrawn.Error != nil {
+			return withdrawn.Error
+		}
+		transfer.WithdrawnAdvisorRequests = withdrawn.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// leaderActivityQuery selects each unarchived team's leader with their latest sign in or session
+// activity; accounts that never signed in count from their creation
+func (r *repository) leaderActivityQuery() *gorm.DB {
+	return r.db.Table("teams t").
+		Select(`t.id AS team_id, t.name AS team_name, t.department_id AS department_id,
+			COALESCE(d.university_id, 0) AS university_id, u.id AS leader_id, u.name AS leader_name,
+			(u.is_active AND u.deleted_at IS NULL) AS leader_active,
+			GREATEST(u.created_at, u.last_login_at,
+				(SELECT MAX(s.last_seen_at) FROM user_sessions s WHERE s.user_id = u.id)) AS last_active_at,
+			t.leader_absent_notified_at AS notified_at`).
+		Joins("JOIN team_members m ON m.team_id = t.id AND m.role = 'leader'").
+		Joins("JOIN users u ON u.id = m.user_id").
+		Joins("LEFT JOIN departments d ON d.id = t.department_id").
+		Where("t.is_archived = ?", false)
+}
+
+func (r *repository) GetLeaderActivity(teamID uint) (*LeaderActivity, error) {
+	var activity LeaderActivity
+	err := r.leaderActivityQuery().Where("t.id = ?", teamID).Limit(1).Scan(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	if activity.TeamID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &activity, nil
+}
+
+func (r *repository) GetLeaderActivities() ([]LeaderActivity, error) {
+	var activities []LeaderActivity
+	err := r.leaderActivityQuery().Order("t.id").Scan(&activities).Error
+	return activities, err
+}
+
+func (r *repository) SetLeaderAbsentNotified(teamID uint, at *time.Time) error {
+	return r.db.Model(&domain.Team{}).Where("id = ?", teamID).UpdateColumn("leader_absent_notified_at", at).Error
+}
+
+func (r *repository) GetOpenLeadershipVote(teamID uint) (*domain.LeadershipVote, error) {
+	var votes []domain.LeadershipVote
+	err := r.db.Preload("Ballots").
+		Where("team_id = ? AND status = ?", teamID, enums.LeadershipVoteOpen).
+		Limit(1).
+		Find(&votes).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(votes) == 0 {
+		return nil, nil
+	}
+	return &votes[0], nil
+}
+
+func (r *repository) CreateLeadershipVote(vote *domain.LeadershipVote) error {
+	return r.db.Create(vote).Error
+}
+
+// SaveLeadershipBallot records the member's vote, replacing the one they cast before
+func (r *repository) SaveLeadershipBallot(ballot *domain.LeadershipBallot) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "vote_id"}, {Name: "voter_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"candidate_id", "updated_at"}),
+	}).Create(ballot).Error
+}
+
+func (r *repository) CloseLeadershipVote(vote *domain.LeadershipVote) error {
+	return r.db.Model(vote).Select("status", "elected_id", "closed_at").Updates(vote).Error
+}
+
+func (r *repository) ReplaceLeader(teamID, oldLeaderID, newLeaderID uint, vote *domain.LeadershipVote) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", teamID, oldLeaderID).
+			Update("role", "member").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", teamID, newLeaderID).
+			Update("role", "leader").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Team{}).Where("id = ?", teamID).
+			UpdateColumn("leader_absent_notified_at", nil).Error; err != nil {
+			return err
+		}
+		if vote == nil {
+			return nil
+		}
+		return (&repository{db: tx}).CloseLeadershipVote(vote)
+	})
+}
+
+func (r *repository) GetExpiredLeadershipVotes(now time.Time) ([]domain.LeadershipVote, error) {
+	var votes []domain.LeadershipVote
+	err := r.db.Where("status = ? AND expires_at <= ?", enums.LeadershipVoteOpen, now).
+		Order("id").
+		Find(&votes).Error
+	return votes, err
+}
+
+func (r *repository) GetDepartmentAdminIDs(departmentID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.User{}).
+		Where("role = ? AND department_id = ? AND is_active = ?", enums.RoleAdmin, departmentID, true).
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -2,26 +2,86 @@ package teams
 
 import (
 	"backend/internal/domain"
+	"backend/internal/users"
+	"backend/pkg/audit"
 	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/settings"
 	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
 )
 
+// InvitationTTL is how long a team invitation stays valid before it expires
+const InvitationTTL = 7 * 24 * time.Hour
+
+// PendingInvitation is an open invitation as seen by the invitee
+type PendingInvitation struct {
+	ID               uint       `json:"id"`
+	TeamID           uint       `json:"team_id"`
+	TeamName         string     `json:"team_name"`
+	InviterName      string     `json:"inviter_name"`
+	Message          string     `json:"message"`
+	InvitedAt        *time.Time `json:"invited_at"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	RemainingSeconds int64      `json:"remaining_seconds" gorm:"-"` // -1 when the invitation never expires
+}
+
+// MemberProfile is a team member's public user details with their team-specific role and skills
+type MemberProfile struct {
+	users.UserSummary
+	TeamRole       string               `json:"team_role"` // leader or member
+	FunctionalRole enums.FunctionalRole `json:"functional_role"`
+	Skills         []string             `json:"skills"`
+}
+
+// TeamBalance is the functional-role and skill coverage of a team
+type TeamBalance struct {
+	TeamID       uint                         `json:"team_id"`
+	TeamName     string                       `json:"team_name"`
+	MemberCount  int                          `json:"member_count"`
+	RoleCounts   map[enums.FunctionalRole]int `json:"role_counts"`
+	Unassigned   int                          `json:"unassigned"`
+	MissingRoles []enums.FunctionalRole       `json:"missing_roles"`
+	Skills       []string                     `json:"skills"`
+}
+
+// AdvisorChecks looks up advisors' conflict of interest declarations and the department's
+// advisor quotas; implemented by the proposal service
+type AdvisorChecks interface {
+	GetConflictDeclaration(advisorID uint, teamID uint) (*domain.ConflictDeclaration, error)
+	CheckTeamQuota(team *domain.Team, advisorID uint) error
+}
+
 type Service struct {
-	repo Repository
+	repo        Repository
+	bus         *events.Bus
+	auditLogger *audit.Logger
+	settings    *settings.Store
+	advisors    AdvisorChecks
 }
 
-func NewService(r Repository) *Service {
-	return &Service{repo: r}
+func NewService(r Repository, bus *events.Bus, auditLogger *audit.Logger, settingsStore *settings.Store, advisors AdvisorChecks) *Service {
+	return &Service{repo: r, bus: bus, auditLogger: auditLogger, settings: settingsStore, advisors: advisors}
 }
 
 // 1. Create Team
 func (s *Service) CreateTeam(name string, creatorID uint, deptID uint) (*domain.Team, error) {
+	// A student may lead or join only one active team per cohort
+	academicYear := s.repo.GetCurrentAcademicYear(creatorID)
+	if err := s.checkCohortMembership(creatorID, academicYear, 0, "you already belong to an active team in this cohort"); err != nil {
+		return nil, err
+	}
+
 	team := &domain.Team{
 		Name:         name,
 		DepartmentID: deptID,
 		CreatedBy:    creatorID,
 		IsFinalized:  false,
 		AdvisorID:    nil,
+		AcademicYear: academicYear,
 	}
 
 	if err := s.repo.CreateWithLeader(team, creatorID); err != nil {
@@ -50,7 +110,7 @@ func (s *Service) CreateTeam(name string, creatorID uint, deptID uint) (*domain.
 	return team, nil
 }
 // 2. Invite Member
-func (s *Service) InviteMember(teamID, inviteeID, requesterID uint) error {
+func (s *Service) InviteMember(teamID, inviteeID, requesterID uint, message string) error {
 	// A. Check Team Existence
 	team, err := s.repo.GetByID(teamID)
 	if err != nil {
@@ -67,22 +127,162 @@ func (s *Service) InviteMember(teamID, inviteeID, requesterID uint) error {
 		return errors.New("only team leader can invite members")
 	}
 
-	// D. Add to DB
-	member := &domain.TeamMember{
-		TeamID:           teamID,
-		UserID:           inviteeID,
-		Role:             "member",
-		InvitationStatus: enums.InvitationStatusPending,
+	// D. Rule: No duplicate members or open invitations
+	if _, err := s.repo.GetMember(teamID, inviteeID); err == nil {
+		return errors.New("user is already a team member")
+	}
+	if latest, err := s.repo.GetLatestInvitation(teamID, inviteeID); err == nil && latest.Status == enums.InvitationStatusPending {
+		return errors.New("user already has a pending invitation")
+	}
+	if err := s.checkCohortMembership(inviteeID, team.AcademicYear, teamID, "user already belongs to an active team in this cohort"); err != nil {
+		return err
+	}
+
+	// E. Create invitation
+	expiresAt := time.Now().Add(InvitationTTL)
+	invitation := &domain.TeamInvitation{
+		TeamID:    teamID,
+		InviteeID: inviteeID,
+		InviterID: requesterID,
+		Status:    enums.InvitationStatusPending,
+		Message:   message,
+		ExpiresAt: &expiresAt,
+	}
+	if err := s.repo.CreateInvitation(invitation); err != nil {
+		return err
+	}
+
+	s.publishInvitation(team, invitation)
+	return nil
+}
+
+// ResendInvitation reopens a pending or expired invitation with a fresh expiry and notifies the invitee again
+func (s *Service) ResendInvitation(teamID, inviteeID, requesterID uint) error {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil {
+		return err
+	}
+
+	if team.IsFinalized {
+		return errors.New("cannot invite members: team is finalized")
+	}
+
+	if !s.isLeader(team, requesterID) {
+		return errors.New("only team leader can invite members")
+	}
+
+	invitation, err := s.repo.GetLatestInvitation(teamID, inviteeID)
+	if err != nil || (invitation.Status != enums.InvitationStatusPending && invitation.Status != enums.InvitationStatusExpired) {
+		return errors.New("invitation not found")
+	}
+
+	expiresAt := time.Now().Add(InvitationTTL)
+	invitation.Status = enums.InvitationStatusPending
+	invitation.InviterID = requesterID
+	invitation.ExpiresAt = &expiresAt
+	if err := s.repo.UpdateInvitation(invitation); err != nil {
+		return err
+	}
+
+	s.publishInvitation(team, invitation)
+	return nil
+}
+
+func (s *Service) publishInvitation(team *domain.Team, invitation *domain.TeamInvitation) {
+	s.bus.Publish(events.Event{
+		Name:       events.TeamInvited,
+		EntityType: "team",
+		EntityID:   team.ID,
+		ActorID:    invitation.InviterID,
+		UserIDs:    []uint{invitation.InviteeID},
+		Data: map[string]interface{}{
+			"team_name":     team.Name,
+			"inviter_name":  memberName(team, invitation.InviterID),
+			"invitee_id":    invitation.InviteeID,
+			"invitation_id": invitation.ID,
+			"expires_at":    invitation.ExpiresAt,
+		},
+	})
+}
+
+// GetMyInvitations lists the user's pending invitations with time left to respond
+func (s *Service) GetMyInvitations(userID uint) ([]PendingInvitation, error) {
+	now := time.Now()
+	invitations, err := s.repo.GetPendingInvitations(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range invitations {
+		if invitations[i].ExpiresAt == nil {
+			invitations[i].RemainingSeconds = -1
+			continue
+		}
+		invitations[i].RemainingSeconds = int64(invitations[i].ExpiresAt.Sub(now).Seconds())
+	}
+	return invitations, nil
+}
+
+// CleanupExpiredInvitations marks pending invitations past their expiry as expired (run by the scheduler)
+func (s *Service) CleanupExpiredInvitations() {
+	expired, err := s.repo.ExpireInvitations(time.Now())
+	if err != nil {
+		log.Printf("failed to clean up expired invitations: %v", err)
+		return
+	}
+	if expired > 0 {
+		log.Printf("Expired %d team invitation(s)", expired)
 	}
-	return s.repo.AddMember(member)
 }
 
 // 3. Respond to Invite
 func (s *Service) RespondToInvitation(teamID, userID uint, accept bool) error {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.repo.GetLatestInvitation(teamID, userID)
+	if err != nil || invitation.Status == enums.InvitationStatusAccepted || invitation.Status == enums.InvitationStatusRejected {
+		return errors.New("invitation not found")
+	}
+	if invitation.Status == enums.InvitationStatusExpired ||
+		(invitation.ExpiresAt != nil && time.Now().After(*invitation.ExpiresAt)) {
+		return errors.New("invitation has expired")
+	}
+
+	now := time.Now()
+	invitation.RespondedAt = &now
+
+	eventName := events.TeamInvitationAccepted
 	if !accept {
-		return s.repo.RemoveMember(teamID, userID)
+		eventName = events.TeamInvitationRejected
+		invitation.Status = enums.InvitationStatusRejected
+		err = s.repo.UpdateInvitation(invitation)
+	} else {
+		if err := s.checkCohortMembership(userID, team.AcademicYear, teamID, "you already belong to an active team in this cohort"); err != nil {
+			return err
+		}
+		invitation.Status = enums.InvitationStatusAccepted
+		err = s.repo.AcceptInvitation(invitation)
 	}
-	return s.repo.UpdateMemberStatus(teamID, userID, enums.InvitationStatusAccepted)
+	if err != nil {
+		return err
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       eventName,
+		EntityType: "team",
+		EntityID:   teamID,
+		ActorID:    userID,
+		UserIDs:    leaderIDs(team),
+		Data: map[string]interface{}{
+			"team_name":     team.Name,
+			"member_name":   s.userName(userID),
+			"invitation_id": invitation.ID,
+		},
+	})
+	return nil
 }
 
 // 4. Finalize Team (The Lock)
@@ -105,6 +305,19 @@ func (s *Service) FinalizeTeam(teamID, requesterID uint) error {
 	return s.repo.Update(team)
 }
 
+// checkCohortMembership enforces one active team per cohort. Teams from archived cohorts
+// keep their members for the record but no longer count.
+func (s *Service) checkCohortMembership(userID uint, academicYear string, teamID uint, message string) error {
+	taken, err := s.repo.HasActiveTeamInCohort(userID, academicYear, teamID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errors.New(message)
+	}
+	return nil
+}
+
 // Helper
 func (s *Service) isLeader(team *domain.Team, userID uint) bool {
 	for _, m := range team.Members {
@@ -115,6 +328,33 @@ func (s *Service) isLeader(team *domain.Team, userID uint) bool {
 	return false
 }
 
+func leaderIDs(team *domain.Team) []uint {
+	var ids []uint
+	for _, m := range team.Members {
+		if m.Role == "leader" {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+func (s *Service) userName(userID uint) string {
+	var user domain.User
+	if err := s.repo.GetDB().Select("name").First(&user, userID).Error; err != nil {
+		return ""
+	}
+	return user.Name
+}
+
+func memberName(team *domain.Team, userID uint) string {
+	for _, m := range team.Members {
+		if m.UserID == userID {
+			return m.User.Name
+		}
+	}
+	return ""
+}
+
 // Getters for Handler
 func (s *Service) GetMyTeams(userID uint, availableOnly bool) ([]domain.Team, error) {
 	return s.repo.GetByUserID(userID, availableOnly)
@@ -124,24 +364,139 @@ func (s *Service) GetTeam(id uint) (*domain.Team, error) {
 	return s.repo.GetByID(id)
 }
 
-// GetTeamMembers retrieves the list of users in a team
-func (s *Service) GetTeamMembers(teamID uint) ([]domain.User, error) {
+// GetTeams loads several teams at once; IDs that do not exist are left out
+func (s *Service) GetTeams(ids []uint) ([]domain.Team, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return s.repo.GetByIDs(ids)
+}
+
+// GetTeamMembers retrieves the users in a team along with their team role, functional role and skills
+func (s *Service) GetTeamMembers(teamID uint) ([]MemberProfile, error) {
 	// 1. Get the team (Repo already preloads Members and Members.User)
 	team, err := s.repo.GetByID(teamID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Extract the User objects from the TeamMember relationship
-	var users []domain.User
+	// 2. Flatten each TeamMember into the user plus its team-specific fields
+	var members []MemberProfile
 	for _, member := range team.Members {
 		// Verify user data exists (safety check)
 		if member.User.ID != 0 {
-			users = append(users, member.User)
+			skills := member.Skills
+			if skills == nil {
+				skills = []string{}
+			}
+			members = append(members, MemberProfile{
+				UserSummary:    *users.NewUserSummary(&member.User),
+				TeamRole:       member.Role,
+				FunctionalRole: member.FunctionalRole,
+				Skills:         skills,
+			})
 		}
 	}
 
-	return users, nil
+	return members, nil
+}
+
+// UpdateMemberProfile lets the leader set a member's functional role and skill tags
+func (s *Service) UpdateMemberProfile(teamID, memberID, requesterID uint, functionalRole string, skills []string) (*domain.TeamMember, error) {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil {
+		return nil, errors.New("team not found")
+	}
+	if !s.isLeader(team, requesterID) {
+		return nil, errors.New("only the team leader can edit member profiles")
+	}
+
+	member, err := s.repo.GetMember(teamID, memberID)
+	if err != nil {
+		return nil, errors.New("user is not a member of this team")
+	}
+
+	if functionalRole != "" && !enums.IsValidFunctionalRole(functionalRole) {
+		return nil, errors.New("invalid functional role")
+	}
+	normalized, err := normalizeSkills(skills)
+	if err != nil {
+		return nil, err
+	}
+
+	member.FunctionalRole = enums.FunctionalRole(functionalRole)
+	member.Skills = normalized
+	if err := s.repo.UpdateMemberProfile(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// GetTeamBalance summarizes a team's functional roles and skills so admins can judge its balance
+func (s *Service) GetTeamBalance(teamID uint, departmentID uint) (*TeamBalance, error) {
+	team, err := s.repo.GetByID(teamID)
+	if err != nil || team.DepartmentID != departmentID {
+		return nil, errors.New("team not found")
+	}
+
+	balance := &TeamBalance{
+		TeamID:       team.ID,
+		TeamName:     team.Name,
+		MemberCount:  len(team.Members),
+		RoleCounts:   make(map[enums.FunctionalRole]int),
+		MissingRoles: []enums.FunctionalRole{},
+		Skills:       []string{},
+	}
+
+	skillSet := make(map[string]bool)
+	for _, member := range team.Members {
+		if member.FunctionalRole == "" {
+			balance.Unassigned++
+		} else {
+			balance.RoleCounts[member.FunctionalRole]++
+		}
+		for _, skill := range member.Skills {
+			skillSet[skill] = true
+		}
+	}
+	for _, role := range enums.FunctionalRoles {
+		if balance.RoleCounts[role] == 0 {
+			balance.MissingRoles = append(balance.MissingRoles, role)
+		}
+	}
+	for skill := range skillSet {
+		balance.Skills = append(balance.Skills, skill)
+	}
+	sort.Strings(balance.Skills)
+
+	return balance, nil
+}
+
+// maxSkillsPerMember and maxSkillLength bound the free-form skill tags
+const (
+	maxSkillsPerMember = 15
+	maxSkillLength     = 30
+)
+
+// normalizeSkills lowercases, trims and de-duplicates skill tags
+func normalizeSkills(skills []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if skill == "" || seen[skill] {
+			continue
+		}
+		if len(skill) > maxSkillLength {
+			return nil, errors.New("skill tags must be at most 30 characters")
+		}
+		seen[skill] = true
+		normalized = append(normalized, skill)
+	}
+	if len(normalized) > maxSkillsPerMember {
+		return nil, errors.New("a member can have at most 15 skill tags")
+	}
+	return normalized, nil
 }
 
 func (s *Service) RemoveMember(teamID, memberID, requesterID uint) error {
@@ -231,24 +586,45 @@ func (s *Service) DeleteTeam(teamID, requesterID uint) error {
 	return s.repo.Delete(teamID)
 }
 
-// 8. Assign Advisor
-func (s *Service) AssignAdvisor(teamID, requesterID, advisorID uint) error {
+// 8. Assign Advisor. Advisors with auto-accept on accept at once and the team is finalized;
+// accepted reports whether that happened.
+func (s *Service) AssignAdvisor(teamID, requesterID, advisorID uint) (accepted bool, err error) {
 	team, err := s.repo.GetByID(teamID)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Rule: Only Leader can assign
 	if !s.isLeader(team, requesterID) {
-		return errors.New("only team leader can assign advisor")
+		return false, errors.New("only team leader can assign advisor")
 	}
 
 	// Rule: Cannot change advisor if finalized
 	if team.IsFinalized {
-		return errors.New("cannot change advisor: team is finalized")
+		return false, errors.New("cannot change advisor: team is finalized")
+	}
+
+	// Rule: Cannot pick an advisor with an unresolved conflict of interest
+	declaration, err := s.advisors.GetConflictDeclaration(advisorID, teamID)
+	if err != nil {
+		return false, err
+	}
+	if declaration != nil && declaration.HasConflict && declaration.OverriddenAt == nil {
+		return false, errors.New("advisor has declared a conflict of interest with this team")
+	}
+
+	// Rule: Cannot pick an advisor the department's quotas leave no room for
+	if err := s.advisors.CheckTeamQuota(team, advisorID); err != nil {
+		return false, err
 	}
 
-	return s.repo.AssignAdvisor(teamID, advisorID)
+	if s.autoAccepts(advisorID) {
+		if err := s.repo.AcceptAdvisor(teamID, advisorID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, s.repo.AssignAdvisor(teamID, advisorID)
 }
 
 // 9. Advisor Response (approve/reject team assignment)
@@ -265,6 +641,23 @@ func (s *Service) AdvisorResponse(teamID, advisorID uint, decision, comment stri
 
 	// Apply decision
 	if decision == "approve" {
+		// Rule: Advisor must declare conflicts of interest before accepting
+		declaration, err := s.advisors.GetConflictDeclaration(advisorID, teamID)
+		if err != nil {
+			return err
+		}
+		if declaration == nil {
+			return errors.New("declare any conflict of interest with this team before accepting it")
+		}
+		if declaration.HasConflict && declaration.OverriddenAt == nil {
+			return errors.New("cannot accept: you declared a conflict of interest that the department head has not overridden")
+		}
+
+		// Rule: The quotas may have filled up since the team picked the advisor
+		if err := s.advisors.CheckTeamQuota(team, advisorID); err != nil {
+			return err
+		}
+
 		// Approve the team - can now create proposals
 		team.IsFinalized = true
 		return s.repo.Update(team)
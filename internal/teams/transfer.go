@@ -0,0 +1,169 @@
+package teams
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var (
+	ErrDepartmentNotFound       = errors.New("department not found")
+	ErrSameDepartment           = errors.New("the team already belongs to this department")
+	ErrOtherUniversity          = errors.New("teams can only move to a department of the same university")
+	ErrTeamArchived             = errors.New("archived teams cannot be transferred")
+	ErrMembersInOtherDepartment = errors.New("some members do not belong to the target department")
+	ErrAdvisorInOtherDepartment = errors.New("the team's advisor does not belong to the target department; set remove_advisor to unassign them")
+)
+
+// TransferDepartmentRequest moves a team created under the wrong department
+type TransferDepartmentRequest struct {
+	DepartmentID  uint   `json:"department_id" binding:"required" example:"4"`
+	Reason        string `json:"reason" binding:"required,max=500" example:"Team was registered under Software Engineering by mistake"`
+	RemoveAdvisor bool   `json:"remove_advisor"` // unassign an advisor of the old department instead of refusing
+}
+
+// MisplacedMember is a team member whose own department is not the target department
+type MisplacedMember struct {
+	UserID       uint   `json:"user_id"`
+	Name         string `json:"name"`
+	DepartmentID uint   `json:"department_id"`
+}
+
+// MemberDepartmentError refuses a transfer; it matches ErrMembersInOtherDepartment and names the members
+type MemberDepartmentError struct {
+	Members []MisplacedMember
+}
+
+func (e *MemberDepartmentError) Error() string {
+	names := make([]string, 0, len(e.Members))
+	for _, m := range e.Members {
+		names = append(names, m.Name)
+	}
+	return fmt.Sprintf("%s: %s", ErrMembersInOtherDepartment, strings.Join(names, ", "))
+}
+
+func (e *MemberDepartmentError) Unwrap() error {
+	return ErrMembersInOtherDepartment
+}
+
+// DepartmentTransfer is what moved with the team
+type DepartmentTransfer struct {
+	TeamID                   uint   `json:"team_id"`
+	FromDepartmentID         uint   `json:"from_department_id"`
+	ToDepartmentID           uint   `json:"to_department_id"`
+	ProposalIDs              []uint `json:"proposal_ids"`
+	ProjectIDs               []uint `json:"project_ids"`
+	DeadlineExtensions       int64  `json:"deadline_extensions"`        // requests now decided by the new department
+	WithdrawnAdvisorRequests int64  `json:"withdrawn_advisor_requests"` // open requests to advisors of the old department
+	RemovedAdvisorIDs        []uint `json:"removed_advisor_ids,omitempty"`
+}
+
+// TransferDepartment moves a team of the admin's department, with its proposals and projects, to
+// another department of the same university. Every member must already belong to the target
+// department. Each moved team, proposal and project gets an audit entry.
+func (s *Service) TransferDepartment(teamID uint, req TransferDepartmentRequest, adminID uint, adminDeptID uint, meta audit.RequestMeta) (*DepartmentTransfer, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to transfer a team")
+	}
+
+	team, err := s.repo.GetByID(teamID)
+	// Admins without a department manage every department
+	if err != nil || (adminDeptID != 0 && team.DepartmentID != adminDeptID) {
+		return nil, errors.New("team not found")
+	}
+	if team.IsArchived {
+		return nil, ErrTeamArchived
+	}
+	if team.DepartmentID == req.DepartmentID {
+		return nil, ErrSameDepartment
+	}
+
+	target, err := s.repo.GetDepartment(req.DepartmentID)
+	if err != nil {
+		return nil, ErrDepartmentNotFound
+	}
+	if team.Department == nil || team.Department.UniversityID != target.UniversityID {
+		return nil, ErrOtherUniversity
+	}
+
+	var misplaced []MisplacedMember
+	for _, m := range team.Members {
+		if m.User.DepartmentID != target.ID {
+			misplaced = append(misplaced, MisplacedMember{UserID: m.UserID, Name: m.User.Name, DepartmentID: m.User.DepartmentID})
+		}
+	}
+	if len(misplaced) > 0 {
+		return nil, &MemberDepartmentError{Members: misplaced}
+	}
+
+	// The team's advisor and those of its active proposals have to follow or be unassigned
+	advisorIDs := make(map[uint]bool)
+	if team.AdvisorID != nil {
+		advisorIDs[*team.AdvisorID] = true
+	}
+	for _, p := range team.Proposals {
+		if p.AdvisorID != nil && !p.IsArchived {
+			advisorIDs[*p.AdvisorID] = true
+		}
+	}
+	var removeAdvisors []uint
+	for advisorID := range advisorIDs {
+		advisor, err := s.repo.GetUser(advisorID)
+		if err != nil {
+			return nil, err
+		}
+		if advisor.DepartmentID != target.ID {
+			removeAdvisors = append(removeAdvisors, advisorID)
+		}
+	}
+	if len(removeAdvisors) > 0 && !req.RemoveAdvisor {
+		return nil, ErrAdvisorInOtherDepartment
+	}
+
+	admin, err := s.repo.GetUser(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	transfer, err := s.repo.TransferDepartment(team, target.ID, removeAdvisors)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditTransfer(transfer, team, reason, admin, meta)
+	return transfer, nil
+}
+
+// auditTransfer records the move on the team and on every proposal and project that moved with it
+func (s *Service) auditTransfer(transfer *DepartmentTransfer, team *domain.Team, reason string, admin *domain.User, meta audit.RequestMeta) {
+	record := func(entityType string, entityID uint, newState map[string]interface{}) {
+		newState["department_id"] = transfer.ToDepartmentID
+		newState["team_id"] = transfer.TeamID
+		newState["reason"] = reason
+		err := s.auditLogger.LogAction(entityType, entityID, "department_transferred", &admin.ID, string(admin.Role), admin.Email,
+			map[string]interface{}{"department_id": transfer.FromDepartmentID},
+			newState, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+		if err != nil {
+			log.Printf("failed to audit the department transfer of %s %d: %v", entityType, entityID, err)
+		}
+	}
+
+	record("team", team.ID, map[string]interface{}{
+		"team_name":                  team.Name,
+		"proposal_ids":               transfer.ProposalIDs,
+		"project_ids":                transfer.ProjectIDs,
+		"deadline_extensions":        transfer.DeadlineExtensions,
+		"withdrawn_advisor_requests": transfer.WithdrawnAdvisorRequests,
+		"removed_advisor_ids":        transfer.RemovedAdvisorIDs,
+	})
+	for _, id := range transfer.ProposalIDs {
+		record("proposal", id, map[string]interface{}{})
+	}
+	for _, id := range transfer.ProjectIDs {
+		record("project", id, map[string]interface{}{})
+	}
+}
@@ -2,8 +2,10 @@ package universities
 
 import (
 	"backend/pkg/response"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -154,3 +156,76 @@ func (h *Handler) DeleteUniversity(c *gin.Context) {
 
 	response.JSON(c, http.StatusOK, "University deleted successfully", nil)
 }
+
+// OnboardUniversity godoc
+// @Summary Onboard a university
+// @Description Creates a university, its departments with their per-cohort quotas, and the first department head account in one transaction. Nothing is created if any part fails.
+// @Tags Universities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param onboarding body OnboardUniversityRequest true "University, departments and department head"
+// @Success 201 {object} response.Response{data=OnboardingResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/universities/onboard [post]
+func (h *Handler) OnboardUniversity(c *gin.Context) {
+	var req OnboardUniversityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.service.OnboardUniversity(req)
+	if err != nil {
+		if errors.Is(err, ErrHeadEmailTaken) {
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "duplicate key") {
+			response.Error(c, http.StatusConflict, "University or department already exists", err.Error())
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Onboarding failed", err.Error())
+		return
+	}
+
+	response.JSON(c, http.StatusCreated, "University onboarded successfully", result)
+}
+
+// GetMetrics godoc
+// @Summary University metrics
+// @Description Roll-up for rectorate-level reporting: active students, teams and proposals by status (archived cohorts excluded), published projects of every cohort, and the average time from a proposal version's submission to its first feedback. Figures are cached for up to 15 minutes; generated_at says when they were computed. Admins can only see their own university.
+// @Tags Universities
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "University ID"
+// @Success 200 {object} response.Response{data=UniversityMetrics}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /universities/{id}/metrics [get]
+func (h *Handler) GetMetrics(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid university ID", err.Error())
+		return
+	}
+	if uint(id) != c.GetUint("university_id") {
+		response.Error(c, http.StatusForbidden, "You can only view the metrics of your own university", nil)
+		return
+	}
+
+	metrics, err := h.service.GetMetrics(uint(id))
+	if err != nil {
+		if errors.Is(err, ErrUniversityNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to compute university metrics", err.Error())
+		return
+	}
+
+	response.Success(c, metrics)
+}
@@ -0,0 +1,78 @@
+package universities
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// MetricsTTL is how long a university's metrics are served from memory; the roll-up scans every
+// proposal of the university, and reporting does not need live figures
+const MetricsTTL = 15 * time.Minute
+
+var ErrUniversityNotFound = errors.New("university not found")
+
+// UniversityMetrics is the roll-up of a university's capstone activity for rectorate-level reporting
+type UniversityMetrics struct {
+	UniversityID   uint   `json:"university_id"`
+	UniversityName string `json:"university_name"`
+	AcademicYear   string `json:"academic_year"`
+
+	Students          int64            `json:"students"`  // active student accounts
+	Teams             int64            `json:"teams"`     // teams not archived with their cohort
+	Proposals         int64            `json:"proposals"` // proposals not archived, by status below
+	ProposalsByStatus map[string]int64 `json:"proposals_by_status"`
+	PublishedProjects int64            `json:"published_projects"` // public projects of every cohort
+
+	// AvgReviewTurnaroundHours is the mean time from a proposal version being submitted to its
+	// first feedback, over the versions reviewed so far; nil when none has been
+	AvgReviewTurnaroundHours *float64 `json:"avg_review_turnaround_hours"`
+	ReviewedVersions         int64    `json:"reviewed_versions"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// metricsCache keeps the metrics per university until they are MetricsTTL old
+type metricsCache struct {
+	mu      sync.Mutex
+	entries map[uint]*UniversityMetrics
+}
+
+func (c *metricsCache) get(universityID uint) (*UniversityMetrics, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metrics, ok := c.entries[universityID]
+	if !ok || time.Since(metrics.GeneratedAt) > MetricsTTL {
+		return nil, false
+	}
+	return metrics, true
+}
+
+func (c *metricsCache) put(metrics *UniversityMetrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[metrics.UniversityID] = metrics
+}
+
+// GetMetrics returns the university's metrics, computing them when the cached ones are stale
+func (s *Service) GetMetrics(universityID uint) (*UniversityMetrics, error) {
+	if metrics, ok := s.metrics.get(universityID); ok {
+		return metrics, nil
+	}
+
+	university, err := s.repo.GetByID(universityID)
+	if err != nil {
+		return nil, ErrUniversityNotFound
+	}
+	metrics, err := s.repo.GetMetrics(universityID)
+	if err != nil {
+		return nil, err
+	}
+	metrics.UniversityID = university.ID
+	metrics.UniversityName = university.Name
+	metrics.AcademicYear = university.AcademicYear
+	metrics.GeneratedAt = time.Now()
+
+	s.metrics.put(metrics)
+	return metrics, nil
+}
@@ -0,0 +1,156 @@
+package universities
+
+import (
+	"backend/internal/domain"
+	"backend/internal/users"
+	"backend/pkg/enums"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrHeadEmailTaken = errors.New("department head email already exists")
+
+// OnboardUniversityRequest sets up a new university in one call: the university, its
+// departments with their quotas, and the account of the first department head
+type OnboardUniversityRequest struct {
+	Name             string                     `json:"name" binding:"required"`
+	AcademicYear     string                     `json:"academic_year"`
+	ProjectPeriod    string                     `json:"project_period"`
+	VisibilityRule   string                     `json:"visibility_rule"`    // defaults to private
+	AICheckerEnabled *bool                      `json:"ai_checker_enabled"` // defaults to enabled
+	BlindReview      bool                       `json:"blind_review"`
+	ProjectStartsOn  *time.Time                 `json:"project_starts_on"`
+	ProjectEndsOn    *time.Time                 `json:"project_ends_on"`
+	Departments      []OnboardDepartmentRequest `json:"departments" binding:"required,min=1,dive"`
+	DepartmentHead   OnboardHeadRequest         `json:"department_head" binding:"required"`
+}
+
+type OnboardDepartmentRequest struct {
+	Name                 string `json:"name" binding:"required"`
+	Code                 string `json:"code" binding:"required"`
+	AdvisorProposalLimit int    `json:"advisor_proposal_limit" binding:"min=0"` // 0 = unlimited
+	TeamLimit            int    `json:"team_limit" binding:"min=0"`             // 0 = unlimited
+}
+
+type OnboardHeadRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Email          string `json:"email" binding:"required,email"`
+	Password       string `json:"password" binding:"required,min=6"`
+	DepartmentCode string `json:"department_code"` // department they head; defaults to the first one
+}
+
+// OnboardingResult is what the onboarding created
+type OnboardingResult struct {
+	University     domain.University     `json:"university"`
+	Departments    []OnboardedDepartment `json:"departments"`
+	DepartmentHead users.UserResponse    `json:"department_head"`
+}
+
+type OnboardedDepartment struct {
+	ID                   uint   `json:"id"`
+	Name                 string `json:"name"`
+	Code                 string `json:"code"`
+	AdvisorProposalLimit int    `json:"advisor_proposal_limit"`
+	TeamLimit            int    `json:"team_limit"`
+}
+
+// onboarding is everything written for a new university, in one transaction
+type onboarding struct {
+	University  *domain.University
+	Departments []domain.Department
+	Quotas      []domain.DepartmentQuota // same order as Departments
+	Head        *domain.User
+	HeadIndex   int // index in Departments of the head's department
+}
+
+// OnboardUniversity validates the whole request up front and then creates everything in a
+// single transaction, so a failure part-way leaves nothing behind
+func (s *Service) OnboardUniversity(req OnboardUniversityRequest) (*OnboardingResult, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errors.New("university name is required")
+	}
+	if err := validateProjectPeriod(req.ProjectStartsOn, req.ProjectEndsOn); err != nil {
+		return nil, err
+	}
+
+	plan := &onboarding{
+		University: &domain.University{
+			Name:             strings.TrimSpace(req.Name),
+			AcademicYear:     req.AcademicYear,
+			ProjectPeriod:    req.ProjectPeriod,
+			VisibilityRule:   req.VisibilityRule,
+			AICheckerEnabled: true,
+			BlindReview:      req.BlindReview,
+			ProjectStartsOn:  req.ProjectStartsOn,
+			ProjectEndsOn:    req.ProjectEndsOn,
+		},
+		HeadIndex: -1,
+	}
+	if plan.University.VisibilityRule == "" {
+		plan.University.VisibilityRule = "private"
+	}
+	if req.AICheckerEnabled != nil {
+		plan.University.AICheckerEnabled = *req.AICheckerEnabled
+	}
+
+	headCode := strings.ToUpper(strings.TrimSpace(req.DepartmentHead.DepartmentCode))
+	seen := make(map[string]bool)
+	for i, d := range req.Departments {
+		code := strings.ToUpper(strings.TrimSpace(d.Code))
+		if seen[code] {
+			return nil, errors.New("duplicate department code: " + code)
+		}
+		seen[code] = true
+		if code == headCode || (headCode == "" && i == 0) {
+			plan.HeadIndex = i
+		}
+		plan.Departments = append(plan.Departments, domain.Department{Name: strings.TrimSpace(d.Name), Code: code})
+		plan.Quotas = append(plan.Quotas, domain.DepartmentQuota{
+			AdvisorProposalLimit: d.AdvisorProposalLimit,
+			TeamLimit:            d.TeamLimit,
+		})
+	}
+	if plan.HeadIndex < 0 {
+		return nil, errors.New("department_code does not match any of the new departments")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.DepartmentHead.Email))
+	if s.repo.EmailExists(email) {
+		return nil, ErrHeadEmailTaken
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.DepartmentHead.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+	plan.Head = &domain.User{
+		Name:          req.DepartmentHead.Name,
+		Email:         email,
+		Password:      string(hashedPassword),
+		Role:          enums.RoleAdmin,
+		IsActive:      true,
+		EmailVerified: true,
+	}
+
+	if err := s.repo.CreateOnboarding(plan); err != nil {
+		return nil, err
+	}
+
+	result := &OnboardingResult{
+		University:     *plan.University,
+		Departments:    make([]OnboardedDepartment, 0, len(plan.Departments)),
+		DepartmentHead: users.NewUserResponse(plan.Head),
+	}
+	for i, d := range plan.Departments {
+		result.Departments = append(result.Departments, OnboardedDepartment{
+			ID:                   d.ID,
+			Name:                 d.Name,
+			Code:                 d.Code,
+			AdvisorProposalLimit: plan.Quotas[i].AdvisorProposalLimit,
+			TeamLimit:            plan.Quotas[i].TeamLimit,
+		})
+	}
+	return result, nil
+}
@@ -2,6 +2,8 @@ package universities
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/enums"
+	"math"
 
 	"gorm.io/gorm"
 )
@@ -12,6 +14,13 @@ type Repository interface {
 	GetAll() ([]domain.University, error)
 	Update(university *domain.University) error
 	Delete(id uint) error
+
+	// Onboarding
+	EmailExists(email string) bool
+	CreateOnboarding(plan *onboarding) error
+
+	// Reporting
+	GetMetrics(universityID uint) (*UniversityMetrics, error)
 }
 
 type repository struct {
@@ -48,3 +57,108 @@ func (r *repository) Update(university *domain.University) error {
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&domain.University{}, id).Error
 }
+
+func (r *repository) EmailExists(email string) bool {
+	var count int64
+	r.db.Model(&domain.User{}).Where("LOWER(email) = ?", email).Count(&count)
+	return count > 0
+}
+
+// CreateOnboarding writes the university, its departments and quotas, and the department head
+// in one transaction; IDs are filled in on the plan
+func (r *repository) CreateOnboarding(plan *onboarding) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(plan.University).Error; err != nil {
+			return err
+		}
+		for i := range plan.Departments {
+			plan.Departments[i].UniversityID = plan.University.ID
+			if err := tx.Omit("University").Create(&plan.Departments[i]).Error; err != nil {
+				return err
+			}
+			plan.Quotas[i].DepartmentID = plan.Departments[i].ID
+			if err := tx.Create(&plan.Quotas[i]).Error; err != nil {
+				return err
+			}
+		}
+		plan.Head.UniversityID = plan.University.ID
+		plan.Head.DepartmentID = plan.Departments[plan.HeadIndex].ID
+		plan.Head.Department = domain.Department{}
+		return tx.Omit("University", "Department").Create(plan.Head).Error
+	})
+}
+
+// GetMetrics counts the university's students, teams, proposals and published projects, and
+// measures how long versions waited for their first feedback
+func (r *repository) GetMetrics(universityID uint) (*UniversityMetrics, error) {
+	metrics := &UniversityMetrics{ProposalsByStatus: make(map[string]int64)}
+
+	err := r.db.Model(&domain.User{}).
+		Where("university_id = ? AND role = ? AND is_active = true AND deleted_at IS NULL", universityID, enums.RoleStudent).
+		Count(&metrics.Students).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Table("teams").
+		Joins("JOIN departments ON departments.id = teams.department_id").
+		Where("departments.university_id = ? AND teams.is_archived = false", universityID).
+		Count(&metrics.Teams).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []struct {
+		Status string
+		Count  int64
+	}
+	err = r.db.Table("proposals").
+		Select("proposals.status AS status, COUNT(*) AS count").
+		Joins("JOIN teams ON teams.id = proposals.team_id").
+		Joins("JOIN departments ON departments.id = teams.department_id").
+		Where("departments.university_id = ? AND proposals.is_archived = false", universityID).
+		Group("proposals.status").
+		Scan(&statuses).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		metrics.ProposalsByStatus[s.Status] = s.Count
+		metrics.Proposals += s.Count
+	}
+
+	err = r.db.Table("projects").
+		Joins("JOIN departments ON departments.id = projects.department_id").
+		Where("departments.university_id = ? AND projects.visibility = ?", universityID, "public").
+		Count(&metrics.PublishedProjects).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var turnaround struct {
+		Versions int64
+		AvgHours *float64
+	}
+	err = r.db.Raw(`
+		SELECT COUNT(*) AS versions,
+			AVG(EXTRACT(EPOCH FROM (first_feedback.created_at - pv.created_at)) / 3600) AS avg_hours
+		FROM proposal_versions pv
+		JOIN proposals p ON p.id = pv.proposal_id
+		JOIN teams t ON t.id = p.team_id
+		JOIN departments d ON d.id = t.department_id
+		JOIN LATERAL (
+			SELECT MIN(f.created_at) AS created_at FROM feedbacks f WHERE f.proposal_version_id = pv.id
+		) first_feedback ON first_feedback.created_at IS NOT NULL
+		WHERE d.university_id = ?`, universityID).
+		Scan(&turnaround).Error
+	if err != nil {
+		return nil, err
+	}
+	metrics.ReviewedVersions = turnaround.Versions
+	if turnaround.AvgHours != nil {
+		hours := math.Round(*turnaround.AvgHours*10) / 10
+		metrics.AvgReviewTurnaroundHours = &hours
+	}
+
+	return metrics, nil
+}
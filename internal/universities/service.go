@@ -3,36 +3,47 @@ package universities
 import (
 	"backend/internal/domain"
 	"errors"
+	"time"
 )
 
 type Service struct {
-	repo Repository
+	repo    Repository
+	metrics *metricsCache
 }
 
 func NewService(r Repository) *Service {
-	return &Service{repo: r}
+	return &Service{repo: r, metrics: &metricsCache{entries: make(map[uint]*UniversityMetrics)}}
 }
 
 type CreateUniversityRequest struct {
-	Name             string `json:"name" binding:"required"`
-	AcademicYear     string `json:"academic_year"`
-	ProjectPeriod    string `json:"project_period"`
-	VisibilityRule   string `json:"visibility_rule"`
-	AICheckerEnabled bool   `json:"ai_checker_enabled"`
+	Name             string     `json:"name" binding:"required"`
+	AcademicYear     string     `json:"academic_year"`
+	ProjectPeriod    string     `json:"project_period"`
+	VisibilityRule   string     `json:"visibility_rule"`
+	AICheckerEnabled bool       `json:"ai_checker_enabled"`
+	BlindReview      bool       `json:"blind_review"`
+	ProjectStartsOn  *time.Time `json:"project_starts_on"`
+	ProjectEndsOn    *time.Time `json:"project_ends_on"`
 }
 
 type UpdateUniversityRequest struct {
-	Name             string `json:"name"`
-	AcademicYear     string `json:"academic_year"`
-	ProjectPeriod    string `json:"project_period"`
-	VisibilityRule   string `json:"visibility_rule"`
-	AICheckerEnabled *bool  `json:"ai_checker_enabled"`
+	Name             string     `json:"name"`
+	AcademicYear     string     `json:"academic_year"`
+	ProjectPeriod    string     `json:"project_period"`
+	VisibilityRule   string     `json:"visibility_rule"`
+	AICheckerEnabled *bool      `json:"ai_checker_enabled"`
+	BlindReview      *bool      `json:"blind_review"`
+	ProjectStartsOn  *time.Time `json:"project_starts_on"`
+	ProjectEndsOn    *time.Time `json:"project_ends_on"`
 }
 
 func (s *Service) CreateUniversity(req CreateUniversityRequest) (*domain.University, error) {
 	if req.Name == "" {
 		return nil, errors.New("university name is required")
 	}
+	if err := validateProjectPeriod(req.ProjectStartsOn, req.ProjectEndsOn); err != nil {
+		return nil, err
+	}
 
 	university := &domain.University{
 		Name:             req.Name,
@@ -40,6 +51,9 @@ func (s *Service) CreateUniversity(req CreateUniversityRequest) (*domain.Univers
 		ProjectPeriod:    req.ProjectPeriod,
 		VisibilityRule:   req.VisibilityRule,
 		AICheckerEnabled: req.AICheckerEnabled,
+		BlindReview:      req.BlindReview,
+		ProjectStartsOn:  req.ProjectStartsOn,
+		ProjectEndsOn:    req.ProjectEndsOn,
 	}
 
 	if university.VisibilityRule == "" {
@@ -83,6 +97,18 @@ func (s *Service) UpdateUniversity(id uint, req UpdateUniversityRequest) (*domai
 	if req.AICheckerEnabled != nil {
 		university.AICheckerEnabled = *req.AICheckerEnabled
 	}
+	if req.BlindReview != nil {
+		university.BlindReview = *req.BlindReview
+	}
+	if req.ProjectStartsOn != nil {
+		university.ProjectStartsOn = req.ProjectStartsOn
+	}
+	if req.ProjectEndsOn != nil {
+		university.ProjectEndsOn = req.ProjectEndsOn
+	}
+	if err := validateProjectPeriod(university.ProjectStartsOn, university.ProjectEndsOn); err != nil {
+		return nil, err
+	}
 
 	err = s.repo.Update(university)
 	if err != nil {
@@ -100,3 +126,14 @@ func (s *Service) DeleteUniversity(id uint) error {
 
 	return s.repo.Delete(id)
 }
+
+// validateProjectPeriod requires both or neither bound, in order
+func validateProjectPeriod(startsOn, endsOn *time.Time) error {
+	if (startsOn == nil) != (endsOn == nil) {
+		return errors.New("project_starts_on and project_ends_on must be set together")
+	}
+	if startsOn != nil && !endsOn.After(*startsOn) {
+		return errors.New("project_ends_on must be after project_starts_on")
+	}
+	return nil
+}
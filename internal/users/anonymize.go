@@ -0,0 +1,115 @@
+package users
+
+import (
+	"backend/pkg/audit"
+	"backend/pkg/enums"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrAnonymizeSelf         = errors.New("you cannot anonymize your own account")
+	ErrAnonymizeOutsideScope = errors.New("the account must belong to your department")
+	ErrAnonymizeAdmin        = errors.New("admin accounts cannot be anonymized")
+	ErrAlreadyAnonymized     = errors.New("the account has been anonymized")
+)
+
+// AnonymizedName replaces an erased user's name wherever they still appear, e.g. as a team
+// member, proposal author or project reviewer
+const AnonymizedName = "Anonymized User"
+
+// ErasedIdentity is the personal data an anonymization removes, kept only long enough to scrub
+// copies of it from audit states and reviews
+type ErasedIdentity struct {
+	Name      string
+	Email     string
+	StudentID string
+}
+
+// anonymizedEmail keeps the unique email column satisfied with an address that cannot receive mail
+func anonymizedEmail(userID uint) string {
+	return fmt.Sprintf("anonymized-%d@users.invalid", userID)
+}
+
+// replacements pairs each erased value with its placeholder
+func (e ErasedIdentity) replacements(userID uint) [][2]string {
+	var pairs [][2]string
+	if email := strings.TrimSpace(e.Email); email != "" {
+		pairs = append(pairs, [2]string{email, anonymizedEmail(userID)})
+	}
+	if name := strings.TrimSpace(e.Name); name != "" {
+		pairs = append(pairs, [2]string{name, AnonymizedName})
+	}
+	if studentID := strings.TrimSpace(e.StudentID); studentID != "" {
+		pairs = append(pairs, [2]string{studentID, ""})
+	}
+	return pairs
+}
+
+// jsonString encodes a value the way it appears in stored JSON, without HTML escaping
+func jsonString(value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// AnonymizeUser irreversibly erases a user's personal data. Their teams and advisees are handed
+// over by the cascade policy as for a deactivation, then the name, email, student ID and photo
+// are replaced with placeholders, sessions are revoked, notifications and data exports deleted,
+// and copies of the identity are scrubbed from the audit trail and their reviews. The account
+// row, memberships, proposals and reviews stay, so teams and archived projects remain intact.
+func (s *Service) AnonymizeUser(id uint, opts CascadeOptions, adminID uint, departmentID uint, meta audit.RequestMeta) (*DependencyReport, error) {
+	if id == adminID {
+		return nil, ErrAnonymizeSelf
+	}
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.DepartmentID != departmentID {
+		return nil, ErrAnonymizeOutsideScope
+	}
+	if user.Role == enums.RoleAdmin {
+		return nil, ErrAnonymizeAdmin
+	}
+	if user.AnonymizedAt != nil {
+		return nil, ErrAlreadyAnonymized
+	}
+	admin, err := s.repo.GetByID(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	report, err := s.buildReport(user, opts.AdvisorID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := report.planFor(opts.Policy)
+	if err != nil {
+		return report, err
+	}
+
+	// Archives on disk go first; the rows are deleted with the rest of the personal data
+	if export, err := s.repo.GetLatestDataExport(user.ID); err == nil && export != nil {
+		s.removeDataExport(export)
+	}
+
+	former := ErasedIdentity{Name: user.Name, Email: user.Email, StudentID: user.StudentID}
+	if err := s.repo.AnonymizeUser(user.ID, plan, former); err != nil {
+		return nil, err
+	}
+	s.revokeTokens(user.ID)
+
+	s.auditLogger.LogAction("user", user.ID, "user_anonymized", &admin.ID, string(admin.Role), admin.Email, nil,
+		map[string]interface{}{
+			"role":    user.Role,
+			"cascade": opts.Policy,
+		}, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+
+	return report, nil
+}
@@ -0,0 +1,281 @@
+package users
+
+import (
+	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// CascadePolicy decides what happens to the teams and proposals a user is responsible for
+// when their account is deactivated or deleted
+type CascadePolicy string
+
+const (
+	CascadeBlock    CascadePolicy = "block"    // refuse while leadership or advising work depends on the user
+	CascadeReassign CascadePolicy = "reassign" // hand leadership and advisees over, then proceed
+)
+
+// Dependency kinds reported before a user is removed
+const (
+	DependencyTeamLeader         = "team_leader"
+	DependencyTeamAdvisor        = "team_advisor"
+	DependencyProposalAdvisor    = "proposal_advisor"
+	DependencyPendingInvitations = "pending_invitations"
+	DependencyDelegations        = "delegations"
+)
+
+// activeAdvisingStatuses are the proposal states in which losing the advisor strands the team
+var activeAdvisingStatuses = []enums.ProposalStatus{
+	enums.ProposalStatusSubmitted,
+	enums.ProposalStatusUnderReview,
+	enums.ProposalStatusRevisionRequired,
+	enums.ProposalStatusAwaitingSecondReview,
+	enums.ProposalStatusApproved,
+}
+
+// CascadeOptions are the admin's choices for a deactivation or deletion
+type CascadeOptions struct {
+	Policy    CascadePolicy
+	AdvisorID *uint // replacement advisor; the least-loaded advisor in the department when nil
+}
+
+// Dependency is one thing that would be orphaned by removing the user
+type Dependency struct {
+	Kind       string `json:"kind"`
+	EntityID   uint   `json:"entity_id,omitempty"`
+	Name       string `json:"name"`
+	Count      int64  `json:"count,omitempty"`
+	Blocking   bool   `json:"blocking"`            // stops removal under the block policy
+	Resolvable bool   `json:"resolvable"`          // the reassign policy can handle it
+	Resolution string `json:"resolution"`          // what removal will do about it
+	TargetID   *uint  `json:"target_id,omitempty"` // new leader or advisor
+}
+
+// DependencyReport lists everything that depends on a user and how each item would be resolved
+type DependencyReport struct {
+	UserID       uint         `json:"user_id"`
+	Role         enums.Role   `json:"role"`
+	Blocking     bool         `json:"blocking"`     // removal is refused under the block policy
+	CanReassign  bool         `json:"can_reassign"` // removal succeeds under the reassign policy
+	Dependencies []Dependency `json:"dependencies"`
+	plan         CascadePlan
+}
+
+// CascadePlan is the hand-over ApplyCascade performs in one transaction
+type CascadePlan struct {
+	LeaderTransfers map[uint]uint // team ID -> new leader ID
+	AdvisorID       uint          // replacement advisor for TeamIDs and ProposalIDs
+	TeamIDs         []uint
+	ProposalIDs     []uint
+}
+
+// ParseCascadePolicy validates a policy name; empty means block
+func ParseCascadePolicy(value string) (CascadePolicy, error) {
+	switch CascadePolicy(value) {
+	case "", CascadeBlock:
+		return CascadeBlock, nil
+	case CascadeReassign:
+		return CascadeReassign, nil
+	}
+	return "", errors.New("invalid cascade policy")
+}
+
+// GetDependencyReport shows what removing the user would orphan and how reassignment would resolve it
+func (s *Service) GetDependencyReport(userID uint, advisorID *uint) (*DependencyReport, error) {
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return s.buildReport(user, advisorID)
+}
+
+func (s *Service) buildReport(user *domain.User, advisorID *uint) (*DependencyReport, error) {
+	report := &DependencyReport{
+		UserID:       user.ID,
+		Role:         user.Role,
+		Dependencies: []Dependency{},
+		plan:         CascadePlan{LeaderTransfers: map[uint]uint{}},
+	}
+
+	ledTeams, err := s.repo.GetLedTeams(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range ledTeams {
+		dep := Dependency{Kind: DependencyTeamLeader, EntityID: team.ID, Name: team.Name, Blocking: true}
+		candidates, err := s.repo.GetSuccessorCandidates(team.ID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			dep.Resolution = "no other active member can take over; reassign or disband the team manually"
+		} else {
+			successor := candidates[0]
+			dep.Resolvable = true
+			dep.TargetID = &successor.ID
+			dep.Resolution = fmt.Sprintf("leadership transfers to %s", successor.Name)
+			report.plan.LeaderTransfers[team.ID] = successor.ID
+		}
+		report.Dependencies = append(report.Dependencies, dep)
+	}
+
+	advisedTeams, err := s.repo.GetAdvisedTeams(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	advisedProposals, err := s.repo.GetAdvisedProposals(user.ID, activeAdvisingStatuses)
+	if err != nil {
+		return nil, err
+	}
+	if len(advisedTeams) > 0 || len(advisedProposals) > 0 {
+		replacement, reason := s.pickReplacementAdvisor(user, advisorID)
+		resolution := reason
+		if replacement != nil {
+			resolution = fmt.Sprintf("reassigned to %s", replacement.Name)
+			report.plan.AdvisorID = replacement.ID
+		}
+
+		for _, team := range advisedTeams {
+			report.plan.TeamIDs = append(report.plan.TeamIDs, team.ID)
+			report.Dependencies = append(report.Dependencies, advisorDependency(DependencyTeamAdvisor, team.ID, team.Name, replacement, resolution))
+		}
+		for _, proposal := range advisedProposals {
+			name := fmt.Sprintf("Proposal #%d (%s)", proposal.ID, proposal.Status)
+			if proposal.Team != nil {
+				name = fmt.Sprintf("%s - %s", name, proposal.Team.Name)
+			}
+			report.plan.ProposalIDs = append(report.plan.ProposalIDs, proposal.ID)
+			report.Dependencies = append(report.Dependencies, advisorDependency(DependencyProposalAdvisor, proposal.ID, name, replacement, resolution))
+		}
+	}
+
+	// Invitations and delegations are closed automatically under either policy
+	invitations, err := s.repo.CountPendingInvitations(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invitations > 0 {
+		report.Dependencies = append(report.Dependencies, Dependency{
+			Kind: DependencyPendingInvitations, Name: "Pending team invitations", Count: invitations,
+			Resolvable: true, Resolution: "expired",
+		})
+	}
+	delegations, err := s.repo.CountActiveDelegations(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if delegations > 0 {
+		report.Dependencies = append(report.Dependencies, Dependency{
+			Kind: DependencyDelegations, Name: "Active delegations", Count: delegations,
+			Resolvable: true, Resolution: "revoked",
+		})
+	}
+
+	report.CanReassign = true
+	for _, dep := range report.Dependencies {
+		report.Blocking = report.Blocking || dep.Blocking
+		report.CanReassign = report.CanReassign && dep.Resolvable
+	}
+	return report, nil
+}
+
+func advisorDependency(kind string, id uint, name string, replacement *domain.User, resolution string) Dependency {
+	dep := Dependency{Kind: kind, EntityID: id, Name: name, Blocking: true, Resolution: resolution}
+	if replacement != nil {
+		dep.Resolvable = true
+		dep.TargetID = &replacement.ID
+	}
+	return dep
+}
+
+// pickReplacementAdvisor validates the admin's choice, or picks the active advisor in the
+// user's department with the fewest assigned proposals. The string explains a nil result.
+func (s *Service) pickReplacementAdvisor(user *domain.User, advisorID *uint) (*domain.User, string) {
+	if advisorID != nil {
+		advisor, err := s.repo.GetByID(*advisorID)
+		if err != nil || advisor.ID == user.ID || advisor.Role != enums.RoleAdvisor ||
+			!advisor.IsActive || advisor.DepartmentID != user.DepartmentID {
+			return nil, "the chosen replacement must be another active advisor in the same department"
+		}
+		return advisor, ""
+	}
+
+	advisors, err := s.repo.GetAdvisorsByDepartment(user.DepartmentID)
+	if err != nil {
+		return nil, "could not load department advisors"
+	}
+	workload, err := s.repo.GetAdvisorWorkload(user.DepartmentID)
+	if err != nil {
+		return nil, "could not load advisor workload"
+	}
+
+	var candidates []domain.User
+	for _, advisor := range advisors {
+		if advisor.ID != user.ID && advisor.IsActive {
+			candidates = append(candidates, advisor)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, "no other active advisor in the department; assign one manually"
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if workload[candidates[i].ID] != workload[candidates[j].ID] {
+			return workload[candidates[i].ID] < workload[candidates[j].ID]
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	return &candidates[0], ""
+}
+
+// removeUser runs the cascade policy and then deactivates or deletes the account.
+// When the policy cannot proceed the report is returned with the error.
+func (s *Service) removeUser(id uint, opts CascadeOptions, remove bool) (*DependencyReport, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	report, err := s.buildReport(user, opts.AdvisorID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := report.planFor(opts.Policy)
+	if err != nil {
+		return report, err
+	}
+
+	if err := s.repo.ApplyCascade(id, plan, remove); err != nil {
+		return nil, err
+	}
+	s.revokeTokens(id)
+	return report, nil
+}
+
+// revokeTokens signs out a user who lost access; the account change already happened, so a
+// failure is logged rather than returned
+func (s *Service) revokeTokens(userID uint) {
+	if err := s.tokens.RevokeUserTokens(userID, auth.RevocationDeactivated, nil); err != nil {
+		log.Printf("failed to revoke tokens of user %d: %v", userID, err)
+	}
+}
+
+// planFor returns the hand-over to perform under the policy, or an error when it cannot proceed
+func (r *DependencyReport) planFor(policy CascadePolicy) (CascadePlan, error) {
+	switch policy {
+	case CascadeReassign:
+		if !r.CanReassign {
+			return CascadePlan{}, errors.New("user has dependencies that cannot be reassigned")
+		}
+		return r.plan, nil
+	default:
+		if r.Blocking {
+			return CascadePlan{}, errors.New("user has dependencies")
+		}
+		return CascadePlan{}, nil
+	}
+}
@@ -0,0 +1,151 @@
+package users
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/events"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+const (
+	// DashboardMaxAge is how old a materialized dashboard may be before a read recomputes it
+	DashboardMaxAge = 5 * time.Minute
+	// DashboardRefreshInterval is how often the refresh job runs; it refreshes dashboards
+	// before they reach DashboardMaxAge so reads rarely pay for the queries
+	DashboardRefreshInterval = time.Minute
+)
+
+// MissedDeadline is a revision request whose resubmission deadline passed while the team still owes a revision
+type MissedDeadline struct {
+	FeedbackID uint      `json:"feedback_id"`
+	ProposalID uint      `json:"proposal_id"`
+	TeamID     uint      `json:"team_id"`
+	TeamName   string    `json:"team_name"`
+	AdvisorID  uint      `json:"advisor_id"`
+	ResubmitBy time.Time `json:"resubmit_by"`
+	MissedAt   time.Time `json:"missed_at"`
+}
+
+// dashboardPayload holds the list parts of the dashboard that do not fit in columns
+type dashboardPayload struct {
+	RecentProposals []ProposalSummary `json:"recent_proposals"`
+	AdvisorWorkload []AdvisorWorkload `json:"advisor_workload"`
+	MissedDeadlines []MissedDeadline  `json:"missed_deadlines"`
+}
+
+// GetAdminDashboardStats serves the department dashboard from its materialized row,
+// recomputing it when it is missing, dirty, stale or forceRefresh is set
+func (s *Service) GetAdminDashboardStats(deptID uint, forceRefresh bool) (*AdminDashboardStats, error) {
+	if !forceRefresh {
+		stat, err := s.repo.GetDashboardStat(deptID)
+		if err == nil && !stat.Dirty && time.Since(stat.RefreshedAt) < DashboardMaxAge {
+			if stats, err := statsFromSnapshot(stat); err == nil {
+				return stats, nil
+			}
+		}
+	}
+	return s.RefreshDashboardStats(deptID)
+}
+
+// RefreshDashboardStats recomputes the department dashboard and stores it
+func (s *Service) RefreshDashboardStats(deptID uint) (*AdminDashboardStats, error) {
+	return s.refreshDashboard(deptID, nil)
+}
+
+// refreshDashboard recomputes and stores the dashboard, then pushes what changed to live
+// dashboards along with the events that caused it
+func (s *Service) refreshDashboard(deptID uint, reasons []string) (*AdminDashboardStats, error) {
+	stats, err := s.computeAdminDashboardStats(deptID)
+	if err != nil {
+		return nil, err
+	}
+	stats.RefreshedAt = time.Now()
+
+	payload, err := json.Marshal(dashboardPayload{
+		RecentProposals: stats.RecentProposals,
+		AdvisorWorkload: stats.AdvisorWorkload,
+		MissedDeadlines: stats.MissedDeadlines,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stat := &domain.DashboardStat{
+		DepartmentID:      deptID,
+		PendingCount:      stats.PendingCount,
+		UnderReviewCount:  stats.UnderReviewCount,
+		ApprovedCount:     stats.ApprovedCount,
+		TotalTeams:        stats.TotalTeams,
+		AvailableAdvisors: stats.AvailableAdvisors,
+		MissedDeadlines:   stats.MissedDeadlineCount,
+		Payload:           string(payload),
+		RefreshedAt:       stats.RefreshedAt,
+	}
+	if err := s.repo.SaveDashboardStat(stat); err != nil {
+		// The fresh numbers are still worth returning
+		log.Printf("failed to store dashboard stats for department %d: %v", deptID, err)
+	}
+	s.stream.publish(deptID, stats, reasons)
+	return stats, nil
+}
+
+// RefreshDashboards recomputes dirty and soon-to-be-stale dashboards; run periodically by the scheduler
+func (s *Service) RefreshDashboards() {
+	ids, err := s.repo.GetDashboardsToRefresh(time.Now().Add(-DashboardMaxAge + DashboardRefreshInterval))
+	if err != nil {
+		log.Printf("failed to list dashboards to refresh: %v", err)
+		return
+	}
+	for _, id := range ids {
+		if _, err := s.RefreshDashboardStats(id); err != nil {
+			log.Printf("failed to refresh dashboard for department %d: %v", id, err)
+		}
+	}
+}
+
+// RegisterSubscribers marks dashboards dirty when proposals or teams change or a revision deadline
+// is missed; dashboards being watched live are recomputed and pushed right away
+func (s *Service) RegisterSubscribers(bus *events.Bus) {
+	bus.Subscribe(s.markDashboardDirty,
+		events.TeamInvitationAccepted,
+		events.ProposalSubmitted,
+		events.ProposalAdvisorAssigned,
+		events.ProposalApproved,
+		events.ProposalRevisionRequest,
+		events.ProposalRejected,
+		events.RevisionDeadlineMissed,
+		events.CohortArchived,
+	)
+}
+
+func (s *Service) markDashboardDirty(e events.Event) {
+	if err := s.repo.MarkDashboardDirty(e.EntityType, e.EntityID); err != nil {
+		log.Printf("failed to mark dashboard dirty for %s: %v", e.Name, err)
+	}
+	if s.watchingDashboards() {
+		s.scheduleDashboardPush(e)
+	}
+}
+
+func statsFromSnapshot(stat *domain.DashboardStat) (*AdminDashboardStats, error) {
+	var payload dashboardPayload
+	if stat.Payload != "" {
+		if err := json.Unmarshal([]byte(stat.Payload), &payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AdminDashboardStats{
+		PendingCount:        stat.PendingCount,
+		UnderReviewCount:    stat.UnderReviewCount,
+		ApprovedCount:       stat.ApprovedCount,
+		TotalTeams:          stat.TotalTeams,
+		AvailableAdvisors:   stat.AvailableAdvisors,
+		RecentProposals:     payload.RecentProposals,
+		AdvisorWorkload:     payload.AdvisorWorkload,
+		MissedDeadlineCount: stat.MissedDeadlines,
+		MissedDeadlines:     payload.MissedDeadlines,
+		RefreshedAt:         stat.RefreshedAt,
+	}, nil
+}
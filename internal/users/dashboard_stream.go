@@ -0,0 +1,217 @@
+package users
+
+import (
+	"backend/pkg/events"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// DashboardPushDelay gathers the events of a burst, e.g. a cohort archive, into one recompute
+	DashboardPushDelay = 2 * time.Second
+	// DashboardStreamKeepAlive is how often an idle stream gets a comment so proxies keep it open
+	DashboardStreamKeepAlive = 25 * time.Second
+	dashboardStreamBuffer    = 8
+)
+
+// CountChange is a dashboard counter's new value and how much it moved
+type CountChange struct {
+	Value  int64 `json:"value"`
+	Change int64 `json:"change"`
+}
+
+// DashboardDelta is one change pushed to live dashboards: only the counters that moved, and whole
+// lists only when they changed. Keys match the fields of AdminDashboardStats.
+type DashboardDelta struct {
+	Reasons     []string               `json:"reasons,omitempty"` // events behind the change, e.g. proposal.submitted
+	Counts      map[string]CountChange `json:"counts,omitempty"`
+	Lists       map[string]interface{} `json:"lists,omitempty"` // recent_proposals, advisor_workload, missed_deadlines
+	RefreshedAt time.Time              `json:"refreshed_at"`
+}
+
+func (d *DashboardDelta) empty() bool {
+	return len(d.Counts) == 0 && len(d.Lists) == 0
+}
+
+// dashboardStream fans dashboard changes out to the department heads watching them. Domain events
+// for a watched department trigger a recompute after DashboardPushDelay; each recompute is
+// compared with the last stats sent and the difference is pushed. Streams live in this process.
+type dashboardStream struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan DashboardDelta]struct{}
+	last        map[uint]*AdminDashboardStats
+	pending     map[uint][]string // reasons waiting for the scheduled recompute
+}
+
+func newDashboardStream() *dashboardStream {
+	return &dashboardStream{
+		subscribers: make(map[uint]map[chan DashboardDelta]struct{}),
+		last:        make(map[uint]*AdminDashboardStats),
+		pending:     make(map[uint][]string),
+	}
+}
+
+// SubscribeDashboard streams the department's dashboard changes from the given stats onwards.
+// The channel is closed when the subscriber falls behind; call cancel when the client leaves.
+func (s *Service) SubscribeDashboard(deptID uint, current *AdminDashboardStats) (<-chan DashboardDelta, func()) {
+	ch := make(chan DashboardDelta, dashboardStreamBuffer)
+	st := s.stream
+
+	st.mu.Lock()
+	if st.subscribers[deptID] == nil {
+		st.subscribers[deptID] = make(map[chan DashboardDelta]struct{})
+	}
+	st.subscribers[deptID][ch] = struct{}{}
+	if last := st.last[deptID]; last == nil || last.RefreshedAt.Before(current.RefreshedAt) {
+		st.last[deptID] = current
+	}
+	st.mu.Unlock()
+
+	cancel := func() {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		if _, ok := st.subscribers[deptID][ch]; ok {
+			delete(st.subscribers[deptID], ch)
+			close(ch)
+		}
+		if len(st.subscribers[deptID]) == 0 {
+			delete(st.subscribers, deptID)
+			delete(st.last, deptID)
+		}
+	}
+	return ch, cancel
+}
+
+// watchingDashboards reports whether any dashboard stream is open
+func (s *Service) watchingDashboards() bool {
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	return len(s.stream.subscribers) > 0
+}
+
+// scheduleDashboardPush recomputes a watched department's dashboard shortly after an event
+func (s *Service) scheduleDashboardPush(e events.Event) {
+	deptID, err := s.repo.GetEntityDepartment(e.EntityType, e.EntityID)
+	if err != nil || deptID == 0 {
+		return
+	}
+
+	st := s.stream
+	st.mu.Lock()
+	if len(st.subscribers[deptID]) == 0 {
+		st.mu.Unlock()
+		return
+	}
+	_, scheduled := st.pending[deptID]
+	st.pending[deptID] = append(st.pending[deptID], string(e.Name))
+	st.mu.Unlock()
+	if scheduled {
+		return
+	}
+
+	time.AfterFunc(DashboardPushDelay, func() {
+		st.mu.Lock()
+		reasons := st.pending[deptID]
+		delete(st.pending, deptID)
+		st.mu.Unlock()
+
+		if _, err := s.refreshDashboard(deptID, reasons); err != nil {
+			log.Printf("failed to refresh dashboard for department %d: %v", deptID, err)
+		}
+	})
+}
+
+// publish sends the difference between the last stats sent and these to the department's streams
+func (st *dashboardStream) publish(deptID uint, stats *AdminDashboardStats, reasons []string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	subscribers := st.subscribers[deptID]
+	if len(subscribers) == 0 {
+		return
+	}
+	delta := diffDashboard(st.last[deptID], stats)
+	st.last[deptID] = stats
+	if delta.empty() {
+		return
+	}
+	delta.Reasons = uniqueReasons(reasons)
+
+	for ch := range subscribers {
+		select {
+		case ch <- delta:
+		default:
+			// A subscriber that cannot keep up is dropped; it reconnects and starts from a snapshot
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+	if len(subscribers) == 0 {
+		delete(st.subscribers, deptID)
+		delete(st.last, deptID)
+	}
+}
+
+func diffDashboard(old, stats *AdminDashboardStats) DashboardDelta {
+	delta := DashboardDelta{
+		Counts:      make(map[string]CountChange),
+		Lists:       make(map[string]interface{}),
+		RefreshedAt: stats.RefreshedAt,
+	}
+	if old == nil {
+		old = &AdminDashboardStats{}
+	}
+
+	counts := []struct {
+		key      string
+		old, new int64
+	}{
+		{"pending_assignment", old.PendingCount, stats.PendingCount},
+		{"under_review", old.UnderReviewCount, stats.UnderReviewCount},
+		{"approved", old.ApprovedCount, stats.ApprovedCount},
+		{"total_teams", old.TotalTeams, stats.TotalTeams},
+		{"available_advisors", old.AvailableAdvisors, stats.AvailableAdvisors},
+		{"missed_revision_deadlines", old.MissedDeadlineCount, stats.MissedDeadlineCount},
+	}
+	for _, c := range counts {
+		if c.old != c.new {
+			delta.Counts[c.key] = CountChange{Value: c.new, Change: c.new - c.old}
+		}
+	}
+
+	lists := []struct {
+		key      string
+		old, new interface{}
+	}{
+		{"recent_proposals", old.RecentProposals, stats.RecentProposals},
+		{"advisor_workload", old.AdvisorWorkload, stats.AdvisorWorkload},
+		{"missed_deadlines", old.MissedDeadlines, stats.MissedDeadlines},
+	}
+	for _, l := range lists {
+		if !sameJSON(l.old, l.new) {
+			delta.Lists[l.key] = l.new
+		}
+	}
+	return delta
+}
+
+// sameJSON compares lists by their JSON form, as snapshots read back from storage lose time zones
+func sameJSON(a, b interface{}) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(x) == string(y)
+}
+
+func uniqueReasons(reasons []string) []string {
+	seen := make(map[string]bool, len(reasons))
+	var result []string
+	for _, r := range reasons {
+		if !seen[r] {
+			seen[r] = true
+			result = append(result, r)
+		}
+	}
+	return result
+}
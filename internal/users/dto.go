@@ -0,0 +1,129 @@
+package users
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"time"
+)
+
+// UserResponse is the API view of a user account. Credentials, lockout state and preloaded
+// relations are never part of it.
+type UserResponse struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Role           enums.Role `json:"role"`
+	StudentID      string     `json:"student_id,omitempty"`
+	UniversityID   uint       `json:"university_id"`
+	DepartmentID   uint       `json:"department_id"`
+	ProfilePhoto   string     `json:"profile_photo,omitempty"`
+	IsActive       bool       `json:"is_active"`
+	EmailVerified  bool       `json:"email_verified"`
+	PresenceHidden bool       `json:"presence_hidden"`
+	LastLoginAt    *time.Time `json:"last_login_at,omitempty"`
+	AnonymizedAt   *time.Time `json:"anonymized_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// UserSummary identifies a user inside another resource, e.g. a team member or a proposal's advisor
+type UserSummary struct {
+	ID           uint       `json:"id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Role         enums.Role `json:"role"`
+	ProfilePhoto string     `json:"profile_photo,omitempty"`
+}
+
+// PeerMatch is a student found by the invitation search. CurrentTeam is the team they are in while
+// it is still being formed, so the leader can tell before inviting them.
+type PeerMatch struct {
+	UserSummary
+	StudentID   string `json:"student_id,omitempty"`
+	CurrentTeam string `json:"current_team,omitempty"`
+}
+
+// ProposalSummary is a proposal as listed on the admin dashboard and in advisor workloads
+type ProposalSummary struct {
+	ID           uint                 `json:"id"`
+	Title        string               `json:"title"`
+	Status       enums.ProposalStatus `json:"status"`
+	TeamID       *uint                `json:"team_id"`
+	TeamName     string               `json:"team_name,omitempty"`
+	AdvisorID    *uint                `json:"advisor_id"`
+	AcademicYear string               `json:"academic_year"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+func NewUserResponse(u *domain.User) UserResponse {
+	return UserResponse{
+		ID:             u.ID,
+		Name:           u.Name,
+		Email:          u.Email,
+		Role:           u.Role,
+		StudentID:      u.StudentID,
+		UniversityID:   u.UniversityID,
+		DepartmentID:   u.DepartmentID,
+		ProfilePhoto:   u.ProfilePhoto,
+		IsActive:       u.IsActive,
+		EmailVerified:  u.EmailVerified,
+		PresenceHidden: u.PresenceHidden,
+		LastLoginAt:    u.LastLoginAt,
+		AnonymizedAt:   u.AnonymizedAt,
+		CreatedAt:      u.CreatedAt,
+	}
+}
+
+func NewUserResponses(users []domain.User) []UserResponse {
+	result := make([]UserResponse, 0, len(users))
+	for i := range users {
+		result = append(result, NewUserResponse(&users[i]))
+	}
+	return result
+}
+
+// NewUserSummary returns nil when the user was not loaded, so unloaded relations are omitted
+func NewUserSummary(u *domain.User) *UserSummary {
+	if u == nil || u.ID == 0 {
+		return nil
+	}
+	return &UserSummary{
+		ID:           u.ID,
+		Name:         u.Name,
+		Email:        u.Email,
+		Role:         u.Role,
+		ProfilePhoto: u.ProfilePhoto,
+	}
+}
+
+func NewUserSummaries(users []domain.User) []UserSummary {
+	result := make([]UserSummary, 0, len(users))
+	for i := range users {
+		if summary := NewUserSummary(&users[i]); summary != nil {
+			result = append(result, *summary)
+		}
+	}
+	return result
+}
+
+// newProposalSummaries takes the title from the first loaded version
+func newProposalSummaries(proposals []domain.Proposal) []ProposalSummary {
+	result := make([]ProposalSummary, 0, len(proposals))
+	for _, p := range proposals {
+		summary := ProposalSummary{
+			ID:           p.ID,
+			Status:       p.Status,
+			TeamID:       p.TeamID,
+			AdvisorID:    p.AdvisorID,
+			AcademicYear: p.AcademicYear,
+			CreatedAt:    p.CreatedAt,
+		}
+		if len(p.Versions) > 0 {
+			summary.Title = p.Versions[0].Title
+		}
+		if p.Team != nil {
+			summary.TeamName = p.Team.Name
+		}
+		result = append(result, summary)
+	}
+	return result
+}
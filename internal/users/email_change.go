@@ -0,0 +1,190 @@
+package users
+
+import (
+	"backend/internal/auth"
+	"backend/internal/domain"
+	"backend/pkg/audit"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// EmailChangeTTL is how long the confirmation link sent to the new address stays valid
+const EmailChangeTTL = 24 * time.Hour
+
+// EmailConfirmPath is the web app page the confirmation link opens; it posts the token to
+// POST /users/confirm-email
+const EmailConfirmPath = "/confirm-email"
+
+var (
+	ErrEmailChangeUnavailable = errors.New("email changes need outgoing email, which is not configured")
+	ErrWrongPassword          = errors.New("current password is incorrect")
+	ErrSameEmail              = errors.New("the new address is your current email")
+	ErrEmailTaken             = errors.New("email already exists")
+	ErrEmailChangeInvalid     = errors.New("the confirmation link is invalid or has already been used")
+	ErrEmailChangeExpired     = errors.New("the confirmation link has expired; request the change again")
+)
+
+// ChangeEmailRequest asks to move the account to another address; the password guards against
+// an unattended session being used to take the account over
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email" example:"abebe.kebede@astu.edu.et"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// ConfirmEmailRequest carries the token of the confirmation link
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// PendingEmailChange is returned once the confirmation link is on its way
+type PendingEmailChange struct {
+	NewEmail  string    `json:"new_email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// normalizeEmail compares addresses the way people type them
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// hashEmailToken is what is stored of a confirmation token, so a leaked table cannot confirm changes
+func hashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestEmailChange emails a confirmation link to the new address. The account keeps its current
+// email, for signing in and notifications, until the link is followed; requesting again replaces
+// an earlier pending change. The current address is told that a change was requested.
+func (s *Service) RequestEmailChange(userID uint, req ChangeEmailRequest, meta audit.RequestMeta) (*PendingEmailChange, error) {
+	if !s.mailer.Enabled() {
+		return nil, ErrEmailChangeUnavailable
+	}
+	user, err := s.repo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	newEmail := normalizeEmail(req.NewEmail)
+	if newEmail == normalizeEmail(user.Email) {
+		return nil, ErrSameEmail
+	}
+	taken, err := s.repo.EmailTaken(newEmail, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	change := &domain.EmailChange{
+		UserID:    user.ID,
+		OldEmail:  user.Email,
+		NewEmail:  newEmail,
+		TokenHash: hashEmailToken(token),
+		ExpiresAt: time.Now().Add(EmailChangeTTL),
+	}
+	if err := s.repo.CreateEmailChange(change); err != nil {
+		return nil, err
+	}
+
+	link := strings.TrimRight(s.appURL, "/") + EmailConfirmPath + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hello %s,\n\nConfirm %s as the new email address of your account by opening this link within %d hours:\n\n%s\n\nUntil then you keep signing in with %s. If you did not ask for this, ignore this message.\n",
+		user.Name, newEmail, int(EmailChangeTTL.Hours()), link, user.Email)
+	if err := s.mailer.Send(newEmail, "Confirm your new email address", body); err != nil {
+		// Without the link the change cannot be confirmed, so it is not kept pending
+		if delErr := s.repo.DeleteEmailChange(change.ID); delErr != nil {
+			log.Printf("failed to drop email change %d: %v", change.ID, delErr)
+		}
+		return nil, fmt.Errorf("failed to send the confirmation email: %w", err)
+	}
+
+	notice := fmt.Sprintf("Hello %s,\n\nA change of your account's email address to %s was requested. It takes effect once confirmed from the new address. If this was not you, change your password.\n",
+		user.Name, newEmail)
+	if err := s.mailer.Send(user.Email, "Email change requested", notice); err != nil {
+		log.Printf("failed to notify user %d of the email change request: %v", user.ID, err)
+	}
+
+	s.auditEmailChange(user, "email_change_requested",
+		map[string]interface{}{"email": user.Email},
+		map[string]interface{}{"new_email": newEmail, "expires_at": change.ExpiresAt}, meta)
+
+	return &PendingEmailChange{NewEmail: newEmail, ExpiresAt: change.ExpiresAt}, nil
+}
+
+// ConfirmEmailChange switches the account to the new address of a confirmation link. The new
+// address counts as verified, every session is signed out since tokens carry the old address, and
+// the old address is told about the change.
+func (s *Service) ConfirmEmailChange(token string, meta audit.RequestMeta) (*domain.User, error) {
+	change, err := s.repo.GetEmailChangeByTokenHash(hashEmailToken(strings.TrimSpace(token)))
+	if err != nil || change.ConfirmedAt != nil {
+		return nil, ErrEmailChangeInvalid
+	}
+	if time.Now().After(change.ExpiresAt) {
+		return nil, ErrEmailChangeExpired
+	}
+
+	user, err := s.repo.GetByID(change.UserID)
+	if err != nil || user.AnonymizedAt != nil {
+		return nil, ErrEmailChangeInvalid
+	}
+	// The account moved to another address since the link was sent
+	if user.Email != change.OldEmail {
+		return nil, ErrEmailChangeInvalid
+	}
+	// Another account may have taken the address in the meantime
+	taken, err := s.repo.EmailTaken(change.NewEmail, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+
+	if err := s.repo.ConfirmEmailChange(change, time.Now()); err != nil {
+		return nil, err
+	}
+
+	s.auditEmailChange(user, "email_changed",
+		map[string]interface{}{"email": change.OldEmail},
+		map[string]interface{}{"email": change.NewEmail}, meta)
+
+	if err := s.tokens.RevokeUserTokens(user.ID, auth.RevocationEmailChanged, &user.ID); err != nil {
+		log.Printf("failed to revoke tokens of user %d after an email change: %v", user.ID, err)
+	}
+
+	notice := fmt.Sprintf("Hello %s,\n\nThe email address of your account was changed to %s. This address no longer signs in. If this was not you, contact your department administrator.\n",
+		user.Name, change.NewEmail)
+	if err := s.mailer.Send(change.OldEmail, "Your email address was changed", notice); err != nil {
+		log.Printf("failed to notify user %d of the email change: %v", user.ID, err)
+	}
+
+	return s.repo.GetByID(user.ID)
+}
+
+// auditEmailChange records a step of the change as done by the user themselves
+func (s *Service) auditEmailChange(user *domain.User, action string, oldState, newState map[string]interface{}, meta audit.RequestMeta) {
+	err := s.auditLogger.LogAction("user", user.ID, action, &user.ID, string(user.Role), user.Email,
+		oldState, newState, meta.IPAddress, meta.UserAgent, meta.RequestID, "")
+	if err != nil {
+		log.Printf("failed to audit %s of user %d: %v", action, user.ID, err)
+	}
+}
@@ -0,0 +1,406 @@
+package users
+
+import (
+	"archive/zip"
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"backend/pkg/events"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DataExportDir holds generated exports. It is not served as a static directory; archives are
+	// only downloaded through their owner's export endpoint.
+	DataExportDir = "./exports"
+	// DataExportTTL is how long a generated export stays downloadable
+	DataExportTTL = 7 * 24 * time.Hour
+	// dataExportStaleAfter is when an unfinished export is assumed lost, e.g. to a restart
+	dataExportStaleAfter = 15 * time.Minute
+)
+
+var ErrDataExportNotReady = errors.New("the export is not ready yet")
+
+// PersonalData is everything stored about a user, as loaded for an export
+type PersonalData struct {
+	Profile       domain.User
+	Memberships   []domain.TeamMember
+	Teams         []domain.Team
+	Proposals     []domain.Proposal
+	Feedback      []domain.Feedback
+	Reviews       []domain.ProjectReview
+	Notifications []domain.Notification
+	AuditEvents   []domain.AuditLog
+}
+
+// dataExportSections are the files of an export archive, in the order they are written
+var dataExportSections = []string{"profile", "team_memberships", "proposals", "feedback", "reviews", "notifications", "audit_events"}
+
+type exportMembership struct {
+	TeamID         uint                 `json:"team_id"`
+	TeamName       string               `json:"team_name"`
+	AcademicYear   string               `json:"academic_year"`
+	Role           string               `json:"role"`
+	FunctionalRole enums.FunctionalRole `json:"functional_role,omitempty"`
+	Skills         []string             `json:"skills"`
+	JoinedTeamAt   time.Time            `json:"team_created_at"`
+}
+
+type exportProposal struct {
+	ID           uint                 `json:"id"`
+	TeamID       *uint                `json:"team_id"`
+	AdvisorID    *uint                `json:"advisor_id"`
+	Status       enums.ProposalStatus `json:"status"`
+	AcademicYear string               `json:"academic_year"`
+	CreatedBy    uint                 `json:"created_by"`
+	CreatedAt    time.Time            `json:"created_at"`
+	Versions     []exportVersion      `json:"versions"`
+}
+
+type exportVersion struct {
+	VersionNumber    int       `json:"version_number"`
+	Title            string    `json:"title"`
+	Abstract         string    `json:"abstract"`
+	ProblemStatement string    `json:"problem_statement"`
+	Objectives       string    `json:"objectives"`
+	Methodology      string    `json:"methodology"`
+	ExpectedTimeline string    `json:"expected_timeline"`
+	ExpectedOutcomes string    `json:"expected_outcomes"`
+	FileURL          *string   `json:"file_url"`
+	CreatedBy        uint      `json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type exportFeedback struct {
+	ID         uint                    `json:"id"`
+	ProposalID uint                    `json:"proposal_id"`
+	VersionID  uint                    `json:"proposal_version_id"`
+	ReviewerID uint                    `json:"reviewer_id"`
+	Decision   domain.FeedbackDecision `json:"decision"`
+	Comment    string                  `json:"comment"`
+	ResubmitBy *time.Time              `json:"resubmit_by,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+type exportReview struct {
+	ProjectID uint      `json:"project_id"`
+	Rate      int       `json:"rate"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportNotification struct {
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	ActionURL string     `json:"action_url"`
+	IsRead    bool       `json:"is_read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type exportAuditEvent struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   uint      `json:"entity_id"`
+	Action     string    `json:"action"`
+	ActorID    *uint     `json:"actor_id"`
+	ActorRole  string    `json:"actor_role"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// RequestDataExport returns the user's current export, starting a new one in the background when
+// there is none, the last one expired or failed, or refresh is set. Only the latest export is kept.
+func (s *Service) RequestDataExport(userID uint, refresh bool) (*domain.DataExport, error) {
+	latest, err := s.repo.GetLatestDataExport(userID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		switch latest.Status {
+		case enums.DataExportStatusQueued, enums.DataExportStatusRunning:
+			if time.Since(latest.CreatedAt) < dataExportStaleAfter {
+				return latest, nil
+			}
+		case enums.DataExportStatusCompleted:
+			if !refresh && latest.ExpiresAt != nil && latest.ExpiresAt.After(time.Now()) {
+				return latest, nil
+			}
+		}
+		s.removeDataExport(latest)
+	}
+
+	export := &domain.DataExport{UserID: userID, Status: enums.DataExportStatusQueued}
+	if err := s.repo.CreateDataExport(export); err != nil {
+		return nil, err
+	}
+	go s.generateDataExport(export.ID)
+	return export, nil
+}
+
+// DataExportJSON combines the sections of a completed export into one JSON document
+func (s *Service) DataExportJSON(export *domain.DataExport) ([]byte, error) {
+	if export.Status != enums.DataExportStatusCompleted || export.FilePath == "" {
+		return nil, ErrDataExportNotReady
+	}
+	archive, err := zip.OpenReader(export.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	document := make(map[string]json.RawMessage, len(dataExportSections)+1)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		document[f.Name[:len(f.Name)-len(filepath.Ext(f.Name))]] = content
+	}
+	exportedAt, _ := json.Marshal(export.CompletedAt)
+	document["exported_at"] = exportedAt
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// CleanupDataExports deletes expired exports and their archives; run periodically by the scheduler
+func (s *Service) CleanupDataExports() {
+	expired, err := s.repo.GetExpiredDataExports(time.Now())
+	if err != nil {
+		log.Printf("failed to list expired data exports: %v", err)
+		return
+	}
+	for i := range expired {
+		s.removeDataExport(&expired[i])
+	}
+	if len(expired) > 0 {
+		log.Printf("Removed %d expired data export(s)", len(expired))
+	}
+}
+
+func (s *Service) removeDataExport(export *domain.DataExport) {
+	if export.FilePath != "" {
+		if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("failed to remove data export %d: %v", export.ID, err)
+		}
+	}
+	if err := s.repo.DeleteDataExport(export.ID); err != nil {
+		log.Printf("failed to delete data export %d: %v", export.ID, err)
+	}
+}
+
+func (s *Service) generateDataExport(id uint) {
+	export, err := s.repo.GetDataExport(id)
+	if err != nil {
+		log.Printf("data export %d disappeared before it ran: %v", id, err)
+		return
+	}
+	started := time.Now()
+	export.Status = enums.DataExportStatusRunning
+	export.StartedAt = &started
+	if err := s.repo.UpdateDataExport(export); err != nil {
+		log.Printf("failed to start data export %d: %v", id, err)
+		return
+	}
+
+	path, size, err := s.writeDataExport(export)
+	finished := time.Now()
+	export.CompletedAt = &finished
+	if err != nil {
+		log.Printf("data export %d for user %d failed: %v", export.ID, export.UserID, err)
+		export.Status = enums.DataExportStatusFailed
+		export.Error = err.Error()
+		if err := s.repo.UpdateDataExport(export); err != nil {
+			log.Printf("failed to save data export %d: %v", id, err)
+		}
+		return
+	}
+
+	expires := finished.Add(DataExportTTL)
+	export.Status = enums.DataExportStatusCompleted
+	export.FilePath = path
+	export.FileSizeBytes = size
+	export.ExpiresAt = &expires
+	if err := s.repo.UpdateDataExport(export); err != nil {
+		log.Printf("failed to save data export %d: %v", id, err)
+		_ = os.Remove(path)
+		return
+	}
+
+	s.bus.Publish(events.Event{
+		Name:       events.DataExportReady,
+		EntityType: "data_export",
+		EntityID:   export.ID,
+		ActorID:    export.UserID,
+		UserIDs:    []uint{export.UserID},
+		Data: map[string]interface{}{
+			"expires_on": expires.Format("2 January 2006"),
+		},
+	})
+}
+
+// writeDataExport writes the archive, one JSON file per section, and returns its path and size
+func (s *Service) writeDataExport(export *domain.DataExport) (string, int64, error) {
+	data, err := s.repo.GetPersonalData(export.UserID)
+	if err != nil {
+		return "", 0, err
+	}
+	sections := personalDataSections(data)
+
+	dir := filepath.Join(DataExportDir, fmt.Sprint(export.UserID))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("export_%d.zip", export.ID))
+	tmp, err := os.CreateTemp(dir, "export_*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	archive := zip.NewWriter(tmp)
+	for _, name := range dataExportSections {
+		w, err := archive.Create(name + ".json")
+		if err != nil {
+			tmp.Close()
+			return "", 0, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(sections[name]); err != nil {
+			tmp.Close()
+			return "", 0, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+func personalDataSections(data *PersonalData) map[string]interface{} {
+	teams := make(map[uint]domain.Team, len(data.Teams))
+	for _, t := range data.Teams {
+		teams[t.ID] = t
+	}
+	memberships := make([]exportMembership, 0, len(data.Memberships))
+	for _, m := range data.Memberships {
+		team := teams[m.TeamID]
+		memberships = append(memberships, exportMembership{
+			TeamID:         m.TeamID,
+			TeamName:       team.Name,
+			AcademicYear:   team.AcademicYear,
+			Role:           m.Role,
+			FunctionalRole: m.FunctionalRole,
+			Skills:         m.Skills,
+			JoinedTeamAt:   team.CreatedAt,
+		})
+	}
+
+	proposals := make([]exportProposal, 0, len(data.Proposals))
+	for _, p := range data.Proposals {
+		proposal := exportProposal{
+			ID:           p.ID,
+			TeamID:       p.TeamID,
+			AdvisorID:    p.AdvisorID,
+			Status:       p.Status,
+			AcademicYear: p.AcademicYear,
+			CreatedBy:    p.CreatedBy,
+			CreatedAt:    p.CreatedAt,
+			Versions:     make([]exportVersion, 0, len(p.Versions)),
+		}
+		for _, v := range p.Versions {
+			proposal.Versions = append(proposal.Versions, exportVersion{
+				VersionNumber:    v.VersionNumber,
+				Title:            v.Title,
+				Abstract:         v.Abstract,
+				ProblemStatement: v.ProblemStatement,
+				Objectives:       v.Objectives,
+				Methodology:      v.Methodology,
+				ExpectedTimeline: v.ExpectedTimeline,
+				ExpectedOutcomes: v.ExpectedOutcomes,
+				FileURL:          v.FileURL,
+				CreatedBy:        v.CreatedBy,
+				CreatedAt:        v.CreatedAt,
+			})
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	feedback := make([]exportFeedback, 0, len(data.Feedback))
+	for _, f := range data.Feedback {
+		feedback = append(feedback, exportFeedback{
+			ID:         f.ID,
+			ProposalID: f.ProposalID,
+			VersionID:  f.ProposalVersionID,
+			ReviewerID: f.ReviewerID,
+			Decision:   f.Decision,
+			Comment:    f.Comment,
+			ResubmitBy: f.ResubmitBy,
+			CreatedAt:  f.CreatedAt,
+		})
+	}
+
+	reviews := make([]exportReview, 0, len(data.Reviews))
+	for _, r := range data.Reviews {
+		reviews = append(reviews, exportReview{ProjectID: r.ProjectID, Rate: r.Rate, Comment: r.Comment, CreatedAt: r.CreatedAt})
+	}
+
+	notifications := make([]exportNotification, 0, len(data.Notifications))
+	for _, n := range data.Notifications {
+		notifications = append(notifications, exportNotification{
+			Title:     n.Title,
+			Message:   n.Message,
+			ActionURL: n.ActionURL,
+			IsRead:    n.IsRead,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
+	auditEvents := make([]exportAuditEvent, 0, len(data.AuditEvents))
+	for _, a := range data.AuditEvents {
+		auditEvents = append(auditEvents, exportAuditEvent{
+			EntityType: a.EntityType,
+			EntityID:   a.EntityID,
+			Action:     a.Action,
+			ActorID:    a.ActorID,
+			ActorRole:  a.ActorRole,
+			IPAddress:  a.IPAddress,
+			UserAgent:  a.UserAgent,
+			Timestamp:  a.Timestamp,
+		})
+	}
+
+	return map[string]interface{}{
+		"profile":          data.Profile,
+		"team_memberships": memberships,
+		"proposals":        proposals,
+		"feedback":         feedback,
+		"reviews":          reviews,
+		"notifications":    notifications,
+		"audit_events":     auditEvents,
+	}
+}
@@ -1,9 +1,15 @@
 package users
 
 import (
+	"backend/pkg/audit"
+	"backend/pkg/enums"
 	"backend/pkg/response"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
     "backend/internal/auth" // Ensure this is imported for TokenClaims
 
 	"github.com/gin-gonic/gin"
@@ -25,7 +31,7 @@ func NewHandler(s *Service) *Handler {
 // @Produce json
 // @Security BearerAuth
 // @Param teacher body CreateTeacherRequest true "Teacher registration details"
-// @Success 201 {object} response.Response{data=domain.User}
+// @Success 201 {object} response.Response{data=UserResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 409 {object} response.ErrorResponse
@@ -48,7 +54,7 @@ func (h *Handler) CreateTeacher(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusCreated, "Teacher created successfully", user)
+	response.JSON(c, http.StatusCreated, "Teacher created successfully", NewUserResponse(user))
 }
 
 // CreateStudent godoc
@@ -59,7 +65,7 @@ func (h *Handler) CreateTeacher(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param student body CreateStudentRequest true "Student registration details"
-// @Success 201 {object} response.Response{data=domain.User}
+// @Success 201 {object} response.Response{data=UserResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 409 {object} response.ErrorResponse
@@ -82,7 +88,7 @@ func (h *Handler) CreateStudent(c *gin.Context) {
 		return
 	}
 
-	response.JSON(c, http.StatusCreated, "Student created successfully", user)
+	response.JSON(c, http.StatusCreated, "Student created successfully", NewUserResponse(user))
 }
 
 // GetUsers godoc
@@ -95,7 +101,7 @@ func (h *Handler) CreateStudent(c *gin.Context) {
 // @Param department_id query int false "Filter by department ID"
 // @Param university_id query int false "Filter by university ID"
 // @Param is_active query bool false "Filter by active status"
-// @Success 200 {object} response.Response{data=[]domain.User}
+// @Success 200 {object} response.Response{data=[]UserResponse}
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /admin/users [get]
@@ -137,7 +143,7 @@ func (h *Handler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, users)
+	response.Success(c, NewUserResponses(users))
 }
 
 // GetUser godoc
@@ -147,7 +153,7 @@ func (h *Handler) GetUsers(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} response.Response{data=domain.User}
+// @Success 200 {object} response.Response{data=UserResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
@@ -165,22 +171,25 @@ func (h *Handler) GetUser(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, user)
+	response.Success(c, NewUserResponse(user))
 }
 
 // UpdateUserStatus godoc
 // @Summary Activate or deactivate user
-// @Description Admin controls user account activation status
+// @Description Admin controls user account activation status. Deactivating a team leader or advisor is refused with a dependency report (409) unless cascade=reassign, which transfers leadership to the longest-standing member and advisees to advisor_id or the least-loaded department advisor.
 // @Tags Admin - Users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "User ID"
 // @Param status body UpdateUserStatusRequest true "User status"
-// @Success 200 {object} response.Response
+// @Param cascade query string false "Cascade policy: block (default) or reassign"
+// @Param advisor_id query int false "Replacement advisor for reassign"
+// @Success 200 {object} response.Response{data=DependencyReport}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse{errors=DependencyReport}
 // @Failure 500 {object} response.ErrorResponse
 // @Router /admin/users/{id}/status [patch]
 func (h *Handler) UpdateUserStatus(c *gin.Context) {
@@ -196,13 +205,18 @@ func (h *Handler) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
-	err = h.service.UpdateUserStatus(uint(id), req.IsActive)
+	opts, ok := cascadeOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.UpdateUserStatus(uint(id), req.IsActive, opts)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to update user status", err.Error())
+		respondCascadeError(c, "Failed to update user status", report, err)
 		return
 	}
 
-	response.JSON(c, http.StatusOK, "User status updated successfully", nil)
+	response.JSON(c, http.StatusOK, "User status updated successfully", report)
 }
 
 // AssignDepartment godoc
@@ -244,15 +258,18 @@ func (h *Handler) AssignDepartment(c *gin.Context) {
 
 // DeleteUser godoc
 // @Summary Delete user
-// @Description Admin deletes a user account (use with caution)
+// @Description Admin deletes a user account (use with caution). Follows the same cascade policy as deactivation.
 // @Tags Admin - Users
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} response.Response
+// @Param cascade query string false "Cascade policy: block (default) or reassign"
+// @Param advisor_id query int false "Replacement advisor for reassign"
+// @Success 200 {object} response.Response{data=DependencyReport}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse{errors=DependencyReport}
 // @Failure 500 {object} response.ErrorResponse
 // @Router /admin/users/{id} [delete]
 func (h *Handler) DeleteUser(c *gin.Context) {
@@ -262,13 +279,188 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteUser(uint(id))
+	opts, ok := cascadeOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.DeleteUser(uint(id), opts)
+	if err != nil {
+		respondCascadeError(c, "Failed to delete user", report, err)
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "User deleted successfully", report)
+}
+
+// GetUserDependencies godoc
+// @Summary Get a user's dependency report
+// @Description Lists the teams the user leads or advises, the active proposals they advise, and open invitations and delegations, with how the reassign cascade policy would resolve each
+// @Tags Admin - Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param advisor_id query int false "Replacement advisor to preview"
+// @Success 200 {object} response.Response{data=DependencyReport}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /admin/users/{id}/dependencies [get]
+func (h *Handler) GetUserDependencies(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	opts, ok := cascadeOptions(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetDependencyReport(uint(id), opts.AdvisorID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			response.Error(c, http.StatusNotFound, "User not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to build dependency report", err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// MergeUsers godoc
+// @Summary Merge a duplicate student account
+// @Description Moves team memberships, authored proposals, project reviews, invitations and notifications from a student's duplicate account to their primary one in one transaction, then deactivates the duplicate and revokes its sessions. Refused with 409 when the two accounts are in different active teams of the same cohort.
+// @Tags Admin - Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MergeUsersRequest true "Primary and duplicate account"
+// @Success 200 {object} response.Response{data=MergeResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /admin/users/merge [post]
+func (h *Handler) MergeUsers(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	var req MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.service.MergeUsers(req, userClaims.DepartmentID)
+	if err != nil {
+		switch {
+		case err.Error() == "user not found":
+			response.Error(c, http.StatusNotFound, "User not found", err.Error())
+		case errors.Is(err, ErrMergeOutsideScope):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrMergeTeamConflict):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, ErrMergeSameUser), errors.Is(err, ErrMergeNotStudent), errors.Is(err, ErrMergeInactive):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to merge accounts", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Accounts merged successfully", result)
+}
+
+// AnonymizeUser godoc
+// @Summary Anonymize a user (right to erasure)
+// @Description Irreversibly replaces the user's name, email, student ID and photo with placeholders, revokes their sessions, deletes their notifications and data exports, and scrubs their identity from audit logs and their project reviews. Teams, proposals and projects keep their history. Teams the user leads or advises are handed over by the cascade policy, as for a deactivation; the account cannot be reactivated afterwards.
+// @Tags Admin - Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param cascade query string false "Cascade policy: block (default) or reassign"
+// @Param advisor_id query int false "Replacement advisor for reassign"
+// @Success 200 {object} response.Response{data=DependencyReport}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse{errors=DependencyReport}
+// @Router /admin/users/{id}/anonymize [post]
+func (h *Handler) AnonymizeUser(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	opts, ok := cascadeOptions(c)
+	if !ok {
+		return
+	}
+
+	meta := audit.NewRequestMeta(c)
+	report, err := h.service.AnonymizeUser(uint(id), opts, userClaims.UserID, userClaims.DepartmentID, meta)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to delete user", err.Error())
+		switch {
+		case errors.Is(err, ErrAnonymizeOutsideScope):
+			response.Error(c, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, ErrAnonymizeSelf), errors.Is(err, ErrAnonymizeAdmin):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		default:
+			respondCascadeError(c, "Failed to anonymize user", report, err)
+		}
 		return
 	}
 
-	response.JSON(c, http.StatusOK, "User deleted successfully", nil)
+	response.JSON(c, http.StatusOK, "User anonymized successfully", report)
+}
+
+// cascadeOptions reads the cascade policy and replacement advisor from the query string
+func cascadeOptions(c *gin.Context) (CascadeOptions, bool) {
+	policy, err := ParseCascadePolicy(c.Query("cascade"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error(), "use block or reassign")
+		return CascadeOptions{}, false
+	}
+
+	opts := CascadeOptions{Policy: policy}
+	if raw := c.Query("advisor_id"); raw != "" {
+		advisorID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid advisor ID", err.Error())
+			return CascadeOptions{}, false
+		}
+		id := uint(advisorID)
+		opts.AdvisorID = &id
+	}
+	return opts, true
+}
+
+func respondCascadeError(c *gin.Context, message string, report *DependencyReport, err error) {
+	switch {
+	case err.Error() == "user not found":
+		response.Error(c, http.StatusNotFound, "User not found", err.Error())
+	case errors.Is(err, ErrAlreadyAnonymized):
+		response.Error(c, http.StatusConflict, err.Error(), nil)
+	case report != nil:
+		response.Error(c, http.StatusConflict, err.Error(), report)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
 }
 
 // GetPeers godoc
@@ -277,6 +469,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 // @Tags Users
 // @Produce json
 // @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]UserSummary}
 // @Router /users/peers [get]
 func (h *Handler) GetPeers(c *gin.Context) {
 	claims, exists := c.Get("claims")
@@ -292,20 +485,50 @@ func (h *Handler) GetPeers(c *gin.Context) {
 		response.Error(c, http.StatusInternalServerError, "Failed to fetch peers", err.Error())
 		return
 	}
-	
-	for i := range users {
-		users[i].Password = ""
+
+	response.Success(c, NewUserSummaries(users))
+}
+
+// SearchPeers godoc
+// @Summary Search students to invite
+// @Description Search-as-you-type lookup of the department's students by name, email or student ID, exact and prefix matches first. Students already in a finalized team are left out; current_team names the team a student is in while it is still being formed.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "At least 2 characters"
+// @Param limit query int false "Maximum results (default: 10, max: 25)"
+// @Success 200 {object} response.Response{data=[]PeerMatch}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /users/peers/search [get]
+func (h *Handler) SearchPeers(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
 	}
+	userClaims := claims.(*auth.TokenClaims)
 
-	response.Success(c, users)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	matches, err := h.service.SearchPeers(userClaims.DepartmentID, userClaims.UniversityID, userClaims.UserID, c.Query("q"), limit)
+	if errors.Is(err, ErrPeerSearchTooShort) {
+		response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to search peers", err.Error())
+		return
+	}
+
+	response.Success(c, matches)
 }
 
 // GetAdvisors godoc
 // @Summary List advisors with workload
-// @Description Admin sees list of advisors in their department with current team counts
+// @Description Admin sees list of advisors in their department with current team counts and their use of the per-cohort proposal quota
 // @Tags Admin - Users
 // @Produce json
 // @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]AdvisorWorkload}
 // @Router /admin/advisors [get]
 func (h *Handler) GetAdvisors(c *gin.Context) {
     claims, exists := c.Get("claims")
@@ -327,10 +550,12 @@ func (h *Handler) GetAdvisors(c *gin.Context) {
 
 // GetDashboardStats godoc
 // @Summary Get admin dashboard statistics
-// @Description Aggregated stats for the Department Head dashboard
+// @Description Aggregated stats for the Department Head dashboard, served from a materialized copy kept fresh by events and a periodic job. refresh=true recomputes it now.
 // @Tags Admin
 // @Produce json
 // @Security BearerAuth
+// @Param refresh query bool false "Recompute instead of using the materialized stats"
+// @Success 200 {object} response.Response{data=AdminDashboardStats}
 // @Router /admin/stats [get]
 func (h *Handler) GetDashboardStats(c *gin.Context) {
 	claims, exists := c.Get("claims")
@@ -340,11 +565,204 @@ func (h *Handler) GetDashboardStats(c *gin.Context) {
 	}
 	userClaims := claims.(*auth.TokenClaims)
 
-	stats, err := h.service.GetAdminDashboardStats(userClaims.DepartmentID)
+	stats, err := h.service.GetAdminDashboardStats(userClaims.DepartmentID, c.Query("refresh") == "true")
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Failed to fetch stats", err.Error())
 		return
 	}
 
 	response.Success(c, stats)
-}
\ No newline at end of file
+}
+
+// StreamDashboardStats godoc
+// @Summary Stream live dashboard updates
+// @Description Server-Sent Events stream for the Department Head dashboard. It opens with a snapshot event carrying the full stats, then sends a delta event whenever a submission, approval or other change moves the numbers: only the counters that changed (new value and change) and any list that changed, with the events behind it. Comments are sent while idle to keep the connection open. EventSource cannot set headers, so the JWT may be passed as access_token.
+// @Tags Admin
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param access_token query string false "JWT when the Authorization header cannot be set"
+// @Success 200 {object} DashboardDelta "snapshot and delta events"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/stats/stream [get]
+func (h *Handler) StreamDashboardStats(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	stats, err := h.service.GetAdminDashboardStats(userClaims.DepartmentID, false)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch stats", err.Error())
+		return
+	}
+	updates, cancel := h.service.SubscribeDashboard(userClaims.DepartmentID, stats)
+	defer cancel()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+	c.SSEvent("snapshot", stats)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(DashboardStreamKeepAlive)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case delta, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("delta", delta)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// ExportMyData godoc
+// @Summary Export my personal data
+// @Description Compiles the caller's profile, team memberships, proposals, feedback, reviews, notifications and the audit events that reference them. The export is generated in the background: while it runs the endpoint answers 202 with its status and a Retry-After header, and the caller is notified when it is ready. A finished export stays downloadable for seven days; refresh=true discards it and starts a new one.
+// @Tags Users
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param format query string false "zip (default) or json"
+// @Param refresh query bool false "Discard the finished export and generate a new one"
+// @Success 200 {file} file "The export"
+// @Success 202 {object} response.Response{data=domain.DataExport}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/export [get]
+func (h *Handler) ExportMyData(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+
+	format := c.DefaultQuery("format", "zip")
+	if format != "zip" && format != "json" {
+		response.Error(c, http.StatusBadRequest, "format must be zip or json", nil)
+		return
+	}
+
+	export, err := h.service.RequestDataExport(userClaims.UserID, c.Query("refresh") == "true")
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to export data", err.Error())
+		return
+	}
+	if export.Status != enums.DataExportStatusCompleted {
+		c.Header("Retry-After", "30")
+		response.JSON(c, http.StatusAccepted, "Your export is being prepared", export)
+		return
+	}
+
+	filename := fmt.Sprintf("personal_data_%s", export.CompletedAt.Format("2006-01-02"))
+	if format == "json" {
+		body, err := h.service.DataExportJSON(export)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Failed to read export", err.Error())
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+	c.FileAttachment(export.FilePath, filename+".zip")
+}
+
+// ChangeEmail godoc
+// @Summary Change my email address
+// @Description Emails a confirmation link to the new address, valid for 24 hours; the current address stays in use until the link is followed, and is told about the request. Requesting again replaces a pending change. Needs outgoing email to be configured.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangeEmailRequest true "New address and current password"
+// @Success 202 {object} response.Response{data=PendingEmailChange}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /users/me/change-email [post]
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	userClaims := claims.(*auth.TokenClaims)
+	if userClaims.ImpersonatorID != 0 {
+		response.Error(c, http.StatusForbidden, "Cannot change the email of an impersonated account", nil)
+		return
+	}
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	meta := audit.NewRequestMeta(c)
+	pending, err := h.service.RequestEmailChange(userClaims.UserID, req, meta)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrEmailChangeUnavailable):
+			response.Error(c, http.StatusServiceUnavailable, err.Error(), nil)
+		case errors.Is(err, ErrWrongPassword), errors.Is(err, ErrSameEmail):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrEmailTaken):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to request the email change", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusAccepted, "A confirmation link was sent to "+pending.NewEmail, pending)
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Follows the link emailed to the new address: the account switches to it, the address counts as verified and every session is signed out. No authentication is needed, since the link may be opened on another device.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body ConfirmEmailRequest true "Token from the confirmation link"
+// @Success 200 {object} response.Response{data=UserResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 410 {object} response.ErrorResponse
+// @Router /users/confirm-email [post]
+func (h *Handler) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	meta := audit.NewRequestMeta(c)
+	user, err := h.service.ConfirmEmailChange(req.Token, meta)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrEmailChangeInvalid):
+			response.Error(c, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrEmailChangeExpired):
+			response.Error(c, http.StatusGone, err.Error(), nil)
+		case errors.Is(err, ErrEmailTaken):
+			response.Error(c, http.StatusConflict, err.Error(), nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to confirm the email change", err.Error())
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Email changed; please sign in with your new address", NewUserResponse(user))
+}
@@ -0,0 +1,85 @@
+package users
+
+import (
+	"backend/pkg/enums"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMergeSameUser     = errors.New("primary and duplicate must be different accounts")
+	ErrMergeNotStudent   = errors.New("only student accounts can be merged")
+	ErrMergeOutsideScope = errors.New("both accounts must belong to your department")
+	ErrMergeInactive     = errors.New("the primary account is deactivated")
+	ErrMergeTeamConflict = errors.New("both accounts are in different active teams of the same cohort")
+)
+
+type MergeUsersRequest struct {
+	PrimaryID   uint `json:"primary_id" binding:"required" example:"12"`
+	DuplicateID uint `json:"duplicate_id" binding:"required" example:"31"`
+}
+
+// MergeResult counts what was moved from the duplicate account to the primary one
+type MergeResult struct {
+	Primary       *UserSummary `json:"primary"`
+	Duplicate     *UserSummary `json:"duplicate"`
+	Memberships   int64        `json:"memberships"`
+	Proposals     int64        `json:"proposals"`
+	Versions      int64        `json:"versions"`
+	Reviews       int64        `json:"reviews"`
+	Notifications int64        `json:"notifications"`
+	Invitations   int64        `json:"invitations"`
+}
+
+// MergeUsers folds a student's duplicate account into their primary one: team memberships,
+// proposals, project reviews, invitations and notifications move over in one transaction, and
+// the duplicate is deactivated with its sessions revoked. Both accounts must be students of the
+// admin's department, and must not sit in two different active teams of the same cohort, since
+// a student belongs to one team per cohort.
+func (s *Service) MergeUsers(req MergeUsersRequest, departmentID uint) (*MergeResult, error) {
+	if req.PrimaryID == req.DuplicateID {
+		return nil, ErrMergeSameUser
+	}
+	primary, err := s.repo.GetByID(req.PrimaryID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	duplicate, err := s.repo.GetByID(req.DuplicateID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if primary.Role != enums.RoleStudent || duplicate.Role != enums.RoleStudent {
+		return nil, ErrMergeNotStudent
+	}
+	if primary.DepartmentID != departmentID || duplicate.DepartmentID != departmentID {
+		return nil, ErrMergeOutsideScope
+	}
+	if !primary.IsActive {
+		return nil, ErrMergeInactive
+	}
+
+	primaryTeams, err := s.repo.GetMemberTeams(primary.ID)
+	if err != nil {
+		return nil, err
+	}
+	duplicateTeams, err := s.repo.GetMemberTeams(duplicate.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, theirs := range duplicateTeams {
+		for _, ours := range primaryTeams {
+			if theirs.ID != ours.ID && !theirs.IsArchived && !ours.IsArchived && theirs.AcademicYear == ours.AcademicYear {
+				return nil, fmt.Errorf("%w: %s and %s (%s)", ErrMergeTeamConflict, ours.Name, theirs.Name, ours.AcademicYear)
+			}
+		}
+	}
+
+	result, err := s.repo.MergeUsers(primary.ID, duplicate.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Primary = NewUserSummary(primary)
+	result.Duplicate = NewUserSummary(duplicate)
+	return result, nil
+}
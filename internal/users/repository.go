@@ -2,9 +2,13 @@ package users
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/database"
 	"backend/pkg/enums" // Make sure to import this!
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
@@ -19,10 +23,57 @@ type Repository interface {
 	GetDB() *gorm.DB 
 
 	FindPeers(departmentID uint, universityID uint, excludeUserID uint) ([]domain.User, error)
+	SearchPeers(departmentID uint, universityID uint, excludeUserID uint, q string, limit int) ([]PeerMatch, error)
 	// NEW METHODS FOR ADMIN
     GetAdvisorsByDepartment(departmentID uint) ([]domain.User, error)
     // GetAdvisorWorkload returns a map of AdvisorID -> Count
     GetAdvisorWorkload(departmentID uint) (map[uint]int64, error)
+	GetAdvisorProposalLimit(departmentID uint) (int, error)
+	GetCohortAdvisorLoad(departmentID uint) (string, map[uint]int64, error)
+
+	// Cascade: what a user is responsible for, and the atomic hand-over before removal
+	GetLedTeams(userID uint) ([]domain.Team, error)
+	GetSuccessorCandidates(teamID uint, excludeUserID uint) ([]domain.User, error)
+	GetAdvisedTeams(userID uint) ([]domain.Team, error)
+	GetAdvisedProposals(userID uint, statuses []enums.ProposalStatus) ([]domain.Proposal, error)
+	CountPendingInvitations(userID uint) (int64, error)
+	CountActiveDelegations(userID uint) (int64, error)
+	ApplyCascade(userID uint, plan CascadePlan, remove bool) error
+
+	// Merging duplicate student accounts
+	GetMemberTeams(userID uint) ([]domain.Team, error)
+	MergeUsers(primaryID uint, duplicateID uint) (*MergeResult, error)
+
+	// Materialized dashboard stats
+	GetDashboardStat(departmentID uint) (*domain.DashboardStat, error)
+	SaveDashboardStat(stat *domain.DashboardStat) error
+	MarkDashboardDirty(entityType string, entityID uint) error
+	GetDashboardsToRefresh(staleBefore time.Time) ([]uint, error)
+	// GetEntityDepartment resolves the department a team, proposal or project belongs to; 0 when unknown
+	GetEntityDepartment(entityType string, entityID uint) (uint, error)
+	GetMissedRevisionDeadlines(departmentID uint) ([]MissedDeadline, error)
+
+	// Personal data exports
+	CreateDataExport(export *domain.DataExport) error
+	UpdateDataExport(export *domain.DataExport) error
+	GetDataExport(id uint) (*domain.DataExport, error)
+	// GetLatestDataExport returns the user's most recent export, or nil when there is none
+	GetLatestDataExport(userID uint) (*domain.DataExport, error)
+	GetExpiredDataExports(now time.Time) ([]domain.DataExport, error)
+	DeleteDataExport(id uint) error
+	GetPersonalData(userID uint) (*PersonalData, error)
+	// AnonymizeUser applies the cascade plan and replaces the user's personal data in one transaction
+	AnonymizeUser(userID uint, plan CascadePlan, former ErasedIdentity) error
+
+	// Email changes
+	// EmailTaken reports whether another account uses the address, ignoring case
+	EmailTaken(email string, excludeUserID uint) (bool, error)
+	// CreateEmailChange stores a pending change, dropping the user's earlier unconfirmed ones
+	CreateEmailChange(change *domain.EmailChange) error
+	DeleteEmailChange(id uint) error
+	GetEmailChangeByTokenHash(tokenHash string) (*domain.EmailChange, error)
+	// ConfirmEmailChange moves the user to the new address and marks it verified in one transaction
+	ConfirmEmailChange(change *domain.EmailChange, now time.Time) error
 }
 
 type repository struct {
@@ -112,6 +163,35 @@ func (r *repository) FindPeers(departmentID uint, universityID uint, excludeUser
 	return users, err
 }
 
+// SearchPeers matches the department's active students on name, email or student ID, exact and
+// prefix matches first. Students in a finalized team of a running cohort cannot be invited and are left out.
+func (r *repository) SearchPeers(departmentID uint, universityID uint, excludeUserID uint, q string, limit int) ([]PeerMatch, error) {
+	escaped := database.EscapeLike(q)
+	contains, prefix := "%"+escaped+"%", escaped+"%"
+
+	var matches []PeerMatch
+	err := r.db.Model(&domain.User{}).
+		Select(`users.id, users.name, users.email, users.role, users.profile_photo, users.student_id,
+			(SELECT teams.name FROM team_members JOIN teams ON teams.id = team_members.team_id
+				WHERE team_members.user_id = users.id AND teams.is_archived = ?
+				ORDER BY teams.created_at DESC LIMIT 1) AS current_team`, false).
+		Where("users.university_id = ? AND users.department_id = ? AND users.role = ? AND users.id <> ?",
+			universityID, departmentID, enums.RoleStudent, excludeUserID).
+		Where("users.is_active = ? AND users.deleted_at IS NULL AND users.anonymized_at IS NULL", true).
+		Where("users.name ILIKE ? OR users.email ILIKE ? OR users.student_id ILIKE ?", contains, contains, contains).
+		Where(`NOT EXISTS (SELECT 1 FROM team_members JOIN teams ON teams.id = team_members.team_id
+			WHERE team_members.user_id = users.id AND teams.is_finalized = ? AND teams.is_archived = ?)`, true, false).
+		Order(clause.Expr{
+			SQL: `CASE WHEN users.student_id ILIKE ? OR users.email ILIKE ? THEN 0
+				WHEN users.name ILIKE ? OR users.email ILIKE ? OR users.student_id ILIKE ? THEN 1
+				ELSE 2 END, users.name ASC`,
+			Vars: []interface{}{escaped, escaped, prefix, prefix, prefix},
+		}).
+		Limit(limit).
+		Scan(&matches).Error
+	return matches, err
+}
+
 func (r *repository) GetAdvisorsByDepartment(departmentID uint) ([]domain.User, error) {
     var advisors []domain.User
     err := r.db.Where("department_id = ? AND role = ?", departmentID, enums.RoleAdvisor).Find(&advisors).Error
@@ -139,3 +219,559 @@ func (r *repository) GetAdvisorWorkload(departmentID uint) (map[uint]int64, erro
     }
     return workload, err
 }
+
+// GetAdvisorProposalLimit is the department's per-cohort proposal quota per advisor; 0 when unlimited
+func (r *repository) GetAdvisorProposalLimit(departmentID uint) (int, error) {
+	var limit int
+	err := r.db.Model(&domain.DepartmentQuota{}).
+		Select("advisor_proposal_limit").
+		Where("department_id = ?", departmentID).
+		Scan(&limit).Error
+	return limit, err
+}
+
+// GetCohortAdvisorLoad returns the department's current academic year and how many of that
+// cohort's proposals each of its advisors has taken
+func (r *repository) GetCohortAdvisorLoad(departmentID uint) (string, map[uint]int64, error) {
+	var year string
+	if err := r.db.Table("departments").
+		Select("universities.academic_year").
+		Joins("JOIN universities ON universities.id = departments.university_id").
+		Where("departments.id = ?", departmentID).
+		Scan(&year).Error; err != nil {
+		return "", nil, err
+	}
+
+	var rows []struct {
+		AdvisorID uint
+		Count     int64
+	}
+	err := r.db.Table("proposals").
+		Select("proposals.advisor_id, COUNT(*) AS count").
+		Joins("JOIN users ON users.id = proposals.advisor_id").
+		Where("users.department_id = ? AND proposals.academic_year = ?", departmentID, year).
+		Group("proposals.advisor_id").
+		Scan(&rows).Error
+
+	load := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		load[row.AdvisorID] = row.Count
+	}
+	return year, load, err
+}
+
+func (r *repository) GetLedTeams(userID uint) ([]domain.Team, error) {
+	var teams []domain.Team
+	err := r.db.
+		Joins("JOIN team_members ON team_members.team_id = teams.id").
+		Where("team_members.user_id = ? AND team_members.role = ?", userID, "leader").
+		Find(&teams).Error
+	return teams, err
+}
+
+// GetSuccessorCandidates lists the team's other active members, longest-standing first.
+// Members without an accepted invitation joined when the team was created, so they sort first.
+func (r *repository) GetSuccessorCandidates(teamID uint, excludeUserID uint) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.
+		Joins("JOIN team_members ON team_members.user_id = users.id").
+		Joins("LEFT JOIN team_invitations ON team_invitations.team_id = team_members.team_id AND team_invitations.invitee_id = team_members.user_id AND team_invitations.status = ?", enums.InvitationStatusAccepted).
+		Where("team_members.team_id = ? AND users.id != ? AND users.is_active = ?", teamID, excludeUserID, true).
+		Order("team_invitations.responded_at ASC NULLS FIRST, users.id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *repository) GetAdvisedTeams(userID uint) ([]domain.Team, error) {
+	var teams []domain.Team
+	err := r.db.Where("advisor_id = ?", userID).Find(&teams).Error
+	return teams, err
+}
+
+func (r *repository) GetAdvisedProposals(userID uint, statuses []enums.ProposalStatus) ([]domain.Proposal, error) {
+	var proposals []domain.Proposal
+	err := r.db.Preload("Team").
+		Where("advisor_id = ? AND status IN ? AND is_archived = ?", userID, statuses, false).
+		Find(&proposals).Error
+	return proposals, err
+}
+
+func (r *repository) CountPendingInvitations(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.TeamInvitation{}).
+		Where("(invitee_id = ? OR inviter_id = ?) AND status = ?", userID, userID, enums.InvitationStatusPending).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *repository) CountActiveDelegations(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Delegation{}).
+		Where("(delegator_id = ? OR delegate_id = ?) AND revoked_at IS NULL AND ends_at > ?", userID, userID, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// ApplyCascade hands the user's responsibilities over as planned, closes their pending invitations
+// and delegations, then deactivates (or, with remove, deletes) the account, all in one transaction.
+func (r *repository) ApplyCascade(userID uint, plan CascadePlan, remove bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return applyCascade(tx, userID, plan, remove)
+	})
+}
+
+func applyCascade(tx *gorm.DB, userID uint, plan CascadePlan, remove bool) error {
+	for teamID, leaderID := range plan.LeaderTransfers {
+		if err := tx.Model(&domain.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", teamID, userID).
+			Update("role", "member").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.TeamMember{}).
+			Where("team_id = ? AND user_id = ?", teamID, leaderID).
+			Update("role", "leader").Error; err != nil {
+			return err
+		}
+		// Drafts stay editable by the team once their author is gone
+		if err := tx.Model(&domain.Proposal{}).
+			Where("team_id = ? AND created_by = ?", teamID, userID).
+			Update("created_by", leaderID).Error; err != nil {
+			return err
+		}
+	}
+
+	if plan.AdvisorID != 0 {
+		if len(plan.TeamIDs) > 0 {
+			if err := tx.Model(&domain.Team{}).
+				Where("id IN ? AND advisor_id = ?", plan.TeamIDs, userID).
+				Update("advisor_id", plan.AdvisorID).Error; err != nil {
+				return err
+			}
+		}
+		if len(plan.ProposalIDs) > 0 {
+			// a co-reviewer taking the proposals over stops co-reviewing them
+			if err := tx.Model(&domain.Proposal{}).
+				Where("id IN ? AND advisor_id = ?", plan.ProposalIDs, userID).
+				Updates(map[string]interface{}{
+					"advisor_id":                  plan.AdvisorID,
+					"second_reviewer_id":          gorm.Expr("NULLIF(second_reviewer_id, ?)", plan.AdvisorID),
+					"second_reviewer_assigned_at": gorm.Expr("CASE WHEN second_reviewer_id = ? THEN NULL ELSE second_reviewer_assigned_at END", plan.AdvisorID),
+				}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	if err := tx.Model(&domain.TeamInvitation{}).
+		Where("(invitee_id = ? OR inviter_id = ?) AND status = ?", userID, userID, enums.InvitationStatusPending).
+		Updates(map[string]interface{}{"status": enums.InvitationStatusExpired, "responded_at": now}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&domain.Delegation{}).
+		Where("(delegator_id = ? OR delegate_id = ?) AND revoked_at IS NULL AND ends_at > ?", userID, userID, now).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+
+	if !remove {
+		return tx.Model(&domain.User{}).Where("id = ?", userID).Update("is_active", false).Error
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.TeamMember{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&domain.User{}, userID).Error
+}
+
+func (r *repository) GetDashboardStat(departmentID uint) (*domain.DashboardStat, error) {
+	var stat domain.DashboardStat
+	if err := r.db.Where("department_id = ?", departmentID).First(&stat).Error; err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+func (r *repository) SaveDashboardStat(stat *domain.DashboardStat) error {
+	return r.db.Save(stat).Error
+}
+
+// MarkDashboardDirty flags the dashboard of the department an entity belongs to.
+// Departments without a materialized row are computed on first read anyway.
+func (r *repository) MarkDashboardDirty(entityType string, entityID uint) error {
+	query := r.db.Model(&domain.DashboardStat{})
+	switch entityType {
+	case "department":
+		query = query.Where("department_id = ?", entityID)
+	case "team":
+		query = query.Where("department_id IN (?)", r.db.Model(&domain.Team{}).Select("department_id").Where("id = ?", entityID))
+	case "proposal":
+		query = query.Where("department_id IN (?)", r.db.Table("proposals").
+			Select("teams.department_id").
+			Joins("JOIN teams ON teams.id = proposals.team_id").
+			Where("proposals.id = ?", entityID))
+	case "project":
+		query = query.Where("department_id IN (?)", r.db.Model(&domain.Project{}).Select("department_id").Where("id = ?", entityID))
+	default:
+		query = query.Where("1 = 1")
+	}
+	return query.Update("dirty", true).Error
+}
+
+func (r *repository) GetEntityDepartment(entityType string, entityID uint) (uint, error) {
+	var query *gorm.DB
+	switch entityType {
+	case "department":
+		return entityID, nil
+	case "team":
+		query = r.db.Model(&domain.Team{}).Select("department_id").Where("id = ?", entityID)
+	case "proposal":
+		query = r.db.Table("proposals").
+			Select("teams.department_id").
+			Joins("JOIN teams ON teams.id = proposals.team_id").
+			Where("proposals.id = ?", entityID)
+	case "project":
+		query = r.db.Model(&domain.Project{}).Select("department_id").Where("id = ?", entityID)
+	default:
+		return 0, nil
+	}
+	var ids []uint
+	if err := query.Limit(1).Pluck("department_id", &ids).Error; err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *repository) GetDashboardsToRefresh(staleBefore time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.DashboardStat{}).
+		Where("dirty = ? OR refreshed_at < ?", true, staleBefore).
+		Pluck("department_id", &ids).Error
+	return ids, err
+}
+
+// GetMissedRevisionDeadlines lists the department's flagged deadlines whose proposal still awaits revision
+func (r *repository) GetMissedRevisionDeadlines(departmentID uint) ([]MissedDeadline, error) {
+	missed := []MissedDeadline{}
+	err := r.db.Table("feedbacks").
+		Select("feedbacks.id AS feedback_id, feedbacks.proposal_id, teams.id AS team_id, teams.name AS team_name, "+
+			"feedbacks.reviewer_id AS advisor_id, feedbacks.resubmit_by, feedbacks.deadline_missed_at AS missed_at").
+		Joins("JOIN proposals ON proposals.id = feedbacks.proposal_id").
+		Joins("JOIN teams ON teams.id = proposals.team_id").
+		Where("teams.department_id = ? AND feedbacks.deadline_missed_at IS NOT NULL AND proposals.status = ?",
+			departmentID, enums.ProposalStatusRevisionRequired).
+		Order("feedbacks.deadline_missed_at DESC").
+		Scan(&missed).Error
+	return missed, err
+}
+
+// GetMemberTeams lists every team the user is a member of, archived ones included
+func (r *repository) GetMemberTeams(userID uint) ([]domain.Team, error) {
+	var teams []domain.Team
+	err := r.db.
+		Joins("JOIN team_members ON team_members.team_id = teams.id").
+		Where("team_members.user_id = ?", userID).
+		Find(&teams).Error
+	return teams, err
+}
+
+// MergeUsers moves the duplicate's memberships, authored proposals and versions, project reviews,
+// invitations and notifications to the primary account, then revokes the duplicate's sessions and
+// deactivates it, all in one transaction. Where both accounts hold the same membership, review or
+// pending invitation, the primary's is kept; a duplicate who led a shared team hands the lead over.
+func (r *repository) MergeUsers(primaryID uint, duplicateID uint) (*MergeResult, error) {
+	result := &MergeResult{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var memberships []domain.TeamMember
+		if err := tx.Where("user_id = ?", duplicateID).Find(&memberships).Error; err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			var shared int64
+			if err := tx.Model(&domain.TeamMember{}).
+				Where("team_id = ? AND user_id = ?", m.TeamID, primaryID).
+				Count(&shared).Error; err != nil {
+				return err
+			}
+			if shared == 0 {
+				if err := tx.Model(&domain.TeamMember{}).
+					Where("team_id = ? AND user_id = ?", m.TeamID, duplicateID).
+					Update("user_id", primaryID).Error; err != nil {
+					return err
+				}
+				result.Memberships++
+				continue
+			}
+			if m.Role == "leader" {
+				if err := tx.Model(&domain.TeamMember{}).
+					Where("team_id = ? AND user_id = ?", m.TeamID, primaryID).
+					Update("role", "leader").Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("team_id = ? AND user_id = ?", m.TeamID, duplicateID).Delete(&domain.TeamMember{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&domain.Team{}).Where("created_by = ?", duplicateID).Update("created_by", primaryID).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&domain.Proposal{}).Where("created_by = ?", duplicateID).Update("created_by", primaryID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Proposals = res.RowsAffected
+		res = tx.Model(&domain.ProposalVersion{}).Where("created_by = ?", duplicateID).Update("created_by", primaryID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Versions = res.RowsAffected
+
+		if err := tx.Where("user_id = ? AND project_id IN (?)", duplicateID,
+			tx.Model(&domain.ProjectReview{}).Select("project_id").Where("user_id = ?", primaryID)).
+			Delete(&domain.ProjectReview{}).Error; err != nil {
+			return err
+		}
+		res = tx.Model(&domain.ProjectReview{}).Where("user_id = ?", duplicateID).Update("user_id", primaryID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Reviews = res.RowsAffected
+
+		now := time.Now()
+		if err := tx.Model(&domain.TeamInvitation{}).
+			Where("invitee_id = ? AND status = ? AND team_id IN (?)", duplicateID, enums.InvitationStatusPending,
+				tx.Model(&domain.TeamMember{}).Select("team_id").Where("user_id = ?", primaryID)).
+			Updates(map[string]interface{}{"status": enums.InvitationStatusExpired, "responded_at": now}).Error; err != nil {
+			return err
+		}
+		res = tx.Model(&domain.TeamInvitation{}).Where("invitee_id = ?", duplicateID).Update("invitee_id", primaryID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Invitations = res.RowsAffected
+		if err := tx.Model(&domain.TeamInvitation{}).Where("inviter_id = ?", duplicateID).Update("inviter_id", primaryID).Error; err != nil {
+			return err
+		}
+
+		res = tx.Model(&domain.Notification{}).Where("user_id = ?", duplicateID).Update("user_id", primaryID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Notifications = res.RowsAffected
+
+		if err := tx.Model(&domain.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL", duplicateID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", duplicateID).Update("is_active", false).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *repository) CreateDataExport(export *domain.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+func (r *repository) UpdateDataExport(export *domain.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+func (r *repository) GetDataExport(id uint) (*domain.DataExport, error) {
+	var export domain.DataExport
+	if err := r.db.First(&export, id).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *repository) GetLatestDataExport(userID uint) (*domain.DataExport, error) {
+	var exports []domain.DataExport
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(1).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	if len(exports) == 0 {
+		return nil, nil
+	}
+	return &exports[0], nil
+}
+
+func (r *repository) GetExpiredDataExports(now time.Time) ([]domain.DataExport, error) {
+	var exports []domain.DataExport
+	err := r.db.Where("expires_at < ?", now).Find(&exports).Error
+	return exports, err
+}
+
+func (r *repository) DeleteDataExport(id uint) error {
+	return r.db.Delete(&domain.DataExport{}, id).Error
+}
+
+// GetPersonalData loads everything stored about the user: their own records, the proposals of
+// their teams with the feedback on them, and the audit events naming them
+func (r *repository) GetPersonalData(userID uint) (*PersonalData, error) {
+	data := &PersonalData{}
+	if err := r.db.Preload("University").Preload("Department").First(&data.Profile, userID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Where("user_id = ?", userID).Find(&data.Memberships).Error; err != nil {
+		return nil, err
+	}
+	teamIDs := r.db.Model(&domain.TeamMember{}).Select("team_id").Where("user_id = ?", userID)
+	if err := r.db.Where("id IN (?)", teamIDs).Find(&data.Teams).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Preload("Versions", func(db *gorm.DB) *gorm.DB { return db.Order("version_number ASC") }).
+		Where("created_by = ? OR advisor_id = ? OR team_id IN (?)", userID, userID, teamIDs).
+		Order("created_at ASC").
+		Find(&data.Proposals).Error; err != nil {
+		return nil, err
+	}
+	proposalIDs := make([]uint, 0, len(data.Proposals))
+	for _, p := range data.Proposals {
+		proposalIDs = append(proposalIDs, p.ID)
+	}
+
+	if err := r.db.Where("reviewer_id = ? OR proposal_id IN ?", userID, append(proposalIDs, 0)).
+		Order("created_at ASC").
+		Find(&data.Feedback).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Reviews).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Notifications).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("actor_id = ? OR impersonator_id = ? OR delegator_id = ? OR (entity_type = ? AND entity_id = ?)",
+		userID, userID, userID, "user", userID).
+		Order("timestamp ASC").
+		Find(&data.AuditEvents).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *repository) AnonymizeUser(userID uint, plan CascadePlan, former ErasedIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyCascade(tx, userID, plan, false); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"name":           AnonymizedName,
+			"email":          anonymizedEmail(userID),
+			"password":       "",
+			"student_id":     "",
+			"profile_photo":  "",
+			"email_verified": false,
+			"last_login_at":  nil,
+			"anonymized_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.DataExport{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.EmailChange{}).Error; err != nil {
+			return err
+		}
+
+		// Audit entries keep their shape for the archive; only who the actor was is erased
+		if err := tx.Model(&domain.AuditLog{}).Where("actor_id = ?", userID).Updates(map[string]interface{}{
+			"actor_email": anonymizedEmail(userID),
+			"ip_address":  nil,
+			"user_agent":  "",
+		}).Error; err != nil {
+			return err
+		}
+		related := func() *gorm.DB {
+			return tx.Model(&domain.AuditLog{}).Select("id").Where(
+				"actor_id = ? OR impersonator_id = ? OR delegator_id = ? OR (entity_type = ? AND entity_id = ?)",
+				userID, userID, userID, "user", userID)
+		}
+		for _, value := range former.replacements(userID) {
+			quoted, placeholder := jsonString(value[0]), jsonString(value[1])
+			for _, column := range []string{"old_state", "new_state"} {
+				if err := tx.Model(&domain.AuditLog{}).
+					Where("id IN (?) AND "+column+"::text LIKE ?", related(), "%"+quoted+"%").
+					Update(column, gorm.Expr("replace("+column+"::text, ?, ?)::jsonb", quoted, placeholder)).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&domain.AuditLog{}).
+				Where("id IN (?) AND metadata LIKE ?", related(), "%"+quoted+"%").
+				Update("metadata", gorm.Expr("replace(metadata, ?, ?)", quoted, placeholder)).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.ProjectReview{}).
+				Where("user_id = ? AND comment LIKE ?", userID, "%"+value[0]+"%").
+				Update("comment", gorm.Expr("replace(comment, ?, ?)", value[0], value[1])).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repository) EmailTaken(email string, excludeUserID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.User{}).
+		Where("LOWER(email) = ? AND id <> ?", strings.ToLower(email), excludeUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *repository) CreateEmailChange(change *domain.EmailChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND confirmed_at IS NULL", change.UserID).Delete(&domain.EmailChange{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *repository) DeleteEmailChange(id uint) error {
+	return r.db.Delete(&domain.EmailChange{}, id).Error
+}
+
+func (r *repository) GetEmailChangeByTokenHash(tokenHash string) (*domain.EmailChange, error) {
+	var change domain.EmailChange
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&change).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *repository) ConfirmEmailChange(change *domain.EmailChange, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Guards against the same link being followed twice at once
+		result := tx.Model(&domain.EmailChange{}).
+			Where("id = ? AND confirmed_at IS NULL", change.ID).
+			Update("confirmed_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEmailChangeInvalid
+		}
+		return tx.Model(&domain.User{}).Where("id = ? AND email = ?", change.UserID, change.OldEmail).Updates(map[string]interface{}{
+			"email":          change.NewEmail,
+			"email_verified": true,
+		}).Error
+	})
+}
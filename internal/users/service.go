@@ -2,18 +2,36 @@ package users
 
 import (
 	"backend/internal/domain"
+	"backend/pkg/audit"
 	"backend/pkg/enums"
+	"backend/pkg/events"
+	"backend/pkg/mailer"
+	"backend/pkg/settings"
 	"errors"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Service struct {
-	repo Repository
+	repo        Repository
+	bus         *events.Bus
+	auditLogger *audit.Logger
+	tokens      TokenRevoker
+	stream      *dashboardStream
+	mailer      *mailer.Mailer // nil when email is not configured
+	appURL      string         // web app base of links in emails
+	settings    *settings.Store
 }
 
-func NewService(r Repository) *Service {
-	return &Service{repo: r}
+// TokenRevoker revokes a user's access tokens before they expire
+type TokenRevoker interface {
+	RevokeUserTokens(userID uint, reason string, actorID *uint) error
+}
+
+func NewService(r Repository, bus *events.Bus, auditLogger *audit.Logger, tokens TokenRevoker, mail *mailer.Mailer, appURL string, settingsStore *settings.Store) *Service {
+	return &Service{repo: r, bus: bus, auditLogger: auditLogger, tokens: tokens, stream: newDashboardStream(), mailer: mail, appURL: appURL, settings: settingsStore}
 }
 
 type CreateTeacherRequest struct {
@@ -41,18 +59,6 @@ type AssignDepartmentRequest struct {
 	DepartmentID uint `json:"department_id" binding:"required"`
 }
 
-type UserResponse struct {
-	ID           uint       `json:"id"`
-	Name         string     `json:"name"`
-	Email        string     `json:"email"`
-	Role         enums.Role `json:"role"`
-	StudentID    string     `json:"student_id,omitempty"`
-	UniversityID uint       `json:"university_id"`
-	DepartmentID uint       `json:"department_id"`
-	IsActive     bool       `json:"is_active"`
-	CreatedAt    string     `json:"created_at"`
-}
-
 func (s *Service) CreateTeacher(req CreateTeacherRequest) (*domain.User, error) {
 	// Check if email already exists
 	existing, _ := s.repo.GetByEmail(req.Email)
@@ -139,13 +145,21 @@ func (s *Service) GetAllUsers(role string, departmentID uint, universityID uint,
 	return s.repo.GetAll(filters)
 }
 
-func (s *Service) UpdateUserStatus(id uint, isActive bool) error {
-	_, err := s.repo.GetByID(id)
+// UpdateUserStatus reactivates a user directly; deactivation goes through the cascade policy
+func (s *Service) UpdateUserStatus(id uint, isActive bool, opts CascadeOptions) (*DependencyReport, error) {
+	if !isActive {
+		return s.removeUser(id, opts, false)
+	}
+
+	user, err := s.repo.GetByID(id)
 	if err != nil {
-		return errors.New("user not found")
+		return nil, errors.New("user not found")
+	}
+	if user.AnonymizedAt != nil {
+		return nil, ErrAlreadyAnonymized
 	}
 
-	return s.repo.UpdateStatus(id, isActive)
+	return nil, s.repo.UpdateStatus(id, isActive)
 }
 
 func (s *Service) AssignDepartment(userID uint, departmentID uint) error {
@@ -157,13 +171,9 @@ func (s *Service) AssignDepartment(userID uint, departmentID uint) error {
 	return s.repo.AssignDepartment(userID, departmentID)
 }
 
-func (s *Service) DeleteUser(id uint) error {
-	_, err := s.repo.GetByID(id)
-	if err != nil {
-		return errors.New("user not found")
-	}
-
-	return s.repo.Delete(id)
+// DeleteUser deletes a user after the cascade policy has handed over their teams and advisees
+func (s *Service) DeleteUser(id uint, opts CascadeOptions) (*DependencyReport, error) {
+	return s.removeUser(id, opts, true)
 }
 
 // Add Implementation
@@ -171,11 +181,53 @@ func (s *Service) GetPeers(departmentID uint, universityID uint, excludeUserID u
 	return s.repo.FindPeers(departmentID, universityID, excludeUserID)
 }
 
+// Limits of the invitation search
+const (
+	PeerSearchMinQuery     = 2
+	PeerSearchDefaultLimit = 10
+	PeerSearchMaxLimit     = 25
+)
+
+var ErrPeerSearchTooShort = errors.New("search needs at least 2 characters")
+
+// SearchPeers finds invitable students of the department as the user types their name, email or student ID
+func (s *Service) SearchPeers(departmentID uint, universityID uint, excludeUserID uint, q string, limit int) ([]PeerMatch, error) {
+	q = strings.TrimSpace(q)
+	if len([]rune(q)) < PeerSearchMinQuery {
+		return nil, ErrPeerSearchTooShort
+	}
+	if limit <= 0 {
+		limit = PeerSearchDefaultLimit
+	}
+	if limit > PeerSearchMaxLimit {
+		limit = PeerSearchMaxLimit
+	}
+
+	matches, err := s.repo.SearchPeers(departmentID, universityID, excludeUserID, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	if matches == nil {
+		matches = []PeerMatch{}
+	}
+	return matches, nil
+}
+
 // Add DTO
 type AdvisorWorkload struct {
-    Advisor   domain.User `json:"advisor"`
-	Proposals []domain.Proposal `json:"proposals"` 
-    TeamCount int64       `json:"team_count"`
+    Advisor   UserResponse      `json:"advisor"`
+    Proposals []ProposalSummary `json:"proposals"`
+    TeamCount int64             `json:"team_count"`
+    Quota     AdvisorQuota      `json:"quota"`
+}
+
+// AdvisorQuota is an advisor's use of the department's per-cohort proposal quota
+type AdvisorQuota struct {
+    AcademicYear string `json:"academic_year"`
+    Limit        int    `json:"limit"` // 0 = unlimited
+    Used         int64  `json:"used"`
+    Remaining    *int64 `json:"remaining,omitempty"` // nil when unlimited
+    AtCapacity   bool   `json:"at_capacity"`
 }
 
 // Add Method to Service Interface/Struct
@@ -184,6 +236,14 @@ func (s *Service) GetDepartmentAdvisorsWithWorkload(departmentID uint) ([]Adviso
     if err != nil {
         return nil, err
     }
+    limit, err := s.repo.GetAdvisorProposalLimit(departmentID)
+    if err != nil {
+        return nil, err
+    }
+    year, load, err := s.repo.GetCohortAdvisorLoad(departmentID)
+    if err != nil {
+        return nil, err
+    }
 
     var result []AdvisorWorkload
     for _, adv := range advisors {
@@ -196,29 +256,45 @@ func (s *Service) GetDepartmentAdvisorsWithWorkload(departmentID uint) ([]Adviso
             Where("advisor_id = ?", adv.ID).
             Find(&assignedProposals)
 
-        adv.Password = "" // Security
         result = append(result, AdvisorWorkload{
-            Advisor:   adv,
+            Advisor:   NewUserResponse(&adv),
             TeamCount: int64(len(assignedProposals)),
-            Proposals: assignedProposals,
+            Proposals: newProposalSummaries(assignedProposals),
+            Quota:     advisorQuota(year, limit, load[adv.ID]),
         })
     }
     
     return result, nil
 }
 
+func advisorQuota(academicYear string, limit int, used int64) AdvisorQuota {
+    quota := AdvisorQuota{AcademicYear: academicYear, Limit: limit, Used: used}
+    if limit > 0 {
+        remaining := int64(limit) - used
+        if remaining < 0 {
+            remaining = 0
+        }
+        quota.Remaining = &remaining
+        quota.AtCapacity = remaining == 0
+    }
+    return quota
+}
+
 type AdminDashboardStats struct {
-    PendingCount      int64             `json:"pending_assignment"`
-    UnderReviewCount  int64             `json:"under_review"`
-    ApprovedCount     int64             `json:"approved"`
-    TotalTeams        int64             `json:"total_teams"`
-    AvailableAdvisors int64             `json:"available_advisors"`
-    RecentProposals   []domain.Proposal `json:"recent_proposals"`
-    AdvisorWorkload   []AdvisorWorkload `json:"advisor_workload"`
+    PendingCount        int64             `json:"pending_assignment"`
+    UnderReviewCount    int64             `json:"under_review"`
+    ApprovedCount       int64             `json:"approved"`
+    TotalTeams          int64             `json:"total_teams"`
+    AvailableAdvisors   int64             `json:"available_advisors"`
+    RecentProposals     []ProposalSummary `json:"recent_proposals"`
+    AdvisorWorkload     []AdvisorWorkload `json:"advisor_workload"`
+    MissedDeadlineCount int64             `json:"missed_revision_deadlines"`
+    MissedDeadlines     []MissedDeadline  `json:"missed_deadlines"`
+    RefreshedAt         time.Time         `json:"refreshed_at"`
 }
 
-// Service Method
-func (s *Service) GetAdminDashboardStats(deptID uint) (*AdminDashboardStats, error) {
+// computeAdminDashboardStats runs the dashboard queries; reads go through the materialized copy
+func (s *Service) computeAdminDashboardStats(deptID uint) (*AdminDashboardStats, error) {
     stats := &AdminDashboardStats{}
 
 	    // FIX 1: Approved Count Query
@@ -227,17 +303,6 @@ func (s *Service) GetAdminDashboardStats(deptID uint) (*AdminDashboardStats, err
         Where("teams.department_id = ? AND proposals.status = ?", deptID, enums.ProposalStatusApproved).
         Count(&stats.ApprovedCount)
 
-    // FIX 2: Preload Leader in RecentProposals
-    s.repo.GetDB().
-        Preload("Team").
-        Preload("Team.Members.User"). // 👈 FIX: Load Users inside Members
-        Preload("Versions", "version_number = 1").
-        Joins("JOIN teams ON teams.id = proposals.team_id").
-        Where("teams.department_id = ?", deptID). // 👈 FIX: Show ALL department proposals, not just submitted
-        Order("proposals.created_at DESC").
-        Limit(10). // Increased limit
-        Find(&stats.RecentProposals)
-    
     // 1. Proposal Counts (Using raw SQL or multiple count queries for speed)
     s.repo.GetDB().Model(&domain.Proposal{}).
         Joins("JOIN teams ON teams.id = proposals.team_id").
@@ -259,6 +324,7 @@ func (s *Service) GetAdminDashboardStats(deptID uint) (*AdminDashboardStats, err
         Count(&stats.TotalTeams)
 
     // 2. Recent Pending Proposals (Limit 5)
+    var recent []domain.Proposal
     s.repo.GetDB().
         Preload("Team").
         Preload("Versions", "version_number = 1"). // Get Title
@@ -266,19 +332,35 @@ func (s *Service) GetAdminDashboardStats(deptID uint) (*AdminDashboardStats, err
         Where("teams.department_id = ? AND proposals.status = ?", deptID, enums.ProposalStatusSubmitted).
         Order("proposals.created_at DESC").
         Limit(5).
-        Find(&stats.RecentProposals)
+        Find(&recent)
+    stats.RecentProposals = newProposalSummaries(recent)
 
     // 3. Advisor Workload (Reuse existing logic)
     workload, _ := s.GetDepartmentAdvisorsWithWorkload(deptID)
     stats.AdvisorWorkload = workload
     
     // Calc Available Advisors (Capacity > Workload)
-    // Assuming hardcoded capacity of 5 for now
+    // Uses the department's advisor quota, or the university's advisors.default_capacity setting when none is set
+    var universityID uint
+    s.repo.GetDB().Model(&domain.Department{}).Select("university_id").Where("id = ?", deptID).Scan(&universityID)
+    defaultCapacity := s.settings.Int(settings.AdvisorCapacity, universityID)
     for _, w := range workload {
-        if w.TeamCount < 5 {
+        if w.Quota.Limit > 0 {
+            if !w.Quota.AtCapacity {
+                stats.AvailableAdvisors++
+            }
+        } else if w.TeamCount < int64(defaultCapacity) {
             stats.AvailableAdvisors++
         }
     }
 
+    // 4. Revision deadlines that passed without a resubmission
+    missed, err := s.repo.GetMissedRevisionDeadlines(deptID)
+    if err != nil {
+        return nil, err
+    }
+    stats.MissedDeadlines = missed
+    stats.MissedDeadlineCount = int64(len(missed))
+
     return stats, nil
 }
\ No newline at end of file
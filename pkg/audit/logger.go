@@ -5,9 +5,26 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// RequestMeta carries request details recorded in the audit trail
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+// NewRequestMeta reads the audit details of the current request
+func NewRequestMeta(c *gin.Context) RequestMeta {
+	return RequestMeta{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+		RequestID: c.GetString("request_id"),
+	}
+}
+
 type Logger struct {
 	db *gorm.DB
 }
@@ -239,6 +256,43 @@ func (a *Logger) LogVersionCreation(
 	return a.db.Create(log).Error
 }
 
+// ActionFileDownload is the action of a file download; entityType is the proposal or project the file belongs to
+const ActionFileDownload = "file_download"
+
+// LogFileDownload logs a download of a stored file. actorID is nil for anonymous downloads of public files.
+func (a *Logger) LogFileDownload(
+	entityType string,
+	entityID uint,
+	file map[string]interface{},
+	actorID *uint,
+	actorRole string,
+	actorEmail string,
+	impersonatorID *uint,
+	ipAddress string,
+	userAgent string,
+	requestID string,
+) error {
+	fileJSON, _ := json.Marshal(file)
+
+	log := &domain.AuditLog{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Action:         ActionFileDownload,
+		ActorID:        actorID,
+		ActorRole:      actorRole,
+		ActorEmail:     actorEmail,
+		ImpersonatorID: impersonatorID,
+		OldState:       "null",
+		NewState:       string(fileJSON),
+		IPAddress:      ipAddress,
+		UserAgent:      userAgent,
+		RequestID:      requestID,
+		Timestamp:      time.Now(),
+	}
+
+	return a.db.Create(log).Error
+}
+
 // GetAuditLogs retrieves audit logs with filtering
 func (a *Logger) GetAuditLogs(filters map[string]interface{}, limit int, offset int) ([]domain.AuditLog, int64, error) {
 	var logs []domain.AuditLog
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"backend/pkg/events"
+	"log"
+)
+
+// Subscribe records every domain event in the audit trail
+func (a *Logger) Subscribe(bus *events.Bus) {
+	bus.SubscribeAll(func(e events.Event) {
+		var actorID *uint
+		if e.ActorID != 0 {
+			id := e.ActorID
+			actorID = &id
+		}
+
+		err := a.LogAction(
+			e.EntityType,
+			e.EntityID,
+			string(e.Name),
+			actorID,
+			"",
+			"",
+			nil,
+			e.Data,
+			"",
+			"",
+			"",
+			"",
+		)
+		if err != nil {
+			log.Printf("failed to audit event %s: %v", e.Name, err)
+		}
+	})
+}
@@ -0,0 +1,11 @@
+package database
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the LIKE wildcards, and the escape character itself, so user input matches
+// literally inside a LIKE or ILIKE pattern
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,294 @@
+package database
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// MigrateTeamInvitations moves legacy pending team_members rows into team_invitations.
+// Safe to run on every start: it is a no-op once no pending member rows remain.
+func MigrateTeamInvitations(db *gorm.DB) error {
+	var pending []domain.TeamMember
+	if err := db.Where("invitation_status = ?", enums.InvitationStatusPending).Find(&pending).Error; err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range pending {
+			var team domain.Team
+			if err := tx.First(&team, m.TeamID).Error; err != nil {
+				continue
+			}
+
+			invitation := domain.TeamInvitation{
+				TeamID:    m.TeamID,
+				InviteeID: m.UserID,
+				InviterID: team.CreatedBy,
+				Status:    enums.InvitationStatusPending,
+			}
+			if err := tx.Create(&invitation).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("invitation_status = ?", enums.InvitationStatusPending).Delete(&domain.TeamMember{}).Error; err != nil {
+			return err
+		}
+
+		log.Printf("Migrated %d pending team member(s) to team_invitations", len(pending))
+		return nil
+	})
+}
+
+// legacyInvitationTTL matches teams.InvitationTTL, the validity invitations have been created with
+const legacyInvitationTTL = "7 days"
+
+// MigrateInvitationExpiry gives pending invitations created before invitations expired, including
+// those moved over from team_members, the usual validity counted from their creation, so the
+// cleanup job expires them like any other. Safe to run on every start.
+func MigrateInvitationExpiry(tx *gorm.DB) error {
+	result := tx.Exec(`UPDATE team_invitations SET expires_at = created_at + ?::interval
+		WHERE status = ? AND expires_at IS NULL`, legacyInvitationTTL, enums.InvitationStatusPending)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Set the expiry of %d pending team invitation(s)", result.RowsAffected)
+	}
+	return nil
+}
+
+// academicYearSQL is the academic year a timestamp falls in; years run from 1 September, so
+// 2025-10-01 falls in 2025/2026 and 2026-03-01 as well
+const academicYearSQL = `(EXTRACT(YEAR FROM %[1]s - INTERVAL '8 months')::int || '/' ||
+	(EXTRACT(YEAR FROM %[1]s - INTERVAL '8 months')::int + 1))`
+
+// MigrateProposalAcademicYears gives proposals created without an academic year the one they were
+// created in, so archiving a cohort reaches them, then teams still without a cohort the year of
+// their creation. Safe to run on every start.
+func MigrateProposalAcademicYears(tx *gorm.DB) error {
+	result := tx.Exec(`UPDATE proposals SET academic_year = ` + fmt.Sprintf(academicYearSQL, "proposals.created_at") + `
+		WHERE proposals.academic_year IS NULL OR proposals.academic_year = ''`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Assigned an academic year to %d proposal(s)", result.RowsAffected)
+	}
+
+	result = tx.Exec(`UPDATE teams SET academic_year = ` + fmt.Sprintf(academicYearSQL, "teams.created_at") + `
+		WHERE teams.academic_year IS NULL OR teams.academic_year = ''`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Assigned a cohort to %d team(s)", result.RowsAffected)
+	}
+	return nil
+}
+
+// MigrateTeamCohorts gives teams created before cohorts were tracked the academic year of their
+// latest proposal, or of their creator's university when they have none, and marks teams whose
+// proposals were all archived as archived. Safe to run on every start.
+func MigrateTeamCohorts(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`UPDATE teams SET academic_year = COALESCE(
+				(SELECT proposals.academic_year FROM proposals
+					WHERE proposals.team_id = teams.id AND proposals.academic_year <> ''
+					ORDER BY proposals.created_at DESC LIMIT 1),
+				(SELECT universities.academic_year FROM users
+					JOIN universities ON universities.id = users.university_id
+					WHERE users.id = teams.created_by),
+				'')
+			WHERE teams.academic_year IS NULL OR teams.academic_year = ''`)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Assigned a cohort to %d team(s)", result.RowsAffected)
+		}
+
+		return tx.Exec(`UPDATE teams SET is_archived = true, archived_at = (
+				SELECT MAX(proposals.archived_at) FROM proposals WHERE proposals.team_id = teams.id)
+			WHERE teams.is_archived = false
+				AND EXISTS (SELECT 1 FROM proposals WHERE proposals.team_id = teams.id)
+				AND NOT EXISTS (SELECT 1 FROM proposals WHERE proposals.team_id = teams.id AND proposals.is_archived = false)`).Error
+	})
+}
+
+// integrityIndexes are the unique indexes MigrateIntegrityConstraints adds, by model and index name
+var integrityIndexes = []struct {
+	model interface{}
+	name  string
+}{
+	{&domain.Proposal{}, "idx_proposal_active_team"},
+	{&domain.ProposalVersion{}, "idx_proposal_version_number"},
+	{&domain.ProjectReview{}, "idx_project_review_user"},
+}
+
+// integrityConstraints are the foreign keys MigrateIntegrityConstraints recreates with their ON DELETE rule
+var integrityConstraints = []struct {
+	model interface{}
+	name  string
+}{
+	{&domain.Proposal{}, "Versions"}, // versions go with their proposal
+	{&domain.Team{}, "Proposals"},    // a proposal outlives its team
+	{&domain.Team{}, "Members"},      // memberships go with their team
+	{&domain.Notification{}, "User"}, // notifications go with their user
+}
+
+// MigrateIntegrityConstraints adds the unique indexes behind the one-active-proposal-per-team,
+// version numbering and one-review-per-user rules, and the ON DELETE behaviour of the main foreign
+// keys. Duplicate reviews keep the newest and clashing version numbers are renumbered in order;
+// teams with several active proposals cannot be resolved automatically and stop the migration.
+func MigrateIntegrityConstraints(tx *gorm.DB) error {
+	result := tx.Exec(`DELETE FROM project_reviews a USING project_reviews b
+		WHERE a.project_id = b.project_id AND a.user_id = b.user_id AND a.id < b.id`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d duplicate project review(s)", result.RowsAffected)
+	}
+
+	result = tx.Exec(`UPDATE proposal_versions SET version_number = numbered.n
+		FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY proposal_id ORDER BY version_number, id) AS n
+			FROM proposal_versions
+			WHERE proposal_id IN (SELECT proposal_id FROM proposal_versions
+				GROUP BY proposal_id, version_number HAVING COUNT(*) > 1)) AS numbered
+		WHERE proposal_versions.id = numbered.id AND proposal_versions.version_number <> numbered.n`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Renumbered %d proposal version(s)", result.RowsAffected)
+	}
+
+	var teamIDs []uint
+	if err := tx.Model(&domain.Proposal{}).
+		Where("team_id IS NOT NULL AND is_archived = ? AND status <> ?", false, enums.ProposalStatusRejected).
+		Group("team_id").
+		Having("COUNT(*) > 1").
+		Pluck("team_id", &teamIDs).Error; err != nil {
+		return err
+	}
+	if len(teamIDs) > 0 {
+		return fmt.Errorf("teams %v have more than one active proposal; reject or archive the extra ones and migrate again", teamIDs)
+	}
+
+	for _, idx := range integrityIndexes {
+		if tx.Migrator().HasIndex(idx.model, idx.name) {
+			continue
+		}
+		if err := tx.Migrator().CreateIndex(idx.model, idx.name); err != nil {
+			return err
+		}
+	}
+
+	for _, fk := range integrityConstraints {
+		if tx.Migrator().HasConstraint(fk.model, fk.name) {
+			if err := tx.Migrator().DropConstraint(fk.model, fk.name); err != nil {
+				return err
+			}
+		}
+		if err := tx.Migrator().CreateConstraint(fk.model, fk.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropIntegrityIndexes is the rollback of MigrateIntegrityConstraints; the foreign keys keep their ON DELETE rule
+func DropIntegrityIndexes(tx *gorm.DB) error {
+	for _, idx := range integrityIndexes {
+		if !tx.Migrator().HasIndex(idx.model, idx.name) {
+			continue
+		}
+		if err := tx.Migrator().DropIndex(idx.model, idx.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userSearchColumns are the users columns the invitation search matches with ILIKE
+var userSearchColumns = []string{"name", "email", "student_id"}
+
+// MigrateUserSearchIndexes adds trigram indexes so ILIKE '%...%' searches on users do not scan the
+// table. pg_trgm needs privileges the application user may lack; without it the search still works.
+func MigrateUserSearchIndexes(tx *gorm.DB) error {
+	tx.SavePoint("pg_trgm")
+	if err := tx.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		tx.RollbackTo("pg_trgm")
+		log.Printf("pg_trgm is not available (%v); student search runs without trigram indexes", err)
+		return nil
+	}
+
+	for _, column := range userSearchColumns {
+		err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_users_" + column + "_trgm ON users USING gin (" + column + " gin_trgm_ops)").Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quarantineIndexes are the columns of the uploads served as static files, looked up on every
+// static download to refuse quarantined files
+var quarantineIndexes = map[string]string{
+	"proposal_versions":        "file_url",
+	"proposal_version_files":   "file_url",
+	"project_documentations":   "url",
+	"announcement_attachments": "url",
+}
+
+// MigrateQuarantineIndexes adds partial indexes covering only quarantined files, which stay few
+func MigrateQuarantineIndexes(tx *gorm.DB) error {
+	for table, column := range quarantineIndexes {
+		err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_" + table + "_quarantined ON " + table + " (" + column + ") " +
+			"WHERE scan_status IN ('pending', 'failed', 'infected')").Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateDocumentText creates the table of extracted proposal texts with a GIN index on their
+// English text search vector, and gives AI jobs the methodology they analyze. Queries must use
+// the same to_tsvector('english', content) expression for the index to apply.
+func MigrateDocumentText(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&domain.ProposalVersionText{}); err != nil {
+		return err
+	}
+	if !tx.Migrator().HasColumn(&domain.AIJob{}, "Methodology") {
+		if err := tx.Migrator().AddColumn(&domain.AIJob{}, "Methodology"); err != nil {
+			return err
+		}
+	}
+	return tx.Exec("CREATE INDEX IF NOT EXISTS idx_proposal_version_texts_search ON proposal_version_texts " +
+		"USING gin (to_tsvector('english', content))").Error
+}
+
+// MigrateOpenLeadershipVotes cancels the votes opened concurrently before a team could only have one
+// open vote; the newest stays open
+func MigrateOpenLeadershipVotes(tx *gorm.DB) error {
+	result := tx.Exec(`UPDATE leadership_votes SET status = ?, closed_at = NOW()
+		WHERE status = ? AND EXISTS (SELECT 1 FROM leadership_votes newer
+			WHERE newer.team_id = leadership_votes.team_id AND newer.status = ? AND newer.id > leadership_votes.id)`,
+		enums.LeadershipVoteCancelled, enums.LeadershipVoteOpen, enums.LeadershipVoteOpen)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Cancelled %d duplicate open leadership vote(s)", result.RowsAffected)
+	}
+	return nil
+}
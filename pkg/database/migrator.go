@@ -0,0 +1,251 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockKey serializes migration runs across processes starting at the same time
+const migrationLockKey = 727301
+
+// Migration is one versioned schema or data change. IDs sort in the order migrations run.
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error // nil when the migration cannot be rolled back
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	ID          string    `gorm:"primaryKey;type:varchar(100)" json:"id"`
+	Description string    `json:"description"`
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// MigrationStatus is a migration as known to this build and whether the database has it
+type MigrationStatus struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Applied     bool       `json:"applied"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+// SchemaStatus compares the database with the migrations and models this build expects
+type SchemaStatus struct {
+	Migrations []MigrationStatus `json:"migrations"`
+	Pending    int               `json:"pending"`
+	Unknown    []string          `json:"unknown"` // applied in the database but missing from this build
+	Drift      []string          `json:"drift"`   // tables or columns the models expect but the database lacks
+}
+
+// Migrator applies and rolls back Migrations and checks the schema for drift
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) *Migrator {
+	migrations := append([]Migration(nil), Migrations...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].ID < migrations[j].ID })
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// EnsureReady is the startup check. It applies pending migrations when autoMigrate is set and
+// refuses to continue when migrations are pending, the database is ahead of this build, or the
+// schema does not match the models.
+func (m *Migrator) EnsureReady(autoMigrate bool) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if len(status.Unknown) > 0 {
+		return fmt.Errorf("database has migrations this build does not know (%s); deploy a newer build or roll back",
+			strings.Join(status.Unknown, ", "))
+	}
+
+	if status.Pending > 0 {
+		if !autoMigrate {
+			return fmt.Errorf("%d pending migration(s); run `go run ./cmd/migrate up` or set DB_AUTO_MIGRATE=true", status.Pending)
+		}
+		if _, err := m.Up(); err != nil {
+			return err
+		}
+	}
+
+	drift, err := m.Drift()
+	if err != nil {
+		return err
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("schema drift detected, add a migration: %s", strings.Join(drift, "; "))
+	}
+	return nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction. Returns how many ran.
+func (m *Migrator) Up() (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	ran := 0
+	for _, migration := range m.migrations {
+		applied := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+			// Another process may have applied it while we waited for the lock
+			var count int64
+			if err := tx.Model(&SchemaMigration{}).Where("id = ?", migration.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			applied = true
+			return tx.Create(&SchemaMigration{ID: migration.ID, Description: migration.Description, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %s failed: %w", migration.ID, err)
+		}
+		if applied {
+			log.Printf("Applied migration %s: %s", migration.ID, migration.Description)
+			ran++
+		}
+	}
+	return ran, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first. Returns how many were rolled back.
+func (m *Migrator) Down(steps int) (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	var applied []SchemaMigration
+	if err := m.db.Order("id DESC").Limit(steps).Find(&applied).Error; err != nil {
+		return 0, err
+	}
+
+	byID := make(map[string]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byID[migration.ID] = migration
+	}
+
+	rolledBack := 0
+	for _, record := range applied {
+		migration, ok := byID[record.ID]
+		if !ok {
+			return rolledBack, fmt.Errorf("migration %s is not known to this build", record.ID)
+		}
+		if migration.Down == nil {
+			return rolledBack, fmt.Errorf("migration %s cannot be rolled back", record.ID)
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "id = ?", record.ID).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("rolling back %s failed: %w", record.ID, err)
+		}
+		log.Printf("Rolled back migration %s", record.ID)
+		rolledBack++
+	}
+	return rolledBack, nil
+}
+
+// Status lists every migration with whether it is applied, plus unknown migrations and drift
+func (m *Migrator) Status() (*SchemaStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var applied []SchemaMigration
+	if err := m.db.Order("id ASC").Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[string]time.Time, len(applied))
+	for _, record := range applied {
+		appliedAt[record.ID] = record.AppliedAt
+	}
+
+	status := &SchemaStatus{Migrations: []MigrationStatus{}, Unknown: []string{}}
+	known := make(map[string]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.ID] = true
+		entry := MigrationStatus{ID: migration.ID, Description: migration.Description}
+		if at, ok := appliedAt[migration.ID]; ok {
+			entry.Applied = true
+			entry.AppliedAt = &at
+		} else {
+			status.Pending++
+		}
+		status.Migrations = append(status.Migrations, entry)
+	}
+	for _, record := range applied {
+		if !known[record.ID] {
+			status.Unknown = append(status.Unknown, record.ID)
+		}
+	}
+
+	drift, err := m.Drift()
+	if err != nil {
+		return nil, err
+	}
+	status.Drift = drift
+	return status, nil
+}
+
+// Drift lists the tables and columns the domain models expect that the database does not have
+func (m *Migrator) Drift() ([]string, error) {
+	drift := []string{}
+	for _, model := range Models() {
+		stmt := &gorm.Statement{DB: m.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+
+		if !m.db.Migrator().HasTable(table) {
+			drift = append(drift, "missing table "+table)
+			continue
+		}
+		columns, err := m.db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			existing[column.Name()] = true
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if !existing[name] {
+				drift = append(drift, fmt.Sprintf("missing column %s.%s", table, name))
+			}
+		}
+	}
+	return drift, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.AutoMigrate(&SchemaMigration{})
+}
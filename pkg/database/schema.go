@@ -0,0 +1,892 @@
+package database
+
+import (
+	"backend/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Models lists every persisted domain model, in dependency order. The drift check compares
+// them with the database, so a new model or field also needs a migration below.
+func Models() []interface{} {
+	return []interface{}{
+		&domain.University{},
+		&domain.Department{},
+		&domain.User{},
+		&domain.Team{},
+		&domain.TeamMember{},
+		&domain.TeamInvitation{},
+		&domain.Proposal{},
+		&domain.ProposalVersion{},
+		&domain.ProposalVersionFile{},
+		&domain.TimelinePhase{},
+		&domain.ProposalArchiveMatch{},
+		&domain.Feedback{},
+		&domain.SecondOpinion{},
+		&domain.FeedbackTemplate{},
+		&domain.ReviewChecklistItem{},
+		&domain.Project{},
+		&domain.ProjectShareLink{},
+		&domain.ProjectDocumentation{},
+		&domain.GradingCriterion{},
+		&domain.ProjectExaminer{},
+		&domain.GradeSheet{},
+		&domain.ProjectGrade{},
+		&domain.ProjectReview{},
+		&domain.Notification{},
+		&domain.AuditLog{},
+		&domain.AIJob{},
+		&domain.Delegation{},
+		&domain.RolePermission{},
+		&domain.DashboardStat{},
+		&domain.DepartmentQuota{},
+		&domain.AdvisorWorkloadSnapshot{},
+		&domain.ProposalRules{},
+		&domain.SubmissionWindow{},
+		&domain.Announcement{},
+		&domain.AnnouncementAttachment{},
+		&domain.TeamTask{},
+		&domain.ConflictDeclaration{},
+		&domain.DataExport{},
+		&domain.AdvisorRequest{},
+		&domain.DeadlineExtension{},
+		&domain.UserSession{},
+		&domain.FailedJob{},
+		&domain.FeedbackAttachment{},
+		&domain.ProjectShare{},
+		&domain.MaintenanceState{},
+		&domain.RevokedToken{},
+		&domain.EmailChange{},
+		&domain.Setting{},
+		&domain.ProposalVersionText{},
+		&domain.LeadershipVote{},
+		&domain.LeadershipBallot{},
+		&domain.ReviewFilter{},
+		&domain.AdvisorMessage{},
+	}
+}
+
+// Migrations is the ordered history of schema and data changes. Never edit or remove an
+// applied migration; add a new one with the next number instead.
+var Migrations = []Migration{
+	{
+		// Creates the schema as of the switch to versioned migrations. On databases that were
+		// auto-migrated before, it only fills in what is missing.
+		ID:          "0001_baseline_schema",
+		Description: "Baseline schema from the domain models",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(Models()...)
+		},
+		Down: func(tx *gorm.DB) error {
+			models := Models()
+			for i := len(models) - 1; i >= 0; i-- {
+				if err := tx.Migrator().DropTable(models[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0002_team_invitations",
+		Description: "Move pending team members into team_invitations",
+		Up:          MigrateTeamInvitations,
+		Down:        keepData,
+	},
+	{
+		ID:          "0003_team_cohorts",
+		Description: "Backfill team cohorts and archive teams of archived cohorts",
+		Up:          MigrateTeamCohorts,
+		Down:        keepData,
+	},
+	{
+		ID:          "0004_department_quotas",
+		Description: "Per-cohort advisor and team quotas for departments",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.DepartmentQuota{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.DepartmentQuota{})
+		},
+	},
+	{
+		ID:          "0005_presence_privacy",
+		Description: "Let users hide their online status",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.User{}, "PresenceHidden") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.User{}, "PresenceHidden")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.User{}, "PresenceHidden")
+		},
+	},
+	{
+		ID:          "0006_user_sessions",
+		Description: "Login sessions behind access tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.UserSession{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.UserSession{})
+		},
+	},
+	{
+		ID:          "0007_failed_jobs",
+		Description: "Dead letter queue for failed background jobs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.FailedJob{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.FailedJob{})
+		},
+	},
+	{
+		ID:          "0008_second_opinions",
+		Description: "Second-opinion requests on proposal versions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.SecondOpinion{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.SecondOpinion{})
+		},
+	},
+	{
+		ID:          "0009_draft_autosave",
+		Description: "Track when a proposal draft was last saved",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.ProposalVersion{}, "LastSavedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.ProposalVersion{}, "LastSavedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.ProposalVersion{}, "LastSavedAt")
+		},
+	},
+	{
+		ID:          "0010_grading",
+		Description: "Grading rubric, project examiners, grade sheets and final grades",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.GradingCriterion{}, &domain.ProjectExaminer{}, &domain.GradeSheet{}, &domain.ProjectGrade{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.ProjectGrade{}, &domain.GradeSheet{}, &domain.ProjectExaminer{}, &domain.GradingCriterion{})
+		},
+	},
+	{
+		ID:          "0011_proposal_rules",
+		Description: "Per-department word count and heading rules for proposals",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.ProposalRules{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.ProposalRules{})
+		},
+	},
+	{
+		ID:          "0012_submission_windows",
+		Description: "Proposal submission windows and their deadline reminders",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.SubmissionWindow{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.SubmissionWindow{})
+		},
+	},
+	{
+		ID:          "0013_announcements",
+		Description: "Department announcements and their attachments",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.Announcement{}, &domain.AnnouncementAttachment{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.AnnouncementAttachment{}, &domain.Announcement{})
+		},
+	},
+	{
+		ID:          "0014_proposal_version_files",
+		Description: "History of attachments replaced on draft proposal versions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.ProposalVersionFile{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.ProposalVersionFile{})
+		},
+	},
+	{
+		ID:          "0015_team_tasks",
+		Description: "Team task checklists",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.TeamTask{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.TeamTask{})
+		},
+	},
+	{
+		ID:          "0016_conflict_declarations",
+		Description: "Advisor conflict-of-interest declarations",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.ConflictDeclaration{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.ConflictDeclaration{})
+		},
+	},
+	{
+		ID:          "0017_data_exports",
+		Description: "Personal data exports requested by users",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.DataExport{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.DataExport{})
+		},
+	},
+	{
+		ID:          "0018_user_anonymization",
+		Description: "Record when a user's personal data was erased",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.User{}, "AnonymizedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.User{}, "AnonymizedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.User{}, "AnonymizedAt")
+		},
+	},
+	{
+		ID:          "0019_blind_review",
+		Description: "Let universities hide students from advisors until the proposal is decided",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.University{}, "BlindReview") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.University{}, "BlindReview")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.University{}, "BlindReview")
+		},
+	},
+	{
+		ID:          "0020_advisor_requests",
+		Description: "Ranked advisor preferences asked in order",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.AdvisorRequest{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.AdvisorRequest{})
+		},
+	},
+	{
+		ID:          "0021_notification_grouping",
+		Description: "Thread repeated notifications about the same thing",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&domain.Notification{}, "GroupKey") {
+				if err := tx.Migrator().AddColumn(&domain.Notification{}, "GroupKey"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(&domain.Notification{}, "GroupKey"); err != nil {
+					return err
+				}
+			}
+			// Same key as notifications.GroupKey builds for new notifications
+			return tx.Model(&domain.Notification{}).
+				Where("group_key IS NULL OR group_key = ''").
+				Update("group_key", gorm.Expr("reference_type || ':' || reference_id || ':' || title")).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.Notification{}, "GroupKey")
+		},
+	},
+	{
+		ID:          "0022_deadline_extensions",
+		Description: "Team requests to submit after the submission window closes",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.DeadlineExtension{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.DeadlineExtension{})
+		},
+	},
+	{
+		ID:          "0023_integrity_constraints",
+		Description: "Unique active proposal per team, version numbers and reviews; ON DELETE rules",
+		Up:          MigrateIntegrityConstraints,
+		Down:        DropIntegrityIndexes,
+	},
+	{
+		ID:          "0024_user_search_trigrams",
+		Description: "Trigram indexes behind the student invitation search",
+		Up:          MigrateUserSearchIndexes,
+		Down: func(tx *gorm.DB) error {
+			for _, column := range userSearchColumns {
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_users_" + column + "_trgm").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0025_documentation_link_checks",
+		Description: "Record the reachability of code and deployed links",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range linkCheckFields {
+				if tx.Migrator().HasColumn(&domain.ProjectDocumentation{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.ProjectDocumentation{}, field); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&domain.ProjectDocumentation{}, "LinkStatus") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&domain.ProjectDocumentation{}, "LinkStatus")
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range linkCheckFields {
+				if err := tx.Migrator().DropColumn(&domain.ProjectDocumentation{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0026_feedback_attachments",
+		Description: "Annotated files attached to advisor feedback",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.FeedbackAttachment{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.FeedbackAttachment{})
+		},
+	},
+	{
+		ID:          "0027_project_shares",
+		Description: "Log project shares for deduplication and channel tracking",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.ProjectShare{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.ProjectShare{})
+		},
+	},
+	{
+		ID:          "0028_department_showcase",
+		Description: "Let departments opt in to the public project showcase",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.Department{}, "ShowcaseEnabled") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.Department{}, "ShowcaseEnabled")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.Department{}, "ShowcaseEnabled")
+		},
+	},
+	{
+		ID:          "0029_maintenance_state",
+		Description: "Maintenance flag admins can switch without a restart",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.MaintenanceState{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.MaintenanceState{})
+		},
+	},
+	{
+		ID:          "0030_advisor_auto_accept",
+		Description: "Let advisors accept team assignments automatically",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.User{}, "AdvisorAutoAccept") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.User{}, "AdvisorAutoAccept")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.User{}, "AdvisorAutoAccept")
+		},
+	},
+	{
+		ID:          "0031_version_file_retention",
+		Description: "Retention policy moving old proposal version files to cold storage",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&domain.University{}, "VersionFilesKept") {
+				if err := tx.Migrator().AddColumn(&domain.University{}, "VersionFilesKept"); err != nil {
+					return err
+				}
+			}
+			for _, model := range []interface{}{&domain.ProposalVersion{}, &domain.ProposalVersionFile{}} {
+				for _, field := range coldStorageFields {
+					if tx.Migrator().HasColumn(model, field) {
+						continue
+					}
+					if err := tx.Migrator().AddColumn(model, field); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&domain.ProposalVersion{}, &domain.ProposalVersionFile{}} {
+				for _, field := range coldStorageFields {
+					if err := tx.Migrator().DropColumn(model, field); err != nil {
+						return err
+					}
+				}
+			}
+			return tx.Migrator().DropColumn(&domain.University{}, "VersionFilesKept")
+		},
+	},
+	{
+		ID:          "0032_revoked_tokens",
+		Description: "Denylist revoking access tokens before they expire",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.RevokedToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.RevokedToken{})
+		},
+	},
+	{
+		ID:          "0033_department_ai_checker",
+		Description: "Per-department override of the university's AI checker flag",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.Department{}, "AICheckerEnabled") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.Department{}, "AICheckerEnabled")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.Department{}, "AICheckerEnabled")
+		},
+	},
+	{
+		ID:          "0034_project_description",
+		Description: "Markdown project description with its rendered HTML",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range []string{"Description", "DescriptionHTML"} {
+				if tx.Migrator().HasColumn(&domain.Project{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.Project{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range []string{"Description", "DescriptionHTML"} {
+				if err := tx.Migrator().DropColumn(&domain.Project{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0035_proposal_abandonment",
+		Description: "Auto-close rule for revision requests the team stopped answering",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&domain.University{}, "AbandonAfterDays") {
+				if err := tx.Migrator().AddColumn(&domain.University{}, "AbandonAfterDays"); err != nil {
+					return err
+				}
+			}
+			for _, field := range abandonmentFields {
+				if tx.Migrator().HasColumn(&domain.Proposal{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.Proposal{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range abandonmentFields {
+				if err := tx.Migrator().DropColumn(&domain.Proposal{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropColumn(&domain.University{}, "AbandonAfterDays")
+		},
+	},
+	{
+		ID:          "0036_proposal_archive_matches",
+		Description: "Archived projects similar to a proposal, found on submission",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&domain.Proposal{}, "ArchiveCheckedAt") {
+				if err := tx.Migrator().AddColumn(&domain.Proposal{}, "ArchiveCheckedAt"); err != nil {
+					return err
+				}
+			}
+			return tx.AutoMigrate(&domain.ProposalArchiveMatch{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&domain.ProposalArchiveMatch{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&domain.Proposal{}, "ArchiveCheckedAt")
+		},
+	},
+	{
+		ID:          "0037_advisor_workload_snapshots",
+		Description: "Weekly advisor workload snapshots",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.AdvisorWorkloadSnapshot{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.AdvisorWorkloadSnapshot{})
+		},
+	},
+	{
+		ID:          "0038_project_continuation",
+		Description: "Projects continued by a later cohort, with the documentation carried over",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&domain.Proposal{}, "ContinuesProjectID") {
+				if err := tx.Migrator().AddColumn(&domain.Proposal{}, "ContinuesProjectID"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(&domain.Proposal{}, "ContinuesProjectID"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasColumn(&domain.Project{}, "PreviousProjectID") {
+				if err := tx.Migrator().AddColumn(&domain.Project{}, "PreviousProjectID"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().CreateIndex(&domain.Project{}, "PreviousProjectID"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&domain.ProjectDocumentation{}, "CarriedOverFromID") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.ProjectDocumentation{}, "CarriedOverFromID")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&domain.ProjectDocumentation{}, "CarriedOverFromID"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&domain.Project{}, "PreviousProjectID"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&domain.Proposal{}, "ContinuesProjectID")
+		},
+	},
+	{
+		ID:          "0039_file_quarantine",
+		Description: "Indexes behind the download check of quarantined uploads",
+		Up:          MigrateQuarantineIndexes,
+		Down: func(tx *gorm.DB) error {
+			for table := range quarantineIndexes {
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_" + table + "_quarantined").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0040_feedback_quality",
+		Description: "Time spent on and word count of feedback; the word count is backfilled from the comments",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range feedbackQualityFields {
+				if tx.Migrator().HasColumn(&domain.Feedback{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.Feedback{}, field); err != nil {
+					return err
+				}
+			}
+			// Older comments include the templates they were composed from
+			return tx.Exec(`UPDATE feedbacks SET word_count = COALESCE(array_length(regexp_split_to_array(btrim(comment), '\s+'), 1), 0)
+				WHERE word_count = 0 AND btrim(comment) <> ''`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range feedbackQualityFields {
+				if err := tx.Migrator().DropColumn(&domain.Feedback{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0041_email_changes",
+		Description: "Pending and confirmed email address changes",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.EmailChange{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.EmailChange{})
+		},
+	},
+	{
+		ID:          "0042_advisor_acceptance",
+		Description: "Acceptance deadline and escalation of proposal advisor assignments",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range advisorAcceptanceFields {
+				if tx.Migrator().HasColumn(&domain.Proposal{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.Proposal{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range advisorAcceptanceFields {
+				if err := tx.Migrator().DropColumn(&domain.Proposal{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0043_settings",
+		Description: "Typed system settings with per-university overrides",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.Setting{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.Setting{})
+		},
+	},
+	{
+		ID:          "0044_examiner_accounts",
+		Description: "Time-boxed guest accounts for external examiners",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range examinerAccountFields {
+				if tx.Migrator().HasColumn(&domain.User{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.User{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range examinerAccountFields {
+				if err := tx.Migrator().DropColumn(&domain.User{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0045_proposal_document_text",
+		Description: "Text extracted from proposal PDFs for full-text search and AI analysis",
+		Up:          MigrateDocumentText,
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&domain.AIJob{}, "Methodology"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&domain.ProposalVersionText{})
+		},
+	},
+	{
+		ID:          "0046_leadership_votes",
+		Description: "Member votes and admin appointments replacing an absent team leader",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&domain.LeadershipVote{}, &domain.LeadershipBallot{}); err != nil {
+				return err
+			}
+			if tx.Migrator().HasColumn(&domain.Team{}, "LeaderAbsentNotifiedAt") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.Team{}, "LeaderAbsentNotifiedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&domain.Team{}, "LeaderAbsentNotifiedAt"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&domain.LeadershipBallot{}, &domain.LeadershipVote{})
+		},
+	},
+	{
+		ID:          "0047_review_moderation",
+		Description: "Banned-word filters and a moderation queue for public project reviews",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&domain.ReviewFilter{}); err != nil {
+				return err
+			}
+			// Existing reviews were already public and take the approved default
+			for _, field := range reviewModerationFields {
+				if tx.Migrator().HasColumn(&domain.ProjectReview{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.ProjectReview{}, field); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&domain.ProjectReview{}, "ModerationStatus") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&domain.ProjectReview{}, "ModerationStatus")
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range reviewModerationFields {
+				if err := tx.Migrator().DropColumn(&domain.ProjectReview{}, field); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable(&domain.ReviewFilter{})
+		},
+	},
+	{
+		ID:          "0048_advisor_messages",
+		Description: "Messages from the department head to filtered groups of advisors",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&domain.AdvisorMessage{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&domain.AdvisorMessage{})
+		},
+	},
+	{
+		ID:          "0049_project_publish_schedule",
+		Description: "Scheduled publication of projects once their grade is locked",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range publishScheduleFields {
+				if tx.Migrator().HasColumn(&domain.Project{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.Project{}, field); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&domain.Project{}, "PublishAt") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&domain.Project{}, "PublishAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range publishScheduleFields {
+				if err := tx.Migrator().DropColumn(&domain.Project{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0050_proposal_second_reviewer",
+		Description: "Co-reviewer whose approval a proposal also needs",
+		Up: func(tx *gorm.DB) error {
+			for _, field := range secondReviewerFields {
+				if tx.Migrator().HasColumn(&domain.Proposal{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&domain.Proposal{}, field); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&domain.Proposal{}, "SecondReviewerID") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&domain.Proposal{}, "SecondReviewerID")
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range secondReviewerFields {
+				if err := tx.Migrator().DropColumn(&domain.Proposal{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0051_invitation_expiry",
+		Description: "Backfill the expiry of pending invitations created without one",
+		Up:          MigrateInvitationExpiry,
+		Down:        keepData,
+	},
+	{
+		ID:          "0052_proposal_academic_years",
+		Description: "Backfill the academic year of proposals and teams from their creation date",
+		Up:          MigrateProposalAcademicYears,
+		Down:        keepData,
+	},
+	{
+		ID:          "0053_advisor_no_conflicts",
+		Description: "Blanket no-conflict confirmation of auto-accepting advisors",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&domain.User{}, "AdvisorNoConflictAt") {
+				if err := tx.Migrator().AddColumn(&domain.User{}, "AdvisorNoConflictAt"); err != nil {
+					return err
+				}
+			}
+			// Turning auto-accept on always required the confirmation
+			return tx.Exec(`UPDATE users SET advisor_no_conflict_at = updated_at
+				WHERE advisor_auto_accept = true AND advisor_no_conflict_at IS NULL`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.User{}, "AdvisorNoConflictAt")
+		},
+	},
+	{
+		ID:          "0054_leadership_vote_open_team",
+		Description: "One open leadership vote per team",
+		Up: func(tx *gorm.DB) error {
+			if err := MigrateOpenLeadershipVotes(tx); err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&domain.LeadershipVote{}, "idx_leadership_vote_open_team") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&domain.LeadershipVote{}, "idx_leadership_vote_open_team")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropIndex(&domain.LeadershipVote{}, "idx_leadership_vote_open_team")
+		},
+	},
+	{
+		ID:          "0055_project_documentation_hash",
+		Description: "Content hash of project documents, served as their ETag",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&domain.ProjectDocumentation{}, "FileHash") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&domain.ProjectDocumentation{}, "FileHash")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&domain.ProjectDocumentation{}, "FileHash")
+		},
+	},
+}
+
+var secondReviewerFields = []string{"SecondReviewerID", "SecondReviewerAssignedAt"}
+
+var publishScheduleFields = []string{"PublishAt", "PublishScheduledBy"}
+
+var reviewModerationFields = []string{"ModerationStatus", "FlaggedTerms", "ModeratedBy", "ModeratedAt", "ModerationNote"}
+
+var examinerAccountFields = []string{"AccessExpiresAt", "Affiliation"}
+
+var advisorAcceptanceFields = []string{"AdvisorAssignedAt", "AdvisorAcceptBy", "AdvisorAcceptedAt", "AdvisorEscalatedAt"}
+
+var feedbackQualityFields = []string{"TimeSpentMinutes", "WordCount"}
+
+var abandonmentFields = []string{"AbandonWarnings", "AbandonWarnedAt", "AbandonedAt", "ReopenedAt"}
+
+var linkCheckFields = []string{"LinkStatus", "LinkHTTPStatus", "LinkTitle", "LinkError", "LinkFailures", "LinkCheckedAt"}
+
+var coldStorageFields = []string{"ArchivedAt", "ArchivePath", "RestoredAt"}
+
+// keepData is the rollback of a data-only migration: the moved or backfilled data stays as it is
+func keepData(tx *gorm.DB) error {
+	return nil
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// BulkSeedOptions sizes a bulk seeding run. Universities and departments are named
+// "Load Test University <n>" and "LT<u>D<d>", so runs against the same database add to them.
+type BulkSeedOptions struct {
+	Universities             int
+	DepartmentsPerUniversity int
+	AdvisorsPerDept          int
+	TeamsPerDept             int
+	MembersPerTeam           int
+	Files                    bool   // write a PDF for every proposal version
+	UploadDir                string // where files go; served as /uploads
+	Seed                     int64  // varies the generated data between datasets
+}
+
+// bulkStageWeights is the share of teams at each stage, roughly what a department looks like
+// mid-semester: most teams are writing or in review, fewer are approved, rejected or still forming
+var bulkStageWeights = []int{
+	stageForming:          8,
+	stageDraft:            14,
+	stageSubmitted:        14,
+	stageUnderReview:      16,
+	stageRevisionRequired: 14,
+	stageApproved:         12,
+	stagePublished:        14,
+	stageRejected:         8,
+}
+
+// SeedBulk generates universities and departments with students, advisors, teams and proposals
+// for performance testing. Stages are drawn at random with realistic weights rather than cycled.
+// Like the demo profile it is deterministic for a given seed and only adds what is missing.
+func SeedBulk(db *gorm.DB, opts BulkSeedOptions) error {
+	if opts.Universities < 1 || opts.DepartmentsPerUniversity < 1 || opts.AdvisorsPerDept < 1 || opts.MembersPerTeam < 1 {
+		return errors.New("universities, departments, advisors and members must be at least 1")
+	}
+	if opts.TeamsPerDept < 0 {
+		return errors.New("teams must not be negative")
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(demoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	g := &demoGenerator{
+		password: string(hashed),
+		now:      time.Now(),
+		seed:     opts.Seed,
+		weights:  bulkStageWeights,
+	}
+	if opts.Files {
+		g.files = &proposalFiles{dir: opts.UploadDir}
+	}
+	size := seedSize{
+		Departments:     opts.DepartmentsPerUniversity,
+		AdvisorsPerDept: opts.AdvisorsPerDept,
+		TeamsPerDept:    opts.TeamsPerDept,
+		MembersPerTeam:  opts.MembersPerTeam,
+	}
+
+	started := time.Now()
+	total := 0
+	for u := 1; u <= opts.Universities; u++ {
+		university := domain.University{
+			Name:           fmt.Sprintf("Load Test University %d", u),
+			AcademicYear:   "2025/2026",
+			ProjectPeriod:  "Semester 2",
+			VisibilityRule: "private",
+		}
+		if err := db.Where("name = ?", university.Name).FirstOrCreate(&university).Error; err != nil {
+			return err
+		}
+		g.university = university
+
+		for d := 1; d <= opts.DepartmentsPerUniversity; d++ {
+			dept := domain.Department{
+				Name:         fmt.Sprintf("Load Test Department %d.%d", u, d),
+				Code:         fmt.Sprintf("LT%dD%d", u, d),
+				UniversityID: university.ID,
+			}
+			if err := db.Where("university_id = ? AND code = ?", university.ID, dept.Code).FirstOrCreate(&dept).Error; err != nil {
+				return err
+			}
+
+			created, err := g.seedDepartment(db, dept, size)
+			if err != nil {
+				return fmt.Errorf("seeding %s: %w", dept.Code, err)
+			}
+			total += created
+			if created > 0 {
+				log.Printf("✓ Created %d team(s) in %s", created, dept.Code)
+			}
+		}
+	}
+
+	log.Printf("Bulk seeding created %d team(s) in %s", total, time.Since(started).Round(time.Second))
+	log.Printf("Accounts: <code>.t<n>.m<n>@%s and <code>.advisor<n>@%s / %s", demoEmailDomain, demoEmailDomain, demoPassword)
+	return nil
+}
+
+// proposalFiles writes generated proposal documents into the upload directory
+type proposalFiles struct {
+	dir string
+}
+
+// attach writes a small PDF for the version and records it the way an upload would
+func (f *proposalFiles) attach(tx *gorm.DB, version *domain.ProposalVersion, rng *rand.Rand) error {
+	pages := rng.Intn(12) + 4
+	content := generatedPDF(version.Title, pages)
+
+	name := fmt.Sprintf("v%d.pdf", version.VersionNumber)
+	subDir := filepath.Join("proposals", fmt.Sprint(version.ProposalID))
+	if err := os.MkdirAll(filepath.Join(f.dir, subDir), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(f.dir, subDir, name), content, 0o644); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	url := filepath.Join("uploads", subDir, name)
+	return tx.Model(version).Updates(map[string]interface{}{
+		"file_url":              url,
+		"file_hash":             hex.EncodeToString(sum[:]),
+		"file_size_bytes":       int64(len(content)),
+		"declared_content_type": "application/pdf",
+		"detected_mime":         "application/pdf",
+		"page_count":            pages,
+		"scan_status":           enums.ScanStatusClean,
+	}).Error
+}
+
+// generatedPDF renders a minimal, valid PDF with the title on each of its pages
+func generatedPDF(title string, pages int) []byte {
+	var buf bytes.Buffer
+	offsets := make([]int, 0, 3+2*pages)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]byte, 0, pages*8)
+	for p := 0; p < pages; p++ {
+		kids = fmt.Appendf(kids, "%d 0 R ", 4+2*p)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for p := 0; p < pages; p++ {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*p))
+		text := fmt.Sprintf("BT /F1 14 Tf 72 770 Td (%s - page %d) Tj ET", pdfEscape(title), p+1)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(text), text))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfEscape(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		switch r {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+		}
+		if r < 128 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
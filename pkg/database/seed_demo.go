@@ -0,0 +1,394 @@
+package database
+
+import (
+	"backend/internal/domain"
+	"backend/pkg/enums"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// demoEmailDomain marks generated accounts; they all share demoPassword
+const (
+	demoEmailDomain = "demo.astu.edu.et"
+	demoPassword    = "Demo@123"
+)
+
+type seedSize struct {
+	Departments     int
+	AdvisorsPerDept int
+	TeamsPerDept    int
+	MembersPerTeam  int
+}
+
+var (
+	demoSize     = seedSize{Departments: 2, AdvisorsPerDept: 3, TeamsPerDept: 16, MembersPerTeam: 4}
+	loadTestSize = seedSize{Departments: 5, AdvisorsPerDept: 10, TeamsPerDept: 120, MembersPerTeam: 4}
+)
+
+// demoStage is how far a generated team has got; teams cycle through every stage
+type demoStage int
+
+const (
+	stageForming demoStage = iota // no proposal yet, one invitation pending
+	stageDraft
+	stageSubmitted
+	stageUnderReview
+	stageRevisionRequired
+	stageApproved
+	stagePublished
+	stageRejected
+	stageCount
+)
+
+var (
+	firstNames = []string{"Abebe", "Almaz", "Bethlehem", "Biniam", "Dawit", "Eden", "Eyerusalem", "Fitsum", "Hana", "Henok",
+		"Kalkidan", "Kidus", "Liya", "Mahlet", "Meron", "Nahom", "Naod", "Rahel", "Robel", "Samrawit",
+		"Selam", "Tsion", "Yared", "Yonas", "Zelalem", "Mekdes", "Natnael", "Ruth", "Abel", "Saron"}
+	lastNames = []string{"Tesfaye", "Bekele", "Haile", "Girma", "Alemu", "Tadesse", "Kebede", "Mekonnen", "Assefa", "Wolde",
+		"Desta", "Getachew", "Negash", "Abera", "Mulugeta", "Tilahun", "Lemma", "Ayele", "Fikre", "Worku"}
+	advisorTitles = []string{"Dr.", "Dr.", "Mr.", "Ms."}
+	skills        = []string{"go", "react", "python", "postgresql", "docker", "flutter", "pytorch", "figma", "latex", "kotlin"}
+	topics        = []struct{ Title, Problem string }{
+		{"Smart Irrigation Scheduling with Soil Moisture Sensors", "Smallholder farms over- or under-water crops because irrigation follows fixed schedules."},
+		{"Amharic Handwriting Recognition for Archive Digitization", "Handwritten Amharic records in public archives cannot be searched."},
+		{"Campus Lost and Found Mobile Platform", "Items lost on campus are rarely returned because there is no shared registry."},
+		{"Predicting Student Dropout Risk from LMS Activity", "Advisors notice struggling students too late to intervene."},
+		{"Blockchain-Based Land Title Registry", "Paper land titles are easy to forge and slow to verify."},
+		{"Telemedicine Triage Chatbot for Rural Clinics", "Rural clinics lack staff to triage patients before consultations."},
+		{"Real-Time Bus Tracking for Addis Ababa Commuters", "Commuters wait without knowing when the next bus arrives."},
+		{"Energy Consumption Dashboard for University Buildings", "Facilities teams cannot see which buildings waste electricity."},
+		{"Automated Grading of Programming Assignments", "Instructors spend hours grading repetitive programming exercises."},
+		{"Crop Disease Detection from Leaf Images", "Farmers identify crop diseases too late to prevent losses."},
+		{"Secure Electronic Voting for Student Unions", "Student union elections are slow to count and hard to audit."},
+		{"Library Seat Reservation System", "Students waste time searching for free seats during exams."},
+		{"Traffic Sign Recognition for Driver Assistance", "Drivers miss speed limit and warning signs on unfamiliar roads."},
+		{"Inventory Management for Pharmacies", "Pharmacies run out of essential drugs because stock is tracked on paper."},
+		{"Sign Language Translation Using Pose Estimation", "Deaf students struggle to follow lectures without interpreters."},
+		{"Microgrid Load Forecasting", "Off-grid solar microgrids shed load because demand is not forecast."},
+	}
+)
+
+// seedDemo generates departments' worth of students, advisors and teams with proposals in every
+// state, including approved and published projects. Data is deterministic, and departments that
+// already have their generated teams are skipped, so reruns and larger profiles only add what is missing.
+func seedDemo(db *gorm.DB, size seedSize) error {
+	var university domain.University
+	if err := db.Order("id ASC").First(&university).Error; err != nil {
+		return err
+	}
+	var departments []domain.Department
+	if err := db.Where("university_id = ?", university.ID).Order("id ASC").Limit(size.Departments).Find(&departments).Error; err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(demoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	g := &demoGenerator{
+		university: university,
+		password:   string(hashed),
+		now:        time.Now(),
+	}
+
+	for _, dept := range departments {
+		created, err := g.seedDepartment(db, dept, size)
+		if err != nil {
+			return fmt.Errorf("seeding demo data for %s: %w", dept.Code, err)
+		}
+		if created > 0 {
+			log.Printf("✓ Created %d demo team(s) in %s", created, dept.Code)
+		}
+	}
+
+	log.Printf("Demo accounts: <name>@%s / %s", demoEmailDomain, demoPassword)
+	return nil
+}
+
+type demoGenerator struct {
+	university domain.University
+	password   string
+	now        time.Time
+	rng        *rand.Rand
+	seed       int64          // added to the department ID to seed rng
+	weights    []int          // relative share of each demoStage; nil cycles through them
+	files      *proposalFiles // writes a document for every version when set
+}
+
+func (g *demoGenerator) seedDepartment(db *gorm.DB, dept domain.Department, size seedSize) (int, error) {
+	prefix := strings.ToLower(dept.Code)
+
+	// Resume after the teams generated by an earlier, smaller run
+	var existing int64
+	if err := db.Model(&domain.Team{}).
+		Joins("JOIN users ON users.id = teams.created_by").
+		Where("teams.department_id = ? AND users.email LIKE ?", dept.ID, "%@"+demoEmailDomain).
+		Count(&existing).Error; err != nil {
+		return 0, err
+	}
+	if int(existing) >= size.TeamsPerDept {
+		return 0, nil
+	}
+
+	// Same seed per department, so generated data is the same on every machine
+	g.rng = rand.New(rand.NewSource(g.seed + int64(dept.ID)))
+
+	created := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		advisors := make([]domain.User, 0, size.AdvisorsPerDept)
+		for i := 0; i < size.AdvisorsPerDept; i++ {
+			advisor, err := g.user(tx, dept, fmt.Sprintf("%s.advisor%d", prefix, i+1), enums.RoleAdvisor,
+				advisorTitles[i%len(advisorTitles)]+" "+g.name(i*7+3), "")
+			if err != nil {
+				return err
+			}
+			advisors = append(advisors, *advisor)
+		}
+
+		for t := int(existing); t < size.TeamsPerDept; t++ {
+			if err := g.team(tx, dept, prefix, t, size.MembersPerTeam, advisors[t%len(advisors)]); err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	return created, err
+}
+
+// user returns the generated account with the given email handle, creating it if needed
+func (g *demoGenerator) user(tx *gorm.DB, dept domain.Department, handle string, role enums.Role, name string, studentID string) (*domain.User, error) {
+	user := domain.User{
+		Name:          name,
+		Email:         handle + "@" + demoEmailDomain,
+		Password:      g.password,
+		Role:          role,
+		UniversityID:  g.university.ID,
+		DepartmentID:  dept.ID,
+		StudentID:     studentID,
+		IsActive:      true,
+		EmailVerified: true,
+	}
+	err := tx.Where("email = ?", user.Email).FirstOrCreate(&user).Error
+	return &user, err
+}
+
+func (g *demoGenerator) name(i int) string {
+	return firstNames[i%len(firstNames)] + " " + lastNames[(i/len(firstNames)+i)%len(lastNames)]
+}
+
+// stage picks how far a team has got: the next stage in turn, or a weighted draw
+func (g *demoGenerator) stage(index int) demoStage {
+	if len(g.weights) == 0 {
+		return demoStage(index % int(stageCount))
+	}
+	total := 0
+	for _, w := range g.weights {
+		total += w
+	}
+	n := g.rng.Intn(total)
+	for s, w := range g.weights {
+		if n < w {
+			return demoStage(s)
+		}
+		n -= w
+	}
+	return stageDraft
+}
+
+func (g *demoGenerator) daysAgo(maxDays int) time.Time {
+	return g.now.Add(-time.Duration(g.rng.Intn(maxDays*24)+1) * time.Hour)
+}
+
+func (g *demoGenerator) team(tx *gorm.DB, dept domain.Department, prefix string, index int, membersPerTeam int, advisor domain.User) error {
+	stage := g.stage(index)
+	topic := topics[(index+int(dept.ID))%len(topics)]
+	createdAt := g.daysAgo(150)
+
+	members := make([]domain.User, 0, membersPerTeam)
+	for m := 0; m < membersPerTeam; m++ {
+		handle := fmt.Sprintf("%s.t%03d.m%d", prefix, index+1, m+1)
+		studentID := fmt.Sprintf("ETS%04d/14", int(dept.ID)*1000+index*membersPerTeam+m)
+		student, err := g.user(tx, dept, handle, enums.RoleStudent, g.name(index*membersPerTeam+m+int(dept.ID)*11), studentID)
+		if err != nil {
+			return err
+		}
+		members = append(members, *student)
+	}
+	leader := members[0]
+
+	team := domain.Team{
+		Name:         fmt.Sprintf("%s Team %d", strings.Fields(topic.Title)[0], index+1),
+		DepartmentID: dept.ID,
+		CreatedBy:    leader.ID,
+		IsFinalized:  stage != stageForming,
+		AcademicYear: g.university.AcademicYear,
+		CreatedAt:    createdAt,
+	}
+	if stage >= stageUnderReview {
+		team.AdvisorID = &advisor.ID
+	}
+	if err := tx.Create(&team).Error; err != nil {
+		return err
+	}
+
+	// A forming team has only its leader and some invitations still open
+	joined := members
+	if stage == stageForming {
+		joined = members[:1]
+	}
+	for m, member := range joined {
+		role := "member"
+		if m == 0 {
+			role = "leader"
+		}
+		row := domain.TeamMember{
+			TeamID:           team.ID,
+			UserID:           member.ID,
+			Role:             role,
+			InvitationStatus: enums.InvitationStatusAccepted,
+			FunctionalRole:   enums.FunctionalRoles[m%len(enums.FunctionalRoles)],
+			Skills:           []string{skills[(index+m)%len(skills)], skills[(index+m+3)%len(skills)]},
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+	}
+	if stage == stageForming {
+		expires := g.now.Add(5 * 24 * time.Hour)
+		for _, invitee := range members[1:] {
+			invitation := domain.TeamInvitation{
+				TeamID:    team.ID,
+				InviteeID: invitee.ID,
+				InviterID: leader.ID,
+				Status:    enums.InvitationStatusPending,
+				Message:   "Join us for our capstone project!",
+				ExpiresAt: &expires,
+			}
+			if err := tx.Create(&invitation).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return g.proposal(tx, team, leader, advisor, topic.Title, topic.Problem, stage, createdAt)
+}
+
+func (g *demoGenerator) proposal(tx *gorm.DB, team domain.Team, leader, advisor domain.User, title, problem string, stage demoStage, createdAt time.Time) error {
+	status := map[demoStage]enums.ProposalStatus{
+		stageDraft:            enums.ProposalStatusDraft,
+		stageSubmitted:        enums.ProposalStatusSubmitted,
+		stageUnderReview:      enums.ProposalStatusUnderReview,
+		stageRevisionRequired: enums.ProposalStatusRevisionRequired,
+		stageApproved:         enums.ProposalStatusApproved,
+		stagePublished:        enums.ProposalStatusApproved,
+		stageRejected:         enums.ProposalStatusRejected,
+	}[stage]
+
+	proposal := domain.Proposal{
+		TeamID:       &team.ID,
+		Status:       status,
+		CreatedBy:    leader.ID,
+		AcademicYear: team.AcademicYear,
+		CreatedAt:    createdAt,
+	}
+	if stage >= stageUnderReview {
+		proposal.AdvisorID = &advisor.ID
+	}
+	if err := tx.Create(&proposal).Error; err != nil {
+		return err
+	}
+
+	// Approved proposals went through one revision first
+	versions := 1
+	if stage == stageApproved || stage == stagePublished {
+		versions = 2
+	}
+	var last domain.ProposalVersion
+	for v := 1; v <= versions; v++ {
+		last = domain.ProposalVersion{
+			ProposalID:       proposal.ID,
+			Title:            title,
+			Abstract:         fmt.Sprintf("This project addresses a practical problem: %s We design, build and evaluate a working system with real users.", problem),
+			ProblemStatement: problem,
+			Objectives:       "Gather requirements from stakeholders; design the system architecture; implement a working prototype; evaluate it with real users.",
+			Methodology:      "Iterative development in two-week sprints with user testing at the end of each sprint.",
+			ExpectedTimeline: "Requirements (3 weeks), design (3 weeks), implementation (8 weeks), evaluation and report (4 weeks).",
+			ExpectedOutcomes: "A deployed prototype, an evaluation report and the final thesis.",
+			VersionNumber:    v,
+			IsApproved:       v == versions && (stage == stageApproved || stage == stagePublished),
+			CreatedBy:        leader.ID,
+			CreatedAt:        createdAt.Add(time.Duration(v-1) * 10 * 24 * time.Hour),
+		}
+		if err := tx.Create(&last).Error; err != nil {
+			return err
+		}
+		if g.files != nil {
+			if err := g.files.attach(tx, &last, g.rng); err != nil {
+				return err
+			}
+		}
+
+		decision := domain.FeedbackDecision("")
+		comment := ""
+		var resubmitBy *time.Time
+		switch {
+		case v < versions:
+			decision, comment = domain.FeedbackDecisionRevise, "Narrow the scope and describe how you will evaluate the prototype."
+		case stage == stageRevisionRequired:
+			due := g.now.Add(time.Duration(g.rng.Intn(10)+1) * 24 * time.Hour)
+			decision, comment, resubmitBy = domain.FeedbackDecisionRevise, "Good idea, but the methodology needs more detail on data collection.", &due
+		case stage == stageApproved || stage == stagePublished:
+			decision, comment = domain.FeedbackDecisionApprove, "Well scoped and clearly motivated. Approved."
+		case stage == stageRejected:
+			decision, comment = domain.FeedbackDecisionReject, "This overlaps heavily with an existing project in the archive."
+		}
+		if decision != "" {
+			feedback := domain.Feedback{
+				ProposalID:        proposal.ID,
+				ProposalVersionID: last.ID,
+				ReviewerID:        advisor.ID,
+				Decision:          decision,
+				Comment:           comment,
+				WordCount:         len(strings.Fields(comment)),
+				ResubmitBy:        resubmitBy,
+				CreatedAt:         last.CreatedAt.Add(3 * 24 * time.Hour),
+			}
+			if err := tx.Create(&feedback).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if stage != stageApproved && stage != stagePublished {
+		return nil
+	}
+
+	project := domain.Project{
+		ProposalID:   proposal.ID,
+		TeamID:       team.ID,
+		DepartmentID: team.DepartmentID,
+		Summary:      last.Abstract,
+		ApprovedBy:   advisor.ID,
+		Visibility:   "private",
+		CreatedAt:    last.CreatedAt.Add(3 * 24 * time.Hour),
+	}
+	if stage == stagePublished {
+		published := project.CreatedAt.Add(30 * 24 * time.Hour)
+		if published.After(g.now) {
+			published = g.now
+		}
+		project.Visibility = "public"
+		project.PublishedAt = &published
+		project.ViewCount = g.rng.Intn(500)
+		project.ShareCount = g.rng.Intn(40)
+	}
+	return tx.Create(&project).Error
+}
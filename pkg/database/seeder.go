@@ -9,8 +9,37 @@ import (
 	"gorm.io/gorm"
 )
 
-// SeedDatabase seeds the database with initial data
-func SeedDatabase(db *gorm.DB) error {
+// Seed profiles, selected with SEED_PROFILE
+const (
+	SeedProfileNone     = "none"      // seed nothing
+	SeedProfileMinimal  = "minimal"   // university, departments and one account per role
+	SeedProfileDemo     = "demo"      // minimal plus a realistic dataset for frontend work
+	SeedProfileLoadTest = "load-test" // the demo dataset at scale
+)
+
+// SeedDatabase seeds the data of the given profile. Each part is seeded once; rerunning
+// with a larger profile adds what is missing.
+func SeedDatabase(db *gorm.DB, profile string) error {
+	if profile == SeedProfileNone {
+		log.Println("Seeding disabled")
+		return nil
+	}
+
+	if err := seedMinimal(db); err != nil {
+		return err
+	}
+
+	switch profile {
+	case SeedProfileDemo:
+		return seedDemo(db, demoSize)
+	case SeedProfileLoadTest:
+		return seedDemo(db, loadTestSize)
+	}
+	return nil
+}
+
+// seedMinimal creates the university, departments and the default accounts
+func seedMinimal(db *gorm.DB) error {
 	log.Println("Checking for seed data...")
 
 	// Check if university already exists
@@ -0,0 +1,144 @@
+package deadletter
+
+import (
+	"backend/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound  = errors.New("failed job not found")
+	ErrNoRetrier = errors.New("jobs of this kind cannot be retried")
+)
+
+// Retrier re-runs a failed job from its stored payload
+type Retrier func(payload []byte) error
+
+// Queue stores background jobs that failed for good, so an outage does not silently lose work.
+// Subsystems record their failures and register a Retrier for their kind; operators list,
+// retry and delete entries. A job that fails again replaces its entry rather than adding one.
+type Queue struct {
+	db       *gorm.DB
+	mu       sync.RWMutex
+	retriers map[string]Retrier
+}
+
+func NewQueue(db *gorm.DB) *Queue {
+	return &Queue{db: db, retriers: make(map[string]Retrier)}
+}
+
+// Handle registers how jobs of a kind are retried
+func (q *Queue) Handle(kind string, retrier Retrier) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.retriers[kind] = retrier
+}
+
+// Record stores a failed job. Failures to record are logged, never returned, so callers can
+// record from their own error paths. A nil queue is a no-op.
+func (q *Queue) Record(kind string, reference string, payload interface{}, attempts int, cause error) {
+	if q == nil || cause == nil {
+		return
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("failed to encode dead letter %s %s: %v", kind, reference, err)
+		return
+	}
+
+	job := domain.FailedJob{
+		Kind:      kind,
+		Reference: reference,
+		Payload:   string(encoded),
+		Error:     cause.Error(),
+		Attempts:  attempts,
+		FailedAt:  time.Now(),
+	}
+	err = q.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "reference"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payload", "error", "attempts", "failed_at"}),
+	}).Create(&job).Error
+	if err != nil {
+		log.Printf("failed to record dead letter %s %s: %v", kind, reference, err)
+	}
+}
+
+// Count returns how many failed jobs are waiting for an operator
+func (q *Queue) Count() (int64, error) {
+	var total int64
+	err := q.db.Model(&domain.FailedJob{}).Count(&total).Error
+	return total, err
+}
+
+// List returns a page of failed jobs, newest failure first, optionally of one kind
+func (q *Queue) List(kind string, page, limit int) ([]domain.FailedJob, int64, error) {
+	query := q.db.Model(&domain.FailedJob{})
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	jobs := []domain.FailedJob{}
+	err := query.Order("failed_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, total, err
+}
+
+// Retry re-runs a failed job. On success the entry is removed; on failure it is kept with the new error.
+func (q *Queue) Retry(id uint) (*domain.FailedJob, error) {
+	var job domain.FailedJob
+	if err := q.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	q.mu.RLock()
+	retrier, ok := q.retriers[job.Kind]
+	q.mu.RUnlock()
+	if !ok {
+		return nil, ErrNoRetrier
+	}
+
+	now := time.Now()
+	job.Retries++
+	job.LastRetriedAt = &now
+	if retryErr := retrier([]byte(job.Payload)); retryErr != nil {
+		job.Error = retryErr.Error()
+		if err := q.db.Save(&job).Error; err != nil {
+			return nil, err
+		}
+		return &job, fmt.Errorf("retry failed: %w", retryErr)
+	}
+
+	if err := q.db.Delete(&domain.FailedJob{}, job.ID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Delete discards a failed job without retrying it
+func (q *Queue) Delete(id uint) error {
+	result := q.db.Delete(&domain.FailedJob{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
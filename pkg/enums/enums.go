@@ -7,6 +7,9 @@ const (
 	RoleAdvisor Role = "advisor"
 	RoleAdmin   Role = "admin"
 	RolePublic  Role = "public"
+	// RoleExaminer is an external examiner's guest account, created by a department admin and
+	// limited to the projects they examine until their access expires; it cannot be registered
+	RoleExaminer Role = "examiner"
 )
 
 // Helper to check validity
@@ -25,8 +28,12 @@ const (
 	ProposalStatusSubmitted        ProposalStatus = "submitted"
 	ProposalStatusUnderReview      ProposalStatus = "under_review"
 	ProposalStatusRevisionRequired ProposalStatus = "revision_required"
-	ProposalStatusApproved         ProposalStatus = "approved"
-	ProposalStatusRejected         ProposalStatus = "rejected"
+	// ProposalStatusAwaitingSecondReview holds an advisor's approval until the co-reviewer approves too
+	ProposalStatusAwaitingSecondReview ProposalStatus = "awaiting_second_review"
+	ProposalStatusApproved             ProposalStatus = "approved"
+	ProposalStatusRejected             ProposalStatus = "rejected"
+	// ProposalStatusAbandoned closes a revision request the team stopped working on; an admin can reopen it
+	ProposalStatusAbandoned ProposalStatus = "abandoned"
 )
 
 type TeamStatus string
@@ -43,6 +50,9 @@ const (
 	DocumentStatusPending  DocumentStatus = "pending"
 	DocumentStatusApproved DocumentStatus = "approved"
 	DocumentStatusRejected DocumentStatus = "rejected"
+	// DocumentStatusCarriedOver marks a copy of a previous cohort's document on a continued project;
+	// it is shown for reference and does not count as submitted
+	DocumentStatusCarriedOver DocumentStatus = "carried_over"
 )
 
 type InvitationStatus string
@@ -51,4 +61,143 @@ const (
 	InvitationStatusPending  InvitationStatus = "pending"
 	InvitationStatusAccepted InvitationStatus = "accepted"
 	InvitationStatusRejected InvitationStatus = "rejected"
+	InvitationStatusExpired  InvitationStatus = "expired"
+)
+
+// FunctionalRole is what a member mainly works on within their team
+type FunctionalRole string
+
+const (
+	FunctionalRoleFrontend      FunctionalRole = "frontend"
+	FunctionalRoleBackend       FunctionalRole = "backend"
+	FunctionalRoleML            FunctionalRole = "ml"
+	FunctionalRoleDocumentation FunctionalRole = "documentation"
+)
+
+// FunctionalRoles lists every functional role, in display order
+var FunctionalRoles = []FunctionalRole{FunctionalRoleFrontend, FunctionalRoleBackend, FunctionalRoleML, FunctionalRoleDocumentation}
+
+func IsValidFunctionalRole(r string) bool {
+	for _, role := range FunctionalRoles {
+		if FunctionalRole(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+type AIJobStatus string
+
+const (
+	AIJobStatusQueued    AIJobStatus = "queued"
+	AIJobStatusRunning   AIJobStatus = "running"
+	AIJobStatusCompleted AIJobStatus = "completed"
+	AIJobStatusFailed    AIJobStatus = "failed"
+)
+
+// ScanStatus is the outcome of scanning an uploaded file before it is offered for download
+type ScanStatus string
+
+const (
+	ScanStatusPending  ScanStatus = "pending"
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected"
+	ScanStatusFailed   ScanStatus = "failed"
+	// ScanStatusReleased marks a file an admin let out of quarantine without a clean scan
+	ScanStatusReleased ScanStatus = "released"
+)
+
+// QuarantinedScanStatuses keep a file in quarantine: it is stored but cannot be downloaded
+var QuarantinedScanStatuses = []ScanStatus{ScanStatusPending, ScanStatusFailed, ScanStatusInfected}
+
+// Quarantined tells whether a file with this scan status is held back from downloads. Files
+// uploaded before scanning existed have no status and are served.
+func (s ScanStatus) Quarantined() bool {
+	for _, status := range QuarantinedScanStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkStatus is the reachability of a project's code or deployed link
+type LinkStatus string
+
+const (
+	LinkStatusOK     LinkStatus = "ok"
+	LinkStatusBroken LinkStatus = "broken"
+)
+
+// ConflictNature is the kind of conflict of interest an advisor declares with a team
+type ConflictNature string
+
+const (
+	ConflictNatureRelative     ConflictNature = "relative"     // a family member is on the team
+	ConflictNaturePersonal     ConflictNature = "personal"     // a close personal relationship
+	ConflictNatureFinancial    ConflictNature = "financial"    // business or financial ties
+	ConflictNatureProfessional ConflictNature = "professional" // e.g. the project is for the advisor's own company
+	ConflictNatureOther        ConflictNature = "other"
+)
+
+// ConflictNatures lists every conflict nature, in display order
+var ConflictNatures = []ConflictNature{ConflictNatureRelative, ConflictNaturePersonal, ConflictNatureFinancial, ConflictNatureProfessional, ConflictNatureOther}
+
+func IsValidConflictNature(n string) bool {
+	for _, nature := range ConflictNatures {
+		if ConflictNature(n) == nature {
+			return true
+		}
+	}
+	return false
+}
+
+// DataExportStatus is the state of a user's personal data export
+type DataExportStatus string
+
+const (
+	DataExportStatusQueued    DataExportStatus = "queued"
+	DataExportStatusRunning   DataExportStatus = "running"
+	DataExportStatusCompleted DataExportStatus = "completed"
+	DataExportStatusFailed    DataExportStatus = "failed"
+)
+
+// AdvisorRequestStatus is the state of one preference in a team's ranked advisor requests
+type AdvisorRequestStatus string
+
+const (
+	AdvisorRequestStatusQueued    AdvisorRequestStatus = "queued"  // waiting for the preferences ranked above it
+	AdvisorRequestStatusPending   AdvisorRequestStatus = "pending" // the advisor has been asked
+	AdvisorRequestStatusAccepted  AdvisorRequestStatus = "accepted"
+	AdvisorRequestStatusDeclined  AdvisorRequestStatus = "declined"
+	AdvisorRequestStatusSkipped   AdvisorRequestStatus = "skipped"   // the advisor could no longer be asked, e.g. a declared conflict
+	AdvisorRequestStatusWithdrawn AdvisorRequestStatus = "withdrawn" // the leader withdrew the list, or another preference accepted
+)
+
+// DeadlineExtensionStatus is the state of a team's request for more time to submit its proposal
+type DeadlineExtensionStatus string
+
+const (
+	DeadlineExtensionPending  DeadlineExtensionStatus = "pending"
+	DeadlineExtensionApproved DeadlineExtensionStatus = "approved"
+	DeadlineExtensionDenied   DeadlineExtensionStatus = "denied"
+)
+
+// LeadershipVoteStatus is the state of a team's vote for a new leader
+type LeadershipVoteStatus string
+
+const (
+	LeadershipVoteOpen      LeadershipVoteStatus = "open"
+	LeadershipVoteElected   LeadershipVoteStatus = "elected"   // a candidate won a majority and leads the team
+	LeadershipVoteExpired   LeadershipVoteStatus = "expired"   // no majority in time; the department admin is asked to appoint a leader
+	LeadershipVoteCancelled LeadershipVoteStatus = "cancelled" // the leader became active again, or an admin appointed one
+)
+
+// ReviewModerationStatus is whether a public project review is shown
+type ReviewModerationStatus string
+
+const (
+	ReviewModerationApproved ReviewModerationStatus = "approved" // shown; reviews that match no banned word are approved on submission
+	ReviewModerationPending  ReviewModerationStatus = "pending"  // matched a banned word, hidden until an admin decides
+	ReviewModerationRejected ReviewModerationStatus = "rejected" // hidden for good
 )
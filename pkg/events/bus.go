@@ -0,0 +1,136 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Name identifies a domain event
+type Name string
+
+const (
+	TeamInvited             Name = "team.invited"
+	TeamInvitationAccepted  Name = "team.invitation_accepted"
+	TeamInvitationRejected  Name = "team.invitation_rejected"
+	TaskAssigned            Name = "team.task_assigned"
+	AdvisorRequested        Name = "team.advisor_requested"
+	AdvisorRequestAccepted  Name = "team.advisor_request_accepted"
+	AdvisorRequestDeclined  Name = "team.advisor_request_declined"
+	AdvisorListExhausted    Name = "team.advisor_requests_exhausted"
+	LeaderAbsent            Name = "team.leader_absent"
+	LeadershipVoteStarted   Name = "team.leadership_vote_started"
+	LeadershipVoteExpired   Name = "team.leadership_vote_expired"
+	LeaderReplaced          Name = "team.leader_replaced"
+	ProposalSubmitted       Name = "proposal.submitted"
+	ProposalResubmitted     Name = "proposal.resubmitted"
+	ProposalVersionUploaded Name = "proposal.version_uploaded"
+	ProposalAdvisorAssigned Name = "proposal.advisor_assigned"
+	AssignmentEscalated     Name = "proposal.assignment_escalated"
+	SecondReviewerAssigned  Name = "proposal.second_reviewer_assigned"
+	SecondReviewRequested   Name = "proposal.second_review_requested"
+	ProposalApproved        Name = "proposal.approved"
+	ProposalRevisionRequest Name = "proposal.revision_requested"
+	ProposalRejected        Name = "proposal.rejected"
+	RevisionDeadlineNear    Name = "proposal.revision_deadline_near"
+	RevisionDeadlineMissed  Name = "proposal.revision_deadline_missed"
+	ProposalAbandonWarning  Name = "proposal.abandon_warning"
+	ProposalAbandoned       Name = "proposal.abandoned"
+	ProposalReopened        Name = "proposal.reopened"
+	SubmissionDeadlineNear  Name = "team.submission_deadline_near"
+	SubmissionsOutstanding  Name = "department.submissions_outstanding"
+	ExtensionRequested      Name = "team.deadline_extension_requested"
+	ExtensionApproved       Name = "team.deadline_extension_approved"
+	ExtensionDenied         Name = "team.deadline_extension_denied"
+	SecondOpinionRequested  Name = "proposal.second_opinion_requested"
+	SecondOpinionAnswered   Name = "proposal.second_opinion_answered"
+	ProjectPublished        Name = "project.published"
+	ProjectGradeLocked      Name = "project.grade_locked"
+	CohortArchived          Name = "proposal.cohort_archived"
+	AnnouncementPosted      Name = "department.announcement_posted"
+	AdvisorMessageSent      Name = "department.advisor_message_sent"
+	DataExportReady         Name = "user.data_export_ready"
+	AIAnalysisCompleted     Name = "ai.analysis_completed"
+	AIAnalysisFailed        Name = "ai.analysis_failed"
+)
+
+// Event is a fact published by a service after a state change.
+// UserIDs lists the users directly affected (invitee, team members, advisor...).
+type Event struct {
+	Name       Name
+	EntityType string
+	EntityID   uint
+	ActorID    uint
+	UserIDs    []uint
+	Data       map[string]interface{}
+	OccurredAt time.Time
+}
+
+// Handler reacts to a published event
+type Handler func(Event)
+
+// Bus is an in-process publish/subscribe dispatcher.
+// Handlers run asynchronously so side effects never block or fail the request.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Name][]Handler
+	catchAll []Handler
+	wg       sync.WaitGroup
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[Name][]Handler)}
+}
+
+// Subscribe registers a handler for the given event names
+func (b *Bus) Subscribe(h Handler, names ...Name) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range names {
+		b.handlers[name] = append(b.handlers[name], h)
+	}
+}
+
+// SubscribeAll registers a handler that receives every event
+func (b *Bus) SubscribeAll(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.catchAll = append(b.catchAll, h)
+}
+
+// Publish dispatches the event to all matching handlers. A nil bus is a no-op.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[e.Name])+len(b.catchAll))
+	handlers = append(handlers, b.handlers[e.Name]...)
+	handlers = append(handlers, b.catchAll...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		b.wg.Add(1)
+		go b.dispatch(h, e)
+	}
+}
+
+// Wait blocks until all in-flight handlers have finished (used on shutdown)
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
+
+func (b *Bus) dispatch(h Handler, e Event) {
+	defer b.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("event handler for %s panicked: %v", e.Name, r)
+		}
+	}()
+	h(e)
+}
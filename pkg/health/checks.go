@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// slowDatabase is the round trip above which the database is reported as degraded
+const slowDatabase = 500 * time.Millisecond
+
+// Database pings the database and reports its connection pool
+func Database(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) Result {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return Result{Status: LevelDown, Message: "database unavailable", Error: err.Error()}
+		}
+		start := time.Now()
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return Result{Status: LevelDown, Message: "database unreachable", Error: err.Error()}
+		}
+		latency := time.Since(start)
+
+		stats := sqlDB.Stats()
+		result := Result{Status: LevelOK, Metrics: map[string]interface{}{
+			"ping_ms":          latency.Milliseconds(),
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"wait_count":       stats.WaitCount,
+		}}
+		if latency > slowDatabase {
+			result.Status = LevelDegraded
+			result.Message = fmt.Sprintf("database responds slowly (%d ms)", latency.Milliseconds())
+		}
+		return result
+	}
+}
+
+// Directories checks that each directory, keyed by its role, exists and accepts new files
+func Directories(dirs map[string]string) CheckFunc {
+	return func(ctx context.Context) Result {
+		result := Result{Status: LevelOK, Metrics: make(map[string]interface{}, len(dirs))}
+		for name, dir := range dirs {
+			if err := probeWritable(dir); err != nil {
+				result.Status = LevelDown
+				result.Message = name + " directory is not writable"
+				result.Error = err.Error()
+				result.Metrics[name] = "unwritable"
+				continue
+			}
+			result.Metrics[name] = "writable"
+		}
+		return result
+	}
+}
+
+func probeWritable(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(filepath.Clean(name))
+}
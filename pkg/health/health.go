@@ -0,0 +1,150 @@
+// Package health runs the dependency checks behind the health endpoints. Each check reports
+// one of the levels below; the overall level is down when a critical dependency is down and
+// degraded when any other dependency is not ok.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Level string
+
+const (
+	LevelOK       Level = "ok"
+	LevelDegraded Level = "degraded"
+	LevelDown     Level = "down"
+	// LevelDisabled marks an optional dependency that is not configured; it does not affect the overall level
+	LevelDisabled Level = "disabled"
+)
+
+const (
+	// checkTimeout bounds each check, so one hanging dependency cannot stall the endpoint
+	checkTimeout = 3 * time.Second
+	// cacheTTL keeps frequent probes from uptime monitors off the dependencies
+	cacheTTL = 5 * time.Second
+)
+
+// Result is the outcome of one check. Error holds the underlying failure and is only shown to admins.
+type Result struct {
+	Status    Level                  `json:"status"`
+	Critical  bool                   `json:"critical"`
+	LatencyMS int64                  `json:"latency_ms"`
+	Message   string                 `json:"message,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Metrics   map[string]interface{} `json:"metrics,omitempty"`
+}
+
+// Report is the outcome of all checks
+type Report struct {
+	Status    Level             `json:"status"`
+	Checks    map[string]Result `json:"checks"`
+	CheckedAt time.Time         `json:"checked_at"`
+}
+
+// CheckFunc probes a dependency; the latency and criticality are filled in by the Checker
+type CheckFunc func(ctx context.Context) Result
+
+type check struct {
+	name     string
+	critical bool
+	run      CheckFunc
+}
+
+// Checker holds the registered checks and the last report
+type Checker struct {
+	mu     sync.Mutex
+	checks []check
+	last   *Report
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Register adds a check. The service cannot work without a critical dependency.
+func (c *Checker) Register(name string, critical bool, run CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, critical: critical, run: run})
+}
+
+// Run returns the current report, running the checks in parallel when the last report is stale
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.last.CheckedAt) < cacheTTL {
+		return *c.last
+	}
+
+	// A client hanging up must not leave a failed report in the cache
+	ctx = context.WithoutCancel(ctx)
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, chk := range c.checks {
+		wg.Add(1)
+		go func(i int, chk check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, chk)
+		}(i, chk)
+	}
+	wg.Wait()
+
+	report := Report{Status: LevelOK, Checks: make(map[string]Result, len(c.checks)), CheckedAt: time.Now()}
+	for i, chk := range c.checks {
+		report.Checks[chk.name] = results[i]
+		report.Status = worse(report.Status, overallLevel(results[i]))
+	}
+	c.last = &report
+	return report
+}
+
+// Public returns the report without the underlying errors, which can name hosts and paths
+func (r Report) Public() Report {
+	public := Report{Status: r.Status, Checks: make(map[string]Result, len(r.Checks)), CheckedAt: r.CheckedAt}
+	for name, result := range r.Checks {
+		result.Error = ""
+		public.Checks[name] = result
+	}
+	return public
+}
+
+func runCheck(ctx context.Context, chk check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	done := make(chan Result, 1)
+	start := time.Now()
+	go func() { done <- chk.run(ctx) }()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Result{Status: LevelDown, Message: "check timed out", Error: ctx.Err().Error()}
+	}
+	result.Critical = chk.critical
+	result.LatencyMS = time.Since(start).Milliseconds()
+	return result
+}
+
+// overallLevel is what a check contributes to the overall level
+func overallLevel(result Result) Level {
+	switch {
+	case result.Status == LevelDisabled || result.Status == LevelOK:
+		return LevelOK
+	case result.Status == LevelDown && result.Critical:
+		return LevelDown
+	default:
+		return LevelDegraded
+	}
+}
+
+func worse(a, b Level) Level {
+	rank := map[Level]int{LevelOK: 0, LevelDegraded: 1, LevelDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+)
+
+// ErrInvalidAddress is returned for a sender or recipient that is not a single plain address
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Mailer sends plain-text emails over SMTP. Email is optional: a nil Mailer, returned when
+// no SMTP host is configured, accepts every message and sends nothing.
+type Mailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// New returns a mailer for the SMTP server, or nil when host is empty
+func New(host, port, username, password, from string) *Mailer {
+	if host == "" {
+		return nil
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &Mailer{addr: host + ":" + port, auth: auth, from: from}
+}
+
+// Enabled reports whether messages are actually sent
+func (m *Mailer) Enabled() bool {
+	return m != nil
+}
+
+// Ping checks that the SMTP server accepts connections. Messages are sent synchronously, so an
+// unreachable server means notifications are not being emailed.
+func (m *Mailer) Ping(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Send delivers one message to a single recipient. The subject is folded onto one line and
+// encoded, so text taken from user input cannot add headers.
+func (m *Mailer) Send(to, subject, body string) error {
+	if m == nil {
+		return nil
+	}
+	if err := checkAddress(m.from); err != nil {
+		return fmt.Errorf("sender: %w", err)
+	}
+	if err := checkAddress(to); err != nil {
+		return err
+	}
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + encodeSubject(subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("send mail to %s: %w", to, err)
+	}
+	return nil
+}
+
+// checkAddress accepts a single address, optionally with a display name, on one line
+func checkAddress(address string) error {
+	if strings.ContainsAny(address, "\r\n") {
+		return fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+	if _, err := mail.ParseAddress(address); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+	return nil
+}
+
+// encodeSubject replaces line breaks with spaces and Q-encodes any non-ASCII text
+func encodeSubject(subject string) string {
+	subject = strings.Join(strings.FieldsFunc(subject, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+	return mime.QEncoding.Encode("utf-8", subject)
+}
@@ -0,0 +1,132 @@
+package maintenance
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultMessage is returned to clients when the admin did not leave one
+	DefaultMessage = "The system is undergoing maintenance. You can keep browsing, but changes cannot be saved right now. Please try again shortly."
+
+	// refreshInterval bounds how long an instance keeps serving a stale flag after another
+	// instance switched it
+	refreshInterval = 5 * time.Second
+
+	// stateID is the primary key of the single flag row
+	stateID = 1
+)
+
+var ErrEndsInPast = errors.New("the expected end of maintenance must be in the future")
+
+// Status is the maintenance mode in effect. Source says what enabled it: "config" when
+// MAINTENANCE_MODE is set, which cannot be switched off at runtime, or "database".
+type Status struct {
+	Enabled   bool       `json:"enabled"`
+	Source    string     `json:"source,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	UpdatedBy *uint      `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Mode holds the maintenance flag. It is read on every write request, so the database row is
+// cached and reloaded at most every refreshInterval; a failed reload keeps the last known state.
+type Mode struct {
+	db       *gorm.DB
+	forced   bool
+	mu       sync.Mutex
+	state    domain.MaintenanceState
+	loadedAt time.Time
+}
+
+// NewMode creates the flag; forced keeps maintenance on whatever the database says
+func NewMode(db *gorm.DB, forced bool) *Mode {
+	return &Mode{db: db, forced: forced}
+}
+
+// Status returns the maintenance mode in effect. A nil Mode is never in maintenance.
+func (m *Mode) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+
+	m.mu.Lock()
+	if time.Since(m.loadedAt) >= refreshInterval {
+		var state domain.MaintenanceState
+		err := m.db.Where("id = ?", stateID).Limit(1).Find(&state).Error
+		if err != nil {
+			log.Printf("failed to load maintenance state: %v", err)
+		} else {
+			m.state = state
+		}
+		m.loadedAt = time.Now()
+	}
+	state := m.state
+	m.mu.Unlock()
+
+	return m.status(state)
+}
+
+// Set switches the database flag and returns the resulting status. Turning it off clears the
+// message and expected end.
+func (m *Mode) Set(enabled bool, message string, endsAt *time.Time, actorID uint) (Status, error) {
+	message = strings.TrimSpace(message)
+	if !enabled {
+		message, endsAt = "", nil
+	}
+	if endsAt != nil && !endsAt.After(time.Now()) {
+		return Status{}, ErrEndsInPast
+	}
+
+	state := domain.MaintenanceState{
+		ID:        stateID,
+		Enabled:   enabled,
+		Message:   message,
+		EndsAt:    endsAt,
+		UpdatedBy: &actorID,
+		UpdatedAt: time.Now(),
+	}
+	err := m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "message", "ends_at", "updated_by", "updated_at"}),
+	}).Create(&state).Error
+	if err != nil {
+		return Status{}, err
+	}
+
+	m.mu.Lock()
+	m.state = state
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+
+	return m.status(state), nil
+}
+
+func (m *Mode) status(state domain.MaintenanceState) Status {
+	status := Status{Enabled: state.Enabled || m.forced, EndsAt: state.EndsAt, UpdatedBy: state.UpdatedBy}
+	if !state.UpdatedAt.IsZero() {
+		updatedAt := state.UpdatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	switch {
+	case m.forced:
+		status.Source = "config"
+	case state.Enabled:
+		status.Source = "database"
+	}
+	if status.Enabled {
+		status.Message = state.Message
+		if status.Message == "" {
+			status.Message = DefaultMessage
+		}
+	}
+	return status
+}
@@ -0,0 +1,404 @@
+// Package markdown renders the Markdown users write into HTML that is safe to embed in a page.
+//
+// Only a fixed subset is supported: headings, paragraphs, emphasis, strikethrough, inline and
+// fenced code, block quotes, lists, horizontal rules and links. Raw HTML in the source is
+// escaped rather than passed through, and links keep only http, https, mailto and relative
+// targets, so the output contains no markup the renderer did not produce itself.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rulePattern      = regexp.MustCompile(`^ {0,3}([-*_])( *[-*_]){2,} *$`)
+	unorderedPattern = regexp.MustCompile(`^ {0,3}[-*+]\s+(.*)$`)
+	orderedPattern   = regexp.MustCompile(`^ {0,3}(\d{1,9})[.)]\s+(.*)$`)
+	fencePattern     = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*([A-Za-z0-9_+-]*)")
+)
+
+// Normalize cleans Markdown before it is stored: line endings become \n, control characters
+// other than tabs and newlines are dropped, and surrounding blank space is trimmed
+func Normalize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, src)
+	return strings.TrimSpace(src)
+}
+
+// Render converts Markdown to sanitized HTML
+func Render(src string) string {
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(Normalize(src), "\n"))
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			m := fencePattern.FindStringSubmatch(line)
+			fence, lang := m[1], m[2]
+			i++
+			var code []string
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++ // closing fence, or past the end when it is missing
+			if lang != "" {
+				b.WriteString(`<pre><code class="language-` + lang + `">`)
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(trimmed):
+			m := headingPattern.FindStringSubmatch(trimmed)
+			tag := "h" + string(rune('0'+len(m[1])))
+			b.WriteString("<" + tag + ">" + renderInline(m[2]) + "</" + tag + ">\n")
+			i++
+
+		case rulePattern.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				l := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(l, " "))
+				i++
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case unorderedPattern.MatchString(line), orderedPattern.MatchString(line):
+			i = renderList(b, lines, i)
+
+		default:
+			var paragraph []string
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && (len(paragraph) == 0 || !startsBlock(lines[i])) {
+				paragraph = append(paragraph, lines[i])
+				i++
+			}
+			b.WriteString("<p>" + renderParagraph(paragraph) + "</p>\n")
+		}
+	}
+}
+
+// renderList writes the list starting at lines[start] and returns the index after it. Indented
+// lines continue the item above them; nested lists are flattened into their item's text.
+func renderList(b *strings.Builder, lines []string, start int) int {
+	ordered := orderedPattern.MatchString(lines[start])
+	if ordered {
+		first := orderedPattern.FindStringSubmatch(lines[start])[1]
+		if first = strings.TrimLeft(first, "0"); first != "1" && first != "" {
+			b.WriteString(`<ol start="` + first + `">` + "\n")
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	var item []string
+	flush := func() {
+		if item != nil {
+			b.WriteString("<li>" + renderParagraph(item) + "</li>\n")
+		}
+		item = nil
+	}
+
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if ordered && orderedPattern.MatchString(line) && !isIndented(line) {
+			flush()
+			item = []string{orderedPattern.FindStringSubmatch(line)[2]}
+			continue
+		}
+		if !ordered && unorderedPattern.MatchString(line) && !isIndented(line) {
+			flush()
+			item = []string{unorderedPattern.FindStringSubmatch(line)[1]}
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the list unless the next line continues it
+			if i+1 < len(lines) && (isIndented(lines[i+1]) || sameListMarker(lines[i+1], ordered)) {
+				continue
+			}
+			break
+		}
+		if isIndented(line) || !startsBlock(line) {
+			text := strings.TrimSpace(line)
+			if m := unorderedPattern.FindStringSubmatch(text); m != nil {
+				text = m[1]
+			} else if m := orderedPattern.FindStringSubmatch(text); m != nil {
+				text = m[2]
+			}
+			item = append(item, text)
+			continue
+		}
+		break
+	}
+	flush()
+
+	if ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+	return i
+}
+
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t")
+}
+
+func sameListMarker(line string, ordered bool) bool {
+	if ordered {
+		return orderedPattern.MatchString(line)
+	}
+	return unorderedPattern.MatchString(line)
+}
+
+// startsBlock reports whether the line opens a block other than a paragraph
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return headingPattern.MatchString(trimmed) || rulePattern.MatchString(line) || fencePattern.MatchString(line) ||
+		strings.HasPrefix(trimmed, ">") || unorderedPattern.MatchString(line) || orderedPattern.MatchString(line)
+}
+
+// renderParagraph renders the lines of a paragraph; a line ending in two spaces or a backslash
+// is followed by a hard line break
+func renderParagraph(lines []string) string {
+	parts := make([]string, 0, len(lines))
+	for i, line := range lines {
+		hardBreak := i < len(lines)-1 && (strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\"))
+		line = strings.TrimSpace(line)
+		if hardBreak {
+			line = strings.TrimSuffix(line, "\\")
+		}
+		rendered := renderInline(line)
+		if hardBreak {
+			rendered += "<br>"
+		}
+		parts = append(parts, rendered)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderInline renders code spans, links, emphasis and strikethrough, escaping everything else
+func renderInline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!~>|", text[i+1]) >= 0:
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			run := countRun(text[i:], '`')
+			fence := strings.Repeat("`", run)
+			if end := strings.Index(text[i+run:], fence); end >= 0 {
+				code := strings.TrimSpace(text[i+run : i+run+end])
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += run + end + run
+				continue
+			}
+			b.WriteString(fence)
+			i += run
+			continue
+
+		case c == '[' || (c == '!' && i+1 < len(text) && text[i+1] == '['):
+			open := i
+			if c == '!' {
+				open++
+			}
+			if label, target, n, ok := parseLink(text[open:]); ok {
+				href, safe := safeURL(target)
+				if safe {
+					b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + renderInline(label) + "</a>")
+				} else {
+					b.WriteString(renderInline(label))
+				}
+				i = open + n
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				target := text[i+1 : i+end]
+				if href, safe := safeURL(target); safe && !strings.ContainsAny(target, " \t") && strings.Contains(target, ":") {
+					escaped := html.EscapeString(href)
+					b.WriteString(`<a href="` + escaped + `" rel="nofollow noopener noreferrer">` + escaped + "</a>")
+					i += end + 1
+					continue
+				}
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if n, rendered, ok := renderEmphasis(text, i); ok {
+				b.WriteString(rendered)
+				i += n
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// renderEmphasis renders the emphasis opening at text[i]: ** or __ as strong, * or _ as em,
+// three of them as both and ~~ as del. It returns how many bytes it consumed.
+func renderEmphasis(text string, i int) (int, string, bool) {
+	c := text[i]
+	run := countRun(text[i:], c)
+	var delim, tag string
+	switch {
+	case c == '~' && run >= 2:
+		delim, tag = "~~", "del"
+	case c == '~':
+		return 0, "", false
+	case run >= 3:
+		delim, tag = text[i:i+3], "strong><em"
+	case run >= 2:
+		delim, tag = text[i:i+2], "strong"
+	default:
+		delim, tag = text[i:i+1], "em"
+	}
+
+	// Underscores inside words, as in snake_case, are not emphasis
+	if c == '_' && i > 0 && isWordByte(text[i-1]) {
+		return 0, "", false
+	}
+	start := i + len(delim)
+	if start >= len(text) || text[start] == ' ' {
+		return 0, "", false
+	}
+	end := strings.Index(text[start:], delim)
+	for end >= 0 {
+		closeAt := start + end
+		after := closeAt + len(delim)
+		validClose := text[closeAt-1] != ' ' && end > 0 &&
+			!(c == '_' && after < len(text) && isWordByte(text[after])) &&
+			!(tag == "em" && after < len(text) && text[after] == c)
+		if validClose {
+			open, close := "<"+tag+">", "</"+tag+">"
+			if tag == "strong><em" {
+				close = "</em></strong>"
+			}
+			return after - i, open + renderInline(text[start:closeAt]) + close, true
+		}
+		next := strings.Index(text[closeAt+1:], delim)
+		if next < 0 {
+			break
+		}
+		end = closeAt + 1 + next - start
+	}
+	return 0, "", false
+}
+
+// parseLink parses [label](target) at the start of text and returns its length
+func parseLink(text string) (string, string, int, bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(text) || text[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := closingParen(text[i+2:])
+			if end < 0 {
+				return "", "", 0, false
+			}
+			target := strings.TrimSpace(text[i+2 : i+2+end])
+			// An optional title after the target is accepted and dropped
+			if space := strings.IndexAny(target, " \t"); space >= 0 {
+				target = target[:space]
+			}
+			return text[1:i], strings.Trim(target, "<>"), i + 3 + end, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// closingParen returns the index of the parenthesis closing a link target, allowing balanced
+// parentheses inside it
+func closingParen(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// safeURL returns the link target when it is an http, https or mailto URL, or relative
+func safeURL(target string) (string, bool) {
+	target = strings.TrimSpace(target)
+	if target == "" || strings.ContainsAny(target, "\"'<>`") {
+		return "", false
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return target, true
+	}
+	return "", false
+}
+
+func countRun(text string, c byte) int {
+	n := 0
+	for n < len(text) && text[n] == c {
+		n++
+	}
+	return n
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func()
+}
+
+// Scheduler runs registered maintenance jobs on a fixed interval
+type Scheduler struct {
+	jobs []job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates an empty scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every registers a job. Jobs must be registered before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run func()) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start launches one goroutine per job. Each job runs once immediately, then on every tick.
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+	log.Printf("Scheduler started with %d job(s)", len(s.jobs))
+}
+
+// Stop signals all jobs to exit and waits for running ones to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	s.runOnce(j)
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(j)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", j.name, r)
+		}
+	}()
+	j.run()
+}
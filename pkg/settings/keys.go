@@ -0,0 +1,135 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type is the kind of value a setting holds
+type Type string
+
+const (
+	TypeInt    Type = "int"
+	TypeString Type = "string"
+)
+
+// Keys are typed by their value, so a setting cannot be read as the wrong type
+type (
+	IntKey    string
+	StringKey string
+)
+
+const (
+	// LockoutThreshold is how many failed logins in a row lock an account
+	LockoutThreshold IntKey = "auth.lockout_threshold"
+	// LockoutMinutes is how long a locked account stays locked
+	LockoutMinutes IntKey = "auth.lockout_minutes"
+	// AdvisorCapacity is how many cohort proposals an advisor is expected to handle when their
+	// department has no advisor quota
+	AdvisorCapacity IntKey = "advisors.default_capacity"
+	// LeaderInactiveDays is how long a team leader can go without signing in before the members
+	// may vote for a new leader, or an admin appoint one
+	LeaderInactiveDays IntKey = "teams.leader_inactive_days"
+)
+
+// Definition describes a setting: its type, default and allowed values. Settings that are not
+// PerUniversity can only be set system-wide; RestartRequired ones are only read at startup.
+type Definition struct {
+	Key             string `json:"key"`
+	Type            Type   `json:"type"`
+	Default         string `json:"default"`
+	Description     string `json:"description"`
+	Min             *int   `json:"min,omitempty"`
+	Max             *int   `json:"max,omitempty"`
+	PerUniversity   bool   `json:"per_university"`
+	RestartRequired bool   `json:"restart_required,omitempty"`
+}
+
+func intp(v int) *int {
+	return &v
+}
+
+// Definitions lists every setting, in display order
+var Definitions = []Definition{
+	{
+		Key:           string(LockoutThreshold),
+		Type:          TypeInt,
+		Default:       "5",
+		Description:   "Failed logins in a row that lock an account",
+		Min:           intp(1),
+		Max:           intp(100),
+		PerUniversity: true,
+	},
+	{
+		Key:           string(LockoutMinutes),
+		Type:          TypeInt,
+		Default:       "30",
+		Description:   "Minutes a locked account stays locked",
+		Min:           intp(1),
+		Max:           intp(24 * 60),
+		PerUniversity: true,
+	},
+	{
+		Key:           string(AdvisorCapacity),
+		Type:          TypeInt,
+		Default:       "5",
+		Description:   "Cohort proposals an advisor is expected to handle when the department has no advisor quota",
+		Min:           intp(1),
+		Max:           intp(100),
+		PerUniversity: true,
+	},
+	{
+		Key:           string(LeaderInactiveDays),
+		Type:          TypeInt,
+		Default:       "21",
+		Description:   "Days without activity after which a team leader can be replaced by a member vote or an admin",
+		Min:           intp(3),
+		Max:           intp(365),
+		PerUniversity: true,
+	},
+}
+
+// Lookup returns the definition of a key
+func Lookup(key string) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// InvalidValueError is returned for a value the definition does not allow
+type InvalidValueError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidValueError) Error() string {
+	return e.Key + " " + e.Reason
+}
+
+// Normalize checks a value against the definition and returns it in canonical form
+func (d Definition) Normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch d.Type {
+	case TypeInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", &InvalidValueError{d.Key, "must be a whole number"}
+		}
+		if d.Min != nil && n < *d.Min {
+			return "", &InvalidValueError{d.Key, fmt.Sprintf("must be at least %d", *d.Min)}
+		}
+		if d.Max != nil && n > *d.Max {
+			return "", &InvalidValueError{d.Key, fmt.Sprintf("must be at most %d", *d.Max)}
+		}
+		return strconv.Itoa(n), nil
+	default:
+		if value == "" {
+			return "", &InvalidValueError{d.Key, "must not be empty"}
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,193 @@
+package settings
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshInterval bounds how long an instance keeps serving a value another instance changed
+const refreshInterval = 30 * time.Second
+
+// Where an effective value comes from
+const (
+	SourceDefault    = "default"
+	SourceSystem     = "system"
+	SourceUniversity = "university"
+)
+
+var (
+	ErrUnknownKey       = errors.New("unknown setting")
+	ErrNotPerUniversity = errors.New("this setting can only be set system-wide")
+	ErrNotSet           = errors.New("the setting has no value here to reset")
+)
+
+// Value is a setting's effective value for a university, or system-wide for university 0
+type Value struct {
+	Definition
+	Value        string     `json:"value"`
+	Source       string     `json:"source"` // default, system or university
+	UniversityID uint       `json:"university_id,omitempty"`
+	UpdatedBy    *uint      `json:"updated_by,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+type scopedKey struct {
+	key          string
+	universityID uint
+}
+
+// Store reads and writes settings. Reads happen on hot paths such as logging in, so every row is
+// cached and reloaded at most every refreshInterval; a failed reload keeps the last known values.
+// A nil Store returns the defaults.
+type Store struct {
+	db       *gorm.DB
+	mu       sync.Mutex
+	rows     map[scopedKey]domain.Setting
+	loadedAt time.Time
+}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Int returns the setting for the university, falling back to the system-wide value and then
+// the default; university 0 reads the system-wide value
+func (s *Store) Int(key IntKey, universityID uint) int {
+	def, _ := Lookup(string(key))
+	value := s.Get(def, universityID).Value
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid value %q for setting %s, using the default: %v", value, key, err)
+		n, _ = strconv.Atoi(def.Default)
+	}
+	return n
+}
+
+// String returns the setting like Int does
+func (s *Store) String(key StringKey, universityID uint) string {
+	def, _ := Lookup(string(key))
+	return s.Get(def, universityID).Value
+}
+
+// Get resolves the effective value of a setting
+func (s *Store) Get(def Definition, universityID uint) Value {
+	value := Value{Definition: def, Value: def.Default, Source: SourceDefault}
+	if s == nil {
+		return value
+	}
+	rows := s.load()
+
+	if row, ok := rows[scopedKey{def.Key, 0}]; ok {
+		value.apply(row, SourceSystem)
+	}
+	if def.PerUniversity && universityID != 0 {
+		if row, ok := rows[scopedKey{def.Key, universityID}]; ok {
+			value.apply(row, SourceUniversity)
+		}
+	}
+	return value
+}
+
+func (v *Value) apply(row domain.Setting, source string) {
+	updatedAt := row.UpdatedAt
+	v.Value = row.Value
+	v.Source = source
+	v.UniversityID = row.UniversityID
+	v.UpdatedBy = row.UpdatedBy
+	v.UpdatedAt = &updatedAt
+}
+
+// List returns the effective value of every setting for the university
+func (s *Store) List(universityID uint) []Value {
+	values := make([]Value, 0, len(Definitions))
+	for _, def := range Definitions {
+		values = append(values, s.Get(def, universityID))
+	}
+	return values
+}
+
+// Set stores a value system-wide (university 0) or for one university, after checking it
+// against the setting's definition
+func (s *Store) Set(key string, universityID uint, value string, actorID uint) (Value, error) {
+	def, ok := Lookup(key)
+	if !ok {
+		return Value{}, ErrUnknownKey
+	}
+	if universityID != 0 && !def.PerUniversity {
+		return Value{}, ErrNotPerUniversity
+	}
+	normalized, err := def.Normalize(value)
+	if err != nil {
+		return Value{}, err
+	}
+
+	row := domain.Setting{
+		Key:          key,
+		UniversityID: universityID,
+		Value:        normalized,
+		UpdatedBy:    &actorID,
+		UpdatedAt:    time.Now(),
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}, {Name: "university_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return Value{}, err
+	}
+
+	s.invalidate()
+	return s.Get(def, universityID), nil
+}
+
+// Reset removes the value set system-wide or for the university, so the next level applies again
+func (s *Store) Reset(key string, universityID uint) (Value, error) {
+	def, ok := Lookup(key)
+	if !ok {
+		return Value{}, ErrUnknownKey
+	}
+	result := s.db.Where("key = ? AND university_id = ?", key, universityID).Delete(&domain.Setting{})
+	if result.Error != nil {
+		return Value{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return Value{}, ErrNotSet
+	}
+
+	s.invalidate()
+	return s.Get(def, universityID), nil
+}
+
+// load returns the cached rows, reloading them when they are older than refreshInterval
+func (s *Store) load() map[scopedKey]domain.Setting {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rows == nil || time.Since(s.loadedAt) >= refreshInterval {
+		var rows []domain.Setting
+		if err := s.db.Find(&rows).Error; err != nil {
+			log.Printf("failed to load settings: %v", err)
+		} else {
+			s.rows = make(map[scopedKey]domain.Setting, len(rows))
+			for _, row := range rows {
+				s.rows[scopedKey{row.Key, row.UniversityID}] = row
+			}
+		}
+		s.loadedAt = time.Now()
+	}
+	return s.rows
+}
+
+// invalidate makes the next read reload, so this instance applies its own changes at once
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
@@ -0,0 +1,69 @@
+// Package uow runs operations that span several modules in one database transaction.
+// Repositories join a unit of work through their WithTx method; events raised inside it
+// are published only after it commits, so nobody is notified about rolled-back work.
+package uow
+
+import (
+	"backend/pkg/events"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork runs fn atomically. Services depend on this interface so tests can substitute
+// a fake that runs fn without a database.
+type UnitOfWork interface {
+	Do(fn func(tx *Tx) error) error
+}
+
+// Tx is the state of one unit of work
+type Tx struct {
+	db     *gorm.DB
+	events []events.Event
+}
+
+// NewTx wraps a database handle, e.g. for a fake UnitOfWork in tests
+func NewTx(db *gorm.DB) *Tx {
+	return &Tx{db: db}
+}
+
+// DB is the transaction's handle to pass to repositories' WithTx
+func (t *Tx) DB() *gorm.DB {
+	return t.db
+}
+
+// Publish queues an event until the unit of work commits
+func (t *Tx) Publish(e events.Event) {
+	t.events = append(t.events, e)
+}
+
+// Events are the queued events, in publish order
+func (t *Tx) Events() []events.Event {
+	return t.events
+}
+
+// Manager is the UnitOfWork backed by database transactions and the event bus
+type Manager struct {
+	db  *gorm.DB
+	bus *events.Bus
+}
+
+func NewManager(db *gorm.DB, bus *events.Bus) *Manager {
+	return &Manager{db: db, bus: bus}
+}
+
+// Do runs fn in a transaction, committing when it returns nil and rolling back otherwise.
+// The events fn queued are published after the commit.
+func (m *Manager) Do(fn func(tx *Tx) error) error {
+	tx := &Tx{}
+	err := m.db.Transaction(func(db *gorm.DB) error {
+		tx.db = db
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range tx.events {
+		m.bus.Publish(e)
+	}
+	return nil
+}